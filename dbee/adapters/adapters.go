@@ -2,8 +2,13 @@ package adapters
 
 import (
 	"bytes"
+	"database/sql/driver"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
 	"text/template"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
@@ -12,6 +17,17 @@ import (
 var (
 	errNoValidTypeAliases   = errors.New("no valid type aliases provided")
 	ErrUnsupportedTypeAlias = errors.New("no driver registered for provided type alias")
+	ErrPluginsNotSupported  = errors.New("go plugins are not supported by this build")
+)
+
+// Kinds of registered adapters
+const (
+	// compiled into the binary
+	AdapterKindBuiltin = "builtin"
+	// subprocess speaking the external adapter protocol (see External)
+	AdapterKindExternal = "external"
+	// loaded from a go plugin
+	AdapterKindPlugin = "plugin"
 )
 
 var _ core.Adapter = (*wrappedAdapter)(nil)
@@ -19,21 +35,44 @@ var _ core.Adapter = (*wrappedAdapter)(nil)
 // wrappedAdapter is returned from Mux and adds extra helpers to internal adapter.
 type wrappedAdapter struct {
 	adapter      core.Adapter
+	kind         string
 	extraHelpers map[string]*template.Template
 }
 
+type (
+	// AdapterOptions declare an adapter which is not compiled into the
+	// binary. Either Command or Plugin is required.
+	AdapterOptions struct {
+		// executable and arguments of an external adapter
+		Command []string
+		// path of a go plugin
+		Plugin string
+	}
+
+	// AdapterInfo describes a registered adapter.
+	AdapterInfo struct {
+		Type string
+		Kind string
+	}
+)
+
 // registeredAdapters holds implemented adapters - specific adapters register themselves in their init functions.
 // The main reason is to be able to compile the binary without unsupported os/arch of specific drivers.
 var registeredAdapters = make(map[string]*wrappedAdapter)
 
 // register registers a new adapter for specific database
 func register(adapter core.Adapter, aliases ...string) error {
+	return registerKind(adapter, AdapterKindBuiltin, aliases...)
+}
+
+func registerKind(adapter core.Adapter, kind string, aliases ...string) error {
 	if len(aliases) < 1 {
 		return errNoValidTypeAliases
 	}
 
 	value := &wrappedAdapter{
 		adapter: adapter,
+		kind:    kind,
 	}
 
 	invalidCount := 0
@@ -68,6 +107,43 @@ func (*Mux) AddAdapter(typ string, adapter core.Adapter) error {
 	return register(adapter, typ)
 }
 
+// LoadAdapter registers an adapter from an external binary or a go plugin
+// for the database type.
+func (*Mux) LoadAdapter(typ string, opts *AdapterOptions) error {
+	if opts == nil {
+		return fmt.Errorf("opts cannot be nil")
+	}
+
+	switch {
+	case len(opts.Command) > 0:
+		adapter, err := NewExternal(opts.Command)
+		if err != nil {
+			return err
+		}
+		return registerKind(adapter, AdapterKindExternal, typ)
+	case opts.Plugin != "":
+		adapter, err := loadPlugin(opts.Plugin)
+		if err != nil {
+			return err
+		}
+		return registerKind(adapter, AdapterKindPlugin, typ)
+	default:
+		return errors.New("either command or plugin is required")
+	}
+}
+
+// GetAdapters returns registered adapters sorted by type.
+func (*Mux) GetAdapters() []*AdapterInfo {
+	infos := make([]*AdapterInfo, 0, len(registeredAdapters))
+	for typ, value := range registeredAdapters {
+		infos = append(infos, &AdapterInfo{Type: typ, Kind: value.kind})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Type < infos[j].Type
+	})
+	return infos
+}
+
 func (*Mux) AddHelpers(typ string, helpers map[string]string) error {
 	value, ok := registeredAdapters[typ]
 	if !ok {
@@ -130,3 +206,39 @@ func NewConnection(params *core.ConnectionParams) (*core.Connection, error) {
 
 	return c, nil
 }
+
+// numericSessionID parses the session id for dialects which identify sessions with
+// numbers. The number is formatted back into the kill statement, so it has to be validated.
+func numericSessionID(id string) (int64, error) {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid session id %q: %w", id, err)
+	}
+	return n, nil
+}
+
+// isConnectionError reports whether the error is caused by a broken connection.
+// Statements which fail with it might have been executed, except for
+// driver.ErrBadConn (see isUnsentError).
+func isConnectionError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &netErr)
+}
+
+// isUnsentError reports whether the error guarantees that the statement
+// wasn't sent to the database.
+func isUnsentError(err error) bool {
+	return errors.Is(err, driver.ErrBadConn)
+}
+
+// columnNames returns names of columns.
+func columnNames(columns []*core.Column) []string {
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.Name
+	}
+	return names
+}
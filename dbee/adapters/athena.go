@@ -0,0 +1,111 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/athena"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// Register client
+func init() {
+	_ = register(&Athena{}, "athena")
+}
+
+var _ core.Adapter = (*Athena)(nil)
+
+const (
+	athenaDefaultCatalog      = "AwsDataCatalog"
+	athenaDefaultPollInterval = 500 * time.Millisecond
+)
+
+type Athena struct{}
+
+// Connect creates an [Athena] client in the region specified in the url.
+// The format of the url is as follows:
+//
+//	athena://[region][?options]
+//
+// Where:
+//   - "region" is optional. If not set, the region is taken from the AWS
+//     configuration (e.g. AWS_REGION or the profile).
+//   - "options" is a ampersand-separated list of key=value arguments.
+//
+// The supported "options" are:
+//   - workgroup=name (the workgroup's default output location is used if output isn't set)
+//   - output=s3://bucket/prefix/
+//   - catalog=data-catalog (AwsDataCatalog by default)
+//   - database=default-database
+//   - profile=shared-config-profile
+//   - poll-interval=duration (e.g. 500ms)
+//
+// Credentials are located with the default credential chain of the AWS SDK.
+func (a *Athena) Connect(rawURL string) (core.Driver, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != "athena" {
+		return nil, fmt.Errorf("unexpected scheme: %q", u.Scheme)
+	}
+
+	params := u.Query()
+
+	var options []func(*config.LoadOptions) error
+	if u.Host != "" {
+		options = append(options, config.WithRegion(u.Host))
+	}
+	_ = callIfStringSet("profile", params, func(profile string) error {
+		options = append(options, config.WithSharedConfigProfile(profile))
+		return nil
+	})
+
+	cfg, err := config.LoadDefaultConfig(context.TODO(), options...)
+	if err != nil {
+		return nil, fmt.Errorf("config.LoadDefaultConfig: %w", err)
+	}
+
+	client := &athenaDriver{
+		c:            athena.NewFromConfig(cfg),
+		catalog:      athenaDefaultCatalog,
+		pollInterval: athenaDefaultPollInterval,
+	}
+
+	_ = setStringOption(&client.workgroup, "workgroup", params)
+	_ = setStringOption(&client.output, "output", params)
+	_ = setStringOption(&client.catalog, "catalog", params)
+	_ = setStringOption(&client.database, "database", params)
+
+	if err := setOption(&client.pollInterval, "poll-interval", params, time.ParseDuration); err != nil {
+		return nil, err
+	}
+	if client.pollInterval <= 0 {
+		return nil, fmt.Errorf("invalid value for %q: must be positive", "poll-interval")
+	}
+	if client.workgroup == "" && client.output == "" {
+		return nil, fmt.Errorf("workgroup or output location is required")
+	}
+	if client.output != "" {
+		// the bucket is checked by athena, only the scheme is checked here
+		if out, err := url.Parse(client.output); err != nil || out.Scheme != "s3" {
+			return nil, fmt.Errorf("invalid output location: %q", client.output)
+		}
+	}
+
+	return client, nil
+}
+
+func (*Athena) GetHelpers(opts *core.TableOptions) map[string]string {
+	return map[string]string{
+		"List":       fmt.Sprintf(`SELECT * FROM "%s"."%s" LIMIT 500`, opts.Schema, opts.Table),
+		"Columns":    fmt.Sprintf("SHOW COLUMNS IN %s.%s", opts.Schema, opts.Table),
+		"Partitions": fmt.Sprintf("SHOW PARTITIONS %s.%s", opts.Schema, opts.Table),
+		"Definition": fmt.Sprintf("SHOW CREATE TABLE %s.%s", opts.Schema, opts.Table),
+	}
+}
@@ -0,0 +1,458 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/athena"
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var (
+	_ core.Driver                 = (*athenaDriver)(nil)
+	_ core.StructureLoader        = (*athenaDriver)(nil)
+	_ core.DatabaseSwitcher       = (*athenaDriver)(nil)
+	_ core.SampleDialect          = (*athenaDriver)(nil)
+	_ core.ErrorClassifier        = (*athenaDriver)(nil)
+	_ core.DialectOptionsProvider = (*athenaDriver)(nil)
+)
+
+// number of rows requested per page of results (the maximum athena allows)
+const athenaPageSize = 1000
+
+// athenaErrorPattern matches messages of failed queries, e.g.
+// "COLUMN_NOT_FOUND: line 1:8: Column 'x' cannot be resolved".
+var athenaErrorPattern = regexp.MustCompile(`^([A-Z_]+):(?: line (\d+):(\d+):)?`)
+
+type athenaDriver struct {
+	c            *athena.Client
+	workgroup    string
+	output       string
+	database     string
+	pollInterval time.Duration
+
+	// catalog is switched with SelectDatabase
+	mu      sync.Mutex
+	catalog string
+}
+
+// athenaQueryError is the reason of a failed query execution.
+type athenaQueryError struct {
+	id      string
+	message string
+}
+
+func (e *athenaQueryError) Error() string {
+	return fmt.Sprintf("query %s failed: %s", e.id, e.message)
+}
+
+func (c *athenaDriver) currentCatalog() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.catalog
+}
+
+// Query starts the query execution, polls its status until it finishes and
+// streams its results page by page. The execution is stopped if ctx is
+// canceled before it finishes.
+func (c *athenaDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	input := &athena.StartQueryExecutionInput{
+		QueryString: aws.String(query),
+		QueryExecutionContext: &types.QueryExecutionContext{
+			Catalog: aws.String(c.currentCatalog()),
+		},
+	}
+	if c.database != "" {
+		input.QueryExecutionContext.Database = aws.String(c.database)
+	}
+	if c.workgroup != "" {
+		input.WorkGroup = aws.String(c.workgroup)
+	}
+	if c.output != "" {
+		input.ResultConfiguration = &types.ResultConfiguration{OutputLocation: aws.String(c.output)}
+	}
+
+	started, err := c.c.StartQueryExecution(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	id := aws.ToString(started.QueryExecutionId)
+
+	execution, err := c.wait(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &core.Meta{}
+	if stats := execution.Statistics; stats != nil && stats.DataScannedInBytes != nil {
+		meta.Notices = append(meta.Notices, fmt.Sprintf("data scanned: %d bytes", *stats.DataScannedInBytes))
+	}
+
+	pages := &athenaPages{
+		ctx: ctx,
+		c:   c.c,
+		id:  id,
+		// results of SELECT statements start with a row of column names
+		skipHeaderRow: execution.StatementType == types.StatementTypeDml,
+	}
+	if err := pages.fetch(); err != nil {
+		return nil, err
+	}
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(pages.next, pages.hasNext).
+		WithHeader(pages.header).
+		WithMeta(meta).
+		Build(), nil
+}
+
+// wait polls the status of the query execution until it finishes.
+func (c *athenaDriver) wait(ctx context.Context, id string) (*types.QueryExecution, error) {
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		out, err := c.c.GetQueryExecution(ctx, &athena.GetQueryExecutionInput{QueryExecutionId: aws.String(id)})
+		if err != nil {
+			if ctx.Err() != nil {
+				c.stop(id)
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+
+		execution := out.QueryExecution
+		if execution == nil || execution.Status == nil {
+			return nil, fmt.Errorf("query %s has no status", id)
+		}
+
+		switch execution.Status.State {
+		case types.QueryExecutionStateSucceeded:
+			return execution, nil
+		case types.QueryExecutionStateFailed, types.QueryExecutionStateCancelled:
+			message := aws.ToString(execution.Status.StateChangeReason)
+			if execution.Status.AthenaError != nil && execution.Status.AthenaError.ErrorMessage != nil {
+				message = *execution.Status.AthenaError.ErrorMessage
+			}
+			if message == "" {
+				message = strings.ToLower(string(execution.Status.State))
+			}
+			return nil, &athenaQueryError{id: id, message: message}
+		}
+
+		select {
+		case <-ctx.Done():
+			c.stop(id)
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// stop stops the query execution, the context of the query is already done.
+func (c *athenaDriver) stop(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, _ = c.c.StopQueryExecution(ctx, &athena.StopQueryExecutionInput{QueryExecutionId: aws.String(id)})
+}
+
+// athenaPages reads results of a query execution page by page.
+type athenaPages struct {
+	ctx           context.Context
+	c             *athena.Client
+	id            string
+	skipHeaderRow bool
+
+	header      core.Header
+	columnTypes []string
+	rows        []types.Row
+	nextToken   *string
+	fetched     bool
+	// error of fetching the next page, returned by next
+	err error
+}
+
+// fetch loads the next page of results.
+func (p *athenaPages) fetch() error {
+	out, err := p.c.GetQueryResults(p.ctx, &athena.GetQueryResultsInput{
+		QueryExecutionId: aws.String(p.id),
+		MaxResults:       aws.Int32(athenaPageSize),
+		NextToken:        p.nextToken,
+	})
+	if err != nil {
+		return err
+	}
+
+	rows := out.ResultSet.Rows
+	if !p.fetched {
+		if out.ResultSet.ResultSetMetadata != nil {
+			for _, col := range out.ResultSet.ResultSetMetadata.ColumnInfo {
+				p.header = append(p.header, aws.ToString(col.Name))
+				p.columnTypes = append(p.columnTypes, aws.ToString(col.Type))
+			}
+		}
+		if p.skipHeaderRow && len(rows) > 0 && isAthenaHeaderRow(rows[0], p.header) {
+			rows = rows[1:]
+		}
+		p.fetched = true
+	}
+
+	p.rows = rows
+	p.nextToken = out.NextToken
+	return nil
+}
+
+func (p *athenaPages) hasNext() bool {
+	// pages can be empty
+	for len(p.rows) == 0 && p.nextToken != nil && p.err == nil {
+		p.err = p.fetch()
+	}
+	return len(p.rows) > 0 || p.err != nil
+}
+
+func (p *athenaPages) next() (core.Row, error) {
+	if !p.hasNext() {
+		return nil, errors.New("no more rows")
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	data := p.rows[0].Data
+	p.rows = p.rows[1:]
+
+	row := make(core.Row, len(data))
+	for i, datum := range data {
+		typ := ""
+		if i < len(p.columnTypes) {
+			typ = p.columnTypes[i]
+		}
+		row[i] = athenaValue(datum.VarCharValue, typ)
+	}
+	return row, nil
+}
+
+// isAthenaHeaderRow reports whether the row holds the names of the columns.
+func isAthenaHeaderRow(row types.Row, header core.Header) bool {
+	if len(row.Data) != len(header) {
+		return false
+	}
+	for i, datum := range row.Data {
+		if aws.ToString(datum.VarCharValue) != header[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// athenaValue converts a value of results, which athena returns as text, to
+// the type of its column. NULLs have no value.
+func athenaValue(value *string, typ string) any {
+	if value == nil {
+		return nil
+	}
+	text := *value
+
+	switch strings.ToLower(typ) {
+	case "tinyint", "smallint", "integer", "int", "bigint":
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n
+		}
+	case "float", "real", "double":
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(text); err == nil {
+			return b
+		}
+	}
+	// decimals stay text to keep their precision
+	return text
+}
+
+func (c *athenaDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	out, err := c.c.GetTableMetadata(context.TODO(), &athena.GetTableMetadataInput{
+		CatalogName:  aws.String(c.currentCatalog()),
+		DatabaseName: aws.String(opts.Schema),
+		TableName:    aws.String(opts.Table),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.TableMetadata == nil {
+		return nil, fmt.Errorf("table %s.%s has no metadata", opts.Schema, opts.Table)
+	}
+
+	var columns []*core.Column
+	// partition keys are columns of queries as well
+	for _, col := range append(out.TableMetadata.Columns, out.TableMetadata.PartitionKeys...) {
+		columns = append(columns, &core.Column{
+			Name:    aws.ToString(col.Name),
+			Type:    aws.ToString(col.Type),
+			Comment: aws.ToString(col.Comment),
+		})
+	}
+	return columns, nil
+}
+
+func (c *athenaDriver) Structure() ([]*core.Structure, error) {
+	ctx := context.TODO()
+
+	databases, err := c.StructureRoots(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, database := range databases {
+		database.Children, err = c.StructureChildren(ctx, database)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return databases, nil
+}
+
+// StructureRoots lists databases of the current catalog without their tables.
+func (c *athenaDriver) StructureRoots(ctx context.Context) ([]*core.Structure, error) {
+	var roots []*core.Structure
+
+	paginator := athena.NewListDatabasesPaginator(c.c, &athena.ListDatabasesInput{
+		CatalogName: aws.String(c.currentCatalog()),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, database := range page.DatabaseList {
+			roots = append(roots, &core.Structure{
+				Name:   aws.ToString(database.Name),
+				Schema: aws.ToString(database.Name),
+				Type:   core.StructureTypeNone,
+			})
+		}
+	}
+
+	return roots, nil
+}
+
+// StructureChildren lists tables and views of the database.
+func (c *athenaDriver) StructureChildren(ctx context.Context, parent *core.Structure) ([]*core.Structure, error) {
+	children := []*core.Structure{}
+
+	paginator := athena.NewListTableMetadataPaginator(c.c, &athena.ListTableMetadataInput{
+		CatalogName:  aws.String(c.currentCatalog()),
+		DatabaseName: aws.String(parent.Schema),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, table := range page.TableMetadataList {
+			typ := core.StructureTypeTable
+			if aws.ToString(table.TableType) == "VIRTUAL_VIEW" {
+				typ = core.StructureTypeView
+			}
+			children = append(children, &core.Structure{
+				Name:   aws.ToString(table.Name),
+				Schema: parent.Schema,
+				Type:   typ,
+			})
+		}
+	}
+
+	return children, nil
+}
+
+// ListDatabases lists data catalogs, which are switched like databases.
+func (c *athenaDriver) ListDatabases() (current string, available []string, err error) {
+	current = c.currentCatalog()
+
+	paginator := athena.NewListDataCatalogsPaginator(c.c, &athena.ListDataCatalogsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return "", nil, err
+		}
+		for _, catalog := range page.DataCatalogsSummary {
+			if name := aws.ToString(catalog.CatalogName); name != current {
+				available = append(available, name)
+			}
+		}
+	}
+
+	return current, available, nil
+}
+
+// SelectDatabase switches the data catalog of queries and the structure.
+func (c *athenaDriver) SelectDatabase(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.catalog = name
+	return nil
+}
+
+// SampleSyntax returns the sample syntax of athena's engine (trino).
+func (c *athenaDriver) SampleSyntax() core.SampleSyntax {
+	return core.SampleSyntax{
+		TableSample: "TABLESAMPLE BERNOULLI (%s)",
+		Random:      "rand()",
+	}
+}
+
+func (c *athenaDriver) DialectOptions() *core.DialectOptions {
+	return athenaDialectOptions
+}
+
+// ClassifyError returns details of failed query executions. The error name
+// and position are parsed from the message (e.g. "SYNTAX_ERROR: line 1:8: ...").
+func (c *athenaDriver) ClassifyError(err error) *core.QueryError {
+	var qErr *athenaQueryError
+	if !errors.As(err, &qErr) {
+		return nil
+	}
+
+	match := athenaErrorPattern.FindStringSubmatch(qErr.message)
+	if match == nil {
+		return &core.QueryError{Category: core.ErrorCategoryUnknown}
+	}
+
+	category := core.ErrorCategoryUnknown
+	switch match[1] {
+	case "SYNTAX_ERROR", "INVALID_FUNCTION_ARGUMENT", "TYPE_MISMATCH":
+		category = core.ErrorCategorySyntax
+	case "COLUMN_NOT_FOUND", "TABLE_NOT_FOUND", "SCHEMA_NOT_FOUND", "FUNCTION_NOT_FOUND", "CATALOG_NOT_FOUND":
+		category = core.ErrorCategoryNotFound
+	case "PERMISSION_DENIED", "ACCESS_DENIED":
+		category = core.ErrorCategoryPermission
+	case "EXCEEDED_TIME_LIMIT":
+		category = core.ErrorCategoryTimeout
+	}
+
+	line, _ := strconv.Atoi(match[2])
+	column, _ := strconv.Atoi(match[3])
+	return &core.QueryError{
+		Category: category,
+		Code:     match[1],
+		Line:     line,
+		Column:   column,
+	}
+}
+
+func (c *athenaDriver) Close() {}
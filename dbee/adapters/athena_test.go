@@ -0,0 +1,83 @@
+package adapters
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestAthena_Connect(t *testing.T) {
+	// keep local aws configuration out of the test
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+
+	testCases := []struct {
+		url         string
+		expectError bool
+	}{
+		{url: "athena://eu-west-1?workgroup=primary"},
+		{url: "athena://eu-west-1?output=s3://results/dbee/&catalog=other&database=logs&poll-interval=1s"},
+		{url: "athena://eu-west-1", expectError: true},
+		{url: "athena://eu-west-1?output=/tmp/results", expectError: true},
+		{url: "athena://eu-west-1?workgroup=primary&poll-interval=soon", expectError: true},
+		{url: "athena://eu-west-1?workgroup=primary&poll-interval=-1s", expectError: true},
+		{url: "bigquery://project?workgroup=primary", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.url, func(t *testing.T) {
+			r := require.New(t)
+
+			driver, err := (&Athena{}).Connect(tc.url)
+			if tc.expectError {
+				r.Error(err)
+				return
+			}
+			r.NoError(err)
+			r.NotEmpty(driver.(*athenaDriver).currentCatalog())
+		})
+	}
+}
+
+func TestAthenaValue(t *testing.T) {
+	r := require.New(t)
+
+	r.Nil(athenaValue(nil, "integer"))
+	r.Equal(int64(42), athenaValue(aws.String("42"), "integer"))
+	r.Equal(int64(-7), athenaValue(aws.String("-7"), "bigint"))
+	r.Equal(1.5, athenaValue(aws.String("1.5"), "double"))
+	r.Equal(true, athenaValue(aws.String("true"), "boolean"))
+	// decimals keep their precision
+	r.Equal("12345678901234567890.12", athenaValue(aws.String("12345678901234567890.12"), "decimal"))
+	r.Equal("2024-01-02 03:04:05.000", athenaValue(aws.String("2024-01-02 03:04:05.000"), "timestamp"))
+	r.Equal("", athenaValue(aws.String(""), "varchar"))
+}
+
+func TestAthena_ClassifyError(t *testing.T) {
+	r := require.New(t)
+
+	driver := &athenaDriver{}
+
+	qErr := driver.ClassifyError(&athenaQueryError{id: "1", message: "COLUMN_NOT_FOUND: line 2:8: Column 'x' cannot be resolved"})
+	r.NotNil(qErr)
+	r.Equal(core.ErrorCategoryNotFound, qErr.Category)
+	r.Equal("COLUMN_NOT_FOUND", qErr.Code)
+	r.Equal(2, qErr.Line)
+	r.Equal(8, qErr.Column)
+
+	qErr = driver.ClassifyError(&athenaQueryError{id: "1", message: "SYNTAX_ERROR: mismatched input"})
+	r.NotNil(qErr)
+	r.Equal(core.ErrorCategorySyntax, qErr.Category)
+	r.Zero(qErr.Line)
+
+	qErr = driver.ClassifyError(&athenaQueryError{id: "1", message: "Query exhausted resources"})
+	r.NotNil(qErr)
+	r.Equal(core.ErrorCategoryUnknown, qErr.Category)
+
+	r.Nil(driver.ClassifyError(errors.New("network is unreachable")))
+}
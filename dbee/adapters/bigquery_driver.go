@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"cloud.google.com/go/bigquery"
 	"github.com/kndndrj/nvim-dbee/dbee/core"
@@ -13,7 +14,15 @@ import (
 	"google.golang.org/api/iterator"
 )
 
-var _ core.Driver = (*bigQueryDriver)(nil)
+var (
+	_ core.Driver                   = (*bigQueryDriver)(nil)
+	_ core.StructureLoader          = (*bigQueryDriver)(nil)
+	_ core.ScanEstimator            = (*bigQueryDriver)(nil)
+	_ core.ScanPricer               = (*bigQueryDriver)(nil)
+	_ core.SampleDialect            = (*bigQueryDriver)(nil)
+	_ core.IdentifierQuoter         = (*bigQueryDriver)(nil)
+	_ core.StatementDialectProvider = (*bigQueryDriver)(nil)
+)
 
 type bigQueryDriver struct {
 	c                 *bigquery.Client
@@ -30,7 +39,23 @@ func (c *bigQueryDriver) Query(ctx context.Context, queryStr string) (core.Resul
 	query.UseLegacySQL = c.useLegacySQL
 	query.Location = c.location
 
-	iter, err := query.Read(ctx)
+	job, err := query.Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := job.Wait(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			// the job keeps running on the server otherwise
+			_ = job.Cancel(context.Background())
+		}
+		return nil, err
+	}
+
+	meta := bigQueryMeta(job, status)
+
+	iter, err := job.Read(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +63,28 @@ func (c *bigQueryDriver) Query(ctx context.Context, queryStr string) (core.Resul
 	// schema isn't available until the first call to iter.Next()
 	var firstRowLoader bigqueryRowLoader
 	if err := iter.Next(&firstRowLoader); err != nil {
-		return nil, err
+		if !errors.Is(err, iterator.Done) {
+			return nil, err
+		}
+
+		// statements without a result (e.g. DML) report affected rows
+		if len(iter.Schema) == 0 {
+			var affected int64
+			if stats, ok := status.Statistics.Details.(*bigquery.QueryStatistics); ok {
+				affected = stats.NumDMLAffectedRows
+			}
+			return builders.NewResultStreamBuilder().
+				WithNextFunc(builders.NextSingle(affected)).
+				WithHeader(core.Header{"Rows Affected"}).
+				WithMeta(meta).
+				Build(), nil
+		}
+
+		return builders.NewResultStreamBuilder().
+			WithNextFunc(builders.NextNil()).
+			WithHeader(c.buildHeader("", iter.Schema)).
+			WithMeta(meta).
+			Build(), nil
 	}
 
 	header := c.buildHeader("", iter.Schema)
@@ -70,10 +116,58 @@ func (c *bigQueryDriver) Query(ctx context.Context, queryStr string) (core.Resul
 	result := builders.NewResultStreamBuilder().
 		WithNextFunc(nextFn, hasNextFn).
 		WithHeader(header).
+		WithMeta(meta).
 		Build()
 	return result, nil
 }
 
+// bigQueryMeta returns the meta of the finished query job: its id and the
+// number of processed bytes (the billed ones are in notices).
+func bigQueryMeta(job *bigquery.Job, status *bigquery.JobStatus) *core.Meta {
+	meta := &core.Meta{
+		QueryID: job.ID(),
+	}
+	if status == nil || status.Statistics == nil {
+		return meta
+	}
+
+	meta.BytesProcessed = status.Statistics.TotalBytesProcessed
+	if stats, ok := status.Statistics.Details.(*bigquery.QueryStatistics); ok {
+		if stats.CacheHit {
+			meta.Notices = append(meta.Notices, "results were served from the query cache")
+		} else {
+			meta.Notices = append(meta.Notices, fmt.Sprintf("%d bytes billed", stats.TotalBytesBilled))
+		}
+	}
+	return meta
+}
+
+// EstimateScan returns the number of bytes the query would process, reported
+// by a dry run.
+func (c *bigQueryDriver) EstimateScan(ctx context.Context, queryStr string) (int64, error) {
+	query := c.c.Query(queryStr)
+	query.DryRun = true
+	query.DisableQueryCache = c.disableQueryCache
+	query.UseLegacySQL = c.useLegacySQL
+	query.Location = c.location
+
+	job, err := query.Run(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	status := job.LastStatus()
+	if status == nil || status.Statistics == nil {
+		return 0, errors.New("dry run didn't report statistics")
+	}
+	return status.Statistics.TotalBytesProcessed, nil
+}
+
+// ScanPrice returns the on-demand price of a scanned TiB in US multi-region.
+func (c *bigQueryDriver) ScanPrice() float64 {
+	return 6.25
+}
+
 func (c *bigQueryDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
 	query := fmt.Sprintf("SELECT * FROM `%s.INFORMATION_SCHEMA.COLUMNS` WHERE TABLE_SCHEMA = '%s' AND TABLE_NAME = '%s'", opts.Schema, opts.Schema, opts.Table)
 
@@ -88,6 +182,25 @@ func (c *bigQueryDriver) Columns(opts *core.TableOptions) ([]*core.Column, error
 func (c *bigQueryDriver) Structure() (layouts []*core.Structure, err error) {
 	ctx := context.TODO()
 
+	datasets, err := c.StructureRoots(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dataset := range datasets {
+		dataset.Children, err = c.StructureChildren(ctx, dataset)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return datasets, nil
+}
+
+// StructureRoots lists datasets without their tables.
+func (c *bigQueryDriver) StructureRoots(ctx context.Context) ([]*core.Structure, error) {
+	var layouts []*core.Structure
+
 	datasetsIter := c.c.Datasets(ctx)
 	for {
 		dataset, err := datasetsIter.Next()
@@ -99,36 +212,40 @@ func (c *bigQueryDriver) Structure() (layouts []*core.Structure, err error) {
 			break
 		}
 
-		datasetLayout := &core.Structure{
-			Name:     dataset.DatasetID,
-			Schema:   dataset.DatasetID,
-			Type:     core.StructureTypeNone,
-			Children: []*core.Structure{},
-		}
+		layouts = append(layouts, &core.Structure{
+			Name:   dataset.DatasetID,
+			Schema: dataset.DatasetID,
+			Type:   core.StructureTypeNone,
+		})
+	}
 
-		tablesIter := dataset.Tables(ctx)
-		for {
-			table, err := tablesIter.Next()
-			if err != nil {
-				if !errors.Is(err, iterator.Done) {
-					return nil, err
-				}
+	return layouts, nil
+}
 
-				break
+// StructureChildren lists tables of the dataset.
+func (c *bigQueryDriver) StructureChildren(ctx context.Context, parent *core.Structure) ([]*core.Structure, error) {
+	children := []*core.Structure{}
+
+	tablesIter := c.c.Dataset(parent.Schema).Tables(ctx)
+	for {
+		table, err := tablesIter.Next()
+		if err != nil {
+			if !errors.Is(err, iterator.Done) {
+				return nil, err
 			}
 
-			datasetLayout.Children = append(datasetLayout.Children, &core.Structure{
-				Name:     table.TableID,
-				Schema:   table.DatasetID,
-				Type:     core.StructureTypeTable,
-				Children: nil,
-			})
+			break
 		}
 
-		layouts = append(layouts, datasetLayout)
+		children = append(children, &core.Structure{
+			Name:     table.TableID,
+			Schema:   table.DatasetID,
+			Type:     core.StructureTypeTable,
+			Children: nil,
+		})
 	}
 
-	return layouts, nil
+	return children, nil
 }
 
 func (c *bigQueryDriver) Close() {
@@ -227,3 +344,19 @@ func callIfSet[T any](name string, params url.Values, parse func(string) (T, err
 
 	return cb(val)
 }
+
+func (c *bigQueryDriver) SampleSyntax() core.SampleSyntax {
+	return core.SampleSyntax{
+		Random: "RAND()",
+	}
+}
+
+// QuoteIdentifier quotes the identifier with backticks, double quotes are
+// string literals in bigquery.
+func (c *bigQueryDriver) QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "\\`") + "`"
+}
+
+func (c *bigQueryDriver) StatementDialect() *core.StatementDialect {
+	return bigQueryStatementDialect
+}
@@ -0,0 +1,186 @@
+package adapters
+
+import (
+	"fmt"
+	nurl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// Register client
+func init() {
+	_ = register(&Cassandra{}, "cassandra", "scylla", "scylladb")
+}
+
+var _ core.Adapter = (*Cassandra)(nil)
+
+type Cassandra struct{}
+
+// cassandraDefaultPageSize is the number of rows fetched with each page of a
+// result, unless the url says otherwise.
+const cassandraDefaultPageSize = 1000
+
+// Connect connects to the cluster of the url:
+//
+//	cassandra://[user[:password]@]host1[:port][,host2[:port]...][/keyspace][?options]
+//
+// Supported "options" are:
+//   - consistency=local_quorum sets the consistency of queries.
+//   - page_size=1000 sets the number of rows fetched with a page.
+//   - timeout=10s and connect_timeout=5s set the timeouts of queries and
+//     connecting.
+//   - proto_version=4 sets the version of the native protocol.
+//   - local_dc=dc1 (and local_rack=rack1) prefer hosts of the datacenter.
+//   - token_aware=false disables routing of queries to replicas of the
+//     partition, shuffle_replicas=true spreads them over all replicas.
+//   - ssl=true, ssl_ca=path, ssl_cert=path, ssl_key=path and
+//     ssl_insecure=true configure TLS.
+func (c *Cassandra) Connect(url string) (core.Driver, error) {
+	cluster, pageSize, err := parseCassandraURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := cluster.CreateSession()
+	if err != nil {
+		return nil, fmt.Errorf("cluster.CreateSession: %w", err)
+	}
+
+	return &cassandraDriver{
+		session:  session,
+		pageSize: pageSize,
+	}, nil
+}
+
+func (*Cassandra) GetHelpers(opts *core.TableOptions) map[string]string {
+	name := cassandraQualifiedName(opts.Schema, opts.Table)
+	keyspace := strings.ReplaceAll(opts.Schema, "'", "''")
+	table := strings.ReplaceAll(opts.Table, "'", "''")
+	return map[string]string{
+		"List": fmt.Sprintf("SELECT * FROM %s LIMIT 500", name),
+		"Columns": fmt.Sprintf("SELECT column_name, type, kind, position FROM system_schema.columns WHERE keyspace_name = '%s' AND table_name = '%s'",
+			keyspace, table),
+		"Indexes": fmt.Sprintf("SELECT index_name, kind, options FROM system_schema.indexes WHERE keyspace_name = '%s' AND table_name = '%s'",
+			keyspace, table),
+		"Table Options": fmt.Sprintf("SELECT * FROM system_schema.tables WHERE keyspace_name = '%s' AND table_name = '%s'",
+			keyspace, table),
+	}
+}
+
+// parseCassandraURL parses the url to the cluster config and the page size of
+// results.
+func parseCassandraURL(rawURL string) (*gocql.ClusterConfig, int, error) {
+	u, err := nurl.Parse(rawURL)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not parse db connection string: %w", err)
+	}
+	switch u.Scheme {
+	case "cassandra", "scylla", "scylladb":
+	default:
+		return nil, 0, fmt.Errorf("unexpected scheme: %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, 0, fmt.Errorf("no hosts in url")
+	}
+
+	cluster := gocql.NewCluster(strings.Split(u.Host, ",")...)
+	cluster.Keyspace = strings.Trim(u.Path, "/")
+
+	if u.User != nil {
+		password, _ := u.User.Password()
+		cluster.Authenticator = gocql.PasswordAuthenticator{
+			Username: u.User.Username(),
+			Password: password,
+		}
+	}
+
+	params := u.Query()
+
+	err = callIfStringSet("consistency", params, func(s string) error {
+		consistency, err := gocql.ParseConsistencyWrapper(strings.ToUpper(s))
+		if err != nil {
+			return fmt.Errorf("invalid value for \"consistency\": %w", err)
+		}
+		cluster.Consistency = consistency
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := setOption(&cluster.Timeout, "timeout", params, time.ParseDuration); err != nil {
+		return nil, 0, err
+	}
+	if err := setOption(&cluster.ConnectTimeout, "connect_timeout", params, time.ParseDuration); err != nil {
+		return nil, 0, err
+	}
+	if err := setOption(&cluster.ProtoVersion, "proto_version", params, strconv.Atoi); err != nil {
+		return nil, 0, err
+	}
+
+	pageSize := cassandraDefaultPageSize
+	if err := setOption(&pageSize, "page_size", params, strconv.Atoi); err != nil {
+		return nil, 0, err
+	}
+	if pageSize < 1 {
+		return nil, 0, fmt.Errorf("invalid value for \"page_size\": %d", pageSize)
+	}
+
+	// routing of queries
+	tokenAware := true
+	shuffle := false
+	if err := setBoolOption(&tokenAware, "token_aware", params); err != nil {
+		return nil, 0, err
+	}
+	if err := setBoolOption(&shuffle, "shuffle_replicas", params); err != nil {
+		return nil, 0, err
+	}
+	fallback := gocql.RoundRobinHostPolicy()
+	if dc := params.Get("local_dc"); dc != "" {
+		fallback = gocql.DCAwareRoundRobinPolicy(dc)
+		if rack := params.Get("local_rack"); rack != "" {
+			fallback = gocql.RackAwareRoundRobinPolicy(dc, rack)
+		}
+	}
+	switch {
+	case tokenAware && shuffle:
+		cluster.PoolConfig.HostSelectionPolicy = gocql.TokenAwareHostPolicy(fallback, gocql.ShuffleReplicas())
+	case tokenAware:
+		cluster.PoolConfig.HostSelectionPolicy = gocql.TokenAwareHostPolicy(fallback)
+	default:
+		cluster.PoolConfig.HostSelectionPolicy = fallback
+	}
+
+	// tls
+	var ssl bool
+	if err := setBoolOption(&ssl, "ssl", params); err != nil {
+		return nil, 0, err
+	}
+	var insecure bool
+	if err := setBoolOption(&insecure, "ssl_insecure", params); err != nil {
+		return nil, 0, err
+	}
+	if ssl || params.Get("ssl_ca") != "" || params.Get("ssl_cert") != "" {
+		cluster.SslOpts = &gocql.SslOptions{
+			CaPath:                 params.Get("ssl_ca"),
+			CertPath:               params.Get("ssl_cert"),
+			KeyPath:                params.Get("ssl_key"),
+			EnableHostVerification: !insecure,
+		}
+	}
+
+	return cluster, pageSize, nil
+}
+
+// cassandraQualifiedName returns the quoted name of the table in the keyspace.
+func cassandraQualifiedName(keyspace, table string) string {
+	quote := func(s string) string { return `"` + strings.ReplaceAll(s, `"`, `""`) + `"` }
+	if keyspace == "" {
+		return quote(table)
+	}
+	return quote(keyspace) + "." + quote(table)
+}
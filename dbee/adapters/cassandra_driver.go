@@ -0,0 +1,210 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var (
+	_ core.Driver                 = (*cassandraDriver)(nil)
+	_ core.SystemObjectClassifier = (*cassandraDriver)(nil)
+)
+
+// cassandraSystemKeyspaces are keyspaces of the cluster itself.
+var cassandraSystemKeyspaces = map[string]bool{
+	"system":                true,
+	"system_auth":           true,
+	"system_distributed":    true,
+	"system_schema":         true,
+	"system_traces":         true,
+	"system_views":          true,
+	"system_virtual_schema": true,
+}
+
+type cassandraDriver struct {
+	session  *gocql.Session
+	pageSize int
+}
+
+// Query executes the statement and fetches the rows page by page: the next
+// page is requested with the page state of the previous one only after all of
+// its rows were read.
+func (c *cassandraDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+
+	page := func(state []byte) *gocql.Iter {
+		return c.session.Query(query).
+			WithContext(ctx).
+			PageSize(c.pageSize).
+			PageState(state).
+			Iter()
+	}
+
+	iter := page(nil)
+	columns := iter.Columns()
+	if len(columns) < 1 {
+		// statements without a result (e.g. INSERT without IF)
+		if err := iter.Close(); err != nil {
+			return nil, err
+		}
+		return builders.NewResultStreamBuilder().
+			WithNextFunc(builders.NextSingle("OK")).
+			WithHeader(core.Header{"Result"}).
+			Build(), nil
+	}
+
+	header := make(core.Header, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+
+	next, hasNext := builders.NextYield(func(yield func(...any)) error {
+		for {
+			state := iter.PageState()
+
+			row, err := iter.RowData()
+			if err != nil {
+				_ = iter.Close()
+				return err
+			}
+			for iter.Scan(row.Values...) {
+				values := make([]any, len(row.Values))
+				for i, v := range row.Values {
+					values[i] = cassandraValue(reflect.ValueOf(v).Elem().Interface())
+				}
+				yield(values...)
+			}
+			if err := iter.Close(); err != nil {
+				return err
+			}
+
+			if len(state) < 1 {
+				return nil
+			}
+			iter = page(state)
+		}
+	})
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(header).
+		Build(), nil
+}
+
+// cassandraValue converts values of cql types to values which can be cached
+// and displayed. Collections, tuples and user defined types become json.
+func cassandraValue(val any) any {
+	switch v := val.(type) {
+	case nil, []byte, time.Time:
+		return v
+	case gocql.Duration:
+		return fmt.Sprintf("%dmo%dd%dns", v.Months, v.Days, v.Nanoseconds)
+	case fmt.Stringer:
+		// uuids, decimals, varints, inets and durations
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return nil
+		}
+		return v.String()
+	}
+
+	switch reflect.ValueOf(val).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		out, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(out)
+	default:
+		return val
+	}
+}
+
+func (c *cassandraDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	iter := c.session.Query(
+		`SELECT column_name, type, kind FROM system_schema.columns WHERE keyspace_name = ? AND table_name = ?`,
+		opts.Schema, opts.Table).
+		Iter()
+
+	var columns []*core.Column
+	var name, typ, kind string
+	for iter.Scan(&name, &typ, &kind) {
+		columns = append(columns, &core.Column{
+			Name:       name,
+			Type:       typ,
+			PrimaryKey: kind == "partition_key" || kind == "clustering",
+			NotNull:    kind == "partition_key" || kind == "clustering",
+		})
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+
+	return columns, nil
+}
+
+func (c *cassandraDriver) Structure() ([]*core.Structure, error) {
+	children := make(map[string][]*core.Structure)
+
+	iter := c.session.Query(`SELECT keyspace_name FROM system_schema.keyspaces`).Iter()
+	var keyspace, name string
+	var keyspaces []string
+	for iter.Scan(&keyspace) {
+		keyspaces = append(keyspaces, keyspace)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+
+	iter = c.session.Query(`SELECT keyspace_name, table_name FROM system_schema.tables`).Iter()
+	for iter.Scan(&keyspace, &name) {
+		children[keyspace] = append(children[keyspace], &core.Structure{
+			Name:   name,
+			Schema: keyspace,
+			Type:   core.StructureTypeTable,
+		})
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+
+	iter = c.session.Query(`SELECT keyspace_name, view_name FROM system_schema.views`).Iter()
+	for iter.Scan(&keyspace, &name) {
+		children[keyspace] = append(children[keyspace], &core.Structure{
+			Name:   name,
+			Schema: keyspace,
+			Type:   core.StructureTypeMaterializedView,
+		})
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+
+	var structure []*core.Structure
+	for _, ks := range keyspaces {
+		structure = append(structure, &core.Structure{
+			Name:     ks,
+			Schema:   ks,
+			Type:     core.StructureTypeNone,
+			Children: children[ks],
+		})
+	}
+
+	return structure, nil
+}
+
+func (c *cassandraDriver) IsSystem(node *core.Structure) bool {
+	return cassandraSystemKeyspaces[node.Schema]
+}
+
+func (c *cassandraDriver) Close() {
+	c.session.Close()
+}
@@ -1,6 +1,7 @@
 package adapters
 
 import (
+	"encoding/gob"
 	"fmt"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
@@ -11,6 +12,7 @@ import (
 // Register client
 func init() {
 	_ = register(&Clickhouse{}, "clickhouse")
+	gob.Register(&clickhouseCompositeValue{})
 }
 
 var _ core.Adapter = (*Clickhouse)(nil)
@@ -36,6 +38,7 @@ func (p *Clickhouse) Connect(url string) (core.Driver, error) {
 		c: builders.NewClient(
 			clickhouse.OpenDB(options),
 			builders.WithCustomTypeProcessor("json", jsonProcessor),
+			builders.WithDefaultTypeProcessor(clickhouseValue),
 		),
 		opts: options,
 	}, nil
@@ -1,7 +1,15 @@
 package adapters
 
 import (
+	"bytes"
 	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/kndndrj/nvim-dbee/dbee/core"
@@ -9,8 +17,21 @@ import (
 )
 
 var (
-	_ core.Driver           = (*clickhouseDriver)(nil)
-	_ core.DatabaseSwitcher = (*clickhouseDriver)(nil)
+	_ core.Driver                   = (*clickhouseDriver)(nil)
+	_ core.DatabaseSwitcher         = (*clickhouseDriver)(nil)
+	_ core.ActivityMonitor          = (*clickhouseDriver)(nil)
+	_ core.Planner                  = (*clickhouseDriver)(nil)
+	_ core.Importer                 = (*clickhouseDriver)(nil)
+	_ core.StatementDialectProvider = (*clickhouseDriver)(nil)
+	_ core.TableStatsProvider       = (*clickhouseDriver)(nil)
+	_ core.ServerInfoProvider       = (*clickhouseDriver)(nil)
+	_ core.PartitionLister          = (*clickhouseDriver)(nil)
+	_ core.PartitionManager         = (*clickhouseDriver)(nil)
+	_ core.ObjectActionQuerier      = (*clickhouseDriver)(nil)
+	_ core.SystemObjectClassifier   = (*clickhouseDriver)(nil)
+	_ core.Commenter                = (*clickhouseDriver)(nil)
+	_ core.PoolStatsProvider        = (*clickhouseDriver)(nil)
+	_ core.SampleDialect            = (*clickhouseDriver)(nil)
 )
 
 type clickhouseDriver struct {
@@ -19,13 +40,37 @@ type clickhouseDriver struct {
 }
 
 func (c *clickhouseDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	// collect server logs of the query
+	var mu sync.Mutex
+	var logs []string
+	ctx = clickhouse.Context(ctx,
+		clickhouse.WithSettings(clickhouse.Settings{"send_logs_level": "warning"}),
+		clickhouse.WithLogs(func(l *clickhouse.Log) {
+			mu.Lock()
+			defer mu.Unlock()
+			logs = append(logs, fmt.Sprintf("%s: %s", l.Source, l.Text))
+		}),
+	)
+
 	// run query, fallback to affected rows
-	return c.c.QueryUntilNotEmpty(ctx, query, "select changes() as 'Rows Affected'")
+	rows, err := c.c.QueryUntilNotEmpty(ctx, query, "select changes() as 'Rows Affected'")
+	if err != nil {
+		return nil, err
+	}
+
+	rows.AddCallback(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		meta := rows.Meta()
+		meta.Notices = append(meta.Notices, logs...)
+	})
+
+	return rows, nil
 }
 
 func (c *clickhouseDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
 	return c.c.ColumnsFromQuery(`
-		SELECT name, type
+		SELECT name, type, startsWith(type, 'Nullable('), default_expression, is_in_primary_key, comment
 		FROM system.columns
 		WHERE
 			database='%s' AND
@@ -36,12 +81,15 @@ func (c *clickhouseDriver) Columns(opts *core.TableOptions) ([]*core.Column, err
 func (c *clickhouseDriver) Structure() ([]*core.Structure, error) {
 	query := `
         SELECT
-            table_schema, table_name, table_type
-            FROM information_schema.tables
-            WHERE lower(table_schema) != 'information_schema'
+            t.table_schema, t.table_name,
+            if(s.engine = 'MaterializedView', 'MATERIALIZED VIEW', t.table_type),
+            '', s.comment
+            FROM information_schema.tables t
+            LEFT JOIN system.tables s ON s.database = t.table_schema AND s.name = t.table_name
+            WHERE lower(t.table_schema) != 'information_schema'
         UNION ALL
         SELECT DISTINCT
-            lower(table_schema), lower(table_name), table_type
+            lower(table_schema), lower(table_name), table_type, '', ''
             FROM information_schema.tables
             WHERE lower(table_schema) = 'information_schema'`
 
@@ -50,13 +98,60 @@ func (c *clickhouseDriver) Structure() ([]*core.Structure, error) {
 		return nil, err
 	}
 
-	return getPGStructure(rows)
+	structure, err := getPGStructure(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	c.setRefreshStatus(structure)
+
+	return structure, nil
+}
+
+func (c *clickhouseDriver) IsSystem(node *core.Structure) bool {
+	if node.Type != core.StructureTypeNone {
+		return false
+	}
+	return node.Schema == "system" || strings.EqualFold(node.Schema, "information_schema")
+}
+
+// setRefreshStatus sets the status of refreshable materialized views.
+// system.view_refreshes doesn't exist on older servers, so errors are ignored.
+func (c *clickhouseDriver) setRefreshStatus(structure []*core.Structure) {
+	rows, err := c.Query(context.TODO(), "SELECT database, view, lower(status) FROM system.view_refreshes")
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	status := make(map[[2]string]string)
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil || len(row) < 3 {
+			return
+		}
+		database, _ := row[0].(string)
+		view, _ := row[1].(string)
+		status[[2]string{database, view}], _ = row[2].(string)
+	}
+
+	for _, schema := range structure {
+		for _, child := range schema.Children {
+			if s, ok := status[[2]string{child.Schema, child.Name}]; ok {
+				child.Status = s
+			}
+		}
+	}
 }
 
 func (c *clickhouseDriver) Close() {
 	c.c.Close()
 }
 
+func (c *clickhouseDriver) PoolStats() *core.PoolStats {
+	return c.c.PoolStats()
+}
+
 func (c *clickhouseDriver) ListDatabases() (current string, available []string, err error) {
 	query := `
 		SELECT currentDatabase(), schema_name
@@ -89,3 +184,216 @@ func (c *clickhouseDriver) SelectDatabase(name string) error {
 
 	return nil
 }
+
+func (c *clickhouseDriver) Activity(ctx context.Context) (core.ResultStream, error) {
+	return c.c.Query(ctx, `
+		SELECT query_id, user, address, current_database, elapsed, read_rows, memory_usage, query
+		FROM system.processes
+		WHERE query_id <> queryID()
+		ORDER BY elapsed DESC
+	`)
+}
+
+func (c *clickhouseDriver) KillSession(ctx context.Context, id string) error {
+	return c.c.ExecArgs(ctx, "KILL QUERY WHERE query_id = ?", id)
+}
+
+func (c *clickhouseDriver) TableStats(ctx context.Context) ([]*core.TableStats, error) {
+	return c.c.TableStatsFromQuery(ctx, `
+		SELECT database, table, sum(rows), sum(bytes_on_disk)
+		FROM system.parts
+		WHERE active
+		GROUP BY database, table
+		`)
+}
+
+func (c *clickhouseDriver) ServerInfo(ctx context.Context) (*core.ServerInfo, error) {
+	return c.c.ServerInfoFromQuery(ctx, `
+		SELECT 'version', '', version()
+		UNION ALL
+		SELECT 'uptime', '', toString(uptime())
+		UNION ALL
+		SELECT 'setting', 'timezone', timezone()
+		UNION ALL
+		SELECT 'setting', name, value FROM system.settings
+		WHERE name IN ('max_threads', 'max_memory_usage', 'max_execution_time')
+		`)
+}
+
+// Definition returns CREATE statements of tables and views.
+func (c *clickhouseDriver) Definition(ctx context.Context, opts *core.TableOptions) (string, error) {
+	switch opts.Materialization {
+	case core.StructureTypeTable, core.StructureTypeView, core.StructureTypeMaterializedView:
+		return c.c.DefinitionFromQuery(ctx, 0,
+			"SELECT create_table_query FROM system.tables WHERE database = ? AND name = ?",
+			opts.Schema, opts.Table)
+	default:
+		return "", fmt.Errorf("%w: %s", core.ErrDefinitionNotSupported, opts.Materialization)
+	}
+}
+
+// RefreshMaterializedView refreshes a refreshable materialized view.
+// Regular materialized views are updated on insert and can't be refreshed.
+func (c *clickhouseDriver) RefreshMaterializedView(ctx context.Context, opts *core.TableOptions) error {
+	quote := func(s string) string { return "`" + strings.ReplaceAll(s, "`", "``") + "`" }
+
+	name := quote(opts.Table)
+	if opts.Schema != "" {
+		name = quote(opts.Schema) + "." + name
+	}
+	return c.c.ExecArgs(ctx, "SYSTEM REFRESH VIEW "+name)
+}
+
+// Partitions lists partitions of MergeTree tables by their IDs, the partition
+// key value is used as the expression.
+func (c *clickhouseDriver) Partitions(ctx context.Context, opts *core.TableOptions) ([]*core.Partition, error) {
+	return c.c.PartitionsFromQuery(ctx, `
+		SELECT database, partition_id, any(partition), sum(rows), sum(bytes_on_disk)
+		FROM system.parts
+		WHERE active AND database = ? AND table = ?
+		GROUP BY database, partition_id
+		ORDER BY partition_id
+		`, opts.Schema, opts.Table)
+}
+
+func (c *clickhouseDriver) DetachPartition(ctx context.Context, opts *core.TableOptions, partition *core.Partition) error {
+	return c.alterPartition(ctx, "DETACH", opts, partition)
+}
+
+func (c *clickhouseDriver) DropPartition(ctx context.Context, opts *core.TableOptions, partition *core.Partition) error {
+	return c.alterPartition(ctx, "DROP", opts, partition)
+}
+
+func (c *clickhouseDriver) alterPartition(ctx context.Context, action string, opts *core.TableOptions, partition *core.Partition) error {
+	quote := func(s string) string { return "`" + strings.ReplaceAll(s, "`", "``") + "`" }
+
+	name := quote(opts.Table)
+	if opts.Schema != "" {
+		name = quote(opts.Schema) + "." + name
+	}
+	id := "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(partition.Name) + "'"
+	return c.c.ExecArgs(ctx, fmt.Sprintf("ALTER TABLE %s %s PARTITION ID %s", name, action, id))
+}
+
+func (c *clickhouseDriver) SetComment(ctx context.Context, opts *core.TableOptions, column string, comment string) error {
+	quote := func(s string) string { return "`" + strings.ReplaceAll(s, "`", "``") + "`" }
+
+	name := quote(opts.Table)
+	if opts.Schema != "" {
+		name = quote(opts.Schema) + "." + name
+	}
+	value := "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(comment) + "'"
+
+	if column != "" {
+		return c.c.ExecArgs(ctx, fmt.Sprintf("ALTER TABLE %s COMMENT COLUMN %s %s", name, quote(column), value))
+	}
+	return c.c.ExecArgs(ctx, fmt.Sprintf("ALTER TABLE %s MODIFY COMMENT %s", name, value))
+}
+
+// ObjectActionQuery drops materialized views with DROP VIEW, clickhouse has no
+// DROP MATERIALIZED VIEW statement.
+func (c *clickhouseDriver) ObjectActionQuery(action string, opts *core.TableOptions) (string, bool) {
+	if action != core.ObjectActionDrop || opts.Materialization != core.StructureTypeMaterializedView {
+		return "", false
+	}
+	quote := func(s string) string { return "`" + strings.ReplaceAll(s, "`", "``") + "`" }
+
+	name := quote(opts.Table)
+	if opts.Schema != "" {
+		name = quote(opts.Schema) + "." + name
+	}
+	return "DROP VIEW " + name, true
+}
+
+func (c *clickhouseDriver) Explain(ctx context.Context, query string, analyze bool) (*core.PlanNode, error) {
+	if analyze {
+		return nil, errAnalyzeNotSupported
+	}
+
+	doc, err := queryPlanJSON(ctx, c.c, "EXPLAIN json = 1, description = 1 "+query)
+	if err != nil {
+		return nil, err
+	}
+	return parsePGPlan(doc)
+}
+
+// ImportBatch imports rows with a prepared INSERT statement.
+func (c *clickhouseDriver) ImportBatch(ctx context.Context, table string, columns []string, rows []core.Row) error {
+	schema, name := splitTableName(table)
+	return c.c.InsertBatch(ctx, core.NewDialect(c), schema, name, columns, rows, builders.PlaceholderQuestion)
+}
+
+func (c *clickhouseDriver) StatementDialect() *core.StatementDialect {
+	return clickhouseStatementDialect
+}
+
+// clickhouseValue converts values of clickhouse types which the result can't
+// hold as they are: Nullable(T) values are scanned as pointers, Array, Map
+// and Tuple values as slices, maps and structs, and decimals, uuids and ips
+// as types of their libraries. DateTime64 values are times with their full
+// precision.
+func clickhouseValue(val any) any {
+	v := reflect.ValueOf(val)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+
+	val = v.Interface()
+	switch val := val.(type) {
+	case time.Time:
+		return val
+	case []byte:
+		return string(val)
+	case fmt.Stringer:
+		return val.String()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return &clickhouseCompositeValue{value: b}
+	}
+	return val
+}
+
+// clickhouseCompositeValue is a value of an Array, Map or Tuple column,
+// displayed and exported as json.
+type clickhouseCompositeValue struct {
+	value []byte
+}
+
+func (cv *clickhouseCompositeValue) String() string {
+	return string(cv.value)
+}
+
+func (cv *clickhouseCompositeValue) MarshalJSON() ([]byte, error) {
+	return cv.value, nil
+}
+
+func (cv *clickhouseCompositeValue) GobEncode() ([]byte, error) {
+	w := new(bytes.Buffer)
+	if err := gob.NewEncoder(w).Encode(cv.value); err != nil {
+		return nil, err
+	}
+	return w.Bytes(), nil
+}
+
+func (cv *clickhouseCompositeValue) GobDecode(buf []byte) error {
+	return gob.NewDecoder(bytes.NewBuffer(buf)).Decode(&cv.value)
+}
+
+func (c *clickhouseDriver) SampleSyntax() core.SampleSyntax {
+	return core.SampleSyntax{
+		// SAMPLE works only on tables with a sampling key
+		Random: "rand()",
+	}
+}
@@ -0,0 +1,61 @@
+package adapters
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClickhouseValue(t *testing.T) {
+	r := require.New(t)
+
+	str := "value"
+	var nullStr *string
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	id := uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+
+	// Nullable(T)
+	r.Equal("value", clickhouseValue(&str))
+	r.Nil(clickhouseValue(nullStr))
+	r.Nil(clickhouseValue(nil))
+	// DateTime64(9) keeps its precision
+	r.Equal(ts, clickhouseValue(&ts))
+	// scalars and types of libraries
+	r.Equal(int32(7), clickhouseValue(int32(7)))
+	r.Equal("bytes", clickhouseValue([]byte("bytes")))
+	r.Equal(id.String(), clickhouseValue(id))
+	r.Equal("10.0.0.1", clickhouseValue(net.ParseIP("10.0.0.1")))
+
+	// Array, Map and Tuple
+	for _, tc := range []struct {
+		value    any
+		expected string
+	}{
+		{value: []int32{1, 2, 3}, expected: "[1,2,3]"},
+		{value: []*string{&str, nil}, expected: `["value",null]`},
+		{value: [][]string{{"a"}, {}}, expected: `[["a"],[]]`},
+		{value: map[string]uint8{"k": 1}, expected: `{"k":1}`},
+		{value: []any{"a", int64(1)}, expected: `["a",1]`},
+	} {
+		converted := clickhouseValue(tc.value)
+		r.Equal(tc.expected, fmt.Sprint(converted))
+
+		b, err := json.Marshal(converted)
+		r.NoError(err)
+		r.JSONEq(tc.expected, string(b))
+
+		// composite values are archived
+		var buf bytes.Buffer
+		r.NoError(gob.NewEncoder(&buf).Encode(&converted))
+		var decoded any
+		r.NoError(gob.NewDecoder(&buf).Decode(&decoded))
+		r.Equal(converted, decoded)
+	}
+}
@@ -0,0 +1,251 @@
+//go:build (darwin && (amd64 || arm64)) || (freebsd && (386 || amd64 || arm || arm64)) || (linux && (386 || amd64 || arm || arm64 || ppc64le || riscv64 || s390x)) || (netbsd && amd64) || (openbsd && (amd64 || arm64)) || (windows && (amd64 || arm64))
+
+package adapters
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+// Register client
+func init() {
+	_ = register(&Demo{}, "demo")
+}
+
+var _ core.Adapter = (*Demo)(nil)
+
+const (
+	defaultDemoRows = 1000
+	maxDemoRows     = 1_000_000
+)
+
+// demoDatabases counts demo databases, so every connection gets its own.
+var demoDatabases atomic.Int64
+
+// Demo is an adapter of an in-memory sqlite database filled with generated
+// data, so the plugin can be tried out without a database server. The data is
+// deterministic - the same options always generate the same rows.
+//
+// The url holds options in the form of a query string (e.g. "rows=500&seed=7"):
+//   - rows: number of customers (1000 by default), other tables are sized
+//     relative to it
+//   - seed: seed of the generator (1 by default)
+type Demo struct{}
+
+type demoOptions struct {
+	rows int
+	seed int64
+}
+
+func parseDemoOptions(rawURL string) (*demoOptions, error) {
+	// both "demo://?rows=10" and "rows=10" are accepted
+	if i := strings.IndexByte(rawURL, '?'); i >= 0 {
+		rawURL = rawURL[i+1:]
+	}
+	values, err := url.ParseQuery(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("url.ParseQuery: %w", err)
+	}
+
+	opts := &demoOptions{rows: defaultDemoRows, seed: 1}
+	if rows := values.Get("rows"); rows != "" {
+		opts.rows, err = strconv.Atoi(rows)
+		if err != nil || opts.rows < 1 || opts.rows > maxDemoRows {
+			return nil, fmt.Errorf("invalid number of rows: %q (1 to %d)", rows, maxDemoRows)
+		}
+	}
+	if seed := values.Get("seed"); seed != "" {
+		opts.seed, err = strconv.ParseInt(seed, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid seed: %q", seed)
+		}
+	}
+
+	return opts, nil
+}
+
+func (d *Demo) Connect(rawURL string) (core.Driver, error) {
+	opts, err := parseDemoOptions(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// connections of the pool share the database, which lives as long as one
+	// of them is open
+	name := fmt.Sprintf("file:dbee_demo_%d?mode=memory&cache=shared", demoDatabases.Add(1))
+	db, err := sql.Open("sqlite", name)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open demo database: %v", err)
+	}
+	db.SetConnMaxIdleTime(0)
+	db.SetConnMaxLifetime(0)
+
+	if err := generateDemoData(db, opts); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("generateDemoData: %w", err)
+	}
+
+	return &sqliteDriver{
+		c: builders.NewClient(db),
+	}, nil
+}
+
+func (d *Demo) GetHelpers(opts *core.TableOptions) map[string]string {
+	return new(SQLite).GetHelpers(opts)
+}
+
+var demoSchema = []string{
+	`CREATE TABLE customers (
+		id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		email TEXT NOT NULL UNIQUE,
+		country TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	)`,
+	`CREATE TABLE products (
+		id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		category TEXT NOT NULL,
+		price REAL NOT NULL
+	)`,
+	`CREATE TABLE orders (
+		id INTEGER PRIMARY KEY,
+		customer_id INTEGER NOT NULL REFERENCES customers(id),
+		status TEXT NOT NULL,
+		ordered_at TIMESTAMP NOT NULL
+	)`,
+	`CREATE TABLE order_items (
+		order_id INTEGER NOT NULL REFERENCES orders(id),
+		product_id INTEGER NOT NULL REFERENCES products(id),
+		quantity INTEGER NOT NULL,
+		unit_price REAL NOT NULL,
+		PRIMARY KEY (order_id, product_id)
+	)`,
+	`CREATE INDEX orders_customer_idx ON orders (customer_id)`,
+	`CREATE VIEW customer_totals AS
+		SELECT c.id, c.name, COUNT(DISTINCT o.id) AS orders, ROUND(SUM(i.quantity * i.unit_price), 2) AS total
+		FROM customers c
+		JOIN orders o ON o.customer_id = c.id
+		JOIN order_items i ON i.order_id = o.id
+		GROUP BY c.id, c.name`,
+}
+
+var (
+	demoFirstNames = []string{
+		"Ada", "Alan", "Barbara", "Dennis", "Edsger", "Frances", "Grace", "Guido", "John", "Ken",
+		"Linus", "Margaret", "Niklaus", "Radia", "Rob", "Sophie", "Tim", "Yukihiro", "Žiga", "Zoë",
+	}
+	demoLastNames = []string{
+		"Allen", "Hopper", "Kernighan", "Knuth", "Lamport", "Liskov", "Lovelace", "Perlman", "Pike",
+		"Ritchie", "Rossum", "Thompson", "Torvalds", "Turing", "Wilson", "Wirth", "Matsumoto", "Hamilton",
+	}
+	demoCountries  = []string{"DE", "FR", "GB", "JP", "SI", "US", "BR", "IN", "CA", "AU"}
+	demoCategories = []string{"books", "games", "garden", "kitchen", "music", "office", "sports", "toys"}
+	demoAdjectives = []string{"Classic", "Compact", "Deluxe", "Eco", "Mini", "Pro", "Smart", "Ultra"}
+	demoNouns      = []string{"Blender", "Chair", "Guitar", "Kettle", "Lamp", "Notebook", "Puzzle", "Racket"}
+	demoStatuses   = []string{"pending", "paid", "shipped", "delivered", "canceled"}
+)
+
+// demoEpoch is the start of generated timestamps.
+var demoEpoch = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// generateDemoData creates the demo schema and fills it with rows generated
+// from the seed.
+func generateDemoData(db *sql.DB, opts *demoOptions) error {
+	rnd := rand.New(rand.NewSource(opts.seed))
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, query := range demoSchema {
+		if _, err := tx.Exec(query); err != nil {
+			return fmt.Errorf("tx.Exec: %w", err)
+		}
+	}
+
+	insert := func(query string, count int, args func(i int) []any) error {
+		stmt, err := tx.Prepare(query)
+		if err != nil {
+			return fmt.Errorf("tx.Prepare: %w", err)
+		}
+		defer stmt.Close()
+
+		for i := 1; i <= count; i++ {
+			if _, err := stmt.Exec(args(i)...); err != nil {
+				return fmt.Errorf("stmt.Exec: %w", err)
+			}
+		}
+		return nil
+	}
+	timestamp := func(maxDays int) time.Time {
+		return demoEpoch.Add(time.Duration(rnd.Int63n(int64(maxDays) * int64(24*time.Hour))).Truncate(time.Second))
+	}
+	pick := func(values []string) string {
+		return values[rnd.Intn(len(values))]
+	}
+
+	customers := opts.rows
+	err = insert("INSERT INTO customers VALUES (?, ?, ?, ?, ?)", customers, func(i int) []any {
+		first, last := pick(demoFirstNames), pick(demoLastNames)
+		email := fmt.Sprintf("%s.%s.%d@example.com", strings.ToLower(first), strings.ToLower(last), i)
+		return []any{i, first + " " + last, email, pick(demoCountries), timestamp(365)}
+	})
+	if err != nil {
+		return err
+	}
+
+	products := max(10, customers/10)
+	prices := make([]float64, products+1)
+	err = insert("INSERT INTO products VALUES (?, ?, ?, ?)", products, func(i int) []any {
+		prices[i] = float64(rnd.Intn(20000)+99) / 100
+		return []any{i, pick(demoAdjectives) + " " + pick(demoNouns), pick(demoCategories), prices[i]}
+	})
+	if err != nil {
+		return err
+	}
+
+	orders := customers * 3
+	err = insert("INSERT INTO orders VALUES (?, ?, ?, ?)", orders, func(i int) []any {
+		return []any{i, rnd.Intn(customers) + 1, pick(demoStatuses), timestamp(730)}
+	})
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO order_items VALUES (?, ?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("tx.Prepare: %w", err)
+	}
+	defer stmt.Close()
+	for order := 1; order <= orders; order++ {
+		// distinct products of the order
+		items := rnd.Intn(4) + 1
+		seen := make(map[int]bool, items)
+		for len(seen) < items {
+			product := rnd.Intn(products) + 1
+			if seen[product] {
+				continue
+			}
+			seen[product] = true
+			if _, err := stmt.Exec(order, product, rnd.Intn(5)+1, prices[product]); err != nil {
+				return fmt.Errorf("stmt.Exec: %w", err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
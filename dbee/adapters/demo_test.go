@@ -0,0 +1,67 @@
+package adapters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func demoRows(t *testing.T, driver core.Driver, query string) []core.Row {
+	t.Helper()
+	r := require.New(t)
+
+	stream, err := driver.Query(context.Background(), query)
+	r.NoError(err)
+	defer stream.Close()
+
+	var rows []core.Row
+	for stream.HasNext() {
+		row, err := stream.Next()
+		r.NoError(err)
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestDemo(t *testing.T) {
+	r := require.New(t)
+
+	driver, err := new(Demo).Connect("demo://?rows=50&seed=7")
+	r.NoError(err)
+	defer driver.Close()
+
+	r.Equal([]core.Row{{int64(50), int64(10), int64(150)}}, demoRows(t, driver,
+		"SELECT (SELECT COUNT(*) FROM customers), (SELECT COUNT(*) FROM products), (SELECT COUNT(*) FROM orders)"))
+
+	structure, err := driver.Structure()
+	r.NoError(err)
+	var names []string
+	for _, s := range structure {
+		names = append(names, s.Name)
+		for _, child := range s.Children {
+			names = append(names, child.Name)
+		}
+	}
+	r.Subset(names, []string{"customers", "products", "orders", "order_items"})
+	r.NotEmpty(demoRows(t, driver, "SELECT * FROM customer_totals"))
+
+	// the same options generate the same data, other connections have their own databases
+	same, err := new(Demo).Connect("rows=50&seed=7")
+	r.NoError(err)
+	defer same.Close()
+	other, err := new(Demo).Connect("rows=50&seed=8")
+	r.NoError(err)
+	defer other.Close()
+
+	query := "SELECT * FROM customers ORDER BY id LIMIT 5"
+	r.Equal(demoRows(t, driver, query), demoRows(t, same, query))
+	r.NotEqual(demoRows(t, driver, query), demoRows(t, other, query))
+
+	_, err = new(Demo).Connect("rows=0")
+	r.Error(err)
+	_, err = new(Demo).Connect("seed=x")
+	r.Error(err)
+}
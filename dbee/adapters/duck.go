@@ -1,8 +1,9 @@
-//go:build cgo && ((darwin && (amd64 || arm64)) || (linux && (amd64 || arm64 || riscv64)))
+//go:build cgo && !goplugin && ((darwin && (amd64 || arm64)) || (linux && (amd64 || arm64 || riscv64)))
 
 package adapters
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -21,21 +22,38 @@ var _ core.Adapter = (*Duck)(nil)
 
 type Duck struct{}
 
+// Connect opens a database file (e.g. "analytics.duckdb" or
+// "duckdb:///data/analytics.duckdb?access_mode=read_only"), an in-memory
+// database (":memory:" or empty) or a data file. Data files (parquet, csv and
+// json, globs are allowed) are queried by an in-memory database which exposes
+// them as a view named after the file.
 func (d *Duck) Connect(url string) (core.Driver, error) {
-	db, err := sql.Open("duckdb", url)
+	source := parseDuckURL(url)
+
+	db, err := sql.Open("duckdb", source.dsn)
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to duckdb database: %v", err)
 	}
 
+	if source.file != "" {
+		_, err := db.ExecContext(context.Background(), duckFileView(source.file))
+		if err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("unable to read %q: %v", source.file, err)
+		}
+	}
+
 	return &duckDriver{
 		c: builders.NewClient(db),
 	}, nil
 }
 
 func (*Duck) GetHelpers(opts *core.TableOptions) map[string]string {
+	name := duckQualifiedName(opts.Schema, opts.Table)
 	return map[string]string{
-		"List":        fmt.Sprintf("SELECT * FROM %q LIMIT 500", opts.Table),
-		"Columns":     fmt.Sprintf("DESCRIBE %q", opts.Table),
+		"List":        fmt.Sprintf("SELECT * FROM %s LIMIT 500", name),
+		"Columns":     fmt.Sprintf("DESCRIBE %s", name),
+		"Summarize":   fmt.Sprintf("SUMMARIZE %s", name),
 		"Indexes":     fmt.Sprintf("SELECT * FROM duckdb_indexes() WHERE table_name = '%s'", opts.Table),
 		"Constraints": fmt.Sprintf("SELECT * FROM duckdb_constraints() WHERE table_name = '%s'", opts.Table),
 	}
@@ -2,12 +2,23 @@ package adapters
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 )
 
-var _ core.Driver = (*duckDriver)(nil)
+var (
+	_ core.Driver                   = (*duckDriver)(nil)
+	_ core.StatementDialectProvider = (*duckDriver)(nil)
+	_ core.JSONPathDialect          = (*duckDriver)(nil)
+	_ core.PoolStatsProvider        = (*duckDriver)(nil)
+	_ core.SampleDialect            = (*duckDriver)(nil)
+)
 
 type duckDriver struct {
 	c *builders.Client
@@ -18,36 +29,199 @@ func (c *duckDriver) Query(ctx context.Context, query string) (core.ResultStream
 }
 
 func (c *duckDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
-	return c.c.ColumnsFromQuery("DESCRIBE %q", opts.Table)
+	database, schema := splitDuckSchema(opts.Schema)
+	if schema == "" {
+		schema = "main"
+	}
+	return c.c.ColumnsFromQuery(`
+		SELECT column_name, data_type, is_nullable, column_default
+		FROM information_schema.columns
+		WHERE table_name = '%s' AND table_schema = '%s'
+			AND table_catalog = coalesce(nullif('%s', ''), current_database())
+		ORDER BY ordinal_position
+		`, opts.Table, schema, database)
 }
 
+// Structure lists schemas of the current database and attached databases
+// with their schemas. Tables of attached databases are qualified by the
+// database (e.g. schema "other.main").
 func (c *duckDriver) Structure() ([]*core.Structure, error) {
-	query := `SHOW TABLES;`
+	query := `
+		SELECT database_name, schema_name, table_name, 'TABLE', database_name = current_database()
+			FROM duckdb_tables() WHERE NOT internal AND NOT temporary
+		UNION ALL
+		SELECT database_name, schema_name, view_name, 'VIEW', database_name = current_database()
+			FROM duckdb_views() WHERE NOT internal AND NOT temporary
+		ORDER BY 1, 2, 3`
 
 	rows, err := c.Query(context.TODO(), query)
 	if err != nil {
 		return nil, err
 	}
 
-	var schema []*core.Structure
+	var structure []*core.Structure
+	databases := make(map[string]*core.Structure)
+	schemas := make(map[string]*core.Structure)
 	for rows.HasNext() {
 		row, err := rows.Next()
 		if err != nil {
 			return nil, err
 		}
+		if len(row) < 5 {
+			return nil, errors.New("could not retrieve structure: insufficient info")
+		}
+
+		database, _ := row[0].(string)
+		schemaName, _ := row[1].(string)
+		table, _ := row[2].(string)
+		typ, _ := row[3].(string)
+		current, _ := row[4].(bool)
+
+		qualified := schemaName
+		if !current {
+			qualified = database + "." + schemaName
+		}
 
-		// We know for a fact there is only one string field (see query above)
-		table := row[0].(string)
-		schema = append(schema, &core.Structure{
+		schema, ok := schemas[qualified]
+		if !ok {
+			schema = &core.Structure{
+				Name:   schemaName,
+				Schema: qualified,
+				Type:   core.StructureTypeNone,
+			}
+			schemas[qualified] = schema
+
+			if current {
+				structure = append(structure, schema)
+			} else {
+				db, ok := databases[database]
+				if !ok {
+					db = &core.Structure{
+						Name: database,
+						Type: core.StructureTypeNone,
+					}
+					databases[database] = db
+					structure = append(structure, db)
+				}
+				db.Children = append(db.Children, schema)
+			}
+		}
+
+		schema.Children = append(schema.Children, &core.Structure{
 			Name:   table,
-			Schema: "",
-			Type:   core.StructureTypeTable,
+			Schema: qualified,
+			Type:   getPGStructureType(typ),
 		})
 	}
 
-	return schema, nil
+	return structure, nil
 }
 
 func (c *duckDriver) Close() {
 	c.c.Close()
 }
+
+func (c *duckDriver) PoolStats() *core.PoolStats {
+	return c.c.PoolStats()
+}
+
+func (c *duckDriver) StatementDialect() *core.StatementDialect {
+	return postgresStatementDialect
+}
+
+func (c *duckDriver) JSONPathSyntax() core.JSONPathSyntax {
+	return core.JSONPathSyntaxExtract
+}
+
+// duckSource is a parsed duckdb connection url.
+type duckSource struct {
+	// data source name of the database
+	dsn string
+	// data file exposed as a view (empty if the url is a database)
+	file string
+}
+
+// duckFileReaders are table functions which read data files by extension.
+var duckFileReaders = map[string]string{
+	".parquet": "read_parquet",
+	".csv":     "read_csv_auto",
+	".tsv":     "read_csv_auto",
+	".json":    "read_json_auto",
+	".jsonl":   "read_json_auto",
+	".ndjson":  "read_json_auto",
+}
+
+// parseDuckURL parses the url of a duckdb connection: a path (with an
+// optional "duckdb://" or "file:" prefix and query options), ":memory:" or
+// a data file, which is read by an in-memory database.
+func parseDuckURL(rawURL string) *duckSource {
+	dsn := strings.TrimSpace(rawURL)
+	for _, prefix := range []string{"duckdb://", "duck://", "file://", "file:"} {
+		if strings.HasPrefix(dsn, prefix) {
+			dsn = strings.TrimPrefix(dsn, prefix)
+			break
+		}
+	}
+	if dsn == ":memory:" {
+		dsn = ""
+	}
+
+	path, _, _ := strings.Cut(dsn, "?")
+	path, err := url.PathUnescape(path)
+	if err != nil {
+		path, _, _ = strings.Cut(dsn, "?")
+	}
+	if _, ok := duckFileReaders[strings.ToLower(filepath.Ext(path))]; ok {
+		return &duckSource{file: path}
+	}
+
+	return &duckSource{dsn: dsn}
+}
+
+// duckFileView returns the statement which creates a view of the data file.
+// The view is named after the file, or after its directory if the path is a
+// glob (e.g. "logs" for "logs/*.parquet").
+func duckFileView(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if strings.ContainsAny(name, "*?[") {
+		name = filepath.Base(filepath.Dir(path))
+	}
+
+	reader := duckFileReaders[strings.ToLower(filepath.Ext(path))]
+	return fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s('%s')",
+		duckQualifiedName("", name), reader, strings.ReplaceAll(path, "'", "''"))
+}
+
+// splitDuckSchema splits the schema of a structure node to the database and
+// the schema (e.g. "other.main").
+func splitDuckSchema(schema string) (database, name string) {
+	if db, rest, ok := strings.Cut(schema, "."); ok {
+		return db, rest
+	}
+	return "", schema
+}
+
+// duckQualifiedName returns the quoted name of the table, qualified by the
+// schema and the database (if any).
+func duckQualifiedName(schema, table string) string {
+	quote := func(s string) string {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+
+	var parts []string
+	database, schema := splitDuckSchema(schema)
+	if database != "" {
+		parts = append(parts, quote(database))
+	}
+	if schema != "" {
+		parts = append(parts, quote(schema))
+	}
+	return strings.Join(append(parts, quote(table)), ".")
+}
+
+func (c *duckDriver) SampleSyntax() core.SampleSyntax {
+	return core.SampleSyntax{
+		TableSample: "TABLESAMPLE %s%%",
+		Random:      "random()",
+	}
+}
@@ -0,0 +1,77 @@
+//go:build cgo && !goplugin && ((darwin && (amd64 || arm64)) || (linux && (amd64 || arm64 || riscv64)))
+
+package adapters
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestParseDuckURL(t *testing.T) {
+	testCases := []struct {
+		url  string
+		dsn  string
+		file string
+	}{
+		{url: "", dsn: ""},
+		{url: ":memory:", dsn: ""},
+		{url: "analytics.duckdb", dsn: "analytics.duckdb"},
+		{url: "duckdb:///data/analytics.duckdb?access_mode=read_only", dsn: "/data/analytics.duckdb?access_mode=read_only"},
+		{url: "file:events.parquet", file: "events.parquet"},
+		{url: "/data/logs/*.csv", file: "/data/logs/*.csv"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.url, func(t *testing.T) {
+			source := parseDuckURL(tc.url)
+			require.Equal(t, &duckSource{dsn: tc.dsn, file: tc.file}, source)
+		})
+	}
+
+	require.Equal(t, `CREATE VIEW "logs" AS SELECT * FROM read_csv_auto('/data/logs/*.csv')`, duckFileView("/data/logs/*.csv"))
+	require.Equal(t, `"other"."main"."t"`, duckQualifiedName("other.main", "t"))
+}
+
+func TestDuck_File(t *testing.T) {
+	r := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "people.csv")
+	r.NoError(os.WriteFile(path, []byte("id,name\n1,ann\n2,bob\n"), 0o644))
+
+	driver, err := new(Duck).Connect(path)
+	r.NoError(err)
+	defer driver.Close()
+
+	rows, err := driver.Query(context.Background(), "SELECT name FROM people ORDER BY id")
+	r.NoError(err)
+	var names []any
+	for rows.HasNext() {
+		row, err := rows.Next()
+		r.NoError(err)
+		names = append(names, row[0])
+	}
+	r.Equal([]any{"ann", "bob"}, names)
+
+	// attached databases are listed after schemas of the current one
+	r.NoError(driver.(*duckDriver).c.ExecArgs(context.Background(), "ATTACH ':memory:' AS other"))
+	r.NoError(driver.(*duckDriver).c.ExecArgs(context.Background(), "CREATE TABLE other.main.t (a INT)"))
+
+	structure, err := driver.Structure()
+	r.NoError(err)
+	r.Len(structure, 2)
+	r.Equal("main", structure[0].Name)
+	r.Equal(&core.Structure{Name: "people", Schema: "main", Type: core.StructureTypeView}, structure[0].Children[0])
+	r.Equal("other", structure[1].Name)
+	r.Equal(&core.Structure{Name: "t", Schema: "other.main", Type: core.StructureTypeTable}, structure[1].Children[0].Children[0])
+
+	columns, err := driver.Columns(&core.TableOptions{Schema: "other.main", Table: "t"})
+	r.NoError(err)
+	r.Len(columns, 1)
+	r.Equal("a", columns[0].Name)
+}
@@ -0,0 +1,313 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	nurl "net/url"
+	"strings"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// Register client
+func init() {
+	_ = register(&Elastic{}, "elasticsearch", "elastic", "opensearch")
+}
+
+var _ core.Adapter = (*Elastic)(nil)
+
+type Elastic struct{}
+
+// Connect connects to the cluster of the url:
+//
+//	elasticsearch://[user:password@]host[:port][?options]
+//	opensearch://[user:password@]host[:port][?options]
+//
+// Supported "options" are:
+//   - api_key=key authenticates with the api key instead of user and password.
+//   - ssl=true connects over https, ssl_insecure=true skips verification of
+//     the certificate.
+//   - fetch_size=1000 sets the number of rows of sql result pages.
+//   - timeout=30s sets the timeout of requests.
+//
+// Queries are sent to the sql endpoint of the cluster, except for requests of
+// the search dsl, which are either a json body sent to _search or a request
+// line followed by the body (e.g. "GET /logs/_search {...}").
+func (e *Elastic) Connect(url string) (core.Driver, error) {
+	client, err := parseElasticURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &elasticDriver{
+		c: client,
+	}, nil
+}
+
+func (*Elastic) GetHelpers(opts *core.TableOptions) map[string]string {
+	return map[string]string{
+		"List": fmt.Sprintf(`SELECT * FROM %s LIMIT 500`, elasticQuote(opts.Table)),
+		"Search": fmt.Sprintf(`GET /%s/_search
+{
+  "size": 500,
+  "query": { "match_all": {} }
+}`, nurl.PathEscape(opts.Table)),
+		"Mapping":  fmt.Sprintf("GET /%s/_mapping", nurl.PathEscape(opts.Table)),
+		"Settings": fmt.Sprintf("GET /%s/_settings", nurl.PathEscape(opts.Table)),
+		"Count":    fmt.Sprintf("GET /%s/_count", nurl.PathEscape(opts.Table)),
+	}
+}
+
+// elasticClient sends requests to the rest api of the cluster.
+type elasticClient struct {
+	http    *http.Client
+	baseURL string
+	// opensearch serves sql under a different path and format
+	openSearch bool
+	apiKey     string
+	user       *nurl.Userinfo
+	fetchSize  int64
+}
+
+// parseElasticURL parses the url to the client of the cluster.
+func parseElasticURL(rawURL string) (*elasticClient, error) {
+	u, err := nurl.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse db connection string: %w", err)
+	}
+	switch u.Scheme {
+	case "elasticsearch", "elastic", "opensearch":
+	default:
+		return nil, fmt.Errorf("unexpected scheme: %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("no host in url")
+	}
+
+	params := u.Query()
+
+	var ssl, insecure bool
+	if err := setBoolOption(&ssl, "ssl", params); err != nil {
+		return nil, err
+	}
+	if err := setBoolOption(&insecure, "ssl_insecure", params); err != nil {
+		return nil, err
+	}
+	timeout := time.Minute
+	if err := setOption(&timeout, "timeout", params, time.ParseDuration); err != nil {
+		return nil, err
+	}
+
+	c := &elasticClient{
+		http:       &http.Client{Timeout: timeout},
+		openSearch: u.Scheme == "opensearch",
+		apiKey:     params.Get("api_key"),
+		user:       u.User,
+		fetchSize:  1000,
+	}
+	if err := setInt64Option(&c.fetchSize, "fetch_size", params); err != nil {
+		return nil, err
+	}
+	if c.fetchSize < 1 {
+		return nil, fmt.Errorf("invalid value for \"fetch_size\": %d", c.fetchSize)
+	}
+	if insecure {
+		c.http.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+		}
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		host += ":9200"
+	}
+	scheme := "http"
+	if ssl {
+		scheme = "https"
+	}
+	c.baseURL = scheme + "://" + host
+
+	return c, nil
+}
+
+// do sends the request to the path and decodes the json response to out.
+func (c *elasticClient) do(ctx context.Context, method, path string, body []byte, out any) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	switch {
+	case c.apiKey != "":
+		req.Header.Set("Authorization", "ApiKey "+c.apiKey)
+	case c.user != nil:
+		password, _ := c.user.Password()
+		req.SetBasicAuth(c.user.Username(), password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return elasticResponseError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(out); err != nil {
+		return fmt.Errorf("could not parse response: %w", err)
+	}
+	return nil
+}
+
+// elasticResponseError returns the error message of a failed request.
+func elasticResponseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	var msg struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(body, &msg); err == nil && len(msg.Error) > 0 {
+		var reason string
+		if err := json.Unmarshal(msg.Error, &reason); err == nil {
+			return fmt.Errorf("elasticsearch: %s", reason)
+		}
+
+		var detail struct {
+			Type    string `json:"type"`
+			Reason  string `json:"reason"`
+			Details string `json:"details"`
+		}
+		if err := json.Unmarshal(msg.Error, &detail); err == nil && detail.Reason != "" {
+			reason = detail.Reason
+			if detail.Details != "" {
+				reason += ": " + detail.Details
+			}
+			if detail.Type != "" {
+				return fmt.Errorf("elasticsearch: %s: %s", detail.Type, reason)
+			}
+			return fmt.Errorf("elasticsearch: %s", reason)
+		}
+	}
+	if text := strings.TrimSpace(string(body)); text != "" {
+		return fmt.Errorf("elasticsearch: %s: %s", resp.Status, text)
+	}
+	return fmt.Errorf("elasticsearch: %s", resp.Status)
+}
+
+// elasticSQLPage is a page of rows of a sql query.
+type elasticSQLPage struct {
+	Columns []elasticSQLColumn `json:"columns"`
+	Rows    [][]any            `json:"rows"`
+	Cursor  string             `json:"cursor"`
+
+	// opensearch names of columns and rows
+	Schema   []elasticSQLColumn `json:"schema"`
+	DataRows [][]any            `json:"datarows"`
+}
+
+type elasticSQLColumn struct {
+	Name  string `json:"name"`
+	Alias string `json:"alias"`
+	Type  string `json:"type"`
+}
+
+// header returns names of the columns of the page.
+func (p *elasticSQLPage) header() core.Header {
+	columns := p.Columns
+	if p.Schema != nil {
+		columns = p.Schema
+	}
+
+	header := make(core.Header, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+		if col.Alias != "" {
+			header[i] = col.Alias
+		}
+	}
+	return header
+}
+
+func (p *elasticSQLPage) rows() [][]any {
+	if p.DataRows != nil {
+		return p.DataRows
+	}
+	return p.Rows
+}
+
+func (c *elasticClient) sqlPath() string {
+	if c.openSearch {
+		return "/_plugins/_sql"
+	}
+	return "/_sql?format=json"
+}
+
+// sql sends the query to the sql endpoint and returns the first page.
+func (c *elasticClient) sql(ctx context.Context, query string) (*elasticSQLPage, error) {
+	body, err := json.Marshal(map[string]any{
+		"query":      query,
+		"fetch_size": c.fetchSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var page elasticSQLPage
+	if err := c.do(ctx, http.MethodPost, c.sqlPath(), body, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// sqlNext returns the page of the cursor.
+func (c *elasticClient) sqlNext(ctx context.Context, cursor string) (*elasticSQLPage, error) {
+	body, err := json.Marshal(map[string]any{"cursor": cursor})
+	if err != nil {
+		return nil, err
+	}
+
+	var page elasticSQLPage
+	if err := c.do(ctx, http.MethodPost, c.sqlPath(), body, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// sqlClose releases the cursor of a query which wasn't read to the end.
+func (c *elasticClient) sqlClose(cursor string) error {
+	body, err := json.Marshal(map[string]any{"cursor": cursor})
+	if err != nil {
+		return err
+	}
+
+	path := "/_sql/close"
+	if c.openSearch {
+		path = "/_plugins/_sql/close"
+	}
+	return c.do(context.Background(), http.MethodPost, path, body, nil)
+}
+
+// elasticQuote quotes the identifier of a sql query.
+func elasticQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
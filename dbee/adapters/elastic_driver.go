@@ -0,0 +1,413 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	nurl "net/url"
+	"sort"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var (
+	_ core.Driver                 = (*elasticDriver)(nil)
+	_ core.SystemObjectClassifier = (*elasticDriver)(nil)
+)
+
+// elasticMethods are methods of request lines of the search dsl.
+var elasticMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodDelete: true,
+	http.MethodHead:   true,
+}
+
+type elasticDriver struct {
+	c *elasticClient
+}
+
+func (c *elasticDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	query = strings.TrimSpace(query)
+
+	if method, path, body, ok := parseElasticRequest(query); ok {
+		return c.request(ctx, method, path, body)
+	}
+
+	// sql doesn't accept the terminator
+	return c.sql(ctx, strings.TrimSuffix(query, ";"))
+}
+
+// parseElasticRequest parses a request of the search dsl: either a json body
+// of a search of all indices or a request line followed by the body (if any).
+// Queries which aren't requests are sql.
+func parseElasticRequest(query string) (method, path string, body []byte, ok bool) {
+	if strings.HasPrefix(query, "{") {
+		return http.MethodPost, "/_search", []byte(query), true
+	}
+
+	line, rest, _ := strings.Cut(query, "\n")
+	fields := strings.Fields(line)
+	if len(fields) != 2 || !elasticMethods[strings.ToUpper(fields[0])] {
+		return "", "", nil, false
+	}
+	// e.g. "DELETE FROM logs" is sql
+	if strings.EqualFold(fields[1], "FROM") {
+		return "", "", nil, false
+	}
+
+	path = fields[1]
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if rest = strings.TrimSpace(rest); rest != "" {
+		body = []byte(rest)
+	}
+	return strings.ToUpper(fields[0]), path, body, true
+}
+
+// request sends the request of the search dsl and flattens the response:
+// hits of searches become rows of their source documents, other responses
+// become a single row (or a row per item of an array).
+func (c *elasticDriver) request(ctx context.Context, method, path string, body []byte) (core.ResultStream, error) {
+	var resp any
+	if err := c.c.do(ctx, method, path, body, &resp); err != nil {
+		return nil, err
+	}
+
+	header, rows, meta := flattenElasticResponse(resp)
+	return sliceResultStream(header, rows, meta), nil
+}
+
+// sql runs the query on the sql endpoint and fetches the next page of the
+// cursor only after all rows of the previous one were read.
+func (c *elasticDriver) sql(ctx context.Context, query string) (core.ResultStream, error) {
+	page, err := c.c.sql(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := page.rows()
+	cursor := page.Cursor
+	index := 0
+	var pageErr error
+
+	hasNext := func() bool {
+		for index >= len(rows) && cursor != "" && pageErr == nil {
+			page, err := c.c.sqlNext(ctx, cursor)
+			if err != nil {
+				pageErr = err
+				break
+			}
+			rows, cursor, index = page.rows(), page.Cursor, 0
+		}
+		return index < len(rows) || pageErr != nil
+	}
+	next := func() (core.Row, error) {
+		if !hasNext() {
+			return nil, errors.New("no next row")
+		}
+		if pageErr != nil {
+			err := pageErr
+			pageErr, cursor = nil, ""
+			return nil, err
+		}
+
+		row := make(core.Row, len(rows[index]))
+		for i, v := range rows[index] {
+			row[i] = elasticValue(v)
+		}
+		index++
+		return row, nil
+	}
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(page.header()).
+		WithCloseFunc(func() {
+			if cursor != "" {
+				_ = c.c.sqlClose(cursor)
+			}
+		}).
+		Build(), nil
+}
+
+// elasticValue converts values of json responses: numbers to integers or
+// floats and objects and arrays to json.
+func elasticValue(val any) any {
+	switch v := val.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case map[string]any, []any:
+		out, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(out)
+	default:
+		return val
+	}
+}
+
+// elasticDocuments collects flattened documents with columns in order of
+// their first appearance.
+type elasticDocuments struct {
+	header core.Header
+	index  map[string]int
+	rows   []core.Row
+}
+
+// add adds the document as a row, nested objects are flattened to columns of
+// dotted names (e.g. "user.name").
+func (d *elasticDocuments) add(doc map[string]any) {
+	if d.index == nil {
+		d.index = make(map[string]int)
+	}
+
+	row := make(core.Row, len(d.header))
+	var flatten func(prefix string, obj map[string]any)
+	flatten = func(prefix string, obj map[string]any) {
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		// json objects are decoded to maps, which lose the order of keys
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			name := prefix + key
+			if nested, ok := obj[key].(map[string]any); ok && len(nested) > 0 {
+				flatten(name+".", nested)
+				continue
+			}
+
+			i, ok := d.index[name]
+			if !ok {
+				i = len(d.header)
+				d.index[name] = i
+				d.header = append(d.header, name)
+				row = append(row, nil)
+			}
+			row[i] = elasticValue(obj[key])
+		}
+	}
+	flatten("", doc)
+
+	d.rows = append(d.rows, row)
+}
+
+// result returns the header and rows extended to all columns.
+func (d *elasticDocuments) result() (core.Header, []core.Row) {
+	for i, row := range d.rows {
+		if len(row) < len(d.header) {
+			d.rows[i] = append(row, make(core.Row, len(d.header)-len(row))...)
+		}
+	}
+	return d.header, d.rows
+}
+
+// flattenElasticResponse flattens the json response of a request.
+func flattenElasticResponse(resp any) (core.Header, []core.Row, *core.Meta) {
+	meta := &core.Meta{
+		SchemaType: core.SchemaLess,
+	}
+	var docs elasticDocuments
+
+	switch r := resp.(type) {
+	case []any:
+		// e.g. responses of cat apis with format=json
+		for _, item := range r {
+			if obj, ok := item.(map[string]any); ok {
+				docs.add(obj)
+			} else {
+				docs.add(map[string]any{"value": item})
+			}
+		}
+	case map[string]any:
+		hits, ok := elasticHits(r)
+		if !ok {
+			docs.add(r)
+			break
+		}
+		if total, ok := elasticTotal(r); ok {
+			meta.Notices = append(meta.Notices, total)
+		}
+		if len(hits) < 1 {
+			// aggregations of searches without hits (e.g. "size": 0)
+			if aggs, ok := r["aggregations"].(map[string]any); ok {
+				docs.add(aggs)
+			}
+			break
+		}
+		for _, hit := range hits {
+			doc := map[string]any{
+				"_index": hit["_index"],
+				"_id":    hit["_id"],
+				"_score": hit["_score"],
+			}
+			if source, ok := hit["_source"].(map[string]any); ok {
+				for key, value := range source {
+					doc[key] = value
+				}
+			}
+			docs.add(doc)
+		}
+	default:
+		docs.add(map[string]any{"value": resp})
+	}
+
+	header, rows := docs.result()
+	return header, rows, meta
+}
+
+// elasticHits returns hits of a search response.
+func elasticHits(resp map[string]any) ([]map[string]any, bool) {
+	outer, ok := resp["hits"].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	inner, ok := outer["hits"].([]any)
+	if !ok {
+		return nil, false
+	}
+
+	hits := make([]map[string]any, 0, len(inner))
+	for _, h := range inner {
+		if hit, ok := h.(map[string]any); ok {
+			hits = append(hits, hit)
+		}
+	}
+	return hits, true
+}
+
+// elasticTotal returns the total number of hits of a search response.
+func elasticTotal(resp map[string]any) (string, bool) {
+	outer, _ := resp["hits"].(map[string]any)
+	switch total := outer["total"].(type) {
+	case json.Number:
+		return fmt.Sprintf("%s hits", total), true
+	case map[string]any:
+		if total["relation"] == "gte" {
+			return fmt.Sprintf("at least %v hits", total["value"]), true
+		}
+		return fmt.Sprintf("%v hits", total["value"]), true
+	default:
+		return "", false
+	}
+}
+
+// elasticMapping is the mapping of a field.
+type elasticMapping struct {
+	Type       string                    `json:"type"`
+	Properties map[string]elasticMapping `json:"properties"`
+	// multi-fields (e.g. keyword of a text field)
+	Fields map[string]elasticMapping `json:"fields"`
+}
+
+// Columns lists fields of the index mapping, fields of objects have dotted
+// names (e.g. "user.name").
+func (c *elasticDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	var resp map[string]struct {
+		Mappings elasticMapping `json:"mappings"`
+	}
+	if err := c.c.do(context.Background(), http.MethodGet, "/"+nurl.PathEscape(opts.Table)+"/_mapping", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	// aliases and patterns resolve to multiple indices
+	types := make(map[string]string)
+	for _, index := range resp {
+		flattenElasticMapping("", index.Mappings.Properties, types)
+	}
+
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	columns := make([]*core.Column, len(names))
+	for i, name := range names {
+		columns[i] = &core.Column{
+			Name: name,
+			Type: types[name],
+		}
+	}
+	return columns, nil
+}
+
+// flattenElasticMapping collects types of fields of the properties.
+func flattenElasticMapping(prefix string, properties map[string]elasticMapping, types map[string]string) {
+	for name, field := range properties {
+		name = prefix + name
+		if field.Type != "" {
+			types[name] = field.Type
+		}
+		for sub, multi := range field.Fields {
+			types[name+"."+sub] = multi.Type
+		}
+		flattenElasticMapping(name+".", field.Properties, types)
+	}
+}
+
+// Structure lists indices (including hidden ones) and aliases.
+func (c *elasticDriver) Structure() ([]*core.Structure, error) {
+	ctx := context.Background()
+
+	var indices []struct {
+		Index string `json:"index"`
+	}
+	if err := c.c.do(ctx, http.MethodGet, "/_cat/indices?format=json&h=index&s=index&expand_wildcards=all", nil, &indices); err != nil {
+		return nil, err
+	}
+
+	var aliases []struct {
+		Alias string `json:"alias"`
+	}
+	if err := c.c.do(ctx, http.MethodGet, "/_cat/aliases?format=json&h=alias&s=alias", nil, &aliases); err != nil {
+		return nil, err
+	}
+
+	var structure []*core.Structure
+	for _, index := range indices {
+		structure = append(structure, &core.Structure{
+			Name: index.Index,
+			Type: core.StructureTypeTable,
+		})
+	}
+
+	seen := make(map[string]bool)
+	for _, alias := range aliases {
+		// aliases are listed once per index
+		if seen[alias.Alias] {
+			continue
+		}
+		seen[alias.Alias] = true
+		structure = append(structure, &core.Structure{
+			Name: alias.Alias,
+			Type: core.StructureTypeView,
+		})
+	}
+
+	return structure, nil
+}
+
+// IsSystem reports hidden indices and aliases (e.g. .kibana or .security).
+func (c *elasticDriver) IsSystem(node *core.Structure) bool {
+	return strings.HasPrefix(node.Name, ".")
+}
+
+func (c *elasticDriver) Close() {
+	c.c.http.CloseIdleConnections()
+}
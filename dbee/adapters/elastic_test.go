@@ -0,0 +1,204 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestParseElasticURL(t *testing.T) {
+	r := require.New(t)
+
+	c, err := parseElasticURL("elasticsearch://elastic:pw@search")
+	r.NoError(err)
+	r.Equal("http://search:9200", c.baseURL)
+	r.False(c.openSearch)
+	r.Equal(int64(1000), c.fetchSize)
+	r.Equal("elastic", c.user.Username())
+
+	c, err = parseElasticURL("opensearch://search:443?ssl=true&ssl_insecure=true&api_key=secret&fetch_size=50&timeout=5s")
+	r.NoError(err)
+	r.Equal("https://search:443", c.baseURL)
+	r.True(c.openSearch)
+	r.Equal("secret", c.apiKey)
+	r.Equal(int64(50), c.fetchSize)
+	r.Equal(5*time.Second, c.http.Timeout)
+	r.NotNil(c.http.Transport)
+
+	for _, url := range []string{
+		"postgres://search",
+		"elasticsearch:///logs",
+		"elasticsearch://search?ssl=maybe",
+		"elasticsearch://search?fetch_size=0",
+		"elasticsearch://search?timeout=soon",
+	} {
+		_, err := parseElasticURL(url)
+		r.Error(err, url)
+	}
+}
+
+func TestParseElasticRequest(t *testing.T) {
+	r := require.New(t)
+
+	method, path, body, ok := parseElasticRequest(`{"query": {"match_all": {}}}`)
+	r.True(ok)
+	r.Equal("POST", method)
+	r.Equal("/_search", path)
+	r.Equal(`{"query": {"match_all": {}}}`, string(body))
+
+	method, path, body, ok = parseElasticRequest("get logs/_search\n{\n  \"size\": 1\n}")
+	r.True(ok)
+	r.Equal("GET", method)
+	r.Equal("/logs/_search", path)
+	r.Equal("{\n  \"size\": 1\n}", string(body))
+
+	method, path, body, ok = parseElasticRequest("GET /_cat/indices?format=json")
+	r.True(ok)
+	r.Equal("GET", method)
+	r.Equal("/_cat/indices?format=json", path)
+	r.Nil(body)
+
+	for _, query := range []string{
+		"SELECT * FROM logs",
+		"DELETE FROM logs",
+		"SHOW TABLES",
+	} {
+		_, _, _, ok := parseElasticRequest(query)
+		r.False(ok, query)
+	}
+}
+
+func TestFlattenElasticResponse(t *testing.T) {
+	r := require.New(t)
+
+	decode := func(s string) any {
+		dec := json.NewDecoder(strings.NewReader(s))
+		dec.UseNumber()
+		var v any
+		r.NoError(dec.Decode(&v))
+		return v
+	}
+
+	header, rows, meta := flattenElasticResponse(decode(`{"hits": {"total": {"value": 2, "relation": "eq"}, "hits": [
+		{"_index": "logs", "_id": "1", "_score": 1.5, "_source": {"message": "up", "host": {"name": "a", "ip": ["10.0.0.1"]}}},
+		{"_index": "logs", "_id": "2", "_score": 1, "_source": {"message": "down", "level": 3}}
+	]}}`))
+	r.Equal(core.Header{"_id", "_index", "_score", "host.ip", "host.name", "message", "level"}, header)
+	r.Equal([]core.Row{
+		{"1", "logs", 1.5, `["10.0.0.1"]`, "a", "up", nil},
+		{"2", "logs", int64(1), nil, nil, "down", int64(3)},
+	}, rows)
+	r.Equal([]string{"2 hits"}, meta.Notices)
+	r.Equal(core.SchemaLess, meta.SchemaType)
+
+	header, rows, _ = flattenElasticResponse(decode(`{"hits": {"total": {"value": 10000, "relation": "gte"}, "hits": []},
+		"aggregations": {"avg_level": {"value": 2.5}}}`))
+	r.Equal(core.Header{"avg_level.value"}, header)
+	r.Equal([]core.Row{{2.5}}, rows)
+
+	header, rows, _ = flattenElasticResponse(decode(`[{"index": "logs", "health": "green"}, {"index": "metrics"}]`))
+	r.Equal(core.Header{"health", "index"}, header)
+	r.Equal([]core.Row{{"green", "logs"}, {nil, "metrics"}}, rows)
+}
+
+func TestElasticDriver(t *testing.T) {
+	r := require.New(t)
+
+	var closed []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.Equal("ApiKey secret", req.Header.Get("Authorization"))
+		body, _ := io.ReadAll(req.Body)
+
+		switch req.URL.Path {
+		case "/_plugins/_sql":
+			var q struct {
+				Query  string `json:"query"`
+				Cursor string `json:"cursor"`
+			}
+			r.NoError(json.Unmarshal(body, &q))
+			switch {
+			case q.Query == "SELECT a FROM logs":
+				_, _ = io.WriteString(w, `{"schema": [{"name": "a", "type": "long"}], "datarows": [[1], [2]], "cursor": "c1"}`)
+			case q.Query == "SELECT b FROM logs":
+				_, _ = io.WriteString(w, `{"schema": [{"name": "b", "type": "text"}], "datarows": [["x"]], "cursor": "c2"}`)
+			case q.Cursor == "c1":
+				_, _ = io.WriteString(w, `{"datarows": [[3]]}`)
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = io.WriteString(w, `{"error": {"type": "SyntaxCheckException", "reason": "Invalid SQL query", "details": "unexpected token"}, "status": 400}`)
+			}
+		case "/_plugins/_sql/close":
+			closed = append(closed, string(body))
+			_, _ = io.WriteString(w, `{"succeeded": true}`)
+		case "/logs/_mapping":
+			_, _ = io.WriteString(w, `{"logs": {"mappings": {"properties": {
+				"message": {"type": "text", "fields": {"keyword": {"type": "keyword"}}},
+				"host": {"properties": {"name": {"type": "keyword"}}}
+			}}}}`)
+		case "/_cat/indices":
+			_, _ = io.WriteString(w, `[{"index": ".kibana"}, {"index": "logs"}]`)
+		case "/_cat/aliases":
+			_, _ = io.WriteString(w, `[{"alias": "current"}, {"alias": "current"}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"error": {"type": "index_not_found_exception", "reason": "no such index"}, "status": 404}`)
+		}
+	}))
+	defer server.Close()
+
+	driver, err := (&Elastic{}).Connect("opensearch://" + strings.TrimPrefix(server.URL, "http://") + "?api_key=secret")
+	r.NoError(err)
+	defer driver.Close()
+
+	// pages of the cursor are fetched while reading
+	result, err := driver.Query(context.Background(), "SELECT a FROM logs;")
+	r.NoError(err)
+	r.Equal(core.Header{"a"}, result.Header())
+	var values []any
+	for result.HasNext() {
+		row, err := result.Next()
+		r.NoError(err)
+		values = append(values, row[0])
+	}
+	result.Close()
+	r.Equal([]any{int64(1), int64(2), int64(3)}, values)
+	r.Empty(closed)
+
+	// cursors of results which weren't read to the end are closed
+	result, err = driver.Query(context.Background(), "SELECT b FROM logs")
+	r.NoError(err)
+	result.Close()
+	r.Equal([]string{`{"cursor":"c2"}`}, closed)
+
+	_, err = driver.Query(context.Background(), "SELEC")
+	r.ErrorContains(err, "SyntaxCheckException: Invalid SQL query: unexpected token")
+
+	_, err = driver.Query(context.Background(), "GET /missing/_search")
+	r.ErrorContains(err, "no such index")
+
+	columns, err := driver.Columns(&core.TableOptions{Table: "logs"})
+	r.NoError(err)
+	r.Equal([]*core.Column{
+		{Name: "host.name", Type: "keyword"},
+		{Name: "message", Type: "text"},
+		{Name: "message.keyword", Type: "keyword"},
+	}, columns)
+
+	structure, err := driver.Structure()
+	r.NoError(err)
+	r.Equal([]*core.Structure{
+		{Name: ".kibana", Type: core.StructureTypeTable},
+		{Name: "logs", Type: core.StructureTypeTable},
+		{Name: "current", Type: core.StructureTypeView},
+	}, structure)
+	r.True(driver.(core.SystemObjectClassifier).IsSystem(structure[0]))
+}
@@ -0,0 +1,364 @@
+package adapters
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os/exec"
+	"strconv"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// ExternalProtocolVersion is the version of the protocol spoken with external
+// adapters. It is sent with the "initialize" request.
+const ExternalProtocolVersion = 1
+
+// externalCloseTimeout is how long an adapter process has to exit after
+// "disconnect" before it's killed.
+const externalCloseTimeout = 2 * time.Second
+
+var ErrExternalAdapterExited = errors.New("external adapter exited")
+
+var _ core.Adapter = (*External)(nil)
+
+// External is an adapter implemented by a subprocess. Every connection starts
+// a new process, which speaks a DAP-like protocol over stdio: messages are
+// JSON objects prefixed with a "Content-Length: <n>\r\n\r\n" header.
+//
+// Requests sent to the adapter:
+//
+//	{"seq": 1, "type": "request", "command": "connect", "arguments": {"url": "..."}}
+//
+// Responses sent by the adapter:
+//
+//	{"seq": 1, "type": "response", "request_seq": 1, "command": "connect",
+//	 "success": true, "message": "error message if not successful", "body": {...}}
+//
+// Commands (arguments -> body):
+//
+//	initialize {protocolVersion}            -> {helpers: {title: go template}, capabilities: {cancel: bool}}
+//	connect    {url}                        -> {}
+//	query      {query}                      -> {header: [...], rows: [[...], ...]}
+//	structure  {}                           -> {structure: [{name, schema, type, children}]}
+//	columns    {table, schema, materialization} -> {columns: [{name, type, notNull, primaryKey, default, comment}]}
+//	cancel     {requestSeq}                 -> {}
+//	disconnect {}                           -> {}
+//
+// Messages of other types (e.g. events) are ignored.
+type External struct {
+	command []string
+
+	// helpers reported by the last initialized adapter process
+	mu      sync.Mutex
+	helpers map[string]*template.Template
+}
+
+// NewExternal returns an adapter which runs command (executable and its
+// arguments) for every connection.
+func NewExternal(command []string) (*External, error) {
+	if len(command) < 1 {
+		return nil, errors.New("no command provided")
+	}
+	return &External{command: command}, nil
+}
+
+func (e *External) Connect(url string) (core.Driver, error) {
+	client, err := startExternal(e.command)
+	if err != nil {
+		return nil, err
+	}
+
+	var initialized struct {
+		Helpers      map[string]string `json:"helpers"`
+		Capabilities struct {
+			Cancel bool `json:"cancel"`
+		} `json:"capabilities"`
+	}
+	err = client.request("initialize", map[string]any{"protocolVersion": ExternalProtocolVersion}, &initialized)
+	if err != nil {
+		client.close()
+		return nil, err
+	}
+	e.setHelpers(initialized.Helpers)
+
+	err = client.request("connect", map[string]any{"url": url}, nil)
+	if err != nil {
+		client.close()
+		return nil, err
+	}
+
+	return &externalDriver{
+		c:      client,
+		cancel: initialized.Capabilities.Cancel,
+	}, nil
+}
+
+func (e *External) setHelpers(helpers map[string]string) {
+	templates := make(map[string]*template.Template, len(helpers))
+	for name, text := range helpers {
+		tmpl, err := template.New("helpers").Parse(text)
+		if err != nil {
+			continue
+		}
+		templates[name] = tmpl
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.helpers = templates
+}
+
+func (e *External) GetHelpers(opts *core.TableOptions) map[string]string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	helpers := make(map[string]string, len(e.helpers))
+	for name, tmpl := range e.helpers {
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, opts); err != nil {
+			continue
+		}
+		helpers[name] = out.String()
+	}
+	return helpers
+}
+
+type externalMessage struct {
+	Seq        int             `json:"seq"`
+	Type       string          `json:"type"`
+	Command    string          `json:"command,omitempty"`
+	Arguments  any             `json:"arguments,omitempty"`
+	RequestSeq int             `json:"request_seq,omitempty"`
+	Success    bool            `json:"success,omitempty"`
+	Message    string          `json:"message,omitempty"`
+	Body       json.RawMessage `json:"body,omitempty"`
+}
+
+// externalClient sends requests to an adapter process and matches
+// responses to them, so requests can run concurrently.
+type externalClient struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stderr *tailBuffer
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	seq     int
+	pending map[int]chan *externalMessage
+	// set when the process stops responding
+	err  error
+	done chan struct{}
+}
+
+func startExternal(command []string) (*externalClient, error) {
+	cmd := exec.Command(command[0], command[1:]...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("cmd.StdinPipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("cmd.StdoutPipe: %w", err)
+	}
+	stderr := &tailBuffer{max: 4096}
+	cmd.Stderr = stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("cmd.Start: %w", err)
+	}
+
+	c := &externalClient{
+		cmd:     cmd,
+		stdin:   stdin,
+		stderr:  stderr,
+		pending: make(map[int]chan *externalMessage),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop(stdout)
+
+	return c, nil
+}
+
+func (c *externalClient) readLoop(stdout io.Reader) {
+	reader := bufio.NewReader(stdout)
+	var err error
+	for {
+		var msg *externalMessage
+		msg, err = readExternalMessage(reader)
+		if err != nil {
+			break
+		}
+		if msg.Type != "response" {
+			continue
+		}
+
+		c.mu.Lock()
+		ch, ok := c.pending[msg.RequestSeq]
+		delete(c.pending, msg.RequestSeq)
+		c.mu.Unlock()
+		if ok {
+			ch <- msg
+		}
+	}
+
+	// process is gone, fail all requests
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = fmt.Errorf("%w: %s", ErrExternalAdapterExited, err)
+	if tail := c.stderr.String(); tail != "" {
+		c.err = fmt.Errorf("%w: %s", c.err, tail)
+	}
+	close(c.done)
+}
+
+// send sends a request and returns its sequence number and a channel
+// receiving the response.
+func (c *externalClient) send(command string, args any) (int, chan *externalMessage, error) {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return 0, nil, c.err
+	}
+	c.seq++
+	seq := c.seq
+	ch := make(chan *externalMessage, 1)
+	c.pending[seq] = ch
+	c.mu.Unlock()
+
+	if args == nil {
+		args = struct{}{}
+	}
+	err := c.write(&externalMessage{Seq: seq, Type: "request", Command: command, Arguments: args})
+	if err != nil {
+		c.forget(seq)
+		return 0, nil, err
+	}
+	return seq, ch, nil
+}
+
+func (c *externalClient) forget(seq int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pending, seq)
+}
+
+// wait waits for the response and decodes its body to out (if not nil).
+func (c *externalClient) wait(command string, ch chan *externalMessage, cancel <-chan struct{}, out any) error {
+	var msg *externalMessage
+	select {
+	case msg = <-ch:
+	case <-c.done:
+		return c.err
+	case <-cancel:
+		return errExternalCanceled
+	}
+
+	if !msg.Success {
+		return fmt.Errorf("%s: %s", command, msg.Message)
+	}
+	if out == nil || len(msg.Body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(msg.Body, out); err != nil {
+		return fmt.Errorf("%s: invalid response: %w", command, err)
+	}
+	return nil
+}
+
+var errExternalCanceled = errors.New("request canceled")
+
+// request sends a request and waits for the response.
+func (c *externalClient) request(command string, args, out any) error {
+	_, ch, err := c.send(command, args)
+	if err != nil {
+		return err
+	}
+	return c.wait(command, ch, nil, out)
+}
+
+func (c *externalClient) write(msg *externalMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err = fmt.Fprintf(c.stdin, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+// close asks the adapter to disconnect and kills it if it doesn't exit in time.
+func (c *externalClient) close() {
+	if _, ch, err := c.send("disconnect", nil); err == nil {
+		select {
+		case <-ch:
+		case <-c.done:
+		case <-time.After(externalCloseTimeout):
+		}
+	}
+	_ = c.stdin.Close()
+
+	select {
+	case <-c.done:
+	case <-time.After(externalCloseTimeout):
+		_ = c.cmd.Process.Kill()
+	}
+	_ = c.cmd.Wait()
+}
+
+// readExternalMessage reads a message with a Content-Length header.
+func readExternalMessage(reader *bufio.Reader) (*externalMessage, error) {
+	header, err := textproto.NewReader(reader).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length: %w", err)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return nil, err
+	}
+
+	var msg externalMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	return &msg, nil
+}
+
+// tailBuffer keeps the last max bytes written to it.
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+	max int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.max {
+		b.buf = b.buf[len(b.buf)-b.max:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(bytes.TrimSpace(b.buf))
+}
@@ -0,0 +1,161 @@
+#!/usr/bin/env python3
+"""Reference external adapter for nvim-dbee.
+
+Serves a directory of CSV files as a database: every file is loaded into an
+in-memory SQLite database as a table named after the file, so the files can
+be queried with SQL. Only the python standard library is used.
+
+Register it in the config of the plugin:
+
+    adapters = {
+      csv = { command = { "python3", "/path/to/csv_adapter.py" } },
+    }
+
+and add a connection with type "csv" and the directory as url.
+
+The protocol is described in dbee/adapters/external.go: messages are JSON
+objects prefixed with a "Content-Length" header, sent over stdin and stdout.
+"""
+
+import csv
+import json
+import os
+import sqlite3
+import sys
+import threading
+
+PROTOCOL_VERSION = 1
+
+HELPERS = {
+    "List": 'SELECT * FROM "{{ .Table }}" LIMIT 500',
+    "Count": 'SELECT COUNT(*) FROM "{{ .Table }}"',
+}
+
+write_lock = threading.RLock()
+
+
+def read_message(stream):
+    length = None
+    while True:
+        line = stream.readline()
+        if not line:
+            return None
+        line = line.strip()
+        if not line:
+            break
+        name, _, value = line.decode().partition(":")
+        if name.strip().lower() == "content-length":
+            length = int(value.strip())
+    if length is None:
+        return None
+    return json.loads(stream.read(length))
+
+
+def write_message(message):
+    body = json.dumps(message, default=str).encode()
+    with write_lock:
+        sys.stdout.buffer.write(b"Content-Length: %d\r\n\r\n" % len(body))
+        sys.stdout.buffer.write(body)
+        sys.stdout.buffer.flush()
+
+
+class Adapter:
+    def __init__(self):
+        self.db = None
+        self.seq = 0
+
+    def respond(self, request, body=None, error=None):
+        # queries respond from other threads
+        with write_lock:
+            self.seq += 1
+            write_message(
+                {
+                    "seq": self.seq,
+                    "type": "response",
+                    "request_seq": request["seq"],
+                    "command": request["command"],
+                    "success": error is None,
+                    "message": error or "",
+                    "body": body or {},
+                }
+            )
+
+    def initialize(self, args):
+        if args.get("protocolVersion", 0) < PROTOCOL_VERSION:
+            raise ValueError("unsupported protocol version")
+        return {"helpers": HELPERS, "capabilities": {"cancel": True}}
+
+    def connect(self, args):
+        directory = os.path.expanduser(args["url"])
+        self.db = sqlite3.connect(":memory:", check_same_thread=False)
+        for name in sorted(os.listdir(directory)):
+            table, ext = os.path.splitext(name)
+            if ext.lower() != ".csv":
+                continue
+            with open(os.path.join(directory, name), newline="") as file:
+                reader = csv.reader(file)
+                header = next(reader, None)
+                if not header:
+                    continue
+                columns = ", ".join('"%s"' % col.replace('"', '""') for col in header)
+                self.db.execute('CREATE TABLE "%s" (%s)' % (table, columns))
+                placeholders = ", ".join("?" for _ in header)
+                self.db.executemany(
+                    'INSERT INTO "%s" VALUES (%s)' % (table, placeholders),
+                    (row for row in reader if len(row) == len(header)),
+                )
+        return {}
+
+    def query(self, args):
+        cursor = self.db.execute(args["query"])
+        if cursor.description is None:
+            return {"header": ["Rows Affected"], "rows": [[cursor.rowcount]]}
+        header = [col[0] for col in cursor.description]
+        return {"header": header, "rows": [list(row) for row in cursor.fetchall()]}
+
+    def structure(self, _):
+        tables = self.db.execute("SELECT name FROM sqlite_master WHERE type = 'table' ORDER BY name")
+        return {"structure": [{"name": name, "schema": "", "type": "table"} for (name,) in tables]}
+
+    def columns(self, args):
+        info = self.db.execute("SELECT name, type FROM pragma_table_info(?)", (args["table"],))
+        return {"columns": [{"name": name, "type": typ or "text"} for name, typ in info]}
+
+    def cancel(self, _):
+        if self.db is not None:
+            self.db.interrupt()
+        return {}
+
+    def handle(self, request):
+        try:
+            body = getattr(self, request["command"])(request.get("arguments") or {})
+        except Exception as err:  # errors are reported to the editor
+            self.respond(request, error=str(err))
+        else:
+            self.respond(request, body)
+
+
+def main():
+    adapter = Adapter()
+    while True:
+        request = read_message(sys.stdin.buffer)
+        if request is None or request.get("type") != "request":
+            if request is None:
+                return
+            continue
+
+        command = request.get("command")
+        if command == "disconnect":
+            adapter.respond(request)
+            return
+        if command not in ("initialize", "connect", "query", "structure", "columns", "cancel"):
+            adapter.respond(request, error="unknown command: %s" % command)
+        elif command == "query":
+            # queries run in the background, so they can be canceled
+            threading.Thread(target=adapter.handle, args=(request,), daemon=True).start()
+        else:
+            adapter.handle(request)
+
+
+if __name__ == "__main__":
+    main()
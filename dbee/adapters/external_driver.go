@@ -0,0 +1,155 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var (
+	_ core.Driver             = (*externalDriver)(nil)
+	_ core.CapabilityReporter = (*externalDriver)(nil)
+)
+
+type externalDriver struct {
+	c *externalClient
+	// whether the adapter handles "cancel" requests
+	cancel bool
+}
+
+type externalStructure struct {
+	Name     string               `json:"name"`
+	Schema   string               `json:"schema"`
+	Type     string               `json:"type"`
+	Children []*externalStructure `json:"children"`
+}
+
+func (s *externalStructure) toCore() *core.Structure {
+	children := make([]*core.Structure, 0, len(s.Children))
+	for _, child := range s.Children {
+		children = append(children, child.toCore())
+	}
+	return &core.Structure{
+		Name:     s.Name,
+		Schema:   s.Schema,
+		Type:     core.StructureTypeFromString(s.Type),
+		Children: children,
+	}
+}
+
+func (d *externalDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	seq, ch, err := d.c.send("query", map[string]any{"query": query})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Header []string `json:"header"`
+		Rows   [][]any  `json:"rows"`
+	}
+	err = d.c.wait("query", ch, ctx.Done(), &result)
+	if errors.Is(err, errExternalCanceled) {
+		d.c.forget(seq)
+		// the adapter should stop the query, the response is not awaited
+		go func() { _ = d.c.request("cancel", map[string]any{"requestSeq": seq}, nil) }()
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index := 0
+	hasNext := func() bool {
+		return index < len(result.Rows)
+	}
+	next := func() (core.Row, error) {
+		if !hasNext() {
+			return nil, errors.New("no next row")
+		}
+		row := result.Rows[index]
+		for i, value := range row {
+			row[i] = externalValue(value)
+		}
+		index++
+		return row, nil
+	}
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(result.Header).
+		Build(), nil
+}
+
+func (d *externalDriver) Structure() ([]*core.Structure, error) {
+	var result struct {
+		Structure []*externalStructure `json:"structure"`
+	}
+	if err := d.c.request("structure", nil, &result); err != nil {
+		return nil, err
+	}
+
+	structure := make([]*core.Structure, 0, len(result.Structure))
+	for _, node := range result.Structure {
+		structure = append(structure, node.toCore())
+	}
+	return structure, nil
+}
+
+func (d *externalDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	var result struct {
+		Columns []struct {
+			Name       string `json:"name"`
+			Type       string `json:"type"`
+			NotNull    bool   `json:"notNull"`
+			PrimaryKey bool   `json:"primaryKey"`
+			Default    string `json:"default"`
+			Comment    string `json:"comment"`
+		} `json:"columns"`
+	}
+	err := d.c.request("columns", map[string]any{
+		"table":           opts.Table,
+		"schema":          opts.Schema,
+		"materialization": opts.Materialization.String(),
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]*core.Column, 0, len(result.Columns))
+	for _, col := range result.Columns {
+		columns = append(columns, &core.Column{
+			Name:       col.Name,
+			Type:       col.Type,
+			NotNull:    col.NotNull,
+			PrimaryKey: col.PrimaryKey,
+			Default:    col.Default,
+			Comment:    col.Comment,
+		})
+	}
+	return columns, nil
+}
+
+func (d *externalDriver) Capabilities() map[string]bool {
+	return map[string]bool{
+		core.CapabilityCancel: d.cancel,
+		// rows are sent in a single response
+		core.CapabilityStreaming: false,
+	}
+}
+
+func (d *externalDriver) Close() {
+	d.c.close()
+}
+
+// externalValue converts whole json numbers to integers, so they aren't
+// displayed as floats.
+func externalValue(value any) any {
+	f, ok := value.(float64)
+	if !ok || f != math.Trunc(f) || math.Abs(f) > 1<<53 {
+		return value
+	}
+	return int64(f)
+}
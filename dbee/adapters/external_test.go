@@ -0,0 +1,64 @@
+package adapters
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestExternal_ReferenceAdapter(t *testing.T) {
+	r := require.New(t)
+
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 is not installed")
+	}
+
+	dir := t.TempDir()
+	r.NoError(os.WriteFile(filepath.Join(dir, "users.csv"), []byte("id,name\n1,alice\n2,bob\n"), 0o644))
+
+	adapter, err := NewExternal([]string{python, filepath.Join("external", "csv_adapter.py")})
+	r.NoError(err)
+
+	driver, err := adapter.Connect(dir)
+	r.NoError(err)
+	defer driver.Close()
+
+	r.True(driver.(core.CapabilityReporter).Capabilities()[core.CapabilityCancel])
+
+	structure, err := driver.Structure()
+	r.NoError(err)
+	r.Len(structure, 1)
+	r.Equal("users", structure[0].Name)
+	r.Equal(core.StructureTypeTable, structure[0].Type)
+
+	columns, err := driver.Columns(&core.TableOptions{Table: "users", Materialization: core.StructureTypeTable})
+	r.NoError(err)
+	r.Equal([]string{"id", "name"}, columnNames(columns))
+
+	r.Equal(map[string]string{
+		"List":  `SELECT * FROM "users" LIMIT 500`,
+		"Count": `SELECT COUNT(*) FROM "users"`,
+	}, adapter.GetHelpers(&core.TableOptions{Table: "users"}))
+
+	result, err := driver.Query(context.Background(), "SELECT name, id + 1 FROM users ORDER BY id")
+	r.NoError(err)
+	r.Equal(core.Header{"name", "id + 1"}, result.Header())
+
+	var rows []core.Row
+	for result.HasNext() {
+		row, err := result.Next()
+		r.NoError(err)
+		rows = append(rows, row)
+	}
+	r.Equal([]core.Row{{"alice", int64(2)}, {"bob", int64(3)}}, rows)
+
+	_, err = driver.Query(context.Background(), "SELECT * FROM missing")
+	r.ErrorContains(err, "no such table: missing")
+}
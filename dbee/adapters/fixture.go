@@ -0,0 +1,447 @@
+package adapters
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+// Register clients
+func init() {
+	_ = register(&Recorder{}, "record")
+	_ = register(&Replayer{}, "replay")
+}
+
+var (
+	_ core.Adapter = (*Recorder)(nil)
+	_ core.Adapter = (*Replayer)(nil)
+	_ core.Driver  = (*recordingDriver)(nil)
+	_ core.Driver  = (*replayingDriver)(nil)
+)
+
+var ErrQueryNotRecorded = errors.New("query was not recorded")
+
+const fixtureVersion = 1
+
+// Recorder is an adapter which proxies queries to another adapter and records
+// queries, their results and the structure of the database to a fixture file.
+// Recorded fixtures can be replayed offline with Replayer.
+//
+// The url holds options in the form of a query string, the url of the proxied
+// connection is the last option and it's taken as it is:
+//
+//	path=~/fixtures/bug.json&type=postgres&url=postgres://localhost/db?sslmode=disable
+type Recorder struct{}
+
+// Replayer is an adapter which replays queries recorded with Recorder. The url
+// is the path of the fixture file. Queries which weren't recorded fail with
+// ErrQueryNotRecorded.
+type Replayer struct{}
+
+// fixture is the content of a fixture file.
+type fixture struct {
+	Version   int                       `json:"version"`
+	Type      string                    `json:"type"`
+	Structure []*core.Structure         `json:"structure,omitempty"`
+	Columns   map[string][]*core.Column `json:"columns,omitempty"`
+	Queries   []*fixtureQuery           `json:"queries"`
+}
+
+// fixtureQuery is a recorded query. Queries recorded more than once are
+// replayed in the recorded order.
+type fixtureQuery struct {
+	Query  string           `json:"query"`
+	Header core.Header      `json:"header,omitempty"`
+	Rows   [][]fixtureValue `json:"rows,omitempty"`
+	Error  string           `json:"error,omitempty"`
+}
+
+// fixtureValue is a value of a recorded row. Strings, booleans and integers
+// are stored as json values, other types are stored as objects with their
+// type, so they are replayed with the same type.
+type fixtureValue struct {
+	value any
+}
+
+func (fv fixtureValue) MarshalJSON() ([]byte, error) {
+	switch v := fv.value.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return json.Marshal(v)
+	case float32:
+		return json.Marshal(map[string]any{"float": v})
+	case float64:
+		return json.Marshal(map[string]any{"float": v})
+	case time.Time:
+		return json.Marshal(map[string]any{"time": v.Format(time.RFC3339Nano)})
+	case []byte:
+		return json.Marshal(map[string]any{"bytes": base64.StdEncoding.EncodeToString(v)})
+	default:
+		return json.Marshal(fmt.Sprint(v))
+	}
+}
+
+func (fv *fixtureValue) UnmarshalJSON(data []byte) error {
+	var tagged struct {
+		Float *float64 `json:"float"`
+		Time  *string  `json:"time"`
+		Bytes *string  `json:"bytes"`
+	}
+	if len(data) > 0 && data[0] == '{' {
+		if err := json.Unmarshal(data, &tagged); err != nil {
+			return err
+		}
+		switch {
+		case tagged.Float != nil:
+			fv.value = *tagged.Float
+		case tagged.Time != nil:
+			t, err := time.Parse(time.RFC3339Nano, *tagged.Time)
+			if err != nil {
+				return err
+			}
+			fv.value = t
+		case tagged.Bytes != nil:
+			b, err := base64.StdEncoding.DecodeString(*tagged.Bytes)
+			if err != nil {
+				return err
+			}
+			fv.value = b
+		}
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	// only integers are stored as json numbers
+	fv.value = externalValue(value)
+	return nil
+}
+
+func loadFixture(path string) (*fixture, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f fixture
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	if f.Version != fixtureVersion {
+		return nil, fmt.Errorf("unsupported fixture version: %d", f.Version)
+	}
+	return &f, nil
+}
+
+// save writes the fixture to a temporary file first, so a failed write doesn't
+// corrupt the recording.
+func (f *fixture) save(path string) error {
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func expandFixturePath(path string) (string, error) {
+	usr, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("user.Current: %w", err)
+	}
+
+	if path == "~" {
+		return usr.HomeDir, nil
+	} else if strings.HasPrefix(path, "~/") {
+		return filepath.Join(usr.HomeDir, path[2:]), nil
+	}
+
+	return path, nil
+}
+
+func fixtureColumnsKey(opts *core.TableOptions) string {
+	return opts.Schema + "." + opts.Table
+}
+
+// parseRecorderURL returns the fixture path, the type and the url of the
+// proxied connection.
+func parseRecorderURL(rawURL string) (path, typ, innerURL string, err error) {
+	options := rawURL
+	if strings.HasPrefix(rawURL, "url=") {
+		options, innerURL = "", strings.TrimPrefix(rawURL, "url=")
+	} else if i := strings.Index(rawURL, "&url="); i >= 0 {
+		options, innerURL = rawURL[:i], rawURL[i+len("&url="):]
+	}
+
+	values, err := url.ParseQuery(options)
+	if err != nil {
+		return "", "", "", fmt.Errorf("url.ParseQuery: %w", err)
+	}
+	path, typ = values.Get("path"), values.Get("type")
+	if path == "" || typ == "" {
+		return "", "", "", errors.New("path and type are required")
+	}
+
+	path, err = expandFixturePath(path)
+	if err != nil {
+		return "", "", "", err
+	}
+	return path, typ, innerURL, nil
+}
+
+func (r *Recorder) Connect(rawURL string) (core.Driver, error) {
+	path, typ, innerURL, err := parseRecorderURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if typ == "record" || typ == "replay" {
+		return nil, fmt.Errorf("can't record connections of type %q", typ)
+	}
+
+	adapter, err := new(Mux).GetAdapter(typ)
+	if err != nil {
+		return nil, fmt.Errorf("Mux.GetAdapter: %w", err)
+	}
+
+	// new queries are added to an existing recording
+	f, err := loadFixture(path)
+	if errors.Is(err, os.ErrNotExist) {
+		f = &fixture{Version: fixtureVersion, Type: typ, Columns: make(map[string][]*core.Column)}
+	} else if err != nil {
+		return nil, fmt.Errorf("loadFixture: %w", err)
+	}
+	if f.Columns == nil {
+		f.Columns = make(map[string][]*core.Column)
+	}
+
+	driver, err := adapter.Connect(innerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &recordingDriver{
+		driver:  driver,
+		path:    path,
+		fixture: f,
+	}, nil
+}
+
+func (*Recorder) GetHelpers(opts *core.TableOptions) map[string]string {
+	return nil
+}
+
+func (r *Replayer) Connect(rawURL string) (core.Driver, error) {
+	path, err := expandFixturePath(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := loadFixture(path)
+	if err != nil {
+		return nil, fmt.Errorf("loadFixture: %w", err)
+	}
+
+	return &replayingDriver{
+		fixture: f,
+		played:  make(map[string]int),
+	}, nil
+}
+
+func (*Replayer) GetHelpers(opts *core.TableOptions) map[string]string {
+	return nil
+}
+
+// recordingDriver proxies queries to the driver and records them. Only the
+// first result set of a query is recorded.
+type recordingDriver struct {
+	driver core.Driver
+	path   string
+
+	mu      sync.Mutex
+	fixture *fixture
+}
+
+func (d *recordingDriver) record(update func(f *fixture)) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	update(d.fixture)
+	return d.fixture.save(d.path)
+}
+
+func (d *recordingDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	recorded := &fixtureQuery{Query: strings.TrimSpace(query)}
+
+	header, rows, meta, queryErr := drainStream(d.driver.Query(ctx, query))
+	if queryErr != nil {
+		recorded.Error = queryErr.Error()
+	}
+	recorded.Header = header
+	for _, row := range rows {
+		values := make([]fixtureValue, len(row))
+		for i, value := range row {
+			values[i] = fixtureValue{value: value}
+		}
+		recorded.Rows = append(recorded.Rows, values)
+	}
+
+	// canceled queries are not recorded
+	if ctx.Err() == nil {
+		err := d.record(func(f *fixture) { f.Queries = append(f.Queries, recorded) })
+		if err != nil {
+			return nil, fmt.Errorf("recording query: %w", err)
+		}
+	}
+	if queryErr != nil {
+		return nil, queryErr
+	}
+
+	return sliceResultStream(header, rows, meta), nil
+}
+
+func (d *recordingDriver) Structure() ([]*core.Structure, error) {
+	structure, err := d.driver.Structure()
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.record(func(f *fixture) { f.Structure = structure })
+	if err != nil {
+		return nil, fmt.Errorf("recording structure: %w", err)
+	}
+	return structure, nil
+}
+
+func (d *recordingDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	columns, err := d.driver.Columns(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.record(func(f *fixture) { f.Columns[fixtureColumnsKey(opts)] = columns })
+	if err != nil {
+		return nil, fmt.Errorf("recording columns: %w", err)
+	}
+	return columns, nil
+}
+
+func (d *recordingDriver) Close() {
+	d.driver.Close()
+}
+
+// replayingDriver replays a fixture.
+type replayingDriver struct {
+	fixture *fixture
+
+	mu sync.Mutex
+	// number of times each query was replayed
+	played map[string]int
+}
+
+func (d *replayingDriver) Query(_ context.Context, query string) (core.ResultStream, error) {
+	query = strings.TrimSpace(query)
+
+	d.mu.Lock()
+	var recordings []*fixtureQuery
+	for _, q := range d.fixture.Queries {
+		if q.Query == query {
+			recordings = append(recordings, q)
+		}
+	}
+	if len(recordings) < 1 {
+		d.mu.Unlock()
+		return nil, fmt.Errorf("%w: %q", ErrQueryNotRecorded, query)
+	}
+	// recordings are replayed in order, the last one is repeated
+	recorded := recordings[min(d.played[query], len(recordings)-1)]
+	d.played[query]++
+	d.mu.Unlock()
+
+	if recorded.Error != "" {
+		return nil, errors.New(recorded.Error)
+	}
+
+	rows := make([]core.Row, len(recorded.Rows))
+	for i, values := range recorded.Rows {
+		row := make(core.Row, len(values))
+		for j, value := range values {
+			row[j] = value.value
+		}
+		rows[i] = row
+	}
+	return sliceResultStream(recorded.Header, rows, &core.Meta{}), nil
+}
+
+func (d *replayingDriver) Structure() ([]*core.Structure, error) {
+	return d.fixture.Structure, nil
+}
+
+func (d *replayingDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	columns, ok := d.fixture.Columns[fixtureColumnsKey(opts)]
+	if !ok {
+		return nil, fmt.Errorf("%w: columns of %q", ErrQueryNotRecorded, opts.Table)
+	}
+	return columns, nil
+}
+
+func (d *replayingDriver) Close() {}
+
+// drainStream reads all rows of the first result set of a stream.
+func drainStream(stream core.ResultStream, err error) (core.Header, []core.Row, *core.Meta, error) {
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer stream.Close()
+
+	var rows []core.Row
+	for stream.HasNext() {
+		row, err := stream.Next()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		rows = append(rows, row)
+	}
+	return stream.Header(), rows, stream.Meta(), nil
+}
+
+// sliceResultStream returns a stream of rows which are already in memory.
+func sliceResultStream(header core.Header, rows []core.Row, meta *core.Meta) core.ResultStream {
+	index := 0
+	hasNext := func() bool {
+		return index < len(rows)
+	}
+	next := func() (core.Row, error) {
+		if !hasNext() {
+			return nil, errors.New("no next row")
+		}
+		row := rows[index]
+		index++
+		return row, nil
+	}
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(header).
+		WithMeta(meta).
+		Build()
+}
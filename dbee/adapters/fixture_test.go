@@ -0,0 +1,76 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestRecorder_Replay(t *testing.T) {
+	r := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "fixtures", "demo.json")
+	recorder, err := new(Recorder).Connect("path=" + path + "&type=demo&url=rows=20&seed=3")
+	r.NoError(err)
+
+	query := "SELECT id, name, created_at FROM customers ORDER BY id LIMIT 3"
+	recorded := demoRows(t, recorder, query)
+	r.Len(recorded, 3)
+	_, err = recorder.Query(context.Background(), "SELECT * FROM missing")
+	r.Error(err)
+	structure, err := recorder.Structure()
+	r.NoError(err)
+	columns, err := recorder.Columns(&core.TableOptions{Table: "products"})
+	r.NoError(err)
+	recorder.Close()
+
+	replayer, err := new(Replayer).Connect(path)
+	r.NoError(err)
+	defer replayer.Close()
+
+	// values are replayed with their types
+	r.Equal(recorded, demoRows(t, replayer, "  "+query+"\n"))
+	_, err = replayer.Query(context.Background(), "SELECT * FROM missing")
+	r.ErrorContains(err, "no such table")
+	_, err = replayer.Query(context.Background(), "SELECT 1")
+	r.ErrorIs(err, ErrQueryNotRecorded)
+
+	replayedStructure, err := replayer.Structure()
+	r.NoError(err)
+	r.Equal(len(structure), len(replayedStructure))
+	replayedColumns, err := replayer.Columns(&core.TableOptions{Table: "products"})
+	r.NoError(err)
+	r.Equal(columns, replayedColumns)
+
+	// invalid connections
+	_, err = new(Recorder).Connect("type=demo&url=rows=20")
+	r.Error(err)
+	_, err = new(Recorder).Connect("path=" + path + "&type=replay&url=" + path)
+	r.Error(err)
+	_, err = new(Replayer).Connect(filepath.Join(t.TempDir(), "missing.json"))
+	r.Error(err)
+}
+
+func TestFixtureValue_JSON(t *testing.T) {
+	r := require.New(t)
+
+	timestamp := time.Date(2024, 3, 1, 22, 30, 15, 500, time.UTC)
+	values := []fixtureValue{
+		{nil}, {"text"}, {true}, {int64(42)}, {3.5}, {2.0}, {timestamp}, {[]byte{0, 1, 2}},
+	}
+
+	b, err := json.Marshal(values)
+	r.NoError(err)
+
+	var decoded []fixtureValue
+	r.NoError(json.Unmarshal(b, &decoded))
+	r.Equal([]fixtureValue{
+		{nil}, {"text"}, {true}, {int64(42)}, {3.5}, {2.0}, {timestamp}, {[]byte{0, 1, 2}},
+	}, decoded)
+}
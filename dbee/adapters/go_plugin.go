@@ -0,0 +1,43 @@
+//go:build goplugin && (linux || darwin || freebsd) && cgo
+
+package adapters
+
+import (
+	"fmt"
+	"plugin"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// PluginsSupported reports whether adapters can be loaded from go plugins.
+const PluginsSupported = true
+
+// loadPlugin loads an adapter from a go plugin (built with
+// "go build -buildmode=plugin" against the same version of this module).
+// Plugins require a binary built with "-tags goplugin", which excludes duckdb,
+// because its static library can't be linked into a dynamically linked binary.
+// The plugin exports either a variable "Adapter" of type core.Adapter or a
+// function "NewAdapter() core.Adapter".
+func loadPlugin(path string) (core.Adapter, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("plugin.Open: %w", err)
+	}
+
+	if sym, err := p.Lookup("Adapter"); err == nil {
+		if adapter, ok := sym.(*core.Adapter); ok && *adapter != nil {
+			return *adapter, nil
+		}
+		return nil, fmt.Errorf("symbol Adapter of %q is not a core.Adapter", path)
+	}
+
+	sym, err := p.Lookup("NewAdapter")
+	if err != nil {
+		return nil, fmt.Errorf("plugin %q exports neither Adapter nor NewAdapter", path)
+	}
+	newAdapter, ok := sym.(func() core.Adapter)
+	if !ok {
+		return nil, fmt.Errorf("symbol NewAdapter of %q is not a func() core.Adapter", path)
+	}
+	return newAdapter(), nil
+}
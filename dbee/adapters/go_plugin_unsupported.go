@@ -0,0 +1,16 @@
+//go:build !(goplugin && (linux || darwin || freebsd) && cgo)
+
+package adapters
+
+import (
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// PluginsSupported reports whether adapters can be loaded from go plugins.
+const PluginsSupported = false
+
+// loadPlugin fails, because go plugins are not supported on this platform
+// or the binary was built without cgo or the "goplugin" tag.
+func loadPlugin(string) (core.Adapter, error) {
+	return nil, ErrPluginsNotSupported
+}
@@ -0,0 +1,73 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+// splitTableName splits a schema qualified table name.
+func splitTableName(table string) (schema string, name string) {
+	schema, name, ok := strings.Cut(table, ".")
+	if !ok {
+		return "", table
+	}
+	return schema, name
+}
+
+// mySQLLoadData imports rows with LOAD DATA LOCAL INFILE, streaming them
+// from memory through a registered reader handler.
+// Server has to have local_infile enabled. The table and columns are quoted
+// with the dialect.
+func mySQLLoadData(ctx context.Context, c *builders.Client, dialect *core.Dialect, table string, columns []string, rows []core.Row) error {
+	var sb strings.Builder
+	for _, row := range rows {
+		for i, val := range row {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(mySQLLoadDataField(val))
+		}
+		sb.WriteByte('\n')
+	}
+
+	name := uuid.New().String()
+	mysql.RegisterReaderHandler(name, func() io.Reader {
+		return strings.NewReader(sb.String())
+	})
+	defer mysql.DeregisterReaderHandler(name)
+
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = dialect.QuoteIdentifier(col)
+	}
+
+	query := fmt.Sprintf(`LOAD DATA LOCAL INFILE 'Reader::%s' INTO TABLE %s
+		FIELDS TERMINATED BY ',' ENCLOSED BY '"' ESCAPED BY ''
+		LINES TERMINATED BY '\n' (%s)`, name, dialect.QualifiedName(splitTableName(table)), strings.Join(quoted, ", "))
+
+	return c.ExecArgs(ctx, query)
+}
+
+// mySQLLoadDataField encodes a value for LOAD DATA. With an empty escape
+// character, NULL is the unquoted word NULL and all other values are quoted.
+func mySQLLoadDataField(val any) string {
+	switch v := val.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	default:
+		return `"` + strings.ReplaceAll(fmt.Sprint(v), `"`, `""`) + `"`
+	}
+}
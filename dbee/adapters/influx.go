@@ -0,0 +1,249 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	nurl "net/url"
+	"strings"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// Register client
+func init() {
+	_ = register(&Influx{}, "influx", "influxdb")
+}
+
+var _ core.Adapter = (*Influx)(nil)
+
+type Influx struct{}
+
+// query languages of influx
+const (
+	influxLanguageFlux     = "flux"
+	influxLanguageInfluxQL = "influxql"
+)
+
+// Connect connects to the influx server of the url:
+//
+//	influxdb://[user:password@]host[:port][?options]
+//
+// Supported "options" are:
+//   - language=flux (default) or language=influxql selects the query
+//     language of the connection.
+//   - org=name and token=secret select the organization and authenticate
+//     (influxdb 2).
+//   - database=name (and retention_policy=name) select the database of
+//     influxql queries, user and password of the url authenticate influxdb 1.
+//   - ssl=true connects over https.
+//   - timeout=30s sets the timeout of requests.
+func (i *Influx) Connect(url string) (core.Driver, error) {
+	client, err := parseInfluxURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &influxDriver{
+		c: client,
+	}, nil
+}
+
+func (*Influx) GetHelpers(opts *core.TableOptions) map[string]string {
+	// influxql measurements are listed under databases, flux ones under buckets
+	return map[string]string{
+		"Last Hour (Flux)": fmt.Sprintf(`from(bucket: %q)
+  |> range(start: -1h)
+  |> filter(fn: (r) => r._measurement == %q)
+  |> limit(n: 500)`, opts.Schema, opts.Table),
+		"Last Hour (InfluxQL)": fmt.Sprintf(`SELECT * FROM %s WHERE time > now() - 1h LIMIT 500`,
+			influxQLQualifiedName(opts.Schema, opts.Table)),
+		"Field Keys (InfluxQL)": fmt.Sprintf(`SHOW FIELD KEYS ON %s FROM %s`,
+			influxQLQuote(opts.Schema), influxQLQuote(opts.Table)),
+		"Tag Keys (InfluxQL)": fmt.Sprintf(`SHOW TAG KEYS ON %s FROM %s`,
+			influxQLQuote(opts.Schema), influxQLQuote(opts.Table)),
+	}
+}
+
+// influxClient sends queries to the http api of the server.
+type influxClient struct {
+	http     *http.Client
+	baseURL  string
+	language string
+	org      string
+	token    string
+	user     *nurl.Userinfo
+	// database and retention policy of influxql queries
+	database        string
+	retentionPolicy string
+}
+
+// parseInfluxURL parses the url to the client of the server.
+func parseInfluxURL(rawURL string) (*influxClient, error) {
+	u, err := nurl.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse db connection string: %w", err)
+	}
+	switch u.Scheme {
+	case "influx", "influxdb":
+	default:
+		return nil, fmt.Errorf("unexpected scheme: %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("no host in url")
+	}
+
+	params := u.Query()
+
+	var ssl bool
+	if err := setBoolOption(&ssl, "ssl", params); err != nil {
+		return nil, err
+	}
+	timeout := time.Minute
+	if err := setOption(&timeout, "timeout", params, time.ParseDuration); err != nil {
+		return nil, err
+	}
+
+	c := &influxClient{
+		http:            &http.Client{Timeout: timeout},
+		language:        influxLanguageFlux,
+		org:             params.Get("org"),
+		token:           params.Get("token"),
+		user:            u.User,
+		database:        params.Get("database"),
+		retentionPolicy: params.Get("retention_policy"),
+	}
+	if err := setStringOption(&c.language, "language", params); err != nil {
+		return nil, err
+	}
+	c.language = strings.ToLower(c.language)
+	if c.language != influxLanguageFlux && c.language != influxLanguageInfluxQL {
+		return nil, fmt.Errorf("invalid value for \"language\": %q", c.language)
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		host += ":8086"
+	}
+	scheme := "http"
+	if ssl {
+		scheme = "https"
+	}
+	c.baseURL = scheme + "://" + host
+
+	return c, nil
+}
+
+// do sends the request with credentials of the url and returns the body of
+// a successful response.
+func (c *influxClient) do(req *http.Request) (io.ReadCloser, error) {
+	switch {
+	case c.token != "":
+		req.Header.Set("Authorization", "Token "+c.token)
+	case c.user != nil:
+		password, _ := c.user.Password()
+		req.SetBasicAuth(c.user.Username(), password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, influxResponseError(resp)
+	}
+
+	return resp.Body, nil
+}
+
+// influxResponseError returns the error message of a failed request.
+func influxResponseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	var msg struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &msg); err == nil {
+		if msg.Message != "" {
+			return fmt.Errorf("influx: %s", msg.Message)
+		}
+		if msg.Error != "" {
+			return fmt.Errorf("influx: %s", msg.Error)
+		}
+	}
+	if text := strings.TrimSpace(string(body)); text != "" {
+		return fmt.Errorf("influx: %s: %s", resp.Status, text)
+	}
+	return fmt.Errorf("influx: %s", resp.Status)
+}
+
+// flux sends a flux query and returns the annotated csv response.
+func (c *influxClient) flux(ctx context.Context, query string) (io.ReadCloser, error) {
+	body, err := json.Marshal(map[string]any{
+		"query": query,
+		"type":  "flux",
+		"dialect": map[string]any{
+			"header":      true,
+			"annotations": []string{"datatype", "group", "default"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	params := nurl.Values{}
+	if c.org != "" {
+		params.Set("org", c.org)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v2/query?"+params.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/csv")
+
+	return c.do(req)
+}
+
+// influxQL sends an influxql query and returns the json response.
+func (c *influxClient) influxQL(ctx context.Context, query string) (io.ReadCloser, error) {
+	params := nurl.Values{}
+	params.Set("q", query)
+	params.Set("epoch", "ns")
+	if c.database != "" {
+		params.Set("db", c.database)
+	}
+	if c.retentionPolicy != "" {
+		params.Set("rp", c.retentionPolicy)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/query", strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	return c.do(req)
+}
+
+// influxQLQuote quotes the identifier of an influxql query.
+func influxQLQuote(s string) string {
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), `"`, `\"`) + `"`
+}
+
+// influxQLQualifiedName returns the quoted name of the measurement, qualified
+// by the database (if any).
+func influxQLQualifiedName(database, measurement string) string {
+	if database == "" {
+		return influxQLQuote(measurement)
+	}
+	// measurements of the default retention policy
+	return influxQLQuote(database) + ".." + influxQLQuote(measurement)
+}
@@ -0,0 +1,457 @@
+package adapters
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+var (
+	_ core.Driver                 = (*influxDriver)(nil)
+	_ core.StructureLoader        = (*influxDriver)(nil)
+	_ core.SystemObjectClassifier = (*influxDriver)(nil)
+)
+
+type influxDriver struct {
+	c *influxClient
+}
+
+// Query runs the query in the language of the connection. Tables of flux
+// responses and series of influxql responses are flattened into a single
+// result: columns missing in a table are NULL.
+func (c *influxDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	header, rows, meta, err := c.query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return sliceResultStream(header, rows, meta), nil
+}
+
+func (c *influxDriver) query(ctx context.Context, query string) (core.Header, []core.Row, *core.Meta, error) {
+	if c.c.language == influxLanguageInfluxQL {
+		body, err := c.c.influxQL(ctx, query)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		defer body.Close()
+
+		return parseInfluxQLResponse(body)
+	}
+
+	body, err := c.c.flux(ctx, query)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer body.Close()
+
+	header, rows, err := parseInfluxCSV(body)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return header, rows, &core.Meta{}, nil
+}
+
+// column returns values of the named column of the query result.
+func (c *influxDriver) column(ctx context.Context, query, name string) ([]string, error) {
+	header, rows, _, err := c.query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	index := -1
+	for i, col := range header {
+		if col == name {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		if len(rows) < 1 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not retrieve structure: no %q column", name)
+	}
+
+	values := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if index < len(row) && row[index] != nil {
+			values = append(values, fmt.Sprint(row[index]))
+		}
+	}
+	return values, nil
+}
+
+func (c *influxDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	ctx := context.Background()
+
+	var tagsQuery, fieldsQuery, tagColumn, fieldColumn string
+	if c.c.language == influxLanguageInfluxQL {
+		from := "FROM " + influxQLQuote(opts.Table)
+		if opts.Schema != "" {
+			from = "ON " + influxQLQuote(opts.Schema) + " " + from
+		}
+		tagsQuery, tagColumn = "SHOW TAG KEYS "+from, "tagKey"
+		fieldsQuery, fieldColumn = "SHOW FIELD KEYS "+from, "fieldKey"
+	} else {
+		args := fmt.Sprintf("bucket: %q, measurement: %q", opts.Schema, opts.Table)
+		tagsQuery = "import \"influxdata/influxdb/schema\"\nschema.measurementTagKeys(" + args + ")"
+		fieldsQuery = "import \"influxdata/influxdb/schema\"\nschema.measurementFieldKeys(" + args + ")"
+		tagColumn, fieldColumn = "_value", "_value"
+	}
+
+	tags, err := c.column(ctx, tagsQuery, tagColumn)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := c.column(ctx, fieldsQuery, fieldColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []*core.Column
+	for _, tag := range tags {
+		// flux lists internal columns (e.g. _measurement) as tags
+		if strings.HasPrefix(tag, "_") {
+			continue
+		}
+		columns = append(columns, &core.Column{Name: tag, Type: "tag"})
+	}
+	for _, field := range fields {
+		columns = append(columns, &core.Column{Name: field, Type: "field"})
+	}
+
+	return columns, nil
+}
+
+// Structure lists buckets (databases for influxql) with their measurements.
+func (c *influxDriver) Structure() ([]*core.Structure, error) {
+	ctx := context.TODO()
+
+	roots, err := c.StructureRoots(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, root := range roots {
+		root.Children, err = c.StructureChildren(ctx, root)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return roots, nil
+}
+
+// StructureRoots lists buckets (databases for influxql) without measurements.
+func (c *influxDriver) StructureRoots(ctx context.Context) ([]*core.Structure, error) {
+	var names []string
+	var err error
+	if c.c.language == influxLanguageInfluxQL {
+		names, err = c.column(ctx, "SHOW DATABASES", "name")
+	} else {
+		names, err = c.column(ctx, "buckets()", "name")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	roots := make([]*core.Structure, len(names))
+	for i, name := range names {
+		roots[i] = &core.Structure{
+			Name:   name,
+			Schema: name,
+			Type:   core.StructureTypeNone,
+		}
+	}
+	return roots, nil
+}
+
+// StructureChildren lists measurements of the bucket (database for influxql).
+func (c *influxDriver) StructureChildren(ctx context.Context, parent *core.Structure) ([]*core.Structure, error) {
+	var names []string
+	var err error
+	if c.c.language == influxLanguageInfluxQL {
+		names, err = c.column(ctx, "SHOW MEASUREMENTS ON "+influxQLQuote(parent.Schema), "name")
+	} else {
+		names, err = c.column(ctx, fmt.Sprintf("import \"influxdata/influxdb/schema\"\nschema.measurements(bucket: %q)", parent.Schema), "_value")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	children := make([]*core.Structure, len(names))
+	for i, name := range names {
+		children[i] = &core.Structure{
+			Name:   name,
+			Schema: parent.Schema,
+			Type:   core.StructureTypeTable,
+		}
+	}
+	return children, nil
+}
+
+// IsSystem reports buckets of the server (e.g. _monitoring or _internal).
+func (c *influxDriver) IsSystem(node *core.Structure) bool {
+	return strings.HasPrefix(node.Schema, "_")
+}
+
+func (c *influxDriver) Close() {
+	c.c.http.CloseIdleConnections()
+}
+
+// influxColumns collects columns of all tables of a response, in order of
+// their first appearance.
+type influxColumns struct {
+	names []string
+	index map[string]int
+}
+
+func (ic *influxColumns) add(name string) int {
+	if ic.index == nil {
+		ic.index = make(map[string]int)
+	}
+	if i, ok := ic.index[name]; ok {
+		return i
+	}
+	ic.index[name] = len(ic.names)
+	ic.names = append(ic.names, name)
+	return len(ic.names) - 1
+}
+
+// pad extends rows to all collected columns.
+func (ic *influxColumns) pad(rows []core.Row) []core.Row {
+	for i, row := range rows {
+		if len(row) < len(ic.names) {
+			rows[i] = append(row, make(core.Row, len(ic.names)-len(row))...)
+		}
+	}
+	return rows
+}
+
+// parseInfluxCSV flattens tables of an annotated csv response of a flux query.
+// Values are converted to types of the #datatype annotation and empty values
+// are replaced by the #default annotation.
+func parseInfluxCSV(r io.Reader) (core.Header, []core.Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	var columns influxColumns
+	var rows []core.Row
+
+	var datatypes, defaults []string
+	var positions []int
+	expectHeader := true
+	isError := false
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not parse response: %w", err)
+		}
+
+		switch {
+		case strings.HasPrefix(record[0], "#datatype"):
+			datatypes = record
+			expectHeader = true
+			continue
+		case strings.HasPrefix(record[0], "#default"):
+			defaults = record
+			continue
+		case strings.HasPrefix(record[0], "#"):
+			continue
+		}
+
+		if expectHeader {
+			expectHeader = false
+			// failed queries return a table with error and reference columns
+			isError = len(record) > 1 && record[1] == "error"
+			positions = make([]int, len(record))
+			for i, name := range record {
+				// the first column holds annotations
+				if i == 0 && name == "" {
+					positions[i] = -1
+					continue
+				}
+				if !isError {
+					positions[i] = columns.add(name)
+				}
+			}
+			continue
+		}
+
+		if isError {
+			return nil, nil, fmt.Errorf("influx: %s", record[1])
+		}
+
+		row := make(core.Row, len(columns.names))
+		for i, value := range record {
+			if i >= len(positions) || positions[i] < 0 {
+				continue
+			}
+			if value == "" && i < len(defaults) {
+				value = defaults[i]
+			}
+			datatype := ""
+			if i < len(datatypes) {
+				datatype = datatypes[i]
+			}
+			row[positions[i]] = influxValue(value, datatype)
+		}
+		rows = append(rows, row)
+	}
+
+	return columns.names, columns.pad(rows), nil
+}
+
+// influxValue converts the value of an annotated csv response to its type.
+func influxValue(value, datatype string) any {
+	if value == "" && datatype != "string" {
+		return nil
+	}
+
+	var val any
+	var err error
+	switch {
+	case datatype == "long":
+		val, err = strconv.ParseInt(value, 10, 64)
+	case datatype == "unsignedLong":
+		val, err = strconv.ParseUint(value, 10, 64)
+	case datatype == "double":
+		val, err = strconv.ParseFloat(value, 64)
+	case datatype == "boolean":
+		val, err = strconv.ParseBool(value)
+	case strings.HasPrefix(datatype, "dateTime"):
+		val, err = time.Parse(time.RFC3339Nano, value)
+	default:
+		return value
+	}
+	if err != nil {
+		return value
+	}
+	return val
+}
+
+// influxQLResponse is the json response of influxql queries.
+type influxQLResponse struct {
+	Results []struct {
+		Series []struct {
+			Name    string            `json:"name"`
+			Tags    map[string]string `json:"tags"`
+			Columns []string          `json:"columns"`
+			Values  [][]any           `json:"values"`
+		} `json:"series"`
+		Messages []struct {
+			Level string `json:"level"`
+			Text  string `json:"text"`
+		} `json:"messages"`
+		Error string `json:"error"`
+	} `json:"results"`
+	Error string `json:"error"`
+}
+
+// parseInfluxQLResponse flattens series of all statements of an influxql
+// response. Responses with more than one series get a measurement column
+// and columns of their tags.
+func parseInfluxQLResponse(r io.Reader) (core.Header, []core.Row, *core.Meta, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	var resp influxQLResponse
+	if err := dec.Decode(&resp); err != nil {
+		return nil, nil, nil, fmt.Errorf("could not parse response: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, nil, nil, fmt.Errorf("influx: %s", resp.Error)
+	}
+
+	meta := &core.Meta{}
+	series := 0
+	var tagKeys []string
+	tagSet := make(map[string]bool)
+	for _, result := range resp.Results {
+		if result.Error != "" {
+			return nil, nil, nil, fmt.Errorf("influx: %s", result.Error)
+		}
+		for _, msg := range result.Messages {
+			meta.Notices = append(meta.Notices, fmt.Sprintf("%s: %s", msg.Level, msg.Text))
+		}
+		for _, s := range result.Series {
+			series++
+			for key := range s.Tags {
+				if !tagSet[key] {
+					tagSet[key] = true
+					tagKeys = append(tagKeys, key)
+				}
+			}
+		}
+	}
+	sort.Strings(tagKeys)
+
+	var columns influxColumns
+	if series > 1 {
+		columns.add("measurement")
+	}
+	for _, key := range tagKeys {
+		columns.add(key)
+	}
+
+	var rows []core.Row
+	for _, result := range resp.Results {
+		for _, s := range result.Series {
+			positions := make([]int, len(s.Columns))
+			for i, name := range s.Columns {
+				positions[i] = columns.add(name)
+			}
+
+			for _, values := range s.Values {
+				row := make(core.Row, len(columns.names))
+				if series > 1 {
+					row[0] = s.Name
+				}
+				for key, value := range s.Tags {
+					row[columns.index[key]] = value
+				}
+				for i, value := range values {
+					if i < len(positions) {
+						row[positions[i]] = influxQLValue(s.Columns[i], value)
+					}
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	return columns.names, columns.pad(rows), meta, nil
+}
+
+// influxQLValue converts numbers of the json response: timestamps (requested
+// in nanoseconds) to time and other numbers to integers or floats.
+func influxQLValue(column string, value any) any {
+	num, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+
+	if i, err := num.Int64(); err == nil {
+		if column == "time" {
+			return time.Unix(0, i).UTC()
+		}
+		return i
+	}
+	if f, err := num.Float64(); err == nil {
+		return f
+	}
+	return num.String()
+}
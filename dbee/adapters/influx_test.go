@@ -0,0 +1,140 @@
+package adapters
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestParseInfluxURL(t *testing.T) {
+	r := require.New(t)
+
+	c, err := parseInfluxURL("influxdb://metrics?org=acme&token=secret")
+	r.NoError(err)
+	r.Equal("http://metrics:8086", c.baseURL)
+	r.Equal(influxLanguageFlux, c.language)
+	r.Equal("acme", c.org)
+	r.Equal("secret", c.token)
+
+	c, err = parseInfluxURL("influx://jane:pw@metrics:443?language=InfluxQL&database=telegraf&retention_policy=autogen&ssl=true&timeout=5s")
+	r.NoError(err)
+	r.Equal("https://metrics:443", c.baseURL)
+	r.Equal(influxLanguageInfluxQL, c.language)
+	r.Equal("telegraf", c.database)
+	r.Equal("autogen", c.retentionPolicy)
+	r.Equal("jane", c.user.Username())
+	r.Equal(5*time.Second, c.http.Timeout)
+
+	for _, url := range []string{
+		"postgres://metrics",
+		"influxdb:///telegraf",
+		"influxdb://metrics?language=sql",
+		"influxdb://metrics?ssl=maybe",
+		"influxdb://metrics?timeout=soon",
+	} {
+		_, err := parseInfluxURL(url)
+		r.Error(err, url)
+	}
+}
+
+func TestParseInfluxCSV(t *testing.T) {
+	r := require.New(t)
+
+	header, rows, err := parseInfluxCSV(strings.NewReader("" +
+		"#datatype,string,long,dateTime:RFC3339,double,string\r\n" +
+		"#group,false,false,false,false,true\r\n" +
+		"#default,_result,,,,\r\n" +
+		",result,table,_time,_value,host\r\n" +
+		",,0,2024-01-02T03:04:05Z,1.5,a\r\n" +
+		",,0,2024-01-02T03:04:06Z,,a\r\n" +
+		"\r\n" +
+		"#datatype,string,long,boolean,string\r\n" +
+		"#group,false,false,false,true\r\n" +
+		"#default,_result,,,\r\n" +
+		",result,table,up,region\r\n" +
+		",,1,true,eu\r\n"))
+	r.NoError(err)
+	r.Equal(core.Header{"result", "table", "_time", "_value", "host", "up", "region"}, header)
+	r.Equal([]core.Row{
+		{"_result", int64(0), time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), 1.5, "a", nil, nil},
+		{"_result", int64(0), time.Date(2024, 1, 2, 3, 4, 6, 0, time.UTC), nil, "a", nil, nil},
+		{"_result", int64(1), nil, nil, nil, true, "eu"},
+	}, rows)
+
+	_, _, err = parseInfluxCSV(strings.NewReader("" +
+		"#datatype,string,string\r\n" +
+		"#group,true,true\r\n" +
+		"#default,,\r\n" +
+		",error,reference\r\n" +
+		",bucket not found,\r\n"))
+	r.ErrorContains(err, "bucket not found")
+}
+
+func TestParseInfluxQLResponse(t *testing.T) {
+	r := require.New(t)
+
+	header, rows, meta, err := parseInfluxQLResponse(strings.NewReader(`{"results":[{"statement_id":0,"series":[
+		{"name":"cpu","tags":{"host":"a"},"columns":["time","usage"],"values":[[1704164645000000000,1.5],[1704164646000000000,2]]},
+		{"name":"mem","tags":{"region":"eu"},"columns":["time","used"],"values":[[1704164645000000000,42]]}
+	],"messages":[{"level":"warning","text":"deprecated"}]}]}`))
+	r.NoError(err)
+	r.Equal(core.Header{"measurement", "host", "region", "time", "usage", "used"}, header)
+	ts := time.Unix(1704164645, 0).UTC()
+	r.Equal([]core.Row{
+		{"cpu", "a", nil, ts, 1.5, nil},
+		{"cpu", "a", nil, ts.Add(time.Second), int64(2), nil},
+		{"mem", nil, "eu", ts, nil, int64(42)},
+	}, rows)
+	r.Equal([]string{"warning: deprecated"}, meta.Notices)
+
+	_, _, _, err = parseInfluxQLResponse(strings.NewReader(`{"results":[{"statement_id":0,"error":"database not found: x"}]}`))
+	r.ErrorContains(err, "database not found")
+}
+
+func TestInfluxDriver(t *testing.T) {
+	r := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.Equal("/query", req.URL.Path)
+		r.NoError(req.ParseForm())
+		r.Equal("telegraf", req.Form.Get("db"))
+		user, password, _ := req.BasicAuth()
+		r.Equal("jane", user)
+		r.Equal("pw", password)
+
+		switch req.Form.Get("q") {
+		case "SHOW DATABASES":
+			_, _ = io.WriteString(w, `{"results":[{"series":[{"name":"databases","columns":["name"],"values":[["telegraf"],["_internal"]]}]}]}`)
+		case `SHOW MEASUREMENTS ON "telegraf"`:
+			_, _ = io.WriteString(w, `{"results":[{"series":[{"name":"measurements","columns":["name"],"values":[["cpu"]]}]}]}`)
+		case `SHOW MEASUREMENTS ON "_internal"`:
+			_, _ = io.WriteString(w, `{"results":[{}]}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"error":"error parsing query"}`)
+		}
+	}))
+	defer server.Close()
+
+	driver, err := (&Influx{}).Connect("influxdb://jane:pw@" + strings.TrimPrefix(server.URL, "http://") + "?language=influxql&database=telegraf")
+	r.NoError(err)
+	defer driver.Close()
+
+	structure, err := driver.Structure()
+	r.NoError(err)
+	r.Len(structure, 2)
+	r.Equal("telegraf", structure[0].Name)
+	r.Equal([]*core.Structure{{Name: "cpu", Schema: "telegraf", Type: core.StructureTypeTable}}, structure[0].Children)
+	r.True(driver.(core.SystemObjectClassifier).IsSystem(structure[1]))
+
+	_, err = driver.Query(context.Background(), "SELEC")
+	r.ErrorContains(err, "error parsing query")
+}
@@ -7,7 +7,10 @@ import (
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 )
 
-var _ core.Driver = (*libSQLDriver)(nil)
+var (
+	_ core.Driver            = (*libSQLDriver)(nil)
+	_ core.PoolStatsProvider = (*libSQLDriver)(nil)
+)
 
 type libSQLDriver struct {
 	c *builders.Client
@@ -19,7 +22,7 @@ func (c *libSQLDriver) Query(ctx context.Context, query string) (core.ResultStre
 }
 
 func (c *libSQLDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
-	return c.c.ColumnsFromQuery("SELECT name, type FROM pragma_table_info('%s')", opts.Table)
+	return c.c.ColumnsFromQuery(`SELECT name, type, NOT "notnull", dflt_value, pk > 0 FROM pragma_table_info('%s')`, opts.Table)
 }
 
 func (c *libSQLDriver) Structure() ([]*core.Structure, error) {
@@ -52,3 +55,7 @@ func (c *libSQLDriver) Structure() ([]*core.Structure, error) {
 func (c *libSQLDriver) Close() {
 	c.c.Close()
 }
+
+func (c *libSQLDriver) PoolStats() *core.PoolStats {
+	return c.c.PoolStats()
+}
@@ -7,16 +7,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 var (
 	_ core.Driver           = (*mongoDriver)(nil)
 	_ core.DatabaseSwitcher = (*mongoDriver)(nil)
+	_ core.Subscriber       = (*mongoDriver)(nil)
 )
 
 type mongoDriver struct {
@@ -108,6 +111,53 @@ func (c *mongoDriver) Query(ctx context.Context, query string) (core.ResultStrea
 	return result, nil
 }
 
+// Subscribe opens a change stream of the collection, or of the whole current
+// database if no collection is provided. Change streams require a replica set
+// or a sharded cluster.
+func (c *mongoDriver) Subscribe(ctx context.Context, channel string) (core.ResultStream, error) {
+	dbName, err := c.getCurrentDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	db := c.c.Database(dbName)
+
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	var stream *mongo.ChangeStream
+	if channel == "" {
+		stream, err = db.Watch(ctx, mongo.Pipeline{}, opts)
+	} else {
+		stream, err = db.Collection(channel).Watch(ctx, mongo.Pipeline{}, opts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Watch: %w", err)
+	}
+
+	next, hasNext := builders.NextEvents(ctx, func(ctx context.Context, yield func(...any)) error {
+		for stream.Next(ctx) {
+			var event bson.M
+			if err := stream.Decode(&event); err != nil {
+				return fmt.Errorf("stream.Decode: %w", err)
+			}
+
+			namespace := ""
+			if ns, ok := event["ns"].(bson.M); ok {
+				namespace = fmt.Sprintf("%v.%v", ns["db"], ns["coll"])
+			}
+			yield(time.Now(), event["operationType"], namespace, newMongoResponse(event["documentKey"]), newMongoResponse(event))
+		}
+		return stream.Err()
+	})
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(core.Header{"received_at", "operation", "namespace", "document_key", "event"}).
+		WithMeta(&core.Meta{
+			SchemaType: core.SchemaLess,
+		}).
+		WithCloseFunc(func() { _ = stream.Close(context.Background()) }).
+		Build(), nil
+}
+
 func (c *mongoDriver) Structure() ([]*core.Structure, error) {
 	ctx := context.Background()
 
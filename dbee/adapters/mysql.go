@@ -1,6 +1,7 @@
 package adapters
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"regexp"
@@ -37,7 +38,10 @@ func (m *MySQL) Connect(url string) (core.Driver, error) {
 	}
 
 	return &mySQLDriver{
-		c: builders.NewClient(db),
+		c: builders.NewClient(db,
+			builders.WithCustomTypeProcessor("geometry", mySQLGeometryProcessor),
+			builders.WithNoticeHook(mySQLNoticeHook),
+		),
 	}, nil
 }
 
@@ -50,3 +54,23 @@ func (*MySQL) GetHelpers(opts *core.TableOptions) map[string]string {
 		"Primary Keys": fmt.Sprintf("SELECT * FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS WHERE TABLE_SCHEMA = '%s' AND TABLE_NAME = '%s' AND CONSTRAINT_TYPE = 'PRIMARY KEY'", opts.Schema, opts.Table),
 	}
 }
+
+// mySQLNoticeHook collects warnings of the executed statement with SHOW WARNINGS.
+func mySQLNoticeHook(conn *sql.Conn, onNotice func(string)) (func(), error) {
+	return func() {
+		rows, err := conn.QueryContext(context.Background(), "SHOW WARNINGS")
+		if err != nil {
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var level, message string
+			var code int
+			if err := rows.Scan(&level, &code, &message); err != nil {
+				return
+			}
+			onNotice(fmt.Sprintf("%s %d: %s", level, code, message))
+		}
+	}, nil
+}
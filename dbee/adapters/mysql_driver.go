@@ -2,34 +2,441 @@ package adapters
 
 import (
 	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 )
 
-var _ core.Driver = (*mySQLDriver)(nil)
+// mySQLErrorLinePattern matches the line of syntax errors.
+var mySQLErrorLinePattern = regexp.MustCompile(`at line (\d+)$`)
+
+// mySQLMissingObjectPatterns match names of undefined objects in messages of
+// errors by their number.
+var mySQLMissingObjectPatterns = map[uint16]struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	1054: {core.ErrorObjectColumn, regexp.MustCompile(`^Unknown column '([^']+)'`)},
+	1146: {core.ErrorObjectTable, regexp.MustCompile(`^Table '([^']+)' doesn't exist`)},
+	1305: {core.ErrorObjectFunction, regexp.MustCompile(`^(?:FUNCTION|PROCEDURE) (\S+) does not exist`)},
+	1049: {core.ErrorObjectSchema, regexp.MustCompile(`^Unknown database '([^']+)'`)},
+}
+
+var (
+	_ core.Driver                    = (*mySQLDriver)(nil)
+	_ core.ProcedureCaller           = (*mySQLDriver)(nil)
+	_ core.ActivityMonitor           = (*mySQLDriver)(nil)
+	_ core.TransientErrorClassifier  = (*mySQLDriver)(nil)
+	_ core.ConnectionErrorClassifier = (*mySQLDriver)(nil)
+	_ core.Transactor                = (*mySQLDriver)(nil)
+	_ core.Planner                   = (*mySQLDriver)(nil)
+	_ core.Importer                  = (*mySQLDriver)(nil)
+	_ core.KeyLister                 = (*mySQLDriver)(nil)
+	_ core.IdentifierQuoter          = (*mySQLDriver)(nil)
+	_ core.StatementDialectProvider  = (*mySQLDriver)(nil)
+	_ core.JSONPathDialect           = (*mySQLDriver)(nil)
+	_ core.BlobWriter                = (*mySQLDriver)(nil)
+	_ core.TableInspector            = (*mySQLDriver)(nil)
+	_ core.TriggerLister             = (*mySQLDriver)(nil)
+	_ core.TableStatsProvider        = (*mySQLDriver)(nil)
+	_ core.StructureLoader           = (*mySQLDriver)(nil)
+	_ core.ObjectSearcher            = (*mySQLDriver)(nil)
+	_ core.TypeLister                = (*mySQLDriver)(nil)
+	_ core.GrantLister               = (*mySQLDriver)(nil)
+	_ core.ServerInfoProvider        = (*mySQLDriver)(nil)
+	_ core.PartitionLister           = (*mySQLDriver)(nil)
+	_ core.PartitionManager          = (*mySQLDriver)(nil)
+	_ core.SystemObjectClassifier    = (*mySQLDriver)(nil)
+	_ core.DependencyLister          = (*mySQLDriver)(nil)
+	_ core.Commenter                 = (*mySQLDriver)(nil)
+	_ core.KeywordProvider           = (*mySQLDriver)(nil)
+	_ core.SignatureProvider         = (*mySQLDriver)(nil)
+	_ core.ErrorClassifier           = (*mySQLDriver)(nil)
+	_ core.ErrorExplainer            = (*mySQLDriver)(nil)
+	_ core.PoolStatsProvider         = (*mySQLDriver)(nil)
+	_ core.SampleDialect             = (*mySQLDriver)(nil)
+)
 
 type mySQLDriver struct {
 	c *builders.Client
 }
 
+// mySQLGeometryProcessor converts values of spatial columns, which are stored
+// as a little endian SRID followed by well-known binary, to [core.Geometry].
+func mySQLGeometryProcessor(a any) any {
+	b, ok := a.([]byte)
+	if !ok {
+		return a
+	}
+	if len(b) < 4 {
+		return string(b)
+	}
+
+	geom, err := core.ParseWKB(b[4:], int(binary.LittleEndian.Uint32(b)))
+	if err != nil {
+		return string(b)
+	}
+	return geom
+}
+
 func (c *mySQLDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
 	// run query, fallback to affected rows
 	return c.c.QueryUntilNotEmpty(ctx, query, "select ROW_COUNT() as 'Rows Affected'")
 }
 
+// CallProcedure calls the procedure with CALL. MySQL doesn't support binding of
+// output parameters, so session variables are used instead and selected after
+// the result sets of the procedure.
+func (c *mySQLDriver) CallProcedure(ctx context.Context, name string, params []*core.ProcedureParam) (core.ResultStream, error) {
+	dialect := core.NewDialect(c)
+
+	var statements []*builders.Statement
+	var callArgs []any
+	var selects []string
+
+	placeholders := make([]string, len(params))
+	for i, p := range params {
+		if !p.IsOutput() {
+			placeholders[i] = "?"
+			callArgs = append(callArgs, p.Value)
+			continue
+		}
+
+		variable := "@" + dialect.QuoteIdentifier("dbee_"+p.Name)
+		placeholders[i] = variable
+		selects = append(selects, fmt.Sprintf("%s AS %s", variable, dialect.QuoteIdentifier(p.Name)))
+		if p.Mode == core.ParamModeInOut {
+			statements = append(statements, &builders.Statement{Query: fmt.Sprintf("SET %s = ?", variable), Args: []any{p.Value}})
+		} else {
+			statements = append(statements, &builders.Statement{Query: fmt.Sprintf("SET %s = NULL", variable)})
+		}
+	}
+
+	statements = append(statements, &builders.Statement{
+		Query: fmt.Sprintf("CALL %s(%s)", procedureName(dialect, name), strings.Join(placeholders, ", ")),
+		Args:  callArgs,
+	})
+	if len(selects) > 0 {
+		statements = append(statements, &builders.Statement{Query: "SELECT " + strings.Join(selects, ", ")})
+	}
+
+	return c.c.QuerySequence(ctx, statements...)
+}
+
 func (c *mySQLDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
-	return c.c.ColumnsFromQuery("DESCRIBE `%s`", opts.Table)
+	return c.c.ColumnsFromQuery(`
+		SELECT column_name, column_type, is_nullable, column_default, column_key = 'PRI', column_comment
+		FROM information_schema.columns
+		WHERE
+			table_schema = COALESCE(NULLIF('%s', ''), DATABASE()) AND
+			table_name = '%s'
+		ORDER BY ordinal_position
+		`, opts.Schema, opts.Table)
+}
+
+func (c *mySQLDriver) PrimaryKey(opts *core.TableOptions) ([]string, error) {
+	columns, err := c.c.ColumnsFromQuery(`
+		SELECT column_name, 'key'
+		FROM information_schema.key_column_usage
+		WHERE
+			constraint_name = 'PRIMARY' AND
+			table_schema = COALESCE(NULLIF('%s', ''), DATABASE()) AND
+			table_name = '%s'
+		ORDER BY ordinal_position
+		`, opts.Schema, opts.Table)
+	if err != nil {
+		return nil, err
+	}
+	return columnNames(columns), nil
+}
+
+func (c *mySQLDriver) Indexes(ctx context.Context, opts *core.TableOptions) ([]*core.Index, error) {
+	return c.c.IndexesFromQuery(ctx, `
+		SELECT index_name, column_name, non_unique = 0, index_name = 'PRIMARY'
+		FROM information_schema.statistics
+		WHERE
+			table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND
+			table_name = ?
+		ORDER BY index_name, seq_in_index
+		`, opts.Schema, opts.Table)
+}
+
+func (c *mySQLDriver) Constraints(ctx context.Context, opts *core.TableOptions) ([]*core.Constraint, error) {
+	return c.c.ConstraintsFromQuery(ctx, `
+		SELECT
+			tc.constraint_name,
+			tc.constraint_type,
+			kcu.column_name,
+			NULL,
+			kcu.referenced_table_schema,
+			kcu.referenced_table_name,
+			kcu.referenced_column_name
+		FROM information_schema.table_constraints tc
+		LEFT JOIN information_schema.key_column_usage kcu
+			ON kcu.constraint_schema = tc.constraint_schema AND
+			kcu.constraint_name = tc.constraint_name AND
+			kcu.table_name = tc.table_name
+		WHERE
+			tc.table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND
+			tc.table_name = ?
+		ORDER BY tc.constraint_name, kcu.ordinal_position
+		`, opts.Schema, opts.Table)
+}
+
+func (c *mySQLDriver) Triggers(ctx context.Context, opts *core.TableOptions) ([]*core.Trigger, error) {
+	return c.c.TriggersFromQuery(ctx, `
+		SELECT trigger_name, event_manipulation, action_timing, NULL
+		FROM information_schema.triggers
+		WHERE
+			event_object_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND
+			event_object_table = ?
+		ORDER BY trigger_name
+		`, opts.Schema, opts.Table)
+}
+
+func (c *mySQLDriver) Roles(ctx context.Context) ([]*core.Role, error) {
+	// reading mysql.user requires privileges on the mysql schema
+	return c.c.RolesFromQuery(ctx, `
+		SELECT CONCAT(user, '@', host), super_priv = 'Y', account_locked = 'N', NULL
+		FROM mysql.user
+		ORDER BY user, host
+		`)
+}
+
+func (c *mySQLDriver) Grants(ctx context.Context, opts *core.TableOptions) ([]*core.Grant, error) {
+	return c.c.GrantsFromQuery(ctx, `
+		SELECT grantee, privilege_type, is_grantable, ''
+		FROM information_schema.table_privileges
+		WHERE
+			table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND
+			table_name = ?
+		ORDER BY grantee, privilege_type
+		`, opts.Schema, opts.Table)
+}
+
+func (c *mySQLDriver) TableStats(ctx context.Context) ([]*core.TableStats, error) {
+	// table_rows is an estimate for InnoDB tables
+	return c.c.TableStatsFromQuery(ctx, `
+		SELECT table_schema, table_name, table_rows, data_length + index_length
+		FROM information_schema.tables
+		WHERE table_type = 'BASE TABLE'
+		`)
+}
+
+func (c *mySQLDriver) Partitions(ctx context.Context, opts *core.TableOptions) ([]*core.Partition, error) {
+	return c.c.PartitionsFromQuery(ctx, `
+		SELECT
+			TABLE_SCHEMA,
+			PARTITION_NAME,
+			CASE
+				WHEN PARTITION_METHOD LIKE 'RANGE%' THEN CONCAT('VALUES LESS THAN (', PARTITION_DESCRIPTION, ')')
+				WHEN PARTITION_METHOD LIKE 'LIST%' THEN CONCAT('VALUES IN (', PARTITION_DESCRIPTION, ')')
+				ELSE CONCAT(PARTITION_METHOD, ' (', PARTITION_EXPRESSION, ')')
+			END,
+			TABLE_ROWS,
+			DATA_LENGTH + INDEX_LENGTH
+		FROM information_schema.partitions
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND PARTITION_NAME IS NOT NULL
+		ORDER BY PARTITION_ORDINAL_POSITION
+		`, opts.Schema, opts.Table)
+}
+
+// DetachPartition is not supported, MySQL can only exchange a partition with a table.
+func (c *mySQLDriver) DetachPartition(ctx context.Context, opts *core.TableOptions, partition *core.Partition) error {
+	return fmt.Errorf("%w: mysql can't detach partitions", core.ErrPartitionActionNotSupported)
+}
+
+func (c *mySQLDriver) DropPartition(ctx context.Context, opts *core.TableOptions, partition *core.Partition) error {
+	name := c.QuoteIdentifier(opts.Table)
+	if opts.Schema != "" {
+		name = c.QuoteIdentifier(opts.Schema) + "." + name
+	}
+	return c.c.ExecArgs(ctx, fmt.Sprintf("ALTER TABLE %s DROP PARTITION %s", name, c.QuoteIdentifier(partition.Name)))
+}
+
+// DependsOn lists tables and views used by a view and tables referenced by
+// foreign keys of a table. Views are tracked since MySQL 8.0.13.
+func (c *mySQLDriver) DependsOn(ctx context.Context, opts *core.TableOptions) ([]*core.DependencyNode, error) {
+	return c.c.DependenciesFromQuery(ctx, `
+		SELECT DISTINCT u.TABLE_SCHEMA, u.TABLE_NAME, IF(t.TABLE_TYPE = 'VIEW', 'view', 'table')
+		FROM information_schema.view_table_usage u
+		LEFT JOIN information_schema.tables t ON t.TABLE_SCHEMA = u.TABLE_SCHEMA AND t.TABLE_NAME = u.TABLE_NAME
+		WHERE u.VIEW_SCHEMA = ? AND u.VIEW_NAME = ?
+		UNION
+		SELECT DISTINCT UNIQUE_CONSTRAINT_SCHEMA, REFERENCED_TABLE_NAME, 'table'
+		FROM information_schema.referential_constraints
+		WHERE CONSTRAINT_SCHEMA = ? AND TABLE_NAME = ? AND REFERENCED_TABLE_NAME <> TABLE_NAME
+		ORDER BY 1, 2
+		`, opts.Schema, opts.Table, opts.Schema, opts.Table)
+}
+
+// Signatures lists the function or procedure with parameters from
+// information_schema. MySQL routines can't be overloaded, but a function
+// and a procedure can have the same name.
+func (c *mySQLDriver) Signatures(ctx context.Context, opts *core.TableOptions) ([]*core.Signature, error) {
+	return c.c.SignaturesFromQuery(ctx, `
+		SELECT
+			r.ROUTINE_SCHEMA,
+			r.ROUTINE_NAME,
+			r.ROUTINE_TYPE,
+			COALESCE(p.ORDINAL_POSITION, 0),
+			COALESCE(p.PARAMETER_NAME, ''),
+			COALESCE(p.DTD_IDENTIFIER, ''),
+			LOWER(COALESCE(p.PARAMETER_MODE, 'in')),
+			COALESCE(r.DTD_IDENTIFIER, ''),
+			r.ROUTINE_COMMENT
+		FROM information_schema.routines r
+		LEFT JOIN information_schema.parameters p
+			ON p.SPECIFIC_SCHEMA = r.ROUTINE_SCHEMA AND p.SPECIFIC_NAME = r.SPECIFIC_NAME AND p.ORDINAL_POSITION > 0
+		WHERE r.ROUTINE_NAME = ? AND (? = '' OR r.ROUTINE_SCHEMA = ?)
+		ORDER BY r.ROUTINE_SCHEMA, r.ROUTINE_TYPE, p.ORDINAL_POSITION
+		`, opts.Table, opts.Schema, opts.Schema)
+}
+
+// Dependents lists views using a table or a view and tables referencing
+// a table with foreign keys.
+func (c *mySQLDriver) Dependents(ctx context.Context, opts *core.TableOptions) ([]*core.DependencyNode, error) {
+	return c.c.DependenciesFromQuery(ctx, `
+		SELECT DISTINCT VIEW_SCHEMA, VIEW_NAME, 'view'
+		FROM information_schema.view_table_usage
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+		UNION
+		SELECT DISTINCT CONSTRAINT_SCHEMA, TABLE_NAME, 'table'
+		FROM information_schema.referential_constraints
+		WHERE UNIQUE_CONSTRAINT_SCHEMA = ? AND REFERENCED_TABLE_NAME = ? AND REFERENCED_TABLE_NAME <> TABLE_NAME
+		ORDER BY 1, 2
+		`, opts.Schema, opts.Table, opts.Schema, opts.Table)
+}
+
+func (c *mySQLDriver) ServerInfo(ctx context.Context) (*core.ServerInfo, error) {
+	return c.c.ServerInfoFromQuery(ctx, `
+		SELECT 'version', NULL, VERSION()
+		UNION ALL
+		SELECT 'uptime', NULL, VARIABLE_VALUE FROM performance_schema.global_status
+		WHERE VARIABLE_NAME = 'Uptime'
+		UNION ALL
+		SELECT 'extension', PLUGIN_NAME, PLUGIN_VERSION FROM information_schema.plugins
+		WHERE PLUGIN_STATUS = 'ACTIVE'
+		UNION ALL
+		SELECT 'setting', 'character_set_server', @@character_set_server
+		UNION ALL
+		SELECT 'setting', 'collation_server', @@collation_server
+		UNION ALL
+		SELECT 'setting', 'time_zone', @@time_zone
+		UNION ALL
+		SELECT 'setting', 'max_connections', @@max_connections
+		UNION ALL
+		SELECT 'setting', 'sql_mode', @@sql_mode
+		`)
+}
+
+func (c *mySQLDriver) QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (c *mySQLDriver) Keywords() []string {
+	return []string{"LIMIT", "REPLACE", "ON DUPLICATE KEY UPDATE", "STRAIGHT_JOIN", "IGNORE", "SHOW", "DESCRIBE", "EXPLAIN", "AUTO_INCREMENT", "ENGINE"}
+}
+
+// SetComment sets comments of tables. Column comments are not supported,
+// since MySQL can only change them by redefining the whole column.
+func (c *mySQLDriver) SetComment(ctx context.Context, opts *core.TableOptions, column string, comment string) error {
+	if column != "" {
+		return fmt.Errorf("%w: mysql can't comment a column without redefining it", core.ErrCommentsNotSupported)
+	}
+
+	name := c.QuoteIdentifier(opts.Table)
+	if opts.Schema != "" {
+		name = c.QuoteIdentifier(opts.Schema) + "." + name
+	}
+	value := "'" + strings.NewReplacer(`\`, `\\`, `'`, `''`).Replace(comment) + "'"
+
+	return c.c.ExecArgs(ctx, fmt.Sprintf("ALTER TABLE %s COMMENT = %s", name, value))
 }
 
 func (c *mySQLDriver) Structure() ([]*core.Structure, error) {
-	query := `SELECT table_schema, table_name FROM information_schema.tables`
+	query := `
+		SELECT table_schema, table_name, IF(table_type = 'VIEW', '', table_comment)
+		FROM information_schema.tables
+		`
 
 	rows, err := c.Query(context.TODO(), query)
 	if err != nil {
 		return nil, err
 	}
 
+	return getMySQLStructure(rows)
+}
+
+func (c *mySQLDriver) StructureRoots(ctx context.Context) ([]*core.Structure, error) {
+	return c.c.SchemasFromQuery(ctx, `SELECT schema_name FROM information_schema.schemata`)
+}
+
+func (c *mySQLDriver) StructureChildren(ctx context.Context, parent *core.Structure) ([]*core.Structure, error) {
+	rows, err := c.c.QueryArgs(ctx, `
+		SELECT table_schema, table_name, IF(table_type = 'VIEW', '', table_comment)
+		FROM information_schema.tables
+		WHERE table_schema = ?
+		`, parent.Schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	structure, err := getMySQLStructure(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(structure) < 1 {
+		return nil, nil
+	}
+
+	return structure[0].Children, nil
+}
+
+// IsSystem reports databases of the server itself.
+func (c *mySQLDriver) IsSystem(node *core.Structure) bool {
+	if node.Type != core.StructureTypeNone {
+		return false
+	}
+	switch strings.ToLower(node.Schema) {
+	case "information_schema", "performance_schema", "mysql", "sys":
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *mySQLDriver) SearchObjects(ctx context.Context, pattern string, limit int) ([]*core.ObjectMatch, error) {
+	like := "%" + strings.ToLower(pattern) + "%"
+	return c.c.ObjectMatchesFromQuery(ctx, `
+		SELECT table_schema, '', table_name, IF(table_type = 'VIEW', 'view', 'table')
+		FROM information_schema.tables
+		WHERE LOWER(table_name) LIKE ?
+		UNION ALL
+		SELECT routine_schema, '', routine_name, LOWER(routine_type)
+		FROM information_schema.routines
+		WHERE LOWER(routine_name) LIKE ?
+		UNION ALL
+		SELECT table_schema, table_name, column_name, 'column'
+		FROM information_schema.columns
+		WHERE LOWER(column_name) LIKE ?
+		LIMIT ?
+		`, like, like, like, limit)
+}
+
+// getMySQLStructure groups tables under their schemas.
+// rows is a 2 column wide result of schema and table names.
+func getMySQLStructure(rows core.ResultStream) ([]*core.Structure, error) {
 	children := make(map[string][]*core.Structure)
 
 	for rows.HasNext() {
@@ -38,14 +445,16 @@ func (c *mySQLDriver) Structure() ([]*core.Structure, error) {
 			return nil, err
 		}
 
-		// We know for a fact there are 2 string fields (see query above)
+		// We know for a fact there are 3 string fields (see queries above)
 		schema := row[0].(string)
 		table := row[1].(string)
+		comment, _ := row[2].(string)
 
 		children[schema] = append(children[schema], &core.Structure{
-			Name:   table,
-			Schema: schema,
-			Type:   core.StructureTypeTable,
+			Name:    table,
+			Schema:  schema,
+			Type:    core.StructureTypeTable,
+			Comment: comment,
 		})
 
 	}
@@ -64,6 +473,371 @@ func (c *mySQLDriver) Structure() ([]*core.Structure, error) {
 	return structure, nil
 }
 
+func (c *mySQLDriver) Routines() ([]*core.Structure, error) {
+	return c.c.RoutinesFromQuery(`SELECT routine_schema, routine_name, routine_type FROM information_schema.routines`)
+}
+
+// Types lists ENUM and SET columns, since MySQL has no user-defined types.
+// Types are named after their columns (table.column).
+func (c *mySQLDriver) Types() ([]*core.Structure, error) {
+	rows, err := c.c.QueryArgs(context.Background(), `
+		SELECT table_schema, table_name, column_name, data_type, column_type
+		FROM information_schema.columns
+		WHERE data_type IN ('enum', 'set')
+		ORDER BY table_schema, table_name, ordinal_position
+		`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var types []*core.Structure
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		schema, _ := row[0].(string)
+		table, _ := row[1].(string)
+		column, _ := row[2].(string)
+		kind, _ := row[3].(string)
+		columnType, _ := row[4].(string)
+
+		typ := &core.Structure{
+			Name:   table + "." + column,
+			Schema: schema,
+			Type:   core.StructureTypeType,
+			Status: kind,
+		}
+		for _, value := range mysqlEnumValues(columnType) {
+			typ.Children = append(typ.Children, &core.Structure{
+				Name:   value,
+				Schema: schema,
+				Type:   core.StructureTypeEnumValue,
+			})
+		}
+		types = append(types, typ)
+	}
+
+	return types, nil
+}
+
+// mysqlEnumValues parses values of an ENUM or SET column type (e.g. "enum('a','b')").
+func mysqlEnumValues(columnType string) []string {
+	start := strings.Index(columnType, "(")
+	end := strings.LastIndex(columnType, ")")
+	if start < 0 || end < start {
+		return nil
+	}
+
+	var values []string
+	var sb strings.Builder
+	quoted := false
+	list := columnType[start+1 : end]
+	for i := 0; i < len(list); i++ {
+		ch := list[i]
+		switch {
+		case ch == '\'' && quoted && i+1 < len(list) && list[i+1] == '\'':
+			// escaped quote
+			sb.WriteByte(ch)
+			i++
+		case ch == '\'':
+			quoted = !quoted
+			if !quoted {
+				values = append(values, sb.String())
+				sb.Reset()
+			}
+		case quoted:
+			sb.WriteByte(ch)
+		}
+	}
+
+	return values
+}
+
+func (c *mySQLDriver) Definition(ctx context.Context, opts *core.TableOptions) (string, error) {
+	name := c.QuoteIdentifier(opts.Table)
+	if opts.Schema != "" {
+		name = c.QuoteIdentifier(opts.Schema) + "." + name
+	}
+
+	// column of SHOW CREATE result which holds the CREATE statement
+	var kind string
+	col := 2
+	switch opts.Materialization {
+	case core.StructureTypeFunction:
+		kind = "FUNCTION"
+	case core.StructureTypeProcedure:
+		kind = "PROCEDURE"
+	case core.StructureTypeTrigger:
+		kind = "TRIGGER"
+	case core.StructureTypeTable:
+		kind, col = "TABLE", 1
+	case core.StructureTypeView:
+		kind, col = "VIEW", 1
+	case core.StructureTypeIndex:
+		return c.indexDDL(ctx, opts)
+	default:
+		return "", fmt.Errorf("%w: %s", core.ErrDefinitionNotSupported, opts.Materialization)
+	}
+
+	return c.c.DefinitionFromQuery(ctx, col, fmt.Sprintf("SHOW CREATE %s %s", kind, name))
+}
+
+// indexDDL reconstructs CREATE INDEX statements of the index. Index names are
+// unique per table, so indexes of all tables in the schema with the name are returned.
+func (c *mySQLDriver) indexDDL(ctx context.Context, opts *core.TableOptions) (string, error) {
+	result, err := c.c.QueryArgs(ctx, `
+		SELECT table_name, non_unique, column_name
+		FROM information_schema.statistics
+		WHERE
+			table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND
+			index_name = ?
+		ORDER BY table_name, seq_in_index
+		`, opts.Schema, opts.Table)
+	if err != nil {
+		return "", err
+	}
+	defer result.Close()
+
+	var tables []string
+	columns := make(map[string][]string)
+	unique := make(map[string]bool)
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return "", err
+		}
+		table := fmt.Sprint(row[0])
+		if _, ok := columns[table]; !ok {
+			tables = append(tables, table)
+		}
+		columns[table] = append(columns[table], c.QuoteIdentifier(fmt.Sprint(row[2])))
+		unique[table] = fmt.Sprint(row[1]) == "0"
+	}
+
+	var sb strings.Builder
+	for _, table := range tables {
+		cols := strings.Join(columns[table], ", ")
+		switch {
+		case opts.Table == "PRIMARY":
+			fmt.Fprintf(&sb, "ALTER TABLE %s ADD PRIMARY KEY (%s);\n", c.QuoteIdentifier(table), cols)
+		case unique[table]:
+			fmt.Fprintf(&sb, "CREATE UNIQUE INDEX %s ON %s (%s);\n", c.QuoteIdentifier(opts.Table), c.QuoteIdentifier(table), cols)
+		default:
+			fmt.Fprintf(&sb, "CREATE INDEX %s ON %s (%s);\n", c.QuoteIdentifier(opts.Table), c.QuoteIdentifier(table), cols)
+		}
+	}
+
+	return sb.String(), nil
+}
+
 func (c *mySQLDriver) Close() {
 	c.c.Close()
 }
+
+func (c *mySQLDriver) PoolStats() *core.PoolStats {
+	return c.c.PoolStats()
+}
+
+func (c *mySQLDriver) Activity(ctx context.Context) (core.ResultStream, error) {
+	// same as SHOW FULL PROCESSLIST, but without the current session
+	return c.c.Query(ctx, `
+		SELECT id, user, host, db, command, time, state, info
+		FROM information_schema.processlist
+		WHERE id <> CONNECTION_ID()
+		ORDER BY time DESC
+	`)
+}
+
+func (c *mySQLDriver) KillSession(ctx context.Context, id string) error {
+	n, err := numericSessionID(id)
+	if err != nil {
+		return err
+	}
+	return c.c.ExecArgs(ctx, fmt.Sprintf("KILL %d", n))
+}
+
+// IsTransient reports deadlocks and lock wait timeouts, which roll back the
+// statement.
+func (c *mySQLDriver) IsTransient(err error) bool {
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		// ER_LOCK_DEADLOCK, ER_LOCK_WAIT_TIMEOUT
+		return myErr.Number == 1213 || myErr.Number == 1205
+	}
+	return isUnsentError(err)
+}
+
+// IsConnectionError reports dropped connections.
+func (c *mySQLDriver) IsConnectionError(err error) bool {
+	return errors.Is(err, mysql.ErrInvalidConn) || isConnectionError(err)
+}
+
+// ClassifyError returns details of mysql errors. The line of the error is
+// parsed from the message of syntax errors ("... at line 2").
+func (c *mySQLDriver) ClassifyError(err error) *core.QueryError {
+	var myErr *mysql.MySQLError
+	if !errors.As(err, &myErr) {
+		return nil
+	}
+
+	category := core.ErrorCategoryUnknown
+	switch myErr.Number {
+	case 1045, 1698: // access denied for user
+		category = core.ErrorCategoryAuth
+	case 1044, 1142, 1143, 1227: // access denied to database, table, column, operation
+		category = core.ErrorCategoryPermission
+	case 1064, 1149: // parse error
+		category = core.ErrorCategorySyntax
+	case 1049, 1146, 1054, 1305: // unknown database, table, column, function
+		category = core.ErrorCategoryNotFound
+	case 1048, 1062, 1451, 1452, 3819: // not null, duplicate key, foreign key, check
+		category = core.ErrorCategoryConstraint
+	case 1205, 3024: // lock wait timeout, max execution time exceeded
+		category = core.ErrorCategoryTimeout
+	case 2006, 2013: // server has gone away, lost connection
+		category = core.ErrorCategoryNetwork
+	}
+
+	line := 0
+	if match := mySQLErrorLinePattern.FindStringSubmatch(myErr.Message); match != nil {
+		line, _ = strconv.Atoi(match[1])
+	}
+
+	qErr := &core.QueryError{
+		Category: category,
+		Code:     strconv.Itoa(int(myErr.Number)),
+		Line:     line,
+	}
+	if missing, ok := mySQLMissingObjectPatterns[myErr.Number]; ok {
+		if match := missing.pattern.FindStringSubmatch(myErr.Message); match != nil {
+			qErr.ObjectKind = missing.kind
+			qErr.Object = match[1]
+		}
+	}
+
+	return qErr
+}
+
+// ExplainError returns queries of the other transactions of the latest
+// deadlock reported by InnoDB (requires the PROCESS privilege).
+func (c *mySQLDriver) ExplainError(ctx context.Context, qErr *core.QueryError, query string) []*core.ErrorHint {
+	if qErr.Code != "1213" { // ER_LOCK_DEADLOCK
+		return nil
+	}
+
+	result, err := c.c.QueryArgs(ctx, "SHOW ENGINE INNODB STATUS")
+	if err != nil {
+		return nil
+	}
+	defer result.Close()
+	if !result.HasNext() {
+		return nil
+	}
+	row, err := result.Next()
+	if err != nil || len(row) < 3 {
+		return nil
+	}
+
+	var hints []*core.ErrorHint
+	for _, other := range mySQLDeadlockQueries(fmt.Sprint(row[2])) {
+		if other == strings.TrimSpace(query) {
+			continue
+		}
+		hints = append(hints, &core.ErrorHint{
+			Kind:    core.ErrorHintConflictingQuery,
+			Message: "another transaction of the deadlock ran this query",
+			Query:   other,
+		})
+	}
+
+	return hints
+}
+
+// mySQLDeadlockQueries returns queries of transactions in the "LATEST
+// DETECTED DEADLOCK" section of the InnoDB status. Queries follow the
+// "MySQL thread id" line of each transaction.
+func mySQLDeadlockQueries(status string) []string {
+	start := strings.Index(status, "LATEST DETECTED DEADLOCK")
+	if start < 0 {
+		return nil
+	}
+	section := status[start:]
+	if end := strings.Index(section, "\nTRANSACTIONS\n"); end >= 0 {
+		section = section[:end]
+	}
+
+	var queries []string
+	var query []string
+	inQuery := false
+	for _, line := range strings.Split(section, "\n") {
+		switch {
+		case strings.HasPrefix(line, "MySQL thread id"):
+			inQuery = true
+			query = nil
+		case inQuery && strings.HasPrefix(line, "***"):
+			inQuery = false
+			if q := strings.TrimSpace(strings.Join(query, "\n")); q != "" {
+				queries = append(queries, q)
+			}
+		case inQuery:
+			query = append(query, line)
+		}
+	}
+
+	return queries
+}
+
+func (c *mySQLDriver) BeginTx(ctx context.Context) (core.Transaction, error) {
+	tx, err := c.c.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// ImportBatch imports rows with LOAD DATA LOCAL INFILE.
+func (c *mySQLDriver) ImportBatch(ctx context.Context, table string, columns []string, rows []core.Row) error {
+	return mySQLLoadData(ctx, c.c, core.NewDialect(c), table, columns, rows)
+}
+
+func (c *mySQLDriver) Explain(ctx context.Context, query string, analyze bool) (*core.PlanNode, error) {
+	if analyze {
+		return nil, errAnalyzeNotSupported
+	}
+
+	doc, err := queryPlanJSON(ctx, c.c, "EXPLAIN FORMAT=JSON "+query)
+	if err != nil {
+		return nil, err
+	}
+	return parseMySQLPlan(doc)
+}
+
+func (c *mySQLDriver) StatementDialect() *core.StatementDialect {
+	return mySQLStatementDialect
+}
+
+func (c *mySQLDriver) JSONPathSyntax() core.JSONPathSyntax {
+	return core.JSONPathSyntaxExtract
+}
+
+func (c *mySQLDriver) BlobDialect() *core.BlobDialect {
+	return &core.BlobDialect{
+		Placeholder: "?",
+		Append: func(column string) string {
+			return "CONCAT(" + column + ", ?)"
+		},
+	}
+}
+
+func (c *mySQLDriver) ExecChunks(ctx context.Context, first, next string, r io.Reader, chunkSize int) error {
+	return c.c.ExecChunks(ctx, first, next, r, chunkSize)
+}
+
+func (c *mySQLDriver) SampleSyntax() core.SampleSyntax {
+	return core.SampleSyntax{
+		Random: "RAND()",
+	}
+}
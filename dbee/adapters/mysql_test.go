@@ -0,0 +1,71 @@
+package adapters
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestMySQLEnumValues(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal([]string{"small", "medium", "large"}, mysqlEnumValues("enum('small','medium','large')"))
+	r.Equal([]string{"it's", "a,b", ""}, mysqlEnumValues("set('it''s','a,b','')"))
+	r.Nil(mysqlEnumValues("varchar(20"))
+}
+
+func TestMySQLDeadlockQueries(t *testing.T) {
+	r := require.New(t)
+
+	status := `
+------------------------
+LATEST DETECTED DEADLOCK
+------------------------
+2024-01-02 10:00:00 0x7f
+*** (1) TRANSACTION:
+TRANSACTION 3085, ACTIVE 12 sec starting index read
+mysql tables in use 1, locked 1
+LOCK WAIT 3 lock struct(s), heap size 1136, 2 row lock(s)
+MySQL thread id 8, OS thread handle 140, query id 70 localhost root updating
+UPDATE accounts
+SET balance = 0 WHERE id = 2
+*** (1) HOLDS THE LOCK(S):
+RECORD LOCKS space id 2 page no 4 n bits 72 index PRIMARY of table test.accounts
+*** (2) TRANSACTION:
+TRANSACTION 3086, ACTIVE 8 sec starting index read
+MySQL thread id 9, OS thread handle 141, query id 71 localhost root updating
+UPDATE accounts SET balance = 0 WHERE id = 1
+*** (2) HOLDS THE LOCK(S):
+*** WE ROLL BACK TRANSACTION (2)
+------------
+TRANSACTIONS
+------------
+MySQL thread id 10, OS thread handle 142, query id 72 localhost root
+SELECT 1
+***
+`
+
+	r.Equal([]string{
+		"UPDATE accounts\nSET balance = 0 WHERE id = 2",
+		"UPDATE accounts SET balance = 0 WHERE id = 1",
+	}, mySQLDeadlockQueries(status))
+	r.Nil(mySQLDeadlockQueries("no deadlocks"))
+}
+
+func TestMySQLGeometryProcessor(t *testing.T) {
+	r := require.New(t)
+
+	// SRID 4326 followed by POINT(1 2)
+	value, err := hex.DecodeString("e61000000101000000000000000000f03f0000000000000040")
+	r.NoError(err)
+
+	geom, ok := mySQLGeometryProcessor(value).(*core.Geometry)
+	r.True(ok)
+	r.Equal("SRID=4326;POINT(1 2)", geom.String())
+
+	r.Equal("abc", mySQLGeometryProcessor([]byte("abc")))
+	r.Nil(mySQLGeometryProcessor(nil))
+}
@@ -26,7 +26,12 @@ func (o *Oracle) Connect(url string) (core.Driver, error) {
 	}
 
 	return &oracleDriver{
-		c: builders.NewClient(db),
+		c: builders.NewClient(db,
+			builders.WithCustomTypeProcessor("NUMBER", oracleNumber),
+			builders.WithCustomTypeProcessor("RAW", oracleBinary),
+			builders.WithCustomTypeProcessor("LongRaw", oracleBinary),
+			builders.WithCustomTypeProcessor("OCIBlobLocator", oracleBinary),
+		),
 	}, nil
 }
 
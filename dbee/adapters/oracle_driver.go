@@ -2,13 +2,32 @@ package adapters
 
 import (
 	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
 	"strings"
 
+	go_ora "github.com/sijms/go-ora/v2"
+
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 )
 
-var _ core.Driver = (*oracleDriver)(nil)
+var (
+	_ core.Driver                   = (*oracleDriver)(nil)
+	_ core.LimitDialect             = (*oracleDriver)(nil)
+	_ core.ProcedureCaller          = (*oracleDriver)(nil)
+	_ core.Transactor               = (*oracleDriver)(nil)
+	_ core.Importer                 = (*oracleDriver)(nil)
+	_ core.StatementDialectProvider = (*oracleDriver)(nil)
+	_ core.DialectOptionsProvider   = (*oracleDriver)(nil)
+	_ core.BlobWriter               = (*oracleDriver)(nil)
+	_ core.PoolStatsProvider        = (*oracleDriver)(nil)
+	_ core.SampleDialect            = (*oracleDriver)(nil)
+)
 
 type oracleDriver struct {
 	c *builders.Client
@@ -19,9 +38,12 @@ func (c *oracleDriver) Query(ctx context.Context, query string) (core.ResultStre
 	query = strings.TrimSuffix(query, ";")
 
 	// Use Exec or Query depending on the query
-	action := strings.ToLower(strings.Split(query, " ")[0])
+	var action string
+	if fields := strings.Fields(query); len(fields) > 0 {
+		action = strings.ToLower(fields[0])
+	}
 	hasReturnValues := strings.Contains(strings.ToLower(query), " returning ")
-	if (action == "update" || action == "delete" || action == "insert") && !hasReturnValues {
+	if (action == "update" || action == "delete" || action == "insert" || action == "merge") && !hasReturnValues {
 		return c.c.Exec(ctx, query)
 	}
 
@@ -47,12 +69,15 @@ func (c *oracleDriver) Columns(opts *core.TableOptions) ([]*core.Column, error)
 
 func (c *oracleDriver) Structure() ([]*core.Structure, error) {
 	query := `
-		SELECT T.owner, T.table_name
+		SELECT T.owner, T.table_name, T.table_type, T.status
 		FROM (
-			SELECT owner, table_name
+			SELECT owner, table_name, 'TABLE' AS "table_type", NULL AS "status"
 			FROM all_tables
-			UNION SELECT owner, view_name AS "table_name"
+			WHERE (owner, table_name) NOT IN (SELECT owner, mview_name FROM all_mviews)
+			UNION SELECT owner, view_name AS "table_name", 'VIEW', NULL
 			FROM all_views
+			UNION SELECT owner, mview_name AS "table_name", 'MATERIALIZED VIEW', LOWER(staleness)
+			FROM all_mviews
 		) T
 		JOIN all_users U ON T.owner = U.username
 		WHERE U.common = 'NO'
@@ -72,14 +97,18 @@ func (c *oracleDriver) Structure() ([]*core.Structure, error) {
 			return nil, err
 		}
 
-		// We know for a fact there are 2 string fields (see query above)
+		// We know for a fact there are 3 string fields (see query above)
 		schema := row[0].(string)
 		table := row[1].(string)
+		status, _ := row[3].(string)
+
+		typ := getPGStructureType(row[2].(string))
 
 		children[schema] = append(children[schema], &core.Structure{
 			Name:   table,
 			Schema: schema,
-			Type:   core.StructureTypeTable,
+			Type:   typ,
+			Status: status,
 		})
 
 	}
@@ -98,6 +127,157 @@ func (c *oracleDriver) Structure() ([]*core.Structure, error) {
 	return structure, nil
 }
 
+func (c *oracleDriver) RefreshMaterializedView(ctx context.Context, opts *core.TableOptions) error {
+	name := opts.Table
+	if opts.Schema != "" {
+		name = opts.Schema + "." + name
+	}
+	return c.c.ExecArgs(ctx, "BEGIN DBMS_MVIEW.REFRESH(:1); END;", name)
+}
+
+func (c *oracleDriver) Routines() ([]*core.Structure, error) {
+	return c.c.RoutinesFromQuery(`
+		SELECT O.owner, O.object_name, O.object_type
+		FROM all_objects O
+		JOIN all_users U ON O.owner = U.username
+		WHERE U.common = 'NO'
+			AND O.object_type IN ('FUNCTION', 'PROCEDURE')
+	`)
+}
+
+func (c *oracleDriver) Definition(ctx context.Context, opts *core.TableOptions) (string, error) {
+	var kind string
+	switch opts.Materialization {
+	case core.StructureTypeFunction:
+		kind = "FUNCTION"
+	case core.StructureTypeProcedure:
+		kind = "PROCEDURE"
+	case core.StructureTypeTable, core.StructureTypeView, core.StructureTypeIndex, core.StructureTypeSequence, core.StructureTypeMaterializedView:
+		// object types of DBMS_METADATA match our names in upper case
+		return c.c.DefinitionFromQuery(ctx, 0,
+			"SELECT DBMS_METADATA.GET_DDL(:1, :2, :3) FROM dual",
+			strings.ToUpper(opts.Materialization.String()), opts.Table, opts.Schema)
+	default:
+		return "", fmt.Errorf("%w: %s", core.ErrDefinitionNotSupported, opts.Materialization)
+	}
+
+	// source is stored line by line, without the CREATE keyword
+	source, err := c.c.DefinitionFromQuery(ctx, 0, `
+		SELECT text
+		FROM all_source
+		WHERE owner = :1 AND name = :2 AND type = :3
+		ORDER BY line`,
+		opts.Schema, opts.Table, kind)
+	if err != nil || source == "" {
+		return "", err
+	}
+
+	return "CREATE OR REPLACE " + source, nil
+}
+
 func (c *oracleDriver) Close() {
 	c.c.Close()
 }
+
+func (c *oracleDriver) PoolStats() *core.PoolStats {
+	return c.c.PoolStats()
+}
+
+// CallProcedure calls the procedure in an anonymous PL/SQL block.
+func (c *oracleDriver) CallProcedure(ctx context.Context, name string, params []*core.ProcedureParam) (core.ResultStream, error) {
+	// maximum size of VARCHAR2 in PL/SQL
+	const outputSize = 32767
+
+	if err := checkBindNames(params); err != nil {
+		return nil, err
+	}
+
+	placeholders := make([]string, len(params))
+	args := make([]any, len(params))
+	var outputs []*builders.ProcedureOutput
+	for i, p := range params {
+		placeholders[i] = ":" + p.Name
+		if !p.IsOutput() {
+			args[i] = sql.Named(p.Name, p.Value)
+			continue
+		}
+
+		out := builders.NewProcedureOutput(p)
+		outputs = append(outputs, out)
+		args[i] = sql.Named(p.Name, go_ora.Out{Dest: out.Dest, Size: outputSize, In: p.Mode == core.ParamModeInOut})
+	}
+
+	query := fmt.Sprintf("BEGIN %s(%s); END;", procedureName(core.NewDialect(c), name), strings.Join(placeholders, ", "))
+
+	return c.c.QueryWithOutputs(ctx, query, args, outputs)
+}
+
+func (c *oracleDriver) LimitSyntax() core.LimitSyntax {
+	return core.LimitSyntaxFetchFirst
+}
+
+func (c *oracleDriver) SampleSyntax() core.SampleSyntax {
+	return core.SampleSyntax{
+		TableSample: "SAMPLE (%s)",
+		BeforeAlias: true,
+		Random:      "DBMS_RANDOM.VALUE",
+	}
+}
+
+func (c *oracleDriver) BeginTx(ctx context.Context) (core.Transaction, error) {
+	tx, err := c.c.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// ImportBatch imports rows with a prepared INSERT statement.
+func (c *oracleDriver) ImportBatch(ctx context.Context, table string, columns []string, rows []core.Row) error {
+	schema, name := splitTableName(table)
+	return c.c.InsertBatch(ctx, core.NewDialect(c), schema, name, columns, rows, builders.PlaceholderColon)
+}
+
+func (c *oracleDriver) StatementDialect() *core.StatementDialect {
+	return oracleStatementDialect
+}
+
+func (c *oracleDriver) DialectOptions() *core.DialectOptions {
+	return oracleDialectOptions
+}
+
+func (c *oracleDriver) BlobDialect() *core.BlobDialect {
+	// blobs can't be appended in an UPDATE statement
+	return &core.BlobDialect{Placeholder: ":1"}
+}
+
+// oracleNumber keeps NUMBER values with a scale (which the driver returns as
+// strings to keep their precision) numeric, so they are exported as numbers.
+func oracleNumber(val any) any {
+	s, ok := val.(string)
+	if !ok {
+		return val
+	}
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return s
+	}
+	return json.Number(s)
+}
+
+// oracleBinary renders RAW and BLOB values in hex, like RAWTOHEX does.
+func oracleBinary(val any) any {
+	switch v := val.(type) {
+	case []byte:
+		return strings.ToUpper(hex.EncodeToString(v))
+	case go_ora.Blob:
+		if !v.Valid && v.Data == nil {
+			return nil
+		}
+		return strings.ToUpper(hex.EncodeToString(v.Data))
+	}
+	return val
+}
+
+func (c *oracleDriver) ExecChunks(ctx context.Context, first, next string, r io.Reader, chunkSize int) error {
+	return c.c.ExecChunks(ctx, first, next, r, chunkSize)
+}
@@ -0,0 +1,20 @@
+package adapters
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOracleValues(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal(json.Number("12.50"), oracleNumber("12.50"))
+	r.Equal(int64(42), oracleNumber(int64(42)))
+	r.Equal("n/a", oracleNumber("n/a"))
+	r.Nil(oracleNumber(nil))
+
+	r.Equal("00FFA0", oracleBinary([]byte{0x00, 0xff, 0xa0}))
+	r.Nil(oracleBinary(nil))
+}
@@ -0,0 +1,189 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var errAnalyzeNotSupported = errors.New("analyzed plans not supported")
+
+// queryPlanJSON runs an explain query and returns the plan document from its result.
+// Some databases split the document into multiple rows, so all rows are joined.
+// Values wrapped by type processors of the client (e.g. json of postgres) are
+// accepted as well.
+func queryPlanJSON(ctx context.Context, c *builders.Client, query string) ([]byte, error) {
+	rows, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var doc strings.Builder
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, err
+		}
+		if len(row) < 1 {
+			continue
+		}
+
+		switch v := row[0].(type) {
+		case string:
+			doc.WriteString(v)
+		case []byte:
+			doc.Write(v)
+		case json.Marshaler:
+			b, err := v.MarshalJSON()
+			if err != nil {
+				return nil, fmt.Errorf("could not read plan value: %w", err)
+			}
+			doc.Write(b)
+		case fmt.Stringer:
+			doc.WriteString(v.String())
+		default:
+			return nil, fmt.Errorf("unexpected plan value of type %T", v)
+		}
+		doc.WriteString("\n")
+	}
+
+	return []byte(doc.String()), nil
+}
+
+// toFloat converts json numbers and numeric strings to float.
+func toFloat(v any) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case string:
+		f, _ := strconv.ParseFloat(n, 64)
+		return f
+	default:
+		return 0
+	}
+}
+
+// parsePGPlan parses plans in postgres' json format. ClickHouse uses
+// the same structure (without costs).
+func parsePGPlan(doc []byte) (*core.PlanNode, error) {
+	var plans []struct {
+		Plan map[string]any `json:"Plan"`
+	}
+	err := json.Unmarshal(doc, &plans)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	if len(plans) < 1 || plans[0].Plan == nil {
+		return nil, errors.New("no plan in document")
+	}
+
+	return pgPlanNode(plans[0].Plan), nil
+}
+
+func pgPlanNode(plan map[string]any) *core.PlanNode {
+	node := &core.PlanNode{
+		Cost: toFloat(plan["Total Cost"]),
+		Rows: toFloat(plan["Plan Rows"]),
+	}
+	node.Type, _ = plan["Node Type"].(string)
+	node.Relation, _ = plan["Relation Name"].(string)
+	if node.Relation == "" {
+		node.Relation, _ = plan["Description"].(string)
+	}
+
+	// actual values are reported per loop
+	loops := toFloat(plan["Actual Loops"])
+	if loops < 1 {
+		loops = 1
+	}
+	node.ActualRows = toFloat(plan["Actual Rows"]) * loops
+	node.ActualTime = toFloat(plan["Actual Total Time"]) * loops
+
+	children, _ := plan["Plans"].([]any)
+	for _, child := range children {
+		if c, ok := child.(map[string]any); ok {
+			node.Children = append(node.Children, pgPlanNode(c))
+		}
+	}
+
+	return node
+}
+
+// parseMySQLPlan parses plans in mysql's json format (EXPLAIN FORMAT=JSON).
+// Operations (query blocks, nested loops, sorts, ...) become nodes named after
+// their key and tables become nodes with their access type.
+func parseMySQLPlan(doc []byte) (*core.PlanNode, error) {
+	var root map[string]any
+	err := json.Unmarshal(doc, &root)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	block, ok := root["query_block"].(map[string]any)
+	if !ok {
+		return nil, errors.New("no query block in document")
+	}
+
+	return mySQLPlanNode("query_block", block), nil
+}
+
+func mySQLPlanNode(key string, obj map[string]any) *core.PlanNode {
+	node := &core.PlanNode{
+		Type: key,
+	}
+
+	cost, _ := obj["cost_info"].(map[string]any)
+	if key == "table" {
+		node.Type, _ = obj["access_type"].(string)
+		node.Relation, _ = obj["table_name"].(string)
+		node.Rows = toFloat(obj["rows_produced_per_join"])
+		node.Cost = toFloat(cost["prefix_cost"])
+	} else {
+		node.Cost = toFloat(cost["query_cost"])
+	}
+
+	// json objects are unordered - sort keys to keep the tree stable
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if k == "cost_info" {
+			continue
+		}
+
+		switch val := obj[k].(type) {
+		case map[string]any:
+			node.Children = append(node.Children, mySQLPlanNode(k, val))
+		case []any:
+			// e.g. nested_loop: [{"table": ...}, {"table": ...}]
+			list := &core.PlanNode{Type: k}
+			for _, elem := range val {
+				m, ok := elem.(map[string]any)
+				if !ok {
+					continue
+				}
+				for ek, ev := range m {
+					if em, ok := ev.(map[string]any); ok {
+						list.Children = append(list.Children, mySQLPlanNode(ek, em))
+					}
+				}
+			}
+			if len(list.Children) > 0 {
+				node.Children = append(node.Children, list)
+			}
+		}
+	}
+
+	return node
+}
@@ -0,0 +1,178 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// planSQLDriver is a database/sql driver which answers every query with a
+// single json column holding the plan, like EXPLAIN (FORMAT JSON) of postgres.
+type planSQLDriver struct {
+	plan string
+}
+
+type (
+	planConn struct{ plan string }
+	planStmt struct{ plan string }
+	planRows struct {
+		plan string
+		done bool
+	}
+)
+
+func init() {
+	sql.Register("dbee_plan_test", &planSQLDriver{
+		plan: `[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "users", "Total Cost": 1.5, "Plan Rows": 3}}]`,
+	})
+}
+
+func (d *planSQLDriver) Open(string) (driver.Conn, error) { return &planConn{plan: d.plan}, nil }
+
+func (c *planConn) Prepare(string) (driver.Stmt, error) { return &planStmt{plan: c.plan}, nil }
+func (c *planConn) Close() error                        { return nil }
+func (c *planConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+func (s *planStmt) Close() error                               { return nil }
+func (s *planStmt) NumInput() int                              { return -1 }
+func (s *planStmt) Exec([]driver.Value) (driver.Result, error) { return driver.ResultNoRows, nil }
+func (s *planStmt) Query([]driver.Value) (driver.Rows, error)  { return &planRows{plan: s.plan}, nil }
+
+func (r *planRows) Columns() []string                     { return []string{"QUERY PLAN"} }
+func (r *planRows) Close() error                          { return nil }
+func (r *planRows) ColumnTypeDatabaseTypeName(int) string { return "JSON" }
+func (r *planRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = []byte(r.plan)
+	return nil
+}
+
+func TestPostgres_Explain(t *testing.T) {
+	r := require.New(t)
+
+	db, err := sql.Open("dbee_plan_test", "")
+	r.NoError(err)
+	defer db.Close()
+
+	// the json column is wrapped by the processor of the client
+	driver := &postgresDriver{c: newPostgresClient(db)}
+
+	plan, err := driver.Explain(context.Background(), "SELECT * FROM users", false)
+	r.NoError(err)
+	r.Equal(&core.PlanNode{
+		Type:     "Seq Scan",
+		Relation: "users",
+		Cost:     1.5,
+		Rows:     3,
+	}, plan)
+}
+
+func TestParsePGPlan(t *testing.T) {
+	r := require.New(t)
+
+	doc := `[
+	  {
+	    "Plan": {
+	      "Node Type": "Hash Join",
+	      "Total Cost": 35.5,
+	      "Plan Rows": 10,
+	      "Actual Rows": 8,
+	      "Actual Total Time": 0.5,
+	      "Actual Loops": 1,
+	      "Plans": [
+	        {
+	          "Node Type": "Seq Scan",
+	          "Relation Name": "users",
+	          "Total Cost": 12.1,
+	          "Plan Rows": 100,
+	          "Actual Rows": 50,
+	          "Actual Total Time": 0.1,
+	          "Actual Loops": 2
+	        }
+	      ]
+	    },
+	    "Planning Time": 0.1,
+	    "Execution Time": 0.6
+	  }
+	]`
+
+	plan, err := parsePGPlan([]byte(doc))
+	r.NoError(err)
+	r.Equal(&core.PlanNode{
+		Type:       "Hash Join",
+		Cost:       35.5,
+		Rows:       10,
+		ActualRows: 8,
+		ActualTime: 0.5,
+		Children: []*core.PlanNode{
+			{
+				Type:       "Seq Scan",
+				Relation:   "users",
+				Cost:       12.1,
+				Rows:       100,
+				ActualRows: 100,
+				ActualTime: 0.2,
+			},
+		},
+	}, plan)
+
+	_, err = parsePGPlan([]byte(`[]`))
+	r.Error(err)
+}
+
+func TestParseMySQLPlan(t *testing.T) {
+	r := require.New(t)
+
+	doc := `{
+	  "query_block": {
+	    "select_id": 1,
+	    "cost_info": { "query_cost": "4.50" },
+	    "nested_loop": [
+	      {
+	        "table": {
+	          "table_name": "a",
+	          "access_type": "ALL",
+	          "rows_produced_per_join": 3,
+	          "cost_info": { "prefix_cost": "1.30" }
+	        }
+	      },
+	      {
+	        "table": {
+	          "table_name": "b",
+	          "access_type": "eq_ref",
+	          "rows_produced_per_join": 3,
+	          "cost_info": { "prefix_cost": "4.50" }
+	        }
+	      }
+	    ]
+	  }
+	}`
+
+	plan, err := parseMySQLPlan([]byte(doc))
+	r.NoError(err)
+	r.Equal(&core.PlanNode{
+		Type: "query_block",
+		Cost: 4.5,
+		Children: []*core.PlanNode{
+			{
+				Type: "nested_loop",
+				Children: []*core.PlanNode{
+					{Type: "ALL", Relation: "a", Rows: 3, Cost: 1.3},
+					{Type: "eq_ref", Relation: "b", Rows: 3, Cost: 4.5},
+				},
+			},
+		},
+	}, plan)
+
+	_, err = parseMySQLPlan([]byte(`{}`))
+	r.Error(err)
+}
@@ -2,11 +2,13 @@ package adapters
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	nurl "net/url"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
@@ -35,6 +37,15 @@ func (p *Postgres) Connect(url string) (core.Driver, error) {
 		return nil, fmt.Errorf("unable to connect to postgres database: %w", err)
 	}
 
+	return &postgresDriver{
+		c:   newPostgresClient(db),
+		url: u,
+	}, nil
+}
+
+// newPostgresClient returns a client which wraps json values and reports
+// notices of the database.
+func newPostgresClient(db *sql.DB) *builders.Client {
 	jsonProcessor := func(a any) any {
 		b, ok := a.([]byte)
 		if !ok {
@@ -44,13 +55,13 @@ func (p *Postgres) Connect(url string) (core.Driver, error) {
 		return newPostgresJSONResponse(b)
 	}
 
-	return &postgresDriver{
-		c: builders.NewClient(db,
-			builders.WithCustomTypeProcessor("json", jsonProcessor),
-			builders.WithCustomTypeProcessor("jsonb", jsonProcessor),
-		),
-		url: u,
-	}, nil
+	return builders.NewClient(db,
+		builders.WithCustomTypeProcessor("json", jsonProcessor),
+		builders.WithCustomTypeProcessor("jsonb", jsonProcessor),
+		// types of extensions (e.g. PostGIS geometry) have no names
+		builders.WithCustomTypeProcessor("", postgisProcessor),
+		builders.WithNoticeHook(postgresNoticeHook),
+	)
 }
 
 func (*Postgres) GetHelpers(opts *core.TableOptions) map[string]string {
@@ -87,3 +98,26 @@ func (*Postgres) GetHelpers(opts *core.TableOptions) map[string]string {
 		),
 	}
 }
+
+// postgresNoticeHook collects messages sent with RAISE NOTICE/WARNING.
+func postgresNoticeHook(conn *sql.Conn, onNotice func(string)) (func(), error) {
+	setHandler := func(handler func(*pq.Error)) error {
+		return conn.Raw(func(dc any) error {
+			c, ok := dc.(driver.Conn)
+			if !ok {
+				return errors.New("not a driver connection")
+			}
+			pq.SetNoticeHandler(c, handler)
+			return nil
+		})
+	}
+
+	err := setHandler(func(e *pq.Error) {
+		onNotice(fmt.Sprintf("%s: %s", e.Severity, e.Message))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return func() { _ = setHandler(nil) }, nil
+}
@@ -5,21 +5,77 @@ import (
 	"context"
 	"database/sql"
 	"encoding/gob"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	nurl "net/url"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/lib/pq"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 )
 
 var (
-	_ core.Driver           = (*postgresDriver)(nil)
-	_ core.DatabaseSwitcher = (*postgresDriver)(nil)
+	_ core.Driver                    = (*postgresDriver)(nil)
+	_ core.DatabaseSwitcher          = (*postgresDriver)(nil)
+	_ core.ProcedureCaller           = (*postgresDriver)(nil)
+	_ core.ActivityMonitor           = (*postgresDriver)(nil)
+	_ core.TransientErrorClassifier  = (*postgresDriver)(nil)
+	_ core.ConnectionErrorClassifier = (*postgresDriver)(nil)
+	_ core.Transactor                = (*postgresDriver)(nil)
+	_ core.Planner                   = (*postgresDriver)(nil)
+	_ core.Importer                  = (*postgresDriver)(nil)
+	_ core.KeyLister                 = (*postgresDriver)(nil)
+	_ core.StatementDialectProvider  = (*postgresDriver)(nil)
+	_ core.JSONPathDialect           = (*postgresDriver)(nil)
+	_ core.DialectOptionsProvider    = (*postgresDriver)(nil)
+	_ core.BlobWriter                = (*postgresDriver)(nil)
+	_ core.TableInspector            = (*postgresDriver)(nil)
+	_ core.TriggerLister             = (*postgresDriver)(nil)
+	_ core.TableStatsProvider        = (*postgresDriver)(nil)
+	_ core.StructureLoader           = (*postgresDriver)(nil)
+	_ core.ObjectSearcher            = (*postgresDriver)(nil)
+	_ core.TypeLister                = (*postgresDriver)(nil)
+	_ core.GrantLister               = (*postgresDriver)(nil)
+	_ core.ServerInfoProvider        = (*postgresDriver)(nil)
+	_ core.PartitionLister           = (*postgresDriver)(nil)
+	_ core.PartitionManager          = (*postgresDriver)(nil)
+	_ core.SystemObjectClassifier    = (*postgresDriver)(nil)
+	_ core.Commenter                 = (*postgresDriver)(nil)
+	_ core.DependencyLister          = (*postgresDriver)(nil)
+	_ core.ForeignServerLister       = (*postgresDriver)(nil)
+	_ core.KeywordProvider           = (*postgresDriver)(nil)
+	_ core.SignatureProvider         = (*postgresDriver)(nil)
+	_ core.ErrorClassifier           = (*postgresDriver)(nil)
+	_ core.ErrorExplainer            = (*postgresDriver)(nil)
+	_ core.PoolStatsProvider         = (*postgresDriver)(nil)
+	_ core.SampleDialect             = (*postgresDriver)(nil)
+	_ core.Subscriber                = (*postgresDriver)(nil)
 )
 
+// postgresMissingObjectPatterns match names of undefined objects in messages
+// of errors by their SQLSTATE.
+var postgresMissingObjectPatterns = map[pq.ErrorCode]struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	"42703": {core.ErrorObjectColumn, regexp.MustCompile(`^column "?([^"]+?)"? (?:of relation "[^"]+" )?does not exist`)},
+	"42P01": {core.ErrorObjectTable, regexp.MustCompile(`^relation "([^"]+)" does not exist`)},
+	"42883": {core.ErrorObjectFunction, regexp.MustCompile(`^(?:function|procedure) ([^(]+)\(`)},
+	"3F000": {core.ErrorObjectSchema, regexp.MustCompile(`^schema "([^"]+)" does not exist`)},
+}
+
+// postgresDeadlockProcessPattern matches processes blocking each other in
+// the detail of deadlock errors.
+var postgresDeadlockProcessPattern = regexp.MustCompile(`blocked by process (\d+)`)
+
 type postgresDriver struct {
 	c   *builders.Client
 	url *nurl.URL
@@ -37,22 +93,88 @@ func (c *postgresDriver) Query(ctx context.Context, query string) (core.ResultSt
 }
 
 func (c *postgresDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	// materialized views are not part of information_schema
+	if opts.Materialization == core.StructureTypeMaterializedView {
+		return c.c.ColumnsFromQuery(`
+			SELECT a.attname, format_type(a.atttypid, a.atttypmod), NOT a.attnotnull, NULL, false,
+				col_description(a.attrelid, a.attnum)
+			FROM pg_attribute a
+			JOIN pg_class c ON a.attrelid = c.oid
+			JOIN pg_namespace n ON c.relnamespace = n.oid
+			WHERE
+				n.nspname='%s' AND
+				c.relname='%s' AND
+				a.attnum > 0 AND
+				NOT a.attisdropped
+			ORDER BY a.attnum
+			`, opts.Schema, opts.Table)
+	}
+
 	return c.c.ColumnsFromQuery(`
-		SELECT column_name, data_type
-		FROM information_schema.columns
+		SELECT
+			col.column_name,
+			col.data_type,
+			col.is_nullable,
+			col.column_default,
+			EXISTS (
+				SELECT 1
+				FROM information_schema.table_constraints tc
+				JOIN information_schema.key_column_usage kcu
+					ON tc.constraint_name = kcu.constraint_name AND
+					tc.table_schema = kcu.table_schema
+				WHERE
+					tc.constraint_type = 'PRIMARY KEY' AND
+					tc.table_schema = col.table_schema AND
+					tc.table_name = col.table_name AND
+					kcu.column_name = col.column_name
+			),
+			col_description(
+				(quote_ident(col.table_schema) || '.' || quote_ident(col.table_name))::regclass,
+				col.ordinal_position
+			)
+		FROM information_schema.columns col
 		WHERE
-			table_schema='%s' AND
-			table_name='%s'
+			col.table_schema='%s' AND
+			col.table_name='%s'
+		ORDER BY col.ordinal_position
 		`, opts.Schema, opts.Table)
 }
 
-func (c *postgresDriver) Structure() ([]*core.Structure, error) {
-	query := `
-		SELECT table_schema, table_name, table_type FROM information_schema.tables UNION ALL
-		SELECT schemaname, matviewname, 'VIEW' FROM pg_matviews;
+func (c *postgresDriver) PrimaryKey(opts *core.TableOptions) ([]string, error) {
+	columns, err := c.c.ColumnsFromQuery(`
+		SELECT kcu.column_name, 'key'
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.key_column_usage kcu
+			ON tc.constraint_name = kcu.constraint_name AND
+			tc.table_schema = kcu.table_schema
+		WHERE
+			tc.constraint_type = 'PRIMARY KEY' AND
+			tc.table_schema='%s' AND
+			tc.table_name='%s'
+		ORDER BY kcu.ordinal_position
+		`, opts.Schema, opts.Table)
+	if err != nil {
+		return nil, err
+	}
+	return columnNames(columns), nil
+}
+
+// postgresStructureQuery selects schema, name, type, status and comment of all relations.
+const postgresStructureQuery = `
+	SELECT s.*, obj_description((quote_ident(s.schema) || '.' || quote_ident(s.name))::regclass, 'pg_class')
+	FROM (
+		SELECT table_schema AS schema, table_name AS name, table_type AS type, '' AS status
+		FROM information_schema.tables UNION ALL
+		SELECT schemaname, sequencename, 'SEQUENCE', COALESCE('current value ' || last_value, '')
+		FROM pg_sequences UNION ALL
+		SELECT schemaname, matviewname, 'MATERIALIZED VIEW',
+			CASE WHEN ispopulated THEN 'populated' ELSE 'not populated' END
+		FROM pg_matviews
+	) s
 	`
 
-	rows, err := c.Query(context.TODO(), query)
+func (c *postgresDriver) Structure() ([]*core.Structure, error) {
+	rows, err := c.Query(context.TODO(), postgresStructureQuery)
 	if err != nil {
 		return nil, err
 	}
@@ -60,10 +182,93 @@ func (c *postgresDriver) Structure() ([]*core.Structure, error) {
 	return getPGStructure(rows)
 }
 
+func (c *postgresDriver) StructureRoots(ctx context.Context) ([]*core.Structure, error) {
+	return c.c.SchemasFromQuery(ctx, `SELECT schema_name FROM information_schema.schemata`)
+}
+
+func (c *postgresDriver) StructureChildren(ctx context.Context, parent *core.Structure) ([]*core.Structure, error) {
+	rows, err := c.c.QueryArgs(ctx, postgresStructureQuery+"WHERE s.schema = $1", parent.Schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	structure, err := getPGStructure(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(structure) < 1 {
+		return nil, nil
+	}
+
+	return structure[0].Children, nil
+}
+
+// IsSystem reports catalog, toast and temporary schemas.
+func (c *postgresDriver) IsSystem(node *core.Structure) bool {
+	if node.Type != core.StructureTypeNone {
+		return false
+	}
+	switch {
+	case node.Schema == "pg_catalog", node.Schema == "information_schema":
+		return true
+	case strings.HasPrefix(node.Schema, "pg_toast"), strings.HasPrefix(node.Schema, "pg_temp_"):
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *postgresDriver) SearchObjects(ctx context.Context, pattern string, limit int) ([]*core.ObjectMatch, error) {
+	return c.c.ObjectMatchesFromQuery(ctx, `
+		SELECT * FROM (
+			SELECT table_schema, '', table_name, CASE table_type WHEN 'VIEW' THEN 'view' ELSE 'table' END
+			FROM information_schema.tables
+			WHERE table_name ILIKE $1
+			UNION ALL
+			SELECT schemaname, '', matviewname, 'materialized_view'
+			FROM pg_matviews
+			WHERE matviewname ILIKE $1
+			UNION ALL
+			SELECT routine_schema, '', routine_name, LOWER(routine_type)
+			FROM information_schema.routines
+			WHERE routine_name ILIKE $1
+			UNION ALL
+			SELECT table_schema, table_name, column_name, 'column'
+			FROM information_schema.columns
+			WHERE column_name ILIKE $1
+		) m
+		LIMIT $2
+		`, "%"+pattern+"%", limit)
+}
+
+// CallProcedure calls the procedure with CALL, passing NULL for output parameters.
+// Postgres returns the output parameters as a single row.
+func (c *postgresDriver) CallProcedure(ctx context.Context, name string, params []*core.ProcedureParam) (core.ResultStream, error) {
+	placeholders := make([]string, len(params))
+	var args []any
+	for i, p := range params {
+		if p.Mode == core.ParamModeOut {
+			placeholders[i] = "NULL"
+			continue
+		}
+		args = append(args, p.Value)
+		placeholders[i] = fmt.Sprintf("$%d", len(args))
+	}
+
+	query := fmt.Sprintf("CALL %s(%s)", procedureName(core.NewDialect(c), name), strings.Join(placeholders, ", "))
+
+	return c.c.QueryArgs(ctx, query, args...)
+}
+
 func (c *postgresDriver) Close() {
 	c.c.Close()
 }
 
+func (c *postgresDriver) PoolStats() *core.PoolStats {
+	return c.c.PoolStats()
+}
+
 func (c *postgresDriver) ListDatabases() (current string, available []string, err error) {
 	query := `
 		SELECT current_database(), datname FROM pg_database
@@ -103,7 +308,7 @@ func (c *postgresDriver) SelectDatabase(name string) error {
 }
 
 // getPGStructure fetches the layout from the postgres database.
-// rows is at least 3 column wide result
+// rows is at least 3 column wide result, optional 4th column is the status of the node.
 func getPGStructure(rows core.ResultStream) ([]*core.Structure, error) {
 	children := make(map[string][]*core.Structure)
 
@@ -118,10 +323,20 @@ func getPGStructure(rows core.ResultStream) ([]*core.Structure, error) {
 
 		schema, table, tableType := row[0].(string), row[1].(string), row[2].(string)
 
+		var status, comment string
+		if len(row) > 3 {
+			status, _ = row[3].(string)
+		}
+		if len(row) > 4 {
+			comment, _ = row[4].(string)
+		}
+
 		children[schema] = append(children[schema], &core.Structure{
-			Name:   table,
-			Schema: schema,
-			Type:   getPGStructureType(tableType),
+			Name:    table,
+			Schema:  schema,
+			Type:    getPGStructureType(tableType),
+			Status:  status,
+			Comment: comment,
 		})
 	}
 
@@ -146,6 +361,10 @@ func getPGStructureType(typ string) core.StructureType {
 		return core.StructureTypeTable
 	case "VIEW", "SYSTEM VIEW":
 		return core.StructureTypeView
+	case "MATERIALIZED VIEW":
+		return core.StructureTypeMaterializedView
+	case "SEQUENCE":
+		return core.StructureTypeSequence
 	default:
 		return core.StructureTypeNone
 	}
@@ -201,3 +420,710 @@ func (pj *postgresJSONResponse) GobDecode(buf []byte) error {
 	}
 	return err
 }
+
+// postgisProcessor converts PostGIS geometries, which are returned as hex
+// encoded extended well-known binary, to [core.Geometry]. Other values of
+// unnamed types (e.g. enums) are returned as strings.
+func postgisProcessor(a any) any {
+	b, ok := a.([]byte)
+	if !ok {
+		return a
+	}
+
+	wkb := make([]byte, hex.DecodedLen(len(b)))
+	if _, err := hex.Decode(wkb, b); err != nil {
+		return string(b)
+	}
+	geom, err := core.ParseWKB(wkb, 0)
+	if err != nil {
+		return string(b)
+	}
+	return geom
+}
+
+func (c *postgresDriver) Activity(ctx context.Context) (core.ResultStream, error) {
+	return c.c.Query(ctx, `
+		SELECT
+			pid,
+			usename,
+			datname,
+			application_name,
+			client_addr::text AS client_addr,
+			state,
+			wait_event_type,
+			(now() - query_start)::text AS duration,
+			query
+		FROM pg_stat_activity
+		WHERE pid <> pg_backend_pid()
+			AND backend_type = 'client backend'
+		ORDER BY query_start NULLS LAST
+	`)
+}
+
+func (c *postgresDriver) KillSession(ctx context.Context, id string) error {
+	pid, err := numericSessionID(id)
+	if err != nil {
+		return err
+	}
+	return c.c.ExecArgs(ctx, "SELECT pg_terminate_backend($1)", pid)
+}
+
+// Subscribe listens to notification channels (separated by whitespace) on a
+// dedicated connection, which reconnects if it's lost. Notifications sent
+// while it's reconnecting are lost.
+func (c *postgresDriver) Subscribe(ctx context.Context, channel string) (core.ResultStream, error) {
+	channels := strings.Fields(channel)
+	if len(channels) < 1 {
+		return nil, errors.New("no channel provided")
+	}
+
+	listener := pq.NewListener(c.url.String(), time.Second, time.Minute, nil)
+	for _, ch := range channels {
+		if err := listener.Listen(ch); err != nil {
+			_ = listener.Close()
+			return nil, fmt.Errorf("listener.Listen: %w", err)
+		}
+	}
+
+	next, hasNext := builders.NextEvents(ctx, func(ctx context.Context, yield func(...any)) error {
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case n, ok := <-listener.Notify:
+				if !ok {
+					return errors.New("listener closed")
+				}
+				// sent after the connection is reestablished
+				if n == nil {
+					continue
+				}
+				yield(time.Now(), n.Channel, n.Extra, int64(n.BePid))
+			}
+		}
+	})
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(core.Header{"received_at", "channel", "payload", "pid"}).
+		WithCloseFunc(func() { _ = listener.Close() }).
+		Build(), nil
+}
+
+func (c *postgresDriver) Indexes(ctx context.Context, opts *core.TableOptions) ([]*core.Index, error) {
+	return c.c.IndexesFromQuery(ctx, `
+		SELECT
+			i.relname,
+			COALESCE(a.attname, pg_get_indexdef(ix.indexrelid, k.ord::int, true)),
+			ix.indisunique,
+			ix.indisprimary
+		FROM pg_index ix
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		CROSS JOIN LATERAL unnest(ix.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord)
+		LEFT JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+		WHERE n.nspname = $1 AND t.relname = $2
+		ORDER BY i.relname, k.ord
+		`, opts.Schema, opts.Table)
+}
+
+func (c *postgresDriver) Constraints(ctx context.Context, opts *core.TableOptions) ([]*core.Constraint, error) {
+	return c.c.ConstraintsFromQuery(ctx, `
+		SELECT
+			con.conname,
+			con.contype,
+			a.attname,
+			CASE WHEN con.contype = 'c' THEN pg_get_constraintdef(con.oid, true) END,
+			fn.nspname,
+			ft.relname,
+			fa.attname
+		FROM pg_constraint con
+		JOIN pg_class t ON t.oid = con.conrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		LEFT JOIN LATERAL unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord) ON true
+		LEFT JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+		LEFT JOIN pg_class ft ON ft.oid = con.confrelid
+		LEFT JOIN pg_namespace fn ON fn.oid = ft.relnamespace
+		LEFT JOIN pg_attribute fa ON fa.attrelid = con.confrelid AND fa.attnum = con.confkey[k.ord]
+		WHERE n.nspname = $1 AND t.relname = $2 AND con.contype IN ('p', 'u', 'c', 'f')
+		ORDER BY con.conname, k.ord
+		`, opts.Schema, opts.Table)
+}
+
+func (c *postgresDriver) Triggers(ctx context.Context, opts *core.TableOptions) ([]*core.Trigger, error) {
+	return c.c.TriggersFromQuery(ctx, `
+		SELECT
+			trigger_name,
+			event_manipulation,
+			action_timing,
+			regexp_replace(action_statement, '^EXECUTE (FUNCTION|PROCEDURE) ', '')
+		FROM information_schema.triggers
+		WHERE event_object_schema = $1 AND event_object_table = $2
+		ORDER BY trigger_name, event_manipulation
+		`, opts.Schema, opts.Table)
+}
+
+func (c *postgresDriver) Roles(ctx context.Context) ([]*core.Role, error) {
+	return c.c.RolesFromQuery(ctx, `
+		SELECT r.rolname, r.rolsuper, r.rolcanlogin, m.rolname
+		FROM pg_roles r
+		LEFT JOIN pg_auth_members am ON am.member = r.oid
+		LEFT JOIN pg_roles m ON m.oid = am.roleid
+		WHERE r.rolname NOT LIKE 'pg\_%'
+		ORDER BY r.rolname, m.rolname
+		`)
+}
+
+func (c *postgresDriver) Grants(ctx context.Context, opts *core.TableOptions) ([]*core.Grant, error) {
+	// tables without explicit privileges have the default ones (all for the owner)
+	return c.c.GrantsFromQuery(ctx, `
+		SELECT COALESCE(g.rolname, 'PUBLIC'), a.privilege_type, a.is_grantable, o.rolname
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		CROSS JOIN LATERAL aclexplode(COALESCE(c.relacl, acldefault('r', c.relowner))) a
+		LEFT JOIN pg_roles g ON g.oid = a.grantee
+		JOIN pg_roles o ON o.oid = a.grantor
+		WHERE n.nspname = $1 AND c.relname = $2
+		ORDER BY 1, 2
+		`, opts.Schema, opts.Table)
+}
+
+func (c *postgresDriver) TableStats(ctx context.Context) ([]*core.TableStats, error) {
+	// reltuples is -1 for tables which were never vacuumed or analyzed
+	return c.c.TableStatsFromQuery(ctx, `
+		SELECT
+			n.nspname,
+			c.relname,
+			CASE WHEN c.reltuples < 0 THEN NULL ELSE c.reltuples::bigint END,
+			pg_total_relation_size(c.oid)
+		FROM pg_class c
+		JOIN pg_namespace n ON c.relnamespace = n.oid
+		WHERE
+			c.relkind IN ('r', 'p', 'm') AND
+			n.nspname NOT IN ('pg_catalog', 'information_schema') AND
+			n.nspname NOT LIKE 'pg_toast%'
+		`)
+}
+
+func (c *postgresDriver) ServerInfo(ctx context.Context) (*core.ServerInfo, error) {
+	return c.c.ServerInfoFromQuery(ctx, `
+		SELECT 'version', NULL, version()
+		UNION ALL
+		SELECT 'uptime', NULL, extract(epoch FROM now() - pg_postmaster_start_time())::bigint::text
+		UNION ALL
+		SELECT 'extension', extname::text, extversion FROM pg_extension
+		UNION ALL
+		SELECT 'setting', name, setting FROM pg_settings
+		WHERE name IN (
+			'server_encoding', 'TimeZone', 'DateStyle', 'max_connections',
+			'shared_buffers', 'default_transaction_isolation', 'search_path'
+		)
+		`)
+}
+
+func (c *postgresDriver) Routines() ([]*core.Structure, error) {
+	return c.c.RoutinesFromQuery(`
+		SELECT DISTINCT routine_schema, routine_name, routine_type
+		FROM information_schema.routines
+		WHERE
+			routine_schema NOT IN ('pg_catalog', 'information_schema') AND
+			routine_type IS NOT NULL
+		`)
+}
+
+// Signatures lists overloads of a function or a procedure with parameters
+// from pg_proc. Table functions return their columns as out parameters.
+func (c *postgresDriver) Signatures(ctx context.Context, opts *core.TableOptions) ([]*core.Signature, error) {
+	return c.c.SignaturesFromQuery(ctx, `
+		SELECT
+			n.nspname,
+			p.proname,
+			p.oid::text,
+			COALESCE(a.ord, 0),
+			COALESCE(p.proargnames[a.ord], ''),
+			COALESCE(format_type(a.typ, NULL), ''),
+			CASE p.proargmodes[a.ord] WHEN 'o' THEN 'out' WHEN 't' THEN 'out' WHEN 'b' THEN 'inout' ELSE 'in' END,
+			COALESCE(pg_get_function_result(p.oid), ''),
+			COALESCE(obj_description(p.oid, 'pg_proc'), '')
+		FROM pg_proc p
+		JOIN pg_namespace n ON n.oid = p.pronamespace
+		LEFT JOIN LATERAL unnest(COALESCE(p.proallargtypes, p.proargtypes::oid[])) WITH ORDINALITY AS a(typ, ord) ON true
+		WHERE p.proname = $1 AND ($2 = '' OR n.nspname = $2)
+		ORDER BY n.nspname, p.oid, a.ord
+		`, opts.Table, opts.Schema)
+}
+
+func (c *postgresDriver) Types() ([]*core.Structure, error) {
+	// composite types of tables are skipped
+	return c.c.TypesFromQuery(context.Background(), `
+		SELECT
+			n.nspname,
+			t.typname,
+			CASE t.typtype
+				WHEN 'e' THEN 'enum'
+				WHEN 'd' THEN 'domain of ' || format_type(t.typbasetype, t.typtypmod)
+				WHEN 'r' THEN 'range'
+				ELSE 'composite'
+			END,
+			e.enumlabel
+		FROM pg_type t
+		JOIN pg_namespace n ON n.oid = t.typnamespace
+		LEFT JOIN pg_enum e ON e.enumtypid = t.oid
+		LEFT JOIN pg_class c ON c.oid = t.typrelid
+		WHERE
+			n.nspname NOT IN ('pg_catalog', 'information_schema') AND
+			(t.typtype IN ('e', 'd', 'r') OR (t.typtype = 'c' AND c.relkind = 'c'))
+		ORDER BY n.nspname, t.typname, e.enumsortorder
+		`)
+}
+
+// postgresTableDDLQuery reconstructs CREATE TABLE statement of a table from the
+// catalog, followed by indexes which don't back a constraint.
+const postgresTableDDLQuery = `
+	WITH t AS (
+		SELECT c.oid
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relname = $2 AND c.relkind IN ('r', 'p', 'f')
+	),
+	lines AS (
+		SELECT 0 AS kind, a.attnum::int AS ord,
+			'    ' || quote_ident(a.attname) || ' ' || format_type(a.atttypid, a.atttypmod) ||
+			CASE WHEN a.attnotnull THEN ' NOT NULL' ELSE '' END ||
+			COALESCE(' DEFAULT ' || pg_get_expr(d.adbin, d.adrelid), '') AS line
+		FROM pg_attribute a
+		JOIN t ON a.attrelid = t.oid
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE a.attnum > 0 AND NOT a.attisdropped
+		UNION ALL
+		SELECT 1, (row_number() OVER (ORDER BY con.contype DESC, con.conname))::int,
+			'    CONSTRAINT ' || quote_ident(con.conname) || ' ' || pg_get_constraintdef(con.oid, true)
+		FROM pg_constraint con
+		JOIN t ON con.conrelid = t.oid
+		WHERE con.contype IN ('p', 'u', 'c', 'f', 'x')
+	)
+	SELECT
+		'CREATE TABLE ' || quote_ident($1) || '.' || quote_ident($2) || E' (\n' ||
+		string_agg(line, E',\n' ORDER BY kind, ord) || E'\n);\n' ||
+		COALESCE((
+			SELECT string_agg(pg_get_indexdef(i.indexrelid) || ';', E'\n')
+			FROM pg_index i
+			JOIN t ON i.indrelid = t.oid
+			WHERE NOT EXISTS (SELECT 1 FROM pg_constraint con WHERE con.conindid = i.indexrelid)
+		) || E'\n', '')
+	FROM lines
+	`
+
+func (c *postgresDriver) Definition(ctx context.Context, opts *core.TableOptions) (string, error) {
+	switch opts.Materialization {
+	case core.StructureTypeFunction, core.StructureTypeProcedure:
+		// overloaded routines share the name, so all of them are returned
+		return c.c.DefinitionFromQuery(ctx, 0, `
+			SELECT pg_get_functiondef(p.oid) || E';\n'
+			FROM pg_proc p
+			JOIN pg_namespace n ON n.oid = p.pronamespace
+			WHERE n.nspname = $1 AND p.proname = $2
+			ORDER BY p.oid
+			`, opts.Schema, opts.Table)
+	case core.StructureTypeTrigger:
+		// trigger names are unique per table, so triggers of all tables in the schema are returned
+		return c.c.DefinitionFromQuery(ctx, 0, `
+			SELECT pg_get_triggerdef(t.oid, true) || E';\n'
+			FROM pg_trigger t
+			JOIN pg_class c ON c.oid = t.tgrelid
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1 AND t.tgname = $2 AND NOT t.tgisinternal
+			ORDER BY c.relname
+			`, opts.Schema, opts.Table)
+	case core.StructureTypeTable:
+		return c.c.DefinitionFromQuery(ctx, 0, postgresTableDDLQuery, opts.Schema, opts.Table)
+	case core.StructureTypeView, core.StructureTypeMaterializedView:
+		kind := "VIEW"
+		if opts.Materialization == core.StructureTypeMaterializedView {
+			kind = "MATERIALIZED VIEW"
+		}
+		return c.c.DefinitionFromQuery(ctx, 0, `
+			SELECT 'CREATE `+kind+` ' || quote_ident(n.nspname) || '.' || quote_ident(c.relname) || E' AS\n' ||
+				pg_get_viewdef(c.oid, true) || E'\n'
+			FROM pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1 AND c.relname = $2 AND c.relkind IN ('v', 'm')
+			`, opts.Schema, opts.Table)
+	case core.StructureTypeIndex:
+		return c.c.DefinitionFromQuery(ctx, 0, `
+			SELECT pg_get_indexdef(c.oid) || E';\n'
+			FROM pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1 AND c.relname = $2 AND c.relkind IN ('i', 'I')
+			`, opts.Schema, opts.Table)
+	case core.StructureTypeSequence:
+		return c.c.DefinitionFromQuery(ctx, 0, `
+			SELECT
+				'CREATE SEQUENCE ' || quote_ident(schemaname) || '.' || quote_ident(sequencename) ||
+				' AS ' || data_type::text ||
+				' INCREMENT BY ' || increment_by ||
+				' MINVALUE ' || min_value ||
+				' MAXVALUE ' || max_value ||
+				' START WITH ' || start_value ||
+				' CACHE ' || cache_size ||
+				CASE WHEN cycle THEN ' CYCLE' ELSE ' NO CYCLE' END || E';\n'
+			FROM pg_sequences
+			WHERE schemaname = $1 AND sequencename = $2
+			`, opts.Schema, opts.Table)
+	default:
+		return "", fmt.Errorf("%w: %s", core.ErrDefinitionNotSupported, opts.Materialization)
+	}
+}
+
+func (c *postgresDriver) Keywords() []string {
+	return []string{"ILIKE", "RETURNING", "LATERAL", "ON CONFLICT", "DO NOTHING", "FILTER", "VACUUM", "ANALYZE", "EXPLAIN", "NULLS FIRST", "NULLS LAST", "DISTINCT ON"}
+}
+
+func (c *postgresDriver) RefreshMaterializedView(ctx context.Context, opts *core.TableOptions) error {
+	name := pq.QuoteIdentifier(opts.Table)
+	if opts.Schema != "" {
+		name = pq.QuoteIdentifier(opts.Schema) + "." + name
+	}
+	return c.c.ExecArgs(ctx, "REFRESH MATERIALIZED VIEW "+name)
+}
+
+func (c *postgresDriver) Partitions(ctx context.Context, opts *core.TableOptions) ([]*core.Partition, error) {
+	return c.c.PartitionsFromQuery(ctx, `
+		SELECT
+			n.nspname,
+			c.relname,
+			pg_get_expr(c.relpartbound, c.oid),
+			CASE WHEN c.reltuples < 0 THEN NULL ELSE c.reltuples::bigint END,
+			pg_total_relation_size(c.oid)
+		FROM pg_inherits i
+		JOIN pg_class c ON i.inhrelid = c.oid
+		JOIN pg_namespace n ON c.relnamespace = n.oid
+		JOIN pg_class p ON i.inhparent = p.oid
+		JOIN pg_namespace pn ON p.relnamespace = pn.oid
+		WHERE c.relispartition AND pn.nspname = $1 AND p.relname = $2
+		ORDER BY c.relname
+		`, opts.Schema, opts.Table)
+}
+
+// DetachPartition detaches the partition, which is kept as a standalone table.
+func (c *postgresDriver) DetachPartition(ctx context.Context, opts *core.TableOptions, partition *core.Partition) error {
+	return c.c.ExecArgs(ctx, fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s",
+		postgresQualifiedName(opts.Schema, opts.Table), postgresQualifiedName(partition.Schema, partition.Name)))
+}
+
+func (c *postgresDriver) DropPartition(ctx context.Context, opts *core.TableOptions, partition *core.Partition) error {
+	return c.c.ExecArgs(ctx, "DROP TABLE "+postgresQualifiedName(partition.Schema, partition.Name))
+}
+
+func postgresQualifiedName(schema, name string) string {
+	if schema == "" {
+		return pq.QuoteIdentifier(name)
+	}
+	return pq.QuoteIdentifier(schema) + "." + pq.QuoteIdentifier(name)
+}
+
+func (c *postgresDriver) SetComment(ctx context.Context, opts *core.TableOptions, column string, comment string) error {
+	target := "TABLE " + postgresQualifiedName(opts.Schema, opts.Table)
+	switch {
+	case column != "":
+		target = "COLUMN " + postgresQualifiedName(opts.Schema, opts.Table) + "." + pq.QuoteIdentifier(column)
+	case opts.Materialization == core.StructureTypeView:
+		target = "VIEW " + postgresQualifiedName(opts.Schema, opts.Table)
+	case opts.Materialization == core.StructureTypeMaterializedView:
+		target = "MATERIALIZED VIEW " + postgresQualifiedName(opts.Schema, opts.Table)
+	}
+
+	value := "NULL"
+	if comment != "" {
+		value = pq.QuoteLiteral(comment)
+	}
+
+	return c.c.ExecArgs(ctx, fmt.Sprintf("COMMENT ON %s IS %s", target, value))
+}
+
+// DependsOn lists relations and functions used by a view and tables referenced
+// by foreign keys of a table. Dependencies of function bodies aren't tracked
+// by postgres.
+func (c *postgresDriver) DependsOn(ctx context.Context, opts *core.TableOptions) ([]*core.DependencyNode, error) {
+	return c.c.DependenciesFromQuery(ctx, `
+		WITH target AS (SELECT to_regclass(format('%I.%I', $1::text, $2::text))::oid AS oid)
+		SELECT DISTINCT n.nspname::text, c.relname::text,
+			CASE c.relkind WHEN 'v' THEN 'view' WHEN 'm' THEN 'materialized_view' ELSE 'table' END
+		FROM target t
+		JOIN pg_rewrite r ON r.ev_class = t.oid
+		JOIN pg_depend d ON d.classid = 'pg_rewrite'::regclass AND d.objid = r.oid AND d.refclassid = 'pg_class'::regclass
+		JOIN pg_class c ON d.refobjid = c.oid
+		JOIN pg_namespace n ON c.relnamespace = n.oid
+		WHERE c.oid <> t.oid
+		UNION
+		SELECT DISTINCT n.nspname::text, p.proname::text,
+			CASE p.prokind WHEN 'p' THEN 'procedure' ELSE 'function' END
+		FROM target t
+		JOIN pg_rewrite r ON r.ev_class = t.oid
+		JOIN pg_depend d ON d.classid = 'pg_rewrite'::regclass AND d.objid = r.oid AND d.refclassid = 'pg_proc'::regclass
+		JOIN pg_proc p ON d.refobjid = p.oid
+		JOIN pg_namespace n ON p.pronamespace = n.oid
+		WHERE n.nspname NOT IN ('pg_catalog', 'information_schema')
+		UNION
+		SELECT n.nspname::text, c.relname::text, 'table'
+		FROM target t
+		JOIN pg_constraint con ON con.conrelid = t.oid AND con.contype = 'f'
+		JOIN pg_class c ON con.confrelid = c.oid
+		JOIN pg_namespace n ON c.relnamespace = n.oid
+		WHERE c.oid <> t.oid
+		ORDER BY 1, 2
+		`, opts.Schema, opts.Table)
+}
+
+// Dependents lists views using a relation or a function and tables
+// referencing a table with foreign keys.
+func (c *postgresDriver) Dependents(ctx context.Context, opts *core.TableOptions) ([]*core.DependencyNode, error) {
+	return c.c.DependenciesFromQuery(ctx, `
+		WITH target AS (
+			SELECT 'pg_class'::regclass AS classid, to_regclass(format('%I.%I', $1::text, $2::text))::oid AS oid
+			UNION ALL
+			SELECT 'pg_proc'::regclass, p.oid
+			FROM pg_proc p
+			JOIN pg_namespace n ON p.pronamespace = n.oid
+			WHERE n.nspname = $1 AND p.proname = $2
+		)
+		SELECT DISTINCT n.nspname::text, c.relname::text,
+			CASE c.relkind WHEN 'v' THEN 'view' WHEN 'm' THEN 'materialized_view' ELSE 'table' END
+		FROM target t
+		JOIN pg_depend d ON d.refclassid = t.classid AND d.refobjid = t.oid AND d.classid = 'pg_rewrite'::regclass
+		JOIN pg_rewrite r ON d.objid = r.oid
+		JOIN pg_class c ON r.ev_class = c.oid
+		JOIN pg_namespace n ON c.relnamespace = n.oid
+		WHERE c.oid <> t.oid
+		UNION
+		SELECT n.nspname::text, c.relname::text, 'table'
+		FROM target t
+		JOIN pg_constraint con ON con.confrelid = t.oid AND con.contype = 'f'
+		JOIN pg_class c ON con.conrelid = c.oid
+		JOIN pg_namespace n ON c.relnamespace = n.oid
+		WHERE t.classid = 'pg_class'::regclass AND c.oid <> t.oid
+		ORDER BY 1, 2
+		`, opts.Schema, opts.Table)
+}
+
+func (c *postgresDriver) ForeignServers(ctx context.Context) ([]*core.ForeignServer, error) {
+	return c.c.ForeignServersFromQuery(ctx, `
+		SELECT s.srvname, w.fdwname, COALESCE(array_to_string(s.srvoptions, ', '), '')
+		FROM pg_foreign_server s
+		JOIN pg_foreign_data_wrapper w ON s.srvfdw = w.oid
+		ORDER BY s.srvname
+		`)
+}
+
+// ForeignObjects lists foreign tables created for the server. Remote objects
+// which are not imported are not accessible.
+func (c *postgresDriver) ForeignObjects(ctx context.Context, server string) ([]*core.Structure, error) {
+	return c.c.ForeignObjectsFromQuery(ctx, `
+		SELECT n.nspname, c.relname, 'FOREIGN TABLE'
+		FROM pg_foreign_table ft
+		JOIN pg_foreign_server s ON ft.ftserver = s.oid
+		JOIN pg_class c ON ft.ftrelid = c.oid
+		JOIN pg_namespace n ON c.relnamespace = n.oid
+		WHERE s.srvname = $1
+		ORDER BY n.nspname, c.relname
+		`, server)
+}
+
+// IsTransient reports serialization failures and deadlocks, which roll back
+// the transaction.
+func (c *postgresDriver) IsTransient(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "40001", // serialization_failure
+			"40P01": // deadlock_detected
+			return true
+		}
+		return false
+	}
+	return isUnsentError(err)
+}
+
+// IsConnectionError reports dropped connections and shutdowns of the server.
+func (c *postgresDriver) IsConnectionError(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// connection_exception, admin_shutdown
+		return pqErr.Code.Class() == "08" || pqErr.Code == "57P01"
+	}
+	return isConnectionError(err)
+}
+
+func (c *postgresDriver) ClassifyError(err error) *core.QueryError {
+	return classifyPostgresError(err)
+}
+
+// classifyPostgresError returns details of errors of postgres compatible
+// databases, based on the class of SQLSTATE.
+func classifyPostgresError(err error) *core.QueryError {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return nil
+	}
+
+	category := core.ErrorCategoryUnknown
+	switch pqErr.Code {
+	case "42501": // insufficient_privilege
+		category = core.ErrorCategoryPermission
+	case "42P01", "42703", "42883", "3F000": // undefined table, column, function, schema
+		category = core.ErrorCategoryNotFound
+	case "57014", "55P03": // query_canceled (statement_timeout), lock_not_available
+		category = core.ErrorCategoryTimeout
+	default:
+		switch pqErr.Code.Class() {
+		case "28": // invalid_authorization_specification
+			category = core.ErrorCategoryAuth
+		case "08": // connection_exception
+			category = core.ErrorCategoryNetwork
+		case "42": // syntax_error_or_access_rule_violation
+			category = core.ErrorCategorySyntax
+		case "23": // integrity_constraint_violation
+			category = core.ErrorCategoryConstraint
+		}
+	}
+
+	position, _ := strconv.Atoi(pqErr.Position)
+	qErr := &core.QueryError{
+		Category: category,
+		Code:     string(pqErr.Code),
+		Position: position,
+		Hint:     pqErr.Hint,
+		Detail:   pqErr.Detail,
+	}
+	if missing, ok := postgresMissingObjectPatterns[pqErr.Code]; ok {
+		if match := missing.pattern.FindStringSubmatch(pqErr.Message); match != nil {
+			qErr.ObjectKind = missing.kind
+			qErr.Object = match[1]
+		}
+	}
+
+	return qErr
+}
+
+// ExplainError looks up current queries of processes which caused a deadlock.
+func (c *postgresDriver) ExplainError(ctx context.Context, qErr *core.QueryError, query string) []*core.ErrorHint {
+	if qErr.Code != "40P01" { // deadlock_detected
+		return nil
+	}
+
+	var pids []string
+	for _, match := range postgresDeadlockProcessPattern.FindAllStringSubmatch(qErr.Detail, -1) {
+		pids = append(pids, match[1])
+	}
+	if len(pids) < 1 {
+		return nil
+	}
+
+	result, err := c.c.QueryArgs(ctx, `
+		SELECT pid, query
+		FROM pg_stat_activity
+		WHERE pid = ANY(string_to_array($1, ',')::int[]) AND pid <> pg_backend_pid()
+		ORDER BY pid
+		`, strings.Join(pids, ","))
+	if err != nil {
+		return nil
+	}
+	defer result.Close()
+
+	var hints []*core.ErrorHint
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			break
+		}
+		other := fmt.Sprint(row[1])
+		if strings.TrimSpace(other) == "" || strings.TrimSpace(other) == strings.TrimSpace(query) {
+			continue
+		}
+		hints = append(hints, &core.ErrorHint{
+			Kind:    core.ErrorHintConflictingQuery,
+			Message: fmt.Sprintf("process %v last ran this query", row[0]),
+			Query:   other,
+		})
+	}
+
+	return hints
+}
+
+func (c *postgresDriver) BeginTx(ctx context.Context) (core.Transaction, error) {
+	tx, err := c.c.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// ImportBatch imports rows with COPY.
+func (c *postgresDriver) ImportBatch(ctx context.Context, table string, columns []string, rows []core.Row) error {
+	schema, name := splitTableName(table)
+
+	return c.c.WithTx(ctx, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, pq.CopyInSchema(schema, name, columns...))
+		if err != nil {
+			return fmt.Errorf("tx.PrepareContext: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, row := range rows {
+			_, err := stmt.ExecContext(ctx, row...)
+			if err != nil {
+				return err
+			}
+		}
+
+		// flush the buffered data
+		_, err = stmt.ExecContext(ctx)
+		return err
+	})
+}
+
+func (c *postgresDriver) Explain(ctx context.Context, query string, analyze bool) (*core.PlanNode, error) {
+	prefix := "EXPLAIN (FORMAT JSON) "
+	if analyze {
+		prefix = "EXPLAIN (ANALYZE, FORMAT JSON) "
+	}
+
+	doc, err := queryPlanJSON(ctx, c.c, prefix+query)
+	if err != nil {
+		return nil, err
+	}
+	return parsePGPlan(doc)
+}
+
+func (c *postgresDriver) StatementDialect() *core.StatementDialect {
+	return postgresStatementDialect
+}
+
+func (c *postgresDriver) JSONPathSyntax() core.JSONPathSyntax {
+	return core.JSONPathSyntaxArrow
+}
+
+func (c *postgresDriver) DialectOptions() *core.DialectOptions {
+	return postgresDialectOptions
+}
+
+func (c *postgresDriver) BlobDialect() *core.BlobDialect {
+	return &core.BlobDialect{
+		Placeholder: "$1",
+		Append: func(column string) string {
+			return column + " || $1"
+		},
+	}
+}
+
+func (c *postgresDriver) ExecChunks(ctx context.Context, first, next string, r io.Reader, chunkSize int) error {
+	return c.c.ExecChunks(ctx, first, next, r, chunkSize)
+}
+
+func (c *postgresDriver) SampleSyntax() core.SampleSyntax {
+	return core.SampleSyntax{
+		TableSample: "TABLESAMPLE SYSTEM (%s)",
+		Random:      "RANDOM()",
+	}
+}
@@ -0,0 +1,42 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestClassifyPostgresError_MissingObject(t *testing.T) {
+	r := require.New(t)
+
+	for message, expected := range map[*pq.Error][2]string{
+		{Code: "42703", Message: `column "nme" does not exist`}:                     {core.ErrorObjectColumn, "nme"},
+		{Code: "42703", Message: `column u.nme does not exist`}:                     {core.ErrorObjectColumn, "u.nme"},
+		{Code: "42703", Message: `column "nme" of relation "users" does not exist`}: {core.ErrorObjectColumn, "nme"},
+		{Code: "42P01", Message: `relation "public.user" does not exist`}:           {core.ErrorObjectTable, "public.user"},
+		{Code: "42883", Message: `function lenght(text) does not exist`}:            {core.ErrorObjectFunction, "lenght"},
+		{Code: "3F000", Message: `schema "pubic" does not exist`}:                   {core.ErrorObjectSchema, "pubic"},
+	} {
+		qErr := classifyPostgresError(message)
+		r.NotNil(qErr, message.Message)
+		r.Equal(core.ErrorCategoryNotFound, qErr.Category, message.Message)
+		r.Equal(expected[0], qErr.ObjectKind, message.Message)
+		r.Equal(expected[1], qErr.Object, message.Message)
+	}
+}
+
+func TestPostgisProcessor(t *testing.T) {
+	r := require.New(t)
+
+	geom, ok := postgisProcessor([]byte("0101000020e6100000000000000000f03f0000000000000040")).(*core.Geometry)
+	r.True(ok)
+	r.Equal("SRID=4326;POINT(1 2)", geom.String())
+
+	// values of other unnamed types stay text
+	r.Equal("happy", postgisProcessor([]byte("happy")))
+	r.Equal("0101", postgisProcessor([]byte("0101")))
+	r.Equal(42, postgisProcessor(42))
+}
@@ -0,0 +1,34 @@
+package adapters
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// bindNamePattern matches names which can be used as named bind parameters.
+var bindNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// procedureName quotes parts of the (optionally qualified) procedure name with
+// the dialect. Unquoted parts are folded to the case of the dialect first, so
+// the quoted name refers to the same procedure as the unquoted one.
+func procedureName(dialect *core.Dialect, name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = dialect.NormalizeIdentifier(strings.TrimSpace(part))
+	}
+	return dialect.QualifiedName(parts...)
+}
+
+// checkBindNames returns an error if names of params can't be used as named
+// bind parameters, which can't be quoted.
+func checkBindNames(params []*core.ProcedureParam) error {
+	for _, p := range params {
+		if !bindNamePattern.MatchString(p.Name) {
+			return fmt.Errorf("invalid parameter name: %q", p.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestProcedureName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		driver   core.Driver
+		input    string
+		expected string
+	}{
+		{name: "postgres", driver: &postgresDriver{}, input: "Public.archive", expected: `"public"."archive"`},
+		{name: "postgres quoted", driver: &postgresDriver{}, input: `"Archive"`, expected: `"Archive"`},
+		{name: "oracle", driver: &oracleDriver{}, input: "hr.pkg.archive", expected: `"HR"."PKG"."ARCHIVE"`},
+		{name: "mysql", driver: &mySQLDriver{}, input: "shop.archive`; DROP TABLE t", expected: "`shop`.`archive``; DROP TABLE t`"},
+		{name: "sqlserver", driver: &sqlServerDriver{}, input: "dbo.archive", expected: "[dbo].[archive]"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, procedureName(core.NewDialect(tc.driver), tc.input))
+		})
+	}
+}
+
+func TestCheckBindNames(t *testing.T) {
+	r := require.New(t)
+
+	r.NoError(checkBindNames([]*core.ProcedureParam{{Name: "id"}, {Name: "total_2"}}))
+	r.Error(checkBindNames([]*core.ProcedureParam{{Name: "id"}, {Name: "id = 1; DROP TABLE t --"}}))
+}
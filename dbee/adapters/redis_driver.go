@@ -3,8 +3,10 @@ package adapters
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 
@@ -12,7 +14,10 @@ import (
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 )
 
-var _ core.Driver = (*redisDriver)(nil)
+var (
+	_ core.Driver     = (*redisDriver)(nil)
+	_ core.Subscriber = (*redisDriver)(nil)
+)
 
 type redisDriver struct {
 	redis *redis.Client
@@ -55,6 +60,59 @@ func (c *redisDriver) Query(ctx context.Context, query string) (core.ResultStrea
 	return result, err
 }
 
+// Subscribe subscribes to pub/sub channels separated by whitespace. Channels
+// with glob characters (e.g. "jobs.*") are patterns.
+func (c *redisDriver) Subscribe(ctx context.Context, channel string) (core.ResultStream, error) {
+	channels := strings.Fields(channel)
+	if len(channels) < 1 {
+		return nil, errors.New("no channel provided")
+	}
+
+	var patterns, names []string
+	for _, ch := range channels {
+		if strings.ContainsAny(ch, "*?[") {
+			patterns = append(patterns, ch)
+		} else {
+			names = append(names, ch)
+		}
+	}
+
+	pubsub := c.redis.Subscribe(ctx)
+	if len(names) > 0 {
+		if err := pubsub.Subscribe(ctx, names...); err != nil {
+			_ = pubsub.Close()
+			return nil, fmt.Errorf("pubsub.Subscribe: %w", err)
+		}
+	}
+	if len(patterns) > 0 {
+		if err := pubsub.PSubscribe(ctx, patterns...); err != nil {
+			_ = pubsub.Close()
+			return nil, fmt.Errorf("pubsub.PSubscribe: %w", err)
+		}
+	}
+
+	next, hasNext := builders.NextEvents(ctx, func(ctx context.Context, yield func(...any)) error {
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case msg, ok := <-messages:
+				if !ok {
+					return errors.New("subscription closed")
+				}
+				yield(time.Now(), msg.Channel, msg.Payload)
+			}
+		}
+	})
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(core.Header{"received_at", "channel", "payload"}).
+		WithCloseFunc(func() { _ = pubsub.Close() }).
+		Build(), nil
+}
+
 func (c *redisDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
 	return []*core.Column{
 		{
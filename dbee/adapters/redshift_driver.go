@@ -13,8 +13,12 @@ import (
 )
 
 var (
-	_ core.Driver           = (*redshiftDriver)(nil)
-	_ core.DatabaseSwitcher = (*redshiftDriver)(nil)
+	_ core.Driver                   = (*redshiftDriver)(nil)
+	_ core.DatabaseSwitcher         = (*redshiftDriver)(nil)
+	_ core.StatementDialectProvider = (*redshiftDriver)(nil)
+	_ core.DialectOptionsProvider   = (*redshiftDriver)(nil)
+	_ core.ErrorClassifier          = (*redshiftDriver)(nil)
+	_ core.PoolStatsProvider        = (*redshiftDriver)(nil)
 )
 
 // redshiftDriver is a sql client for redshiftDriver.
@@ -35,6 +39,10 @@ func (r *redshiftDriver) Close() {
 	r.c.Close()
 }
 
+func (r *redshiftDriver) PoolStats() *core.PoolStats {
+	return r.c.PoolStats()
+}
+
 func (r *redshiftDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
 	return r.c.ColumnsFromQuery(`
 		SELECT column_name, data_type
@@ -112,3 +120,15 @@ func (r *redshiftDriver) SelectDatabase(name string) error {
 	r.c.Swap(db)
 	return nil
 }
+
+func (c *redshiftDriver) StatementDialect() *core.StatementDialect {
+	return postgresStatementDialect
+}
+
+func (c *redshiftDriver) DialectOptions() *core.DialectOptions {
+	return postgresDialectOptions
+}
+
+func (c *redshiftDriver) ClassifyError(err error) *core.QueryError {
+	return classifyPostgresError(err)
+}
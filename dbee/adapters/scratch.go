@@ -0,0 +1,115 @@
+//go:build (darwin && (amd64 || arm64)) || (freebsd && (386 || amd64 || arm || arm64)) || (linux && (386 || amd64 || arm || arm64 || ppc64le || riscv64 || s390x)) || (netbsd && amd64) || (openbsd && (amd64 || arm64)) || (windows && (amd64 || arm64))
+
+package adapters
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+// Register client
+func init() {
+	_ = register(&Scratch{}, "scratch")
+}
+
+var _ core.Adapter = (*Scratch)(nil)
+
+// defaultScratchDir is the directory of scratch databases if the url doesn't
+// specify one.
+var defaultScratchDir = filepath.Join(core.StorageDir(), "dbee-scratch")
+
+var scratchNamePattern = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// Scratch is an adapter of a sqlite database per project, which is created
+// on the first connect and kept between runs. It's meant for staging
+// intermediate results, importing files and prototyping without a database
+// server.
+//
+// The url is the project directory (the working directory if empty),
+// optionally followed by options in the form of a query string (e.g.
+// "/home/me/project?dir=/home/me/.local/state/dbee/scratch"):
+//   - dir: directory of scratch databases
+type Scratch struct{}
+
+// ScratchPath returns the path of the scratch database of the project in the
+// directory. Databases are named after the project directory and a hash of
+// its absolute path, so projects with the same name don't share them.
+func ScratchPath(dir, project string) (string, error) {
+	if project == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("os.Getwd: %w", err)
+		}
+		project = wd
+	}
+	project, err := filepath.Abs(project)
+	if err != nil {
+		return "", fmt.Errorf("filepath.Abs: %w", err)
+	}
+	if dir == "" {
+		dir = defaultScratchDir
+	}
+
+	sum := sha256.Sum256([]byte(project))
+	name := strings.Trim(scratchNamePattern.ReplaceAllString(filepath.Base(project), "_"), "_.")
+	if name == "" {
+		name = "root"
+	}
+
+	return filepath.Join(dir, name+"-"+hex.EncodeToString(sum[:6])+".db"), nil
+}
+
+func (s *Scratch) Connect(rawURL string) (core.Driver, error) {
+	project, query, _ := strings.Cut(rawURL, "?")
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("url.ParseQuery: %w", err)
+	}
+
+	dir, err := new(SQLite).expandPath(values.Get("dir"))
+	if err != nil {
+		return nil, err
+	}
+	project, err = new(SQLite).expandPath(project)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := ScratchPath(dir, project)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("os.MkdirAll: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open scratch database: %v", err)
+	}
+	// sql.Open doesn't create the file until the first query
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to open scratch database %q: %v", path, err)
+	}
+
+	return &sqliteDriver{
+		c: builders.NewClient(db),
+	}, nil
+}
+
+func (s *Scratch) GetHelpers(opts *core.TableOptions) map[string]string {
+	return new(SQLite).GetHelpers(opts)
+}
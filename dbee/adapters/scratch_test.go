@@ -0,0 +1,40 @@
+package adapters
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestScratch(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	project := filepath.Join(t.TempDir(), "my project")
+
+	driver, err := new(Scratch).Connect(project + "?dir=" + dir)
+	r.NoError(err)
+
+	// the database is created on connect and kept after the connection is closed
+	path, err := ScratchPath(dir, project)
+	r.NoError(err)
+	r.FileExists(path)
+	r.True(strings.HasPrefix(filepath.Base(path), "my_project-"))
+
+	demoRows(t, driver, "CREATE TABLE staged (id INTEGER)")
+	driver.Close()
+
+	same, err := new(Scratch).Connect(project + "?dir=" + dir)
+	r.NoError(err)
+	defer same.Close()
+	r.Equal([]core.Row{{int64(0)}}, demoRows(t, same, "SELECT COUNT(*) FROM staged"))
+
+	// other projects (even with the same name) have their own databases
+	other, err := ScratchPath(dir, filepath.Join(t.TempDir(), "my project"))
+	r.NoError(err)
+	r.NotEqual(path, other)
+}
@@ -0,0 +1,186 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/snowflakedb/gosnowflake"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var (
+	_ core.Driver                   = (*snowflakeDriver)(nil)
+	_ core.StatementDialectProvider = (*snowflakeDriver)(nil)
+	_ core.JSONPathDialect          = (*snowflakeDriver)(nil)
+	_ core.PoolStatsProvider        = (*snowflakeDriver)(nil)
+)
+
+type snowflakeDriver struct {
+	c *builders.Client
+	// database of the connection url (empty lists all databases)
+	database string
+}
+
+// Query runs the query and reports its snowflake query id in the meta, so
+// long-running queries can be looked up in the query history.
+func (c *snowflakeDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	// the driver sends the id as soon as the query is submitted
+	queryID := make(chan string, 1)
+	ctx = gosnowflake.WithQueryIDChan(ctx, queryID)
+
+	result, err := c.c.QueryUntilNotEmpty(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case id := <-queryID:
+		if meta := result.Meta(); meta != nil {
+			meta.QueryID = id
+		}
+	default:
+	}
+
+	return result, nil
+}
+
+func (c *snowflakeDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	database, schema := splitSnowflakeSchema(opts.Schema)
+	columns := "information_schema.columns"
+	if database != "" {
+		columns = snowflakeQuote(database) + "." + columns
+	}
+
+	return c.c.ColumnsFromQuery(`
+		SELECT column_name, data_type, is_nullable, column_default
+		FROM `+columns+`
+		WHERE table_schema = '%s' AND table_name = '%s'
+		ORDER BY ordinal_position
+		`, schema, opts.Table)
+}
+
+// Structure lists databases with their schemas and tables. If the url has a
+// database, only its schemas are listed.
+func (c *snowflakeDriver) Structure() ([]*core.Structure, error) {
+	query := "SHOW TERSE OBJECTS IN ACCOUNT"
+	if c.database != "" {
+		query = "SHOW TERSE OBJECTS IN DATABASE " + snowflakeQuote(c.database)
+	}
+
+	rows, err := c.Query(context.TODO(), query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// columns of SHOW are found by their names
+	index := make(map[string]int)
+	for i, col := range rows.Header() {
+		index[strings.ToLower(col)] = i
+	}
+	for _, col := range []string{"name", "database_name", "schema_name", "kind"} {
+		if _, ok := index[col]; !ok {
+			return nil, errors.New("could not retrieve structure: insufficient info")
+		}
+	}
+
+	var structure []*core.Structure
+	databases := make(map[string]*core.Structure)
+	schemas := make(map[string]*core.Structure)
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, err
+		}
+		value := func(col string) string {
+			i := index[col]
+			if i >= len(row) {
+				return ""
+			}
+			return fmt.Sprint(row[i])
+		}
+
+		database := value("database_name")
+		schemaName := value("schema_name")
+		if strings.EqualFold(schemaName, "INFORMATION_SCHEMA") {
+			continue
+		}
+
+		db, ok := databases[database]
+		if !ok {
+			db = &core.Structure{
+				Name: database,
+				Type: core.StructureTypeNone,
+			}
+			databases[database] = db
+			structure = append(structure, db)
+		}
+
+		qualified := database + "." + schemaName
+		schema, ok := schemas[qualified]
+		if !ok {
+			schema = &core.Structure{
+				Name:   schemaName,
+				Schema: qualified,
+				Type:   core.StructureTypeNone,
+			}
+			schemas[qualified] = schema
+			db.Children = append(db.Children, schema)
+		}
+
+		schema.Children = append(schema.Children, &core.Structure{
+			Name:   value("name"),
+			Schema: qualified,
+			Type:   getPGStructureType(value("kind")),
+		})
+	}
+
+	return structure, nil
+}
+
+func (c *snowflakeDriver) Close() {
+	c.c.Close()
+}
+
+func (c *snowflakeDriver) PoolStats() *core.PoolStats {
+	return c.c.PoolStats()
+}
+
+func (c *snowflakeDriver) StatementDialect() *core.StatementDialect {
+	return snowflakeStatementDialect
+}
+
+func (c *snowflakeDriver) JSONPathSyntax() core.JSONPathSyntax {
+	return core.JSONPathSyntaxColon
+}
+
+// splitSnowflakeSchema splits the schema of a structure node to the database
+// and the schema (e.g. "SALES.PUBLIC").
+func splitSnowflakeSchema(schema string) (database, name string) {
+	if db, rest, ok := strings.Cut(schema, "."); ok {
+		return db, rest
+	}
+	return "", schema
+}
+
+func snowflakeQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// snowflakeQualifiedName returns the quoted name of the table, qualified by
+// the schema and the database (if any).
+func snowflakeQualifiedName(schema, table string) string {
+	var parts []string
+	database, schema := splitSnowflakeSchema(schema)
+	if database != "" {
+		parts = append(parts, snowflakeQuote(database))
+	}
+	if schema != "" {
+		parts = append(parts, snowflakeQuote(schema))
+	}
+	return strings.Join(append(parts, snowflakeQuote(table)), ".")
+}
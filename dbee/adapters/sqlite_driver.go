@@ -2,12 +2,43 @@ package adapters
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 )
 
-var _ core.Driver = (*sqliteDriver)(nil)
+var (
+	_ core.Driver                   = (*sqliteDriver)(nil)
+	_ core.Transactor               = (*sqliteDriver)(nil)
+	_ core.KeyLister                = (*sqliteDriver)(nil)
+	_ core.TableInspector           = (*sqliteDriver)(nil)
+	_ core.TriggerLister            = (*sqliteDriver)(nil)
+	_ core.DefinitionProvider       = (*sqliteDriver)(nil)
+	_ core.Importer                 = (*sqliteDriver)(nil)
+	_ core.StatementDialectProvider = (*sqliteDriver)(nil)
+	_ core.JSONPathDialect          = (*sqliteDriver)(nil)
+	_ core.BlobWriter               = (*sqliteDriver)(nil)
+	_ core.ObjectSearcher           = (*sqliteDriver)(nil)
+	_ core.ServerInfoProvider       = (*sqliteDriver)(nil)
+	_ core.ObjectActionQuerier      = (*sqliteDriver)(nil)
+	_ core.SystemObjectClassifier   = (*sqliteDriver)(nil)
+	_ core.KeywordProvider          = (*sqliteDriver)(nil)
+	_ core.ErrorClassifier          = (*sqliteDriver)(nil)
+	_ core.PoolStatsProvider        = (*sqliteDriver)(nil)
+)
+
+// sqliteMissingObjectPattern matches the kind and the name of undefined
+// objects in error messages.
+var sqliteMissingObjectPattern = regexp.MustCompile(`no such (column|table|function): (\S+)`)
+
+// sqliteTriggerPattern matches timing and event of a CREATE TRIGGER statement.
+var sqliteTriggerPattern = regexp.MustCompile(`(?is)^\s*CREATE\s+(?:TEMP(?:ORARY)?\s+)?TRIGGER\s+.*?\s(BEFORE|AFTER|INSTEAD\s+OF)?\s*(DELETE|INSERT|UPDATE)\b`)
 
 type sqliteDriver struct {
 	c *builders.Client
@@ -18,8 +49,154 @@ func (c *sqliteDriver) Query(ctx context.Context, query string) (core.ResultStre
 	return c.c.QueryUntilNotEmpty(ctx, query, "select changes() as 'Rows Affected'")
 }
 
+// ClassifyError returns details of sqlite errors based on the primary result
+// code. Syntax errors share the generic SQLITE_ERROR code with other errors,
+// so they are recognized by the message.
+func (c *sqliteDriver) ClassifyError(err error) *core.QueryError {
+	var sqliteErr interface {
+		error
+		Code() int
+	}
+	if !errors.As(err, &sqliteErr) {
+		return nil
+	}
+
+	code := sqliteErr.Code()
+	category := core.ErrorCategoryUnknown
+	switch code & 0xff {
+	case 1: // SQLITE_ERROR
+		switch msg := sqliteErr.Error(); {
+		case strings.Contains(msg, "syntax error"), strings.Contains(msg, "incomplete input"):
+			category = core.ErrorCategorySyntax
+		case strings.Contains(msg, "no such"):
+			category = core.ErrorCategoryNotFound
+		}
+	case 3, 8, 23: // SQLITE_PERM, SQLITE_READONLY, SQLITE_AUTH
+		category = core.ErrorCategoryPermission
+	case 5, 6: // SQLITE_BUSY, SQLITE_LOCKED
+		category = core.ErrorCategoryTimeout
+	case 19: // SQLITE_CONSTRAINT
+		category = core.ErrorCategoryConstraint
+	}
+
+	qErr := &core.QueryError{
+		Category: category,
+		Code:     strconv.Itoa(code),
+	}
+	if match := sqliteMissingObjectPattern.FindStringSubmatch(sqliteErr.Error()); match != nil {
+		qErr.ObjectKind = match[1]
+		qErr.Object = match[2]
+	}
+
+	return qErr
+}
+
 func (c *sqliteDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
-	return c.c.ColumnsFromQuery("SELECT name, type FROM pragma_table_info('%s')", opts.Table)
+	return c.c.ColumnsFromQuery(`SELECT name, type, NOT "notnull", dflt_value, pk > 0 FROM pragma_table_info('%s')`, opts.Table)
+}
+
+func (c *sqliteDriver) PrimaryKey(opts *core.TableOptions) ([]string, error) {
+	columns, err := c.c.ColumnsFromQuery("SELECT name, type FROM pragma_table_info('%s') WHERE pk > 0 ORDER BY pk", opts.Table)
+	if err != nil {
+		return nil, err
+	}
+	return columnNames(columns), nil
+}
+
+func (c *sqliteDriver) Indexes(ctx context.Context, opts *core.TableOptions) ([]*core.Index, error) {
+	return c.c.IndexesFromQuery(ctx, `
+		SELECT il.name, ii.name, il."unique", il.origin = 'pk'
+		FROM pragma_index_list(?) il
+		JOIN pragma_index_info(il.name) ii
+		ORDER BY il.name, ii.seqno
+		`, opts.Table)
+}
+
+// Constraints lists primary key, unique and foreign key constraints.
+// Check constraints are only stored in the table's sql, so they are not listed.
+func (c *sqliteDriver) Constraints(ctx context.Context, opts *core.TableOptions) ([]*core.Constraint, error) {
+	return c.c.ConstraintsFromQuery(ctx, `
+		SELECT * FROM (
+			SELECT 'primary key', 'PRIMARY KEY', name, NULL, NULL, NULL, NULL
+			FROM pragma_table_info(?1)
+			WHERE pk > 0
+			ORDER BY pk
+		)
+		UNION ALL
+		SELECT * FROM (
+			SELECT il.name, 'UNIQUE', ii.name, NULL, NULL, NULL, NULL
+			FROM pragma_index_list(?1) il
+			JOIN pragma_index_info(il.name) ii
+			WHERE il.origin = 'u'
+			ORDER BY il.name, ii.seqno
+		)
+		UNION ALL
+		SELECT * FROM (
+			SELECT 'fk_' || id, 'FOREIGN KEY', "from", NULL, NULL, "table", "to"
+			FROM pragma_foreign_key_list(?1)
+			ORDER BY id, seq
+		)
+		`, opts.Table)
+}
+
+// Triggers lists triggers of the table. Event and timing are parsed from the
+// trigger's sql, because sqlite doesn't store them separately.
+func (c *sqliteDriver) Triggers(ctx context.Context, opts *core.TableOptions) ([]*core.Trigger, error) {
+	result, err := c.c.QueryArgs(ctx, `
+		SELECT name, sql
+		FROM sqlite_schema
+		WHERE type = 'trigger' AND tbl_name = ?
+		ORDER BY name
+		`, opts.Table)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	var triggers []*core.Trigger
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		name, _ := row[0].(string)
+		sql, _ := row[1].(string)
+
+		trigger := &core.Trigger{
+			Name:   name,
+			Timing: "BEFORE",
+		}
+		if match := sqliteTriggerPattern.FindStringSubmatch(sql); match != nil {
+			if match[1] != "" {
+				trigger.Timing = strings.ToUpper(strings.Join(strings.Fields(match[1]), " "))
+			}
+			trigger.Event = strings.ToUpper(match[2])
+		}
+
+		triggers = append(triggers, trigger)
+	}
+
+	return triggers, nil
+}
+
+func (c *sqliteDriver) Definition(ctx context.Context, opts *core.TableOptions) (string, error) {
+	switch opts.Materialization {
+	case core.StructureTypeTable, core.StructureTypeView, core.StructureTypeIndex, core.StructureTypeTrigger:
+	default:
+		return "", fmt.Errorf("%w: %s", core.ErrDefinitionNotSupported, opts.Materialization)
+	}
+
+	// object types of sqlite_schema match our names
+	return c.c.DefinitionFromQuery(ctx, 0, `
+		SELECT sql || ';'
+		FROM sqlite_schema
+		WHERE type = ? AND name = ?
+		`, opts.Materialization.String(), opts.Table)
+}
+
+func (c *sqliteDriver) Keywords() []string {
+	return []string{"LIMIT", "OFFSET", "GLOB", "PRAGMA", "RETURNING", "UPSERT", "ON CONFLICT", "AUTOINCREMENT", "WITHOUT ROWID", "VACUUM", "EXPLAIN QUERY PLAN"}
 }
 
 func (c *sqliteDriver) Structure() ([]*core.Structure, error) {
@@ -49,6 +226,89 @@ func (c *sqliteDriver) Structure() ([]*core.Structure, error) {
 	return schema, nil
 }
 
+// IsSystem reports internal tables (e.g. sqlite_sequence).
+func (c *sqliteDriver) IsSystem(node *core.Structure) bool {
+	return strings.HasPrefix(node.Name, "sqlite_")
+}
+
+func (c *sqliteDriver) SearchObjects(ctx context.Context, pattern string, limit int) ([]*core.ObjectMatch, error) {
+	return c.c.ObjectMatchesFromQuery(ctx, `
+		SELECT '', '', name, 'table'
+		FROM sqlite_schema
+		WHERE type = 'table' AND name LIKE ?1
+		UNION ALL
+		SELECT '', t.name, c.name, 'column'
+		FROM sqlite_schema t
+		JOIN pragma_table_info(t.name) c
+		WHERE t.type = 'table' AND c.name LIKE ?1
+		LIMIT ?2
+		`, "%"+pattern+"%", limit)
+}
+
+// ServerInfo reports the library version and pragmas of the database file.
+// SQLite is embedded, so there is no uptime.
+func (c *sqliteDriver) ServerInfo(ctx context.Context) (*core.ServerInfo, error) {
+	return c.c.ServerInfoFromQuery(ctx, `
+		SELECT 'version', NULL, sqlite_version()
+		UNION ALL
+		SELECT 'setting', 'encoding', encoding FROM pragma_encoding
+		UNION ALL
+		SELECT 'setting', 'journal_mode', journal_mode FROM pragma_journal_mode
+		UNION ALL
+		SELECT 'setting', 'foreign_keys', foreign_keys FROM pragma_foreign_keys
+		UNION ALL
+		SELECT 'setting', 'page_size', page_size FROM pragma_page_size
+		`)
+}
+
+// ObjectActionQuery replaces TRUNCATE, which sqlite doesn't have, with DELETE.
+func (c *sqliteDriver) ObjectActionQuery(action string, opts *core.TableOptions) (string, bool) {
+	if action != core.ObjectActionTruncate {
+		return "", false
+	}
+	return `DELETE FROM "` + strings.ReplaceAll(opts.Table, `"`, `""`) + `"`, true
+}
+
 func (c *sqliteDriver) Close() {
 	c.c.Close()
 }
+
+func (c *sqliteDriver) PoolStats() *core.PoolStats {
+	return c.c.PoolStats()
+}
+
+func (c *sqliteDriver) BeginTx(ctx context.Context) (core.Transaction, error) {
+	tx, err := c.c.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// ImportBatch imports rows with a prepared INSERT statement.
+func (c *sqliteDriver) ImportBatch(ctx context.Context, table string, columns []string, rows []core.Row) error {
+	schema, name := splitTableName(table)
+	return c.c.InsertBatch(ctx, core.NewDialect(c), schema, name, columns, rows, builders.PlaceholderQuestion)
+}
+
+func (c *sqliteDriver) StatementDialect() *core.StatementDialect {
+	return sqliteStatementDialect
+}
+
+func (c *sqliteDriver) JSONPathSyntax() core.JSONPathSyntax {
+	return core.JSONPathSyntaxExtract
+}
+
+func (c *sqliteDriver) BlobDialect() *core.BlobDialect {
+	return &core.BlobDialect{
+		Placeholder: "?",
+		Append: func(column string) string {
+			// || concatenates values as text
+			return "CAST(" + column + " || ? AS BLOB)"
+		},
+	}
+}
+
+func (c *sqliteDriver) ExecChunks(ctx context.Context, first, next string, r io.Reader, chunkSize int) error {
+	return c.c.ExecChunks(ctx, first, next, r, chunkSize)
+}
@@ -0,0 +1,471 @@
+package adapters
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestSQLite_IndexesAndConstraints(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	driver, err := new(SQLite).Connect(":memory:")
+	r.NoError(err)
+	defer driver.Close()
+	sqlite := driver.(*sqliteDriver)
+
+	for _, query := range []string{
+		"CREATE TABLE parent (id INTEGER PRIMARY KEY, code TEXT UNIQUE)",
+		"CREATE TABLE child (a INT, b INT, parent_id INT REFERENCES parent(id), PRIMARY KEY (a, b))",
+		"CREATE INDEX child_idx ON child (b, parent_id)",
+	} {
+		r.NoError(sqlite.c.ExecArgs(ctx, query))
+	}
+
+	opts := &core.TableOptions{Table: "child"}
+
+	indexes, err := sqlite.Indexes(ctx, opts)
+	r.NoError(err)
+	r.Len(indexes, 2)
+	r.Equal("child_idx", indexes[0].Name)
+	r.Equal([]string{"b", "parent_id"}, indexes[0].Columns)
+	r.False(indexes[0].Unique)
+	r.True(indexes[1].Primary)
+	r.Equal([]string{"a", "b"}, indexes[1].Columns)
+
+	constraints, err := sqlite.Constraints(ctx, opts)
+	r.NoError(err)
+	r.Len(constraints, 2)
+	r.Equal(core.ConstraintTypePrimaryKey, constraints[0].Type)
+	r.Equal([]string{"a", "b"}, constraints[0].Columns)
+	r.Equal(core.ConstraintTypeForeignKey, constraints[1].Type)
+	r.Equal([]string{"parent_id"}, constraints[1].Columns)
+	r.Equal("parent", constraints[1].ReferencedTable)
+	r.Equal([]string{"id"}, constraints[1].ReferencedColumns)
+
+	constraints, err = sqlite.Constraints(ctx, &core.TableOptions{Table: "parent"})
+	r.NoError(err)
+	r.Len(constraints, 2)
+	r.Equal(core.ConstraintTypeUnique, constraints[1].Type)
+	r.Equal([]string{"code"}, constraints[1].Columns)
+}
+
+func TestSQLite_Triggers(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	driver, err := new(SQLite).Connect(":memory:")
+	r.NoError(err)
+	defer driver.Close()
+	sqlite := driver.(*sqliteDriver)
+
+	for _, query := range []string{
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, updated TEXT)",
+		"CREATE TABLE log (msg TEXT)",
+		"CREATE TRIGGER items_insert AFTER INSERT ON items BEGIN INSERT INTO log VALUES ('insert'); END",
+		"CREATE TRIGGER items_touch UPDATE OF id ON items BEGIN UPDATE items SET updated = 'now'; END",
+	} {
+		r.NoError(sqlite.c.ExecArgs(ctx, query))
+	}
+
+	triggers, err := sqlite.Triggers(ctx, &core.TableOptions{Table: "items"})
+	r.NoError(err)
+	r.Equal([]*core.Trigger{
+		{Name: "items_insert", Event: "INSERT", Timing: "AFTER"},
+		{Name: "items_touch", Event: "UPDATE", Timing: "BEFORE"},
+	}, triggers)
+
+	definition, err := sqlite.Definition(ctx, &core.TableOptions{
+		Table:           "items_insert",
+		Materialization: core.StructureTypeTrigger,
+	})
+	r.NoError(err)
+	r.Contains(definition, "CREATE TRIGGER items_insert AFTER INSERT ON items")
+}
+
+func TestSQLite_Definition(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	driver, err := new(SQLite).Connect(":memory:")
+	r.NoError(err)
+	defer driver.Close()
+	sqlite := driver.(*sqliteDriver)
+
+	for _, query := range []string{
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE INDEX items_name ON items (name)",
+		"CREATE VIEW named AS SELECT * FROM items WHERE name IS NOT NULL",
+	} {
+		r.NoError(sqlite.c.ExecArgs(ctx, query))
+	}
+
+	definition, err := sqlite.Definition(ctx, &core.TableOptions{Table: "items", Materialization: core.StructureTypeTable})
+	r.NoError(err)
+	r.Equal("CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT);", definition)
+
+	definition, err = sqlite.Definition(ctx, &core.TableOptions{Table: "items_name", Materialization: core.StructureTypeIndex})
+	r.NoError(err)
+	r.Equal("CREATE INDEX items_name ON items (name);", definition)
+
+	definition, err = sqlite.Definition(ctx, &core.TableOptions{Table: "named", Materialization: core.StructureTypeView})
+	r.NoError(err)
+	r.Contains(definition, "CREATE VIEW named")
+
+	_, err = sqlite.Definition(ctx, &core.TableOptions{Table: "items", Materialization: core.StructureTypeSequence})
+	r.ErrorIs(err, core.ErrDefinitionNotSupported)
+}
+
+func TestSQLite_Columns(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	driver, err := new(SQLite).Connect(":memory:")
+	r.NoError(err)
+	defer driver.Close()
+	sqlite := driver.(*sqliteDriver)
+
+	r.NoError(sqlite.c.ExecArgs(ctx, "CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT NOT NULL DEFAULT 'none', note TEXT)"))
+
+	columns, err := sqlite.Columns(&core.TableOptions{Table: "items"})
+	r.NoError(err)
+	r.Equal([]*core.Column{
+		{Name: "id", Type: "INTEGER", PrimaryKey: true},
+		{Name: "name", Type: "TEXT", NotNull: true, Default: "'none'"},
+		{Name: "note", Type: "TEXT"},
+	}, columns)
+}
+
+func TestSQLite_SearchObjects(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	driver, err := new(SQLite).Connect(":memory:")
+	r.NoError(err)
+	defer driver.Close()
+	sqlite := driver.(*sqliteDriver)
+
+	for _, query := range []string{
+		"CREATE TABLE customer_orders (id INTEGER PRIMARY KEY, customer TEXT)",
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)",
+	} {
+		r.NoError(sqlite.c.ExecArgs(ctx, query))
+	}
+
+	matches, err := sqlite.SearchObjects(ctx, "CUSTOMER", 10)
+	r.NoError(err)
+	r.Equal([]*core.ObjectMatch{
+		{Name: "customer_orders", Type: core.StructureTypeTable},
+		{Table: "customer_orders", Name: "customer", Type: core.StructureTypeColumn},
+	}, matches)
+	r.Equal([]string{"customer_orders", "customer"}, matches[1].Path())
+
+	matches, err = sqlite.SearchObjects(ctx, "id", 1)
+	r.NoError(err)
+	r.Len(matches, 1)
+}
+
+func TestSQLite_ServerInfo(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	driver, err := new(SQLite).Connect(":memory:")
+	r.NoError(err)
+	defer driver.Close()
+	sqlite := driver.(*sqliteDriver)
+
+	info, err := sqlite.ServerInfo(ctx)
+	r.NoError(err)
+	r.NotEmpty(info.Version)
+	r.Equal(int64(-1), info.Uptime)
+	r.Equal(&core.Setting{Name: "encoding", Value: "UTF-8"}, info.Settings[0])
+	r.Equal(&core.Setting{Name: "journal_mode", Value: "memory"}, info.Settings[1])
+	r.Len(info.Settings, 4)
+}
+
+func TestSQLite_IsSystem(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	driver, err := new(SQLite).Connect(":memory:")
+	r.NoError(err)
+	defer driver.Close()
+	sqlite := driver.(*sqliteDriver)
+
+	r.NoError(sqlite.c.ExecArgs(ctx, "CREATE TABLE items (id INTEGER PRIMARY KEY AUTOINCREMENT)"))
+
+	structure, err := sqlite.Structure()
+	r.NoError(err)
+	r.Len(structure, 2)
+	for _, node := range structure {
+		r.Equal(node.Name == "sqlite_sequence", sqlite.IsSystem(node), node.Name)
+	}
+}
+
+func TestSQLite_ClassifyError(t *testing.T) {
+	r := require.New(t)
+
+	driver, err := new(SQLite).Connect(":memory:")
+	r.NoError(err)
+	defer driver.Close()
+	sqlite := driver.(*sqliteDriver)
+
+	for query, category := range map[string]core.ErrorCategory{
+		"SELEC 1":                  core.ErrorCategorySyntax,
+		"SELECT * FROM missing":    core.ErrorCategoryNotFound,
+		"INSERT INTO t VALUES (1)": core.ErrorCategoryConstraint,
+	} {
+		r.NoError(sqlite.c.ExecArgs(context.Background(), "CREATE TABLE IF NOT EXISTS t (id INT PRIMARY KEY)"))
+		r.NoError(sqlite.c.ExecArgs(context.Background(), "INSERT OR IGNORE INTO t VALUES (1)"))
+
+		_, err := sqlite.Query(context.Background(), query)
+		r.Error(err, query)
+		qErr := sqlite.ClassifyError(err)
+		r.NotNil(qErr, query)
+		r.Equal(category, qErr.Category, query)
+	}
+}
+
+func TestSQLite_ClassifyMissingObject(t *testing.T) {
+	r := require.New(t)
+
+	driver, err := new(SQLite).Connect(":memory:")
+	r.NoError(err)
+	defer driver.Close()
+	sqlite := driver.(*sqliteDriver)
+
+	r.NoError(sqlite.c.ExecArgs(context.Background(), "CREATE TABLE t (id INT PRIMARY KEY, name TEXT)"))
+
+	for query, expected := range map[string][2]string{
+		"SELECT nme FROM t":          {core.ErrorObjectColumn, "nme"},
+		"SELECT * FROM tt":           {core.ErrorObjectTable, "tt"},
+		"SELECT lenght(name) FROM t": {core.ErrorObjectFunction, "lenght"},
+	} {
+		_, err := sqlite.Query(context.Background(), query)
+		r.Error(err, query)
+		qErr := sqlite.ClassifyError(err)
+		r.NotNil(qErr, query)
+		r.Equal(core.ErrorCategoryNotFound, qErr.Category, query)
+		r.Equal(expected[0], qErr.ObjectKind, query)
+		r.Equal(expected[1], qErr.Object, query)
+	}
+}
+
+func TestSQLite_UploadBlob(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	url := filepath.Join(dir, "blobs.db")
+	connection, err := core.NewConnection(&core.ConnectionParams{Type: "sqlite", URL: url}, new(SQLite))
+	r.NoError(err)
+	defer connection.Close()
+
+	driver, err := new(SQLite).Connect(url)
+	r.NoError(err)
+	defer driver.Close()
+	sqlite := driver.(*sqliteDriver)
+	r.NoError(sqlite.c.ExecArgs(ctx, "CREATE TABLE files (id INT, name TEXT, data BLOB)"))
+
+	hexOf := func() string {
+		rows, err := sqlite.Query(ctx, "SELECT hex(data) FROM files WHERE id = 1")
+		r.NoError(err)
+		defer rows.Close()
+		r.True(rows.HasNext())
+		row, err := rows.Next()
+		r.NoError(err)
+		return fmt.Sprint(row[0])
+	}
+
+	// binary content with null bytes, written in multiple chunks
+	content := []byte{0x00, 0x01, 0xff, 0x00, 'a', 'b', 0x7f, 0x00, 0x10, 0x20}
+	path := filepath.Join(dir, "file.bin")
+	r.NoError(os.WriteFile(path, content, 0o644))
+
+	written, err := connection.UploadBlob(&core.BlobUploadOptions{
+		Table:     "files",
+		Column:    "data",
+		Path:      path,
+		Values:    map[string]any{"id": 1, "name": "file.bin"},
+		ChunkSize: 3,
+	})
+	r.NoError(err)
+	r.EqualValues(len(content), written)
+	r.Equal(strings.ToUpper(hex.EncodeToString(content)), hexOf())
+
+	// update of an existing row
+	r.NoError(os.WriteFile(path, content[:4], 0o644))
+	_, err = connection.UploadBlob(&core.BlobUploadOptions{
+		Table:     "files",
+		Column:    "data",
+		Path:      path,
+		Key:       map[string]any{"id": 1},
+		ChunkSize: 2,
+	})
+	r.NoError(err)
+	r.Equal(strings.ToUpper(hex.EncodeToString(content[:4])), hexOf())
+
+	// empty files write empty values
+	r.NoError(os.WriteFile(path, nil, 0o644))
+	_, err = connection.UploadBlob(&core.BlobUploadOptions{
+		Table:  "files",
+		Column: "data",
+		Path:   path,
+		Key:    map[string]any{"id": 1},
+	})
+	r.NoError(err)
+	r.Equal("", hexOf())
+}
+
+func TestSQLite_ImportBatch(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	driver, err := new(SQLite).Connect(":memory:")
+	r.NoError(err)
+	defer driver.Close()
+	sqlite := driver.(*sqliteDriver)
+	r.NoError(sqlite.c.ExecArgs(ctx, `CREATE TABLE "order items" ("group" TEXT, "a""b" INT)`))
+
+	// names are quoted, so keywords, spaces and quotes work
+	err = sqlite.ImportBatch(ctx, "main.order items", []string{"group", `a"b`}, []core.Row{{"x", 1}, {"y", 2}})
+	r.NoError(err)
+
+	rows, err := sqlite.Query(ctx, `SELECT "group", "a""b" FROM "order items" ORDER BY 1`)
+	r.NoError(err)
+	defer rows.Close()
+	var got []core.Row
+	for rows.HasNext() {
+		row, err := rows.Next()
+		r.NoError(err)
+		got = append(got, row)
+	}
+	r.Equal([]core.Row{{"x", int64(1)}, {"y", int64(2)}}, got)
+}
+
+func TestSQLite_Federate(t *testing.T) {
+	r := require.New(t)
+	dir := t.TempDir()
+
+	connect := func(name string, setup ...string) *core.Connection {
+		c, err := NewConnection(&core.ConnectionParams{ID: core.ConnectionID(name), Name: name, Type: "sqlite", URL: filepath.Join(dir, name+".db")})
+		r.NoError(err)
+		t.Cleanup(c.Close)
+		for _, query := range setup {
+			call := c.Execute(query, nil)
+			<-call.Done()
+			r.NoError(call.Err())
+		}
+		return c
+	}
+
+	users := connect("users",
+		"CREATE TABLE users (id INTEGER, name TEXT)",
+		"INSERT INTO users VALUES (1, 'alice'), (2, 'bob')",
+	)
+	orders := connect("orders",
+		"CREATE TABLE orders (user_id INTEGER, amount REAL)",
+		"INSERT INTO orders VALUES (1, 10.5), (1, 4.5), (2, 1)",
+	)
+	engine := connect("engine")
+
+	// a finished call, e.g. a record of the call history
+	history := users.Execute("SELECT id, name, name FROM users", nil)
+	<-history.Done()
+	r.NoError(history.Err())
+
+	call, err := engine.Federate([]*core.FederatedTable{
+		{Name: "o", Connection: orders, Query: "SELECT * FROM orders"},
+		{Name: "u", Call: history},
+	}, `SELECT u.name, u.name_2, SUM(o.amount) FROM u JOIN o ON o.user_id = u.id GROUP BY u.name ORDER BY u.name`, nil)
+	r.NoError(err)
+	<-call.Done()
+	r.NoError(call.Err())
+
+	res, err := call.GetResult()
+	r.NoError(err)
+	rows, err := res.Rows(0, res.Len())
+	r.NoError(err)
+	r.Equal([]core.Row{{"alice", "alice", 15.0}, {"bob", "bob", 1.0}}, rows)
+	r.Equal([]string{"o: 3 rows from orders", "u: 2 rows from call " + string(history.GetID())}, res.Meta().Notices)
+
+	// tables are created again on every call
+	call, err = engine.Federate([]*core.FederatedTable{
+		{Name: "o", Connection: orders, Query: "SELECT amount FROM orders WHERE user_id = 2"},
+	}, "SELECT * FROM o", nil)
+	r.NoError(err)
+	<-call.Done()
+	r.NoError(call.Err())
+
+	res, err = call.GetResult()
+	r.NoError(err)
+	r.Equal(1, res.Len())
+
+	// only embedded databases are engines
+	remote, err := core.NewConnection(&core.ConnectionParams{Type: "postgres"}, mock.NewAdapter(nil))
+	r.NoError(err)
+	_, err = remote.Federate([]*core.FederatedTable{{Name: "o", Connection: orders, Query: "SELECT 1"}}, "SELECT 1", nil)
+	r.ErrorIs(err, core.ErrFederationNotSupported)
+}
+
+func TestSQLite_ExecuteSample(t *testing.T) {
+	r := require.New(t)
+
+	c, err := NewConnection(&core.ConnectionParams{Type: "sqlite", URL: filepath.Join(t.TempDir(), "sample.db")})
+	r.NoError(err)
+	t.Cleanup(c.Close)
+
+	for _, query := range []string{
+		"CREATE TABLE numbers (n INTEGER)",
+		"WITH RECURSIVE seq(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM seq WHERE n < 100) INSERT INTO numbers SELECT n FROM seq",
+	} {
+		call := c.Execute(query, nil)
+		<-call.Done()
+		r.NoError(call.Err())
+	}
+
+	call, err := c.ExecuteSample("SELECT n FROM numbers", &core.SampleOptions{Rows: 10}, false, nil)
+	r.NoError(err)
+	<-call.Done()
+	r.NoError(call.Err())
+
+	res, err := call.GetResult()
+	r.NoError(err)
+	r.Equal(10, res.Len())
+	r.Equal("10 random rows (ORDER BY RANDOM())", res.Meta().Sampling)
+	// the call keeps the original query
+	r.Equal("SELECT n FROM numbers", call.GetQuery())
+
+	_, err = c.ExecuteSample("DELETE FROM numbers", nil, false, nil)
+	r.ErrorIs(err, core.ErrSamplingNotSupported)
+}
+
+func TestSQLite_EstimateCost(t *testing.T) {
+	r := require.New(t)
+
+	c, err := NewConnection(&core.ConnectionParams{
+		Type: "sqlite",
+		URL:  filepath.Join(t.TempDir(), "cost.db"),
+		Cost: core.CostOptions{MaxCost: 1},
+	})
+	r.NoError(err)
+	t.Cleanup(c.Close)
+
+	_, err = c.EstimateCost("SELECT 1")
+	r.ErrorIs(err, core.ErrCostEstimationNotSupported)
+
+	// queries of databases which can't estimate their cost aren't refused
+	call := c.Execute("SELECT 1", nil)
+	<-call.Done()
+	r.NoError(call.Err())
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	_ "github.com/microsoft/go-mssqldb"
+	"github.com/microsoft/go-mssqldb/azuread"
 	_ "github.com/microsoft/go-mssqldb/integratedauth/krb5"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
@@ -25,13 +26,22 @@ var _ core.Adapter = (*SQLServer)(nil)
 
 type SQLServer struct{}
 
+// Connect connects to the server with the url (see go-mssqldb for its
+// parameters). Windows and Active Directory logins are supported:
+//   - integrated security: a "domain\user" user logs in with NTLM, without a
+//     user on windows the current user logs in (SSPI) and "authenticator=krb5"
+//     uses kerberos (with "krb5-configfile" and "krb5-keytabfile" or
+//     "krb5-credcachefile" parameters).
+//   - azure active directory: "fedauth" parameter selects the workflow (e.g.
+//     "ActiveDirectoryDefault", "ActiveDirectoryPassword" or
+//     "ActiveDirectoryServicePrincipal").
 func (s *SQLServer) Connect(url string) (core.Driver, error) {
 	u, err := nurl.Parse(url)
 	if err != nil {
 		return nil, fmt.Errorf("could not parse db connection string: %w: ", err)
 	}
 
-	db, err := sql.Open("sqlserver", u.String())
+	db, err := sql.Open(sqlServerDriverName(u), u.String())
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to sqlserver database: %v", err)
 	}
@@ -53,11 +63,24 @@ func (s *SQLServer) Connect(url string) (core.Driver, error) {
 
 					return id
 				}),
+			builders.WithSavepointSyntax(builders.SavepointSyntax{
+				Create:     "SAVE TRANSACTION %s",
+				RollbackTo: "ROLLBACK TRANSACTION %s",
+			}),
 		),
 		url: u,
 	}, nil
 }
 
+// sqlServerDriverName returns the name of the sql driver which logs in with
+// the url: urls with "fedauth" log in with azure active directory.
+func sqlServerDriverName(u *nurl.URL) string {
+	if u.Query().Get("fedauth") != "" {
+		return azuread.DriverName
+	}
+	return "sqlserver"
+}
+
 func (*SQLServer) GetHelpers(opts *core.TableOptions) map[string]string {
 	columnSummary := fmt.Sprintf(`
       SELECT c.column_name + ' (' +
@@ -3,16 +3,48 @@ package adapters
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io"
 	nurl "net/url"
+	"strconv"
+	"strings"
+
+	mssql "github.com/microsoft/go-mssqldb"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 )
 
 var (
-	_ core.Driver           = (*sqlServerDriver)(nil)
-	_ core.DatabaseSwitcher = (*sqlServerDriver)(nil)
+	_ core.Driver                    = (*sqlServerDriver)(nil)
+	_ core.DatabaseSwitcher          = (*sqlServerDriver)(nil)
+	_ core.LimitDialect              = (*sqlServerDriver)(nil)
+	_ core.ProcedureCaller           = (*sqlServerDriver)(nil)
+	_ core.ActivityMonitor           = (*sqlServerDriver)(nil)
+	_ core.TransientErrorClassifier  = (*sqlServerDriver)(nil)
+	_ core.ConnectionErrorClassifier = (*sqlServerDriver)(nil)
+	_ core.Transactor                = (*sqlServerDriver)(nil)
+	_ core.Importer                  = (*sqlServerDriver)(nil)
+	_ core.StatementDialectProvider  = (*sqlServerDriver)(nil)
+	_ core.DialectOptionsProvider    = (*sqlServerDriver)(nil)
+	_ core.IdentifierQuoter          = (*sqlServerDriver)(nil)
+	_ core.BlobWriter                = (*sqlServerDriver)(nil)
+	_ core.TableInspector            = (*sqlServerDriver)(nil)
+	_ core.TriggerLister             = (*sqlServerDriver)(nil)
+	_ core.TableStatsProvider        = (*sqlServerDriver)(nil)
+	_ core.ObjectSearcher            = (*sqlServerDriver)(nil)
+	_ core.GrantLister               = (*sqlServerDriver)(nil)
+	_ core.ServerInfoProvider        = (*sqlServerDriver)(nil)
+	_ core.SystemObjectClassifier    = (*sqlServerDriver)(nil)
+	_ core.DependencyLister          = (*sqlServerDriver)(nil)
+	_ core.Commenter                 = (*sqlServerDriver)(nil)
+	_ core.ForeignServerLister       = (*sqlServerDriver)(nil)
+	_ core.KeywordProvider           = (*sqlServerDriver)(nil)
+	_ core.SignatureProvider         = (*sqlServerDriver)(nil)
+	_ core.ErrorClassifier           = (*sqlServerDriver)(nil)
+	_ core.PoolStatsProvider         = (*sqlServerDriver)(nil)
+	_ core.SampleDialect             = (*sqlServerDriver)(nil)
 )
 
 type sqlServerDriver struct {
@@ -21,25 +53,60 @@ type sqlServerDriver struct {
 }
 
 func (c *sqlServerDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
-	// run query, fallback to affected rows
-	return c.c.QueryUntilNotEmpty(ctx, query, "select @@ROWCOUNT as 'Rows Affected'")
+	// run query and collect PRINT messages, fallback to affected rows
+	return c.c.QueryWithMessages(ctx, query)
 }
 
 func (c *sqlServerDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
 	return c.c.ColumnsFromQuery(`
 		SELECT
-			column_name,
-			data_type
-		FROM information_schema.columns
-			WHERE table_name='%s' AND
-			table_schema = '%s'`,
+			col.column_name,
+			col.data_type,
+			col.is_nullable,
+			col.column_default,
+			CASE WHEN EXISTS (
+				SELECT 1
+				FROM information_schema.table_constraints tc
+				JOIN information_schema.key_column_usage kcu
+					ON tc.constraint_name = kcu.constraint_name AND
+					tc.table_schema = kcu.table_schema
+				WHERE
+					tc.constraint_type = 'PRIMARY KEY' AND
+					tc.table_schema = col.table_schema AND
+					tc.table_name = col.table_name AND
+					kcu.column_name = col.column_name
+			) THEN 1 ELSE 0 END,
+			(
+				SELECT CAST(ep.value AS nvarchar(max))
+				FROM sys.extended_properties ep
+				WHERE
+					ep.class = 1 AND
+					ep.name = 'MS_Description' AND
+					ep.major_id = OBJECT_ID(QUOTENAME(col.table_schema) + '.' + QUOTENAME(col.table_name)) AND
+					ep.minor_id = COLUMNPROPERTY(ep.major_id, col.column_name, 'ColumnId')
+			)
+		FROM information_schema.columns col
+			WHERE col.table_name='%s' AND
+			col.table_schema = '%s'
+		ORDER BY col.ordinal_position`,
 		opts.Table,
 		opts.Schema,
 	)
 }
 
 func (c *sqlServerDriver) Structure() ([]*core.Structure, error) {
-	query := `SELECT table_schema, table_name FROM INFORMATION_SCHEMA.TABLES`
+	query := `
+		SELECT s.name, o.name, CASE o.type WHEN 'V' THEN 'VIEW' ELSE 'TABLE' END, CAST(ep.value AS nvarchar(max))
+		FROM sys.objects o
+		JOIN sys.schemas s ON s.schema_id = o.schema_id
+		LEFT JOIN sys.extended_properties ep ON
+			ep.class = 1 AND
+			ep.name = 'MS_Description' AND
+			ep.major_id = o.object_id AND
+			ep.minor_id = 0
+		WHERE o.type IN ('U', 'V') AND o.is_ms_shipped = 0
+		ORDER BY s.name, o.name
+		`
 
 	rows, err := c.Query(context.TODO(), query)
 	if err != nil {
@@ -54,14 +121,16 @@ func (c *sqlServerDriver) Structure() ([]*core.Structure, error) {
 			return nil, err
 		}
 
-		// We know for a fact there are 2 string fields (see query above)
+		// We know for a fact there are 3 string fields and a nullable comment (see query above)
 		schema := row[0].(string)
 		table := row[1].(string)
+		comment, _ := row[3].(string)
 
 		children[schema] = append(children[schema], &core.Structure{
-			Name:   table,
-			Schema: schema,
-			Type:   core.StructureTypeTable,
+			Name:    table,
+			Schema:  schema,
+			Type:    getPGStructureType(row[2].(string)),
+			Comment: comment,
 		})
 
 	}
@@ -80,10 +149,395 @@ func (c *sqlServerDriver) Structure() ([]*core.Structure, error) {
 	return layout, nil
 }
 
+func (c *sqlServerDriver) IsSystem(node *core.Structure) bool {
+	if node.Type != core.StructureTypeNone {
+		return false
+	}
+	return strings.EqualFold(node.Schema, "sys") || strings.EqualFold(node.Schema, "INFORMATION_SCHEMA")
+}
+
+// SetComment stores comments as MS_Description extended properties, which
+// are shown as descriptions by SQL Server tools.
+func (c *sqlServerDriver) SetComment(ctx context.Context, opts *core.TableOptions, column string, comment string) error {
+	level1 := "TABLE"
+	if opts.Materialization == core.StructureTypeView {
+		level1 = "VIEW"
+	}
+
+	return c.c.ExecArgs(ctx, fmt.Sprintf(`
+		DECLARE @level2type varchar(6) = CASE WHEN @p3 = '' THEN NULL ELSE 'COLUMN' END;
+		DECLARE @level2name sysname = NULLIF(@p3, '');
+		IF EXISTS (
+			SELECT 1
+			FROM sys.extended_properties
+			WHERE
+				class = 1 AND
+				name = 'MS_Description' AND
+				major_id = OBJECT_ID(QUOTENAME(@p1) + '.' + QUOTENAME(@p2)) AND
+				minor_id = COALESCE(COLUMNPROPERTY(OBJECT_ID(QUOTENAME(@p1) + '.' + QUOTENAME(@p2)), @p3, 'ColumnId'), 0)
+		)
+		BEGIN
+			IF @p4 = ''
+				EXEC sp_dropextendedproperty 'MS_Description', 'SCHEMA', @p1, '%[1]s', @p2, @level2type, @level2name;
+			ELSE
+				EXEC sp_updateextendedproperty 'MS_Description', @p4, 'SCHEMA', @p1, '%[1]s', @p2, @level2type, @level2name;
+		END
+		ELSE IF @p4 <> ''
+			EXEC sp_addextendedproperty 'MS_Description', @p4, 'SCHEMA', @p1, '%[1]s', @p2, @level2type, @level2name;
+		`, level1), opts.Schema, opts.Table, column, comment)
+}
+
+func (c *sqlServerDriver) SearchObjects(ctx context.Context, pattern string, limit int) ([]*core.ObjectMatch, error) {
+	return c.c.ObjectMatchesFromQuery(ctx, `
+		SELECT TOP (@p2) * FROM (
+			SELECT table_schema AS s, '' AS t, table_name AS n, CASE table_type WHEN 'VIEW' THEN 'view' ELSE 'table' END AS k
+			FROM information_schema.tables
+			WHERE table_name LIKE @p1
+			UNION ALL
+			SELECT routine_schema, '', routine_name, LOWER(routine_type)
+			FROM information_schema.routines
+			WHERE routine_name LIKE @p1
+			UNION ALL
+			SELECT table_schema, table_name, column_name, 'column'
+			FROM information_schema.columns
+			WHERE column_name LIKE @p1
+		) m
+		`, "%"+pattern+"%", limit)
+}
+
+func (c *sqlServerDriver) Indexes(ctx context.Context, opts *core.TableOptions) ([]*core.Index, error) {
+	return c.c.IndexesFromQuery(ctx, `
+		SELECT i.name, c.name, i.is_unique, i.is_primary_key
+		FROM sys.indexes i
+		JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+		JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+		WHERE
+			i.object_id = OBJECT_ID(QUOTENAME(@p1) + '.' + QUOTENAME(@p2)) AND
+			ic.is_included_column = 0
+		ORDER BY i.name, ic.key_ordinal
+		`, opts.Schema, opts.Table)
+}
+
+func (c *sqlServerDriver) Constraints(ctx context.Context, opts *core.TableOptions) ([]*core.Constraint, error) {
+	return c.c.ConstraintsFromQuery(ctx, `
+		SELECT
+			tc.constraint_name,
+			tc.constraint_type,
+			kcu.column_name,
+			cc.check_clause,
+			rk.table_schema,
+			rk.table_name,
+			rk.column_name
+		FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+		LEFT JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE kcu
+			ON kcu.constraint_schema = tc.constraint_schema AND kcu.constraint_name = tc.constraint_name
+		LEFT JOIN INFORMATION_SCHEMA.CHECK_CONSTRAINTS cc
+			ON cc.constraint_schema = tc.constraint_schema AND cc.constraint_name = tc.constraint_name
+		LEFT JOIN INFORMATION_SCHEMA.REFERENTIAL_CONSTRAINTS rc
+			ON rc.constraint_schema = tc.constraint_schema AND rc.constraint_name = tc.constraint_name
+		LEFT JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE rk
+			ON rk.constraint_schema = rc.unique_constraint_schema AND
+			rk.constraint_name = rc.unique_constraint_name AND
+			rk.ordinal_position = kcu.ordinal_position
+		WHERE tc.table_schema = @p1 AND tc.table_name = @p2
+		ORDER BY tc.constraint_name, kcu.ordinal_position
+		`, opts.Schema, opts.Table)
+}
+
+func (c *sqlServerDriver) Triggers(ctx context.Context, opts *core.TableOptions) ([]*core.Trigger, error) {
+	return c.c.TriggersFromQuery(ctx, `
+		SELECT
+			tr.name,
+			te.type_desc,
+			CASE WHEN tr.is_instead_of_trigger = 1 THEN 'INSTEAD OF' ELSE 'AFTER' END,
+			NULL
+		FROM sys.triggers tr
+		JOIN sys.trigger_events te ON te.object_id = tr.object_id
+		WHERE tr.parent_id = OBJECT_ID(QUOTENAME(@p1) + '.' + QUOTENAME(@p2))
+		ORDER BY tr.name, te.type_desc
+		`, opts.Schema, opts.Table)
+}
+
+func (c *sqlServerDriver) Roles(ctx context.Context) ([]*core.Role, error) {
+	return c.c.RolesFromQuery(ctx, `
+		SELECT
+			p.name,
+			CASE WHEN p.name = 'dbo' THEN 1 ELSE 0 END,
+			CASE WHEN p.type = 'R' THEN 0 ELSE 1 END,
+			r.name
+		FROM sys.database_principals p
+		LEFT JOIN sys.database_role_members rm ON rm.member_principal_id = p.principal_id
+		LEFT JOIN sys.database_principals r ON r.principal_id = rm.role_principal_id
+		WHERE
+			p.type IN ('S', 'U', 'G', 'R', 'E', 'X') AND
+			p.is_fixed_role = 0 AND
+			p.name NOT IN ('sys', 'INFORMATION_SCHEMA')
+		ORDER BY p.name, r.name
+		`)
+}
+
+func (c *sqlServerDriver) Grants(ctx context.Context, opts *core.TableOptions) ([]*core.Grant, error) {
+	// denied permissions are listed as well, since they take precedence
+	return c.c.GrantsFromQuery(ctx, `
+		SELECT
+			grantee.name,
+			CASE pe.state WHEN 'D' THEN 'DENY ' ELSE '' END + pe.permission_name,
+			CASE WHEN pe.state = 'W' THEN 1 ELSE 0 END,
+			grantor.name
+		FROM sys.database_permissions pe
+		JOIN sys.database_principals grantee ON grantee.principal_id = pe.grantee_principal_id
+		JOIN sys.database_principals grantor ON grantor.principal_id = pe.grantor_principal_id
+		WHERE
+			pe.class = 1 AND
+			pe.major_id = OBJECT_ID(QUOTENAME(@p1) + '.' + QUOTENAME(@p2))
+		ORDER BY grantee.name, pe.permission_name
+		`, opts.Schema, opts.Table)
+}
+
+// sqlServerObjectType converts sys.objects types to structure types.
+const sqlServerObjectType = `
+	CASE o.type
+		WHEN 'U' THEN 'table'
+		WHEN 'V' THEN 'view'
+		WHEN 'P' THEN 'procedure'
+		WHEN 'FN' THEN 'function'
+		WHEN 'IF' THEN 'function'
+		WHEN 'TF' THEN 'function'
+		WHEN 'TR' THEN 'trigger'
+		ELSE 'table'
+	END`
+
+// DependsOn lists objects referenced by a view, function or procedure and
+// tables referenced by foreign keys of a table.
+func (c *sqlServerDriver) DependsOn(ctx context.Context, opts *core.TableOptions) ([]*core.DependencyNode, error) {
+	return c.c.DependenciesFromQuery(ctx, `
+		SELECT DISTINCT SCHEMA_NAME(o.schema_id), o.name, `+sqlServerObjectType+`
+		FROM sys.sql_expression_dependencies d
+		JOIN sys.objects o ON o.object_id = d.referenced_id
+		WHERE d.referencing_id = OBJECT_ID(QUOTENAME(@p1) + '.' + QUOTENAME(@p2))
+		AND d.referenced_id <> d.referencing_id
+		UNION
+		SELECT DISTINCT SCHEMA_NAME(o.schema_id), o.name, 'table'
+		FROM sys.foreign_keys fk
+		JOIN sys.objects o ON o.object_id = fk.referenced_object_id
+		WHERE fk.parent_object_id = OBJECT_ID(QUOTENAME(@p1) + '.' + QUOTENAME(@p2))
+		AND fk.referenced_object_id <> fk.parent_object_id
+		ORDER BY 1, 2
+		`, opts.Schema, opts.Table)
+}
+
+// Signatures lists the function or procedure with parameters from
+// sys.parameters. Output parameters of procedures are also inputs.
+func (c *sqlServerDriver) Signatures(ctx context.Context, opts *core.TableOptions) ([]*core.Signature, error) {
+	return c.c.SignaturesFromQuery(ctx, `
+		SELECT
+			s.name,
+			o.name,
+			'',
+			COALESCE(p.parameter_id, 0),
+			COALESCE(p.name, ''),
+			COALESCE(TYPE_NAME(p.user_type_id), ''),
+			CASE WHEN p.is_output = 1 THEN 'inout' ELSE 'in' END,
+			COALESCE(
+				(SELECT TYPE_NAME(r.user_type_id) FROM sys.parameters r WHERE r.object_id = o.object_id AND r.parameter_id = 0),
+				CASE WHEN o.type IN ('IF', 'TF', 'FT') THEN 'TABLE' ELSE '' END
+			),
+			COALESCE(CAST(ep.value AS nvarchar(max)), '')
+		FROM sys.objects o
+		JOIN sys.schemas s ON s.schema_id = o.schema_id
+		LEFT JOIN sys.parameters p ON p.object_id = o.object_id AND p.parameter_id > 0
+		LEFT JOIN sys.extended_properties ep
+			ON ep.class = 1 AND ep.major_id = o.object_id AND ep.minor_id = 0 AND ep.name = 'MS_Description'
+		WHERE o.type IN ('FN', 'IF', 'TF', 'FS', 'FT', 'P', 'PC') AND o.name = @p1 AND (@p2 = '' OR s.name = @p2)
+		ORDER BY s.name, p.parameter_id
+		`, opts.Table, opts.Schema)
+}
+
+// Dependents lists views, functions, procedures and triggers referencing an
+// object and tables referencing a table with foreign keys.
+func (c *sqlServerDriver) Dependents(ctx context.Context, opts *core.TableOptions) ([]*core.DependencyNode, error) {
+	return c.c.DependenciesFromQuery(ctx, `
+		SELECT DISTINCT SCHEMA_NAME(o.schema_id), o.name, `+sqlServerObjectType+`
+		FROM sys.sql_expression_dependencies d
+		JOIN sys.objects o ON o.object_id = d.referencing_id
+		WHERE d.referenced_id = OBJECT_ID(QUOTENAME(@p1) + '.' + QUOTENAME(@p2))
+		AND d.referenced_id <> d.referencing_id
+		UNION
+		SELECT DISTINCT SCHEMA_NAME(o.schema_id), o.name, 'table'
+		FROM sys.foreign_keys fk
+		JOIN sys.objects o ON o.object_id = fk.parent_object_id
+		WHERE fk.referenced_object_id = OBJECT_ID(QUOTENAME(@p1) + '.' + QUOTENAME(@p2))
+		AND fk.referenced_object_id <> fk.parent_object_id
+		ORDER BY 1, 2
+		`, opts.Schema, opts.Table)
+}
+
+func (c *sqlServerDriver) ForeignServers(ctx context.Context) ([]*core.ForeignServer, error) {
+	return c.c.ForeignServersFromQuery(ctx, `
+		SELECT name, product + CASE WHEN provider <> '' THEN ' (' + provider + ')' ELSE '' END, data_source
+		FROM sys.servers
+		WHERE is_linked = 1
+		ORDER BY name
+		`)
+}
+
+// ForeignObjects lists tables and views of a linked server. The listing is
+// retrieved from the remote server, so it fails if the server is not reachable.
+func (c *sqlServerDriver) ForeignObjects(ctx context.Context, server string) ([]*core.Structure, error) {
+	return c.c.ForeignObjectsFromQuery(ctx, `
+		SET NOCOUNT ON;
+		DECLARE @tables TABLE (
+			table_cat sysname NULL,
+			table_schem sysname NULL,
+			table_name sysname NOT NULL,
+			table_type varchar(32) NULL,
+			remarks varchar(254) NULL
+		);
+		INSERT INTO @tables EXEC sp_tables_ex @table_server = @p1;
+		SELECT COALESCE(table_schem, ''), table_name, table_type
+		FROM @tables
+		WHERE table_type IN ('TABLE', 'VIEW')
+		ORDER BY table_schem, table_name
+		`, server)
+}
+
+func (c *sqlServerDriver) TableStats(ctx context.Context) ([]*core.TableStats, error) {
+	// row counts are taken from the heap or the clustered index only,
+	// size includes all indexes (pages are 8 KB)
+	return c.c.TableStatsFromQuery(ctx, `
+		SELECT
+			s.name,
+			t.name,
+			SUM(CASE WHEN ps.index_id IN (0, 1) THEN ps.row_count ELSE 0 END),
+			SUM(ps.reserved_page_count) * 8192
+		FROM sys.tables t
+		JOIN sys.schemas s ON t.schema_id = s.schema_id
+		JOIN sys.dm_db_partition_stats ps ON ps.object_id = t.object_id
+		GROUP BY s.name, t.name
+		`)
+}
+
+// ServerInfo reports uptime from the creation time of tempdb, which is
+// recreated on every start and, unlike sys.dm_os_sys_info, visible to all users.
+func (c *sqlServerDriver) ServerInfo(ctx context.Context) (*core.ServerInfo, error) {
+	return c.c.ServerInfoFromQuery(ctx, `
+		SELECT 'version', NULL, CAST(@@VERSION AS NVARCHAR(MAX))
+		UNION ALL
+		SELECT 'uptime', NULL, CAST(DATEDIFF(SECOND, create_date, GETDATE()) AS NVARCHAR(MAX))
+		FROM sys.databases WHERE name = 'tempdb'
+		UNION ALL
+		SELECT 'setting', 'edition', CAST(SERVERPROPERTY('Edition') AS NVARCHAR(MAX))
+		UNION ALL
+		SELECT 'setting', 'product_level', CAST(SERVERPROPERTY('ProductLevel') AS NVARCHAR(MAX))
+		UNION ALL
+		SELECT 'setting', 'collation', CAST(SERVERPROPERTY('Collation') AS NVARCHAR(MAX))
+		UNION ALL
+		SELECT 'setting', 'max_connections', CAST(@@MAX_CONNECTIONS AS NVARCHAR(MAX))
+		`)
+}
+
+func (c *sqlServerDriver) Routines() ([]*core.Structure, error) {
+	return c.c.RoutinesFromQuery(`SELECT routine_schema, routine_name, routine_type FROM INFORMATION_SCHEMA.ROUTINES`)
+}
+
+// sqlServerTableDDLQuery reconstructs a simplified CREATE TABLE statement
+// (columns, nullability and defaults) of a table.
+const sqlServerTableDDLQuery = `
+	SELECT
+		'CREATE TABLE ' + QUOTENAME(@p1) + '.' + QUOTENAME(@p2) + ' (' + CHAR(10) +
+		STRING_AGG(CAST(
+			'    ' + QUOTENAME(column_name) + ' ' + data_type +
+			CASE
+				WHEN data_type IN ('xml', 'text', 'ntext', 'image') THEN ''
+				WHEN character_maximum_length = -1 THEN '(max)'
+				WHEN character_maximum_length IS NOT NULL THEN '(' + CAST(character_maximum_length AS varchar(10)) + ')'
+				WHEN data_type IN ('decimal', 'numeric') THEN '(' + CAST(numeric_precision AS varchar(10)) + ', ' + CAST(numeric_scale AS varchar(10)) + ')'
+				ELSE ''
+			END +
+			CASE WHEN is_nullable = 'NO' THEN ' NOT NULL' ELSE '' END +
+			COALESCE(' DEFAULT ' + column_default, '')
+		AS nvarchar(max)), ',' + CHAR(10)) WITHIN GROUP (ORDER BY ordinal_position) +
+		CHAR(10) + ');'
+	FROM INFORMATION_SCHEMA.COLUMNS
+	WHERE table_schema = @p1 AND table_name = @p2
+	`
+
+func (c *sqlServerDriver) Definition(ctx context.Context, opts *core.TableOptions) (string, error) {
+	switch opts.Materialization {
+	case core.StructureTypeFunction, core.StructureTypeProcedure, core.StructureTypeTrigger, core.StructureTypeView:
+		return c.c.DefinitionFromQuery(ctx, 0,
+			"SELECT OBJECT_DEFINITION(OBJECT_ID(QUOTENAME(@p1) + '.' + QUOTENAME(@p2)))",
+			opts.Schema, opts.Table)
+	case core.StructureTypeTable:
+		return c.c.DefinitionFromQuery(ctx, 0, sqlServerTableDDLQuery, opts.Schema, opts.Table)
+	case core.StructureTypeSequence:
+		return c.c.DefinitionFromQuery(ctx, 0, `
+			SELECT
+				'CREATE SEQUENCE ' + QUOTENAME(@p1) + '.' + QUOTENAME(@p2) +
+				' AS ' + TYPE_NAME(system_type_id) +
+				' START WITH ' + CAST(start_value AS nvarchar(40)) +
+				' INCREMENT BY ' + CAST(increment AS nvarchar(40)) +
+				' MINVALUE ' + CAST(minimum_value AS nvarchar(40)) +
+				' MAXVALUE ' + CAST(maximum_value AS nvarchar(40)) +
+				CASE WHEN is_cycling = 1 THEN ' CYCLE' ELSE ' NO CYCLE' END + ';'
+			FROM sys.sequences
+			WHERE object_id = OBJECT_ID(QUOTENAME(@p1) + '.' + QUOTENAME(@p2))
+			`, opts.Schema, opts.Table)
+	default:
+		return "", fmt.Errorf("%w: %s", core.ErrDefinitionNotSupported, opts.Materialization)
+	}
+}
+
 func (c *sqlServerDriver) Close() {
 	c.c.Close()
 }
 
+func (c *sqlServerDriver) PoolStats() *core.PoolStats {
+	return c.c.PoolStats()
+}
+
+// CallProcedure calls the procedure with EXEC and binds output parameters with OUTPUT.
+func (c *sqlServerDriver) CallProcedure(ctx context.Context, name string, params []*core.ProcedureParam) (core.ResultStream, error) {
+	if err := checkBindNames(params); err != nil {
+		return nil, err
+	}
+
+	assignments := make([]string, len(params))
+	args := make([]any, len(params))
+	var outputs []*builders.ProcedureOutput
+	for i, p := range params {
+		if !p.IsOutput() {
+			assignments[i] = fmt.Sprintf("@%s = @%s", p.Name, p.Name)
+			args[i] = sql.Named(p.Name, p.Value)
+			continue
+		}
+
+		out := builders.NewProcedureOutput(p)
+		outputs = append(outputs, out)
+		assignments[i] = fmt.Sprintf("@%s = @%s OUTPUT", p.Name, p.Name)
+		args[i] = sql.Named(p.Name, sql.Out{Dest: out.Dest, In: p.Mode == core.ParamModeInOut})
+	}
+
+	query := fmt.Sprintf("EXEC %s %s", procedureName(core.NewDialect(c), name), strings.Join(assignments, ", "))
+
+	return c.c.QueryWithOutputs(ctx, query, args, outputs)
+}
+
+func (c *sqlServerDriver) LimitSyntax() core.LimitSyntax {
+	return core.LimitSyntaxTop
+}
+
+func (c *sqlServerDriver) SampleSyntax() core.SampleSyntax {
+	return core.SampleSyntax{
+		TableSample: "TABLESAMPLE (%s PERCENT)",
+		Random:      "NEWID()",
+	}
+}
+
+func (c *sqlServerDriver) Keywords() []string {
+	return []string{"TOP", "OFFSET", "FETCH NEXT", "ROWS ONLY", "OUTPUT", "MERGE", "CROSS APPLY", "OUTER APPLY", "NOLOCK", "DECLARE", "EXEC", "GO"}
+}
+
 func (c *sqlServerDriver) ListDatabases() (current string, available []string, err error) {
 	query := `
 		SELECT DB_NAME(), name
@@ -124,3 +578,139 @@ func (c *sqlServerDriver) SelectDatabase(name string) error {
 
 	return nil
 }
+
+func (c *sqlServerDriver) Activity(ctx context.Context) (core.ResultStream, error) {
+	return c.c.Query(ctx, `
+		SELECT
+			s.session_id,
+			s.login_name,
+			s.host_name,
+			DB_NAME(r.database_id) AS database_name,
+			r.status,
+			r.command,
+			r.wait_type,
+			r.total_elapsed_time AS elapsed_ms,
+			t.text AS query
+		FROM sys.dm_exec_requests r
+		JOIN sys.dm_exec_sessions s ON r.session_id = s.session_id
+		CROSS APPLY sys.dm_exec_sql_text(r.sql_handle) t
+		WHERE s.is_user_process = 1
+			AND r.session_id <> @@SPID
+		ORDER BY r.total_elapsed_time DESC
+	`)
+}
+
+func (c *sqlServerDriver) KillSession(ctx context.Context, id string) error {
+	n, err := numericSessionID(id)
+	if err != nil {
+		return err
+	}
+	return c.c.ExecArgs(ctx, fmt.Sprintf("KILL %d", n))
+}
+
+// IsTransient reports deadlocks, lock timeouts and snapshot conflicts, which
+// roll back the statement.
+func (c *sqlServerDriver) IsTransient(err error) bool {
+	var msErr mssql.Error
+	if errors.As(err, &msErr) {
+		switch msErr.Number {
+		case 1205, // deadlock victim
+			1222, // lock request time out
+			3960: // snapshot isolation update conflict
+			return true
+		}
+		return false
+	}
+	return isUnsentError(err)
+}
+
+// IsConnectionError reports dropped connections.
+func (c *sqlServerDriver) IsConnectionError(err error) bool {
+	return isConnectionError(err)
+}
+
+func (c *sqlServerDriver) ClassifyError(err error) *core.QueryError {
+	var msErr mssql.Error
+	if !errors.As(err, &msErr) {
+		return nil
+	}
+
+	category := core.ErrorCategoryUnknown
+	switch msErr.Number {
+	case 18456, 18452: // login failed
+		category = core.ErrorCategoryAuth
+	case 229, 230, 262, 300: // permission denied
+		category = core.ErrorCategoryPermission
+	case 102, 105, 156, 170: // incorrect syntax
+		category = core.ErrorCategorySyntax
+	case 207, 208, 2812, 4121: // invalid column, object, procedure, function
+		category = core.ErrorCategoryNotFound
+	case 515, 547, 2601, 2627: // not null, foreign key or check, unique, primary key
+		category = core.ErrorCategoryConstraint
+	case 1222: // lock request time out
+		category = core.ErrorCategoryTimeout
+	}
+
+	return &core.QueryError{
+		Category: category,
+		Code:     strconv.Itoa(int(msErr.Number)),
+		Line:     int(msErr.LineNo),
+	}
+}
+
+func (c *sqlServerDriver) BeginTx(ctx context.Context) (core.Transaction, error) {
+	tx, err := c.c.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// ImportBatch imports rows with the bulk copy protocol.
+func (c *sqlServerDriver) ImportBatch(ctx context.Context, table string, columns []string, rows []core.Row) error {
+	return c.c.WithTx(ctx, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, mssql.CopyIn(table, mssql.BulkOptions{}, columns...))
+		if err != nil {
+			return fmt.Errorf("tx.PrepareContext: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, row := range rows {
+			_, err := stmt.ExecContext(ctx, row...)
+			if err != nil {
+				return err
+			}
+		}
+
+		// flush the buffered data
+		_, err = stmt.ExecContext(ctx)
+		return err
+	})
+}
+
+func (c *sqlServerDriver) StatementDialect() *core.StatementDialect {
+	return sqlServerStatementDialect
+}
+
+func (c *sqlServerDriver) DialectOptions() *core.DialectOptions {
+	return sqlServerDialectOptions
+}
+
+// QuoteIdentifier quotes the identifier with brackets, which don't depend on
+// the QUOTED_IDENTIFIER setting.
+func (c *sqlServerDriver) QuoteIdentifier(name string) string {
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+}
+
+func (c *sqlServerDriver) BlobDialect() *core.BlobDialect {
+	return &core.BlobDialect{
+		Placeholder: "@p1",
+		Append: func(column string) string {
+			return column + " + @p1"
+		},
+	}
+}
+
+func (c *sqlServerDriver) ExecChunks(ctx context.Context, first, next string, r io.Reader, chunkSize int) error {
+	return c.c.ExecChunks(ctx, first, next, r, chunkSize)
+}
@@ -0,0 +1,80 @@
+package adapters
+
+import (
+	"regexp"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// statement dialects of supported databases
+var (
+	postgresStatementDialect = &core.StatementDialect{
+		EscapeStrings:  true,
+		DollarQuotes:   true,
+		NestedComments: true,
+	}
+
+	mySQLStatementDialect = &core.StatementDialect{
+		BackslashEscapes:    true,
+		HashComments:        true,
+		BacktickIdentifiers: true,
+		DelimiterCommand:    true,
+	}
+
+	sqlServerStatementDialect = &core.StatementDialect{
+		BracketIdentifiers: true,
+		BatchSeparator:     "GO",
+		BlockStatement:     regexp.MustCompile(`(?is)^(create|alter|create\s+or\s+alter)\s+(procedure|proc|function|trigger)\b`),
+	}
+
+	oracleStatementDialect = &core.StatementDialect{
+		BatchSeparator: "/",
+		BlockStatement: regexp.MustCompile(`(?is)^(declare|begin|create\s+(or\s+replace\s+)?((editionable|noneditionable)\s+)?(procedure|function|package|trigger|type))\b`),
+	}
+
+	snowflakeStatementDialect = &core.StatementDialect{
+		BackslashEscapes: true,
+		DollarQuotes:     true,
+	}
+
+	sqliteStatementDialect = &core.StatementDialect{
+		BacktickIdentifiers: true,
+		BracketIdentifiers:  true,
+		BlockStatement:      regexp.MustCompile(`(?is)^create\s+(temp\s+|temporary\s+)?trigger\b`),
+	}
+
+	clickhouseStatementDialect = &core.StatementDialect{
+		BackslashEscapes:    true,
+		BacktickIdentifiers: true,
+	}
+
+	bigQueryStatementDialect = &core.StatementDialect{
+		BackslashEscapes:    true,
+		HashComments:        true,
+		BacktickIdentifiers: true,
+	}
+)
+
+// dialect options of databases whose literals, identifier case or bind
+// parameters differ from standard sql
+var (
+	postgresDialectOptions = &core.DialectOptions{
+		IdentifierCase: core.IdentifierCaseLower,
+		Placeholder:    core.PlaceholderDollar,
+	}
+
+	oracleDialectOptions = &core.DialectOptions{
+		IdentifierCase:  core.IdentifierCaseUpper,
+		NumericBooleans: true,
+		Placeholder:     core.PlaceholderColon,
+	}
+
+	sqlServerDialectOptions = &core.DialectOptions{
+		NumericBooleans: true,
+		Placeholder:     core.PlaceholderAtP,
+	}
+
+	athenaDialectOptions = &core.DialectOptions{
+		IdentifierCase: core.IdentifierCaseLower,
+	}
+)
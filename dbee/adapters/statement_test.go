@@ -0,0 +1,86 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestDialects(t *testing.T) {
+	testCases := []struct {
+		name        string
+		driver      core.Driver
+		table       string
+		literal     string
+		boolean     string
+		placeholder string
+		limited     string
+	}{
+		{
+			name:        "postgres",
+			driver:      &postgresDriver{},
+			table:       `"public"."users"`,
+			literal:     `'it''s C:\dir'`,
+			boolean:     "TRUE",
+			placeholder: "$2",
+			limited:     "SELECT * FROM t\nLIMIT 5",
+		},
+		{
+			name:        "mysql",
+			driver:      &mySQLDriver{},
+			table:       "`PUBLIC`.`Users`",
+			literal:     `'it\'s C:\\dir'`,
+			boolean:     "TRUE",
+			placeholder: "?",
+			limited:     "SELECT * FROM t\nLIMIT 5",
+		},
+		{
+			name:        "sqlserver",
+			driver:      &sqlServerDriver{},
+			table:       "[PUBLIC].[Users]",
+			literal:     `'it''s C:\dir'`,
+			boolean:     "1",
+			placeholder: "@p2",
+			limited:     "SELECT TOP 5 * FROM t",
+		},
+		{
+			name:        "oracle",
+			driver:      &oracleDriver{},
+			table:       `"PUBLIC"."USERS"`,
+			literal:     `'it''s C:\dir'`,
+			boolean:     "1",
+			placeholder: ":2",
+			limited:     "SELECT * FROM t\nFETCH FIRST 5 ROWS ONLY",
+		},
+		{
+			name:        "bigquery",
+			driver:      &bigQueryDriver{},
+			table:       "`PUBLIC`.`Users`",
+			literal:     `'it\'s C:\\dir'`,
+			boolean:     "TRUE",
+			placeholder: "?",
+			limited:     "SELECT * FROM t\nLIMIT 5",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			d := core.NewDialect(tc.driver)
+
+			table, ok := d.QueryTable("select * from PUBLIC.Users")
+			r.True(ok)
+			r.Equal(tc.table, table)
+			r.Equal(tc.literal, d.Literal(`it's C:\dir`))
+			r.Equal(tc.boolean, d.Literal(true))
+			r.Equal(tc.placeholder, d.Placeholder(2))
+
+			limited, ok := d.InjectLimit("SELECT * FROM t", 5)
+			r.True(ok)
+			r.Equal(tc.limited, limited)
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var (
+	_ core.Driver            = (*trinoDriver)(nil)
+	_ core.StructureLoader   = (*trinoDriver)(nil)
+	_ core.PoolStatsProvider = (*trinoDriver)(nil)
+)
+
+type trinoDriver struct {
+	c *builders.Client
+	// catalog of the connection url (empty lists all catalogs)
+	catalog string
+}
+
+func (c *trinoDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	// trino doesn't accept the terminator
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+	return c.c.Query(ctx, query)
+}
+
+func (c *trinoDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	catalog, schema := splitTrinoSchema(opts.Schema)
+	if catalog == "" {
+		catalog = c.catalog
+	}
+
+	return c.c.ColumnsFromQuery(`
+		SELECT column_name, data_type, is_nullable, column_default
+		FROM `+trinoQuote(catalog)+`.information_schema.columns
+		WHERE table_schema = '%s' AND table_name = '%s'
+		ORDER BY ordinal_position
+		`, trinoEscape(schema), trinoEscape(opts.Table))
+}
+
+// Structure lists catalogs with their schemas and tables. If the url has a
+// catalog, only its schemas are listed.
+func (c *trinoDriver) Structure() ([]*core.Structure, error) {
+	ctx := context.TODO()
+
+	catalogs, err := c.StructureRoots(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, catalog := range catalogs {
+		catalog.Children, err = c.catalogTables(ctx, catalog.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return catalogs, nil
+}
+
+// StructureRoots lists catalogs without their schemas.
+func (c *trinoDriver) StructureRoots(ctx context.Context) ([]*core.Structure, error) {
+	if c.catalog != "" {
+		return []*core.Structure{{
+			Name:   c.catalog,
+			Schema: c.catalog,
+			Type:   core.StructureTypeNone,
+		}}, nil
+	}
+
+	rows, err := c.c.Query(ctx, "SHOW CATALOGS")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var catalogs []*core.Structure
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, err
+		}
+		if len(row) < 1 {
+			return nil, errors.New("could not retrieve structure: insufficient info")
+		}
+
+		name := fmt.Sprint(row[0])
+		catalogs = append(catalogs, &core.Structure{
+			Name:   name,
+			Schema: name,
+			Type:   core.StructureTypeNone,
+		})
+	}
+
+	return catalogs, nil
+}
+
+// StructureChildren lists schemas of a catalog or tables of a schema
+// (qualified by the catalog, e.g. "hive.sales").
+func (c *trinoDriver) StructureChildren(ctx context.Context, parent *core.Structure) ([]*core.Structure, error) {
+	catalog, schema := splitTrinoSchema(parent.Schema)
+	if catalog != "" {
+		return c.tables(ctx, catalog, fmt.Sprintf("WHERE table_schema = '%s'", trinoEscape(schema)))
+	}
+
+	rows, err := c.c.Query(ctx, fmt.Sprintf(`
+		SELECT schema_name
+		FROM %s.information_schema.schemata
+		WHERE schema_name <> 'information_schema'
+		ORDER BY schema_name`, trinoQuote(schema)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schemas []*core.Structure
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, err
+		}
+		if len(row) < 1 {
+			return nil, errors.New("could not retrieve structure: insufficient info")
+		}
+
+		schemas = append(schemas, &core.Structure{
+			Name:   fmt.Sprint(row[0]),
+			Schema: schema + "." + fmt.Sprint(row[0]),
+			Type:   core.StructureTypeNone,
+			Lazy:   true,
+		})
+	}
+
+	return schemas, nil
+}
+
+// catalogTables lists schemas of the catalog with their tables.
+func (c *trinoDriver) catalogTables(ctx context.Context, catalog string) ([]*core.Structure, error) {
+	tables, err := c.tables(ctx, catalog, "WHERE table_schema <> 'information_schema'")
+	if err != nil {
+		return nil, err
+	}
+
+	var schemas []*core.Structure
+	bySchema := make(map[string]*core.Structure)
+	for _, table := range tables {
+		schema, ok := bySchema[table.Schema]
+		if !ok {
+			_, name := splitTrinoSchema(table.Schema)
+			schema = &core.Structure{
+				Name:   name,
+				Schema: table.Schema,
+				Type:   core.StructureTypeNone,
+			}
+			bySchema[table.Schema] = schema
+			schemas = append(schemas, schema)
+		}
+		schema.Children = append(schema.Children, table)
+	}
+
+	return schemas, nil
+}
+
+// tables lists tables of the catalog which match the where clause.
+func (c *trinoDriver) tables(ctx context.Context, catalog, where string) ([]*core.Structure, error) {
+	rows, err := c.c.Query(ctx, fmt.Sprintf(`
+		SELECT table_schema, table_name, table_type
+		FROM %s.information_schema.tables
+		%s
+		ORDER BY table_schema, table_name`, trinoQuote(catalog), where))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []*core.Structure
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, err
+		}
+		if len(row) < 3 {
+			return nil, errors.New("could not retrieve structure: insufficient info")
+		}
+
+		tables = append(tables, &core.Structure{
+			Name:   fmt.Sprint(row[1]),
+			Schema: catalog + "." + fmt.Sprint(row[0]),
+			Type:   getPGStructureType(fmt.Sprint(row[2])),
+		})
+	}
+
+	return tables, nil
+}
+
+func (c *trinoDriver) Close() {
+	c.c.Close()
+}
+
+func (c *trinoDriver) PoolStats() *core.PoolStats {
+	return c.c.PoolStats()
+}
+
+// splitTrinoSchema splits the schema of a structure node to the catalog and
+// the schema (e.g. "hive.sales").
+func splitTrinoSchema(schema string) (catalog, name string) {
+	if cat, rest, ok := strings.Cut(schema, "."); ok {
+		return cat, rest
+	}
+	return "", schema
+}
+
+func trinoQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func trinoEscape(s string) string {
+	return strings.ReplaceAll(s, "'", "''")
+}
+
+// trinoQualifiedName returns the quoted name of the table, qualified by the
+// schema and the catalog (if any).
+func trinoQualifiedName(schema, table string) string {
+	var parts []string
+	catalog, schema := splitTrinoSchema(schema)
+	if catalog != "" {
+		parts = append(parts, trinoQuote(catalog))
+	}
+	if schema != "" {
+		parts = append(parts, trinoQuote(schema))
+	}
+	return strings.Join(append(parts, trinoQuote(table)), ".")
+}
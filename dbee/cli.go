@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/kndndrj/nvim-dbee/dbee/adapters"
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/format"
+	"github.com/kndndrj/nvim-dbee/dbee/handler"
+)
+
+// runQuery runs a query on a connection without the editor and writes the
+// results to stdout:
+//
+//	dbee query --conn prod --format csv "select ..."
+//
+// Connections are read from the same file as the default file source of
+// the plugin. Calls are added to the call log of the editor.
+func runQuery(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	connFlag := fs.String("conn", "", "ID or name of the connection.")
+	connectionsFlag := fs.String("connections", defaultConnectionsFile(), "File with connections (same as the plugin's file source).")
+	typeFlag := fs.String("type", "", "Type of the database, used with --url instead of --conn.")
+	urlFlag := fs.String("url", "", "URL of the database, used with --type instead of --conn.")
+	formatFlag := fs.String("format", "table", "Output format: table, csv or json.")
+	confirmFlag := fs.Bool("yes", false, "Run destructive statements on guarded connections.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	query := strings.Join(fs.Args(), " ")
+	if query == "" {
+		return errors.New("no query provided")
+	}
+
+	formatter, err := newFormatter(*formatFlag)
+	if err != nil {
+		return err
+	}
+
+	params := &core.ConnectionParams{Type: *typeFlag, URL: *urlFlag}
+	if *connFlag != "" {
+		params, err = findConnection(*connectionsFlag, *connFlag)
+		if err != nil {
+			return err
+		}
+	} else if params.Type == "" || params.URL == "" {
+		return errors.New("either --conn or --type and --url are required")
+	}
+
+	c, err := adapters.NewConnection(params)
+	if err != nil {
+		return fmt.Errorf("adapters.NewConnection: %w", err)
+	}
+	defer c.Close()
+
+	// the state of the call is updated after it's done, so the call is
+	// stored once it reaches the final state
+	finished := make(chan struct{})
+	var once sync.Once
+	onEvent := func(state core.CallState, _ *core.Call) {
+		switch state {
+		case core.CallStateArchived, core.CallStateArchiveFailed, core.CallStateExecutingFailed,
+			core.CallStateRetrievingFailed, core.CallStateCanceled:
+			once.Do(func() { close(finished) })
+		}
+	}
+
+	var call *core.Call
+	if *confirmFlag {
+		call = c.ExecuteConfirmed(query, onEvent)
+	} else {
+		call = c.Execute(query, onEvent)
+	}
+	<-call.Done()
+	<-finished
+
+	if params.ID != "" {
+		if err := handler.AppendCallLog(params.ID, call); err != nil {
+			fmt.Fprintf(os.Stderr, "could not store the call: %s\n", err)
+		}
+	}
+
+	if err := call.Err(); err != nil {
+		return err
+	}
+
+	for set := 0; set < call.ResultSetCount(); set++ {
+		res, err := call.GetResultSet(set)
+		if err != nil {
+			return fmt.Errorf("call.GetResultSet: %w", err)
+		}
+		out, err := res.Format(formatter, 0, res.Len())
+		if err != nil {
+			return fmt.Errorf("res.Format: %w", err)
+		}
+		if !bytes.HasSuffix(out, []byte("\n")) {
+			out = append(out, '\n')
+		}
+		if _, err := stdout.Write(out); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newFormatter returns the formatter of output format name.
+func newFormatter(name string) (core.Formatter, error) {
+	switch name {
+	case "table":
+		return handler.NewTableFormatter(), nil
+	case "csv":
+		return format.NewCSV(), nil
+	case "json":
+		return format.NewJSON(), nil
+	case "geojson":
+		return format.NewGeoJSON(), nil
+	}
+	return nil, fmt.Errorf("output format: %q is not supported", name)
+}
+
+// defaultConnectionsFile returns the file of the default file source of the
+// plugin (stdpath("state") .. "/dbee/persistence.json").
+func defaultConnectionsFile() string {
+	state := os.Getenv("XDG_STATE_HOME")
+	if state == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		state = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(state, "nvim", "dbee", "persistence.json")
+}
+
+// findConnection returns parameters of the connection with the provided id
+// or name from a file source.
+func findConnection(path, conn string) (*core.ConnectionParams, error) {
+	connections, err := readConnections(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, params := range connections {
+		if string(params.ID) == conn || params.Name == conn {
+			return params, nil
+		}
+	}
+	return nil, fmt.Errorf("no connection with id or name %q in %q", conn, path)
+}
+
+// readConnections returns all connections of a file source. Lines starting
+// with "//" are comments.
+func readConnections(path string) ([]*core.ConnectionParams, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("os.Open: %w", err)
+	}
+	defer file.Close()
+
+	var contents bytes.Buffer
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if !strings.HasPrefix(strings.TrimSpace(scanner.Text()), "//") {
+			contents.WriteString(scanner.Text() + "\n")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanner.Scan: %w", err)
+	}
+
+	var connections []*core.ConnectionParams
+	if err := json.Unmarshal(contents.Bytes(), &connections); err != nil {
+		return nil, fmt.Errorf("could not parse %q: %w", path, err)
+	}
+	return connections, nil
+}
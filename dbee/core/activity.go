@@ -0,0 +1,17 @@
+package core
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrActivityNotSupported = errors.New("activity monitoring not supported")
+
+// ActivityMonitor is an optional interface for drivers that can list sessions
+// (and the queries they run) on the server and kill them.
+type ActivityMonitor interface {
+	// Activity returns active sessions as a result. The first column
+	// should be the session id accepted by KillSession.
+	Activity(ctx context.Context) (ResultStream, error)
+	KillSession(ctx context.Context, id string) error
+}
@@ -0,0 +1,155 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultBlobChunkSize is the number of bytes of a file written by a single
+// statement.
+const DefaultBlobChunkSize = 1 << 20
+
+var ErrBlobUploadNotSupported = errors.New("uploading binary values is not supported")
+
+type (
+	// BlobDialect describes how binary values are bound and appended.
+	BlobDialect struct {
+		// Placeholder of the bound value (e.g. "?" or "$1"), the first
+		// placeholder of the connection's dialect if empty.
+		Placeholder string
+		// Append returns an expression which appends the bound value to the
+		// (quoted) column. Files are written in a single chunk if it's nil.
+		Append func(column string) string
+	}
+
+	// BlobWriter is an optional interface for drivers which can write binary
+	// values as bind parameters.
+	BlobWriter interface {
+		BlobDialect() *BlobDialect
+		// ExecChunks executes first with the first chunk of r bound as the only
+		// parameter and next with each of the following chunks. Statements run
+		// in a single transaction. Empty readers execute first with an empty value.
+		ExecChunks(ctx context.Context, first, next string, r io.Reader, chunkSize int) error
+	}
+
+	// BlobUploadOptions configure writing a file into a binary column.
+	BlobUploadOptions struct {
+		Table  string
+		Schema string
+		Column string
+		// Path of the uploaded file.
+		Path string
+		// Key identifies the updated row by values of its columns. A new row is
+		// inserted if it's empty.
+		Key map[string]any
+		// Values of other columns of the inserted row. They identify the row
+		// while the rest of the file is appended, so files are written in a
+		// single chunk if they are empty.
+		Values map[string]any
+		// ChunkSize is the number of bytes written at once (DefaultBlobChunkSize if 0).
+		ChunkSize int
+	}
+)
+
+// UploadBlob writes the file into a binary column of a row. The file is
+// streamed in chunks, which are bound as parameters, so its contents never
+// end up in statements. It returns the number of written bytes.
+func (c *Connection) UploadBlob(opts *BlobUploadOptions) (int64, error) {
+	writer, ok := c.driver.(BlobWriter)
+	if !ok {
+		return 0, ErrBlobUploadNotSupported
+	}
+	if opts.Table == "" || opts.Column == "" {
+		return 0, errors.New("no table or column provided")
+	}
+	// bound values can't be passed through transactions of the connection
+	if c.InTransaction() {
+		return 0, ErrTransactionInProgress
+	}
+
+	file, err := os.Open(opts.Path)
+	if err != nil {
+		return 0, fmt.Errorf("os.Open: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("file.Stat: %w", err)
+	}
+
+	sqlDialect := c.Dialect()
+	quote := sqlDialect.QuoteIdentifier
+	table := sqlDialect.QualifiedName(opts.Schema, opts.Table)
+	column := quote(opts.Column)
+	dialect := writer.BlobDialect()
+	placeholder := dialect.Placeholder
+	if placeholder == "" {
+		placeholder = sqlDialect.Placeholder(1)
+	}
+
+	// where clause identifying the row
+	where := func(values map[string]any) string {
+		cols := sortedColumns(values)
+		conds := make([]string, len(cols))
+		for i, col := range cols {
+			if values[col] == nil {
+				conds[i] = quote(col) + " IS NULL"
+				continue
+			}
+			conds[i] = quote(col) + " = " + sqlDialect.Literal(values[col])
+		}
+		return strings.Join(conds, " AND ")
+	}
+
+	var first, next string
+	identity := opts.Key
+	if len(opts.Key) > 0 {
+		first = fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s", table, column, placeholder, where(opts.Key))
+	} else {
+		cols := sortedColumns(opts.Values)
+		quoted := make([]string, 0, len(cols)+1)
+		values := make([]string, 0, len(cols)+1)
+		for _, col := range cols {
+			quoted = append(quoted, quote(col))
+			values = append(values, sqlDialect.Literal(opts.Values[col]))
+		}
+		quoted = append(quoted, column)
+		values = append(values, placeholder)
+		first = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(quoted, ", "), strings.Join(values, ", "))
+		identity = opts.Values
+	}
+
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultBlobChunkSize
+	}
+	if dialect.Append != nil && len(identity) > 0 {
+		next = fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s", table, column, dialect.Append(column), where(identity))
+	} else if info.Size() > int64(chunkSize) {
+		// the file can't be appended
+		chunkSize = int(info.Size())
+	}
+
+	counter := &countingReader{r: file}
+	err = writer.ExecChunks(context.Background(), first, next, counter, chunkSize)
+	if err != nil {
+		return 0, classifyError(c.driver, err, first)
+	}
+
+	return counter.n.Load(), nil
+}
+
+func sortedColumns(values map[string]any) []string {
+	cols := make([]string, 0, len(values))
+	for col := range values {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+	return cols
+}
@@ -14,11 +14,16 @@ import (
 type Client struct {
 	db             *sql.DB
 	typeProcessors map[string]func(any) any
+	defaultProc    func(any) any
+	noticeHook     NoticeHook
+
+	savepointSyntax SavepointSyntax
 }
 
 func NewClient(db *sql.DB, opts ...ClientOption) *Client {
 	config := clientConfig{
-		typeProcessors: make(map[string]func(any) any),
+		typeProcessors:  make(map[string]func(any) any),
+		savepointSyntax: DefaultSavepointSyntax,
 	}
 	for _, opt := range opts {
 		opt(&config)
@@ -27,6 +32,10 @@ func NewClient(db *sql.DB, opts ...ClientOption) *Client {
 	return &Client{
 		db:             db,
 		typeProcessors: config.typeProcessors,
+		defaultProc:    config.defaultProcessor,
+		noticeHook:     config.noticeHook,
+
+		savepointSyntax: config.savepointSyntax,
 	}
 }
 
@@ -34,6 +43,19 @@ func (c *Client) Close() {
 	c.db.Close()
 }
 
+// PoolStats returns utilization of the connection pool of the database.
+func (c *Client) PoolStats() *core.PoolStats {
+	stats := c.db.Stats()
+	return &core.PoolStats{
+		MaxOpen:     stats.MaxOpenConnections,
+		Open:        stats.OpenConnections,
+		InUse:       stats.InUse,
+		Idle:        stats.Idle,
+		WaitCount:   stats.WaitCount,
+		WaitSeconds: stats.WaitDuration.Seconds(),
+	}
+}
+
 // Swap swaps current database connection for another one
 // and closes the old one.
 func (c *Client) Swap(db *sql.DB) {
@@ -60,7 +82,20 @@ func (c *Client) ColumnsFromQuery(query string, args ...any) ([]*core.Column, er
 
 // Exec executes a query and returns a stream with single row (number of affected results).
 func (c *Client) Exec(ctx context.Context, query string) (*ResultStream, error) {
-	res, err := c.db.ExecContext(ctx, query)
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("c.db.Conn: %w", err)
+	}
+	defer conn.Close()
+
+	meta := &core.Meta{}
+	detach, err := c.attachNotices(conn, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := conn.ExecContext(ctx, query)
+	detach()
 	if err != nil {
 		return nil, err
 	}
@@ -73,11 +108,32 @@ func (c *Client) Exec(ctx context.Context, query string) (*ResultStream, error)
 	rows := NewResultStreamBuilder().
 		WithNextFunc(NextSingle(affected)).
 		WithHeader(core.Header{"Rows Affected"}).
+		WithMeta(meta).
 		Build()
 
 	return rows, nil
 }
 
+// attachNotices attaches the notice hook (if any) to connection.
+// Notices are appended to meta.
+func (c *Client) attachNotices(conn *sql.Conn, meta *core.Meta) (detach func(), err error) {
+	if c.noticeHook == nil {
+		return func() {}, nil
+	}
+
+	detach, err = c.noticeHook(conn, func(notice string) {
+		meta.Notices = append(meta.Notices, notice)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("c.noticeHook: %w", err)
+	}
+	if detach == nil {
+		detach = func() {}
+	}
+
+	return detach, nil
+}
+
 // Query executes a query on a connection and returns a result stream.
 func (c *Client) Query(ctx context.Context, query string) (*ResultStream, error) {
 	rows, err := c.db.QueryContext(ctx, query)
@@ -101,21 +157,32 @@ func (c *Client) QueryUntilNotEmpty(ctx context.Context, queries ...string) (*Re
 		return nil, fmt.Errorf("c.db.Conn: %w", err)
 	}
 
+	meta := &core.Meta{}
+	detach, err := c.attachNotices(conn, meta)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
 	for _, query := range queries {
 		rows, err := conn.QueryContext(ctx, query)
 		if err != nil {
+			detach()
 			_ = conn.Close()
 			return nil, fmt.Errorf("conn.QueryContext: %w", err)
 		}
 
 		result, err := c.parseRows(rows)
 		if err != nil {
+			detach()
 			_ = conn.Close()
 			return nil, err
 		}
 
 		// has result
 		if len(result.Header()) > 0 {
+			result.meta = meta
+			result.AddCallback(detach)
 			result.AddCallback(func() { _ = conn.Close() })
 			return result, nil
 		}
@@ -123,12 +190,14 @@ func (c *Client) QueryUntilNotEmpty(ctx context.Context, queries ...string) (*Re
 		result.Close()
 	}
 
+	detach()
 	_ = conn.Close()
 
 	// return an empty result
 	return NewResultStreamBuilder().
 		WithNextFunc(NextNil()).
 		WithHeader(core.Header{"No Results"}).
+		WithMeta(meta).
 		Build(), nil
 }
 
@@ -137,6 +206,9 @@ func (c *Client) getTypeProcessor(typ string) func(any) any {
 	if ok {
 		return proc
 	}
+	if c.defaultProc != nil {
+		return c.defaultProc
+	}
 
 	return func(val any) any {
 		valb, ok := val.([]byte)
@@ -156,47 +228,27 @@ func (c *Client) parseRows(rows *sql.Rows) (*ResultStream, error) {
 	}
 
 	hasNextFunc := func() bool {
-		// TODO: do we even support multiple result sets?
-		// if not next result, check for any new sets
-		if !rows.Next() {
-			if !rows.NextResultSet() {
-				return false
-			}
-			return rows.Next()
-		}
-		return true
+		return rows.Next()
 	}
 
-	nextFunc := func() (core.Row, error) {
-		dbCols, err := rows.ColumnTypes()
-		if err != nil {
-			return nil, err
-		}
-
-		columns := make([]any, len(dbCols))
-		columnPointers := make([]any, len(dbCols))
-		for i := range columns {
-			columnPointers[i] = &columns[i]
-		}
-
-		if err := rows.Scan(columnPointers...); err != nil {
-			return nil, err
+	nextSetFunc := func() (core.Header, bool) {
+		if !rows.NextResultSet() {
+			return nil, false
 		}
-
-		row := make(core.Row, len(dbCols))
-		for i := range dbCols {
-			val := *columnPointers[i].(*any)
-
-			proc := c.getTypeProcessor(dbCols[i].DatabaseTypeName())
-
-			row[i] = proc(val)
+		header, err := rows.Columns()
+		if err != nil {
+			return nil, false
 		}
+		return header, true
+	}
 
-		return row, nil
+	nextFunc := func() (core.Row, error) {
+		return c.ScanRow(rows)
 	}
 
 	result := NewResultStreamBuilder().
 		WithNextFunc(nextFunc, hasNextFunc).
+		WithNextSetFunc(nextSetFunc).
 		WithHeader(header).
 		WithCloseFunc(func() {
 			_ = rows.Close()
@@ -205,3 +257,32 @@ func (c *Client) parseRows(rows *sql.Rows) (*ResultStream, error) {
 
 	return result, nil
 }
+
+// ScanRow scans the current row of rows and applies the registered type processors.
+func (c *Client) ScanRow(rows *sql.Rows) (core.Row, error) {
+	dbCols, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]any, len(dbCols))
+	columnPointers := make([]any, len(dbCols))
+	for i := range columns {
+		columnPointers[i] = &columns[i]
+	}
+
+	if err := rows.Scan(columnPointers...); err != nil {
+		return nil, err
+	}
+
+	row := make(core.Row, len(dbCols))
+	for i := range dbCols {
+		val := *columnPointers[i].(*any)
+
+		proc := c.getTypeProcessor(dbCols[i].DatabaseTypeName())
+
+		row[i] = proc(val)
+	}
+
+	return row, nil
+}
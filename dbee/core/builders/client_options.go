@@ -1,9 +1,20 @@
 package builders
 
-import "strings"
+import (
+	"database/sql"
+	"strings"
+)
+
+// NoticeHook attaches a collector of server notices (warnings, messages, ...) to a single
+// connection. Collected notices should be passed to onNotice. The returned detach
+// function is called after the query finishes and before the connection is released.
+type NoticeHook func(conn *sql.Conn, onNotice func(string)) (detach func(), err error)
 
 type clientConfig struct {
-	typeProcessors map[string]func(any) any
+	typeProcessors   map[string]func(any) any
+	defaultProcessor func(any) any
+	noticeHook       NoticeHook
+	savepointSyntax  SavepointSyntax
 }
 
 type ClientOption func(*clientConfig)
@@ -20,3 +31,27 @@ func WithCustomTypeProcessor(typ string, fn func(any) any) ClientOption {
 		cc.typeProcessors[t] = fn
 	}
 }
+
+// WithDefaultTypeProcessor processes values of types without a custom
+// processor (e.g. parametrized types like "Array(Int32)"). It replaces the
+// default conversion of []byte values to strings.
+func WithDefaultTypeProcessor(fn func(any) any) ClientOption {
+	return func(cc *clientConfig) {
+		cc.defaultProcessor = fn
+	}
+}
+
+// WithNoticeHook collects server notices for every query and attaches them
+// to result's Meta.
+func WithNoticeHook(hook NoticeHook) ClientOption {
+	return func(cc *clientConfig) {
+		cc.noticeHook = hook
+	}
+}
+
+// WithSavepointSyntax overrides the default savepoint syntax used in transactions.
+func WithSavepointSyntax(syntax SavepointSyntax) ClientOption {
+	return func(cc *clientConfig) {
+		cc.savepointSyntax = syntax
+	}
+}
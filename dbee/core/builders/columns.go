@@ -13,6 +13,10 @@ import (
 //
 //	1st elem: name - string
 //	2nd elem: type - string
+//	3rd elem (optional): nullable - bool or "YES"/"NO"
+//	4th elem (optional): default - string
+//	5th elem (optional): primary key - bool
+//	6th elem (optional): comment - string
 func ColumnsFromResultStream(rows core.ResultStream) ([]*core.Column, error) {
 	var out []*core.Column
 
@@ -26,12 +30,12 @@ func ColumnsFromResultStream(rows core.ResultStream) ([]*core.Column, error) {
 			return nil, errors.New("could not retrieve column info: insufficient data")
 		}
 
-		name, ok := row[0].(string)
+		name, ok := textValue(row[0])
 		if !ok {
 			return nil, errors.New("could not retrieve column info: name not a string")
 		}
 
-		typ, ok := row[1].(string)
+		typ, ok := textValue(row[1])
 		if !ok {
 			return nil, errors.New("could not retrieve column info: type not a string")
 		}
@@ -40,9 +44,33 @@ func ColumnsFromResultStream(rows core.ResultStream) ([]*core.Column, error) {
 			Name: name,
 			Type: typ,
 		}
+		if len(row) > 2 && row[2] != nil {
+			column.NotNull = !boolValue(row[2])
+		}
+		if len(row) > 3 {
+			column.Default = stringValue(row[3])
+		}
+		if len(row) > 4 {
+			column.PrimaryKey = boolValue(row[4])
+		}
+		if len(row) > 5 {
+			column.Comment = stringValue(row[5])
+		}
 
 		out = append(out, column)
 	}
 
 	return out, nil
 }
+
+// textValue returns the value as string if it holds text.
+func textValue(val any) (string, bool) {
+	switch v := val.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,218 @@
+package builders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// IndexesFromResultStream converts the result stream to indexes.
+// A result stream should return a row per indexed column, in index order,
+// at least 4 columns wide:
+//
+//	1st elem: index name - string
+//	2nd elem: column name or expression - string
+//	3rd elem: unique - bool
+//	4th elem: primary - bool
+func IndexesFromResultStream(rows core.ResultStream) ([]*core.Index, error) {
+	var out []*core.Index
+	byName := make(map[string]*core.Index)
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, fmt.Errorf("result.Next: %w", err)
+		}
+
+		if len(row) < 4 {
+			return nil, errors.New("could not retrieve index info: insufficient data")
+		}
+
+		name := stringValue(row[0])
+		index, ok := byName[name]
+		if !ok {
+			index = &core.Index{
+				Name:    name,
+				Unique:  boolValue(row[2]),
+				Primary: boolValue(row[3]),
+			}
+			byName[name] = index
+			out = append(out, index)
+		}
+
+		if column := stringValue(row[1]); column != "" {
+			index.Columns = append(index.Columns, column)
+		}
+	}
+
+	return out, nil
+}
+
+// ConstraintsFromResultStream converts the result stream to constraints.
+// A result stream should return a row per constrained column, in key order,
+// at least 7 columns wide:
+//
+//	1st elem: constraint name - string
+//	2nd elem: constraint type - string (e.g. "PRIMARY KEY", see core.ConstraintTypeFromString)
+//	3rd elem: column name - string
+//	4th elem: check definition - string
+//	5th elem: referenced schema - string
+//	6th elem: referenced table - string
+//	7th elem: referenced column - string
+func ConstraintsFromResultStream(rows core.ResultStream) ([]*core.Constraint, error) {
+	var out []*core.Constraint
+	byName := make(map[string]*core.Constraint)
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, fmt.Errorf("result.Next: %w", err)
+		}
+
+		if len(row) < 7 {
+			return nil, errors.New("could not retrieve constraint info: insufficient data")
+		}
+
+		name := stringValue(row[0])
+		constraint, ok := byName[name]
+		if !ok {
+			constraint = &core.Constraint{
+				Name:             name,
+				Type:             core.ConstraintTypeFromString(stringValue(row[1])),
+				Definition:       stringValue(row[3]),
+				ReferencedSchema: stringValue(row[4]),
+				ReferencedTable:  stringValue(row[5]),
+			}
+			byName[name] = constraint
+			out = append(out, constraint)
+		}
+
+		if column := stringValue(row[2]); column != "" {
+			constraint.Columns = append(constraint.Columns, column)
+		}
+		if column := stringValue(row[6]); column != "" {
+			constraint.ReferencedColumns = append(constraint.ReferencedColumns, column)
+		}
+	}
+
+	return out, nil
+}
+
+// TriggersFromResultStream converts the result stream to triggers.
+// A result stream should return a row per trigger event, at least 4 columns wide:
+//
+//	1st elem: trigger name - string
+//	2nd elem: event - string (e.g. "INSERT")
+//	3rd elem: timing - string (e.g. "AFTER")
+//	4th elem: function - string
+//
+// Events of the same trigger are joined with "OR".
+func TriggersFromResultStream(rows core.ResultStream) ([]*core.Trigger, error) {
+	var out []*core.Trigger
+	byName := make(map[string]*core.Trigger)
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, fmt.Errorf("result.Next: %w", err)
+		}
+
+		if len(row) < 4 {
+			return nil, errors.New("could not retrieve trigger info: insufficient data")
+		}
+
+		name := stringValue(row[0])
+		event := stringValue(row[1])
+
+		trigger, ok := byName[name]
+		if ok {
+			trigger.Event += " OR " + event
+			continue
+		}
+
+		trigger = &core.Trigger{
+			Name:     name,
+			Event:    event,
+			Timing:   stringValue(row[2]),
+			Function: stringValue(row[3]),
+		}
+		byName[name] = trigger
+		out = append(out, trigger)
+	}
+
+	return out, nil
+}
+
+// IndexesFromQuery executes the query and converts the result to indexes
+// (see IndexesFromResultStream).
+func (c *Client) IndexesFromQuery(ctx context.Context, query string, args ...any) ([]*core.Index, error) {
+	result, err := c.QueryArgs(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return IndexesFromResultStream(result)
+}
+
+// ConstraintsFromQuery executes the query and converts the result to constraints
+// (see ConstraintsFromResultStream).
+func (c *Client) ConstraintsFromQuery(ctx context.Context, query string, args ...any) ([]*core.Constraint, error) {
+	result, err := c.QueryArgs(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return ConstraintsFromResultStream(result)
+}
+
+// TriggersFromQuery executes the query and converts the result to triggers
+// (see TriggersFromResultStream).
+func (c *Client) TriggersFromQuery(ctx context.Context, query string, args ...any) ([]*core.Trigger, error) {
+	result, err := c.QueryArgs(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return TriggersFromResultStream(result)
+}
+
+// stringValue converts a catalog value to string. NULL is converted to an empty string.
+func stringValue(val any) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// boolValue converts a catalog value to bool. Drivers report booleans
+// as bools, numbers or strings.
+func boolValue(val any) bool {
+	switch v := val.(type) {
+	case bool:
+		return v
+	case int64:
+		return v != 0
+	case int:
+		return v != 0
+	default:
+		s := strings.ToLower(stringValue(val))
+		b, err := strconv.ParseBool(s)
+		if err == nil {
+			return b
+		}
+		return s == "yes" || s == "y"
+	}
+}
@@ -0,0 +1,99 @@
+package builders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// RoutinesFromResultStream converts the result stream to routine layout nodes.
+// A result stream should return rows that are at least 3 columns wide and
+// have the following structure:
+//
+//	1st elem: schema - string
+//	2nd elem: name - string
+//	3rd elem: type - string ("FUNCTION" or "PROCEDURE")
+func RoutinesFromResultStream(rows core.ResultStream) ([]*core.Structure, error) {
+	var out []*core.Structure
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, fmt.Errorf("result.Next: %w", err)
+		}
+
+		if len(row) < 3 {
+			return nil, errors.New("could not retrieve routine info: insufficient data")
+		}
+
+		schema, ok := row[0].(string)
+		if !ok {
+			return nil, errors.New("could not retrieve routine info: schema not a string")
+		}
+		name, ok := row[1].(string)
+		if !ok {
+			return nil, errors.New("could not retrieve routine info: name not a string")
+		}
+		typ, _ := row[2].(string)
+
+		structureType := core.StructureTypeFunction
+		if strings.EqualFold(typ, "PROCEDURE") {
+			structureType = core.StructureTypeProcedure
+		}
+
+		out = append(out, &core.Structure{
+			Name:   name,
+			Schema: schema,
+			Type:   structureType,
+		})
+	}
+
+	return out, nil
+}
+
+// RoutinesFromQuery executes the query and converts the result to routine
+// layout nodes (see RoutinesFromResultStream).
+func (c *Client) RoutinesFromQuery(query string, args ...any) ([]*core.Structure, error) {
+	result, err := c.QueryArgs(context.Background(), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return RoutinesFromResultStream(result)
+}
+
+// DefinitionFromQuery executes the query and joins the values of the column
+// at index col of all rows. Useful for catalogs which store the source line by line.
+func (c *Client) DefinitionFromQuery(ctx context.Context, col int, query string, args ...any) (string, error) {
+	result, err := c.QueryArgs(ctx, query, args...)
+	if err != nil {
+		return "", err
+	}
+	defer result.Close()
+
+	var sb strings.Builder
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return "", fmt.Errorf("result.Next: %w", err)
+		}
+		if col >= len(row) || row[col] == nil {
+			continue
+		}
+
+		switch v := row[col].(type) {
+		case string:
+			sb.WriteString(v)
+		case []byte:
+			sb.Write(v)
+		default:
+			sb.WriteString(fmt.Sprint(v))
+		}
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,50 @@
+package builders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// DependenciesFromResultStream converts the result stream to dependency nodes.
+// A result stream should return a row per object, at least 3 columns wide:
+//
+//	1st elem: schema - string
+//	2nd elem: object name - string
+//	3rd elem: object type - string (e.g. "view", see core.StructureTypeFromString)
+func DependenciesFromResultStream(rows core.ResultStream) ([]*core.DependencyNode, error) {
+	var out []*core.DependencyNode
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, fmt.Errorf("result.Next: %w", err)
+		}
+
+		if len(row) < 3 {
+			return nil, errors.New("could not retrieve dependencies: insufficient data")
+		}
+
+		out = append(out, &core.DependencyNode{
+			Schema: stringValue(row[0]),
+			Name:   stringValue(row[1]),
+			Type:   core.StructureTypeFromString(stringValue(row[2])),
+		})
+	}
+
+	return out, nil
+}
+
+// DependenciesFromQuery executes the query and converts the result to dependency
+// nodes (see DependenciesFromResultStream).
+func (c *Client) DependenciesFromQuery(ctx context.Context, query string, args ...any) ([]*core.DependencyNode, error) {
+	result, err := c.QueryArgs(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return DependenciesFromResultStream(result)
+}
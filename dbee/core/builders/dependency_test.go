@@ -0,0 +1,32 @@
+package builders_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestDependenciesFromResultStream(t *testing.T) {
+	r := require.New(t)
+
+	rows := mock.NewResultStream([]core.Row{
+		{"public", "active_users", "view"},
+		{"public", []byte("user_totals"), "materialized_view"},
+		{"public", "orders", "table"},
+	})
+
+	nodes, err := builders.DependenciesFromResultStream(rows)
+	r.NoError(err)
+	r.Equal([]*core.DependencyNode{
+		{Schema: "public", Name: "active_users", Type: core.StructureTypeView},
+		{Schema: "public", Name: "user_totals", Type: core.StructureTypeMaterializedView},
+		{Schema: "public", Name: "orders", Type: core.StructureTypeTable},
+	}, nodes)
+
+	_, err = builders.DependenciesFromResultStream(mock.NewResultStream([]core.Row{{"public", "orders"}}))
+	r.Error(err)
+}
@@ -0,0 +1,98 @@
+package builders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// ForeignServersFromResultStream converts the result stream to foreign servers.
+// A result stream should return a row per server, at least 3 columns wide:
+//
+//	1st elem: server name - string
+//	2nd elem: foreign data wrapper or provider - string
+//	3rd elem: connection options - string
+func ForeignServersFromResultStream(rows core.ResultStream) ([]*core.ForeignServer, error) {
+	var out []*core.ForeignServer
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, fmt.Errorf("result.Next: %w", err)
+		}
+
+		if len(row) < 3 {
+			return nil, errors.New("could not retrieve foreign servers: insufficient data")
+		}
+
+		out = append(out, &core.ForeignServer{
+			Name:    stringValue(row[0]),
+			Wrapper: stringValue(row[1]),
+			Options: stringValue(row[2]),
+		})
+	}
+
+	return out, nil
+}
+
+// ForeignObjectsFromResultStream converts the result stream to layout nodes of
+// objects accessible through a foreign server. A result stream should return
+// a row per object, at least 3 columns wide:
+//
+//	1st elem: schema - string
+//	2nd elem: object name - string
+//	3rd elem: object type - string (types containing "VIEW" are views, others are tables)
+func ForeignObjectsFromResultStream(rows core.ResultStream) ([]*core.Structure, error) {
+	var out []*core.Structure
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, fmt.Errorf("result.Next: %w", err)
+		}
+
+		if len(row) < 3 {
+			return nil, errors.New("could not retrieve foreign objects: insufficient data")
+		}
+
+		typ := core.StructureTypeTable
+		if strings.Contains(strings.ToUpper(stringValue(row[2])), "VIEW") {
+			typ = core.StructureTypeView
+		}
+
+		out = append(out, &core.Structure{
+			Schema: stringValue(row[0]),
+			Name:   stringValue(row[1]),
+			Type:   typ,
+		})
+	}
+
+	return out, nil
+}
+
+// ForeignServersFromQuery executes the query and converts the result to foreign
+// servers (see ForeignServersFromResultStream).
+func (c *Client) ForeignServersFromQuery(ctx context.Context, query string, args ...any) ([]*core.ForeignServer, error) {
+	result, err := c.QueryArgs(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return ForeignServersFromResultStream(result)
+}
+
+// ForeignObjectsFromQuery executes the query and converts the result to layout
+// nodes (see ForeignObjectsFromResultStream).
+func (c *Client) ForeignObjectsFromQuery(ctx context.Context, query string, args ...any) ([]*core.Structure, error) {
+	result, err := c.QueryArgs(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return ForeignObjectsFromResultStream(result)
+}
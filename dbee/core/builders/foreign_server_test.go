@@ -0,0 +1,48 @@
+package builders_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestForeignServersFromResultStream(t *testing.T) {
+	r := require.New(t)
+
+	rows := mock.NewResultStream([]core.Row{
+		{"reporting", "postgres_fdw", "host=reports, port=5432"},
+		{"legacy", []byte("SQL Server (SQLNCLI11)"), nil},
+	})
+
+	servers, err := builders.ForeignServersFromResultStream(rows)
+	r.NoError(err)
+	r.Equal([]*core.ForeignServer{
+		{Name: "reporting", Wrapper: "postgres_fdw", Options: "host=reports, port=5432"},
+		{Name: "legacy", Wrapper: "SQL Server (SQLNCLI11)"},
+	}, servers)
+
+	_, err = builders.ForeignServersFromResultStream(mock.NewResultStream([]core.Row{{"reporting"}}))
+	r.Error(err)
+}
+
+func TestForeignObjectsFromResultStream(t *testing.T) {
+	r := require.New(t)
+
+	rows := mock.NewResultStream([]core.Row{
+		{"dbo", "orders", "TABLE"},
+		{"dbo", "active_orders", "VIEW"},
+		{"sys", "objects", "SYSTEM VIEW"},
+	})
+
+	objects, err := builders.ForeignObjectsFromResultStream(rows)
+	r.NoError(err)
+	r.Equal([]*core.Structure{
+		{Schema: "dbo", Name: "orders", Type: core.StructureTypeTable},
+		{Schema: "dbo", Name: "active_orders", Type: core.StructureTypeView},
+		{Schema: "sys", Name: "objects", Type: core.StructureTypeView},
+	}, objects)
+}
@@ -0,0 +1,106 @@
+package builders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// RolesFromResultStream converts the result stream to roles.
+// A result stream should return a row per role membership (or a single row
+// for roles without any), at least 4 columns wide:
+//
+//	1st elem: role name - string
+//	2nd elem: superuser - bool
+//	3rd elem: can login - bool
+//	4th elem: name of the role this role is a member of - string (NULL if none)
+func RolesFromResultStream(rows core.ResultStream) ([]*core.Role, error) {
+	var out []*core.Role
+	byName := make(map[string]*core.Role)
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, fmt.Errorf("result.Next: %w", err)
+		}
+
+		if len(row) < 4 {
+			return nil, errors.New("could not retrieve role info: insufficient data")
+		}
+
+		name := stringValue(row[0])
+		role, ok := byName[name]
+		if !ok {
+			role = &core.Role{
+				Name:      name,
+				Superuser: boolValue(row[1]),
+				CanLogin:  boolValue(row[2]),
+			}
+			byName[name] = role
+			out = append(out, role)
+		}
+
+		if member := stringValue(row[3]); member != "" {
+			role.MemberOf = append(role.MemberOf, member)
+		}
+	}
+
+	return out, nil
+}
+
+// GrantsFromResultStream converts the result stream to grants.
+// A result stream should return a row per privilege, at least 4 columns wide:
+//
+//	1st elem: grantee - string
+//	2nd elem: privilege - string
+//	3rd elem: grantable - bool
+//	4th elem: grantor - string
+func GrantsFromResultStream(rows core.ResultStream) ([]*core.Grant, error) {
+	var out []*core.Grant
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, fmt.Errorf("result.Next: %w", err)
+		}
+
+		if len(row) < 4 {
+			return nil, errors.New("could not retrieve grant info: insufficient data")
+		}
+
+		out = append(out, &core.Grant{
+			Grantee:   stringValue(row[0]),
+			Privilege: stringValue(row[1]),
+			Grantable: boolValue(row[2]),
+			Grantor:   stringValue(row[3]),
+		})
+	}
+
+	return out, nil
+}
+
+// RolesFromQuery executes the query and converts the result to roles
+// (see RolesFromResultStream).
+func (c *Client) RolesFromQuery(ctx context.Context, query string, args ...any) ([]*core.Role, error) {
+	result, err := c.QueryArgs(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return RolesFromResultStream(result)
+}
+
+// GrantsFromQuery executes the query and converts the result to grants
+// (see GrantsFromResultStream).
+func (c *Client) GrantsFromQuery(ctx context.Context, query string, args ...any) ([]*core.Grant, error) {
+	result, err := c.QueryArgs(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return GrantsFromResultStream(result)
+}
@@ -0,0 +1,30 @@
+package builders_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestRolesFromResultStream(t *testing.T) {
+	r := require.New(t)
+
+	rows := mock.NewResultStream([]core.Row{
+		{"admin", true, true, nil},
+		{"alice", false, true, "readers"},
+		{"alice", false, true, "writers"},
+		{"readers", false, false, nil},
+	})
+
+	roles, err := builders.RolesFromResultStream(rows)
+	r.NoError(err)
+	r.Equal([]*core.Role{
+		{Name: "admin", Superuser: true, CanLogin: true},
+		{Name: "alice", CanLogin: true, MemberOf: []string{"readers", "writers"}},
+		{Name: "readers"},
+	}, roles)
+}
@@ -0,0 +1,109 @@
+package builders
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// Placeholder returns the n-th (1-based) bind parameter placeholder of a dialect.
+type Placeholder func(n int) string
+
+var (
+	// PlaceholderQuestion is used by mysql, sqlite and the like ("?").
+	PlaceholderQuestion Placeholder = func(int) string { return "?" }
+	// PlaceholderDollar is used by postgres ("$1").
+	PlaceholderDollar Placeholder = func(n int) string { return "$" + strconv.Itoa(n) }
+	// PlaceholderColon is used by oracle (":1").
+	PlaceholderColon Placeholder = func(n int) string { return ":" + strconv.Itoa(n) }
+	// PlaceholderAtP is used by sqlserver ("@p1").
+	PlaceholderAtP Placeholder = func(n int) string { return "@p" + strconv.Itoa(n) }
+)
+
+// WithTx runs fn in a transaction, which is committed if fn succeeds
+// and rolled back otherwise.
+func (c *Client) WithTx(ctx context.Context, fn func(*sql.Tx) error) error {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("c.db.BeginTx: %w", err)
+	}
+
+	err = fn(tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// InsertBatch inserts rows into table in a single transaction using a prepared
+// INSERT statement. This is the generic bulk import path for drivers that don't
+// have a faster one. The table (in schema, if not empty) and columns are quoted
+// with the dialect.
+func (c *Client) InsertBatch(ctx context.Context, dialect *core.Dialect, schema, table string, columns []string, rows []core.Row, placeholder Placeholder) error {
+	quoted := make([]string, len(columns))
+	placeholders := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = dialect.QuoteIdentifier(col)
+		placeholders[i] = placeholder(i + 1)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		dialect.QualifiedName(schema, table),
+		strings.Join(quoted, ", "),
+		strings.Join(placeholders, ", "))
+
+	return c.WithTx(ctx, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return fmt.Errorf("tx.PrepareContext: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, row := range rows {
+			_, err := stmt.ExecContext(ctx, row...)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ExecChunks reads r in chunks of chunkSize bytes and executes first with the
+// first chunk and next with each of the following chunks bound as the only
+// parameter, all in a single transaction. This is the generic implementation
+// of core.BlobWriter.
+func (c *Client) ExecChunks(ctx context.Context, first, next string, r io.Reader, chunkSize int) error {
+	return c.WithTx(ctx, func(tx *sql.Tx) error {
+		buf := make([]byte, chunkSize)
+		query := first
+		for {
+			n, err := io.ReadFull(r, buf)
+			if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+				return fmt.Errorf("io.ReadFull: %w", err)
+			}
+			// the rest of an empty reader was already written
+			if n == 0 && query != first {
+				return nil
+			}
+
+			_, execErr := tx.ExecContext(ctx, query, buf[:n])
+			if execErr != nil {
+				return execErr
+			}
+			if err != nil || next == "" {
+				return nil
+			}
+			query = next
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package builders
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang-sql/sqlexp"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// QueryWithMessages executes a query on drivers that support sqlexp.ReturnMessage.
+// Messages the server sends along with results (e.g. PRINT statements) are collected
+// into Meta's notices. Errors after the first result set are treated as messages as
+// well, since they don't necessarily abort the rest of the batch.
+// If the query returns no rows, the number of affected rows is returned instead.
+func (c *Client) QueryWithMessages(ctx context.Context, query string, args ...any) (*ResultStream, error) {
+	retmsg := &sqlexp.ReturnMessage{}
+	rows, err := c.db.QueryContext(ctx, query, append(args, retmsg)...)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &core.Meta{}
+	var affected int64
+
+	// advance processes messages until the next rows are available.
+	// It returns false if there are no more results.
+	advance := func(failOnError bool) (bool, error) {
+		for {
+			switch m := retmsg.Message(ctx).(type) {
+			case sqlexp.MsgNotice:
+				meta.Notices = append(meta.Notices, m.Message.String())
+			case sqlexp.MsgError:
+				if failOnError {
+					return false, m.Error
+				}
+				meta.Notices = append(meta.Notices, fmt.Sprintf("error: %s", m.Error))
+			case sqlexp.MsgRowsAffected:
+				affected += m.Count
+			case sqlexp.MsgNext:
+				return true, nil
+			case sqlexp.MsgNextResultSet:
+				if !rows.NextResultSet() {
+					return false, rows.Err()
+				}
+			}
+		}
+	}
+
+	hasRows, err := advance(true)
+	if err != nil {
+		_ = rows.Close()
+		return nil, err
+	}
+	if !hasRows {
+		_ = rows.Close()
+		return NewResultStreamBuilder().
+			WithNextFunc(NextSingle(affected)).
+			WithHeader(core.Header{"Rows Affected"}).
+			WithMeta(meta).
+			Build(), nil
+	}
+
+	header, err := rows.Columns()
+	if err != nil {
+		_ = rows.Close()
+		return nil, err
+	}
+
+	hasNext := func() bool {
+		return rows.Next()
+	}
+
+	nextSet := func() (core.Header, bool) {
+		ok, err := advance(false)
+		if err != nil {
+			meta.Notices = append(meta.Notices, fmt.Sprintf("error: %s", err))
+			return nil, false
+		}
+		if !ok {
+			return nil, false
+		}
+		header, err := rows.Columns()
+		if err != nil {
+			return nil, false
+		}
+		return header, true
+	}
+
+	next := func() (core.Row, error) {
+		return c.ScanRow(rows)
+	}
+
+	return NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithNextSetFunc(nextSet).
+		WithHeader(header).
+		WithMeta(meta).
+		WithCloseFunc(func() {
+			_ = rows.Close()
+		}).
+		Build(), nil
+}
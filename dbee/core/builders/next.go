@@ -1,6 +1,7 @@
 package builders
 
 import (
+	"context"
 	"errors"
 	"sync/atomic"
 	"time"
@@ -152,3 +153,57 @@ func NextYield(fn func(yield func(...any)) error) (func() (core.Row, error), fun
 
 	return next, hasNext
 }
+
+// NextEvents creates next and hasNext functions of a stream of events, which
+// doesn't end until fn returns or ctx is canceled. hasNext blocks until fn
+// yields the next event, however long it takes (e.g. events of
+// subscriptions). An error returned by fn (e.g. a lost connection) is
+// returned by next after the last event, errors after ctx is canceled are
+// ignored.
+// WARNING: the caller must call "hasNext" before each call to "next".
+func NextEvents(ctx context.Context, fn func(ctx context.Context, yield func(...any)) error) (func() (core.Row, error), func() bool) {
+	resultsCh := make(chan []any)
+	errorsCh := make(chan error, 1)
+
+	go func() {
+		defer close(resultsCh)
+
+		err := fn(ctx, func(v ...any) {
+			select {
+			case resultsCh <- v:
+			case <-ctx.Done():
+			}
+		})
+		if err != nil && ctx.Err() == nil {
+			errorsCh <- err
+		}
+	}()
+
+	var row core.Row
+	var nextErr error
+
+	hasNext := func() bool {
+		select {
+		case vals, ok := <-resultsCh:
+			if !ok {
+				select {
+				case nextErr = <-errorsCh:
+					row = nil
+					return true
+				default:
+				}
+				return false
+			}
+			row = vals
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	next := func() (core.Row, error) {
+		return row, nextErr
+	}
+
+	return next, hasNext
+}
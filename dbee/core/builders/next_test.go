@@ -1,6 +1,7 @@
 package builders_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -90,3 +91,63 @@ func TestNextYield_SingleRow(t *testing.T) {
 
 	r.Equal(false, hasNext())
 }
+
+func TestNextEvents(t *testing.T) {
+	r := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan string)
+	next, hasNext := builders.NextEvents(ctx, func(ctx context.Context, yield func(...any)) error {
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case event := <-events:
+				yield(event)
+			}
+		}
+	})
+
+	// events arrive long after the subscription started
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		events <- "first"
+		time.Sleep(200 * time.Millisecond)
+		events <- "second"
+	}()
+
+	for _, expected := range []string{"first", "second"} {
+		r.True(hasNext())
+		row, err := next()
+		r.NoError(err)
+		r.Equal(core.Row{expected}, row)
+	}
+
+	// canceling ends the stream
+	cancel()
+	r.False(hasNext())
+}
+
+func TestNextEvents_Error(t *testing.T) {
+	r := require.New(t)
+
+	expectedError := errors.New("connection lost")
+
+	next, hasNext := builders.NextEvents(context.Background(), func(_ context.Context, yield func(...any)) error {
+		yield("event")
+		return expectedError
+	})
+
+	r.True(hasNext())
+	row, err := next()
+	r.NoError(err)
+	r.Equal(core.Row{"event"}, row)
+
+	r.True(hasNext())
+	_, err = next()
+	r.ErrorIs(err, expectedError)
+
+	r.False(hasNext())
+}
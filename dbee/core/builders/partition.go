@@ -0,0 +1,54 @@
+package builders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// PartitionsFromResultStream converts the result stream to partitions.
+// A result stream should return a row per partition, at least 5 columns wide:
+//
+//	1st elem: schema - string
+//	2nd elem: partition name - string
+//	3rd elem: partition bound or key value - string
+//	4th elem: estimated row count - number (NULL if unknown)
+//	5th elem: size in bytes - number (NULL if unknown)
+func PartitionsFromResultStream(rows core.ResultStream) ([]*core.Partition, error) {
+	var out []*core.Partition
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, fmt.Errorf("result.Next: %w", err)
+		}
+
+		if len(row) < 5 {
+			return nil, errors.New("could not retrieve partitions: insufficient data")
+		}
+
+		out = append(out, &core.Partition{
+			Schema:     stringValue(row[0]),
+			Name:       stringValue(row[1]),
+			Expression: stringValue(row[2]),
+			Rows:       intValue(row[3]),
+			Size:       intValue(row[4]),
+		})
+	}
+
+	return out, nil
+}
+
+// PartitionsFromQuery executes the query and converts the result to partitions
+// (see PartitionsFromResultStream).
+func (c *Client) PartitionsFromQuery(ctx context.Context, query string, args ...any) ([]*core.Partition, error) {
+	result, err := c.QueryArgs(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return PartitionsFromResultStream(result)
+}
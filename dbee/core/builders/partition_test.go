@@ -0,0 +1,36 @@
+package builders_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestPartitionsFromResultStream(t *testing.T) {
+	r := require.New(t)
+
+	rows := mock.NewResultStream([]core.Row{
+		{"public", "events_2024", "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')", int64(1200), int64(65536)},
+		{"public", "events_default", []byte("DEFAULT"), nil, nil},
+	})
+
+	partitions, err := builders.PartitionsFromResultStream(rows)
+	r.NoError(err)
+	r.Equal([]*core.Partition{
+		{
+			Schema:     "public",
+			Name:       "events_2024",
+			Expression: "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')",
+			Rows:       1200,
+			Size:       65536,
+		},
+		{Schema: "public", Name: "events_default", Expression: "DEFAULT", Rows: -1, Size: -1},
+	}, partitions)
+
+	_, err = builders.PartitionsFromResultStream(mock.NewResultStream([]core.Row{{"public", "events_2024"}}))
+	r.Error(err)
+}
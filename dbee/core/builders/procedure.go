@@ -0,0 +1,289 @@
+package builders
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// Statement is a single query with its arguments.
+type Statement struct {
+	Query string
+	Args  []any
+}
+
+// ProcedureOutput is a destination for a procedure's output parameter.
+type ProcedureOutput struct {
+	Name string
+	Dest *sql.NullString
+}
+
+// NewProcedureOutput creates an output destination. For in-out parameters,
+// the destination is prefilled with value.
+func NewProcedureOutput(param *core.ProcedureParam) *ProcedureOutput {
+	dest := new(sql.NullString)
+	if param.Mode == core.ParamModeInOut && param.Value != nil {
+		dest.String = fmt.Sprint(param.Value)
+		dest.Valid = true
+	}
+
+	return &ProcedureOutput{
+		Name: param.Name,
+		Dest: dest,
+	}
+}
+
+// OutputsRow returns a result stream with a single row containing values of outputs.
+// NULL values are returned as nil.
+func OutputsRow(outputs []*ProcedureOutput) *ResultStream {
+	if len(outputs) < 1 {
+		return NewResultStreamBuilder().
+			WithNextFunc(NextNil()).
+			WithHeader(core.Header{"No Results"}).
+			Build()
+	}
+
+	header := make(core.Header, len(outputs))
+	row := make(core.Row, len(outputs))
+	for i, out := range outputs {
+		header[i] = out.Name
+		if out.Dest.Valid {
+			row[i] = out.Dest.String
+		}
+	}
+
+	has := true
+	next := func() (core.Row, error) {
+		has = false
+		return row, nil
+	}
+
+	return NewResultStreamBuilder().
+		WithNextFunc(next, func() bool { return has }).
+		WithHeader(header).
+		Build()
+}
+
+// QueryWithOutputs executes the query with args and streams the result sets it
+// returns. Drivers populate output destinations only after all result sets are
+// consumed, which is why values of outputs are appended as the last result set.
+// Result sets without columns are skipped.
+func (c *Client) QueryWithOutputs(ctx context.Context, query string, args []any, outputs []*ProcedureOutput) (*ResultStream, error) {
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// tail is the row of outputs, once the result sets of the query are consumed
+	var tail *ResultStream
+	toOutputs := func() (core.Header, error) {
+		for rows.NextResultSet() {
+		}
+		if err := rows.Close(); err != nil {
+			return nil, err
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		tail = OutputsRow(outputs)
+		return tail.Header(), nil
+	}
+
+	// nextHeader advances to the next result set with columns
+	nextHeader := func() (core.Header, bool, error) {
+		for {
+			header, err := rows.Columns()
+			if err != nil {
+				return nil, false, err
+			}
+			if len(header) > 0 {
+				return header, true, nil
+			}
+			if !rows.NextResultSet() {
+				return nil, false, rows.Err()
+			}
+		}
+	}
+
+	header, ok, err := nextHeader()
+	if err == nil && !ok {
+		header, err = toOutputs()
+	}
+	if err != nil {
+		_ = rows.Close()
+		return nil, err
+	}
+
+	hasNext := func() bool {
+		if tail != nil {
+			return tail.HasNext()
+		}
+		return rows.Next()
+	}
+
+	next := func() (core.Row, error) {
+		if tail != nil {
+			return tail.Next()
+		}
+		return c.ScanRow(rows)
+	}
+
+	nextSet := func() (core.Header, bool) {
+		if tail != nil {
+			return nil, false
+		}
+		if rows.NextResultSet() {
+			header, ok, err := nextHeader()
+			if err != nil {
+				return nil, false
+			}
+			if ok {
+				return header, true
+			}
+		}
+		if len(outputs) < 1 {
+			_ = rows.Close()
+			return nil, false
+		}
+		header, err := toOutputs()
+		if err != nil {
+			return nil, false
+		}
+		return header, true
+	}
+
+	return NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithNextSetFunc(nextSet).
+		WithHeader(header).
+		WithCloseFunc(func() {
+			_ = rows.Close()
+		}).
+		Build(), nil
+}
+
+// ExecArgs executes a query with arguments without returning any rows.
+func (c *Client) ExecArgs(ctx context.Context, query string, args ...any) error {
+	_, err := c.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+// QueryArgs executes a query with arguments and returns a result stream.
+func (c *Client) QueryArgs(ctx context.Context, query string, args ...any) (*ResultStream, error) {
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.parseRows(rows)
+}
+
+// QuerySequence executes statements on a single connection and streams the result
+// sets of all of them in order. Result sets without columns (e.g. of statements which
+// don't return rows) are skipped. Useful for dialects which rely on session variables.
+// Errors of statements executed after the first result set are added to notices.
+func (c *Client) QuerySequence(ctx context.Context, statements ...*Statement) (*ResultStream, error) {
+	if len(statements) < 1 {
+		return nil, fmt.Errorf("no statements provided")
+	}
+
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("c.db.Conn: %w", err)
+	}
+
+	var rows *sql.Rows
+	closeRows := func() {
+		if rows != nil {
+			_ = rows.Close()
+		}
+	}
+
+	// advance moves to the next result set with columns, executing the remaining
+	// statements as needed. It returns false if there are no more result sets.
+	advance := func(nextSet bool) (core.Header, bool, error) {
+		for {
+			if rows == nil {
+				if len(statements) < 1 {
+					return nil, false, nil
+				}
+				stmt := statements[0]
+				statements = statements[1:]
+
+				r, err := conn.QueryContext(ctx, stmt.Query, stmt.Args...)
+				if err != nil {
+					return nil, false, fmt.Errorf("conn.QueryContext: %w", err)
+				}
+				rows = r
+				nextSet = false
+			}
+
+			if !nextSet || rows.NextResultSet() {
+				header, err := rows.Columns()
+				if err != nil {
+					return nil, false, err
+				}
+				if len(header) > 0 {
+					return header, true, nil
+				}
+				nextSet = true
+				continue
+			}
+
+			_ = rows.Close()
+			if err := rows.Err(); err != nil {
+				return nil, false, err
+			}
+			rows = nil
+		}
+	}
+
+	header, ok, err := advance(false)
+	if err != nil {
+		closeRows()
+		_ = conn.Close()
+		return nil, err
+	}
+	if !ok {
+		_ = conn.Close()
+		return NewResultStreamBuilder().
+			WithNextFunc(NextNil()).
+			WithHeader(core.Header{"No Results"}).
+			Build(), nil
+	}
+
+	meta := &core.Meta{}
+
+	hasNext := func() bool {
+		return rows != nil && rows.Next()
+	}
+
+	next := func() (core.Row, error) {
+		return c.ScanRow(rows)
+	}
+
+	nextSet := func() (core.Header, bool) {
+		if rows == nil {
+			return nil, false
+		}
+		header, ok, err := advance(true)
+		if err != nil {
+			meta.Notices = append(meta.Notices, fmt.Sprintf("error: %s", err))
+			return nil, false
+		}
+		return header, ok
+	}
+
+	return NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithNextSetFunc(nextSet).
+		WithHeader(header).
+		WithMeta(meta).
+		WithCloseFunc(func() {
+			closeRows()
+			_ = conn.Close()
+		}).
+		Build(), nil
+}
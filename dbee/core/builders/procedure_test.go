@@ -0,0 +1,194 @@
+package builders_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+// procedureSet is a single result set returned by procedureSQLDriver.
+type procedureSet struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+// procedureSQLDriver is a database/sql driver which returns predefined result
+// sets per query. Output arguments are set to "out" once rows are closed, the
+// same as real drivers do after all result sets are consumed. Executed
+// statements affect 2 rows.
+type procedureSQLDriver struct {
+	results map[string][]procedureSet
+}
+
+type (
+	procedureConn struct{ results map[string][]procedureSet }
+	procedureTx   struct{}
+	procedureStmt struct {
+		query   string
+		results map[string][]procedureSet
+	}
+	procedureRows struct {
+		sets    []procedureSet
+		row     int
+		outputs []sql.Out
+	}
+)
+
+func init() {
+	sql.Register("dbee_procedure_test", &procedureSQLDriver{results: map[string][]procedureSet{
+		"CALL with_sets": {
+			{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}, {int64(2)}}},
+			{},
+			{columns: []string{"name"}, rows: [][]driver.Value{{"a"}}},
+			{},
+		},
+		"CALL no_sets":           {{}},
+		"SET @x = 1":             {{}},
+		"SELECT @x":              {{columns: []string{"@x"}, rows: [][]driver.Value{{"1"}}}},
+		"CREATE TABLE t (a int)": {{}},
+	}})
+}
+
+func (d *procedureSQLDriver) Open(string) (driver.Conn, error) {
+	return &procedureConn{results: d.results}, nil
+}
+
+func (c *procedureConn) Prepare(query string) (driver.Stmt, error) {
+	return &procedureStmt{query: query, results: c.results}, nil
+}
+func (c *procedureConn) Close() error                             { return nil }
+func (c *procedureConn) Begin() (driver.Tx, error)                { return procedureTx{}, nil }
+func (c *procedureConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+func (s *procedureStmt) Close() error  { return nil }
+func (s *procedureStmt) NumInput() int { return -1 }
+func (s *procedureStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(2), nil
+}
+func (s *procedureStmt) Query([]driver.Value) (driver.Rows, error) { return nil, driver.ErrSkip }
+
+func (s *procedureStmt) QueryContext(_ context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	rows := &procedureRows{sets: s.results[s.query]}
+	for _, arg := range args {
+		if out, ok := arg.Value.(sql.Out); ok {
+			rows.outputs = append(rows.outputs, out)
+		}
+	}
+	return rows, nil
+}
+
+func (procedureTx) Commit() error   { return nil }
+func (procedureTx) Rollback() error { return nil }
+
+func (r *procedureRows) Columns() []string      { return r.sets[0].columns }
+func (r *procedureRows) HasNextResultSet() bool { return len(r.sets) > 1 }
+func (r *procedureRows) NextResultSet() error {
+	if len(r.sets) < 2 {
+		return io.EOF
+	}
+	r.sets = r.sets[1:]
+	r.row = 0
+	return nil
+}
+
+func (r *procedureRows) Next(dest []driver.Value) error {
+	if r.row >= len(r.sets[0].rows) {
+		return io.EOF
+	}
+	copy(dest, r.sets[0].rows[r.row])
+	r.row++
+	return nil
+}
+
+func (r *procedureRows) Close() error {
+	for _, out := range r.outputs {
+		*out.Dest.(*sql.NullString) = sql.NullString{String: "out", Valid: true}
+	}
+	return nil
+}
+
+// readSets reads all result sets of the stream.
+func readSets(t *testing.T, result core.ResultStream) []core.Header {
+	t.Helper()
+
+	var sets []core.Header
+	for {
+		header := append(core.Header{}, result.Header()...)
+		for result.HasNext() {
+			row, err := result.Next()
+			require.NoError(t, err)
+			for _, val := range row {
+				header = append(header, val.(string))
+			}
+		}
+		sets = append(sets, header)
+
+		if !result.(core.MultiResultStream).NextResultSet() {
+			break
+		}
+	}
+	result.Close()
+	return sets
+}
+
+func TestClient_QueryWithOutputs(t *testing.T) {
+	r := require.New(t)
+
+	db, err := sql.Open("dbee_procedure_test", "")
+	r.NoError(err)
+	defer db.Close()
+
+	client := builders.NewClient(db, builders.WithDefaultTypeProcessor(func(val any) any {
+		return fmt.Sprint(val)
+	}))
+
+	param := &core.ProcedureParam{Name: "total", Mode: core.ParamModeOut}
+	out := builders.NewProcedureOutput(param)
+	outputs := []*builders.ProcedureOutput{out}
+
+	// result sets of the procedure come first, sets without columns are skipped
+	result, err := client.QueryWithOutputs(context.Background(), "CALL with_sets", []any{sql.Out{Dest: out.Dest}}, outputs)
+	r.NoError(err)
+	r.Equal([]core.Header{{"id", "1", "2"}, {"name", "a"}, {"total", "out"}}, readSets(t, result))
+
+	result, err = client.QueryWithOutputs(context.Background(), "CALL no_sets", []any{sql.Out{Dest: out.Dest}}, outputs)
+	r.NoError(err)
+	r.Equal([]core.Header{{"total", "out"}}, readSets(t, result))
+
+	// without outputs, only the result sets are returned
+	result, err = client.QueryWithOutputs(context.Background(), "CALL with_sets", nil, nil)
+	r.NoError(err)
+	r.Equal([]core.Header{{"id", "1", "2"}, {"name", "a"}}, readSets(t, result))
+}
+
+func TestClient_QuerySequence(t *testing.T) {
+	r := require.New(t)
+
+	db, err := sql.Open("dbee_procedure_test", "")
+	r.NoError(err)
+	defer db.Close()
+
+	client := builders.NewClient(db, builders.WithDefaultTypeProcessor(func(val any) any {
+		return fmt.Sprint(val)
+	}))
+
+	result, err := client.QuerySequence(context.Background(),
+		&builders.Statement{Query: "SET @x = 1"},
+		&builders.Statement{Query: "CALL with_sets"},
+		&builders.Statement{Query: "SELECT @x"},
+	)
+	r.NoError(err)
+	r.Equal([]core.Header{{"id", "1", "2"}, {"name", "a"}, {"@x", "1"}}, readSets(t, result))
+
+	result, err = client.QuerySequence(context.Background(), &builders.Statement{Query: "SET @x = 1"})
+	r.NoError(err)
+	r.Equal([]core.Header{{"No Results"}}, readSets(t, result))
+}
@@ -0,0 +1,52 @@
+package builders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// ObjectMatchesFromResultStream converts the result stream to search matches.
+// A result stream should return rows that are at least 4 columns wide:
+//
+//	1st elem: schema - string
+//	2nd elem: table of the column (empty for other objects) - string
+//	3rd elem: name - string
+//	4th elem: type - string (see core.StructureTypeFromString)
+func ObjectMatchesFromResultStream(rows core.ResultStream) ([]*core.ObjectMatch, error) {
+	var out []*core.ObjectMatch
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, fmt.Errorf("result.Next: %w", err)
+		}
+
+		if len(row) < 4 {
+			return nil, errors.New("could not retrieve search matches: insufficient data")
+		}
+
+		out = append(out, &core.ObjectMatch{
+			Schema: stringValue(row[0]),
+			Table:  stringValue(row[1]),
+			Name:   stringValue(row[2]),
+			Type:   core.StructureTypeFromString(stringValue(row[3])),
+		})
+	}
+
+	return out, nil
+}
+
+// ObjectMatchesFromQuery executes the query and converts the result to search
+// matches (see ObjectMatchesFromResultStream).
+func (c *Client) ObjectMatchesFromQuery(ctx context.Context, query string, args ...any) ([]*core.ObjectMatch, error) {
+	result, err := c.QueryArgs(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return ObjectMatchesFromResultStream(result)
+}
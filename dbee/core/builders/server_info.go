@@ -0,0 +1,63 @@
+package builders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// ServerInfoFromResultStream converts the result stream to server info.
+// A result stream should return a row per property, at least 3 columns wide:
+//
+//	1st elem: kind - string ("version", "uptime", "extension" or "setting")
+//	2nd elem: name - string (name of the extension or setting)
+//	3rd elem: value - any (version, uptime in seconds or value of the setting)
+//
+// Rows of unknown kinds are ignored.
+func ServerInfoFromResultStream(rows core.ResultStream) (*core.ServerInfo, error) {
+	info := &core.ServerInfo{Uptime: -1}
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, fmt.Errorf("result.Next: %w", err)
+		}
+
+		if len(row) < 3 {
+			return nil, errors.New("could not retrieve server info: insufficient data")
+		}
+
+		switch stringValue(row[0]) {
+		case "version":
+			info.Version = stringValue(row[2])
+		case "uptime":
+			info.Uptime = intValue(row[2])
+		case "extension":
+			info.Extensions = append(info.Extensions, &core.Extension{
+				Name:    stringValue(row[1]),
+				Version: stringValue(row[2]),
+			})
+		case "setting":
+			info.Settings = append(info.Settings, &core.Setting{
+				Name:  stringValue(row[1]),
+				Value: stringValue(row[2]),
+			})
+		}
+	}
+
+	return info, nil
+}
+
+// ServerInfoFromQuery executes the query and converts the result to server info
+// (see ServerInfoFromResultStream).
+func (c *Client) ServerInfoFromQuery(ctx context.Context, query string, args ...any) (*core.ServerInfo, error) {
+	result, err := c.QueryArgs(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return ServerInfoFromResultStream(result)
+}
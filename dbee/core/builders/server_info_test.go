@@ -0,0 +1,45 @@
+package builders_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestServerInfoFromResultStream(t *testing.T) {
+	r := require.New(t)
+
+	rows := mock.NewResultStream([]core.Row{
+		{"version", nil, "PostgreSQL 16.2"},
+		{"uptime", nil, float64(3600.5)},
+		{"extension", "plpgsql", "1.0"},
+		{"extension", "pg_trgm", []byte("1.6")},
+		{"setting", "TimeZone", "UTC"},
+		{"unknown", "ignored", "value"},
+	})
+
+	info, err := builders.ServerInfoFromResultStream(rows)
+	r.NoError(err)
+	r.Equal(&core.ServerInfo{
+		Version: "PostgreSQL 16.2",
+		Uptime:  3600,
+		Extensions: []*core.Extension{
+			{Name: "plpgsql", Version: "1.0"},
+			{Name: "pg_trgm", Version: "1.6"},
+		},
+		Settings: []*core.Setting{
+			{Name: "TimeZone", Value: "UTC"},
+		},
+	}, info)
+
+	info, err = builders.ServerInfoFromResultStream(mock.NewResultStream(nil))
+	r.NoError(err)
+	r.Equal(int64(-1), info.Uptime)
+
+	_, err = builders.ServerInfoFromResultStream(mock.NewResultStream([]core.Row{{"version", nil}}))
+	r.Error(err)
+}
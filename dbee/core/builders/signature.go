@@ -0,0 +1,73 @@
+package builders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// SignaturesFromResultStream converts the result stream to signatures.
+// A result stream should return a row per parameter (or a single row for
+// routines without parameters), ordered by overload and parameter position,
+// at least 9 columns wide:
+//
+//	1st elem: schema - string
+//	2nd elem: routine name - string
+//	3rd elem: overload identifier - string (e.g. oid, empty if routines can't be overloaded)
+//	4th elem: parameter position - int (0 if the routine has no parameters)
+//	5th elem: parameter name - string
+//	6th elem: parameter type - string
+//	7th elem: parameter mode - string ("in", "out" or "inout")
+//	8th elem: return type - string
+//	9th elem: comment - string
+func SignaturesFromResultStream(rows core.ResultStream) ([]*core.Signature, error) {
+	var out []*core.Signature
+	var last string
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, fmt.Errorf("result.Next: %w", err)
+		}
+
+		if len(row) < 9 {
+			return nil, errors.New("could not retrieve signatures: insufficient data")
+		}
+
+		key := stringValue(row[0]) + "\x00" + stringValue(row[1]) + "\x00" + stringValue(row[2])
+		if len(out) == 0 || key != last {
+			out = append(out, &core.Signature{
+				Schema:  stringValue(row[0]),
+				Name:    stringValue(row[1]),
+				Returns: stringValue(row[7]),
+				Comment: stringValue(row[8]),
+			})
+			last = key
+		}
+
+		if intValue(row[3]) > 0 {
+			signature := out[len(out)-1]
+			signature.Params = append(signature.Params, &core.SignatureParam{
+				Name: stringValue(row[4]),
+				Type: stringValue(row[5]),
+				Mode: core.ParamModeFromString(stringValue(row[6])),
+			})
+		}
+	}
+
+	return out, nil
+}
+
+// SignaturesFromQuery executes the query and converts the result to signatures
+// (see SignaturesFromResultStream).
+func (c *Client) SignaturesFromQuery(ctx context.Context, query string, args ...any) ([]*core.Signature, error) {
+	result, err := c.QueryArgs(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return SignaturesFromResultStream(result)
+}
@@ -0,0 +1,53 @@
+package builders_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestSignaturesFromResultStream(t *testing.T) {
+	r := require.New(t)
+
+	rows := mock.NewResultStream([]core.Row{
+		{"public", "add", "16401", int64(1), "a", "integer", "in", "integer", "adds numbers"},
+		{"public", "add", "16401", int64(2), "b", "integer", "in", "integer", "adds numbers"},
+		{"public", "add", "16402", int64(1), "", "numeric", "in", "numeric", ""},
+		{"public", "add", "16402", int64(2), "total", "numeric", "out", "numeric", ""},
+		{"public", "now_utc", "16403", int64(0), "", "", "in", "timestamp", ""},
+	})
+
+	signatures, err := builders.SignaturesFromResultStream(rows)
+	r.NoError(err)
+	r.Equal([]*core.Signature{
+		{
+			Schema: "public",
+			Name:   "add",
+			Params: []*core.SignatureParam{
+				{Name: "a", Type: "integer"},
+				{Name: "b", Type: "integer"},
+			},
+			Returns: "integer",
+			Comment: "adds numbers",
+		},
+		{
+			Schema: "public",
+			Name:   "add",
+			Params: []*core.SignatureParam{
+				{Type: "numeric"},
+				{Name: "total", Type: "numeric", Mode: core.ParamModeOut},
+			},
+			Returns: "numeric",
+		},
+		{Schema: "public", Name: "now_utc", Returns: "timestamp"},
+	}, signatures)
+	r.Equal("public.add(a integer, b integer) -> integer", signatures[0].String())
+	r.Equal("public.add(numeric, out total numeric) -> numeric", signatures[1].String())
+
+	_, err = builders.SignaturesFromResultStream(mock.NewResultStream([]core.Row{{"public", "add"}}))
+	r.Error(err)
+}
@@ -0,0 +1,54 @@
+package builders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// SchemasFromResultStream converts the result stream to schema layout nodes
+// without children (see core.StructureLoader).
+// A result stream should return rows that are at least 1 column wide:
+//
+//	1st elem: schema - string
+func SchemasFromResultStream(rows core.ResultStream) ([]*core.Structure, error) {
+	var out []*core.Structure
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, fmt.Errorf("result.Next: %w", err)
+		}
+
+		if len(row) < 1 {
+			return nil, errors.New("could not retrieve schema info: insufficient data")
+		}
+
+		schema, ok := textValue(row[0])
+		if !ok {
+			return nil, errors.New("could not retrieve schema info: schema not a string")
+		}
+
+		out = append(out, &core.Structure{
+			Name:   schema,
+			Schema: schema,
+			Type:   core.StructureTypeNone,
+		})
+	}
+
+	return out, nil
+}
+
+// SchemasFromQuery executes the query and converts the result to schema
+// layout nodes (see SchemasFromResultStream).
+func (c *Client) SchemasFromQuery(ctx context.Context, query string, args ...any) ([]*core.Structure, error) {
+	result, err := c.QueryArgs(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return SchemasFromResultStream(result)
+}
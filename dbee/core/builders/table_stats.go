@@ -0,0 +1,85 @@
+package builders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// TableStatsFromResultStream converts the result stream to table statistics.
+// A result stream should return a row per table, at least 4 columns wide:
+//
+//	1st elem: schema - string
+//	2nd elem: table - string
+//	3rd elem: estimated row count - number (NULL if unknown)
+//	4th elem: size in bytes - number (NULL if unknown)
+func TableStatsFromResultStream(rows core.ResultStream) ([]*core.TableStats, error) {
+	var out []*core.TableStats
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, fmt.Errorf("result.Next: %w", err)
+		}
+
+		if len(row) < 4 {
+			return nil, errors.New("could not retrieve table statistics: insufficient data")
+		}
+
+		out = append(out, &core.TableStats{
+			Schema: stringValue(row[0]),
+			Table:  stringValue(row[1]),
+			Rows:   intValue(row[2]),
+			Size:   intValue(row[3]),
+		})
+	}
+
+	return out, nil
+}
+
+// TableStatsFromQuery executes the query and converts the result to table
+// statistics (see TableStatsFromResultStream).
+func (c *Client) TableStatsFromQuery(ctx context.Context, query string, args ...any) ([]*core.TableStats, error) {
+	result, err := c.QueryArgs(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return TableStatsFromResultStream(result)
+}
+
+// intValue converts a catalog value to int64. NULL and values which can't be
+// converted are returned as -1.
+func intValue(val any) int64 {
+	switch v := val.(type) {
+	case int64:
+		return v
+	case int32:
+		return int64(v)
+	case int:
+		return int64(v)
+	case uint64:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case float64:
+		return int64(v)
+	case float32:
+		return int64(v)
+	case nil:
+		return -1
+	default:
+		s := stringValue(val)
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return int64(f)
+		}
+		return -1
+	}
+}
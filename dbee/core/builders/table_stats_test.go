@@ -0,0 +1,32 @@
+package builders_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestTableStatsFromResultStream(t *testing.T) {
+	r := require.New(t)
+
+	rows := mock.NewResultStream([]core.Row{
+		{"public", "users", int64(1200), int64(65536)},
+		{"public", "events", uint64(3), []byte("8192")},
+		{"public", "fresh", nil, "1.6e4"},
+	})
+
+	stats, err := builders.TableStatsFromResultStream(rows)
+	r.NoError(err)
+	r.Equal([]*core.TableStats{
+		{Schema: "public", Table: "users", Rows: 1200, Size: 65536},
+		{Schema: "public", Table: "events", Rows: 3, Size: 8192},
+		{Schema: "public", Table: "fresh", Rows: -1, Size: 16000},
+	}, stats)
+
+	_, err = builders.TableStatsFromResultStream(mock.NewResultStream([]core.Row{{"public", "users"}}))
+	r.Error(err)
+}
@@ -0,0 +1,160 @@
+package builders
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+var (
+	_ core.Transaction       = (*Tx)(nil)
+	_ core.TransactionExecer = (*Tx)(nil)
+)
+
+// SavepointSyntax holds format strings of savepoint statements.
+// Savepoint name is passed to them as the only argument.
+type SavepointSyntax struct {
+	Create     string
+	RollbackTo string
+}
+
+// DefaultSavepointSyntax is the standard sql syntax for savepoints.
+var DefaultSavepointSyntax = SavepointSyntax{
+	Create:     "SAVEPOINT %s",
+	RollbackTo: "ROLLBACK TO SAVEPOINT %s",
+}
+
+// Tx is a transaction started by Client.
+type Tx struct {
+	tx     *sql.Tx
+	conn   *sql.Conn
+	client *Client
+}
+
+// BeginTx starts a new transaction. The transaction holds a single connection
+// until it's committed or rolled back.
+func (c *Client) BeginTx(ctx context.Context) (*Tx, error) {
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("c.db.Conn: %w", err)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return &Tx{
+		tx:     tx,
+		conn:   conn,
+		client: c,
+	}, nil
+}
+
+// Query executes the query in the transaction. Data-modifying statements without
+// a RETURNING (or OUTPUT) clause return the number of affected rows, the same as
+// Client.Exec. Server notices are collected into Meta's notices.
+func (t *Tx) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	meta := &core.Meta{}
+	detach, err := t.client.attachNotices(t.conn, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	if !returnsRows(query) {
+		res, err := t.tx.ExecContext(ctx, query)
+		detach()
+		if err != nil {
+			return nil, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+
+		return NewResultStreamBuilder().
+			WithNextFunc(NextSingle(affected)).
+			WithHeader(core.Header{"Rows Affected"}).
+			WithMeta(meta).
+			Build(), nil
+	}
+
+	rows, err := t.tx.QueryContext(ctx, query)
+	if err != nil {
+		detach()
+		return nil, err
+	}
+
+	result, err := t.client.parseRows(rows)
+	if err != nil {
+		detach()
+		return nil, err
+	}
+
+	// statements without results (e.g. DDL)
+	if len(result.Header()) < 1 {
+		result.Close()
+		detach()
+		return NewResultStreamBuilder().
+			WithNextFunc(NextNil()).
+			WithHeader(core.Header{"No Results"}).
+			WithMeta(meta).
+			Build(), nil
+	}
+
+	result.meta = meta
+	result.AddCallback(detach)
+	return result, nil
+}
+
+// returnsRows reports whether the statement is expected to return rows. Data
+// modifying statements return them only with a RETURNING (or OUTPUT) clause.
+func returnsRows(query string) bool {
+	fields := strings.Fields(strings.ToLower(query))
+	if len(fields) < 1 {
+		return true
+	}
+
+	switch fields[0] {
+	case "insert", "update", "delete", "merge", "replace":
+		for _, field := range fields[1:] {
+			if field == "returning" || field == "output" {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (t *Tx) Exec(ctx context.Context, query string) (int64, error) {
+	res, err := t.tx.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (t *Tx) Savepoint(ctx context.Context, name string) error {
+	_, err := t.tx.ExecContext(ctx, fmt.Sprintf(t.client.savepointSyntax.Create, name))
+	return err
+}
+
+func (t *Tx) RollbackTo(ctx context.Context, name string) error {
+	_, err := t.tx.ExecContext(ctx, fmt.Sprintf(t.client.savepointSyntax.RollbackTo, name))
+	return err
+}
+
+func (t *Tx) Commit() error {
+	defer t.conn.Close()
+	return t.tx.Commit()
+}
+
+func (t *Tx) Rollback() error {
+	defer t.conn.Close()
+	return t.tx.Rollback()
+}
@@ -0,0 +1,56 @@
+package builders_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+func TestTx_Query(t *testing.T) {
+	r := require.New(t)
+
+	db, err := sql.Open("dbee_procedure_test", "")
+	r.NoError(err)
+	defer db.Close()
+
+	client := builders.NewClient(db,
+		builders.WithDefaultTypeProcessor(func(val any) any {
+			return fmt.Sprint(val)
+		}),
+		builders.WithNoticeHook(func(_ *sql.Conn, onNotice func(string)) (func(), error) {
+			onNotice("NOTICE: hello")
+			return nil, nil
+		}),
+	)
+
+	tx, err := client.BeginTx(context.Background())
+	r.NoError(err)
+	defer tx.Rollback()
+
+	// data-modifying statements return the number of affected rows
+	result, err := tx.Query(context.Background(), "UPDATE t SET a = 1")
+	r.NoError(err)
+	r.Equal(core.Header{"Rows Affected"}, result.Header())
+	row, err := result.Next()
+	r.NoError(err)
+	r.Equal(core.Row{int64(2)}, row)
+	r.Equal([]string{"NOTICE: hello"}, result.Meta().Notices)
+
+	result, err = tx.Query(context.Background(), "CREATE TABLE t (a int)")
+	r.NoError(err)
+	r.Equal(core.Header{"No Results"}, result.Header())
+	r.Equal([]string{"NOTICE: hello"}, result.Meta().Notices)
+
+	result, err = tx.Query(context.Background(), "SELECT @x")
+	r.NoError(err)
+	r.Equal([]core.Header{{"@x", "1"}}, readSets(t, result))
+	r.Equal([]string{"NOTICE: hello"}, result.Meta().Notices)
+
+	r.NoError(tx.Commit())
+}
@@ -0,0 +1,69 @@
+package builders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// TypesFromResultStream converts the result stream to user-defined type layout
+// nodes (see core.TypeLister). A result stream should return a row per enum
+// value (or a single row for other types), in value order, at least 4 columns wide:
+//
+//	1st elem: schema - string
+//	2nd elem: type name - string
+//	3rd elem: kind - string (e.g. "enum" or "domain")
+//	4th elem: enum value - string (NULL for other types)
+func TypesFromResultStream(rows core.ResultStream) ([]*core.Structure, error) {
+	var out []*core.Structure
+	byName := make(map[string]*core.Structure)
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, fmt.Errorf("result.Next: %w", err)
+		}
+
+		if len(row) < 4 {
+			return nil, errors.New("could not retrieve type info: insufficient data")
+		}
+
+		schema, name := stringValue(row[0]), stringValue(row[1])
+		key := schema + "." + name
+		typ, ok := byName[key]
+		if !ok {
+			typ = &core.Structure{
+				Name:   name,
+				Schema: schema,
+				Type:   core.StructureTypeType,
+				Status: stringValue(row[2]),
+			}
+			byName[key] = typ
+			out = append(out, typ)
+		}
+
+		if row[3] != nil {
+			typ.Children = append(typ.Children, &core.Structure{
+				Name:   stringValue(row[3]),
+				Schema: schema,
+				Type:   core.StructureTypeEnumValue,
+			})
+		}
+	}
+
+	return out, nil
+}
+
+// TypesFromQuery executes the query and converts the result to user-defined
+// type layout nodes (see TypesFromResultStream).
+func (c *Client) TypesFromQuery(ctx context.Context, query string, args ...any) ([]*core.Structure, error) {
+	result, err := c.QueryArgs(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return TypesFromResultStream(result)
+}
@@ -0,0 +1,35 @@
+package builders_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestTypesFromResultStream(t *testing.T) {
+	r := require.New(t)
+
+	rows := mock.NewResultStream([]core.Row{
+		{"public", "mood", "enum", "sad"},
+		{"public", "mood", "enum", "happy"},
+		{"public", "email", "domain of text", nil},
+	})
+
+	types, err := builders.TypesFromResultStream(rows)
+	r.NoError(err)
+	r.Len(types, 2)
+
+	r.Equal("mood", types[0].Name)
+	r.Equal(core.StructureTypeType, types[0].Type)
+	r.Equal("enum", types[0].Status)
+	r.Len(types[0].Children, 2)
+	r.Equal("happy", types[0].Children[1].Name)
+	r.Equal(core.StructureTypeEnumValue, types[0].Children[1].Type)
+
+	r.Equal("domain of text", types[1].Status)
+	r.Empty(types[1].Children)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,8 +21,10 @@ type (
 		timeTaken time.Duration
 		timestamp time.Time
 
-		result     *Result
-		archive    *archive
+		// every result set has its own result and archive
+		results    []*Result
+		archives   []*archive
+		resultsMu  sync.Mutex
 		cancelFunc func()
 
 		// any error that might occur during execution
@@ -38,21 +41,58 @@ type callPersistent struct {
 	TimeTaken int64  `json:"time_taken_us"`
 	Timestamp int64  `json:"timestamp_us"`
 	Error     string `json:"error,omitempty"`
+	// details of the error (if it's a QueryError)
+	ErrorInfo *queryErrorPersistent `json:"error_info,omitempty"`
+	// number of result sets (0 is the same as 1 for backwards compatibility)
+	ResultSets int `json:"result_sets,omitempty"`
+}
+
+// queryErrorPersistent is used for marshaling and unmarshaling details of QueryError
+type queryErrorPersistent struct {
+	Category string `json:"category"`
+	Code     string `json:"code,omitempty"`
+	Position int    `json:"position,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Hint     string `json:"hint,omitempty"`
+
+	Detail     string `json:"detail,omitempty"`
+	ObjectKind string `json:"object_kind,omitempty"`
+	Object     string `json:"object,omitempty"`
 }
 
 func (c *Call) toPersistent() *callPersistent {
 	errMsg := ""
+	var errInfo *queryErrorPersistent
 	if c.err != nil {
 		errMsg = c.err.Error()
+
+		var qErr *QueryError
+		if errors.As(c.err, &qErr) {
+			errInfo = &queryErrorPersistent{
+				Category: string(qErr.Category),
+				Code:     qErr.Code,
+				Position: qErr.Position,
+				Line:     qErr.Line,
+				Column:   qErr.Column,
+				Hint:     qErr.Hint,
+
+				Detail:     qErr.Detail,
+				ObjectKind: qErr.ObjectKind,
+				Object:     qErr.Object,
+			}
+		}
 	}
 
 	return &callPersistent{
-		ID:        string(c.id),
-		Query:     c.query,
-		State:     c.state.String(),
-		TimeTaken: c.timeTaken.Microseconds(),
-		Timestamp: c.timestamp.UnixMicro(),
-		Error:     errMsg,
+		ID:         string(c.id),
+		Query:      c.query,
+		State:      c.state.String(),
+		TimeTaken:  c.timeTaken.Microseconds(),
+		Timestamp:  c.timestamp.UnixMicro(),
+		Error:      errMsg,
+		ErrorInfo:  errInfo,
+		ResultSets: c.ResultSetCount(),
 	}
 }
 
@@ -70,9 +110,24 @@ func (c *Call) UnmarshalJSON(data []byte) error {
 	done := make(chan struct{})
 	close(done)
 
-	archive := newArchive(CallID(alias.ID))
+	sets := alias.ResultSets
+	if sets < 1 {
+		sets = 1
+	}
+
+	results := make([]*Result, sets)
+	archives := make([]*archive, sets)
+	for i := range results {
+		results[i] = new(Result)
+		archives[i] = newArchive(CallID(alias.ID), i)
+	}
+
 	state := CallStateFromString(alias.State)
-	if state == CallStateArchived && archive.isEmpty() {
+	switch {
+	case state == CallStateArchived && archives[0].isEmpty():
+		state = CallStateUnknown
+	case state == CallStateExecuting || state == CallStateRetrieving:
+		// the backend stopped before the call finished
 		state = CallStateUnknown
 	}
 
@@ -80,6 +135,21 @@ func (c *Call) UnmarshalJSON(data []byte) error {
 	if alias.Error != "" {
 		callErr = errors.New(alias.Error)
 	}
+	if info := alias.ErrorInfo; callErr != nil && info != nil {
+		callErr = &QueryError{
+			Category: ErrorCategory(info.Category),
+			Code:     info.Code,
+			Position: info.Position,
+			Line:     info.Line,
+			Column:   info.Column,
+			Hint:     info.Hint,
+
+			Detail:     info.Detail,
+			ObjectKind: info.ObjectKind,
+			Object:     info.Object,
+			Err:        callErr,
+		}
+	}
 
 	*c = Call{
 		id:        CallID(alias.ID),
@@ -89,8 +159,8 @@ func (c *Call) UnmarshalJSON(data []byte) error {
 		timestamp: time.UnixMicro(alias.Timestamp),
 		err:       callErr,
 
-		result:  new(Result),
-		archive: newArchive(CallID(alias.ID)),
+		results:  results,
+		archives: archives,
 
 		done: done,
 	}
@@ -98,6 +168,14 @@ func (c *Call) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+type callIDKey struct{}
+
+// callIDFromContext returns the ID of the call, whose executor received the context.
+func callIDFromContext(ctx context.Context) CallID {
+	id, _ := ctx.Value(callIDKey{}).(CallID)
+	return id
+}
+
 func newCallFromExecutor(executor func(context.Context) (ResultStream, error), query string, onEvent func(CallState, *Call)) *Call {
 	id := CallID(uuid.New().String())
 	c := &Call{
@@ -105,20 +183,32 @@ func newCallFromExecutor(executor func(context.Context) (ResultStream, error), q
 		query: query,
 		state: CallStateUnknown,
 
-		result:  new(Result),
-		archive: newArchive(id),
+		results:  []*Result{new(Result)},
+		archives: []*archive{newArchive(id, 0)},
 
 		done: make(chan struct{}),
 	}
 
 	eventsCh := make(chan CallState, 10)
 
-	ctx, cancel := context.WithCancel(context.Background())
+	// events can be sent by cancel after the executor is done,
+	// so sends and closing of the channel are guarded
+	var eventsMu sync.Mutex
+	eventsClosed := false
+	sendEvent := func(state CallState) {
+		eventsMu.Lock()
+		defer eventsMu.Unlock()
+		if !eventsClosed {
+			eventsCh <- state
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), callIDKey{}, id))
 	c.timestamp = time.Now()
 	c.cancelFunc = func() {
 		cancel()
 		c.timeTaken = time.Since(c.timestamp)
-		eventsCh <- CallStateCanceled
+		sendEvent(CallStateCanceled)
 	}
 
 	// event function handler
@@ -139,41 +229,80 @@ func newCallFromExecutor(executor func(context.Context) (ResultStream, error), q
 	}()
 
 	go func() {
-		defer close(eventsCh)
+		defer func() {
+			eventsMu.Lock()
+			eventsClosed = true
+			close(eventsCh)
+			eventsMu.Unlock()
+		}()
 
 		// execute the function
-		eventsCh <- CallStateExecuting
+		sendEvent(CallStateExecuting)
 		iter, err := executor(ctx)
+		executionTime := time.Since(c.timestamp)
 		if err != nil {
 			c.timeTaken = time.Since(c.timestamp)
 			c.err = err
-			eventsCh <- CallStateExecutingFailed
+			sendEvent(CallStateExecutingFailed)
 			close(c.done)
 			return
 		}
 
-		// set iterator to result
-		err = c.result.SetIter(iter, func() { eventsCh <- CallStateRetrieving })
-		if err != nil {
-			c.timeTaken = time.Since(c.timestamp)
-			c.err = err
-			eventsCh <- CallStateRetrievingFailed
-			close(c.done)
-			return
+		meta := iter.Meta()
+		if meta != nil {
+			meta.Metrics.Execution = executionTime
 		}
 
-		// archive the result
-		err = c.archive.setResult(c.result)
-		if err != nil {
-			c.timeTaken = time.Since(c.timestamp)
-			c.err = err
-			eventsCh <- CallStateArchiveFailed
-			close(c.done)
-			return
+		// streams with multiple result sets are drained set by set,
+		// so they have to stay open until the last one is read
+		multi, isMulti := iter.(MultiResultStream)
+		if isMulti {
+			defer iter.Close()
+		}
+
+		for set := 0; ; set++ {
+			result, archive := c.resultSet(set)
+
+			var stream ResultStream = iter
+			if isMulti {
+				stream = &resultSetStream{ResultStream: iter}
+			}
+
+			// set iterator to result
+			var onFillStart func()
+			if set == 0 {
+				onFillStart = func() { sendEvent(CallStateRetrieving) }
+			}
+			fetchStart := time.Now()
+			err = result.SetIter(stream, onFillStart)
+			if meta != nil {
+				meta.Metrics.Fetch += time.Since(fetchStart)
+			}
+			if err != nil {
+				c.timeTaken = time.Since(c.timestamp)
+				c.err = err
+				sendEvent(CallStateRetrievingFailed)
+				close(c.done)
+				return
+			}
+
+			// archive the result
+			err = archive.setResult(ctx, result)
+			if err != nil {
+				c.timeTaken = time.Since(c.timestamp)
+				c.err = err
+				sendEvent(CallStateArchiveFailed)
+				close(c.done)
+				return
+			}
+
+			if !isMulti || !multi.NextResultSet() {
+				break
+			}
 		}
 
 		c.timeTaken = time.Since(c.timestamp)
-		eventsCh <- CallStateArchived
+		sendEvent(CallStateArchived)
 		close(c.done)
 	}()
 
@@ -210,26 +339,85 @@ func (c *Call) Done() chan struct{} {
 	return c.done
 }
 
+// Cancel stops the call, whether it's executing, retrieving rows or
+// writing the result to history. Finished calls are left as they are.
 func (c *Call) Cancel() {
-	if c.state > CallStateExecuting {
+	select {
+	case <-c.done:
 		return
+	default:
 	}
 	if c.cancelFunc != nil {
 		c.cancelFunc()
 	}
 }
 
+// GetResult returns the first result set of the call.
 func (c *Call) GetResult() (*Result, error) {
-	if c.result.IsEmpty() {
-		iter, err := c.archive.getResult()
+	return c.GetResultSet(0)
+}
+
+// GetResultSet returns the n-th result set of the call.
+func (c *Call) GetResultSet(set int) (*Result, error) {
+	c.resultsMu.Lock()
+	if set < 0 || set >= len(c.results) {
+		c.resultsMu.Unlock()
+		return nil, fmt.Errorf("result set %d does not exist", set)
+	}
+	result, archive := c.results[set], c.archives[set]
+	c.resultsMu.Unlock()
+
+	if result.IsEmpty() {
+		iter, err := archive.getResult()
 		if err != nil {
-			return nil, fmt.Errorf("c.archive.getResult: %w", err)
+			return nil, fmt.Errorf("archive.getResult: %w", err)
 		}
-		err = c.result.SetIter(iter, nil)
+		err = result.SetIter(iter, nil)
 		if err != nil {
-			return nil, fmt.Errorf("c.result.setIter: %w", err)
+			return nil, fmt.Errorf("result.setIter: %w", err)
 		}
 	}
 
-	return c.result, nil
+	return result, nil
+}
+
+// ResultSetCount returns the number of result sets the call produced so far.
+func (c *Call) ResultSetCount() int {
+	c.resultsMu.Lock()
+	defer c.resultsMu.Unlock()
+	return len(c.results)
+}
+
+// CachedRows returns the number of rows of the call held in memory.
+// Rows of archived results are not counted until they are read again.
+func (c *Call) CachedRows() int {
+	c.resultsMu.Lock()
+	defer c.resultsMu.Unlock()
+
+	rows := 0
+	for _, res := range c.results {
+		rows += res.Len()
+	}
+	return rows
 }
+
+// resultSet returns the result and archive of the n-th set, creating them if needed.
+func (c *Call) resultSet(set int) (*Result, *archive) {
+	c.resultsMu.Lock()
+	defer c.resultsMu.Unlock()
+
+	for len(c.results) <= set {
+		c.results = append(c.results, new(Result))
+		c.archives = append(c.archives, newArchive(c.id, len(c.archives)))
+	}
+
+	return c.results[set], c.archives[set]
+}
+
+// resultSetStream exposes a single set of a multi set stream. Closing it
+// doesn't close the underlying stream.
+type resultSetStream struct {
+	ResultStream
+}
+
+func (*resultSetStream) Close() {}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"encoding/gob"
 	"errors"
 	"fmt"
@@ -17,38 +18,51 @@ func init() {
 	gob.Register(time.Time{})
 }
 
-const archiveBasePath = "/tmp/dbee-history/"
+// archiveBasePath is the directory of archived results of all calls.
+var archiveBasePath = filepath.Join(StorageDir(), "dbee-history")
 
 // these variables create a file name for a specified type
 var (
 	archiveDir = func(callID CallID) string {
 		return filepath.Join(archiveBasePath, string(callID))
 	}
+	// archiveSetDir is the directory of the n-th result set.
+	// The first set is stored directly in the call's directory.
+	archiveSetDir = func(callID CallID, set int) string {
+		if set == 0 {
+			return archiveDir(callID)
+		}
+		return filepath.Join(archiveDir(callID), fmt.Sprintf("set_%d", set))
+	}
 
-	metaFile = func(callID CallID) string {
-		return filepath.Join(archiveDir(callID), "meta.gob")
+	metaFile = func(dir string) string {
+		return filepath.Join(dir, "meta.gob")
 	}
-	headerFile = func(callID CallID) string {
-		return filepath.Join(archiveDir(callID), "header.gob")
+	headerFile = func(dir string) string {
+		return filepath.Join(dir, "header.gob")
 	}
-	rowFile = func(callID CallID, i int) string {
-		return filepath.Join(archiveDir(callID), fmt.Sprintf("row_%d.gob", i))
+	rowFile = func(dir string, i int) string {
+		return filepath.Join(dir, fmt.Sprintf("row_%d.gob", i))
 	}
 )
 
 type archive struct {
-	id       CallID
+	dir      string
 	isFilled bool
 }
 
-func newArchive(id CallID) *archive {
+// newArchive creates an archive for the n-th result set of a call.
+func newArchive(id CallID, set int) *archive {
+	dir := archiveSetDir(id, set)
+
+	// meta is written last, so archives interrupted by a crash don't have it
 	isFilled := true
-	_, err := os.Stat(archiveDir(id))
+	_, err := os.Stat(metaFile(dir))
 	if os.IsNotExist(err) {
 		isFilled = false
 	}
 	return &archive{
-		id:       id,
+		dir:      dir,
 		isFilled: isFilled,
 	}
 }
@@ -57,14 +71,51 @@ func (a *archive) isEmpty() bool {
 	return !a.isFilled
 }
 
-// archive stores the cache record to disk as a set of gob files
-func (a *archive) setResult(result *Result) error {
+// HistoryDiskUsage returns the size of archived results of all calls in bytes.
+func HistoryDiskUsage() (int64, error) {
+	var size int64
+	err := filepath.WalkDir(archiveBasePath, func(_ string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("filepath.WalkDir: %w", err)
+	}
+	return size, nil
+}
+
+// archive stores the cache record to disk as a set of gob files.
+// If the context is canceled, the partially written archive is removed.
+func (a *archive) setResult(ctx context.Context, result *Result) (err error) {
 	if a.isFilled {
 		return nil
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil && ctx.Err() != nil {
+			_ = os.RemoveAll(a.dir)
+		}
+	}()
+
+	start := time.Now()
 
 	// create the directory for the history record
-	err := os.MkdirAll(archiveDir(a.id), os.ModePerm)
+	err = os.MkdirAll(a.dir, os.ModePerm)
 	if err != nil {
 		return fmt.Errorf("os.MkdirAll: %w", err)
 	}
@@ -75,9 +126,10 @@ func (a *archive) setResult(result *Result) error {
 	// meta.gob - meta
 	// row_0.gob - first row
 	// row_n.gob - n-th row
+	// set_n/ - n-th result set (same layout as above)
 
 	// header
-	file, err := os.Create(headerFile(a.id))
+	file, err := os.Create(headerFile(a.dir))
 	if err != nil {
 		return fmt.Errorf("os.Create: %w", err)
 	}
@@ -89,19 +141,6 @@ func (a *archive) setResult(result *Result) error {
 		return fmt.Errorf("encoder.Encode: %w", err)
 	}
 
-	// meta
-	file, err = os.Create(metaFile(a.id))
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	encoder = gob.NewEncoder(file)
-	err = encoder.Encode(*result.Meta())
-	if err != nil {
-		return err
-	}
-
 	// rows
 	chunkSize := 500
 	length := len(result.rows)
@@ -112,6 +151,10 @@ func (a *archive) setResult(result *Result) error {
 	for i := 0; i <= length/chunkSize; i++ {
 		i := i
 		g.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
 			// get chunk
 			chunkStart := chunkSize * i
 			chunkEnd := chunkSize * (i + 1)
@@ -126,7 +169,7 @@ func (a *archive) setResult(result *Result) error {
 				return nil
 			}
 
-			file, err := os.Create(rowFile(a.id, i))
+			file, err := os.Create(rowFile(a.dir, i))
 			if err != nil {
 				return fmt.Errorf("os.Create: %w", err)
 			}
@@ -145,6 +188,24 @@ func (a *archive) setResult(result *Result) error {
 		return err
 	}
 
+	// meta is written last, so that it includes the archiving time
+	if meta := result.Meta(); meta != nil {
+		meta.Metrics.Archive += time.Since(start)
+	}
+
+	// meta
+	file, err = os.Create(metaFile(a.dir))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder = gob.NewEncoder(file)
+	err = encoder.Encode(*result.Meta())
+	if err != nil {
+		return err
+	}
+
 	a.isFilled = true
 
 	return nil
@@ -155,20 +216,20 @@ func (a *archive) getResult() (*archiveRows, error) {
 	if !a.isFilled {
 		return nil, errors.New("archive does not contain a result")
 	}
-	return newArchiveRows(a.id)
+	return newArchiveRows(a.dir)
 }
 
 type archiveRows struct {
-	id      CallID
+	dir     string
 	header  Header
 	meta    *Meta
 	iter    func() (Row, error)
 	hasNext func() bool
 }
 
-func newArchiveRows(id CallID) (*archiveRows, error) {
+func newArchiveRows(dir string) (*archiveRows, error) {
 	r := &archiveRows{
-		id: id,
+		dir: dir,
 	}
 
 	err := r.readHeader()
@@ -188,7 +249,7 @@ func newArchiveRows(id CallID) (*archiveRows, error) {
 func (r *archiveRows) readHeader() error {
 	// header
 	var header Header
-	file, err := os.Open(headerFile(r.id))
+	file, err := os.Open(headerFile(r.dir))
 	if err != nil {
 		return fmt.Errorf("os.Open: %w", err)
 	}
@@ -208,7 +269,7 @@ func (r *archiveRows) readHeader() error {
 func (r *archiveRows) readMeta() error {
 	// meta
 	var meta Meta
-	file, err := os.Open(metaFile(r.id))
+	file, err := os.Open(metaFile(r.dir))
 	if err != nil {
 		return fmt.Errorf("os.Open: %w", err)
 	}
@@ -241,13 +302,13 @@ func (r *archiveRows) readIter() {
 	// open the first file if it exists,
 	// loop through its contents and try the next file
 	fileExists := func(rowIndex int) bool {
-		_, err := os.Stat(rowFile(r.id, rowIndex))
+		_, err := os.Stat(rowFile(r.dir, rowIndex))
 		return err == nil
 	}
 
 	// openFile returns rows of the file
 	openFile := func(i int) ([]Row, error) {
-		file, err := os.Open(rowFile(r.id, i))
+		file, err := os.Open(rowFile(r.dir, i))
 		if err != nil {
 			return nil, fmt.Errorf("os.Open: %w", err)
 		}
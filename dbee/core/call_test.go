@@ -106,6 +106,43 @@ func TestCall_Cancel(t *testing.T) {
 	r.Equal(len(expectedEvents), eventIndex)
 }
 
+func TestCall_CancelWhileRetrieving(t *testing.T) {
+	r := require.New(t)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(mock.NewRows(0, 10),
+		mock.AdapterWithResultStreamOpts(mock.ResultStreamWithNextSleep(100*time.Millisecond)),
+	))
+	r.NoError(err)
+
+	retrieving := make(chan struct{})
+	call := connection.Execute("_", func(state core.CallState, c *core.Call) {
+		if state == core.CallStateRetrieving {
+			close(retrieving)
+		}
+	})
+
+	select {
+	case <-retrieving:
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not start retrieving in expected time")
+	}
+	call.Cancel()
+
+	select {
+	case <-call.Done():
+		// wait a bit for state to stabilize
+		time.Sleep(100 * time.Millisecond)
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+
+	r.Equal(core.CallStateCanceled, call.GetState())
+
+	// canceling a finished call does nothing
+	call.Cancel()
+	r.Equal(core.CallStateCanceled, call.GetState())
+}
+
 func TestCall_FailedQuery(t *testing.T) {
 	r := require.New(t)
 
@@ -194,3 +231,83 @@ func TestCall_Archive(t *testing.T) {
 	r.NoError(err)
 	r.Equal(rows, actualRows)
 }
+
+func TestCall_MultipleResultSets(t *testing.T) {
+	r := require.New(t)
+
+	sets := [][]core.Row{
+		mock.NewRows(0, 10),
+		mock.NewRows(10, 15),
+		mock.NewRows(15, 30),
+	}
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(sets[0],
+		mock.AdapterWithResultStreamOpts(mock.ResultStreamWithExtraSets(sets[1:]...)),
+	))
+	r.NoError(err)
+
+	call := connection.Execute("_", nil)
+
+	// wait for call to finish
+	select {
+	case <-call.Done():
+		// wait a bit for state to stabilize
+		time.Sleep(100 * time.Millisecond)
+	case <-time.After(5 * time.Second):
+		t.Error("call did not finish in expected time")
+	}
+	r.Equal(core.CallStateArchived, call.GetState())
+	r.Equal(len(sets), call.ResultSetCount())
+
+	// marshal to json and back
+	b, err := json.Marshal(call)
+	r.NoError(err)
+	restoredCall := new(core.Call)
+	err = json.Unmarshal(b, restoredCall)
+	r.NoError(err)
+	r.Equal(len(sets), restoredCall.ResultSetCount())
+
+	for _, c := range []*core.Call{call, restoredCall} {
+		for i, rows := range sets {
+			result, err := c.GetResultSet(i)
+			r.NoError(err)
+			actualRows, err := result.Rows(0, len(rows))
+			r.NoError(err)
+			r.Equal(rows, actualRows)
+		}
+
+		_, err = c.GetResultSet(len(sets))
+		r.Error(err)
+	}
+}
+
+func TestCall_Metrics(t *testing.T) {
+	r := require.New(t)
+
+	rows := mock.NewRows(0, 3)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(rows,
+		mock.AdapterWithQuerySideEffect("_", func(context.Context) error {
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		}),
+		mock.AdapterWithResultStreamOpts(mock.ResultStreamWithNextSleep(50*time.Millisecond)),
+	))
+	r.NoError(err)
+
+	call := connection.Execute("_", nil)
+
+	select {
+	case <-call.Done():
+	case <-time.After(5 * time.Second):
+		t.Error("call did not finish in expected time")
+	}
+
+	result, err := call.GetResult()
+	r.NoError(err)
+
+	metrics := result.Meta().Metrics
+	r.GreaterOrEqual(metrics.Execution, 100*time.Millisecond)
+	r.GreaterOrEqual(metrics.Fetch, 150*time.Millisecond)
+	r.Greater(metrics.Archive, time.Duration(0))
+}
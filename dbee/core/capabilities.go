@@ -0,0 +1,105 @@
+package core
+
+import "sort"
+
+// Capabilities of connections, named after the optional driver interfaces.
+const (
+	CapabilityCancel            = "cancel"
+	CapabilityStreaming         = "streaming"
+	CapabilityTransactions      = "transactions"
+	CapabilityDatabaseSwitching = "database_switching"
+	CapabilityLazyStructure     = "lazy_structure"
+	CapabilityIndexes           = "indexes"
+	CapabilityTriggers          = "triggers"
+	CapabilityDefinitions       = "definitions"
+	CapabilityRoutines          = "routines"
+	CapabilitySignatures        = "signatures"
+	CapabilitySearch            = "search"
+	CapabilityPlan              = "plan"
+	CapabilityServerInfo        = "server_info"
+	CapabilityImport            = "import"
+	CapabilityComments          = "comments"
+	CapabilityDependencies      = "dependencies"
+	CapabilityGrants            = "grants"
+	CapabilityPartitions        = "partitions"
+	CapabilityTableStats        = "table_stats"
+	CapabilityActivity          = "activity"
+	CapabilitySubscriptions     = "subscriptions"
+)
+
+// CapabilityReporter is an optional interface for drivers which know only at
+// runtime what they support (e.g. external adapters). Reported capabilities
+// override the ones discovered from implemented interfaces.
+type CapabilityReporter interface {
+	Capabilities() map[string]bool
+}
+
+// driverCapabilities discovers capabilities from interfaces implemented by
+// the driver. Queries are canceled through their context and rows are read
+// from result streams while the query runs, so all drivers support canceling
+// and streaming unless they report otherwise.
+func driverCapabilities(driver Driver) map[string]bool {
+	_, transactions := driver.(Transactor)
+	_, switching := driver.(DatabaseSwitcher)
+	_, lazy := driver.(StructureLoader)
+	_, indexes := driver.(TableInspector)
+	_, triggers := driver.(TriggerLister)
+	_, definitions := driver.(DefinitionProvider)
+	_, routines := driver.(RoutineLister)
+	_, signatures := driver.(SignatureProvider)
+	_, search := driver.(ObjectSearcher)
+	_, plan := driver.(Planner)
+	_, serverInfo := driver.(ServerInfoProvider)
+	_, importer := driver.(Importer)
+	_, comments := driver.(Commenter)
+	_, dependencies := driver.(DependencyLister)
+	_, grants := driver.(GrantLister)
+	_, partitions := driver.(PartitionLister)
+	_, stats := driver.(TableStatsProvider)
+	_, activity := driver.(ActivityMonitor)
+	_, subscriptions := driver.(Subscriber)
+
+	return map[string]bool{
+		CapabilityCancel:            true,
+		CapabilityStreaming:         true,
+		CapabilityTransactions:      transactions,
+		CapabilityDatabaseSwitching: switching,
+		CapabilityLazyStructure:     lazy,
+		CapabilityIndexes:           indexes,
+		CapabilityTriggers:          triggers,
+		CapabilityDefinitions:       definitions,
+		CapabilityRoutines:          routines,
+		CapabilitySignatures:        signatures,
+		CapabilitySearch:            search,
+		CapabilityPlan:              plan,
+		CapabilityServerInfo:        serverInfo,
+		CapabilityImport:            importer,
+		CapabilityComments:          comments,
+		CapabilityDependencies:      dependencies,
+		CapabilityGrants:            grants,
+		CapabilityPartitions:        partitions,
+		CapabilityTableStats:        stats,
+		CapabilityActivity:          activity,
+		CapabilitySubscriptions:     subscriptions,
+	}
+}
+
+// GetCapabilities returns sorted names of features supported by the driver of
+// the connection, so the frontend can hide actions which would fail.
+func (c *Connection) GetCapabilities() []string {
+	capabilities := driverCapabilities(c.driver)
+	if reporter, ok := c.driver.(CapabilityReporter); ok {
+		for name, supported := range reporter.Capabilities() {
+			capabilities[name] = supported
+		}
+	}
+
+	var names []string
+	for name, supported := range capabilities {
+		if supported {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,30 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_GetCapabilities(t *testing.T) {
+	r := require.New(t)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{Type: "mock"}, mock.NewAdapter(mock.NewRows(0, 1)))
+	r.NoError(err)
+
+	capabilities := connection.GetCapabilities()
+	r.IsIncreasing(capabilities)
+	r.Subset(capabilities, []string{
+		core.CapabilityCancel,
+		core.CapabilityStreaming,
+		core.CapabilityTransactions,
+		core.CapabilityDatabaseSwitching,
+		core.CapabilityDefinitions,
+		core.CapabilityIndexes,
+	})
+	r.NotContains(capabilities, core.CapabilityPlan)
+	r.NotContains(capabilities, core.CapabilitySignatures)
+}
@@ -0,0 +1,39 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrCommentsNotSupported = errors.New("setting comments not supported")
+
+// Commenter is an optional interface for drivers that can set comments
+// (descriptions) of tables, views and columns.
+type Commenter interface {
+	// SetComment sets the comment of the column of the object described by opts.
+	// If column is empty, the comment of the object itself is set. An empty
+	// comment removes the existing one.
+	SetComment(ctx context.Context, opts *TableOptions, column string, comment string) error
+}
+
+// SetComment sets the comment of the object described by opts or of its column
+// if column is not empty.
+func (c *Connection) SetComment(opts *TableOptions, column string, comment string) error {
+	commenter, ok := c.driver.(Commenter)
+	if !ok {
+		return ErrCommentsNotSupported
+	}
+	if opts == nil || opts.Table == "" {
+		return errors.New("no table provided")
+	}
+
+	err := commenter.SetComment(context.Background(), opts, column, comment)
+	if err != nil {
+		return fmt.Errorf("commenter.SetComment: %w", err)
+	}
+
+	// comments are part of the structure
+	c.RefreshStructure(nil)
+	return nil
+}
@@ -0,0 +1,40 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_SetComment(t *testing.T) {
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 1),
+		mock.AdapterWithTableDefinition("users", []*core.Column{{Name: "id", Type: "int"}}),
+	)
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	structure, err := connection.GetStructure()
+	r.NoError(err)
+	r.Empty(structure[0].Comment)
+
+	opts := &core.TableOptions{Table: "users", Materialization: core.StructureTypeTable}
+	r.NoError(connection.SetComment(opts, "", "registered users"))
+	r.NoError(connection.SetComment(opts, "id", "user id"))
+
+	// the structure is refreshed with the new comment
+	structure, err = connection.GetStructure()
+	r.NoError(err)
+	r.Equal("registered users", structure[0].Comment)
+
+	columns, err := connection.GetColumns(opts)
+	r.NoError(err)
+	r.Equal("user id", columns[0].Comment)
+
+	r.Error(connection.SetComment(nil, "", "comment"))
+	r.Error(connection.SetComment(&core.TableOptions{Table: "unknown"}, "", "comment"))
+}
@@ -0,0 +1,405 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DiffKind says how an object differs between the source and the target
+// of a schema comparison.
+type DiffKind int
+
+const (
+	// DiffKindMissing objects exist in the source only.
+	DiffKindMissing DiffKind = iota
+	// DiffKindExtra objects exist in the target only.
+	DiffKindExtra
+	// DiffKindChanged objects exist in both, but their definitions differ.
+	DiffKindChanged
+)
+
+func (k DiffKind) String() string {
+	switch k {
+	case DiffKindMissing:
+		return "missing"
+	case DiffKindExtra:
+		return "extra"
+	default:
+		return "changed"
+	}
+}
+
+type (
+	// CompareOptions limit and extend a schema comparison.
+	CompareOptions struct {
+		// schema compared on the source connection, all schemas if empty
+		SourceSchema string
+		// schema compared on the target connection, defaults to SourceSchema
+		TargetSchema string
+		// generate statements which make the target match the source
+		Migration bool
+	}
+
+	// SchemaDiff is the difference between tables of two connections.
+	SchemaDiff struct {
+		Tables []*TableDiff
+		// migration statements (if requested), they use standard ALTER TABLE
+		// syntax and should be reviewed before they are run
+		Migration []string
+	}
+
+	// TableDiff is a table which is missing, extra or changed in the target.
+	// Columns and indexes are set for changed tables only.
+	TableDiff struct {
+		Schema  string
+		Name    string
+		Kind    DiffKind
+		Columns []*ColumnDiff
+		Indexes []*IndexDiff
+	}
+
+	// ColumnDiff is a column which differs between the source and the target.
+	// Source or Target is nil for missing or extra columns.
+	ColumnDiff struct {
+		Name   string
+		Kind   DiffKind
+		Source *Column
+		Target *Column
+	}
+
+	// IndexDiff is an index which differs between the source and the target.
+	// Source or Target is nil for missing or extra indexes.
+	IndexDiff struct {
+		Name   string
+		Kind   DiffKind
+		Source *Index
+		Target *Index
+	}
+)
+
+// comparedTable is a table collected for comparison.
+type comparedTable struct {
+	opts    *TableOptions
+	columns []*Column
+	// nil if the driver can't list indexes
+	indexes []*Index
+}
+
+// CompareSchemas compares tables, columns and indexes of the source connection
+// with the ones of the target connection (e.g. staging with production).
+// Indexes are compared only if both drivers can list them.
+func CompareSchemas(source, target *Connection, opts *CompareOptions) (*SchemaDiff, error) {
+	if source == nil || target == nil {
+		return nil, errors.New("source and target connections are required")
+	}
+	if opts == nil {
+		opts = &CompareOptions{}
+	}
+	targetSchema := opts.TargetSchema
+	if targetSchema == "" {
+		targetSchema = opts.SourceSchema
+	}
+
+	sourceTables, err := compareTables(source, opts.SourceSchema)
+	if err != nil {
+		return nil, fmt.Errorf("source %s: %w", source.GetName(), err)
+	}
+	targetTables, err := compareTables(target, targetSchema)
+	if err != nil {
+		return nil, fmt.Errorf("target %s: %w", target.GetName(), err)
+	}
+
+	diff := &SchemaDiff{}
+
+	for _, key := range sortedKeys(sourceTables) {
+		src := sourceTables[key]
+		dst, ok := targetTables[key]
+		if !ok {
+			diff.Tables = append(diff.Tables, &TableDiff{
+				Schema: src.opts.Schema,
+				Name:   src.opts.Table,
+				Kind:   DiffKindMissing,
+			})
+			continue
+		}
+
+		columns := diffColumns(src.columns, dst.columns)
+		var indexes []*IndexDiff
+		if src.indexes != nil && dst.indexes != nil {
+			indexes = diffIndexes(src.indexes, dst.indexes)
+		}
+		if len(columns) > 0 || len(indexes) > 0 {
+			diff.Tables = append(diff.Tables, &TableDiff{
+				Schema:  dst.opts.Schema,
+				Name:    dst.opts.Table,
+				Kind:    DiffKindChanged,
+				Columns: columns,
+				Indexes: indexes,
+			})
+		}
+	}
+
+	for _, key := range sortedKeys(targetTables) {
+		if _, ok := sourceTables[key]; ok {
+			continue
+		}
+		dst := targetTables[key]
+		diff.Tables = append(diff.Tables, &TableDiff{
+			Schema: dst.opts.Schema,
+			Name:   dst.opts.Table,
+			Kind:   DiffKindExtra,
+		})
+	}
+
+	if opts.Migration {
+		diff.Migration = migrationStatements(diff, sourceTables, targetSchema, opts.SourceSchema != "", target.Dialect().QuoteIdentifier)
+	}
+
+	return diff, nil
+}
+
+// compareTables collects tables of the connection in the schema (all schemas
+// if empty), keyed by name. Tables of all schemas are keyed by "schema.name".
+func compareTables(c *Connection, schema string) (map[string]*comparedTable, error) {
+	structure, err := c.GetStructure()
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make(map[string]*comparedTable)
+
+	var walk func(nodes []*Structure) error
+	walk = func(nodes []*Structure) error {
+		for _, node := range nodes {
+			if schema != "" && node.Schema != schema {
+				continue
+			}
+
+			children := node.Children
+			if node.Lazy {
+				children, err = c.GetStructureChildren(node)
+				if err != nil {
+					return err
+				}
+			}
+			if err := walk(children); err != nil {
+				return err
+			}
+
+			if node.Type != StructureTypeTable {
+				continue
+			}
+
+			opts := &TableOptions{Table: node.Name, Schema: node.Schema, Materialization: node.Type}
+			columns, err := c.GetColumns(opts)
+			if err != nil {
+				return err
+			}
+			indexes, err := c.GetIndexes(opts)
+			if errors.Is(err, ErrInspectionNotSupported) {
+				indexes = nil
+			} else if err != nil {
+				return err
+			} else if indexes == nil {
+				indexes = []*Index{}
+			}
+
+			key := node.Name
+			if schema == "" {
+				key = node.Schema + "." + node.Name
+			}
+			tables[key] = &comparedTable{opts: opts, columns: columns, indexes: indexes}
+		}
+		return nil
+	}
+
+	if err := walk(structure); err != nil {
+		return nil, err
+	}
+
+	return tables, nil
+}
+
+func diffColumns(source, target []*Column) []*ColumnDiff {
+	var diffs []*ColumnDiff
+
+	byName := make(map[string]*Column, len(target))
+	for _, col := range target {
+		byName[col.Name] = col
+	}
+	for _, src := range source {
+		dst, ok := byName[src.Name]
+		switch {
+		case !ok:
+			diffs = append(diffs, &ColumnDiff{Name: src.Name, Kind: DiffKindMissing, Source: src})
+		case !strings.EqualFold(src.Type, dst.Type) || src.NotNull != dst.NotNull || src.Default != dst.Default:
+			diffs = append(diffs, &ColumnDiff{Name: src.Name, Kind: DiffKindChanged, Source: src, Target: dst})
+		}
+		delete(byName, src.Name)
+	}
+	for _, dst := range target {
+		if _, ok := byName[dst.Name]; ok {
+			diffs = append(diffs, &ColumnDiff{Name: dst.Name, Kind: DiffKindExtra, Target: dst})
+		}
+	}
+
+	return diffs
+}
+
+func diffIndexes(source, target []*Index) []*IndexDiff {
+	var diffs []*IndexDiff
+
+	byName := make(map[string]*Index, len(target))
+	for _, idx := range target {
+		byName[idx.Name] = idx
+	}
+	for _, src := range source {
+		dst, ok := byName[src.Name]
+		switch {
+		case !ok:
+			diffs = append(diffs, &IndexDiff{Name: src.Name, Kind: DiffKindMissing, Source: src})
+		case src.Unique != dst.Unique || src.Primary != dst.Primary ||
+			strings.Join(src.Columns, ",") != strings.Join(dst.Columns, ","):
+			diffs = append(diffs, &IndexDiff{Name: src.Name, Kind: DiffKindChanged, Source: src, Target: dst})
+		}
+		delete(byName, src.Name)
+	}
+	for _, dst := range target {
+		if _, ok := byName[dst.Name]; ok {
+			diffs = append(diffs, &IndexDiff{Name: dst.Name, Kind: DiffKindExtra, Target: dst})
+		}
+	}
+
+	return diffs
+}
+
+// migrationStatements returns statements which make the target match the
+// source. If a single schema is compared (tables are keyed by name), missing
+// tables are created in the target schema.
+func migrationStatements(diff *SchemaDiff, source map[string]*comparedTable, targetSchema string, singleSchema bool, quote func(string) string) []string {
+	var out []string
+
+	name := func(schema, table string) string {
+		if schema == "" {
+			return quote(table)
+		}
+		return quote(schema) + "." + quote(table)
+	}
+	columnDef := func(col *Column) string {
+		def := quote(col.Name) + " " + col.Type
+		if col.NotNull {
+			def += " NOT NULL"
+		}
+		if col.Default != "" {
+			def += " DEFAULT " + col.Default
+		}
+		return def
+	}
+	createIndex := func(table string, idx *Index) string {
+		columns := make([]string, len(idx.Columns))
+		for i, col := range idx.Columns {
+			columns[i] = quote(col)
+		}
+		unique := ""
+		if idx.Unique {
+			unique = "UNIQUE "
+		}
+		return fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)", unique, quote(idx.Name), table, strings.Join(columns, ", "))
+	}
+
+	for _, table := range diff.Tables {
+		switch table.Kind {
+		case DiffKindMissing:
+			key := table.Name
+			if !singleSchema {
+				key = table.Schema + "." + table.Name
+			}
+			src := source[key]
+			schema := table.Schema
+			if singleSchema {
+				schema = targetSchema
+			}
+			tableName := name(schema, table.Name)
+
+			defs := make([]string, len(src.columns))
+			var primary []string
+			for i, col := range src.columns {
+				defs[i] = columnDef(col)
+				if col.PrimaryKey {
+					primary = append(primary, quote(col.Name))
+				}
+			}
+			if len(primary) > 0 {
+				defs = append(defs, "PRIMARY KEY ("+strings.Join(primary, ", ")+")")
+			}
+			out = append(out, fmt.Sprintf("CREATE TABLE %s (\n  %s\n)", tableName, strings.Join(defs, ",\n  ")))
+
+			for _, idx := range src.indexes {
+				if !idx.Primary {
+					out = append(out, createIndex(tableName, idx))
+				}
+			}
+
+		case DiffKindExtra:
+			out = append(out, "DROP TABLE "+name(table.Schema, table.Name))
+
+		case DiffKindChanged:
+			tableName := name(table.Schema, table.Name)
+			alter := "ALTER TABLE " + tableName + " "
+
+			for _, col := range table.Columns {
+				switch col.Kind {
+				case DiffKindMissing:
+					out = append(out, alter+"ADD COLUMN "+columnDef(col.Source))
+				case DiffKindExtra:
+					out = append(out, alter+"DROP COLUMN "+quote(col.Name))
+				case DiffKindChanged:
+					column := alter + "ALTER COLUMN " + quote(col.Name) + " "
+					if !strings.EqualFold(col.Source.Type, col.Target.Type) {
+						out = append(out, column+"TYPE "+col.Source.Type)
+					}
+					if col.Source.NotNull != col.Target.NotNull {
+						if col.Source.NotNull {
+							out = append(out, column+"SET NOT NULL")
+						} else {
+							out = append(out, column+"DROP NOT NULL")
+						}
+					}
+					if col.Source.Default != col.Target.Default {
+						if col.Source.Default != "" {
+							out = append(out, column+"SET DEFAULT "+col.Source.Default)
+						} else {
+							out = append(out, column+"DROP DEFAULT")
+						}
+					}
+				}
+			}
+
+			for _, idx := range table.Indexes {
+				// primary keys are changed together with columns
+				if (idx.Source != nil && idx.Source.Primary) || (idx.Target != nil && idx.Target.Primary) {
+					continue
+				}
+				if idx.Kind != DiffKindMissing {
+					out = append(out, "DROP INDEX "+name(table.Schema, idx.Name))
+				}
+				if idx.Kind != DiffKindExtra {
+					out = append(out, createIndex(tableName, idx.Source))
+				}
+			}
+		}
+	}
+
+	return out
+}
+
+func sortedKeys(tables map[string]*comparedTable) []string {
+	keys := make([]string, 0, len(tables))
+	for key := range tables {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
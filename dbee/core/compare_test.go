@@ -0,0 +1,82 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestCompareSchemas(t *testing.T) {
+	r := require.New(t)
+
+	id := &core.Column{Name: "id", Type: "int", NotNull: true, PrimaryKey: true}
+
+	staging, err := core.NewConnection(&core.ConnectionParams{Name: "staging"}, mock.NewAdapter(mock.NewRows(0, 1),
+		mock.AdapterWithTableDefinition("users", []*core.Column{
+			id,
+			{Name: "email", Type: "text", NotNull: true},
+			{Name: "created", Type: "timestamp", Default: "now()"},
+		}),
+		mock.AdapterWithIndexes("users",
+			&core.Index{Name: "users_pkey", Columns: []string{"id"}, Primary: true, Unique: true},
+			&core.Index{Name: "users_email", Columns: []string{"email"}, Unique: true},
+		),
+		mock.AdapterWithTableDefinition("orders", []*core.Column{id, {Name: "total", Type: "numeric"}}),
+	))
+	r.NoError(err)
+
+	prod, err := core.NewConnection(&core.ConnectionParams{Name: "prod"}, mock.NewAdapter(mock.NewRows(0, 1),
+		mock.AdapterWithTableDefinition("users", []*core.Column{
+			id,
+			{Name: "email", Type: "varchar"},
+			{Name: "legacy", Type: "int"},
+		}),
+		mock.AdapterWithIndexes("users",
+			&core.Index{Name: "users_pkey", Columns: []string{"id"}, Primary: true, Unique: true},
+		),
+		mock.AdapterWithTableDefinition("logs", []*core.Column{{Name: "msg", Type: "text"}}),
+	))
+	r.NoError(err)
+
+	diff, err := core.CompareSchemas(staging, prod, &core.CompareOptions{Migration: true})
+	r.NoError(err)
+
+	r.Len(diff.Tables, 3)
+	r.Equal("orders", diff.Tables[0].Name)
+	r.Equal(core.DiffKindMissing, diff.Tables[0].Kind)
+
+	users := diff.Tables[1]
+	r.Equal("users", users.Name)
+	r.Equal(core.DiffKindChanged, users.Kind)
+	r.Len(users.Columns, 3)
+	r.Equal("email", users.Columns[0].Name)
+	r.Equal(core.DiffKindChanged, users.Columns[0].Kind)
+	r.Equal("created", users.Columns[1].Name)
+	r.Equal(core.DiffKindMissing, users.Columns[1].Kind)
+	r.Equal("legacy", users.Columns[2].Name)
+	r.Equal(core.DiffKindExtra, users.Columns[2].Kind)
+	r.Len(users.Indexes, 1)
+	r.Equal(core.DiffKindMissing, users.Indexes[0].Kind)
+
+	r.Equal("logs", diff.Tables[2].Name)
+	r.Equal(core.DiffKindExtra, diff.Tables[2].Kind)
+
+	r.Equal([]string{
+		"CREATE TABLE \"orders\" (\n  \"id\" int NOT NULL,\n  \"total\" numeric,\n  PRIMARY KEY (\"id\")\n)",
+		`ALTER TABLE "users" ALTER COLUMN "email" TYPE text`,
+		`ALTER TABLE "users" ALTER COLUMN "email" SET NOT NULL`,
+		`ALTER TABLE "users" ADD COLUMN "created" timestamp DEFAULT now()`,
+		`ALTER TABLE "users" DROP COLUMN "legacy"`,
+		`CREATE UNIQUE INDEX "users_email" ON "users" ("email")`,
+		`DROP TABLE "logs"`,
+	}, diff.Migration)
+
+	// identical schemas
+	diff, err = core.CompareSchemas(staging, staging, nil)
+	r.NoError(err)
+	r.Empty(diff.Tables)
+	r.Empty(diff.Migration)
+}
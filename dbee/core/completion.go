@@ -0,0 +1,135 @@
+package core
+
+import "strings"
+
+// Kinds of completion items which are not structure types
+const (
+	CompletionKindSchema  = "schema"
+	CompletionKindKeyword = "keyword"
+)
+
+// sqlKeywords are keywords of standard sql, completed on every connection.
+var sqlKeywords = []string{
+	"ALL", "ALTER", "AND", "AS", "ASC", "BETWEEN", "BY", "CASE", "CAST", "COALESCE",
+	"COMMIT", "CREATE", "CROSS", "DELETE", "DESC", "DISTINCT", "DROP", "ELSE", "END",
+	"EXCEPT", "EXISTS", "FALSE", "FROM", "FULL", "GROUP", "HAVING", "IN", "INDEX",
+	"INNER", "INSERT", "INTERSECT", "INTO", "IS", "JOIN", "LEFT", "LIKE", "NOT",
+	"NULL", "ON", "OR", "ORDER", "OUTER", "OVER", "PARTITION", "PRIMARY", "RIGHT",
+	"ROLLBACK", "SELECT", "SET", "TABLE", "THEN", "TRUE", "UNION", "UPDATE", "USING",
+	"VALUES", "VIEW", "WHEN", "WHERE", "WITH",
+}
+
+type (
+	// CompletionOptions filter completion items.
+	CompletionOptions struct {
+		// only items whose name starts with prefix (case insensitive)
+		Prefix string
+		// only objects of the schema, objects of all schemas if empty
+		Schema string
+		// columns of the table (or view) are included if set
+		Table string
+	}
+
+	// CompletionItem is a name which can be completed in a query.
+	CompletionItem struct {
+		Name string
+		// structure type of the object (e.g. "table" or "column"),
+		// CompletionKindSchema or CompletionKindKeyword
+		Kind string
+		// schema of objects
+		Schema string
+		// additional info, e.g. the type of a column
+		Detail string
+	}
+
+	// KeywordProvider is an optional interface for drivers whose dialect has
+	// keywords besides standard sql (e.g. RETURNING).
+	KeywordProvider interface {
+		Keywords() []string
+	}
+)
+
+// GetCompletion returns schemas, objects, columns and keywords for completion
+// of queries on the connection. The structure and columns are cached, so only
+// parts which weren't requested before are loaded from the database.
+func (c *Connection) GetCompletion(opts *CompletionOptions) ([]*CompletionItem, error) {
+	if opts == nil {
+		opts = &CompletionOptions{}
+	}
+
+	prefix := strings.ToLower(opts.Prefix)
+	var items []*CompletionItem
+	add := func(item *CompletionItem) {
+		if strings.HasPrefix(strings.ToLower(item.Name), prefix) {
+			items = append(items, item)
+		}
+	}
+
+	structure, err := c.GetStructureWithOptions(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// kind of the table whose columns are completed
+	tableType := StructureTypeTable
+	var walk func(nodes []*Structure) error
+	walk = func(nodes []*Structure) error {
+		for _, node := range nodes {
+			if opts.Schema != "" && node.Schema != opts.Schema {
+				continue
+			}
+
+			switch node.Type {
+			case StructureTypeNone:
+				if node.Schema == node.Name {
+					add(&CompletionItem{Name: node.Name, Kind: CompletionKindSchema})
+				}
+			case StructureTypeTable, StructureTypeView, StructureTypeMaterializedView,
+				StructureTypeFunction, StructureTypeProcedure:
+				add(&CompletionItem{Name: node.Name, Kind: node.Type.String(), Schema: node.Schema})
+				if node.Name == opts.Table && node.Type != StructureTypeFunction && node.Type != StructureTypeProcedure {
+					tableType = node.Type
+				}
+			}
+
+			children := node.Children
+			if node.Lazy {
+				children, err = c.GetStructureChildren(node)
+				if err != nil {
+					return err
+				}
+			}
+			if err := walk(children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(structure); err != nil {
+		return nil, err
+	}
+
+	if opts.Table != "" {
+		columns, ok := c.structure.getColumns(opts.Schema, opts.Table)
+		if !ok {
+			columns, err = c.GetColumns(&TableOptions{Table: opts.Table, Schema: opts.Schema, Materialization: tableType})
+			if err != nil {
+				return nil, err
+			}
+			c.structure.setColumns(opts.Schema, opts.Table, columns)
+		}
+		for _, col := range columns {
+			add(&CompletionItem{Name: col.Name, Kind: StructureTypeColumn.String(), Schema: opts.Schema, Detail: col.Type})
+		}
+	}
+
+	keywords := sqlKeywords
+	if provider, ok := c.driver.(KeywordProvider); ok {
+		keywords = append(append([]string{}, sqlKeywords...), provider.Keywords()...)
+	}
+	for _, keyword := range keywords {
+		add(&CompletionItem{Name: keyword, Kind: CompletionKindKeyword})
+	}
+
+	return items, nil
+}
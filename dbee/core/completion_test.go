@@ -0,0 +1,51 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_GetCompletion(t *testing.T) {
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 1),
+		mock.AdapterWithTableDefinition("users", []*core.Column{
+			{Name: "id", Type: "int", PrimaryKey: true},
+			{Name: "username", Type: "text"},
+		}),
+		mock.AdapterWithRoutine(&core.Structure{
+			Name:   "upsert_user",
+			Schema: "public",
+			Type:   core.StructureTypeProcedure,
+		}, "CREATE PROCEDURE upsert_user() AS 'select 1' LANGUAGE sql;"),
+	)
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	items, err := connection.GetCompletion(&core.CompletionOptions{Prefix: "U", Table: "users"})
+	r.NoError(err)
+	r.ElementsMatch([]*core.CompletionItem{
+		{Name: "users", Kind: "table"},
+		{Name: "upsert_user", Kind: "procedure", Schema: "public"},
+		{Name: "username", Kind: "column", Detail: "text"},
+		{Name: "UNION", Kind: core.CompletionKindKeyword},
+		{Name: "UPDATE", Kind: core.CompletionKindKeyword},
+		{Name: "USING", Kind: core.CompletionKindKeyword},
+	}, items)
+
+	// objects of a single schema
+	items, err = connection.GetCompletion(&core.CompletionOptions{Prefix: "p", Schema: "public"})
+	r.NoError(err)
+	r.Equal([]*core.CompletionItem{
+		{Name: "public", Kind: core.CompletionKindSchema},
+		{Name: "PARTITION", Kind: core.CompletionKindKeyword},
+		{Name: "PRIMARY", Kind: core.CompletionKindKeyword},
+	}, items)
+
+	_, err = connection.GetCompletion(&core.CompletionOptions{Table: "missing"})
+	r.Error(err)
+}
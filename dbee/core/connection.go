@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"path"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -51,6 +54,21 @@ type Connection struct {
 
 	driver  Driver
 	adapter Adapter
+
+	// transaction in progress (if any)
+	txMu       sync.Mutex
+	tx         Transaction
+	savepoints []string
+	// if set, statements implicitly start a transaction which stays open until commit
+	manualCommit bool
+
+	structure *structureCache
+	// nil if results aren't masked
+	masker *masker
+	// nil if values are displayed as they are
+	display *ValueDisplay
+	// nil if the connection has no query quota
+	quota *quotaTracker
 }
 
 func (s *Connection) MarshalJSON() ([]byte, error) {
@@ -64,6 +82,16 @@ func NewConnection(params *ConnectionParams, adapter Adapter) (*Connection, erro
 		expanded.ID = ConnectionID(uuid.New().String())
 	}
 
+	masker, err := newMasker(expanded.Masking)
+	if err != nil {
+		return nil, fmt.Errorf("newMasker: %w", err)
+	}
+
+	display, err := NewValueDisplay(&expanded.Display)
+	if err != nil {
+		return nil, fmt.Errorf("NewValueDisplay: %w", err)
+	}
+
 	driver, err := adapter.Connect(expanded.URL)
 	if err != nil {
 		return nil, fmt.Errorf("adapter.Connect: %w", err)
@@ -75,6 +103,11 @@ func NewConnection(params *ConnectionParams, adapter Adapter) (*Connection, erro
 
 		driver:  driver,
 		adapter: adapter,
+
+		structure: newStructureCache(time.Duration(expanded.StructureTTL) * time.Second),
+		masker:    masker,
+		display:   display,
+		quota:     newQuotaTracker(expanded.Quota),
 	}
 
 	return c, nil
@@ -96,22 +129,199 @@ func (c *Connection) GetURL() string {
 	return c.params.URL
 }
 
+// IsGuarded reports whether destructive statements on this connection
+// require confirmation.
+func (c *Connection) IsGuarded() bool {
+	return c.params.Guarded
+}
+
+// GetValueDisplay returns the display of values of the connection's results
+// (nil if values are displayed as they are).
+func (c *Connection) GetValueDisplay() *ValueDisplay {
+	return c.display
+}
+
 // GetParams returns the original source for this connection
 func (c *Connection) GetParams() *ConnectionParams {
 	return c.unexpandedParams
 }
 
+// Execute executes the query on the connection. On guarded connections, destructive
+// statements fail with ErrConfirmationRequired - use ExecuteConfirmed to run them.
 func (c *Connection) Execute(query string, onEvent func(CallState, *Call)) *Call {
-	exec := func(ctx context.Context) (ResultStream, error) {
+	return c.execute(query, false, onEvent)
+}
+
+// ExecuteConfirmed is the same as Execute, but skips the confirmation check
+// on guarded connections.
+func (c *Connection) ExecuteConfirmed(query string, onEvent func(CallState, *Call)) *Call {
+	return c.execute(query, true, onEvent)
+}
+
+func (c *Connection) execute(query string, confirmed bool, onEvent func(CallState, *Call)) *Call {
+	return newCallFromExecutor(c.executor(query, confirmed), query, onEvent)
+}
+
+// executor returns a function which runs the query on the connection's driver.
+func (c *Connection) executor(query string, confirmed bool) func(context.Context) (ResultStream, error) {
+	return func(ctx context.Context) (ResultStream, error) {
 		if strings.TrimSpace(query) == "" {
 			return nil, errors.New("empty query")
 		}
-		return c.driver.Query(ctx, query)
+		if !confirmed {
+			if err := c.CheckGuard(query); err != nil {
+				return nil, err
+			}
+			if err := c.checkCost(ctx, query); err != nil {
+				return nil, err
+			}
+		}
+		if err := c.quota.acquire(); err != nil {
+			return nil, err
+		}
+
+		limited, injected := c.Dialect().InjectLimit(query, c.params.AutoLimit)
+		if !injected {
+			limited = query
+		}
+
+		limits := c.resourceLimits()
+		cancel := func() {}
+		if limits != nil {
+			if err := limits.checkScan(ctx, c.driver, limited); err != nil {
+				return nil, err
+			}
+			ctx, cancel = limits.withRuntime(ctx)
+		}
+
+		var rows ResultStream
+		var retries int
+		tx, err := c.statementTx()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		if tx != nil {
+			// statements in transactions can't be retried on their own
+			rows, err = tx.Query(ctx, limited)
+		} else {
+			rows, retries, err = queryWithRetry(ctx, c.driver, limited, c.params.Retries)
+		}
+		if err != nil {
+			cancel()
+			if limits != nil {
+				err = limits.queryError(ctx, err)
+			}
+			return nil, classifyError(c.driver, err, limited)
+		}
+		if changesStructure(query) {
+			c.structure.invalidate(nil)
+		}
+		if meta := rows.Meta(); meta != nil {
+			if injected {
+				meta.InjectedLimit = c.params.AutoLimit
+			}
+			meta.Retries = retries
+		}
+		if limits != nil {
+			// the runtime is limited until the stream is closed
+			rows = limits.stream(ctx, cancel, rows)
+		}
+		return c.masker.stream(c.quota.stream(rows)), nil
+	}
+}
+
+// CallProcedure calls a stored procedure with provided parameters. Result sets
+// of the procedure are returned as the call's result, followed by values of
+// output parameters. On guarded connections, procedure calls fail with
+// ErrConfirmationRequired - use CallProcedureConfirmed to run them.
+func (c *Connection) CallProcedure(name string, params []*ProcedureParam, onEvent func(CallState, *Call)) *Call {
+	return c.callProcedure(name, params, false, onEvent)
+}
+
+// CallProcedureConfirmed is the same as CallProcedure, but skips the confirmation
+// check on guarded connections.
+func (c *Connection) CallProcedureConfirmed(name string, params []*ProcedureParam, onEvent func(CallState, *Call)) *Call {
+	return c.callProcedure(name, params, true, onEvent)
+}
+
+func (c *Connection) callProcedure(name string, params []*ProcedureParam, confirmed bool, onEvent func(CallState, *Call)) *Call {
+	query := procedureCallString(name, params)
+
+	exec := func(ctx context.Context) (ResultStream, error) {
+		if strings.TrimSpace(name) == "" {
+			return nil, errors.New("empty procedure name")
+		}
+		caller, ok := c.driver.(ProcedureCaller)
+		if !ok {
+			return nil, ErrProcedureCallingNotSupported
+		}
+		if !confirmed {
+			if err := c.CheckGuard(query); err != nil {
+				return nil, err
+			}
+		}
+		if err := c.quota.acquire(); err != nil {
+			return nil, err
+		}
+
+		rows, err := caller.CallProcedure(ctx, name, params)
+		if err != nil {
+			return nil, err
+		}
+		return c.masker.stream(c.quota.stream(rows)), nil
 	}
 
 	return newCallFromExecutor(exec, query, onEvent)
 }
 
+// GetActivity lists active sessions on the server. The list is returned as the call's result.
+func (c *Connection) GetActivity(onEvent func(CallState, *Call)) *Call {
+	exec := func(ctx context.Context) (ResultStream, error) {
+		monitor, ok := c.driver.(ActivityMonitor)
+		if !ok {
+			return nil, ErrActivityNotSupported
+		}
+		rows, err := monitor.Activity(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return c.masker.stream(rows), nil
+	}
+
+	return newCallFromExecutor(exec, "-- server activity", onEvent)
+}
+
+// KillSession terminates the session with provided id on the server.
+func (c *Connection) KillSession(id string) error {
+	monitor, ok := c.driver.(ActivityMonitor)
+	if !ok {
+		return ErrActivityNotSupported
+	}
+	if strings.TrimSpace(id) == "" {
+		return errors.New("empty session id")
+	}
+
+	err := monitor.KillSession(context.Background(), id)
+	if err != nil {
+		return fmt.Errorf("monitor.KillSession: %w", err)
+	}
+
+	return nil
+}
+
+// CheckGuard returns ErrConfirmationRequired if the connection is guarded
+// and the query contains a destructive statement.
+func (c *Connection) CheckGuard(query string) error {
+	if !c.params.Guarded {
+		return nil
+	}
+	if reason, ok := DestructiveReason(query); ok {
+		return fmt.Errorf("%w: %s", ErrConfirmationRequired, reason)
+	}
+	return nil
+}
+
 // SelectDatabase tries to switch to a given database with the used client.
 // on error, the switch doesn't happen and the previous connection remains active.
 func (c *Connection) SelectDatabase(name string) error {
@@ -124,6 +334,7 @@ func (c *Connection) SelectDatabase(name string) error {
 	if err != nil {
 		return fmt.Errorf("switcher.SelectDatabase: %w", err)
 	}
+	c.structure.invalidate(nil)
 
 	return nil
 }
@@ -139,7 +350,31 @@ func (c *Connection) ListDatabases() (current string, available []string, err er
 		return "", nil, fmt.Errorf("switcher.ListDatabases: %w", err)
 	}
 
-	return currentDB, availableDBs, nil
+	return currentDB, filterDatabases(availableDBs, c.params.HiddenDatabases), nil
+}
+
+// filterDatabases removes databases matching any of the hidden patterns.
+// Invalid patterns don't match anything.
+func filterDatabases(databases []string, hidden []string) []string {
+	if len(hidden) < 1 {
+		return databases
+	}
+
+	filtered := make([]string, 0, len(databases))
+	for _, db := range databases {
+		visible := true
+		for _, pattern := range hidden {
+			if ok, _ := path.Match(pattern, db); ok {
+				visible = false
+				break
+			}
+		}
+		if visible {
+			filtered = append(filtered, db)
+		}
+	}
+
+	return filtered
 }
 
 func (c *Connection) GetColumns(opts *TableOptions) ([]*Column, error) {
@@ -158,13 +393,47 @@ func (c *Connection) GetColumns(opts *TableOptions) ([]*Column, error) {
 	return cols, nil
 }
 
+// GetStructure returns the structure of the database, including system
+// objects (see GetStructureWithOptions). The structure is cached until it's
+// refreshed (see RefreshStructure) or expires.
 func (c *Connection) GetStructure() ([]*Structure, error) {
-	// structure
-	structure, err := c.driver.Structure()
+	if structure, ok := c.structure.get(nil); ok {
+		return structure, nil
+	}
+
+	structure, err := c.loadStructure()
 	if err != nil {
 		return nil, err
 	}
 
+	c.structure.set(nil, structure)
+	return structure, nil
+}
+
+func (c *Connection) loadStructure() ([]*Structure, error) {
+	var structure []*Structure
+	var err error
+
+	if loader, ok := c.driver.(StructureLoader); ok {
+		// top-level nodes only, children are loaded with GetStructureChildren
+		structure, err = c.getStructureRoots(loader)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		structure, err = c.driver.Structure()
+		if err != nil {
+			return nil, err
+		}
+
+		// functions, procedures and types
+		objects, err := c.schemaObjects()
+		if err != nil {
+			return nil, err
+		}
+		structure = mergeSchemaObjects(structure, objects)
+	}
+
 	// fallback to not confuse users
 	if len(structure) < 1 {
 		structure = []*Structure{
@@ -191,5 +460,8 @@ func (c *Connection) GetHelpers(opts *TableOptions) map[string]string {
 }
 
 func (c *Connection) Close() {
+	if c.InTransaction() {
+		_ = c.RollbackTransaction()
+	}
 	c.driver.Close()
 }
@@ -7,6 +7,48 @@ type ConnectionParams struct {
 	Name string
 	Type string
 	URL  string
+	// Guarded connections require confirmation for destructive statements.
+	Guarded bool
+	// AutoLimit is appended as a limit to unbounded SELECT statements (0 disables it).
+	AutoLimit int
+	// Retries is the number of times a statement is retried if it fails with
+	// a transient error, or with a dropped connection if it's a single
+	// read-only statement (0 disables retrying).
+	Retries int
+	// StructureTTL is the number of seconds after which the cached structure
+	// is loaded again (0 caches it until it's refreshed).
+	StructureTTL int
+	// HiddenDatabases are shell patterns (e.g. "test_*") of databases which
+	// are not listed by ListDatabases.
+	HiddenDatabases []string
+	// Preview configures the "select" object action of tables and views.
+	Preview PreviewOptions
+	// Masking rules are applied to values of results before they are
+	// displayed, archived or exported.
+	Masking []MaskRule
+	// Display configures how timestamps and numbers of results are displayed.
+	Display DisplayOptions
+	// Limits are enforced on calls if the connection is guarded.
+	Limits ResourceLimits
+	// Quota limits how many queries run on the connection.
+	Quota QueryQuota
+	// Cost configures cost estimates and the maximum cost of queries.
+	Cost CostOptions
+	// MigrationsDir is the directory of migration files of the connection.
+	// Migrations are discovered in the project root if it's empty.
+	MigrationsDir string
+}
+
+// PreviewOptions configure rows returned by the "select" object action.
+type PreviewOptions struct {
+	// Limit is the number of returned rows (DefaultSelectLimit if 0).
+	Limit int
+	// Latest orders rows by the primary key in descending order, so the
+	// most recently inserted rows come first.
+	Latest bool
+	// Columns are the selected columns, all columns if empty. Columns which
+	// the object doesn't have are ignored.
+	Columns []string
 }
 
 // Expand returns a copy of the original parameters with expanded fields
@@ -16,19 +58,209 @@ func (p *ConnectionParams) Expand() *ConnectionParams {
 		Name: expandOrDefault(p.Name),
 		Type: expandOrDefault(p.Type),
 		URL:  expandOrDefault(p.URL),
+
+		Guarded:      p.Guarded,
+		AutoLimit:    p.AutoLimit,
+		Retries:      p.Retries,
+		StructureTTL: p.StructureTTL,
+
+		HiddenDatabases: p.HiddenDatabases,
+		Preview:         p.Preview,
+		Masking:         p.Masking,
+		Display:         p.Display,
+		Limits:          p.Limits,
+		Quota:           p.Quota,
+		Cost:            p.Cost,
+		MigrationsDir:   expandOrDefault(p.MigrationsDir),
 	}
 }
 
 func (cp *ConnectionParams) MarshalJSON() ([]byte, error) {
+	var preview *PreviewOptions
+	if cp.Preview.Limit != 0 || cp.Preview.Latest || len(cp.Preview.Columns) > 0 {
+		preview = &cp.Preview
+	}
+	var display *DisplayOptions
+	if !cp.Display.IsEmpty() {
+		display = &cp.Display
+	}
+	var limits *ResourceLimits
+	if !cp.Limits.IsEmpty() || cp.Limits.TruncateRows {
+		limits = &cp.Limits
+	}
+	var quota *QueryQuota
+	if !cp.Quota.IsEmpty() {
+		quota = &cp.Quota
+	}
+	var cost *CostOptions
+	if !cp.Cost.IsEmpty() {
+		cost = &cp.Cost
+	}
+	var masking []*MaskRule
+	for i := range cp.Masking {
+		masking = append(masking, &cp.Masking[i])
+	}
+
+	return json.Marshal(struct {
+		ID           string `json:"id"`
+		Name         string `json:"name"`
+		Type         string `json:"type"`
+		URL          string `json:"url"`
+		Guarded      bool   `json:"guarded,omitempty"`
+		AutoLimit    int    `json:"auto_limit,omitempty"`
+		Retries      int    `json:"retries,omitempty"`
+		StructureTTL int    `json:"structure_ttl,omitempty"`
+
+		HiddenDatabases []string        `json:"hidden_databases,omitempty"`
+		Preview         *PreviewOptions `json:"preview,omitempty"`
+		Masking         []*MaskRule     `json:"masking,omitempty"`
+		Display         *DisplayOptions `json:"display,omitempty"`
+		Limits          *ResourceLimits `json:"limits,omitempty"`
+		Quota           *QueryQuota     `json:"quota,omitempty"`
+		Cost            *CostOptions    `json:"cost,omitempty"`
+		MigrationsDir   string          `json:"migrations_dir,omitempty"`
+	}{
+		ID:           string(cp.ID),
+		Name:         cp.Name,
+		Type:         cp.Type,
+		URL:          cp.URL,
+		Guarded:      cp.Guarded,
+		AutoLimit:    cp.AutoLimit,
+		Retries:      cp.Retries,
+		StructureTTL: cp.StructureTTL,
+
+		HiddenDatabases: cp.HiddenDatabases,
+		Preview:         preview,
+		Masking:         masking,
+		Display:         display,
+		Limits:          limits,
+		Quota:           quota,
+		Cost:            cost,
+		MigrationsDir:   cp.MigrationsDir,
+	})
+}
+
+// UnmarshalJSON reads parameters in the same form as MarshalJSON writes them
+// (e.g. connections of file sources).
+func (cp *ConnectionParams) UnmarshalJSON(data []byte) error {
+	var alias struct {
+		ID           string `json:"id"`
+		Name         string `json:"name"`
+		Type         string `json:"type"`
+		URL          string `json:"url"`
+		Guarded      bool   `json:"guarded"`
+		AutoLimit    int    `json:"auto_limit"`
+		Retries      int    `json:"retries"`
+		StructureTTL int    `json:"structure_ttl"`
+
+		HiddenDatabases []string `json:"hidden_databases"`
+		Preview         *struct {
+			Limit   int      `json:"limit"`
+			Latest  bool     `json:"latest"`
+			Columns []string `json:"columns"`
+		} `json:"preview"`
+		Masking []struct {
+			Column  string `json:"column"`
+			Pattern string `json:"pattern"`
+			Method  string `json:"method"`
+			Keep    int    `json:"keep"`
+		} `json:"masking"`
+		Display *struct {
+			Timezone           string `json:"timezone"`
+			TimeFormat         string `json:"time_format"`
+			ThousandsSeparator string `json:"thousands_separator"`
+			FloatPrecision     *int   `json:"float_precision"`
+		} `json:"display"`
+		Limits *struct {
+			MaxRows         int   `json:"max_rows"`
+			TruncateRows    bool  `json:"truncate_rows"`
+			MaxScannedBytes int64 `json:"max_scanned_bytes"`
+			MaxRuntime      int   `json:"max_runtime"`
+		} `json:"limits"`
+		Quota *struct {
+			MaxQueriesPerMinute int   `json:"max_queries_per_minute"`
+			CooldownRows        int64 `json:"cooldown_rows"`
+			Cooldown            int   `json:"cooldown"`
+		} `json:"quota"`
+		Cost *struct {
+			PricePerTiB float64 `json:"price_per_tib"`
+			MaxCost     float64 `json:"max_cost"`
+		} `json:"cost"`
+		MigrationsDir string `json:"migrations_dir"`
+	}
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+
+	*cp = ConnectionParams{
+		ID:           ConnectionID(alias.ID),
+		Name:         alias.Name,
+		Type:         alias.Type,
+		URL:          alias.URL,
+		Guarded:      alias.Guarded,
+		AutoLimit:    alias.AutoLimit,
+		Retries:      alias.Retries,
+		StructureTTL: alias.StructureTTL,
+
+		HiddenDatabases: alias.HiddenDatabases,
+		MigrationsDir:   alias.MigrationsDir,
+	}
+	if alias.Preview != nil {
+		cp.Preview = PreviewOptions{
+			Limit:   alias.Preview.Limit,
+			Latest:  alias.Preview.Latest,
+			Columns: alias.Preview.Columns,
+		}
+	}
+	if alias.Display != nil {
+		cp.Display = DisplayOptions{
+			Timezone:           alias.Display.Timezone,
+			TimeFormat:         alias.Display.TimeFormat,
+			ThousandsSeparator: alias.Display.ThousandsSeparator,
+			FloatPrecision:     alias.Display.FloatPrecision,
+		}
+	}
+	if alias.Limits != nil {
+		cp.Limits = ResourceLimits{
+			MaxRows:         alias.Limits.MaxRows,
+			TruncateRows:    alias.Limits.TruncateRows,
+			MaxScannedBytes: alias.Limits.MaxScannedBytes,
+			MaxRuntime:      alias.Limits.MaxRuntime,
+		}
+	}
+	if alias.Quota != nil {
+		cp.Quota = QueryQuota{
+			MaxQueriesPerMinute: alias.Quota.MaxQueriesPerMinute,
+			CooldownRows:        alias.Quota.CooldownRows,
+			Cooldown:            alias.Quota.Cooldown,
+		}
+	}
+	if alias.Cost != nil {
+		cp.Cost = CostOptions{
+			PricePerTiB: alias.Cost.PricePerTiB,
+			MaxCost:     alias.Cost.MaxCost,
+		}
+	}
+	for _, rule := range alias.Masking {
+		cp.Masking = append(cp.Masking, MaskRule{
+			Column:  rule.Column,
+			Pattern: rule.Pattern,
+			Method:  rule.Method,
+			Keep:    rule.Keep,
+		})
+	}
+
+	return nil
+}
+
+func (po *PreviewOptions) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
-		ID   string `json:"id"`
-		Name string `json:"name"`
-		Type string `json:"type"`
-		URL  string `json:"url"`
+		Limit   int      `json:"limit,omitempty"`
+		Latest  bool     `json:"latest,omitempty"`
+		Columns []string `json:"columns,omitempty"`
 	}{
-		ID:   string(cp.ID),
-		Name: cp.Name,
-		Type: cp.Type,
-		URL:  cp.URL,
+		Limit:   po.Limit,
+		Latest:  po.Latest,
+		Columns: po.Columns,
 	})
 }
@@ -0,0 +1,154 @@
+package core_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestExportConnections(t *testing.T) {
+	type testCase struct {
+		name            string
+		url             string
+		mode            core.SecretMode
+		expectedURL     string
+		expectedSecrets []string
+	}
+
+	testCases := []testCase{
+		{
+			name:            "url password",
+			url:             "postgres://admin:s3cr%40t@db:5432/app?sslmode=disable",
+			expectedURL:     `postgres://admin:{{ secret "prod_password" }}@db:5432/app?sslmode=disable`,
+			expectedSecrets: []string{"prod_password"},
+		},
+		{
+			name:            "query parameters",
+			url:             "clickhouse://db:9000/app?username=admin&password=pass&access_token=tok",
+			expectedURL:     `clickhouse://db:9000/app?username=admin&password={{ secret "prod_password" }}&access_token={{ secret "prod_access_token" }}`,
+			expectedSecrets: []string{"prod_password", "prod_access_token"},
+		},
+		{
+			name:            "key value dsn",
+			url:             "host=db user=admin password='p w' dbname=app",
+			expectedURL:     `host=db user=admin password={{ secret "prod_password" }} dbname=app`,
+			expectedSecrets: []string{"prod_password"},
+		},
+		{
+			name:            "mysql dsn",
+			url:             "admin:pass@tcp(db:3306)/app",
+			expectedURL:     `admin:{{ secret "prod_password" }}@tcp(db:3306)/app`,
+			expectedSecrets: []string{"prod_password"},
+		},
+		{
+			name:            "url password with @",
+			url:             "postgres://admin:p@ss@word@db:5432/app?email=me@example.com",
+			expectedURL:     `postgres://admin:{{ secret "prod_password" }}@db:5432/app?email=me@example.com`,
+			expectedSecrets: []string{"prod_password"},
+		},
+		{
+			name:            "mysql dsn password with @",
+			url:             "admin:p@ss@tcp(db:3306)/app",
+			expectedURL:     `admin:{{ secret "prod_password" }}@tcp(db:3306)/app`,
+			expectedSecrets: []string{"prod_password"},
+		},
+		{
+			name:            "mysql dsn password with @ over unix socket",
+			url:             "admin:p@ss@unix(/tmp/mysql.sock)/app",
+			expectedURL:     `admin:{{ secret "prod_password" }}@unix(/tmp/mysql.sock)/app`,
+			expectedSecrets: []string{"prod_password"},
+		},
+		{
+			name:            "env references",
+			url:             "postgres://admin:pass@db/app",
+			mode:            core.SecretModeEnv,
+			expectedURL:     `postgres://admin:{{ env "DBEE_PROD_PASSWORD" }}@db/app`,
+			expectedSecrets: []string{"prod_password"},
+		},
+		{
+			name:        "templates are kept",
+			url:         `postgres://admin:{{ env "PGPASSWORD" }}@db/app`,
+			expectedURL: `postgres://admin:{{ env "PGPASSWORD" }}@db/app`,
+		},
+		{
+			name:        "no credentials",
+			url:         "sqlite:///tmp/app.db",
+			expectedURL: "sqlite:///tmp/app.db",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			bundle, err := core.ExportConnections([]*core.ConnectionParams{
+				{ID: "file_source_/home/me/conns.json_abc", Name: "Prod", Type: "postgres", URL: tc.url},
+			}, tc.mode)
+			r.NoError(err)
+
+			r.Len(bundle.Connections, 1)
+			r.Equal(core.ConnectionID("prod"), bundle.Connections[0].ID)
+			r.Equal(tc.expectedURL, bundle.Connections[0].URL)
+
+			var names []string
+			for _, secret := range bundle.Secrets {
+				r.Equal(core.ConnectionID("prod"), secret.ConnectionID)
+				names = append(names, secret.Name)
+			}
+			r.Equal(tc.expectedSecrets, names)
+		})
+	}
+}
+
+func TestConnectionBundle_Import(t *testing.T) {
+	r := require.New(t)
+
+	bundle, err := core.ExportConnections([]*core.ConnectionParams{
+		{ID: "1", Name: "app", Type: "postgres", URL: "postgres://admin:old@db/app"},
+		{ID: "2", Name: "app", Type: "mysql", URL: "admin:old@tcp(db)/app?token=old"},
+		{ID: "3", Name: "env", Type: "postgres", URL: "postgres://admin:old@db/app"},
+	}, core.SecretModeStrip)
+	r.NoError(err)
+
+	path := filepath.Join(t.TempDir(), "connections.json")
+	r.NoError(bundle.Write(path))
+	info, err := os.Stat(path)
+	r.NoError(err)
+	r.Equal(os.FileMode(0o600), info.Mode().Perm())
+
+	read, err := core.ReadConnectionBundle(path)
+	r.NoError(err)
+	r.Equal(bundle, read)
+
+	var missing []string
+	for _, secret := range read.MissingSecrets() {
+		missing = append(missing, secret.Name)
+	}
+	r.Equal([]string{"app_password", "app_2_password", "app_2_token", "env_password"}, missing)
+
+	connections := read.FillSecrets(map[string]string{
+		"app_password":   "p@ss word",
+		"app_2_password": "new",
+		"env_password":   `{{ env "PGPASSWORD" }}`,
+	})
+	r.Len(connections, 3)
+	r.Equal("postgres://admin:p%40ss%20word@db/app", connections[0].URL)
+	r.Equal(`admin:new@tcp(db)/app?token={{ secret "app_2_token" }}`, connections[1].URL)
+	r.Equal(`postgres://admin:{{ env "PGPASSWORD" }}@db/app`, connections[2].URL)
+
+	// the bundle itself isn't changed
+	r.Equal(`postgres://admin:{{ secret "app_password" }}@db/app`, read.Connections[0].URL)
+
+	// env references are missing only if the variables aren't set
+	envBundle, err := core.ExportConnections([]*core.ConnectionParams{
+		{ID: "3", Name: "env", Type: "postgres", URL: "postgres://admin:old@db/app"},
+	}, core.SecretModeEnv)
+	r.NoError(err)
+	r.Len(envBundle.MissingSecrets(), 1)
+	t.Setenv("DBEE_ENV_PASSWORD", "secret")
+	r.Empty(envBundle.MissingSecrets())
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInspectionNotSupported = errors.New("listing indexes and constraints not supported")
+
+type ConstraintType int
+
+const (
+	ConstraintTypeNone ConstraintType = iota
+	ConstraintTypePrimaryKey
+	ConstraintTypeUnique
+	ConstraintTypeCheck
+	ConstraintTypeForeignKey
+)
+
+func (t ConstraintType) String() string {
+	switch t {
+	case ConstraintTypePrimaryKey:
+		return "primary_key"
+	case ConstraintTypeUnique:
+		return "unique"
+	case ConstraintTypeCheck:
+		return "check"
+	case ConstraintTypeForeignKey:
+		return "foreign_key"
+	default:
+		return ""
+	}
+}
+
+// ConstraintTypeFromString parses the constraint type as named by
+// information_schema (e.g. "PRIMARY KEY") or by String.
+func ConstraintTypeFromString(s string) ConstraintType {
+	switch strings.ReplaceAll(strings.ToLower(s), " ", "_") {
+	case "primary_key", "p":
+		return ConstraintTypePrimaryKey
+	case "unique", "u":
+		return ConstraintTypeUnique
+	case "check", "c":
+		return ConstraintTypeCheck
+	case "foreign_key", "f":
+		return ConstraintTypeForeignKey
+	default:
+		return ConstraintTypeNone
+	}
+}
+
+type (
+	// Index is an index of a table.
+	Index struct {
+		Name string
+		// indexed columns (or expressions) in index order
+		Columns []string
+		Unique  bool
+		Primary bool
+	}
+
+	// Constraint is a primary key, unique, check or foreign key constraint of a table.
+	Constraint struct {
+		Name    string
+		Type    ConstraintType
+		Columns []string
+		// definition of check constraints
+		Definition string
+		// table and columns referenced by foreign keys
+		ReferencedSchema  string
+		ReferencedTable   string
+		ReferencedColumns []string
+	}
+
+	// TableInspector is an optional interface for drivers that can list
+	// indexes and constraints of a table.
+	TableInspector interface {
+		Indexes(ctx context.Context, opts *TableOptions) ([]*Index, error)
+		Constraints(ctx context.Context, opts *TableOptions) ([]*Constraint, error)
+	}
+)
+
+// GetIndexes returns indexes of the table described by opts.
+func (c *Connection) GetIndexes(opts *TableOptions) ([]*Index, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("opts cannot be nil")
+	}
+
+	inspector, ok := c.driver.(TableInspector)
+	if !ok {
+		return nil, ErrInspectionNotSupported
+	}
+
+	indexes, err := inspector.Indexes(context.Background(), opts)
+	if err != nil {
+		return nil, fmt.Errorf("inspector.Indexes: %w", err)
+	}
+
+	return indexes, nil
+}
+
+// GetConstraints returns constraints of the table described by opts.
+func (c *Connection) GetConstraints(opts *TableOptions) ([]*Constraint, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("opts cannot be nil")
+	}
+
+	inspector, ok := c.driver.(TableInspector)
+	if !ok {
+		return nil, ErrInspectionNotSupported
+	}
+
+	constraints, err := inspector.Constraints(context.Background(), opts)
+	if err != nil {
+		return nil, fmt.Errorf("inspector.Constraints: %w", err)
+	}
+
+	return constraints, nil
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrCostEstimationNotSupported = errors.New("cost estimation not supported")
+	ErrCostLimitExceeded          = errors.New("estimated query cost exceeds the limit")
+)
+
+// ErrorCategoryCost errors are returned by queries whose estimated cost
+// exceeds the maximum cost of a connection.
+const ErrorCategoryCost ErrorCategory = "cost"
+
+// bytes in a tebibyte, the unit of prices of scanned bytes
+const bytesPerTiB = 1 << 40
+
+// CostOptions configure cost estimates of queries on databases which bill
+// them by scanned bytes (e.g. BigQuery). Zero values use the defaults of the
+// driver and disable the guard.
+type CostOptions struct {
+	// PricePerTiB is the price in USD of a scanned TiB, the driver's default
+	// (e.g. on-demand pricing) if 0.
+	PricePerTiB float64
+	// MaxCost is the estimated cost in USD above which queries are refused
+	// unless they are confirmed.
+	MaxCost float64
+}
+
+func (co *CostOptions) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		PricePerTiB float64 `json:"price_per_tib,omitempty"`
+		MaxCost     float64 `json:"max_cost,omitempty"`
+	}{
+		PricePerTiB: co.PricePerTiB,
+		MaxCost:     co.MaxCost,
+	})
+}
+
+// IsEmpty reports whether no options are set.
+func (co *CostOptions) IsEmpty() bool {
+	return co.PricePerTiB <= 0 && co.MaxCost <= 0
+}
+
+// ScanPricer is an optional interface for scan estimating drivers of
+// databases which bill queries by scanned bytes.
+type ScanPricer interface {
+	// ScanPrice returns the default price in USD of a scanned TiB.
+	ScanPrice() float64
+}
+
+// CostEstimate is the estimated cost of a query, obtained without running it.
+type CostEstimate struct {
+	// number of bytes the query would scan
+	ScannedBytes int64
+	// price in USD of a scanned TiB (0 if unknown)
+	PricePerTiB float64
+	// estimated cost in USD (0 if the price is unknown)
+	Cost float64
+	// maximum cost of the connection (0 if queries aren't refused)
+	MaxCost float64
+}
+
+// Exceeds reports whether the query would be refused by the maximum cost.
+func (ce *CostEstimate) Exceeds() bool {
+	return ce.MaxCost > 0 && ce.Cost > ce.MaxCost
+}
+
+// CostLimitError is the error of a call which was refused because of its
+// estimated cost. It's returned as the Err of a QueryError with the
+// ErrorCategoryCost category.
+type CostLimitError struct {
+	Estimate *CostEstimate
+}
+
+func (e *CostLimitError) Error() string {
+	return fmt.Sprintf("%s: query would scan %d bytes for $%.2f, the limit is $%.2f",
+		ErrCostLimitExceeded, e.Estimate.ScannedBytes, e.Estimate.Cost, e.Estimate.MaxCost)
+}
+
+func (e *CostLimitError) Is(target error) bool {
+	return target == ErrCostLimitExceeded
+}
+
+// EstimateCost estimates the bytes the query would scan and their price,
+// without running the query. ErrCostEstimationNotSupported is returned if the
+// driver can't estimate scanned bytes.
+func (c *Connection) EstimateCost(query string) (*CostEstimate, error) {
+	estimate, err := c.estimateCost(context.Background(), query)
+	if err != nil {
+		if errors.Is(err, ErrCostEstimationNotSupported) {
+			return nil, err
+		}
+		return nil, classifyError(c.driver, err, query)
+	}
+	return estimate, nil
+}
+
+func (c *Connection) estimateCost(ctx context.Context, query string) (*CostEstimate, error) {
+	estimator, ok := c.driver.(ScanEstimator)
+	if !ok {
+		return nil, ErrCostEstimationNotSupported
+	}
+
+	scanned, err := estimator.EstimateScan(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	price := c.params.Cost.PricePerTiB
+	if pricer, ok := c.driver.(ScanPricer); ok && price <= 0 {
+		price = pricer.ScanPrice()
+	}
+	estimate := &CostEstimate{
+		ScannedBytes: scanned,
+		MaxCost:      c.params.Cost.MaxCost,
+	}
+	if price > 0 {
+		estimate.PricePerTiB = price
+		estimate.Cost = float64(scanned) / bytesPerTiB * price
+	}
+	return estimate, nil
+}
+
+// checkCost refuses the query if its estimated cost exceeds the maximum cost
+// of the connection. Queries of drivers which can't estimate their cost
+// aren't refused.
+func (c *Connection) checkCost(ctx context.Context, query string) error {
+	if c.params.Cost.MaxCost <= 0 {
+		return nil
+	}
+
+	estimate, err := c.estimateCost(ctx, query)
+	if errors.Is(err, ErrCostEstimationNotSupported) {
+		return nil
+	}
+	if err != nil {
+		return classifyError(c.driver, err, query)
+	}
+	if estimate.Exceeds() {
+		return &QueryError{
+			Category: ErrorCategoryCost,
+			Code:     "max_cost",
+			Hint:     "filter by partitioned or clustered columns, or confirm the query if the cost is expected",
+			Err:      &CostLimitError{Estimate: estimate},
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+const tib = 1 << 40
+
+func TestConnection_EstimateCost(t *testing.T) {
+	r := require.New(t)
+
+	params := &core.ConnectionParams{Cost: core.CostOptions{PricePerTiB: 5, MaxCost: 10}}
+	connection, err := core.NewConnection(params, mock.NewAdapter(mock.NewRows(0, 10), mock.AdapterWithScanEstimate(3*tib)))
+	r.NoError(err)
+
+	estimate, err := connection.EstimateCost("select")
+	r.NoError(err)
+	r.Equal(int64(3*tib), estimate.ScannedBytes)
+	r.Equal(5.0, estimate.PricePerTiB)
+	r.Equal(15.0, estimate.Cost)
+	r.Equal(10.0, estimate.MaxCost)
+	r.True(estimate.Exceeds())
+
+	// unknown price
+	connection, err = core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(mock.NewRows(0, 10), mock.AdapterWithScanEstimate(tib)))
+	r.NoError(err)
+
+	estimate, err = connection.EstimateCost("select")
+	r.NoError(err)
+	r.Equal(int64(tib), estimate.ScannedBytes)
+	r.Zero(estimate.Cost)
+	r.False(estimate.Exceeds())
+}
+
+func TestConnection_MaxCost(t *testing.T) {
+	type testCase struct {
+		name        string
+		cost        core.CostOptions
+		scanned     int64
+		confirmed   bool
+		expectError bool
+	}
+
+	testCases := []testCase{
+		{
+			name:    "no maximum cost",
+			cost:    core.CostOptions{PricePerTiB: 5},
+			scanned: 100 * tib,
+		},
+		{
+			name:    "within maximum cost",
+			cost:    core.CostOptions{PricePerTiB: 5, MaxCost: 10},
+			scanned: 2 * tib,
+		},
+		{
+			name:        "above maximum cost",
+			cost:        core.CostOptions{PricePerTiB: 5, MaxCost: 10},
+			scanned:     3 * tib,
+			expectError: true,
+		},
+		{
+			name:      "above maximum cost confirmed",
+			cost:      core.CostOptions{PricePerTiB: 5, MaxCost: 10},
+			scanned:   3 * tib,
+			confirmed: true,
+		},
+		{
+			name:    "unknown price",
+			cost:    core.CostOptions{MaxCost: 10},
+			scanned: 100 * tib,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			params := &core.ConnectionParams{Cost: tc.cost}
+			connection, err := core.NewConnection(params, mock.NewAdapter(mock.NewRows(0, 10), mock.AdapterWithScanEstimate(tc.scanned)))
+			r.NoError(err)
+
+			var call *core.Call
+			if tc.confirmed {
+				call = connection.ExecuteConfirmed("select", nil)
+			} else {
+				call = connection.Execute("select", nil)
+			}
+			select {
+			case <-call.Done():
+			case <-time.After(5 * time.Second):
+				t.Fatal("call did not finish in expected time")
+			}
+
+			if !tc.expectError {
+				r.NoError(call.Err())
+				return
+			}
+
+			r.ErrorIs(call.Err(), core.ErrCostLimitExceeded)
+
+			var qErr *core.QueryError
+			r.True(errors.As(call.Err(), &qErr))
+			r.Equal(core.ErrorCategoryCost, qErr.Category)
+			r.Equal("max_cost", qErr.Code)
+
+			var costErr *core.CostLimitError
+			r.True(errors.As(call.Err(), &costErr))
+			r.Equal(15.0, costErr.Estimate.Cost)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_HiddenDatabases(t *testing.T) {
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 1),
+		mock.AdapterWithDatabases("app", "app", "app_test_1", "app_test_2", "postgres", "reports"),
+	)
+	connection, err := core.NewConnection(&core.ConnectionParams{
+		HiddenDatabases: []string{"*_test_*", "postgres", "[invalid"},
+	}, adapter)
+	r.NoError(err)
+
+	current, available, err := connection.ListDatabases()
+	r.NoError(err)
+	r.Equal("app", current)
+	r.Equal([]string{"reports"}, available)
+
+	// hidden databases can still be selected, the current one is never hidden
+	r.NoError(connection.SelectDatabase("app_test_1"))
+	current, available, err = connection.ListDatabases()
+	r.NoError(err)
+	r.Equal("app_test_1", current)
+	r.Equal([]string{"app", "reports"}, available)
+}
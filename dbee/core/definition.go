@@ -0,0 +1,78 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrDefinitionNotSupported = errors.New("retrieving definitions not supported")
+	ErrDefinitionNotFound     = errors.New("definition not found")
+)
+
+type (
+	// RoutineLister is an optional interface for drivers that can list functions
+	// and stored procedures. Routines are returned as a flat list and are placed
+	// under the schema nodes of the structure.
+	RoutineLister interface {
+		Routines() ([]*Structure, error)
+	}
+
+	// DefinitionProvider is an optional interface for drivers that can retrieve
+	// the source of database objects (e.g. functions and procedures). For tables,
+	// views, indexes and sequences the source is their DDL (CREATE statements).
+	// Kind of the object is passed as opts.Materialization.
+	DefinitionProvider interface {
+		Definition(ctx context.Context, opts *TableOptions) (string, error)
+	}
+)
+
+// GetDefinition returns the source of the object described by opts.
+func (c *Connection) GetDefinition(opts *TableOptions) (string, error) {
+	if opts == nil {
+		return "", fmt.Errorf("opts cannot be nil")
+	}
+
+	provider, ok := c.driver.(DefinitionProvider)
+	if !ok {
+		return "", ErrDefinitionNotSupported
+	}
+
+	definition, err := provider.Definition(context.Background(), opts)
+	if err != nil {
+		return "", fmt.Errorf("provider.Definition: %w", err)
+	}
+	if definition == "" {
+		return "", ErrDefinitionNotFound
+	}
+
+	return definition, nil
+}
+
+// mergeSchemaObjects places objects (e.g. routines) under schema nodes of the
+// structure with the same name. Schema nodes which don't exist yet are created.
+func mergeSchemaObjects(structure []*Structure, objects []*Structure) []*Structure {
+	schemas := make(map[string]*Structure, len(structure))
+	for _, s := range structure {
+		if s.Type == StructureTypeNone {
+			schemas[s.Schema] = s
+		}
+	}
+
+	for _, object := range objects {
+		schema, ok := schemas[object.Schema]
+		if !ok {
+			schema = &Structure{
+				Name:   object.Schema,
+				Schema: object.Schema,
+				Type:   StructureTypeNone,
+			}
+			schemas[object.Schema] = schema
+			structure = append(structure, schema)
+		}
+		schema.Children = append(schema.Children, object)
+	}
+
+	return structure
+}
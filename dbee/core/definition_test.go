@@ -0,0 +1,54 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_Routines(t *testing.T) {
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 1),
+		mock.AdapterWithRoutine(&core.Structure{
+			Name:   "add",
+			Schema: "public",
+			Type:   core.StructureTypeFunction,
+		}, "CREATE FUNCTION add(a int, b int) RETURNS int AS 'select a + b' LANGUAGE sql;"),
+		mock.AdapterWithRoutine(&core.Structure{
+			Name:   "cleanup",
+			Schema: "public",
+			Type:   core.StructureTypeProcedure,
+		}, "CREATE PROCEDURE cleanup() AS 'delete from logs' LANGUAGE sql;"),
+	)
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	// routines are placed under their schema
+	structure, err := connection.GetStructure()
+	r.NoError(err)
+	r.Len(structure, 1)
+	r.Equal("public", structure[0].Name)
+	r.Equal(core.StructureTypeNone, structure[0].Type)
+	r.Len(structure[0].Children, 2)
+	r.Equal(core.StructureTypeFunction, structure[0].Children[0].Type)
+	r.Equal(core.StructureTypeProcedure, structure[0].Children[1].Type)
+
+	definition, err := connection.GetDefinition(&core.TableOptions{
+		Table:           "add",
+		Schema:          "public",
+		Materialization: core.StructureTypeFunction,
+	})
+	r.NoError(err)
+	r.Contains(definition, "CREATE FUNCTION add")
+
+	_, err = connection.GetDefinition(&core.TableOptions{
+		Table:           "missing",
+		Schema:          "public",
+		Materialization: core.StructureTypeFunction,
+	})
+	r.ErrorIs(err, core.ErrDefinitionNotFound)
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrDependenciesNotSupported = errors.New("listing dependencies not supported")
+
+// MaxDependencyDepth limits how deep dependency trees are followed.
+const MaxDependencyDepth = 8
+
+type (
+	// DependencyNode is an object in a dependency tree. Children of a node are
+	// objects it depends on or objects which depend on it, depending on the
+	// direction of the tree.
+	DependencyNode struct {
+		Schema   string
+		Name     string
+		Type     StructureType
+		Children []*DependencyNode
+		// set if the object was already visited higher up in the tree
+		// (e.g. in cyclic foreign keys), its children are not repeated
+		Cycle bool
+	}
+
+	// Dependencies of an object in both directions.
+	Dependencies struct {
+		// objects the object depends on (e.g. tables used by a view)
+		DependsOn []*DependencyNode
+		// objects which depend on the object (e.g. views using a table)
+		Dependents []*DependencyNode
+	}
+
+	// DependencyLister is an optional interface for drivers that can list
+	// direct dependencies of database objects. Connection follows them to
+	// build the whole tree.
+	DependencyLister interface {
+		DependsOn(ctx context.Context, opts *TableOptions) ([]*DependencyNode, error)
+		Dependents(ctx context.Context, opts *TableOptions) ([]*DependencyNode, error)
+	}
+)
+
+// GetDependencies returns trees of objects which the object described by opts
+// depends on and objects which depend on it (e.g. to check what breaks if
+// a table is dropped).
+func (c *Connection) GetDependencies(opts *TableOptions) (*Dependencies, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("opts cannot be nil")
+	}
+
+	lister, ok := c.driver.(DependencyLister)
+	if !ok {
+		return nil, ErrDependenciesNotSupported
+	}
+
+	ctx := context.Background()
+
+	dependsOn, err := dependencyTree(ctx, lister.DependsOn, opts, nil, 1)
+	if err != nil {
+		return nil, fmt.Errorf("lister.DependsOn: %w", err)
+	}
+	dependents, err := dependencyTree(ctx, lister.Dependents, opts, nil, 1)
+	if err != nil {
+		return nil, fmt.Errorf("lister.Dependents: %w", err)
+	}
+
+	return &Dependencies{
+		DependsOn:  dependsOn,
+		Dependents: dependents,
+	}, nil
+}
+
+// dependencyTree follows direct dependencies returned by list recursively.
+// Objects on the path from the root are marked as cycles instead of being
+// followed again.
+func dependencyTree(ctx context.Context, list func(context.Context, *TableOptions) ([]*DependencyNode, error), opts *TableOptions, path []string, depth int) ([]*DependencyNode, error) {
+	path = append(path, dependencyKey(opts.Schema, opts.Table))
+
+	nodes, err := list(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, node := range nodes {
+		key := dependencyKey(node.Schema, node.Name)
+		for _, visited := range path {
+			if visited == key {
+				node.Cycle = true
+				break
+			}
+		}
+		if node.Cycle || depth >= MaxDependencyDepth {
+			continue
+		}
+
+		node.Children, err = dependencyTree(ctx, list, &TableOptions{
+			Table:           node.Name,
+			Schema:          node.Schema,
+			Materialization: node.Type,
+		}, path, depth+1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return nodes, nil
+}
+
+func dependencyKey(schema, name string) string {
+	return schema + "." + name
+}
@@ -0,0 +1,58 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_GetDependencies(t *testing.T) {
+	r := require.New(t)
+
+	users := &core.Structure{Schema: "public", Name: "users", Type: core.StructureTypeTable}
+	orders := &core.Structure{Schema: "public", Name: "orders", Type: core.StructureTypeTable}
+	report := &core.Structure{Schema: "public", Name: "report", Type: core.StructureTypeView}
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 1),
+		mock.AdapterWithDependency(report, orders),
+		mock.AdapterWithDependency(orders, users),
+		// cyclic foreign key
+		mock.AdapterWithDependency(users, orders),
+	)
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	deps, err := connection.GetDependencies(&core.TableOptions{
+		Schema:          "public",
+		Table:           "report",
+		Materialization: core.StructureTypeView,
+	})
+	r.NoError(err)
+	r.Empty(deps.Dependents)
+	r.Equal([]*core.DependencyNode{
+		{Schema: "public", Name: "orders", Type: core.StructureTypeTable, Children: []*core.DependencyNode{
+			{Schema: "public", Name: "users", Type: core.StructureTypeTable, Children: []*core.DependencyNode{
+				{Schema: "public", Name: "orders", Type: core.StructureTypeTable, Cycle: true},
+			}},
+		}},
+	}, deps.DependsOn)
+
+	deps, err = connection.GetDependencies(&core.TableOptions{
+		Schema:          "public",
+		Table:           "users",
+		Materialization: core.StructureTypeTable,
+	})
+	r.NoError(err)
+	r.Equal([]*core.DependencyNode{
+		{Schema: "public", Name: "orders", Type: core.StructureTypeTable, Children: []*core.DependencyNode{
+			{Schema: "public", Name: "report", Type: core.StructureTypeView},
+			{Schema: "public", Name: "users", Type: core.StructureTypeTable, Cycle: true},
+		}},
+	}, deps.Dependents)
+
+	_, err = connection.GetDependencies(nil)
+	r.Error(err)
+}
@@ -0,0 +1,246 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IdentifierCase is the case unquoted identifiers are folded to.
+type IdentifierCase int
+
+const (
+	// IdentifierCasePreserve keeps the case of unquoted identifiers (e.g.
+	// mysql, sqlite and sqlserver).
+	IdentifierCasePreserve IdentifierCase = iota
+	// IdentifierCaseLower folds unquoted identifiers to lower case (e.g.
+	// postgres).
+	IdentifierCaseLower
+	// IdentifierCaseUpper folds unquoted identifiers to upper case (e.g.
+	// oracle).
+	IdentifierCaseUpper
+)
+
+// PlaceholderStyle is the way bind parameters are written.
+type PlaceholderStyle int
+
+const (
+	// PlaceholderQuestion is "?" (e.g. mysql and sqlite).
+	PlaceholderQuestion PlaceholderStyle = iota
+	// PlaceholderDollar is "$1" (e.g. postgres).
+	PlaceholderDollar
+	// PlaceholderColon is ":1" (e.g. oracle).
+	PlaceholderColon
+	// PlaceholderAtP is "@p1" (e.g. sqlserver).
+	PlaceholderAtP
+)
+
+type (
+	// DialectOptions describe how literals, identifiers and bind parameters
+	// of a database differ from standard sql.
+	DialectOptions struct {
+		IdentifierCase IdentifierCase
+		// booleans are written as 1 and 0 (e.g. sqlserver and oracle)
+		NumericBooleans bool
+		Placeholder     PlaceholderStyle
+	}
+
+	// DialectOptionsProvider is an optional interface for drivers whose
+	// literals, identifier case or bind parameters differ from standard sql.
+	DialectOptionsProvider interface {
+		DialectOptions() *DialectOptions
+	}
+)
+
+// Dialect generates parts of statements for a database: quoted identifiers,
+// literals, limits and bind parameters. Statements generated by the plugin
+// (scaffolds, edits of results, exports of INSERT statements, ...) are built
+// with the dialect of their connection, which is assembled from optional
+// interfaces of the driver ([IdentifierQuoter], [LimitDialect],
+// [StatementDialectProvider], [DialectOptionsProvider] and [JSONPathDialect]).
+type Dialect struct {
+	quote            func(string) string
+	limit            LimitSyntax
+	jsonPath         JSONPathSyntax
+	backslashEscapes bool
+	opts             DialectOptions
+}
+
+// DefaultDialect follows standard sql.
+var DefaultDialect = NewDialect(nil)
+
+// NewDialect assembles the dialect of the driver.
+func NewDialect(driver Driver) *Dialect {
+	d := &Dialect{
+		quote: quoteIdentifier,
+		limit: LimitSyntaxLimit,
+	}
+	if quoter, ok := driver.(IdentifierQuoter); ok {
+		d.quote = quoter.QuoteIdentifier
+	}
+	if dialect, ok := driver.(LimitDialect); ok {
+		d.limit = dialect.LimitSyntax()
+	}
+	if provider, ok := driver.(StatementDialectProvider); ok {
+		d.backslashEscapes = provider.StatementDialect().BackslashEscapes
+	}
+	if provider, ok := driver.(DialectOptionsProvider); ok {
+		d.opts = *provider.DialectOptions()
+	}
+	if dialect, ok := driver.(JSONPathDialect); ok {
+		d.jsonPath = dialect.JSONPathSyntax()
+	}
+	return d
+}
+
+// Dialect returns the dialect of statements generated for the connection.
+func (c *Connection) Dialect() *Dialect {
+	return NewDialect(c.driver)
+}
+
+// QuoteIdentifier quotes the identifier as it is, so its case is kept.
+func (d *Dialect) QuoteIdentifier(name string) string {
+	return d.quote(name)
+}
+
+// QualifiedName quotes parts of the name and joins them with dots. Empty
+// parts (e.g. schemas of tables in the default schema) are left out.
+func (d *Dialect) QualifiedName(parts ...string) string {
+	quoted := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part != "" {
+			quoted = append(quoted, d.quote(part))
+		}
+	}
+	return strings.Join(quoted, ".")
+}
+
+// NormalizeIdentifier returns the name an identifier written in a statement
+// refers to: quoted identifiers are unquoted and unquoted ones are folded to
+// the case of the dialect.
+func (d *Dialect) NormalizeIdentifier(ident string) string {
+	// quotes of the dialect are those around an empty identifier
+	quotes := d.quote("")
+	if len(quotes) == 2 && len(ident) >= 2 && ident[0] == quotes[0] && ident[len(ident)-1] == quotes[1] {
+		closing := string(quotes[1])
+		return strings.ReplaceAll(ident[1:len(ident)-1], closing+closing, closing)
+	}
+	// identifiers quoted with standard double quotes are accepted by most
+	// databases besides their own quotes
+	if len(ident) >= 2 && ident[0] == '"' && ident[len(ident)-1] == '"' {
+		return strings.ReplaceAll(ident[1:len(ident)-1], `""`, `"`)
+	}
+
+	switch d.opts.IdentifierCase {
+	case IdentifierCaseLower:
+		return strings.ToLower(ident)
+	case IdentifierCaseUpper:
+		return strings.ToUpper(ident)
+	default:
+		return ident
+	}
+}
+
+// Literal formats the value as a literal.
+func (d *Dialect) Literal(val any) string {
+	switch v := val.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		switch {
+		case d.opts.NumericBooleans && v:
+			return "1"
+		case d.opts.NumericBooleans:
+			return "0"
+		case v:
+			return "TRUE"
+		default:
+			return "FALSE"
+		}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return d.StringLiteral(v.Format("2006-01-02 15:04:05.999999999Z07:00"))
+	case []byte:
+		return d.StringLiteral(string(v))
+	default:
+		return d.StringLiteral(fmt.Sprint(v))
+	}
+}
+
+// StringLiteral formats the text as a string literal.
+func (d *Dialect) StringLiteral(text string) string {
+	if d.backslashEscapes {
+		text = strings.ReplaceAll(text, `\`, `\\`)
+		return "'" + strings.ReplaceAll(text, "'", `\'`) + "'"
+	}
+	return "'" + strings.ReplaceAll(text, "'", "''") + "'"
+}
+
+// LimitSyntax returns the way the database limits the number of rows.
+func (d *Dialect) LimitSyntax() LimitSyntax {
+	return d.limit
+}
+
+// InjectLimit adds the limit clause of the dialect to SELECT statements which
+// aren't limited yet (see [InjectLimit]).
+func (d *Dialect) InjectLimit(query string, limit int) (string, bool) {
+	return InjectLimit(query, limit, d.limit)
+}
+
+// Placeholder returns the n-th (1-based) bind parameter.
+func (d *Dialect) Placeholder(n int) string {
+	switch d.opts.Placeholder {
+	case PlaceholderDollar:
+		return "$" + strconv.Itoa(n)
+	case PlaceholderColon:
+		return ":" + strconv.Itoa(n)
+	case PlaceholderAtP:
+		return "@p" + strconv.Itoa(n)
+	default:
+		return "?"
+	}
+}
+
+// quoteIdentifier quotes the identifier with standard sql double quotes.
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+var (
+	// queryTableRe matches the table of queries which select from a single
+	// table
+	queryTableRe = regexp.MustCompile("(?is)^select\\s.+?\\sfrom\\s+((?:\"[^\"]*\"|`[^`]*`|\\[[^\\]]*\\]|[\\w$#]+)(?:\\s*\\.\\s*(?:\"[^\"]*\"|`[^`]*`|\\[[^\\]]*\\]|[\\w$#]+))*)(.*)$")
+	// queryTablePartRe matches parts of a qualified table name
+	queryTablePartRe = regexp.MustCompile("\"[^\"]*\"|`[^`]*`|\\[[^\\]]*\\]|[\\w$#]+")
+	// queryJoinRe matches joins of multiple tables
+	queryJoinRe = regexp.MustCompile(`(?i)^\s*,|\bjoin\b`)
+)
+
+// QueryTable returns the quoted name of the table a query selects from, as
+// it's written in the query (e.g. "users" from "select * from users" is
+// "USERS" in a dialect which folds identifiers to upper case). It returns
+// false if the query doesn't select from a single table.
+func (d *Dialect) QueryTable(query string) (string, bool) {
+	match := queryTableRe.FindStringSubmatch(normalizeStatement(query))
+	if match == nil || queryJoinRe.MatchString(match[2]) || strings.Contains(strings.TrimRight(match[2], "; "), ";") {
+		return "", false
+	}
+
+	parts := queryTablePartRe.FindAllString(match[1], -1)
+	for i, part := range parts {
+		if strings.HasPrefix(part, "[") {
+			part = `"` + strings.ReplaceAll(part[1:len(part)-1], `"`, `""`) + `"`
+		} else if strings.HasPrefix(part, "`") {
+			part = `"` + strings.ReplaceAll(part[1:len(part)-1], `"`, `""`) + `"`
+		}
+		parts[i] = d.NormalizeIdentifier(part)
+	}
+	return d.QualifiedName(parts...), true
+}
@@ -0,0 +1,76 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/format"
+)
+
+func TestDefaultDialect(t *testing.T) {
+	r := require.New(t)
+
+	d := core.DefaultDialect
+
+	r.Equal(`"we""ird"`, d.QuoteIdentifier(`we"ird`))
+	r.Equal(`"public"."users"`, d.QualifiedName("public", "users"))
+	r.Equal(`"users"`, d.QualifiedName("", "users"))
+
+	r.Equal("NULL", d.Literal(nil))
+	r.Equal("TRUE", d.Literal(true))
+	r.Equal("42", d.Literal(int64(42)))
+	r.Equal("1.5", d.Literal(1.5))
+	r.Equal(`'it''s C:\new'`, d.Literal(`it's C:\new`))
+	r.Equal("'2024-01-02 03:04:05Z'", d.Literal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	r.Equal("Users", d.NormalizeIdentifier("Users"))
+	r.Equal(`My "Table"`, d.NormalizeIdentifier(`"My ""Table"""`))
+
+	r.Equal("?", d.Placeholder(2))
+
+	limited, ok := d.InjectLimit("select * from users", 10)
+	r.True(ok)
+	r.Equal("select * from users\nLIMIT 10", limited)
+}
+
+func TestDialect_QueryTable(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected string
+	}{
+		{query: "select * from users", expected: `"users"`},
+		{query: "SELECT id, name FROM public.users WHERE id > 10;", expected: `"public"."users"`},
+		{query: `select * from "My Schema"."Users" u order by 1`, expected: `"My Schema"."Users"`},
+		{query: "select * from [dbo].[Users]", expected: `"dbo"."Users"`},
+		{query: "-- comment\nselect 'from x' from `db`.`t`", expected: `"db"."t"`},
+		{query: "select * from a join b on a.id = b.id"},
+		{query: "select * from a, b"},
+		{query: "select * from a; select * from b"},
+		{query: "select 1"},
+		{query: "update users set x = 1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			table, ok := core.DefaultDialect.QueryTable(tc.query)
+			require.Equal(t, tc.expected != "", ok)
+			require.Equal(t, tc.expected, table)
+		})
+	}
+}
+
+func TestInsertFormatter(t *testing.T) {
+	r := require.New(t)
+
+	out, err := format.NewInsert(core.DefaultDialect, `"users"`).Format(core.Header{"id", "name"}, []core.Row{
+		{1, "o'neil"},
+		{2, nil},
+	}, &core.FormatterOptions{})
+	r.NoError(err)
+	r.Equal(`INSERT INTO "users" ("id", "name") VALUES (1, 'o''neil');
+INSERT INTO "users" ("id", "name") VALUES (2, NULL);
+`, string(out))
+}
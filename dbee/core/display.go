@@ -0,0 +1,144 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	// timezones are also available on systems without a timezone database
+	_ "time/tzdata"
+)
+
+// DisplayOptions configure how values of results are displayed. They don't
+// change the values themselves, so exports and stored results keep raw values.
+type DisplayOptions struct {
+	// Timezone of displayed timestamps (e.g. "UTC", "Local" or
+	// "Europe/Ljubljana"). Timestamps keep their own zone if it's empty.
+	Timezone string
+	// TimeFormat is the layout of displayed timestamps in the form of Go's
+	// reference time (e.g. "2006-01-02 15:04:05").
+	TimeFormat string
+	// ThousandsSeparator is inserted between groups of digits of numbers
+	// (e.g. "," or "_").
+	ThousandsSeparator string
+	// FloatPrecision is the number of decimals of floating point numbers
+	// (nil displays the shortest exact representation).
+	FloatPrecision *int
+}
+
+func (do *DisplayOptions) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Timezone           string `json:"timezone,omitempty"`
+		TimeFormat         string `json:"time_format,omitempty"`
+		ThousandsSeparator string `json:"thousands_separator,omitempty"`
+		FloatPrecision     *int   `json:"float_precision,omitempty"`
+	}{
+		Timezone:           do.Timezone,
+		TimeFormat:         do.TimeFormat,
+		ThousandsSeparator: do.ThousandsSeparator,
+		FloatPrecision:     do.FloatPrecision,
+	})
+}
+
+// IsEmpty reports whether the options change the display of any value.
+func (do *DisplayOptions) IsEmpty() bool {
+	return do.Timezone == "" && do.TimeFormat == "" && do.ThousandsSeparator == "" && do.FloatPrecision == nil
+}
+
+// ValueDisplay converts values to displayed strings according to display
+// options. A nil ValueDisplay displays values as they are.
+type ValueDisplay struct {
+	opts     DisplayOptions
+	location *time.Location
+}
+
+// NewValueDisplay validates the options. It returns nil if the options don't
+// change the display of any value.
+func NewValueDisplay(opts *DisplayOptions) (*ValueDisplay, error) {
+	if opts == nil || opts.IsEmpty() {
+		return nil, nil
+	}
+	if opts.FloatPrecision != nil && *opts.FloatPrecision < 0 {
+		return nil, fmt.Errorf("invalid float precision: %d", *opts.FloatPrecision)
+	}
+
+	vd := &ValueDisplay{opts: *opts}
+	if opts.Timezone != "" {
+		location, err := time.LoadLocation(opts.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("time.LoadLocation: %w", err)
+		}
+		vd.location = location
+	}
+
+	return vd, nil
+}
+
+// String converts the value to a displayed string.
+func (vd *ValueDisplay) String(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	if vd == nil {
+		return fmt.Sprint(value)
+	}
+
+	switch v := value.(type) {
+	case time.Time:
+		if vd.location != nil {
+			v = v.In(vd.location)
+		}
+		if vd.opts.TimeFormat != "" {
+			return v.Format(vd.opts.TimeFormat)
+		}
+		return v.String()
+	case float32:
+		return vd.float(float64(v), 32)
+	case float64:
+		return vd.float(v, 64)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return groupDigits(fmt.Sprint(v), vd.opts.ThousandsSeparator)
+	}
+
+	return fmt.Sprint(value)
+}
+
+func (vd *ValueDisplay) float(f float64, bits int) string {
+	switch {
+	case vd.opts.FloatPrecision != nil:
+		return groupDigits(strconv.FormatFloat(f, 'f', *vd.opts.FloatPrecision, bits), vd.opts.ThousandsSeparator)
+	case vd.opts.ThousandsSeparator != "":
+		// digits are grouped without the exponent notation
+		return groupDigits(strconv.FormatFloat(f, 'f', -1, bits), vd.opts.ThousandsSeparator)
+	}
+	return strconv.FormatFloat(f, 'g', -1, bits)
+}
+
+// groupDigits inserts the separator between groups of three digits of the
+// integer part of a number. Numbers in exponent notation, infinities and NaNs
+// are returned as they are.
+func groupDigits(number, separator string) string {
+	if separator == "" || strings.ContainsAny(number, "eEnN") {
+		return number
+	}
+
+	sign := ""
+	if strings.HasPrefix(number, "-") || strings.HasPrefix(number, "+") {
+		sign, number = number[:1], number[1:]
+	}
+	integer, fraction := number, ""
+	if i := strings.IndexByte(number, '.'); i >= 0 {
+		integer, fraction = number[:i], number[i:]
+	}
+
+	var b strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(separator)
+		}
+		b.WriteRune(digit)
+	}
+
+	return sign + b.String() + fraction
+}
@@ -0,0 +1,137 @@
+package core_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestValueDisplay_String(t *testing.T) {
+	precision := func(p int) *int { return &p }
+	timestamp := time.Date(2024, 3, 1, 22, 30, 15, 0, time.UTC)
+
+	type testCase struct {
+		name     string
+		opts     *core.DisplayOptions
+		value    any
+		expected string
+	}
+
+	testCases := []testCase{
+		{
+			name:     "no options",
+			opts:     nil,
+			value:    1234567.891,
+			expected: "1.234567891e+06",
+		},
+		{
+			name:     "strings are kept",
+			opts:     &core.DisplayOptions{ThousandsSeparator: ","},
+			value:    "1234567",
+			expected: "1234567",
+		},
+		{
+			name:     "integer",
+			opts:     &core.DisplayOptions{ThousandsSeparator: ","},
+			value:    int64(-1234567),
+			expected: "-1,234,567",
+		},
+		{
+			name:     "short integer",
+			opts:     &core.DisplayOptions{ThousandsSeparator: ","},
+			value:    123,
+			expected: "123",
+		},
+		{
+			name:     "float precision",
+			opts:     &core.DisplayOptions{FloatPrecision: precision(2)},
+			value:    3.14159,
+			expected: "3.14",
+		},
+		{
+			name:     "float precision and separator",
+			opts:     &core.DisplayOptions{FloatPrecision: precision(1), ThousandsSeparator: "_"},
+			value:    1234567.891,
+			expected: "1_234_567.9",
+		},
+		{
+			name:     "float separator",
+			opts:     &core.DisplayOptions{ThousandsSeparator: ","},
+			value:    1234567.5,
+			expected: "1,234,567.5",
+		},
+		{
+			name:     "zero precision",
+			opts:     &core.DisplayOptions{FloatPrecision: precision(0)},
+			value:    float32(2.5),
+			expected: "2",
+		},
+		{
+			name:     "timezone",
+			opts:     &core.DisplayOptions{Timezone: "Asia/Tokyo"},
+			value:    timestamp,
+			expected: "2024-03-02 07:30:15 +0900 JST",
+		},
+		{
+			name:     "time format",
+			opts:     &core.DisplayOptions{TimeFormat: "2006-01-02 15:04"},
+			value:    timestamp,
+			expected: "2024-03-01 22:30",
+		},
+		{
+			name:     "nil",
+			opts:     &core.DisplayOptions{ThousandsSeparator: ","},
+			value:    nil,
+			expected: "<nil>",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			display, err := core.NewValueDisplay(tc.opts)
+			r.NoError(err)
+			r.Equal(tc.expected, display.String(tc.value))
+		})
+	}
+}
+
+func TestNewValueDisplay_Invalid(t *testing.T) {
+	r := require.New(t)
+
+	precision := -1
+	_, err := core.NewValueDisplay(&core.DisplayOptions{FloatPrecision: &precision})
+	r.Error(err)
+
+	_, err = core.NewConnection(&core.ConnectionParams{
+		Display: core.DisplayOptions{Timezone: "Nowhere/Unknown"},
+	}, mock.NewAdapter(nil))
+	r.Error(err)
+}
+
+func TestConnectionParams_DisplayJSON(t *testing.T) {
+	r := require.New(t)
+
+	precision := 0
+	params := &core.ConnectionParams{
+		Name: "db",
+		Display: core.DisplayOptions{
+			Timezone:       "UTC",
+			FloatPrecision: &precision,
+		},
+	}
+
+	b, err := json.Marshal(params)
+	r.NoError(err)
+	r.Contains(string(b), `"display":{"timezone":"UTC","float_precision":0}`)
+
+	var decoded core.ConnectionParams
+	r.NoError(json.Unmarshal(b, &decoded))
+	r.Equal(params.Display, decoded.Display)
+}
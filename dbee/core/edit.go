@@ -0,0 +1,275 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrNoKeyColumns    = errors.New("no key columns to identify edited rows")
+	ErrUnknownColumn   = errors.New("unknown column")
+	ErrRowOutOfRange   = errors.New("row index out of range")
+	ErrNothingToChange = errors.New("no edits provided")
+	ErrMaskedKeyColumn = errors.New("key column is masked")
+	// ErrRowsAffected is returned if an edit of a row didn't affect exactly
+	// that row (e.g. keys aren't unique or the row was changed meanwhile).
+	ErrRowsAffected = errors.New("edit didn't affect exactly one row")
+)
+
+// savepoint of edits applied in a transaction in progress
+const editsSavepoint = "dbee_edits"
+
+type (
+	// KeyLister is an optional interface for drivers that can look up
+	// the primary key of a table.
+	KeyLister interface {
+		PrimaryKey(opts *TableOptions) ([]string, error)
+	}
+
+	// IdentifierQuoter is an optional interface for drivers that don't quote
+	// identifiers with standard double quotes.
+	IdentifierQuoter interface {
+		QuoteIdentifier(name string) string
+	}
+)
+
+type (
+	// CellEdit changes the value of a single cell of the result.
+	CellEdit struct {
+		// index of the row in the result
+		Row    int
+		Column string
+		Value  any
+	}
+
+	// ResultEdits are changes made to a result in an editable grid.
+	ResultEdits struct {
+		// target table of the edits
+		Table  string
+		Schema string
+		// columns which identify a row. Looked up from the database if empty.
+		KeyColumns []string
+
+		Updates []*CellEdit
+		// new rows as column-value pairs
+		Inserts []map[string]any
+		// indexes of deleted rows in the result
+		Deletes []int
+	}
+)
+
+// GenerateEdits generates UPDATE, INSERT and DELETE statements which apply
+// the edits of the result to the table. Updated and deleted rows are identified
+// by values of key columns in the original result.
+func (c *Connection) GenerateEdits(result *Result, edits *ResultEdits) ([]string, error) {
+	if len(edits.Updates) < 1 && len(edits.Inserts) < 1 && len(edits.Deletes) < 1 {
+		return nil, ErrNothingToChange
+	}
+	if edits.Table == "" {
+		return nil, errors.New("no table provided")
+	}
+
+	dialect := c.Dialect()
+	quote := dialect.QuoteIdentifier
+	table := dialect.QualifiedName(edits.Schema, edits.Table)
+
+	header := result.Header()
+	positions := make(map[string]int, len(header))
+	for i, col := range header {
+		positions[col] = i
+	}
+
+	keys := edits.KeyColumns
+	if len(keys) < 1 && (len(edits.Updates) > 0 || len(edits.Deletes) > 0) {
+		lister, ok := c.driver.(KeyLister)
+		if !ok {
+			return nil, ErrNoKeyColumns
+		}
+		var err error
+		keys, err = lister.PrimaryKey(&TableOptions{
+			Table:  edits.Table,
+			Schema: edits.Schema,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("lister.PrimaryKey: %w", err)
+		}
+		if len(keys) < 1 {
+			return nil, ErrNoKeyColumns
+		}
+	}
+	// masked values don't identify rows
+	var masked []*compiledMaskRule
+	if c.masker != nil {
+		masked, _ = c.masker.columns(header)
+	}
+	for _, key := range keys {
+		pos, ok := positions[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: key column %q is not in the result", ErrUnknownColumn, key)
+		}
+		if masked != nil && masked[pos] != nil {
+			return nil, fmt.Errorf("%w: %q", ErrMaskedKeyColumn, key)
+		}
+	}
+
+	// where clause identifying a row of the result
+	where := func(index int) (string, error) {
+		if index < 0 || index >= result.Len() {
+			return "", fmt.Errorf("%w: %d", ErrRowOutOfRange, index)
+		}
+		rows, err := result.Rows(index, index+1)
+		if err != nil {
+			return "", fmt.Errorf("result.Rows: %w", err)
+		}
+		row := rows[0]
+
+		conds := make([]string, len(keys))
+		for i, key := range keys {
+			val := row[positions[key]]
+			if val == nil {
+				conds[i] = quote(key) + " IS NULL"
+				continue
+			}
+			conds[i] = quote(key) + " = " + dialect.Literal(val)
+		}
+		return strings.Join(conds, " AND "), nil
+	}
+
+	var statements []string
+
+	// group updates by row, so each row gets a single statement
+	byRow := make(map[int][]*CellEdit)
+	var rowOrder []int
+	for _, edit := range edits.Updates {
+		if _, ok := positions[edit.Column]; !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownColumn, edit.Column)
+		}
+		if _, ok := byRow[edit.Row]; !ok {
+			rowOrder = append(rowOrder, edit.Row)
+		}
+		byRow[edit.Row] = append(byRow[edit.Row], edit)
+	}
+	for _, index := range rowOrder {
+		cond, err := where(index)
+		if err != nil {
+			return nil, err
+		}
+
+		sets := make([]string, len(byRow[index]))
+		for i, edit := range byRow[index] {
+			sets[i] = quote(edit.Column) + " = " + dialect.Literal(edit.Value)
+		}
+		statements = append(statements, fmt.Sprintf("UPDATE %s SET %s WHERE %s", table, strings.Join(sets, ", "), cond))
+	}
+
+	for _, insert := range edits.Inserts {
+		if len(insert) < 1 {
+			continue
+		}
+		cols := make([]string, 0, len(insert))
+		for col := range insert {
+			cols = append(cols, col)
+		}
+		sort.Strings(cols)
+
+		quoted := make([]string, len(cols))
+		values := make([]string, len(cols))
+		for i, col := range cols {
+			quoted[i] = quote(col)
+			values[i] = dialect.Literal(insert[col])
+		}
+		statements = append(statements, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(quoted, ", "), strings.Join(values, ", ")))
+	}
+
+	for _, index := range edits.Deletes {
+		cond, err := where(index)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, fmt.Sprintf("DELETE FROM %s WHERE %s", table, cond))
+	}
+
+	return statements, nil
+}
+
+// ApplyEdits executes statements in a transaction. If a transaction is already
+// in progress on the connection, statements are executed in it and it's left open.
+// Otherwise a new transaction is started and committed if all statements succeed.
+//
+// UPDATE and DELETE statements (of GenerateEdits) have to affect exactly one
+// row, otherwise all statements are rolled back (to a savepoint in
+// transactions in progress).
+func (c *Connection) ApplyEdits(statements []string) error {
+	ctx := context.Background()
+
+	run := func(tx Transaction) error {
+		for _, stmt := range statements {
+			if !editsRow(stmt) {
+				result, err := tx.Query(ctx, stmt)
+				if err != nil {
+					return fmt.Errorf("%s: %w", stmt, err)
+				}
+				result.Close()
+				continue
+			}
+
+			execer, ok := tx.(TransactionExecer)
+			if !ok {
+				return fmt.Errorf("%s: %w: affected rows can't be checked", stmt, ErrRowsAffected)
+			}
+			affected, err := execer.Exec(ctx, stmt)
+			if err != nil {
+				return fmt.Errorf("%s: %w", stmt, err)
+			}
+			if affected != 1 {
+				return fmt.Errorf("%s: %w: %d rows affected", stmt, ErrRowsAffected, affected)
+			}
+		}
+		return nil
+	}
+
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+
+	if c.tx != nil {
+		if err := c.tx.Savepoint(ctx, editsSavepoint); err != nil {
+			return fmt.Errorf("c.tx.Savepoint: %w", err)
+		}
+		if err := run(c.tx); err != nil {
+			_ = c.tx.RollbackTo(ctx, editsSavepoint)
+			return err
+		}
+		return nil
+	}
+
+	transactor, ok := c.driver.(Transactor)
+	if !ok {
+		return ErrTransactionsNotSupported
+	}
+
+	tx, err := transactor.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("transactor.BeginTx: %w", err)
+	}
+
+	err = run(tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+	return nil
+}
+
+// editsRow reports whether the statement of GenerateEdits changes an existing row.
+func editsRow(stmt string) bool {
+	verb, _, _ := strings.Cut(strings.TrimSpace(stmt), " ")
+	return strings.EqualFold(verb, "update") || strings.EqualFold(verb, "delete")
+}
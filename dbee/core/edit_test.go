@@ -0,0 +1,182 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_GenerateEdits(t *testing.T) {
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 3),
+		mock.AdapterWithResultStreamOpts(mock.ResultStreamWithHeader(core.Header{"id", "name"})),
+	)
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	call := connection.Execute("select * from users", nil)
+	select {
+	case <-call.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+	result, err := call.GetResult()
+	r.NoError(err)
+
+	edits := &core.ResultEdits{
+		Schema:     "public",
+		Table:      "users",
+		KeyColumns: []string{"id"},
+		Updates: []*core.CellEdit{
+			{Row: 1, Column: "name", Value: "o'neil"},
+		},
+		Inserts: []map[string]any{
+			{"id": 3, "name": nil},
+		},
+		Deletes: []int{2},
+	}
+
+	statements, err := connection.GenerateEdits(result, edits)
+	r.NoError(err)
+	r.Equal([]string{
+		`UPDATE "public"."users" SET "name" = 'o''neil' WHERE "id" = 1`,
+		`INSERT INTO "public"."users" ("id", "name") VALUES (3, NULL)`,
+		`DELETE FROM "public"."users" WHERE "id" = 2`,
+	}, statements)
+
+	// invalid edits
+	_, err = connection.GenerateEdits(result, &core.ResultEdits{Table: "users", KeyColumns: []string{"id"}, Deletes: []int{3}})
+	r.ErrorIs(err, core.ErrRowOutOfRange)
+	_, err = connection.GenerateEdits(result, &core.ResultEdits{Table: "users", KeyColumns: []string{"nope"}, Deletes: []int{0}})
+	r.ErrorIs(err, core.ErrUnknownColumn)
+	// mock doesn't know primary keys
+	_, err = connection.GenerateEdits(result, &core.ResultEdits{Table: "users", Deletes: []int{0}})
+	r.ErrorIs(err, core.ErrNoKeyColumns)
+	_, err = connection.GenerateEdits(result, &core.ResultEdits{Table: "users"})
+	r.ErrorIs(err, core.ErrNothingToChange)
+
+	// statements are applied in a single transaction
+	r.NoError(connection.ApplyEdits(statements))
+	r.Equal([]string{
+		"begin",
+		"exec " + statements[0],
+		"query " + statements[1],
+		"exec " + statements[2],
+		"commit",
+	}, adapter.TransactionOps())
+}
+
+func TestConnection_GenerateEdits_MaskedKey(t *testing.T) {
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 3),
+		mock.AdapterWithResultStreamOpts(mock.ResultStreamWithHeader(core.Header{"id", "email"})),
+	)
+	connection, err := core.NewConnection(&core.ConnectionParams{
+		Masking: []core.MaskRule{{Column: "email", Method: core.MaskHash}},
+	}, adapter)
+	r.NoError(err)
+
+	call := connection.Execute("select * from users", nil)
+	select {
+	case <-call.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+	result, err := call.GetResult()
+	r.NoError(err)
+
+	// masked values don't identify rows
+	_, err = connection.GenerateEdits(result, &core.ResultEdits{Table: "users", KeyColumns: []string{"email"}, Deletes: []int{0}})
+	r.ErrorIs(err, core.ErrMaskedKeyColumn)
+	_, err = connection.GenerateEdits(result, &core.ResultEdits{
+		Table:      "users",
+		KeyColumns: []string{"id", "email"},
+		Updates:    []*core.CellEdit{{Row: 0, Column: "id", Value: 5}},
+	})
+	r.ErrorIs(err, core.ErrMaskedKeyColumn)
+
+	// masked columns can still be edited
+	statements, err := connection.GenerateEdits(result, &core.ResultEdits{
+		Table:      "users",
+		KeyColumns: []string{"id"},
+		Updates:    []*core.CellEdit{{Row: 0, Column: "email", Value: "new"}},
+	})
+	r.NoError(err)
+	r.Equal([]string{`UPDATE "users" SET "email" = 'new' WHERE "id" = 0`}, statements)
+}
+
+func TestConnection_ApplyEdits_RowsAffected(t *testing.T) {
+	const (
+		insert   = `INSERT INTO "users" ("id") VALUES (5)`
+		update   = `UPDATE "users" SET "name" = 'a' WHERE "id" = 1`
+		deleted  = `DELETE FROM "users" WHERE "id" = 2`
+		nothing  = `DELETE FROM "users" WHERE "id" = 3`
+		multiple = `UPDATE "users" SET "name" = 'b' WHERE "id" = 4`
+	)
+
+	type testCase struct {
+		name        string
+		statements  []string
+		expectedErr error
+		expectedOps []string
+	}
+
+	testCases := []testCase{
+		{
+			name:        "single rows",
+			statements:  []string{insert, update, deleted},
+			expectedOps: []string{"begin", "query " + insert, "exec " + update, "exec " + deleted, "commit"},
+		},
+		{
+			name:        "no row",
+			statements:  []string{update, nothing, insert},
+			expectedErr: core.ErrRowsAffected,
+			expectedOps: []string{"begin", "exec " + update, "exec " + nothing, "rollback"},
+		},
+		{
+			name:        "multiple rows",
+			statements:  []string{multiple},
+			expectedErr: core.ErrRowsAffected,
+			expectedOps: []string{"begin", "exec " + multiple, "rollback"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			adapter := mock.NewAdapter(mock.NewRows(0, 3),
+				mock.AdapterWithAffectedRows(nothing, 0),
+				mock.AdapterWithAffectedRows(multiple, 2),
+			)
+			connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+			r.NoError(err)
+
+			err = connection.ApplyEdits(tc.statements)
+			if tc.expectedErr != nil {
+				r.ErrorIs(err, tc.expectedErr)
+			} else {
+				r.NoError(err)
+			}
+			r.Equal(tc.expectedOps, adapter.TransactionOps())
+		})
+	}
+
+	// edits in a transaction in progress are rolled back to a savepoint
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 3), mock.AdapterWithAffectedRows(nothing, 0))
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+	r.NoError(connection.BeginTransaction())
+
+	r.ErrorIs(connection.ApplyEdits([]string{update, nothing}), core.ErrRowsAffected)
+	r.Equal([]string{"begin", "savepoint dbee_edits", "exec " + update, "exec " + nothing, "rollback to dbee_edits"}, adapter.TransactionOps())
+	r.True(connection.InTransaction())
+}
@@ -0,0 +1,252 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrorHintKind is the kind of an ErrorHint.
+type ErrorHintKind string
+
+const (
+	// hint or detail reported by the database
+	ErrorHintDatabase ErrorHintKind = "database"
+	// names of existing objects similar to a missing one
+	ErrorHintSimilarNames ErrorHintKind = "similar_names"
+	// query of another session the failed query conflicted with
+	ErrorHintConflictingQuery ErrorHintKind = "conflicting_query"
+)
+
+// maxSimilarNames is the maximum number of candidates of a similar names hint.
+const maxSimilarNames = 5
+
+type (
+	// ErrorHint is a structured explanation of a query error.
+	ErrorHint struct {
+		Kind    ErrorHintKind
+		Message string
+		// names similar to the missing object (ErrorHintSimilarNames)
+		Candidates []string
+		// query of the other session (ErrorHintConflictingQuery)
+		Query string
+	}
+
+	// ErrorExplainer is an optional interface for drivers which can look up
+	// context of an error in the database, e.g. queries of sessions involved
+	// in a deadlock. Lookups which fail (e.g. because of missing privileges)
+	// are skipped.
+	ErrorExplainer interface {
+		ExplainError(ctx context.Context, qErr *QueryError, query string) []*ErrorHint
+	}
+)
+
+// ExplainError returns hints how to fix the error of the query: hints of the
+// database, names similar to the missing object from the cached structure
+// and context looked up by the driver. Errors which weren't classified (see
+// QueryError) have no hints.
+func (c *Connection) ExplainError(err error, query string) []*ErrorHint {
+	var qErr *QueryError
+	if !errors.As(err, &qErr) {
+		return nil
+	}
+
+	var hints []*ErrorHint
+	if qErr.Hint != "" {
+		hints = append(hints, &ErrorHint{Kind: ErrorHintDatabase, Message: qErr.Hint})
+	}
+	if qErr.Detail != "" {
+		hints = append(hints, &ErrorHint{Kind: ErrorHintDatabase, Message: qErr.Detail})
+	}
+
+	if qErr.Category == ErrorCategoryNotFound && qErr.Object != "" {
+		if candidates := c.similarNames(qErr.ObjectKind, qErr.Object, query); len(candidates) > 0 {
+			hints = append(hints, &ErrorHint{
+				Kind:       ErrorHintSimilarNames,
+				Message:    fmt.Sprintf("%s %q does not exist, did you mean %s?", qErr.ObjectKind, qErr.Object, strings.Join(candidates, ", ")),
+				Candidates: candidates,
+			})
+		}
+	}
+
+	if explainer, ok := c.driver.(ErrorExplainer); ok {
+		hints = append(hints, explainer.ExplainError(context.Background(), qErr, query)...)
+	}
+
+	return hints
+}
+
+// similarNames returns names of objects of the kind which are similar to
+// the (possibly qualified) name. Columns are searched in tables referenced
+// by the query.
+func (c *Connection) similarNames(kind, name, query string) []string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(part, "\"`[]")
+	}
+	name = parts[len(parts)-1]
+	qualifier := strings.ToLower(strings.Join(parts[:len(parts)-1], "."))
+
+	var names []string
+	switch kind {
+	case ErrorObjectColumn:
+		catalog := c.lintCatalog()
+
+		var tables []*Structure
+		for _, stmt := range c.SplitStatements(query) {
+			words, depths, opens := lintWords(lexSQL(stmt.Text, c.statementDialect()))
+			for _, ref := range lintTableRefs(words, depths, opens) {
+				table := catalog.lookup(ref.name)
+				if table == nil {
+					continue
+				}
+				// the column is qualified by the table or its alias
+				if qualifier != "" && qualifier != strings.ToLower(ref.alias) &&
+					qualifier != strings.ToLower(strings.Join(ref.name, ".")) && qualifier != strings.ToLower(table.Name) {
+					continue
+				}
+				tables = append(tables, table)
+			}
+		}
+
+		for _, table := range tables {
+			for _, col := range catalog.columns(table) {
+				if len(tables) > 1 {
+					names = append(names, table.Name+"."+col.Name)
+					continue
+				}
+				names = append(names, col.Name)
+			}
+		}
+	case ErrorObjectTable, ErrorObjectFunction, ErrorObjectSchema:
+		names = c.structure.names(kind)
+	}
+
+	return similarNames(name, names, maxSimilarNames)
+}
+
+// names returns names of cached objects of the kind (see ErrorObject*).
+// Objects in schemas are qualified with the schema.
+func (sc *structureCache) names(kind string) []string {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	root, ok := sc.entries[structureCacheKey(nil)]
+	if !ok {
+		return nil
+	}
+
+	var names []string
+	var walk func(nodes []*Structure)
+	walk = func(nodes []*Structure) {
+		for _, node := range nodes {
+			var match bool
+			switch kind {
+			case ErrorObjectTable:
+				match = node.Type == StructureTypeTable || node.Type == StructureTypeView || node.Type == StructureTypeMaterializedView
+			case ErrorObjectFunction:
+				match = node.Type == StructureTypeFunction || node.Type == StructureTypeProcedure
+			case ErrorObjectSchema:
+				match = node.Type == StructureTypeNone && node.Schema != "" && node.Schema == node.Name
+			}
+			if match {
+				if node.Schema != "" && kind != ErrorObjectSchema {
+					names = append(names, node.Schema+"."+node.Name)
+				} else {
+					names = append(names, node.Name)
+				}
+			}
+
+			children := node.Children
+			if node.Lazy {
+				entry, ok := sc.entries[structureCacheKey(node)]
+				if !ok {
+					continue
+				}
+				children = entry.structure
+			}
+			walk(children)
+		}
+	}
+	walk(root.structure)
+
+	return names
+}
+
+// similarNames returns at most limit names which are similar to name (by
+// edit distance of the unqualified names or by containing each other), the
+// most similar first.
+func similarNames(name string, names []string, limit int) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+
+	name = strings.ToLower(name)
+	maxDistance := len([]rune(name)) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	seen := make(map[string]bool)
+	var candidates []candidate
+	for _, n := range names {
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+
+		short := strings.ToLower(n[strings.LastIndex(n, ".")+1:])
+		if short == name {
+			// the same name in another table or schema
+			candidates = append(candidates, candidate{name: n})
+			continue
+		}
+		d := editDistance(name, short)
+		contains := len(name) > 2 && len(short) > 2 && (strings.Contains(short, name) || strings.Contains(name, short))
+		if d > maxDistance && !contains {
+			continue
+		}
+		candidates = append(candidates, candidate{name: n, distance: d})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].name < candidates[j].name
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	out := make([]string, len(candidates))
+	for i, c := range candidates {
+		out[i] = c.name
+	}
+	return out
+}
+
+// editDistance returns the levenshtein distance of a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
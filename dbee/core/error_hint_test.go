@@ -0,0 +1,99 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_ExplainError(t *testing.T) {
+	r := require.New(t)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(nil,
+		mock.AdapterWithTableDefinition("users", []*core.Column{
+			{Name: "id", Type: "int"},
+			{Name: "name", Type: "text"},
+			{Name: "email", Type: "text"},
+		}),
+		mock.AdapterWithTableDefinition("orders", []*core.Column{
+			{Name: "id", Type: "int"},
+			{Name: "user_id", Type: "int"},
+			{Name: "amount", Type: "int"},
+		}),
+	))
+	r.NoError(err)
+
+	// hints come from the cached structure
+	_, err = connection.GetStructure()
+	r.NoError(err)
+
+	type testCase struct {
+		name          string
+		err           error
+		query         string
+		expectedHints []*core.ErrorHint
+	}
+
+	testCases := []testCase{
+		{
+			name:  "misspelled column",
+			err:   &core.QueryError{Category: core.ErrorCategoryNotFound, ObjectKind: core.ErrorObjectColumn, Object: "nmae", Err: errors.New("_")},
+			query: "SELECT nmae FROM users",
+			expectedHints: []*core.ErrorHint{{
+				Kind:       core.ErrorHintSimilarNames,
+				Message:    `column "nmae" does not exist, did you mean name?`,
+				Candidates: []string{"name"},
+			}},
+		},
+		{
+			name:  "qualified column of joined tables",
+			err:   &core.QueryError{Category: core.ErrorCategoryNotFound, ObjectKind: core.ErrorObjectColumn, Object: "o.userid", Err: errors.New("_")},
+			query: "SELECT o.userid FROM users u JOIN orders o ON o.user_id = u.id",
+			expectedHints: []*core.ErrorHint{{
+				Kind:       core.ErrorHintSimilarNames,
+				Message:    `column "o.userid" does not exist, did you mean user_id?`,
+				Candidates: []string{"user_id"},
+			}},
+		},
+		{
+			name:  "misspelled table",
+			err:   &core.QueryError{Category: core.ErrorCategoryNotFound, ObjectKind: core.ErrorObjectTable, Object: "order", Err: errors.New("_")},
+			query: "SELECT * FROM order",
+			expectedHints: []*core.ErrorHint{{
+				Kind:       core.ErrorHintSimilarNames,
+				Message:    `table "order" does not exist, did you mean orders?`,
+				Candidates: []string{"orders"},
+			}},
+		},
+		{
+			name:  "hint and detail of the database",
+			err:   &core.QueryError{Category: core.ErrorCategoryConstraint, Hint: "drop the row first", Detail: "key (id)=(1) is referenced", Err: errors.New("_")},
+			query: "DELETE FROM users",
+			expectedHints: []*core.ErrorHint{
+				{Kind: core.ErrorHintDatabase, Message: "drop the row first"},
+				{Kind: core.ErrorHintDatabase, Message: "key (id)=(1) is referenced"},
+			},
+		},
+		{
+			name:  "nothing similar",
+			err:   &core.QueryError{Category: core.ErrorCategoryNotFound, ObjectKind: core.ErrorObjectColumn, Object: "created_at", Err: errors.New("_")},
+			query: "SELECT created_at FROM users",
+		},
+		{
+			name:  "unclassified error",
+			err:   errors.New("connection refused"),
+			query: "SELECT 1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			r.Equal(tc.expectedHints, connection.ExplainError(tc.err, tc.query))
+		})
+	}
+}
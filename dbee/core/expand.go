@@ -3,6 +3,7 @@ package core
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"strings"
@@ -12,8 +13,9 @@ import (
 func expand(value string) (string, error) {
 	tmpl, err := template.New("expand_variables").
 		Funcs(template.FuncMap{
-			"env":  os.Getenv,
-			"exec": execCommand,
+			"env":    os.Getenv,
+			"exec":   execCommand,
+			"secret": missingSecret,
 		}).
 		Parse(value)
 	if err != nil {
@@ -46,6 +48,12 @@ func execCommand(line string) (string, error) {
 	return strings.TrimSpace(string(out)), err
 }
 
+// missingSecret fails expansion of placeholders of secrets which weren't
+// provided on import (see ExportConnections).
+func missingSecret(name string) (string, error) {
+	return "", fmt.Errorf("missing secret %q", name)
+}
+
 // expandOrDefault silently suppresses errors.
 func expandOrDefault(value string) string {
 	ex, err := expand(value)
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+const (
+	// rows formatted at once by stream formatters
+	exportChunkRows = 1000
+	// bytes written at once by other formatters
+	exportChunkBytes = 64 * 1024
+)
+
+// ExportProgress is reported after every chunk written by an export.
+type ExportProgress struct {
+	// rows written so far
+	Rows int
+	// number of exported rows
+	RowsTotal int
+	// bytes written so far
+	BytesWritten int64
+}
+
+// Export writes rows in range from-to of the result to w. Stream formatters
+// format and write the rows in chunks, other formatters format all rows at
+// once and only writing is chunked. Progress is reported after every chunk and
+// the export stops with ctx.Err() when ctx is canceled.
+func (cr *Result) Export(ctx context.Context, w io.Writer, formatter Formatter, from, to int, progress func(*ExportProgress)) error {
+	rows, fromAdjusted, _, err := cr.getRows(from, to)
+	if err != nil {
+		return fmt.Errorf("cr.getRows: %w", err)
+	}
+
+	p := &ExportProgress{RowsTotal: len(rows)}
+	write := func(b []byte) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := w.Write(b)
+		p.BytesWritten += int64(n)
+		return err
+	}
+	report := func() {
+		if progress != nil {
+			progress(&ExportProgress{Rows: p.Rows, RowsTotal: p.RowsTotal, BytesWritten: p.BytesWritten})
+		}
+	}
+
+	opts := &FormatterOptions{
+		SchemaType: cr.meta.SchemaType,
+		ChunkStart: fromAdjusted,
+	}
+
+	stream, ok := formatter.(StreamFormatter)
+	if !ok {
+		out, err := formatter.Format(cr.header, rows, opts)
+		if err != nil {
+			return fmt.Errorf("formatter.Format: %w", err)
+		}
+		for start := 0; start < len(out); start += exportChunkBytes {
+			if err := write(out[start:min(start+exportChunkBytes, len(out))]); err != nil {
+				return err
+			}
+			report()
+		}
+		p.Rows = len(rows)
+		report()
+		return nil
+	}
+
+	// an empty result is a single chunk with the header only
+	for start := 0; start == 0 || start < len(rows); start += exportChunkRows {
+		end := min(start+exportChunkRows, len(rows))
+		opts.ChunkStart = fromAdjusted + start
+
+		out, err := stream.FormatChunk(cr.header, rows[start:end], opts, start == 0, end == len(rows))
+		if err != nil {
+			return fmt.Errorf("stream.FormatChunk: %w", err)
+		}
+		if err := write(out); err != nil {
+			return err
+		}
+		p.Rows = end
+		report()
+	}
+
+	return nil
+}
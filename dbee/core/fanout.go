@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// FanOutConnectionColumn is the name of the column prepended to every row
+// of a fan-out result.
+const FanOutConnectionColumn = "connection"
+
+// ExecuteOnMany executes the same query on all provided connections concurrently.
+// Results are aggregated into a single result with the name of the connection
+// prepended to every row. Connections which fail don't fail the whole call -
+// their errors are reported in result's notices instead.
+func ExecuteOnMany(connections []*Connection, query string, confirmed bool, onEvent func(CallState, *Call)) *Call {
+	exec := func(ctx context.Context) (ResultStream, error) {
+		if len(connections) < 1 {
+			return nil, errors.New("no connections provided")
+		}
+
+		parts := make([]*fanOutPart, len(connections))
+
+		var wg sync.WaitGroup
+		for i, c := range connections {
+			i, c := i, c
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				parts[i] = runFanOutPart(ctx, c, query, confirmed)
+			}()
+		}
+		wg.Wait()
+
+		return mergeFanOutParts(parts)
+	}
+
+	return newCallFromExecutor(exec, query, onEvent)
+}
+
+type fanOutPart struct {
+	name   string
+	header Header
+	rows   []Row
+	err    error
+}
+
+func runFanOutPart(ctx context.Context, c *Connection, query string, confirmed bool) *fanOutPart {
+	part := &fanOutPart{name: c.GetName()}
+
+	iter, err := c.executor(query, confirmed)(ctx)
+	if err != nil {
+		part.err = err
+		return part
+	}
+	defer iter.Close()
+
+	part.header = iter.Header()
+	for iter.HasNext() {
+		row, err := iter.Next()
+		if err != nil {
+			part.err = err
+			return part
+		}
+		part.rows = append(part.rows, row)
+	}
+
+	return part
+}
+
+func mergeFanOutParts(parts []*fanOutPart) (ResultStream, error) {
+	meta := &Meta{}
+	var header Header
+	var rows []Row
+	var errs []error
+
+	for _, part := range parts {
+		if part.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", part.name, part.err))
+			meta.Notices = append(meta.Notices, fmt.Sprintf("%s: %s", part.name, part.err))
+			continue
+		}
+
+		// header of the first successful connection is used for the whole result
+		if header == nil {
+			header = append(Header{FanOutConnectionColumn}, part.header...)
+		}
+		if len(part.header) != len(header)-1 {
+			meta.Notices = append(meta.Notices, fmt.Sprintf("%s: skipped - columns don't match the other results", part.name))
+			continue
+		}
+
+		for _, row := range part.rows {
+			rows = append(rows, append(Row{part.name}, row...))
+		}
+	}
+
+	if len(errs) == len(parts) {
+		return nil, errors.Join(errs...)
+	}
+
+	return newSliceStream(header, rows, meta), nil
+}
+
+// sliceStream is a ResultStream over rows which are already in memory.
+type sliceStream struct {
+	header Header
+	meta   *Meta
+	rows   []Row
+	index  int
+}
+
+func newSliceStream(header Header, rows []Row, meta *Meta) *sliceStream {
+	return &sliceStream{
+		header: header,
+		meta:   meta,
+		rows:   rows,
+	}
+}
+
+func (s *sliceStream) Meta() *Meta {
+	return s.meta
+}
+
+func (s *sliceStream) Header() Header {
+	return s.header
+}
+
+func (s *sliceStream) HasNext() bool {
+	return s.index < len(s.rows)
+}
+
+func (s *sliceStream) Next() (Row, error) {
+	if !s.HasNext() {
+		return nil, errors.New("no next row")
+	}
+	row := s.rows[s.index]
+	s.index++
+	return row, nil
+}
+
+func (s *sliceStream) Close() {}
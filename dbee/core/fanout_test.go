@@ -0,0 +1,77 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestExecuteOnMany(t *testing.T) {
+	r := require.New(t)
+
+	rows := mock.NewRows(0, 3)
+
+	first, err := core.NewConnection(&core.ConnectionParams{Name: "first"}, mock.NewAdapter(rows))
+	r.NoError(err)
+	second, err := core.NewConnection(&core.ConnectionParams{Name: "second"}, mock.NewAdapter(rows))
+	r.NoError(err)
+	failing, err := core.NewConnection(&core.ConnectionParams{Name: "failing"}, mock.NewAdapter(rows,
+		mock.AdapterWithQuerySideEffect("_", func(context.Context) error {
+			return errors.New("connection refused")
+		}),
+	))
+	r.NoError(err)
+
+	call := core.ExecuteOnMany([]*core.Connection{first, failing, second}, "_", false, nil)
+
+	select {
+	case <-call.Done():
+	case <-time.After(5 * time.Second):
+		t.Error("call did not finish in expected time")
+	}
+	r.NoError(call.Err())
+
+	result, err := call.GetResult()
+	r.NoError(err)
+
+	r.Equal(core.Header{core.FanOutConnectionColumn, "header_0", "header_1"}, result.Header())
+	r.Len(result.Meta().Notices, 1)
+	r.Contains(result.Meta().Notices[0], "failing")
+
+	actualRows, err := result.Rows(0, 2*len(rows))
+	r.NoError(err)
+	r.Len(actualRows, 2*len(rows))
+	for i, row := range actualRows {
+		name := "first"
+		if i >= len(rows) {
+			name = "second"
+		}
+		r.Equal(append(core.Row{name}, rows[i%len(rows)]...), row)
+	}
+}
+
+func TestExecuteOnMany_AllFailed(t *testing.T) {
+	r := require.New(t)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{Name: "failing"}, mock.NewAdapter(nil,
+		mock.AdapterWithQuerySideEffect("_", func(context.Context) error {
+			return errors.New("connection refused")
+		}),
+	))
+	r.NoError(err)
+
+	call := core.ExecuteOnMany([]*core.Connection{connection}, "_", false, nil)
+
+	select {
+	case <-call.Done():
+	case <-time.After(5 * time.Second):
+		t.Error("call did not finish in expected time")
+	}
+	r.ErrorContains(call.Err(), "connection refused")
+}
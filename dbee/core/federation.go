@@ -0,0 +1,220 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrFederationNotSupported is returned if the engine of a federated query
+// isn't an embedded database.
+var ErrFederationNotSupported = errors.New("federated queries need an embedded engine (sqlite, scratch or demo connection)")
+
+// federationEngines are types of connections which can run federated queries.
+// Rows of other connections are copied into them, so only local databases
+// are allowed.
+var federationEngines = map[string]bool{
+	"sqlite":  true,
+	"sqlite3": true,
+	"scratch": true,
+	"demo":    true,
+}
+
+// FederatedTable is a table of a federated query. It's filled with rows of a
+// query on a connection or with rows of a result set of a call (e.g. a record
+// of the call history).
+type FederatedTable struct {
+	// name of the table in the federated query
+	Name string
+	// connection and query whose rows fill the table, rows are masked and
+	// limited by the connection as usual
+	Connection *Connection
+	Query      string
+	// result set of a finished call whose rows fill the table (used instead
+	// of Connection)
+	Call      *Call
+	ResultSet int
+}
+
+// Federate copies rows of the tables into the connection, which has to be an
+// embedded database, and runs the query on it, so that data of different
+// connections (e.g. postgres and mysql, or a history record and a live table)
+// can be joined. Tables are created again on every call. Types of columns
+// are inferred from values, notices of the result list loaded tables.
+func (c *Connection) Federate(tables []*FederatedTable, query string, onEvent func(CallState, *Call)) (*Call, error) {
+	importer, ok := c.driver.(Importer)
+	if !ok || !federationEngines[c.GetType()] {
+		return nil, ErrFederationNotSupported
+	}
+	if len(tables) < 1 {
+		return nil, errors.New("no tables provided")
+	}
+	names := make(map[string]bool)
+	for _, table := range tables {
+		if table.Name == "" {
+			return nil, errors.New("federated table has no name")
+		}
+		if names[strings.ToLower(table.Name)] {
+			return nil, fmt.Errorf("duplicate federated table: %q", table.Name)
+		}
+		names[strings.ToLower(table.Name)] = true
+		if table.Call == nil && (table.Connection == nil || table.Query == "") {
+			return nil, fmt.Errorf("federated table %q has no source", table.Name)
+		}
+	}
+
+	exec := func(ctx context.Context) (ResultStream, error) {
+		var notices []string
+		for _, table := range tables {
+			header, rows, source, err := federatedRows(ctx, table)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", table.Name, err)
+			}
+			if err := c.loadFederatedTable(ctx, importer, table.Name, header, rows); err != nil {
+				return nil, fmt.Errorf("%s: %w", table.Name, err)
+			}
+			notices = append(notices, fmt.Sprintf("%s: %d rows from %s", table.Name, len(rows), source))
+		}
+
+		iter, err := c.executor(query, true)(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if meta := iter.Meta(); meta != nil {
+			meta.Notices = append(notices, meta.Notices...)
+		}
+		return iter, nil
+	}
+
+	return newCallFromExecutor(exec, query, onEvent), nil
+}
+
+// federatedRows returns rows of the source of the table and a description
+// of the source.
+func federatedRows(ctx context.Context, table *FederatedTable) (Header, []Row, string, error) {
+	if table.Call != nil {
+		select {
+		case <-table.Call.Done():
+		default:
+			return nil, nil, "", fmt.Errorf("call %s is still running", table.Call.GetID())
+		}
+		if err := table.Call.Err(); err != nil {
+			return nil, nil, "", fmt.Errorf("call %s failed: %w", table.Call.GetID(), err)
+		}
+		res, err := table.Call.GetResultSet(table.ResultSet)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		rows, err := res.Rows(0, res.Len())
+		if err != nil {
+			return nil, nil, "", err
+		}
+		return res.Header(), rows, fmt.Sprintf("call %s", table.Call.GetID()), nil
+	}
+
+	// reuse fan-out parts, which read all rows of a query on a connection
+	part := runFanOutPart(ctx, table.Connection, table.Query, false)
+	if part.err != nil {
+		return nil, nil, "", part.err
+	}
+	return part.header, part.rows, part.name, nil
+}
+
+// loadFederatedTable creates the table in the engine and imports the rows.
+func (c *Connection) loadFederatedTable(ctx context.Context, importer Importer, name string, header Header, rows []Row) error {
+	if len(header) < 1 {
+		return errors.New("source has no columns")
+	}
+
+	columns := federatedColumns(header)
+	definitions := make([]string, len(columns))
+	for i, col := range columns {
+		definitions[i] = quoteIdentifier(col) + " " + federatedColumnType(rows, i)
+	}
+
+	table := quoteIdentifier(name)
+	for _, stmt := range []string{
+		"DROP TABLE IF EXISTS " + table,
+		fmt.Sprintf("CREATE TABLE %s (%s)", table, strings.Join(definitions, ", ")),
+	} {
+		if _, err := queryAll(ctx, c.driver.Query, stmt); err != nil {
+			return classifyError(c.driver, err, stmt)
+		}
+	}
+
+	for from := 0; from < len(rows); from += defaultImportBatchSize {
+		to := min(from+defaultImportBatchSize, len(rows))
+		batch := make([]Row, to-from)
+		for i, row := range rows[from:to] {
+			batch[i] = make(Row, len(columns))
+			for j := range columns {
+				if j < len(row) {
+					batch[i][j] = federatedValue(row[j])
+				}
+			}
+		}
+		if err := importer.ImportBatch(ctx, name, columns, batch); err != nil {
+			return classifyError(c.driver, err, "")
+		}
+	}
+
+	return nil
+}
+
+// federatedColumns returns unique names of columns of the header (results
+// can have duplicate or empty column names, tables can't).
+func federatedColumns(header Header) []string {
+	columns := make([]string, len(header))
+	seen := make(map[string]bool)
+	for i, name := range header {
+		if name == "" {
+			name = fmt.Sprintf("column_%d", i+1)
+		}
+		unique := name
+		for n := 2; seen[strings.ToLower(unique)]; n++ {
+			unique = fmt.Sprintf("%s_%d", name, n)
+		}
+		seen[strings.ToLower(unique)] = true
+		columns[i] = unique
+	}
+	return columns
+}
+
+// federatedColumnType infers the type of the column from its first non-NULL
+// value.
+func federatedColumnType(rows []Row, column int) string {
+	for _, row := range rows {
+		if column >= len(row) || row[column] == nil {
+			continue
+		}
+		switch row[column].(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			return "BIGINT"
+		case float32, float64:
+			return "DOUBLE"
+		case bool:
+			return "BOOLEAN"
+		case time.Time:
+			return "TIMESTAMP"
+		case []byte:
+			return "BLOB"
+		}
+		return "TEXT"
+	}
+	return "TEXT"
+}
+
+// federatedValue converts values of drivers' own types (e.g. numerics or
+// uuids) to values which can be inserted into the engine.
+func federatedValue(val any) any {
+	switch v := val.(type) {
+	case nil, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64,
+		float32, float64, bool, string, []byte, time.Time:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(val)
+}
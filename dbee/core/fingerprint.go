@@ -0,0 +1,291 @@
+package core
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// fingerprintToken is a token of a fingerprint.
+type fingerprintToken struct {
+	kind    sqlTokenKind
+	text    string
+	keyword bool
+	// separated from the previous token of the query by whitespace
+	spaced bool
+}
+
+// QueryFingerprint returns the anonymized form of the query, which is the
+// same for queries that differ only in values: literals and bind parameters
+// are replaced with "?", comments are removed, keywords are in upper case and
+// whitespace is normalized (e.g. "select * from users where id = 42 -- me"
+// becomes "SELECT * FROM users WHERE id = ?"). Values of IN lists are
+// collapsed to a single value and repeated rows of VALUES to a single row.
+func QueryFingerprint(query string, dialect *StatementDialect) string {
+	tokens := lexSQL(query, dialect)
+
+	var out []*fingerprintToken
+	// indexes of open parentheses in out
+	var open []int
+
+	for i, tok := range tokens {
+		var prev *fingerprintToken
+		if len(out) > 0 {
+			prev = out[len(out)-1]
+		}
+
+		switch {
+		case tok.kind == sqlTokenComment:
+			continue
+		case tok.kind == sqlTokenString, tok.kind == sqlTokenNumber, isBindParameter(tok):
+			out = append(out, &fingerprintToken{kind: sqlTokenPunct, text: "?"})
+			continue
+		case tok.is("-", "+") && i+1 < len(tokens) && tokens[i+1].kind == sqlTokenNumber:
+			// sign of a number is a part of the literal, unless it's an operator
+			// (e.g. "a - 1")
+			if prev == nil || (prev.kind == sqlTokenPunct && prev.text != ")" && prev.text != "?") || prev.keyword {
+				continue
+			}
+		}
+
+		ftok := &fingerprintToken{kind: tok.kind, text: tok.text, spaced: i > 0 && tokens[i-1].end != tok.start}
+		if tok.kind == sqlTokenWord && formatKeywords[tok.upper()] &&
+			(prev == nil || prev.text != ".") && (i+1 >= len(tokens) || !tokens[i+1].is(".")) {
+			ftok.text = tok.upper()
+			ftok.keyword = true
+		}
+		out = append(out, ftok)
+
+		switch {
+		case tok.is("("):
+			open = append(open, len(out)-1)
+		case tok.is(")") && len(open) > 0:
+			start := open[len(open)-1]
+			open = open[:len(open)-1]
+			out = collapseValueList(out, start)
+		}
+	}
+
+	// trailing delimiters
+	for len(out) > 0 && out[len(out)-1].text == ";" {
+		out = out[:len(out)-1]
+	}
+
+	var sb strings.Builder
+	for i, tok := range out {
+		if i > 0 && fingerprintSpace(out[i-1], tok) {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(tok.text)
+	}
+	return sb.String()
+}
+
+// QueryFingerprint returns the anonymized form of the query (see
+// [QueryFingerprint]) using the dialect of the connection.
+func (c *Connection) QueryFingerprint(query string) string {
+	return QueryFingerprint(query, c.statementDialect())
+}
+
+// isBindParameter reports whether the token is a bind parameter ("?", "$1"
+// or ":name").
+func isBindParameter(tok *sqlToken) bool {
+	if tok.kind == sqlTokenPunct {
+		return tok.text == "?"
+	}
+	return tok.kind == sqlTokenWord && len(tok.text) > 1 && (tok.text[0] == '$' || tok.text[0] == ':')
+}
+
+// isValueList reports whether the tokens are values separated by commas.
+func isValueList(tokens []*fingerprintToken) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	for i, tok := range tokens {
+		if (i%2 == 0 && tok.text != "?") || (i%2 == 1 && tok.text != ",") {
+			return false
+		}
+	}
+	return len(tokens)%2 == 1
+}
+
+// collapseValueList collapses the parenthesized list which starts at index
+// start and ends with the last token: values of IN are collapsed to a single
+// value and a row which repeats the previous one is removed.
+func collapseValueList(out []*fingerprintToken, start int) []*fingerprintToken {
+	end := len(out) - 1
+	if !isValueList(out[start+1 : end]) {
+		return out
+	}
+
+	if start > 0 && out[start-1].keyword && out[start-1].text == "IN" {
+		return append(out[:start+1], out[start+1], out[end])
+	}
+
+	// previous row is "(...)," right before the list
+	size := end - start + 1
+	prevStart := start - 1 - size
+	if prevStart < 0 || out[start-1].text != "," {
+		return out
+	}
+	for i := 0; i < size; i++ {
+		if out[prevStart+i].text != out[start+i].text {
+			return out
+		}
+	}
+	return out[:start-1]
+}
+
+// fingerprintSpace reports whether tok is separated from the previous token
+// in a fingerprint.
+func fingerprintSpace(prev, tok *fingerprintToken) bool {
+	switch {
+	case prev.text == "(" || prev.text == "." || prev.text == "::":
+		return false
+	case tok.text == "," || tok.text == ")" || tok.text == ";" || tok.text == "." || tok.text == "::":
+		return false
+	case tok.text == "(":
+		// function calls stay as they are written (e.g. "count(*)")
+		return tok.spaced || prev.keyword || (prev.kind != sqlTokenWord && prev.kind != sqlTokenQuotedIdent)
+	}
+	return true
+}
+
+// QueryStats are statistics of the calls of a query fingerprint.
+type QueryStats struct {
+	Fingerprint string
+	// newest call with the fingerprint
+	LastCall *Call
+	// number of calls
+	Count int
+	// number of calls which failed
+	Failed int
+	// total duration of the calls
+	TotalTime time.Duration
+}
+
+// AverageTime returns the average duration of the calls.
+func (qs *QueryStats) AverageTime() time.Duration {
+	if qs.Count == 0 {
+		return 0
+	}
+	return qs.TotalTime / time.Duration(qs.Count)
+}
+
+// QueryStatsOptions select and order statistics of query fingerprints.
+type QueryStatsOptions struct {
+	// order by the number of calls instead of the newest call
+	ByFrequency bool
+	// fuzzy pattern matched against fingerprints, matches are ordered by
+	// their score first (e.g. "selusr" matches "SELECT * FROM users ...")
+	Pattern string
+	// maximum number of returned statistics, 0 returns all of them
+	Limit int
+}
+
+// GetQueryStats groups the calls by fingerprints of their queries, so calls
+// of the same query with different values are a single entry of the history.
+func GetQueryStats(calls []*Call, dialect *StatementDialect, opts *QueryStatsOptions) []*QueryStats {
+	if opts == nil {
+		opts = &QueryStatsOptions{}
+	}
+
+	lookup := make(map[string]*QueryStats)
+	var stats []*QueryStats
+	for _, call := range calls {
+		if call == nil {
+			continue
+		}
+		fingerprint := QueryFingerprint(call.GetQuery(), dialect)
+		if fingerprint == "" {
+			continue
+		}
+
+		st, ok := lookup[fingerprint]
+		if !ok {
+			st = &QueryStats{Fingerprint: fingerprint}
+			lookup[fingerprint] = st
+			stats = append(stats, st)
+		}
+		st.Count++
+		st.TotalTime += call.GetTimeTaken()
+		if state := call.GetState(); state == CallStateExecutingFailed || state == CallStateRetrievingFailed {
+			st.Failed++
+		}
+		if st.LastCall == nil || !call.GetTimestamp().Before(st.LastCall.GetTimestamp()) {
+			st.LastCall = call
+		}
+	}
+
+	scores := make(map[*QueryStats]int, len(stats))
+	if opts.Pattern != "" {
+		matched := stats[:0]
+		for _, st := range stats {
+			score, ok := fuzzyScore(st.Fingerprint, opts.Pattern)
+			if ok {
+				scores[st] = score
+				matched = append(matched, st)
+			}
+		}
+		stats = matched
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if scores[a] != scores[b] {
+			return scores[a] > scores[b]
+		}
+		if opts.ByFrequency && a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.LastCall.GetTimestamp().After(b.LastCall.GetTimestamp())
+	})
+
+	if opts.Limit > 0 && len(stats) > opts.Limit {
+		stats = stats[:opts.Limit]
+	}
+	return stats
+}
+
+// GetQueryStats groups the calls by fingerprints of their queries (see
+// [GetQueryStats]) using the dialect of the connection.
+func (c *Connection) GetQueryStats(calls []*Call, opts *QueryStatsOptions) []*QueryStats {
+	return GetQueryStats(calls, c.statementDialect(), opts)
+}
+
+// fuzzyScore matches words of the pattern against the text as case
+// insensitive subsequences. Consecutive characters and characters at the
+// start of words score higher.
+func fuzzyScore(text, pattern string) (int, bool) {
+	runes := []rune(strings.ToLower(text))
+
+	score := 0
+	for _, word := range strings.Fields(strings.ToLower(pattern)) {
+		pos := 0
+		last := -2
+		for _, pr := range word {
+			found := -1
+			for i := pos; i < len(runes); i++ {
+				if runes[i] == pr {
+					found = i
+					break
+				}
+			}
+			if found < 0 {
+				return 0, false
+			}
+
+			score++
+			if found == last+1 {
+				score += 4
+			}
+			if found == 0 || !(unicode.IsLetter(runes[found-1]) || unicode.IsDigit(runes[found-1]) || runes[found-1] == '_') {
+				score += 2
+			}
+			last = found
+			pos = found + 1
+		}
+	}
+	return score, true
+}
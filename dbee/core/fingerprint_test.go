@@ -0,0 +1,100 @@
+package core_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestQueryFingerprint(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected string
+	}{
+		{
+			query:    "select * from users where id = 42",
+			expected: "SELECT * FROM users WHERE id = ?",
+		},
+		{
+			query:    "SELECT *\n  FROM users -- mine\n WHERE id=7 /* x */ AND name = 'o''neil';",
+			expected: "SELECT * FROM users WHERE id = ? AND name = ?",
+		},
+		{
+			query:    "select a - 1, -2.5, count(*) from t where x > -3",
+			expected: "SELECT a - ?, ?, count(*) FROM t WHERE x > ?",
+		},
+		{
+			query:    "select * from t where id in (1, 2, 3) and k in (4)",
+			expected: "SELECT * FROM t WHERE id IN (?) AND k IN (?)",
+		},
+		{
+			query:    "insert into t (a, b) values (1, 'x'), (2, 'y'), (3, 'z')",
+			expected: "INSERT INTO t (a, b) VALUES (?, ?)",
+		},
+		{
+			query:    "select * from t where a = $1 and b = :name and c = ?",
+			expected: "SELECT * FROM t WHERE a = ? AND b = ? AND c = ?",
+		},
+		{
+			query:    `select u.id, "Order".total::text from u join "Order" on u.id = "Order".user_id`,
+			expected: `SELECT u.id, "Order".total::text FROM u JOIN "Order" ON u.id = "Order".user_id`,
+		},
+		{
+			query:    "select 1; select 2;",
+			expected: "SELECT ?; SELECT ?",
+		},
+		{
+			query:    "-- only a comment",
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			require.Equal(t, tc.expected, core.QueryFingerprint(tc.query, nil))
+		})
+	}
+}
+
+func TestGetQueryStats(t *testing.T) {
+	r := require.New(t)
+
+	var calls []*core.Call
+	r.NoError(json.Unmarshal([]byte(`[
+		{"id": "1", "query": "select * from users where id = 1", "state": "archived", "time_taken_us": 100, "timestamp_us": 1000},
+		{"id": "2", "query": "select count(*) from orders", "state": "archived", "time_taken_us": 500, "timestamp_us": 2000},
+		{"id": "3", "query": "SELECT * FROM users WHERE id = 2", "state": "executing_failed", "time_taken_us": 200, "timestamp_us": 3000},
+		{"id": "4", "query": "select * from users where id = 3", "state": "archived", "time_taken_us": 300, "timestamp_us": 4000},
+		{"id": "5", "query": "delete from sessions", "state": "archived", "time_taken_us": 50, "timestamp_us": 5000}
+	]`), &calls))
+
+	// newest first
+	stats := core.GetQueryStats(calls, nil, nil)
+	r.Len(stats, 3)
+	r.Equal("DELETE FROM sessions", stats[0].Fingerprint)
+	r.Equal("SELECT * FROM users WHERE id = ?", stats[1].Fingerprint)
+	r.Equal(core.CallID("4"), stats[1].LastCall.GetID())
+	r.Equal(3, stats[1].Count)
+	r.Equal(1, stats[1].Failed)
+	r.EqualValues(200_000, stats[1].AverageTime())
+
+	// most frequent first
+	stats = core.GetQueryStats(calls, nil, &core.QueryStatsOptions{ByFrequency: true, Limit: 2})
+	r.Len(stats, 2)
+	r.Equal("SELECT * FROM users WHERE id = ?", stats[0].Fingerprint)
+	r.Equal("DELETE FROM sessions", stats[1].Fingerprint)
+
+	// fuzzy search
+	stats = core.GetQueryStats(calls, nil, &core.QueryStatsOptions{Pattern: "count ord"})
+	r.Len(stats, 1)
+	r.Equal("SELECT count(*) FROM orders", stats[0].Fingerprint)
+
+	stats = core.GetQueryStats(calls, nil, &core.QueryStatsOptions{Pattern: "from"})
+	r.Len(stats, 3)
+
+	stats = core.GetQueryStats(calls, nil, &core.QueryStatsOptions{Pattern: "xyz"})
+	r.Empty(stats)
+}
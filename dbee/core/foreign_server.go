@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrForeignServersNotSupported = errors.New("listing foreign servers not supported")
+
+type (
+	// ForeignServer is a remote server the database can query
+	// (e.g. a postgres foreign server or a sql server linked server).
+	ForeignServer struct {
+		Name string
+		// foreign data wrapper or provider used to connect to the server
+		Wrapper string
+		// connection options (e.g. host and port)
+		Options string
+	}
+
+	// ForeignServerLister is an optional interface for drivers that can list
+	// foreign servers and objects accessible through them.
+	ForeignServerLister interface {
+		ForeignServers(ctx context.Context) ([]*ForeignServer, error)
+		// ForeignObjects lists tables and views of the server. Listing fails
+		// if the server is not accessible.
+		ForeignObjects(ctx context.Context, server string) ([]*Structure, error)
+	}
+)
+
+// GetForeignServers returns foreign (linked) servers of the database.
+func (c *Connection) GetForeignServers() ([]*ForeignServer, error) {
+	lister, ok := c.driver.(ForeignServerLister)
+	if !ok {
+		return nil, ErrForeignServersNotSupported
+	}
+
+	servers, err := lister.ForeignServers(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("lister.ForeignServers: %w", err)
+	}
+
+	return servers, nil
+}
+
+// GetForeignObjects returns tables and views accessible through the foreign server.
+func (c *Connection) GetForeignObjects(server string) ([]*Structure, error) {
+	if server == "" {
+		return nil, errors.New("no server provided")
+	}
+
+	lister, ok := c.driver.(ForeignServerLister)
+	if !ok {
+		return nil, ErrForeignServersNotSupported
+	}
+
+	objects, err := lister.ForeignObjects(context.Background(), server)
+	if err != nil {
+		return nil, fmt.Errorf("lister.ForeignObjects: %w", err)
+	}
+
+	return objects, nil
+}
@@ -8,7 +8,10 @@ import (
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 )
 
-var _ core.Formatter = (*CSV)(nil)
+var (
+	_ core.Formatter       = (*CSV)(nil)
+	_ core.StreamFormatter = (*CSV)(nil)
+)
 
 type CSV struct{}
 
@@ -45,3 +48,21 @@ func (cf *CSV) Format(header core.Header, rows []core.Row, _ *core.FormatterOpti
 
 	return b.Bytes(), nil
 }
+
+// FormatChunk formats rows of a chunk, the header is written with the first one.
+func (cf *CSV) FormatChunk(header core.Header, rows []core.Row, _ *core.FormatterOptions, first, _ bool) ([]byte, error) {
+	data := cf.parseSchemaFul(header, rows)
+	if !first {
+		data = data[1:]
+	}
+
+	b := new(bytes.Buffer)
+	w := csv.NewWriter(b)
+
+	err := w.WriteAll(data)
+	if err != nil {
+		return nil, fmt.Errorf("w.WriteAll: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,85 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+var (
+	_ core.Formatter       = (*GeoJSON)(nil)
+	_ core.StreamFormatter = (*GeoJSON)(nil)
+)
+
+// GeoJSON formats rows as a GeoJSON feature collection. The first geometry
+// of each row is the feature's geometry and the rest of the row's columns
+// are its properties.
+type GeoJSON struct{}
+
+func NewGeoJSON() *GeoJSON {
+	return &GeoJSON{}
+}
+
+type geoJSONFeature struct {
+	Type       string         `json:"type"`
+	Geometry   *core.Geometry `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+func (gf *GeoJSON) features(header core.Header, rows []core.Row) []*geoJSONFeature {
+	features := make([]*geoJSONFeature, len(rows))
+
+	for i, row := range rows {
+		feature := &geoJSONFeature{
+			Type:       "Feature",
+			Properties: make(map[string]any, len(row)),
+		}
+		for j, val := range row {
+			if geom, ok := val.(*core.Geometry); ok && feature.Geometry == nil {
+				feature.Geometry = geom
+				continue
+			}
+			h := fmt.Sprintf("<unknown-field-%d>", j)
+			if j < len(header) {
+				h = header[j]
+			}
+			feature.Properties[h] = val
+		}
+		features[i] = feature
+	}
+
+	return features
+}
+
+func (gf *GeoJSON) Format(header core.Header, rows []core.Row, opts *core.FormatterOptions) ([]byte, error) {
+	return gf.FormatChunk(header, rows, opts, true, true)
+}
+
+// FormatChunk formats rows of a chunk as features of a collection, which is
+// opened by the first chunk and closed by the last one.
+func (gf *GeoJSON) FormatChunk(header core.Header, rows []core.Row, opts *core.FormatterOptions, first, last bool) ([]byte, error) {
+	features := gf.features(header, rows)
+
+	b := new(bytes.Buffer)
+	if first {
+		b.WriteString(`{"type":"FeatureCollection","features":[`)
+	}
+	for i, feature := range features {
+		if !first || i > 0 {
+			b.WriteString(",")
+		}
+		out, err := json.Marshal(feature)
+		if err != nil {
+			return nil, fmt.Errorf("json.Marshal: %w", err)
+		}
+		b.WriteString("\n")
+		b.Write(out)
+	}
+	if last {
+		b.WriteString("\n]}")
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,64 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+var (
+	_ core.Formatter       = (*Insert)(nil)
+	_ core.StreamFormatter = (*Insert)(nil)
+)
+
+// Insert formats rows as INSERT statements, with identifiers and values
+// written in the dialect of the target database.
+type Insert struct {
+	dialect *core.Dialect
+	// quoted name of the target table
+	table string
+}
+
+// NewInsert creates a formatter of INSERT statements into the table, which
+// has to be quoted already (e.g. with dialect.QualifiedName).
+func NewInsert(dialect *core.Dialect, table string) *Insert {
+	if dialect == nil {
+		dialect = core.DefaultDialect
+	}
+	return &Insert{
+		dialect: dialect,
+		table:   table,
+	}
+}
+
+func (inf *Insert) Format(header core.Header, rows []core.Row, opts *core.FormatterOptions) ([]byte, error) {
+	return inf.FormatChunk(header, rows, opts, true, true)
+}
+
+// FormatChunk formats each row of the chunk as a single statement.
+func (inf *Insert) FormatChunk(header core.Header, rows []core.Row, opts *core.FormatterOptions, first, last bool) ([]byte, error) {
+	columns := make([]string, len(header))
+	for i, h := range header {
+		columns[i] = inf.dialect.QuoteIdentifier(h)
+	}
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES (", inf.table, strings.Join(columns, ", "))
+
+	b := new(bytes.Buffer)
+	values := make([]string, len(header))
+	for _, row := range rows {
+		for i := range values {
+			var val any
+			if i < len(row) {
+				val = row[i]
+			}
+			values[i] = inf.dialect.Literal(val)
+		}
+		b.WriteString(prefix)
+		b.WriteString(strings.Join(values, ", "))
+		b.WriteString(");\n")
+	}
+
+	return b.Bytes(), nil
+}
@@ -1,13 +1,17 @@
 package format
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 )
 
-var _ core.Formatter = (*JSON)(nil)
+var (
+	_ core.Formatter       = (*JSON)(nil)
+	_ core.StreamFormatter = (*JSON)(nil)
+)
 
 type JSON struct{}
 
@@ -48,16 +52,23 @@ func (jf *JSON) parseSchemaLess(header core.Header, rows []core.Row) []any {
 	return data
 }
 
-func (jf *JSON) Format(header core.Header, rows []core.Row, opts *core.FormatterOptions) ([]byte, error) {
-	var data any
+func (jf *JSON) parse(header core.Header, rows []core.Row, opts *core.FormatterOptions) []any {
+	var data []any
 	switch opts.SchemaType {
 	case core.SchemaLess:
 		data = jf.parseSchemaLess(header, rows)
 	case core.SchemaFul:
 		fallthrough
 	default:
-		data = jf.parseSchemaFul(header, rows)
+		for _, record := range jf.parseSchemaFul(header, rows) {
+			data = append(data, record)
+		}
 	}
+	return data
+}
+
+func (jf *JSON) Format(header core.Header, rows []core.Row, opts *core.FormatterOptions) ([]byte, error) {
+	data := jf.parse(header, rows, opts)
 
 	out, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
@@ -66,3 +77,34 @@ func (jf *JSON) Format(header core.Header, rows []core.Row, opts *core.Formatter
 
 	return out, nil
 }
+
+// FormatChunk formats rows of a chunk as elements of a JSON array, which is
+// opened by the first chunk and closed by the last one. The concatenated output
+// is the same as the output of Format.
+func (jf *JSON) FormatChunk(header core.Header, rows []core.Row, opts *core.FormatterOptions, first, last bool) ([]byte, error) {
+	data := jf.parse(header, rows, opts)
+	if first && last && len(data) == 0 {
+		return []byte("null"), nil
+	}
+
+	b := new(bytes.Buffer)
+	if first {
+		b.WriteString("[")
+	}
+	for i, item := range data {
+		if !first || i > 0 {
+			b.WriteString(",")
+		}
+		out, err := json.MarshalIndent(item, "  ", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("json.MarshalIndent: %w", err)
+		}
+		b.WriteString("\n  ")
+		b.Write(out)
+	}
+	if last {
+		b.WriteString("\n]")
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,446 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	gob.Register(&Geometry{})
+}
+
+// types of geometries in the well-known binary format
+const (
+	wkbPoint              = 1
+	wkbLineString         = 2
+	wkbPolygon            = 3
+	wkbMultiPoint         = 4
+	wkbMultiLineString    = 5
+	wkbMultiPolygon       = 6
+	wkbGeometryCollection = 7
+)
+
+// flags of the extended (PostGIS) well-known binary format
+const (
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+)
+
+// maxGeometryDepth limits nesting of geometry collections.
+const maxGeometryDepth = 32
+
+var errInvalidWKB = errors.New("invalid well-known binary geometry")
+
+var wkbTypeNames = map[uint32]string{
+	wkbPoint:              "Point",
+	wkbLineString:         "LineString",
+	wkbPolygon:            "Polygon",
+	wkbMultiPoint:         "MultiPoint",
+	wkbMultiLineString:    "MultiLineString",
+	wkbMultiPolygon:       "MultiPolygon",
+	wkbGeometryCollection: "GeometryCollection",
+}
+
+// Geometry is a value of a spatial column (e.g. PostGIS geometry or MySQL
+// spatial types). It's displayed as well-known text (prefixed with the SRID
+// if it's known) and exported to json as a GeoJSON geometry.
+type Geometry struct {
+	srid uint32
+	// the original well-known binary value
+	wkb  []byte
+	root *geometryNode
+}
+
+// geometryNode is a parsed geometry. Coordinates of points are x, y and
+// optional z and m values.
+type geometryNode struct {
+	kind       uint32
+	hasZ, hasM bool
+	// Point (nil if empty)
+	point []float64
+	// LineString
+	line [][]float64
+	// Polygon
+	rings [][][]float64
+	// MultiPoint, MultiLineString, MultiPolygon and GeometryCollection
+	children []*geometryNode
+}
+
+// ParseWKB parses a geometry in the well-known binary format, including its
+// ISO (Z, M) and extended PostGIS variants. srid is used unless the value
+// embeds its own.
+func ParseWKB(wkb []byte, srid int) (*Geometry, error) {
+	p := &wkbParser{data: wkb, srid: uint32(srid)}
+	root, err := p.geometry(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(wkb) {
+		return nil, fmt.Errorf("%w: %d trailing bytes", errInvalidWKB, len(wkb)-p.pos)
+	}
+
+	return &Geometry{
+		srid: p.srid,
+		wkb:  wkb,
+		root: root,
+	}, nil
+}
+
+// SRID returns the spatial reference system identifier (0 if unknown).
+func (g *Geometry) SRID() int {
+	return int(g.srid)
+}
+
+// Type returns the geometry type (e.g. "Point" or "MultiPolygon").
+func (g *Geometry) Type() string {
+	return wkbTypeNames[g.root.kind]
+}
+
+// WKT returns the geometry in the well-known text format.
+func (g *Geometry) WKT() string {
+	var b strings.Builder
+	g.root.writeWKT(&b, true)
+	return b.String()
+}
+
+// String returns the geometry in the well-known text format, prefixed with
+// "SRID=<srid>;" if the SRID is known (extended well-known text).
+func (g *Geometry) String() string {
+	if g.srid == 0 {
+		return g.WKT()
+	}
+	return "SRID=" + strconv.FormatUint(uint64(g.srid), 10) + ";" + g.WKT()
+}
+
+// MarshalJSON returns the geometry as a GeoJSON geometry object. M values
+// are left out.
+func (g *Geometry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(g.root.geoJSON())
+}
+
+func (g *Geometry) GobEncode() ([]byte, error) {
+	w := new(bytes.Buffer)
+	encoder := gob.NewEncoder(w)
+	if err := encoder.Encode(g.srid); err != nil {
+		return nil, err
+	}
+	if err := encoder.Encode(g.wkb); err != nil {
+		return nil, err
+	}
+	return w.Bytes(), nil
+}
+
+func (g *Geometry) GobDecode(buf []byte) error {
+	decoder := gob.NewDecoder(bytes.NewBuffer(buf))
+	var srid uint32
+	if err := decoder.Decode(&srid); err != nil {
+		return err
+	}
+	var wkb []byte
+	if err := decoder.Decode(&wkb); err != nil {
+		return err
+	}
+
+	parsed, err := ParseWKB(wkb, int(srid))
+	if err != nil {
+		return err
+	}
+	*g = *parsed
+	return nil
+}
+
+type wkbParser struct {
+	data  []byte
+	pos   int
+	order binary.ByteOrder
+	srid  uint32
+}
+
+func (p *wkbParser) uint32() (uint32, error) {
+	if len(p.data)-p.pos < 4 {
+		return 0, fmt.Errorf("%w: unexpected end", errInvalidWKB)
+	}
+	v := p.order.Uint32(p.data[p.pos:])
+	p.pos += 4
+	return v, nil
+}
+
+// count reads the number of elements which take at least size bytes each.
+func (p *wkbParser) count(size int) (int, error) {
+	n, err := p.uint32()
+	if err != nil {
+		return 0, err
+	}
+	if uint64(n)*uint64(size) > uint64(len(p.data)-p.pos) {
+		return 0, fmt.Errorf("%w: %d elements don't fit in %d bytes", errInvalidWKB, n, len(p.data)-p.pos)
+	}
+	return int(n), nil
+}
+
+func (p *wkbParser) coordinates(dims int) ([]float64, error) {
+	if len(p.data)-p.pos < 8*dims {
+		return nil, fmt.Errorf("%w: unexpected end", errInvalidWKB)
+	}
+	coords := make([]float64, dims)
+	for i := range coords {
+		coords[i] = math.Float64frombits(p.order.Uint64(p.data[p.pos:]))
+		p.pos += 8
+	}
+	return coords, nil
+}
+
+func (p *wkbParser) points(dims int) ([][]float64, error) {
+	n, err := p.count(8 * dims)
+	if err != nil {
+		return nil, err
+	}
+	points := make([][]float64, n)
+	for i := range points {
+		if points[i], err = p.coordinates(dims); err != nil {
+			return nil, err
+		}
+	}
+	return points, nil
+}
+
+func (p *wkbParser) geometry(depth int) (*geometryNode, error) {
+	if depth > maxGeometryDepth {
+		return nil, fmt.Errorf("%w: geometries are nested too deep", errInvalidWKB)
+	}
+	if p.pos >= len(p.data) {
+		return nil, fmt.Errorf("%w: unexpected end", errInvalidWKB)
+	}
+	switch p.data[p.pos] {
+	case 0:
+		p.order = binary.BigEndian
+	case 1:
+		p.order = binary.LittleEndian
+	default:
+		return nil, fmt.Errorf("%w: unknown byte order %d", errInvalidWKB, p.data[p.pos])
+	}
+	p.pos++
+
+	typ, err := p.uint32()
+	if err != nil {
+		return nil, err
+	}
+	node := &geometryNode{
+		hasZ: typ&ewkbZ != 0,
+		hasM: typ&ewkbM != 0,
+	}
+	if typ&ewkbSRID != 0 {
+		srid, err := p.uint32()
+		if err != nil {
+			return nil, err
+		}
+		if depth == 0 {
+			p.srid = srid
+		}
+	}
+	// ISO types of geometries with z and m values are offset by thousands
+	typ &^= ewkbZ | ewkbM | ewkbSRID
+	switch typ / 1000 {
+	case 1:
+		node.hasZ = true
+	case 2:
+		node.hasM = true
+	case 3:
+		node.hasZ, node.hasM = true, true
+	}
+	node.kind = typ % 1000
+	if typ >= 4000 || wkbTypeNames[node.kind] == "" {
+		return nil, fmt.Errorf("%w: unknown geometry type %d", errInvalidWKB, typ)
+	}
+
+	dims := 2
+	if node.hasZ {
+		dims++
+	}
+	if node.hasM {
+		dims++
+	}
+
+	switch node.kind {
+	case wkbPoint:
+		point, err := p.coordinates(dims)
+		if err != nil {
+			return nil, err
+		}
+		// empty points have NaN coordinates
+		if !math.IsNaN(point[0]) || !math.IsNaN(point[1]) {
+			node.point = point
+		}
+	case wkbLineString:
+		if node.line, err = p.points(dims); err != nil {
+			return nil, err
+		}
+	case wkbPolygon:
+		n, err := p.count(4)
+		if err != nil {
+			return nil, err
+		}
+		node.rings = make([][][]float64, n)
+		for i := range node.rings {
+			if node.rings[i], err = p.points(dims); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		// collections consist of whole geometries with their own byte order
+		n, err := p.count(5)
+		if err != nil {
+			return nil, err
+		}
+		order := p.order
+		node.children = make([]*geometryNode, n)
+		for i := range node.children {
+			if node.children[i], err = p.geometry(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		p.order = order
+	}
+
+	return node, nil
+}
+
+func (n *geometryNode) isEmpty() bool {
+	switch n.kind {
+	case wkbPoint:
+		return n.point == nil
+	case wkbLineString:
+		return len(n.line) == 0
+	case wkbPolygon:
+		return len(n.rings) == 0
+	}
+	return len(n.children) == 0
+}
+
+// writeWKT writes the geometry in the well-known text format. Members of
+// multi geometries are written without their type.
+func (n *geometryNode) writeWKT(b *strings.Builder, withType bool) {
+	if withType {
+		b.WriteString(strings.ToUpper(wkbTypeNames[n.kind]))
+		switch {
+		case n.hasZ && n.hasM:
+			b.WriteString(" ZM ")
+		case n.hasZ:
+			b.WriteString(" Z ")
+		case n.hasM:
+			b.WriteString(" M ")
+		}
+	}
+	if n.isEmpty() {
+		if withType && !n.hasZ && !n.hasM {
+			b.WriteString(" ")
+		}
+		b.WriteString("EMPTY")
+		return
+	}
+
+	b.WriteString("(")
+	switch n.kind {
+	case wkbPoint:
+		writeWKTPoint(b, n.point)
+	case wkbLineString:
+		writeWKTPoints(b, n.line)
+	case wkbPolygon:
+		for i, ring := range n.rings {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			b.WriteString("(")
+			writeWKTPoints(b, ring)
+			b.WriteString(")")
+		}
+	default:
+		for i, child := range n.children {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			child.writeWKT(b, n.kind == wkbGeometryCollection)
+		}
+	}
+	b.WriteString(")")
+}
+
+func writeWKTPoint(b *strings.Builder, point []float64) {
+	for i, coord := range point {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(strconv.FormatFloat(coord, 'f', -1, 64))
+	}
+}
+
+func writeWKTPoints(b *strings.Builder, points [][]float64) {
+	for i, point := range points {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		writeWKTPoint(b, point)
+	}
+}
+
+// geoJSON converts the geometry to a GeoJSON geometry object.
+func (n *geometryNode) geoJSON() map[string]any {
+	obj := map[string]any{
+		"type": wkbTypeNames[n.kind],
+	}
+
+	switch n.kind {
+	case wkbPoint:
+		obj["coordinates"] = n.position(n.point)
+	case wkbLineString:
+		obj["coordinates"] = n.positions(n.line)
+	case wkbPolygon:
+		rings := make([][][]float64, len(n.rings))
+		for i, ring := range n.rings {
+			rings[i] = n.positions(ring)
+		}
+		obj["coordinates"] = rings
+	case wkbGeometryCollection:
+		geometries := make([]map[string]any, len(n.children))
+		for i, child := range n.children {
+			geometries[i] = child.geoJSON()
+		}
+		obj["geometries"] = geometries
+	default:
+		coordinates := make([]any, 0, len(n.children))
+		for _, child := range n.children {
+			if !child.isEmpty() {
+				coordinates = append(coordinates, child.geoJSON()["coordinates"])
+			}
+		}
+		obj["coordinates"] = coordinates
+	}
+
+	return obj
+}
+
+// position returns x, y and z of the point (GeoJSON has no m values).
+func (n *geometryNode) position(point []float64) []float64 {
+	if point == nil {
+		return []float64{}
+	}
+	if n.hasZ {
+		return point[:3]
+	}
+	return point[:2]
+}
+
+func (n *geometryNode) positions(points [][]float64) [][]float64 {
+	positions := make([][]float64, len(points))
+	for i, point := range points {
+		positions[i] = n.position(point)
+	}
+	return positions
+}
@@ -0,0 +1,171 @@
+package core_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/format"
+)
+
+// well-known binary values of test geometries
+const (
+	wkbPoint         = "0101000000000000000000f03f0000000000000040"
+	wkbPointEmpty    = "0101000000000000000000f87f000000000000f87f"
+	wkbPointBig      = "00000000013ff00000000000004000000000000000"
+	wkbPointZ        = "01e9030000000000000000f03f00000000000000400000000000000840"
+	ewkbPointSRID    = "0101000020e6100000000000000000f03f0000000000000040"
+	wkbLineString    = "01020000000200000000000000000000000000000000000000000000000000f03f000000000000f03f"
+	wkbPolygon       = "0103000000010000000400000000000000000000000000000000000000000000000000f03f0000000000000000000000000000f03f000000000000f03f00000000000000000000000000000000"
+	wkbMultiPoint    = "010400000002000000" + wkbPoint + wkbPointBig
+	wkbCollection    = "010700000002000000" + wkbPoint + wkbLineString
+	wkbUnknownType   = "0108000000"
+	wkbHugeLineCount = "0102000000ffffffff"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func TestParseWKB(t *testing.T) {
+	testCases := []struct {
+		wkb     string
+		srid    int
+		typ     string
+		text    string
+		geoJSON string
+	}{
+		{
+			wkb:     wkbPoint,
+			typ:     "Point",
+			text:    "POINT(1 2)",
+			geoJSON: `{"coordinates":[1,2],"type":"Point"}`,
+		},
+		{
+			wkb:     wkbPointEmpty,
+			typ:     "Point",
+			text:    "POINT EMPTY",
+			geoJSON: `{"coordinates":[],"type":"Point"}`,
+		},
+		{
+			wkb:     wkbPointBig,
+			srid:    3857,
+			typ:     "Point",
+			text:    "SRID=3857;POINT(1 2)",
+			geoJSON: `{"coordinates":[1,2],"type":"Point"}`,
+		},
+		{
+			wkb:     wkbPointZ,
+			typ:     "Point",
+			text:    "POINT Z (1 2 3)",
+			geoJSON: `{"coordinates":[1,2,3],"type":"Point"}`,
+		},
+		{
+			// the embedded SRID takes precedence
+			wkb:     ewkbPointSRID,
+			srid:    3857,
+			typ:     "Point",
+			text:    "SRID=4326;POINT(1 2)",
+			geoJSON: `{"coordinates":[1,2],"type":"Point"}`,
+		},
+		{
+			wkb:     wkbLineString,
+			typ:     "LineString",
+			text:    "LINESTRING(0 0,1 1)",
+			geoJSON: `{"coordinates":[[0,0],[1,1]],"type":"LineString"}`,
+		},
+		{
+			wkb:     wkbPolygon,
+			typ:     "Polygon",
+			text:    "POLYGON((0 0,1 0,1 1,0 0))",
+			geoJSON: `{"coordinates":[[[0,0],[1,0],[1,1],[0,0]]],"type":"Polygon"}`,
+		},
+		{
+			wkb:     wkbMultiPoint,
+			typ:     "MultiPoint",
+			text:    "MULTIPOINT((1 2),(1 2))",
+			geoJSON: `{"coordinates":[[1,2],[1,2]],"type":"MultiPoint"}`,
+		},
+		{
+			wkb:     wkbCollection,
+			typ:     "GeometryCollection",
+			text:    "GEOMETRYCOLLECTION(POINT(1 2),LINESTRING(0 0,1 1))",
+			geoJSON: `{"geometries":[{"coordinates":[1,2],"type":"Point"},{"coordinates":[[0,0],[1,1]],"type":"LineString"}],"type":"GeometryCollection"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			r := require.New(t)
+
+			geom, err := core.ParseWKB(mustDecodeHex(t, tc.wkb), tc.srid)
+			r.NoError(err)
+			r.Equal(tc.typ, geom.Type())
+			r.Equal(tc.text, geom.String())
+
+			out, err := json.Marshal(geom)
+			r.NoError(err)
+			r.JSONEq(tc.geoJSON, string(out))
+		})
+	}
+}
+
+func TestParseWKB_Invalid(t *testing.T) {
+	for _, wkb := range []string{
+		"",
+		"02",
+		wkbPoint[:20],
+		wkbPoint + "00",
+		wkbUnknownType,
+		wkbHugeLineCount,
+	} {
+		_, err := core.ParseWKB(mustDecodeHex(t, wkb), 0)
+		require.Error(t, err, wkb)
+	}
+}
+
+func TestGeometry_Gob(t *testing.T) {
+	r := require.New(t)
+
+	geom, err := core.ParseWKB(mustDecodeHex(t, wkbPolygon), 4326)
+	r.NoError(err)
+
+	var buf bytes.Buffer
+	r.NoError(gob.NewEncoder(&buf).Encode(core.Row{geom}))
+
+	var row core.Row
+	r.NoError(gob.NewDecoder(&buf).Decode(&row))
+	r.Equal(geom.String(), row[0].(*core.Geometry).String())
+}
+
+func TestGeoJSONFormatter(t *testing.T) {
+	r := require.New(t)
+
+	geom, err := core.ParseWKB(mustDecodeHex(t, wkbPoint), 4326)
+	r.NoError(err)
+
+	out, err := format.NewGeoJSON().Format(core.Header{"id", "location"}, []core.Row{
+		{1, geom},
+		{2, nil},
+	}, &core.FormatterOptions{})
+	r.NoError(err)
+	r.JSONEq(`{
+		"type": "FeatureCollection",
+		"features": [
+			{"type": "Feature", "geometry": {"type": "Point", "coordinates": [1, 2]}, "properties": {"id": 1}},
+			{"type": "Feature", "geometry": null, "properties": {"id": 2, "location": null}}
+		]
+	}`, string(out))
+
+	out, err = format.NewGeoJSON().Format(core.Header{"id"}, nil, &core.FormatterOptions{})
+	r.NoError(err)
+	r.JSONEq(`{"type": "FeatureCollection", "features": []}`, string(out))
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrGrantsNotSupported = errors.New("listing roles and grants not supported")
+
+type (
+	// Role is a database user or role.
+	Role struct {
+		Name      string
+		Superuser bool
+		CanLogin  bool
+		// roles this role is a member of
+		MemberOf []string
+	}
+
+	// Grant is a privilege on a table granted to a role.
+	Grant struct {
+		Grantee string
+		// e.g. SELECT or INSERT
+		Privilege string
+		// grantee can grant the privilege to others
+		Grantable bool
+		Grantor   string
+	}
+
+	// GrantLister is an optional interface for drivers that can list roles
+	// and privileges granted on tables.
+	GrantLister interface {
+		Roles(ctx context.Context) ([]*Role, error)
+		Grants(ctx context.Context, opts *TableOptions) ([]*Grant, error)
+	}
+)
+
+// GetRoles returns users and roles of the database.
+func (c *Connection) GetRoles() ([]*Role, error) {
+	lister, ok := c.driver.(GrantLister)
+	if !ok {
+		return nil, ErrGrantsNotSupported
+	}
+
+	roles, err := lister.Roles(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("lister.Roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// GetGrants returns privileges granted on the table described by opts.
+func (c *Connection) GetGrants(opts *TableOptions) ([]*Grant, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("opts cannot be nil")
+	}
+
+	lister, ok := c.driver.(GrantLister)
+	if !ok {
+		return nil, ErrGrantsNotSupported
+	}
+
+	grants, err := lister.Grants(context.Background(), opts)
+	if err != nil {
+		return nil, fmt.Errorf("lister.Grants: %w", err)
+	}
+
+	return grants, nil
+}
@@ -0,0 +1,191 @@
+package core
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var ErrConfirmationRequired = errors.New("statement requires confirmation on guarded connection")
+
+var (
+	guardCommentsRe  = regexp.MustCompile(`(?s)--[^\n]*|/\*.*?\*/`)
+	guardLiteralsRe  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	guardWhitespace  = regexp.MustCompile(`\s+`)
+	guardFirstWordRe = regexp.MustCompile(`^\s*(\w+)`)
+)
+
+// normalizeStatement strips comments and string literals from a statement so
+// that keywords inside of them don't confuse the detection.
+func normalizeStatement(stmt string) string {
+	stmt = guardCommentsRe.ReplaceAllString(stmt, " ")
+	stmt = guardLiteralsRe.ReplaceAllString(stmt, "''")
+	return strings.TrimSpace(guardWhitespace.ReplaceAllString(stmt, " "))
+}
+
+// DestructiveReason checks every statement in query and returns a short
+// description of the first destructive one. If the query is safe, ok is false.
+//
+// Destructive statements are: DELETE and UPDATE without WHERE, TRUNCATE, DROP and
+// procedure calls (CALL, EXEC), whose effects are unknown.
+// Statements with common table expressions (WITH ...) are checked after them,
+// and data-modifying expressions are checked as well.
+func DestructiveReason(query string) (reason string, ok bool) {
+	for _, stmt := range strings.Split(normalizeStatement(query), ";") {
+		if reason, ok := destructiveStatement(stmt); ok {
+			return reason, true
+		}
+	}
+
+	return "", false
+}
+
+func destructiveStatement(stmt string) (string, bool) {
+	bodies, main := splitCTEs(stmt)
+	for _, body := range bodies {
+		if reason, ok := destructiveStatement(body); ok {
+			return reason, true
+		}
+	}
+
+	match := guardFirstWordRe.FindStringSubmatch(main)
+	if match == nil {
+		return "", false
+	}
+
+	switch strings.ToLower(match[1]) {
+	case "delete":
+		if !hasWhere(main) {
+			return "DELETE without WHERE clause", true
+		}
+	case "update":
+		if !hasWhere(main) {
+			return "UPDATE without WHERE clause", true
+		}
+	case "truncate":
+		return "TRUNCATE statement", true
+	case "drop":
+		return "DROP statement", true
+	case "call", "exec", "execute":
+		return "procedure call", true
+	}
+
+	return "", false
+}
+
+// hasWhere reports whether a normalized statement has a WHERE clause of its own.
+// Clauses inside of parentheses (e.g. of subqueries) are ignored.
+func hasWhere(stmt string) bool {
+	stmt = strings.TrimSpace(stmt)
+	for i := 0; i < len(stmt); i++ {
+		if stmt[i] == '(' {
+			_, rest, ok := cutParens(stmt[i:])
+			if !ok {
+				return false
+			}
+			i = len(stmt) - len(rest) - 1
+			continue
+		}
+		if i > 0 && isWordByte(stmt[i-1]) {
+			continue
+		}
+		if _, ok := cutKeyword(stmt[i:], "where"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// splitCTEs splits a normalized statement which starts with WITH into bodies
+// of its common table expressions and the statement which follows them.
+// Other statements are returned as they are.
+func splitCTEs(stmt string) (bodies []string, main string) {
+	rest, ok := cutKeyword(strings.TrimSpace(stmt), "with")
+	if !ok {
+		return nil, stmt
+	}
+	rest, _ = cutKeyword(rest, "recursive")
+
+	for {
+		// name with optional column list
+		rest = skipIdentifier(rest)
+		if strings.HasPrefix(rest, "(") {
+			if _, rest, ok = cutParens(rest); !ok {
+				return bodies, rest
+			}
+		}
+
+		if rest, ok = cutKeyword(rest, "as"); !ok {
+			return bodies, rest
+		}
+		rest, _ = cutKeyword(rest, "not")
+		rest, _ = cutKeyword(rest, "materialized")
+
+		var body string
+		if body, rest, ok = cutParens(rest); !ok {
+			return bodies, rest
+		}
+		bodies = append(bodies, body)
+
+		if !strings.HasPrefix(rest, ",") {
+			return bodies, rest
+		}
+		rest = strings.TrimSpace(rest[1:])
+	}
+}
+
+// cutKeyword removes the keyword (in any case) from the start of s.
+func cutKeyword(s, keyword string) (string, bool) {
+	if len(s) < len(keyword) || !strings.EqualFold(s[:len(keyword)], keyword) {
+		return s, false
+	}
+	if len(s) > len(keyword) && isWordByte(s[len(keyword)]) {
+		return s, false
+	}
+	return strings.TrimSpace(s[len(keyword):]), true
+}
+
+// skipIdentifier removes a plain or quoted identifier from the start of s.
+func skipIdentifier(s string) string {
+	closing := map[byte]byte{'"': '"', '`': '`', '[': ']'}
+	if len(s) > 0 {
+		if end, ok := closing[s[0]]; ok {
+			if i := strings.IndexByte(s[1:], end); i >= 0 {
+				return strings.TrimSpace(s[i+2:])
+			}
+			return ""
+		}
+	}
+
+	i := 0
+	for i < len(s) && isWordByte(s[i]) {
+		i++
+	}
+	return strings.TrimSpace(s[i:])
+}
+
+// cutParens returns the contents of the parentheses at the start of s
+// (including nested ones) and the rest of s.
+func cutParens(s string) (inner, rest string, ok bool) {
+	if !strings.HasPrefix(s, "(") {
+		return "", s, false
+	}
+
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return s[1:i], strings.TrimSpace(s[i+1:]), true
+			}
+		}
+	}
+	return "", s, false
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
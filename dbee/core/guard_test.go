@@ -0,0 +1,55 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestDestructiveReason(t *testing.T) {
+	type testCase struct {
+		name        string
+		query       string
+		destructive bool
+	}
+
+	testCases := []testCase{
+		{name: "select", query: "SELECT * FROM t", destructive: false},
+		{name: "delete with where", query: "DELETE FROM t WHERE id = 1", destructive: false},
+		{name: "delete without where", query: "delete from t", destructive: true},
+		{name: "update with where", query: "UPDATE t SET a = 1 WHERE id = 1", destructive: false},
+		{name: "update without where", query: "UPDATE t SET a = 1", destructive: true},
+		{name: "where in literal", query: "UPDATE t SET a = 'where'", destructive: true},
+		{name: "where in comment", query: "DELETE FROM t -- where id = 1", destructive: true},
+		{name: "update with where in subquery", query: "UPDATE t SET a = (SELECT b FROM u WHERE u.id = 1)", destructive: true},
+		{name: "delete using subquery with where", query: "DELETE FROM t USING (SELECT id FROM u WHERE u.a = 1) s", destructive: true},
+		{name: "update with subquery and where", query: "UPDATE t SET a = (SELECT b FROM u WHERE u.id = t.id) WHERE t.id = 1", destructive: false},
+		{name: "delete with where after subquery", query: "DELETE FROM t USING (SELECT id FROM u) s WHERE(s.id = t.id)", destructive: false},
+		{name: "where in identifier", query: "DELETE FROM nowhere_t", destructive: true},
+		{name: "truncate", query: "TRUNCATE TABLE t", destructive: true},
+		{name: "drop", query: "  drop table t", destructive: true},
+		{name: "second statement", query: "SELECT 1; DROP TABLE t;", destructive: true},
+		{name: "call", query: "CALL archive_orders(1)", destructive: true},
+		{name: "exec", query: "exec dbo.archive_orders @id = 1", destructive: true},
+		{name: "drop in comment", query: "/* drop table t */ SELECT 1", destructive: false},
+		{name: "cte select", query: "WITH x AS (SELECT 1) SELECT * FROM x", destructive: false},
+		{name: "cte delete without where", query: "WITH x AS (SELECT 1) DELETE FROM t", destructive: true},
+		{name: "cte delete with where", query: "WITH x AS (SELECT 1) DELETE FROM t WHERE id IN (SELECT * FROM x)", destructive: false},
+		{name: "cte update without where", query: "with x as (select 1) update t set a = 1", destructive: true},
+		{name: "cte where only in expression", query: "WITH x AS (SELECT id FROM a WHERE b) UPDATE t SET a = 1", destructive: true},
+		{name: "cte nested parentheses", query: "WITH RECURSIVE x (n) AS (SELECT (1) UNION ALL SELECT (n + 1) FROM x WHERE (n < (10))), y AS NOT MATERIALIZED (SELECT 2) DELETE FROM t", destructive: true},
+		{name: "cte quoted names", query: `WITH "del ete" AS (SELECT 1), [x] AS (SELECT 2) UPDATE t SET a = 1`, destructive: true},
+		{name: "data-modifying cte", query: "WITH d AS (DELETE FROM t RETURNING *) SELECT * FROM d", destructive: true},
+		{name: "data-modifying cte with where", query: "WITH d AS (DELETE FROM t WHERE id = 1 RETURNING *) SELECT * FROM d", destructive: false},
+		{name: "nested data-modifying cte", query: "WITH d AS (WITH e AS (SELECT 1) UPDATE t SET a = 1 RETURNING *) SELECT * FROM d", destructive: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, ok := core.DestructiveReason(tc.query)
+			require.Equal(t, tc.destructive, ok)
+		})
+	}
+}
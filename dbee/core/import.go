@@ -0,0 +1,533 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+var (
+	ErrImportNotSupported  = errors.New("import is not supported for this type of database")
+	ErrUnknownImportFormat = errors.New("unknown import format")
+)
+
+const defaultImportBatchSize = 1000
+
+type ImportFormat string
+
+const (
+	ImportFormatCSV    ImportFormat = "csv"
+	ImportFormatNDJSON ImportFormat = "ndjson"
+)
+
+// ImportFormatFromPath guesses the import format from file extension.
+func ImportFormatFromPath(path string) (ImportFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ImportFormatCSV, nil
+	case ".ndjson", ".jsonl":
+		return ImportFormatNDJSON, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownImportFormat, path)
+	}
+}
+
+type (
+	// ImportOptions describe a bulk import of a local file into a table.
+	ImportOptions struct {
+		// path of the source file
+		Path string
+		// target table (can be schema qualified)
+		Table string
+		// format of the file (guessed from extension if empty)
+		Format ImportFormat
+		// maps source columns to table columns. If empty, source columns are
+		// imported to columns with the same name. Otherwise only mapped columns are imported.
+		Columns map[string]string
+		// number of rows sent to the database at once
+		BatchSize int
+		// file where rejected rows are written (defaults to Path + ".errors")
+		ErrorPath string
+	}
+
+	// ImportProgress is reported after every batch of an import.
+	ImportProgress struct {
+		CallID CallID
+		// rows written to the table
+		Imported int
+		// rows that were rejected (and written to the error file)
+		Failed int
+		// bytes of the source file read so far
+		BytesRead int64
+		// size of the source file
+		BytesTotal int64
+	}
+
+	// Importer is an optional interface for drivers that can import rows in bulk.
+	// ImportBatch should write all rows or none of them. The table (which can be
+	// schema qualified) and columns are unquoted names, which are quoted by the
+	// driver.
+	Importer interface {
+		ImportBatch(ctx context.Context, table string, columns []string, rows []Row) error
+	}
+)
+
+// importSource reads rows from a file.
+type importSource interface {
+	// header returns names of source columns
+	header() []string
+	// next returns the next row, the row as it appears in file and its line number.
+	// Malformed rows return an importRowError, end of file returns io.EOF.
+	next() (row Row, raw string, line int, err error)
+}
+
+// importRowError is a per-row error which doesn't stop the import.
+type importRowError struct {
+	err error
+}
+
+func (e *importRowError) Error() string { return e.err.Error() }
+
+func (e *importRowError) Unwrap() error { return e.err }
+
+// countingReader counts the bytes read from the underlying reader.
+type countingReader struct {
+	r io.Reader
+	n atomic.Int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n.Add(int64(n))
+	return n, err
+}
+
+type csvSource struct {
+	reader *csv.Reader
+	cols   []string
+}
+
+func newCSVSource(r io.Reader) (*csvSource, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading csv header: %w", err)
+	}
+	reader.FieldsPerRecord = len(header)
+
+	return &csvSource{
+		reader: reader,
+		cols:   header,
+	}, nil
+}
+
+func (s *csvSource) header() []string {
+	return s.cols
+}
+
+func (s *csvSource) next() (Row, string, int, error) {
+	record, err := s.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, "", 0, io.EOF
+	}
+
+	var line int
+	var perr *csv.ParseError
+	if errors.As(err, &perr) {
+		line = perr.StartLine
+	} else if len(record) > 0 {
+		line, _ = s.reader.FieldPos(0)
+	}
+
+	raw := csvLine(record)
+	if err != nil {
+		if perr != nil {
+			return nil, raw, line, &importRowError{err: err}
+		}
+		return nil, raw, line, err
+	}
+
+	// empty fields are treated as NULL, the same as COPY does
+	row := make(Row, len(record))
+	for i, field := range record {
+		if field != "" {
+			row[i] = field
+		}
+	}
+
+	return row, raw, line, nil
+}
+
+// csvLine encodes the record as a single csv line.
+func csvLine(record []string) string {
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	_ = w.Write(record)
+	w.Flush()
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+type ndjsonSource struct {
+	scanner *bufio.Scanner
+	cols    []string
+	line    int
+
+	// first row is read in advance to determine the header
+	first    map[string]any
+	firstRaw string
+	firstErr error
+}
+
+func newNDJSONSource(r io.Reader, columns []string) (*ndjsonSource, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	s := &ndjsonSource{
+		scanner: scanner,
+		cols:    columns,
+	}
+
+	s.first, s.firstRaw, s.firstErr = s.readObject()
+	if len(s.cols) < 1 {
+		if s.first == nil {
+			return nil, fmt.Errorf("no columns found in first object: %w", s.firstErr)
+		}
+		for k := range s.first {
+			s.cols = append(s.cols, k)
+		}
+		sort.Strings(s.cols)
+	}
+
+	return s, nil
+}
+
+func (s *ndjsonSource) readObject() (map[string]any, string, error) {
+	for s.scanner.Scan() {
+		s.line++
+		raw := s.scanner.Text()
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+
+		decoder := json.NewDecoder(strings.NewReader(raw))
+		decoder.UseNumber()
+
+		var obj map[string]any
+		err := decoder.Decode(&obj)
+		if err != nil {
+			return nil, raw, &importRowError{err: fmt.Errorf("invalid json: %w", err)}
+		}
+		return obj, raw, nil
+	}
+	if err := s.scanner.Err(); err != nil {
+		return nil, "", err
+	}
+	return nil, "", io.EOF
+}
+
+func (s *ndjsonSource) header() []string {
+	return s.cols
+}
+
+func (s *ndjsonSource) next() (Row, string, int, error) {
+	var obj map[string]any
+	var raw string
+	var err error
+	if s.first != nil || s.firstErr != nil {
+		obj, raw, err = s.first, s.firstRaw, s.firstErr
+		s.first, s.firstErr = nil, nil
+	} else {
+		obj, raw, err = s.readObject()
+	}
+	if err != nil {
+		return nil, raw, s.line, err
+	}
+
+	row := make(Row, len(s.cols))
+	for i, col := range s.cols {
+		val := obj[col]
+		switch v := val.(type) {
+		case map[string]any, []any:
+			// nested values are imported as json
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, raw, s.line, &importRowError{err: err}
+			}
+			val = string(b)
+		case json.Number:
+			val = v.String()
+		}
+		row[i] = val
+	}
+
+	return row, raw, s.line, nil
+}
+
+// importMapping maps source columns to table columns.
+// It returns the indexes of source columns to import and the table columns.
+func importMapping(header []string, mapping map[string]string) ([]int, []string, error) {
+	if len(mapping) < 1 {
+		indexes := make([]int, len(header))
+		for i := range header {
+			indexes[i] = i
+		}
+		return indexes, header, nil
+	}
+
+	positions := make(map[string]int, len(header))
+	for i, col := range header {
+		positions[col] = i
+	}
+
+	sources := make([]string, 0, len(mapping))
+	for src := range mapping {
+		sources = append(sources, src)
+	}
+	sort.Strings(sources)
+
+	indexes := make([]int, len(sources))
+	columns := make([]string, len(sources))
+	for i, src := range sources {
+		pos, ok := positions[src]
+		if !ok {
+			return nil, nil, fmt.Errorf("column %q not found in source", src)
+		}
+		indexes[i] = pos
+		columns[i] = mapping[src]
+	}
+
+	return indexes, columns, nil
+}
+
+// importErrorFile writes rejected rows as json lines. It's created lazily.
+type importErrorFile struct {
+	path string
+	file *os.File
+}
+
+func (f *importErrorFile) write(line int, raw string, rowErr error) error {
+	if f.file == nil {
+		file, err := os.Create(f.path)
+		if err != nil {
+			return fmt.Errorf("os.Create: %w", err)
+		}
+		f.file = file
+	}
+
+	b, err := json.Marshal(&struct {
+		Line  int    `json:"line"`
+		Row   string `json:"row"`
+		Error string `json:"error"`
+	}{
+		Line:  line,
+		Row:   raw,
+		Error: rowErr.Error(),
+	})
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	_, err = f.file.Write(append(b, '\n'))
+	return err
+}
+
+func (f *importErrorFile) close() {
+	if f.file != nil {
+		_ = f.file.Close()
+	}
+}
+
+// Import streams a local CSV or NDJSON file into a table. Rows are sent in batches
+// using the driver's fastest bulk path. If a batch fails, its rows are retried one
+// by one and rows that still fail are written to the error file.
+// onProgress is called after every batch.
+func (c *Connection) Import(opts *ImportOptions, onEvent func(CallState, *Call), onProgress func(*ImportProgress)) (*Call, error) {
+	importer, ok := c.driver.(Importer)
+	if !ok {
+		return nil, ErrImportNotSupported
+	}
+	if opts.Table == "" {
+		return nil, errors.New("no table provided")
+	}
+
+	format := opts.Format
+	if format == "" {
+		var err error
+		format, err = ImportFormatFromPath(opts.Path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	batchSize := opts.BatchSize
+	if batchSize < 1 {
+		batchSize = defaultImportBatchSize
+	}
+	errorPath := opts.ErrorPath
+	if errorPath == "" {
+		errorPath = opts.Path + ".errors"
+	}
+
+	exec := func(ctx context.Context) (ResultStream, error) {
+		file, err := os.Open(opts.Path)
+		if err != nil {
+			return nil, fmt.Errorf("os.Open: %w", err)
+		}
+		defer file.Close()
+
+		stat, err := file.Stat()
+		if err != nil {
+			return nil, fmt.Errorf("file.Stat: %w", err)
+		}
+
+		reader := &countingReader{r: file}
+
+		var source importSource
+		switch format {
+		case ImportFormatCSV:
+			source, err = newCSVSource(reader)
+		case ImportFormatNDJSON:
+			// with explicit mapping, mapped columns are the source header
+			var cols []string
+			for src := range opts.Columns {
+				cols = append(cols, src)
+			}
+			sort.Strings(cols)
+			source, err = newNDJSONSource(reader, cols)
+		default:
+			err = fmt.Errorf("%w: %q", ErrUnknownImportFormat, format)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		indexes, columns, err := importMapping(source.header(), opts.Columns)
+		if err != nil {
+			return nil, err
+		}
+
+		errFile := &importErrorFile{path: errorPath}
+		defer errFile.close()
+
+		progress := &ImportProgress{
+			CallID:     callIDFromContext(ctx),
+			BytesTotal: stat.Size(),
+		}
+
+		type pending struct {
+			row  Row
+			raw  string
+			line int
+		}
+		batch := make([]pending, 0, batchSize)
+
+		reject := func(line int, raw string, rowErr error) error {
+			progress.Failed++
+			return errFile.write(line, raw, rowErr)
+		}
+
+		flush := func() error {
+			if len(batch) < 1 {
+				return nil
+			}
+			defer func() { batch = batch[:0] }()
+
+			rows := make([]Row, len(batch))
+			for i := range batch {
+				rows[i] = batch[i].row
+			}
+
+			err := importer.ImportBatch(ctx, opts.Table, columns, rows)
+			if err == nil {
+				progress.Imported += len(rows)
+				return nil
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			// find the offending rows
+			for _, p := range batch {
+				err := importer.ImportBatch(ctx, opts.Table, columns, []Row{p.row})
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				if err != nil {
+					if err := reject(p.line, p.raw, err); err != nil {
+						return err
+					}
+					continue
+				}
+				progress.Imported++
+			}
+			return nil
+		}
+
+		report := func() {
+			if onProgress == nil {
+				return
+			}
+			progress.BytesRead = reader.n.Load()
+			p := *progress
+			onProgress(&p)
+		}
+
+		for {
+			row, raw, line, err := source.next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			var rowErr *importRowError
+			if errors.As(err, &rowErr) {
+				if err := reject(line, raw, rowErr); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			mapped := make(Row, len(indexes))
+			for i, idx := range indexes {
+				mapped[i] = row[idx]
+			}
+			batch = append(batch, pending{row: mapped, raw: raw, line: line})
+
+			if len(batch) >= batchSize {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+				report()
+			}
+		}
+
+		if err := flush(); err != nil {
+			return nil, err
+		}
+		report()
+
+		errFileName := ""
+		if progress.Failed > 0 {
+			errFileName = errorPath
+		}
+
+		return newSliceStream(
+			Header{"Imported", "Failed", "Error File"},
+			[]Row{{progress.Imported, progress.Failed, errFileName}},
+			&Meta{},
+		), nil
+	}
+
+	query := fmt.Sprintf("-- import %s into %s", opts.Path, opts.Table)
+
+	return newCallFromExecutor(exec, query, onEvent), nil
+}
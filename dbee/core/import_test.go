@@ -0,0 +1,109 @@
+package core_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_Import(t *testing.T) {
+	type testCase struct {
+		name          string
+		fileName      string
+		content       string
+		columns       map[string]string
+		expectedRows  []core.Row
+		expectedFails int
+	}
+
+	// rows with id "bad" are rejected by the database
+	validator := func(row core.Row) error {
+		if row[0] == "bad" {
+			return errors.New("invalid id")
+		}
+		return nil
+	}
+
+	testCases := []testCase{
+		{
+			name:     "csv",
+			fileName: "data.csv",
+			content:  "id,name\n1,alice\n2,\nbad,carol\n3,dave,extra\n4,\"o,neil\"\n",
+			expectedRows: []core.Row{
+				{"1", "alice"},
+				{"2", nil},
+				{"4", "o,neil"},
+			},
+			expectedFails: 2,
+		},
+		{
+			name:     "csv with mapping",
+			fileName: "data.csv",
+			content:  "name,id,ignored\nalice,1,x\nbob,2,y\n",
+			columns:  map[string]string{"id": "user_id"},
+			expectedRows: []core.Row{
+				{"1"},
+				{"2"},
+			},
+		},
+		{
+			name:     "ndjson",
+			fileName: "data.ndjson",
+			content:  "{\"id\": \"1\", \"tags\": [1, 2]}\n{\"id\": 2}\nnot json\n\n{\"id\": \"bad\"}\n",
+			expectedRows: []core.Row{
+				{"1", "[1,2]"},
+				{"2", nil},
+			},
+			expectedFails: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			path := filepath.Join(t.TempDir(), tc.fileName)
+			r.NoError(os.WriteFile(path, []byte(tc.content), 0o600))
+
+			adapter := mock.NewAdapter(nil, mock.AdapterWithImportValidator(validator))
+			connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+			r.NoError(err)
+
+			var last *core.ImportProgress
+			call, err := connection.Import(&core.ImportOptions{
+				Path:      path,
+				Table:     "users",
+				Columns:   tc.columns,
+				BatchSize: 2,
+			}, nil, func(p *core.ImportProgress) {
+				last = p
+			})
+			r.NoError(err)
+			<-call.Done()
+			r.NoError(call.Err())
+
+			r.Equal(tc.expectedRows, adapter.ImportedRows())
+
+			r.NotNil(last)
+			r.Equal(call.GetID(), last.CallID)
+			r.Equal(len(tc.expectedRows), last.Imported)
+			r.Equal(tc.expectedFails, last.Failed)
+			r.Equal(int64(len(tc.content)), last.BytesTotal)
+
+			errorFile, err := os.ReadFile(path + ".errors")
+			if tc.expectedFails == 0 {
+				r.True(os.IsNotExist(err))
+				return
+			}
+			r.NoError(err)
+			r.Len(strings.Split(strings.TrimSpace(string(errorFile)), "\n"), tc.expectedFails)
+		})
+	}
+}
@@ -0,0 +1,403 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// JSONPathSyntax is the way a database extracts a value at a path of a json
+// column.
+type JSONPathSyntax int
+
+const (
+	// JSONPathSyntaxStandard uses sql/json: JSON_VALUE(col, '$.a.b').
+	JSONPathSyntaxStandard JSONPathSyntax = iota
+	// JSONPathSyntaxExtract uses the json_extract function: json_extract(col, '$.a.b').
+	JSONPathSyntaxExtract
+	// JSONPathSyntaxArrow chains arrow operators: col -> 'a' ->> 'b'.
+	JSONPathSyntaxArrow
+	// JSONPathSyntaxColon traverses semi-structured data: col:a.b.
+	JSONPathSyntaxColon
+)
+
+// JSONPathDialect is an optional interface for drivers that don't extract
+// json values with sql/json functions.
+type JSONPathDialect interface {
+	JSONPathSyntax() JSONPathSyntax
+}
+
+// JSONFlattenOptions select values of a json column which are flattened into
+// columns of a derived result.
+type JSONFlattenOptions struct {
+	// name of the json column
+	Column string
+	// paths of values in the column (e.g. "address.city" or "tags[0]"),
+	// each one becomes a column named "<column>.<path>"
+	Paths []string
+}
+
+// jsonDetectRows is the number of rows inspected when detecting json columns.
+const jsonDetectRows = 100
+
+var jsonIdentifierRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// jsonPathStep is a key of an object or an index of an array.
+type jsonPathStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseJSONPath parses paths of dot separated keys with array indexes in
+// brackets. Keys with special characters are double quoted: a."b.c"[0].
+// A leading "$" is optional.
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	path = strings.TrimSpace(path)
+	path = strings.TrimPrefix(path, "$")
+
+	var steps []jsonPathStep
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+			if i >= len(path) || path[i] == '.' || path[i] == '[' {
+				return nil, fmt.Errorf("invalid json path %q: empty key", path)
+			}
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid json path %q: unclosed bracket", path)
+			}
+			index, err := strconv.Atoi(path[i+1 : i+end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid json path %q: invalid array index %q", path, path[i+1:i+end])
+			}
+			steps = append(steps, jsonPathStep{index: index, isIndex: true})
+			i += end + 1
+		case '"':
+			end := strings.IndexByte(path[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid json path %q: unclosed quote", path)
+			}
+			steps = append(steps, jsonPathStep{key: path[i+1 : i+1+end]})
+			i += end + 2
+		default:
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			steps = append(steps, jsonPathStep{key: path[i : i+end]})
+			i += end
+		}
+	}
+
+	if len(steps) < 1 {
+		return nil, errors.New("empty json path")
+	}
+	return steps, nil
+}
+
+// JSONPathExpression returns the sql expression which extracts the value at
+// the path (see JSONFlattenOptions) of the quoted json column.
+func JSONPathExpression(column, path string, syntax JSONPathSyntax) (string, error) {
+	return jsonPathExpression(column, path, syntax, DefaultDialect.StringLiteral)
+}
+
+// jsonPathExpression builds the expression with string literals of a dialect.
+func jsonPathExpression(column, path string, syntax JSONPathSyntax, literal func(string) string) (string, error) {
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return "", err
+	}
+
+	switch syntax {
+	case JSONPathSyntaxArrow:
+		expr := column
+		for i, step := range steps {
+			op := " -> "
+			if i == len(steps)-1 {
+				// the last value is extracted as text
+				op = " ->> "
+			}
+			if step.isIndex {
+				expr += op + strconv.Itoa(step.index)
+			} else {
+				expr += op + literal(step.key)
+			}
+		}
+		return expr, nil
+	case JSONPathSyntaxColon:
+		var b strings.Builder
+		b.WriteString(column)
+		for i, step := range steps {
+			switch {
+			case step.isIndex:
+				fmt.Fprintf(&b, "[%d]", step.index)
+				continue
+			case i == 0:
+				b.WriteString(":")
+			default:
+				b.WriteString(".")
+			}
+			if jsonIdentifierRe.MatchString(step.key) {
+				b.WriteString(step.key)
+			} else {
+				b.WriteString(quoteIdentifier(step.key))
+			}
+		}
+		return b.String(), nil
+	case JSONPathSyntaxExtract:
+		return fmt.Sprintf("json_extract(%s, %s)", column, literal(sqlJSONPath(steps))), nil
+	default:
+		return fmt.Sprintf("JSON_VALUE(%s, %s)", column, literal(sqlJSONPath(steps))), nil
+	}
+}
+
+// sqlJSONPath formats the steps as a sql/json path: $.a."b c"[0].
+func sqlJSONPath(steps []jsonPathStep) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, step := range steps {
+		switch {
+		case step.isIndex:
+			fmt.Fprintf(&b, "[%d]", step.index)
+		case jsonIdentifierRe.MatchString(step.key):
+			b.WriteString("." + step.key)
+		default:
+			b.WriteString("." + strconv.Quote(step.key))
+		}
+	}
+	return b.String()
+}
+
+// JSONPath returns the expression which extracts the value at the path of
+// the json column.
+func (d *Dialect) JSONPath(column, path string) (string, error) {
+	return jsonPathExpression(d.quote(column), path, d.jsonPath, d.StringLiteral)
+}
+
+// JSONPathExpression returns the expression which extracts the value at the
+// path of the json column in the dialect of the connection.
+func (c *Connection) JSONPathExpression(column, path string) (string, error) {
+	return c.Dialect().JSONPath(column, path)
+}
+
+// decodeJSON decodes json documents stored as text and values which drivers
+// already decoded (e.g. documents of document databases). The second return
+// value is false if the value isn't a json object or array.
+func decodeJSON(value any) (any, bool) {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		return nil, false
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	case json.RawMessage:
+		raw = v
+	default:
+		var err error
+		raw, err = json.Marshal(v)
+		if err != nil {
+			return nil, false
+		}
+	}
+
+	raw = bytes.TrimSpace(raw)
+	if len(raw) < 2 || (raw[0] != '{' && raw[0] != '[') {
+		return nil, false
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	// keep numbers as they are (e.g. big ids)
+	dec.UseNumber()
+	var decoded any
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, false
+	}
+	if dec.More() {
+		return nil, false
+	}
+	return decoded, true
+}
+
+// FormatJSONValue pretty prints a json value of a cell.
+func FormatJSONValue(value any) (string, error) {
+	decoded, ok := decodeJSON(value)
+	if !ok {
+		return "", errors.New("value is not a json object or array")
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(decoded); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// JSONColumns returns names of columns of the result which hold json objects
+// or arrays. Columns are detected from values of the first rows, all of which
+// must be either json or NULL.
+func JSONColumns(res *Result) ([]string, error) {
+	rows, err := res.Rows(0, min(res.Len(), jsonDetectRows))
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []string
+	for i, name := range res.Header() {
+		found := false
+		for _, row := range rows {
+			if i >= len(row) || row[i] == nil {
+				continue
+			}
+			if _, ok := decodeJSON(row[i]); !ok {
+				found = false
+				break
+			}
+			found = true
+		}
+		if found {
+			columns = append(columns, name)
+		}
+	}
+
+	return columns, nil
+}
+
+// jsonPathValue returns the value at the path of a decoded document. Nested
+// objects and arrays are returned as compact json.
+func jsonPathValue(doc any, steps []jsonPathStep) any {
+	current := doc
+	for _, step := range steps {
+		switch v := current.(type) {
+		case map[string]any:
+			if step.isIndex {
+				return nil
+			}
+			current = v[step.key]
+		case []any:
+			if !step.isIndex || step.index >= len(v) {
+				return nil
+			}
+			current = v[step.index]
+		default:
+			return nil
+		}
+	}
+
+	switch current.(type) {
+	case map[string]any, []any:
+		out, err := json.Marshal(current)
+		if err != nil {
+			return nil
+		}
+		return string(out)
+	default:
+		return current
+	}
+}
+
+// FlattenJSON returns a call whose result is the result set of the finished
+// source call with values at paths of a json column appended as columns.
+// Values of missing paths are NULL.
+func FlattenJSON(source *Call, set int, opts *JSONFlattenOptions, onEvent func(CallState, *Call)) (*Call, error) {
+	if opts == nil || opts.Column == "" {
+		return nil, errors.New("no json column provided")
+	}
+	if len(opts.Paths) < 1 {
+		return nil, errors.New("no json paths provided")
+	}
+	paths := make([][]jsonPathStep, len(opts.Paths))
+	for i, path := range opts.Paths {
+		steps, err := parseJSONPath(path)
+		if err != nil {
+			return nil, err
+		}
+		paths[i] = steps
+	}
+
+	exec := func(ctx context.Context) (ResultStream, error) {
+		select {
+		case <-source.Done():
+		default:
+			return nil, fmt.Errorf("call %s is still running", source.GetID())
+		}
+		if err := source.Err(); err != nil {
+			return nil, fmt.Errorf("call %s failed: %w", source.GetID(), err)
+		}
+		res, err := source.GetResultSet(set)
+		if err != nil {
+			return nil, err
+		}
+
+		column := -1
+		for i, name := range res.Header() {
+			if name == opts.Column {
+				column = i
+				break
+			}
+		}
+		if column < 0 {
+			return nil, fmt.Errorf("unknown column: %q", opts.Column)
+		}
+
+		rows, err := res.Rows(0, res.Len())
+		if err != nil {
+			return nil, err
+		}
+
+		header := append(Header{}, res.Header()...)
+		for _, path := range opts.Paths {
+			path = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(path), "$"), ".")
+			if strings.HasPrefix(path, "[") {
+				header = append(header, opts.Column+path)
+			} else {
+				header = append(header, opts.Column+"."+path)
+			}
+		}
+
+		meta := &Meta{}
+		skipped := 0
+		flattened := make([]Row, 0, len(rows))
+		for _, row := range rows {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			out := make(Row, len(row), len(row)+len(paths))
+			copy(out, row)
+
+			var doc any
+			if column < len(row) && row[column] != nil {
+				var ok bool
+				doc, ok = decodeJSON(row[column])
+				if !ok {
+					skipped++
+				}
+			}
+			for _, steps := range paths {
+				out = append(out, jsonPathValue(doc, steps))
+			}
+			flattened = append(flattened, out)
+		}
+		if skipped > 0 {
+			meta.Notices = append(meta.Notices, fmt.Sprintf("%d values of column %q are not json", skipped, opts.Column))
+		}
+
+		return newSliceStream(header, flattened, meta), nil
+	}
+
+	query := fmt.Sprintf("-- %s of call %s: %s", opts.Column, source.GetID(), strings.Join(opts.Paths, ", "))
+	return newCallFromExecutor(exec, query, onEvent), nil
+}
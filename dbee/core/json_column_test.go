@@ -0,0 +1,145 @@
+package core_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestJSONPathExpression(t *testing.T) {
+	r := require.New(t)
+
+	type testCase struct {
+		syntax   core.JSONPathSyntax
+		path     string
+		expected string
+	}
+
+	testCases := []testCase{
+		{syntax: core.JSONPathSyntaxStandard, path: "address.city", expected: `JSON_VALUE("data", '$.address.city')`},
+		{syntax: core.JSONPathSyntaxStandard, path: `$.tags[0]."first name"`, expected: `JSON_VALUE("data", '$.tags[0]."first name"')`},
+		{syntax: core.JSONPathSyntaxExtract, path: "items[1].sku", expected: `json_extract("data", '$.items[1].sku')`},
+		{syntax: core.JSONPathSyntaxArrow, path: "address.city", expected: `"data" -> 'address' ->> 'city'`},
+		{syntax: core.JSONPathSyntaxArrow, path: "tags[0]", expected: `"data" -> 'tags' ->> 0`},
+		{syntax: core.JSONPathSyntaxArrow, path: "o'neil", expected: `"data" ->> 'o''neil'`},
+		{syntax: core.JSONPathSyntaxColon, path: `items[0]."unit price"`, expected: `"data":items[0]."unit price"`},
+	}
+
+	for _, tc := range testCases {
+		expr, err := core.JSONPathExpression(`"data"`, tc.path, tc.syntax)
+		r.NoError(err, tc.path)
+		r.Equal(tc.expected, expr, tc.path)
+	}
+
+	for _, path := range []string{"", "a..b", "tags[x]", "tags[0", `"a`} {
+		_, err := core.JSONPathExpression(`"data"`, path, core.JSONPathSyntaxStandard)
+		r.Error(err, path)
+	}
+}
+
+func TestFormatJSONValue(t *testing.T) {
+	r := require.New(t)
+
+	formatted, err := core.FormatJSONValue([]byte(`{"id":12345678901234567890,"tags":["a","<b>"]}`))
+	r.NoError(err)
+	r.Equal("{\n  \"id\": 12345678901234567890,\n  \"tags\": [\n    \"a\",\n    \"<b>\"\n  ]\n}", formatted)
+
+	formatted, err = core.FormatJSONValue(map[string]any{"nested": map[string]any{"ok": true}})
+	r.NoError(err)
+	r.Equal("{\n  \"nested\": {\n    \"ok\": true\n  }\n}", formatted)
+
+	_, err = core.FormatJSONValue("plain text")
+	r.Error(err)
+	_, err = core.FormatJSONValue(42)
+	r.Error(err)
+}
+
+func TestFlattenJSON(t *testing.T) {
+	r := require.New(t)
+
+	rows := []core.Row{
+		{1, `{"customer": {"email": "a@example.com"}, "items": [{"sku": "x1"}, {"sku": "x2"}]}`},
+		{2, nil},
+		{3, []byte(`{"customer": {"email": "c@example.com", "tags": ["vip"]}, "items": []}`)},
+		{4, "not json"},
+	}
+	conn, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(rows,
+		mock.AdapterWithResultStreamOpts(mock.ResultStreamWithHeader(core.Header{"id", "payload"})),
+	))
+	r.NoError(err)
+
+	source := conn.Execute("_", nil)
+	select {
+	case <-source.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+	r.NoError(source.Err())
+
+	res, err := source.GetResult()
+	r.NoError(err)
+	columns, err := core.JSONColumns(res)
+	r.NoError(err)
+	r.Empty(columns, "a value which isn't json disqualifies the column")
+
+	call, err := core.FlattenJSON(source, 0, &core.JSONFlattenOptions{
+		Column: "payload",
+		Paths:  []string{"customer.email", "$.items[1].sku", "customer.tags"},
+	}, nil)
+	r.NoError(err)
+
+	select {
+	case <-call.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+	r.NoError(call.Err())
+
+	flattened, err := call.GetResult()
+	r.NoError(err)
+	r.Equal(core.Header{"id", "payload", "payload.customer.email", "payload.items[1].sku", "payload.customer.tags"}, flattened.Header())
+	r.Len(flattened.Meta().Notices, 1)
+
+	actual, err := flattened.Rows(0, len(rows))
+	r.NoError(err)
+	r.Equal([]core.Row{
+		append(rows[0], "a@example.com", "x2", nil),
+		append(rows[1], nil, nil, nil),
+		append(rows[2], "c@example.com", nil, `["vip"]`),
+		append(rows[3], nil, nil, nil),
+	}, actual)
+
+	_, err = core.FlattenJSON(source, 0, &core.JSONFlattenOptions{Column: "payload"}, nil)
+	r.Error(err)
+}
+
+func TestJSONColumns(t *testing.T) {
+	r := require.New(t)
+
+	rows := []core.Row{
+		{1, json.RawMessage(`[1, 2]`), `{"a": 1}`},
+		{2, nil, "{}"},
+	}
+	conn, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(rows,
+		mock.AdapterWithResultStreamOpts(mock.ResultStreamWithHeader(core.Header{"id", "list", "doc"})),
+	))
+	r.NoError(err)
+
+	call := conn.Execute("_", nil)
+	select {
+	case <-call.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+
+	res, err := call.GetResult()
+	r.NoError(err)
+	columns, err := core.JSONColumns(res)
+	r.NoError(err)
+	r.Equal([]string{"list", "doc"}, columns)
+}
@@ -0,0 +1,148 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// LayoutDumpVersion is the version of the layout document. It changes only
+// if fields are removed or their meaning changes, new fields can be added
+// within the same version.
+const LayoutDumpVersion = 1
+
+type (
+	// LayoutDump is the whole structure of a connection, meant to be consumed
+	// by external tools (e.g. as a test fixture).
+	LayoutDump struct {
+		Version    int               `json:"version"`
+		Connection *LayoutConnection `json:"connection"`
+		Nodes      []*LayoutNode     `json:"nodes"`
+	}
+
+	// LayoutConnection identifies the connection of a layout dump.
+	LayoutConnection struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		Type string `json:"type"`
+	}
+
+	// LayoutNode is a node of the structure. ID is the path of names from the
+	// root of the tree, joined with "/" (e.g. "public/users").
+	LayoutNode struct {
+		ID       string          `json:"id"`
+		Name     string          `json:"name"`
+		Schema   string          `json:"schema,omitempty"`
+		Type     string          `json:"type"`
+		Status   string          `json:"status,omitempty"`
+		Comment  string          `json:"comment,omitempty"`
+		Columns  []*LayoutColumn `json:"columns,omitempty"`
+		Children []*LayoutNode   `json:"children,omitempty"`
+	}
+
+	// LayoutColumn is a column of a table or a view.
+	LayoutColumn struct {
+		Name       string `json:"name"`
+		Type       string `json:"type"`
+		NotNull    bool   `json:"not_null,omitempty"`
+		Default    string `json:"default,omitempty"`
+		PrimaryKey bool   `json:"primary_key,omitempty"`
+		Comment    string `json:"comment,omitempty"`
+	}
+)
+
+// DumpLayout returns the whole structure of the connection (including children
+// of lazy nodes and columns of tables and views) as a versioned JSON document.
+// Nodes on every level are sorted by name and type, so dumps of the same
+// structure are equal.
+func (c *Connection) DumpLayout() ([]byte, error) {
+	structure, err := c.GetStructure()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := c.layoutNodes("", structure)
+	if err != nil {
+		return nil, err
+	}
+
+	dump := &LayoutDump{
+		Version: LayoutDumpVersion,
+		Connection: &LayoutConnection{
+			ID:   string(c.GetID()),
+			Name: c.GetName(),
+			Type: c.GetType(),
+		},
+		Nodes: nodes,
+	}
+
+	out, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("json.MarshalIndent: %w", err)
+	}
+	return out, nil
+}
+
+func (c *Connection) layoutNodes(parentID string, structure []*Structure) ([]*LayoutNode, error) {
+	nodes := make([]*LayoutNode, 0, len(structure))
+
+	for _, s := range structure {
+		id := s.Name
+		if parentID != "" {
+			id = parentID + "/" + s.Name
+		}
+		node := &LayoutNode{
+			ID:      id,
+			Name:    s.Name,
+			Schema:  s.Schema,
+			Type:    s.Type.String(),
+			Status:  s.Status,
+			Comment: s.Comment,
+		}
+
+		children := s.Children
+		if s.Lazy {
+			var err error
+			children, err = c.GetStructureChildren(s)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", id, err)
+			}
+		}
+		childNodes, err := c.layoutNodes(id, children)
+		if err != nil {
+			return nil, err
+		}
+		if len(childNodes) > 0 {
+			node.Children = childNodes
+		}
+
+		switch s.Type {
+		case StructureTypeTable, StructureTypeView, StructureTypeMaterializedView:
+			columns, err := c.GetColumns(&TableOptions{Table: s.Name, Schema: s.Schema, Materialization: s.Type})
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", id, err)
+			}
+			for _, col := range columns {
+				node.Columns = append(node.Columns, &LayoutColumn{
+					Name:       col.Name,
+					Type:       col.Type,
+					NotNull:    col.NotNull,
+					Default:    col.Default,
+					PrimaryKey: col.PrimaryKey,
+					Comment:    col.Comment,
+				})
+			}
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Name != nodes[j].Name {
+			return nodes[i].Name < nodes[j].Name
+		}
+		return nodes[i].Type < nodes[j].Type
+	})
+
+	return nodes, nil
+}
@@ -0,0 +1,55 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_DumpLayout(t *testing.T) {
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 1),
+		mock.AdapterWithTableDefinition("users", []*core.Column{
+			{Name: "id", Type: "int", PrimaryKey: true},
+			{Name: "name", Type: "text", NotNull: true},
+		}),
+		mock.AdapterWithRoutine(&core.Structure{
+			Name:   "add",
+			Schema: "public",
+			Type:   core.StructureTypeFunction,
+		}, "CREATE FUNCTION add(a int, b int) RETURNS int AS 'select a + b' LANGUAGE sql;"),
+	)
+	connection, err := core.NewConnection(&core.ConnectionParams{ID: "dev", Name: "Dev", Type: "mock"}, adapter)
+	r.NoError(err)
+
+	dump, err := connection.DumpLayout()
+	r.NoError(err)
+	r.JSONEq(`{
+		"version": 1,
+		"connection": {"id": "dev", "name": "Dev", "type": "mock"},
+		"nodes": [
+			{
+				"id": "public",
+				"name": "public",
+				"schema": "public",
+				"type": "",
+				"children": [
+					{"id": "public/add", "name": "add", "schema": "public", "type": "function"}
+				]
+			},
+			{
+				"id": "users",
+				"name": "users",
+				"type": "table",
+				"columns": [
+					{"name": "id", "type": "int", "primary_key": true},
+					{"name": "name", "type": "text", "not_null": true}
+				]
+			}
+		]
+	}`, string(dump))
+}
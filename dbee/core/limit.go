@@ -0,0 +1,97 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// LimitSyntax is the way a database limits the number of returned rows.
+type LimitSyntax int
+
+const (
+	// LimitSyntaxLimit appends "LIMIT n" to the query.
+	LimitSyntaxLimit LimitSyntax = iota
+	// LimitSyntaxTop inserts "TOP n" after SELECT.
+	LimitSyntaxTop
+	// LimitSyntaxFetchFirst appends "FETCH FIRST n ROWS ONLY" to the query.
+	LimitSyntaxFetchFirst
+)
+
+// LimitDialect is an optional interface for drivers that don't use
+// the LIMIT clause to limit the number of returned rows.
+type LimitDialect interface {
+	LimitSyntax() LimitSyntax
+}
+
+var (
+	limitExistingRe = regexp.MustCompile(`(?i)\b(limit|top|fetch\s+(first|next)|rownum)\b`)
+	// clauses which a limit can't follow (locking clauses, OFFSET without a
+	// limit, OFFSET ... FETCH of T-SQL) and SELECT ... INTO, which returns no rows
+	limitConflictRe = regexp.MustCompile(`(?i)\b(offset|into|for\s+(update|share|no\s+key\s+update|key\s+share)|lock\s+in\s+share\s+mode)\b`)
+	limitSelectRe   = regexp.MustCompile(`(?i)^select(\s+(distinct|all))?\s`)
+	// quoted identifiers, which may be named like keywords (e.g. "offset")
+	limitQuotedRe = regexp.MustCompile("\"(?:[^\"]|\"\")*\"|`[^`]*`|\\[[^\\]]*\\]")
+)
+
+// InjectLimit adds a limit clause to SELECT statements that don't already
+// limit the number of rows. Only single statement queries are rewritten, and
+// queries with locking clauses, OFFSET (or OFFSET ... FETCH) or INTO are left
+// unchanged. It returns the rewritten query and true if the limit was injected.
+func InjectLimit(query string, limit int, syntax LimitSyntax) (string, bool) {
+	if limit <= 0 {
+		return query, false
+	}
+
+	normalized := strings.TrimRight(normalizeStatement(query), "; ")
+	if strings.Contains(normalized, ";") {
+		return query, false
+	}
+	unquoted := limitQuotedRe.ReplaceAllString(normalized, `""`)
+	if limitExistingRe.MatchString(unquoted) || limitConflictRe.MatchString(unquoted) {
+		return query, false
+	}
+
+	first := strings.ToLower(strings.SplitN(normalized, " ", 2)[0])
+	if first != "select" && (first != "with" || syntax == LimitSyntaxTop) {
+		return query, false
+	}
+
+	limited, ok := appendLimit(trimStatement(query), limit, syntax)
+	if !ok {
+		return query, false
+	}
+	return limited, true
+}
+
+// trimStatement strips whitespace, the trailing terminator and comments around it
+// from a statement, so clauses can be appended to it.
+func trimStatement(query string) string {
+	tokens := lexSQL(query, nil)
+	end := len(tokens)
+	for end > 0 && (tokens[end-1].kind == sqlTokenComment || tokens[end-1].is(";")) {
+		end--
+	}
+	if end == 0 {
+		return ""
+	}
+	return strings.TrimSpace(query[:tokens[end-1].end])
+}
+
+// appendLimit adds the limit clause to a trimmed SELECT statement without
+// checking whether it's already limited.
+func appendLimit(trimmed string, limit int, syntax LimitSyntax) (string, bool) {
+	switch syntax {
+	case LimitSyntaxTop:
+		trimmed = strings.TrimSpace(trimmed)
+		loc := limitSelectRe.FindStringIndex(trimmed)
+		if loc == nil {
+			return "", false
+		}
+		return fmt.Sprintf("%sTOP %d %s", trimmed[:loc[1]], limit, trimmed[loc[1]:]), true
+	case LimitSyntaxFetchFirst:
+		return fmt.Sprintf("%s\nFETCH FIRST %d ROWS ONLY", trimmed, limit), true
+	default:
+		return fmt.Sprintf("%s\nLIMIT %d", trimmed, limit), true
+	}
+}
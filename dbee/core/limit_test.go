@@ -0,0 +1,179 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestInjectLimit(t *testing.T) {
+	type testCase struct {
+		name     string
+		query    string
+		syntax   core.LimitSyntax
+		expected string
+		injected bool
+	}
+
+	testCases := []testCase{
+		{
+			name:     "limit",
+			query:    "SELECT * FROM t;",
+			syntax:   core.LimitSyntaxLimit,
+			expected: "SELECT * FROM t\nLIMIT 100",
+			injected: true,
+		},
+		{
+			name:     "trailing line comment",
+			query:    "SELECT * FROM t; -- note",
+			syntax:   core.LimitSyntaxLimit,
+			expected: "SELECT * FROM t\nLIMIT 100",
+			injected: true,
+		},
+		{
+			name:     "trailing block comment",
+			query:    "SELECT * FROM t /* note */;\n/* another; note */",
+			syntax:   core.LimitSyntaxFetchFirst,
+			expected: "SELECT * FROM t\nFETCH FIRST 100 ROWS ONLY",
+			injected: true,
+		},
+		{
+			name:     "comment before terminator",
+			query:    "SELECT * FROM t -- note\n;",
+			syntax:   core.LimitSyntaxLimit,
+			expected: "SELECT * FROM t\nLIMIT 100",
+			injected: true,
+		},
+		{
+			name:     "top",
+			query:    "select distinct a from t",
+			syntax:   core.LimitSyntaxTop,
+			expected: "select distinct TOP 100 a from t",
+			injected: true,
+		},
+		{
+			name:     "fetch first",
+			query:    "SELECT * FROM t",
+			syntax:   core.LimitSyntaxFetchFirst,
+			expected: "SELECT * FROM t\nFETCH FIRST 100 ROWS ONLY",
+			injected: true,
+		},
+		{
+			name:     "cte",
+			query:    "WITH x AS (SELECT 1) SELECT * FROM x",
+			syntax:   core.LimitSyntaxLimit,
+			expected: "WITH x AS (SELECT 1) SELECT * FROM x\nLIMIT 100",
+			injected: true,
+		},
+		{
+			name:     "already limited",
+			query:    "SELECT * FROM t LIMIT 5",
+			syntax:   core.LimitSyntaxLimit,
+			expected: "SELECT * FROM t LIMIT 5",
+		},
+		{
+			name:     "not a select",
+			query:    "UPDATE t SET a = 1",
+			syntax:   core.LimitSyntaxLimit,
+			expected: "UPDATE t SET a = 1",
+		},
+		{
+			name:     "multiple statements",
+			query:    "SELECT 1; SELECT 2",
+			syntax:   core.LimitSyntaxLimit,
+			expected: "SELECT 1; SELECT 2",
+		},
+		{
+			name:     "for update",
+			query:    "SELECT * FROM t WHERE id = 1 FOR UPDATE",
+			syntax:   core.LimitSyntaxLimit,
+			expected: "SELECT * FROM t WHERE id = 1 FOR UPDATE",
+		},
+		{
+			name:     "for share",
+			query:    "SELECT * FROM t for  share",
+			syntax:   core.LimitSyntaxLimit,
+			expected: "SELECT * FROM t for  share",
+		},
+		{
+			name:     "for no key update",
+			query:    "SELECT * FROM t FOR NO KEY UPDATE SKIP LOCKED",
+			syntax:   core.LimitSyntaxFetchFirst,
+			expected: "SELECT * FROM t FOR NO KEY UPDATE SKIP LOCKED",
+		},
+		{
+			name:     "lock in share mode",
+			query:    "SELECT * FROM t LOCK IN SHARE MODE",
+			syntax:   core.LimitSyntaxLimit,
+			expected: "SELECT * FROM t LOCK IN SHARE MODE",
+		},
+		{
+			name:     "offset",
+			query:    "SELECT * FROM t ORDER BY id OFFSET 10",
+			syntax:   core.LimitSyntaxLimit,
+			expected: "SELECT * FROM t ORDER BY id OFFSET 10",
+		},
+		{
+			name:     "offset rows",
+			query:    "SELECT * FROM t ORDER BY id OFFSET 10 ROWS",
+			syntax:   core.LimitSyntaxFetchFirst,
+			expected: "SELECT * FROM t ORDER BY id OFFSET 10 ROWS",
+		},
+		{
+			name:     "offset fetch",
+			query:    "SELECT * FROM t ORDER BY id OFFSET 10 ROWS FETCH NEXT 5 ROWS ONLY",
+			syntax:   core.LimitSyntaxTop,
+			expected: "SELECT * FROM t ORDER BY id OFFSET 10 ROWS FETCH NEXT 5 ROWS ONLY",
+		},
+		{
+			name:     "offset without fetch",
+			query:    "SELECT * FROM t ORDER BY id OFFSET 10 ROWS",
+			syntax:   core.LimitSyntaxTop,
+			expected: "SELECT * FROM t ORDER BY id OFFSET 10 ROWS",
+		},
+		{
+			name:     "select into",
+			query:    "SELECT * INTO t2 FROM t",
+			syntax:   core.LimitSyntaxTop,
+			expected: "SELECT * INTO t2 FROM t",
+		},
+		{
+			name:     "select into outfile",
+			query:    "SELECT * FROM t INTO OUTFILE '/tmp/t.csv'",
+			syntax:   core.LimitSyntaxLimit,
+			expected: "SELECT * FROM t INTO OUTFILE '/tmp/t.csv'",
+		},
+		{
+			name:     "keywords in literal",
+			query:    "SELECT 'for update', 'offset', 'into' FROM t",
+			syntax:   core.LimitSyntaxLimit,
+			expected: "SELECT 'for update', 'offset', 'into' FROM t\nLIMIT 100",
+			injected: true,
+		},
+		{
+			name:     "keywords in quoted identifiers",
+			query:    "SELECT \"offset\", `limit`, [into] FROM t",
+			syntax:   core.LimitSyntaxLimit,
+			expected: "SELECT \"offset\", `limit`, [into] FROM t\nLIMIT 100",
+			injected: true,
+		},
+		{
+			name:     "limit keyword in literal",
+			query:    "SELECT 'limit' FROM t",
+			syntax:   core.LimitSyntaxLimit,
+			expected: "SELECT 'limit' FROM t\nLIMIT 100",
+			injected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			out, injected := core.InjectLimit(tc.query, 100, tc.syntax)
+			r.Equal(tc.injected, injected)
+			r.Equal(tc.expected, out)
+		})
+	}
+}
@@ -0,0 +1,439 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LintSeverity is the severity of a lint diagnostic.
+type LintSeverity string
+
+const (
+	LintSeverityError   LintSeverity = "error"
+	LintSeverityWarning LintSeverity = "warning"
+	LintSeverityInfo    LintSeverity = "info"
+)
+
+// Rules reported by Lint
+const (
+	LintRuleMissingWhere    = "missing_where"
+	LintRuleUnknownTable    = "unknown_table"
+	LintRuleAmbiguousColumn = "ambiguous_column"
+)
+
+// LintDiagnostic is a problem found in a statement.
+type LintDiagnostic struct {
+	// 0-based positions of the offending text (end is exclusive)
+	Line    int
+	Col     int
+	EndLine int
+	EndCol  int
+
+	Severity LintSeverity
+	Rule     string
+	Message  string
+}
+
+// lintCatalog is the cached structure statements are checked against.
+type lintCatalog struct {
+	// tables and views by lower case name and "schema.name"
+	tables map[string]*Structure
+	// whether the whole structure is cached, so missing tables don't exist
+	complete bool
+	// columns of a table, nil if they can't be loaded
+	columns func(table *Structure) []*Column
+}
+
+// lintProblem is a diagnostic with byte offsets in the statement.
+type lintProblem struct {
+	start    int
+	end      int
+	severity LintSeverity
+	rule     string
+	message  string
+}
+
+// lintTableRef is a table referenced in a statement.
+type lintTableRef struct {
+	// keyword before the table (FROM, JOIN, INTO or UPDATE)
+	keyword string
+	// index of the first and the last token of the name
+	first int
+	last  int
+	name  []string
+	alias string
+	// cached table, nil if unknown
+	table *Structure
+}
+
+// Lint checks statements in text for DELETE and UPDATE without WHERE, tables
+// which don't exist and ambiguous column references. Tables are checked only
+// against the structure which is already cached, columns of referenced tables
+// are loaded if they aren't cached yet.
+func (c *Connection) Lint(text string) []*LintDiagnostic {
+	catalog := c.lintCatalog()
+
+	var diagnostics []*LintDiagnostic
+	for _, stmt := range c.SplitStatements(text) {
+		tokens := lexSQL(stmt.Text, c.statementDialect())
+		for _, p := range lintStatement(tokens, catalog) {
+			d := &LintDiagnostic{Severity: p.severity, Rule: p.rule, Message: p.message}
+			d.Line, d.Col = textPosition(text, stmt.Start+p.start)
+			d.EndLine, d.EndCol = textPosition(text, stmt.Start+p.end)
+			diagnostics = append(diagnostics, d)
+		}
+	}
+
+	return diagnostics
+}
+
+func (c *Connection) lintCatalog() *lintCatalog {
+	tables, complete := c.structure.objects()
+	return &lintCatalog{
+		tables:   tables,
+		complete: complete,
+		columns: func(table *Structure) []*Column {
+			columns, ok := c.structure.getColumns(table.Schema, table.Name)
+			if ok {
+				return columns
+			}
+			columns, err := c.GetColumns(&TableOptions{Table: table.Name, Schema: table.Schema, Materialization: table.Type})
+			if err != nil {
+				return nil
+			}
+			c.structure.setColumns(table.Schema, table.Name, columns)
+			return columns
+		},
+	}
+}
+
+// objects returns cached tables and views by lower case name and
+// "schema.name". The structure is complete if the top-level structure and
+// children of all lazily loaded nodes are cached.
+func (sc *structureCache) objects() (map[string]*Structure, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	tables := make(map[string]*Structure)
+	root, ok := sc.entries[structureCacheKey(nil)]
+	if !ok {
+		return tables, false
+	}
+
+	complete := true
+	var walk func(nodes []*Structure)
+	walk = func(nodes []*Structure) {
+		for _, node := range nodes {
+			switch node.Type {
+			case StructureTypeTable, StructureTypeView, StructureTypeMaterializedView:
+				name := strings.ToLower(node.Name)
+				if _, ok := tables[name]; !ok {
+					tables[name] = node
+				}
+				if node.Schema != "" {
+					tables[strings.ToLower(node.Schema)+"."+name] = node
+				}
+			}
+
+			children := node.Children
+			if node.Lazy {
+				entry, ok := sc.entries[structureCacheKey(node)]
+				if !ok {
+					complete = false
+					continue
+				}
+				children = entry.structure
+			}
+			walk(children)
+		}
+	}
+	walk(root.structure)
+
+	return tables, complete
+}
+
+// lintStatement returns problems of a single statement.
+func lintStatement(tokens []*sqlToken, catalog *lintCatalog) []*lintProblem {
+	var problems []*lintProblem
+	report := func(first, last *sqlToken, severity LintSeverity, rule, message string) {
+		problems = append(problems, &lintProblem{
+			start:    first.start,
+			end:      last.end,
+			severity: severity,
+			rule:     rule,
+			message:  message,
+		})
+	}
+
+	words, depths, opens := lintWords(tokens)
+	if len(words) == 0 {
+		return nil
+	}
+
+	// DELETE and UPDATE without WHERE
+	if first := words[0]; first.is("DELETE", "UPDATE") {
+		hasWhere := false
+		for i, tok := range words {
+			if depths[i] == 0 && tok.is("WHERE") {
+				hasWhere = true
+				break
+			}
+		}
+		if !hasWhere {
+			report(first, first, LintSeverityWarning, LintRuleMissingWhere,
+				fmt.Sprintf("%s without WHERE affects all rows", first.upper()))
+		}
+	}
+
+	ctes := lintCTENames(words)
+	refs := lintTableRefs(words, depths, opens)
+
+	// tables which don't exist
+	for _, ref := range refs {
+		key := strings.ToLower(strings.Join(ref.name, "."))
+		if ctes[key] {
+			continue
+		}
+		ref.table = catalog.lookup(ref.name)
+		if ref.table != nil || !catalog.complete || lintIsSystemTable(ref.name) {
+			continue
+		}
+		report(words[ref.first], words[ref.last], LintSeverityWarning, LintRuleUnknownTable,
+			fmt.Sprintf("table %q does not exist", strings.Join(ref.name, ".")))
+	}
+
+	// unqualified columns which exist in more than one of the joined tables
+	var joined []*lintTableRef
+	for _, ref := range refs {
+		if depths[ref.first] == 0 && ref.table != nil && ref.keyword != "INTO" {
+			joined = append(joined, ref)
+		}
+	}
+	if len(joined) < 2 || catalog.columns == nil {
+		return problems
+	}
+
+	columns := make([]map[string]bool, len(joined))
+	for i, ref := range joined {
+		columns[i] = make(map[string]bool)
+		for _, col := range catalog.columns(ref.table) {
+			columns[i][strings.ToLower(col.Name)] = true
+		}
+	}
+
+	// names which aren't columns: tables, aliases and select aliases
+	skip := make(map[int]bool)
+	aliases := make(map[string]bool)
+	for _, ref := range refs {
+		for i := ref.first; i <= ref.last; i++ {
+			skip[i] = true
+		}
+		if ref.alias != "" {
+			aliases[strings.ToLower(ref.alias)] = true
+		}
+	}
+	for i, tok := range words {
+		if tok.is("AS") && i+1 < len(words) {
+			aliases[strings.ToLower(lintIdent(words[i+1]))] = true
+		}
+	}
+
+	// columns assigned by UPDATE ... SET belong to the updated table
+	inSet := false
+	for i, tok := range words {
+		if depths[i] == 0 && tok.is("SET", "FROM", "WHERE") {
+			inSet = tok.is("SET")
+		}
+		if depths[i] != 0 || skip[i] || (tok.kind != sqlTokenWord && tok.kind != sqlTokenQuotedIdent) {
+			continue
+		}
+		if inSet && i+1 < len(words) && words[i+1].is("=") {
+			continue
+		}
+		if tok.kind == sqlTokenWord && formatKeywords[tok.upper()] {
+			continue
+		}
+		if (i > 0 && words[i-1].is(".", "AS")) || (i+1 < len(words) && words[i+1].is(".", "(")) {
+			continue
+		}
+
+		name := strings.ToLower(lintIdent(tok))
+		if aliases[name] {
+			continue
+		}
+
+		var in []string
+		for j, ref := range joined {
+			if columns[j][name] {
+				in = append(in, ref.table.Name)
+			}
+		}
+		if len(in) > 1 {
+			sort.Strings(in)
+			report(tok, tok, LintSeverityWarning, LintRuleAmbiguousColumn,
+				fmt.Sprintf("column %q is ambiguous (%s)", lintIdent(tok), strings.Join(in, ", ")))
+		}
+	}
+
+	return problems
+}
+
+// lintWords returns tokens of a statement without comments, the parenthesis
+// depth of each of them and the index of the innermost open parenthesis (-1
+// outside of parentheses).
+func lintWords(tokens []*sqlToken) (words []*sqlToken, depths, opens []int) {
+	// comments don't affect the statement
+	words = make([]*sqlToken, 0, len(tokens))
+	for _, tok := range tokens {
+		if tok.kind != sqlTokenComment {
+			words = append(words, tok)
+		}
+	}
+
+	depths = make([]int, len(words))
+	opens = make([]int, len(words))
+	var open []int
+	for i, tok := range words {
+		if tok.is(")") && len(open) > 0 {
+			open = open[:len(open)-1]
+		}
+		depths[i] = len(open)
+		opens[i] = -1
+		if len(open) > 0 {
+			opens[i] = open[len(open)-1]
+		}
+		if tok.is("(") {
+			open = append(open, i)
+		}
+	}
+
+	return words, depths, opens
+}
+
+// lookup returns a cached table by its (possibly qualified) name.
+func (lc *lintCatalog) lookup(name []string) *Structure {
+	if len(name) > 2 {
+		// database.schema.table
+		name = name[len(name)-2:]
+	}
+	return lc.tables[strings.ToLower(strings.Join(name, "."))]
+}
+
+// lintTableRefs returns tables after FROM, JOIN, INSERT INTO and UPDATE,
+// including comma separated tables of the FROM clause. FROM inside function
+// calls (e.g. EXTRACT(year FROM date)) is ignored.
+func lintTableRefs(words []*sqlToken, depths, opens []int) []*lintTableRef {
+	var refs []*lintTableRef
+	for i := 0; i < len(words); i++ {
+		tok := words[i]
+		switch {
+		case tok.is("FROM", "JOIN"):
+			if tok.is("FROM") && i > 0 && words[i-1].is("DISTINCT") {
+				continue
+			}
+			if o := opens[i]; o >= 0 && !words[o+1].is("SELECT", "WITH") {
+				continue
+			}
+		case tok.is("INTO"):
+			if !words[0].is("INSERT", "MERGE", "REPLACE") {
+				continue
+			}
+		case tok.is("UPDATE"):
+			if i > 0 {
+				continue
+			}
+		default:
+			continue
+		}
+
+		j := i + 1
+		for {
+			if j < len(words) && words[j].is("ONLY", "LATERAL") {
+				j++
+			}
+			ref, next := lintReadTableRef(words, j, tok.is("INTO"))
+			if ref == nil {
+				break
+			}
+			ref.keyword = tok.upper()
+			refs = append(refs, ref)
+			j = next
+			// more tables in the FROM clause
+			if !tok.is("FROM") || j >= len(words) || !words[j].is(",") || depths[j] != depths[i] {
+				break
+			}
+			j++
+		}
+	}
+	return refs
+}
+
+// lintReadTableRef reads a table name and its alias starting at i. Tables
+// followed by a parenthesis are table functions, unless the parenthesis is a
+// list of columns.
+func lintReadTableRef(words []*sqlToken, i int, columnList bool) (*lintTableRef, int) {
+	isName := func(j int) bool {
+		return j < len(words) && (words[j].kind == sqlTokenQuotedIdent ||
+			(words[j].kind == sqlTokenWord && !formatKeywords[words[j].upper()]))
+	}
+	if !isName(i) {
+		return nil, i
+	}
+
+	ref := &lintTableRef{first: i, last: i, name: []string{lintIdent(words[i])}}
+	j := i + 1
+	for j+1 < len(words) && words[j].is(".") && isName(j+1) {
+		ref.last = j + 1
+		ref.name = append(ref.name, lintIdent(words[j+1]))
+		j += 2
+	}
+	if j < len(words) && words[j].is("(") {
+		if columnList {
+			return ref, j
+		}
+		return nil, j
+	}
+
+	if j < len(words) && words[j].is("AS") {
+		j++
+	}
+	if isName(j) {
+		ref.alias = lintIdent(words[j])
+		j++
+	}
+
+	return ref, j
+}
+
+// lintCTENames returns lower case names of common table expressions.
+func lintCTENames(words []*sqlToken) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i+2 < len(words); i++ {
+		if words[i+1].is("AS") && words[i+2].is("(") && i > 0 && words[i-1].is("WITH", "RECURSIVE", ",", ")") {
+			names[strings.ToLower(lintIdent(words[i]))] = true
+		}
+	}
+	return names
+}
+
+// lintIsSystemTable reports whether the table is a well known system table
+// which is usually not part of the structure.
+func lintIsSystemTable(name []string) bool {
+	table := strings.ToLower(name[len(name)-1])
+	if len(name) > 1 {
+		switch strings.ToLower(name[len(name)-2]) {
+		case "information_schema", "pg_catalog", "sys", "mysql", "performance_schema":
+			return true
+		}
+	}
+	return table == "dual" || strings.HasPrefix(table, "pg_") || strings.HasPrefix(table, "sqlite_")
+}
+
+// lintIdent returns the name of an identifier without quotes.
+func lintIdent(tok *sqlToken) string {
+	if tok.kind != sqlTokenQuotedIdent || len(tok.text) < 2 {
+		return tok.text
+	}
+	return tok.text[1 : len(tok.text)-1]
+}
@@ -0,0 +1,57 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_Lint(t *testing.T) {
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 1),
+		mock.AdapterWithTableDefinition("users", []*core.Column{{Name: "id", Type: "int"}, {Name: "name", Type: "text"}}),
+		mock.AdapterWithTableDefinition("orders", []*core.Column{{Name: "id", Type: "int"}, {Name: "user_id", Type: "int"}}),
+	)
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	rules := func(text string) []string {
+		var out []string
+		for _, d := range connection.Lint(text) {
+			out = append(out, d.Rule)
+		}
+		return out
+	}
+
+	// unknown tables aren't reported until the structure is cached
+	r.Empty(rules("select * from customers"))
+
+	_, err = connection.GetStructure()
+	r.NoError(err)
+
+	r.Empty(rules("select name from users where id = 1"))
+	r.Empty(rules("delete from users where id = 1"))
+	r.Empty(rules("with x as (select 1) select extract(year from now()) from x"))
+	r.Empty(rules("select u.id, user_id from users u join orders o on u.id = o.user_id"))
+	r.Empty(rules("insert into users (id, name) select 1, 'a'"))
+
+	diagnostics := connection.Lint("select 1;\ndelete from orders;\nselect * from customers")
+	r.Len(diagnostics, 2)
+	r.Equal(&core.LintDiagnostic{
+		Line: 1, Col: 0, EndLine: 1, EndCol: 6,
+		Severity: core.LintSeverityWarning,
+		Rule:     core.LintRuleMissingWhere,
+		Message:  "DELETE without WHERE affects all rows",
+	}, diagnostics[0])
+	r.Equal(core.LintRuleUnknownTable, diagnostics[1].Rule)
+	r.Equal([]int{2, 14, 2, 23}, []int{diagnostics[1].Line, diagnostics[1].Col, diagnostics[1].EndLine, diagnostics[1].EndCol})
+
+	diagnostics = connection.Lint("select id, name from users, orders")
+	r.Len(diagnostics, 1)
+	r.Equal(core.LintRuleAmbiguousColumn, diagnostics[0].Rule)
+	r.Equal(7, diagnostics[0].Col)
+}
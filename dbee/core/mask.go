@@ -0,0 +1,219 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrUnknownMaskMethod = errors.New("unknown mask method")
+
+const (
+	// MaskRedact replaces the whole value.
+	MaskRedact = "redact"
+	// MaskHash replaces the value with a short hash, so equal values stay
+	// comparable.
+	MaskHash = "hash"
+	// MaskPartial keeps the last few characters of the value.
+	MaskPartial = "partial"
+)
+
+const (
+	maskRedacted       = "****"
+	maskHashLength     = 12
+	defaultMaskPartial = 4
+)
+
+// MaskRule masks values of matching columns in results of a connection.
+type MaskRule struct {
+	// Column is the name of masked columns (case insensitive).
+	Column string
+	// Pattern is a regular expression matched against column names. It's
+	// used instead of Column if it's set.
+	Pattern string
+	// Method is MaskRedact (default), MaskHash or MaskPartial.
+	Method string
+	// Keep is the number of trailing characters kept by MaskPartial (4 if 0).
+	Keep int
+}
+
+func (mr *MaskRule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Column  string `json:"column,omitempty"`
+		Pattern string `json:"pattern,omitempty"`
+		Method  string `json:"method,omitempty"`
+		Keep    int    `json:"keep,omitempty"`
+	}{
+		Column:  mr.Column,
+		Pattern: mr.Pattern,
+		Method:  mr.Method,
+		Keep:    mr.Keep,
+	})
+}
+
+// compiledMaskRule is a validated MaskRule.
+type compiledMaskRule struct {
+	rule    MaskRule
+	pattern *regexp.Regexp
+}
+
+func (r *compiledMaskRule) matches(column string) bool {
+	if r.pattern != nil {
+		return r.pattern.MatchString(column)
+	}
+	return strings.EqualFold(r.rule.Column, column)
+}
+
+// masker applies mask rules of a connection to result streams.
+type masker struct {
+	rules []*compiledMaskRule
+}
+
+// newMasker validates the rules. It returns nil if there are no rules.
+func newMasker(rules []MaskRule) (*masker, error) {
+	if len(rules) < 1 {
+		return nil, nil
+	}
+
+	m := &masker{}
+	for i, rule := range rules {
+		switch rule.Method {
+		case "":
+			rule.Method = MaskRedact
+		case MaskRedact, MaskHash, MaskPartial:
+		default:
+			return nil, fmt.Errorf("rule %d: %w: %q", i, ErrUnknownMaskMethod, rule.Method)
+		}
+		if rule.Method == MaskPartial && rule.Keep <= 0 {
+			rule.Keep = defaultMaskPartial
+		}
+
+		compiled := &compiledMaskRule{rule: rule}
+		if rule.Pattern != "" {
+			re, err := regexp.Compile("(?i)" + rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: regexp.Compile: %w", i, err)
+			}
+			compiled.pattern = re
+		} else if rule.Column == "" {
+			return nil, fmt.Errorf("rule %d: column or pattern is required", i)
+		}
+		m.rules = append(m.rules, compiled)
+	}
+
+	return m, nil
+}
+
+// columns returns the rule of every column of header (nil for columns which
+// aren't masked). The first matching rule wins.
+func (m *masker) columns(header Header) ([]*compiledMaskRule, bool) {
+	rules := make([]*compiledMaskRule, len(header))
+	found := false
+	for i, column := range header {
+		for _, rule := range m.rules {
+			if rule.matches(column) {
+				rules[i] = rule
+				found = true
+				break
+			}
+		}
+	}
+	return rules, found
+}
+
+// stream returns a stream which masks rows of iter. Streams with multiple
+// result sets stay multi set streams.
+func (m *masker) stream(iter ResultStream) ResultStream {
+	if m == nil {
+		return iter
+	}
+
+	ms := &maskedStream{ResultStream: iter, masker: m}
+	ms.update()
+	if multi, ok := iter.(MultiResultStream); ok {
+		return &maskedMultiStream{maskedStream: ms, multi: multi}
+	}
+	return ms
+}
+
+// maskValue masks a single value with the rule. NULLs stay NULLs.
+func maskValue(rule *MaskRule, value any) any {
+	if value == nil {
+		return nil
+	}
+
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		text = fmt.Sprint(v)
+	}
+
+	switch rule.Method {
+	case MaskHash:
+		sum := sha256.Sum256([]byte(text))
+		return hex.EncodeToString(sum[:])[:maskHashLength]
+	case MaskPartial:
+		length := utf8.RuneCountInString(text)
+		if length <= rule.Keep {
+			return strings.Repeat("*", length)
+		}
+		runes := []rune(text)
+		return strings.Repeat("*", length-rule.Keep) + string(runes[length-rule.Keep:])
+	default:
+		return maskRedacted
+	}
+}
+
+// maskedStream masks values of matching columns of the underlying stream.
+type maskedStream struct {
+	ResultStream
+	masker *masker
+	// rules of columns of the current header
+	rules  []*compiledMaskRule
+	masked bool
+}
+
+// update matches rules against the current header.
+func (ms *maskedStream) update() {
+	ms.rules, ms.masked = ms.masker.columns(ms.ResultStream.Header())
+}
+
+func (ms *maskedStream) Next() (Row, error) {
+	row, err := ms.ResultStream.Next()
+	if err != nil || !ms.masked {
+		return row, err
+	}
+
+	masked := make(Row, len(row))
+	for i, value := range row {
+		if i < len(ms.rules) && ms.rules[i] != nil {
+			masked[i] = maskValue(&ms.rules[i].rule, value)
+		} else {
+			masked[i] = value
+		}
+	}
+	return masked, nil
+}
+
+// maskedMultiStream is a maskedStream over a stream with multiple result sets.
+type maskedMultiStream struct {
+	*maskedStream
+	multi MultiResultStream
+}
+
+func (ms *maskedMultiStream) NextResultSet() bool {
+	if !ms.multi.NextResultSet() {
+		return false
+	}
+	ms.update()
+	return true
+}
@@ -0,0 +1,110 @@
+package core_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_Masking(t *testing.T) {
+	r := require.New(t)
+
+	rows := []core.Row{
+		{1, "alice@example.com", "secret-token", "4111111111111111", nil},
+		{2, "bob@example.com", "other-token", "5500", "note"},
+	}
+	adapter := mock.NewAdapter(rows,
+		mock.AdapterWithResultStreamOpts(
+			mock.ResultStreamWithHeader(core.Header{"id", "Email", "api_token", "card", "note"}),
+			mock.ResultStreamWithExtraSets([]core.Row{{"x@example.com"}}),
+		),
+	)
+	connection, err := core.NewConnection(&core.ConnectionParams{
+		Masking: []core.MaskRule{
+			{Column: "email", Method: core.MaskHash},
+			{Pattern: "token$"},
+			{Column: "card", Method: core.MaskPartial},
+			{Column: "header_0", Method: core.MaskPartial, Keep: 2},
+		},
+	}, adapter)
+	r.NoError(err)
+
+	call := connection.Execute("_", nil)
+	select {
+	case <-call.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+	r.NoError(call.Err())
+
+	result, err := call.GetResult()
+	r.NoError(err)
+	actual, err := result.Rows(0, len(rows))
+	r.NoError(err)
+	r.Len(actual, 2)
+
+	// ids and NULLs are kept
+	r.Equal(1, actual[0][0])
+	r.Nil(actual[0][4])
+	r.Equal("note", actual[1][4])
+
+	// values are replaced with hashes
+	r.Len(actual[0][1], 12)
+	r.NotEqual(actual[0][1], actual[1][1])
+	r.NotContains(actual[0][1], "alice")
+
+	r.Equal("****", actual[0][2])
+	r.Equal("****", actual[1][2])
+	r.Equal("************1111", actual[0][3])
+	r.Equal("****", actual[1][3])
+
+	// rules are matched against headers of every result set
+	second, err := call.GetResultSet(1)
+	r.NoError(err)
+	actual, err = second.Rows(0, 1)
+	r.NoError(err)
+	r.Equal(core.Row{"***********om"}, actual[0])
+}
+
+func TestConnection_MaskingInvalidRules(t *testing.T) {
+	r := require.New(t)
+
+	for _, rules := range [][]core.MaskRule{
+		{{Column: "email", Method: "scramble"}},
+		{{Pattern: "("}},
+		{{Method: core.MaskRedact}},
+	} {
+		_, err := core.NewConnection(&core.ConnectionParams{Masking: rules}, mock.NewAdapter(nil))
+		r.Error(err)
+	}
+
+	_, err := core.NewConnection(&core.ConnectionParams{
+		Masking: []core.MaskRule{{Column: "email", Method: "scramble"}},
+	}, mock.NewAdapter(nil))
+	r.ErrorIs(err, core.ErrUnknownMaskMethod)
+}
+
+func TestConnectionParams_MaskingJSON(t *testing.T) {
+	r := require.New(t)
+
+	params := &core.ConnectionParams{
+		Name: "db",
+		Masking: []core.MaskRule{
+			{Column: "email", Method: core.MaskPartial, Keep: 3},
+			{Pattern: "token|secret"},
+		},
+	}
+
+	b, err := json.Marshal(params)
+	r.NoError(err)
+	r.Contains(string(b), `"masking":[{"column":"email","method":"partial","keep":3},{"pattern":"token|secret"}]`)
+
+	var decoded core.ConnectionParams
+	r.NoError(json.Unmarshal(b, &decoded))
+	r.Equal(params.Masking, decoded.Masking)
+}
@@ -0,0 +1,33 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrRefreshNotSupported = errors.New("refreshing materialized views not supported")
+
+// MaterializedViewRefresher is an optional interface for drivers that list
+// materialized views in the layout and can refresh them.
+type MaterializedViewRefresher interface {
+	RefreshMaterializedView(ctx context.Context, opts *TableOptions) error
+}
+
+// RefreshMaterializedView refreshes the data of a materialized view.
+func (c *Connection) RefreshMaterializedView(opts *TableOptions) error {
+	refresher, ok := c.driver.(MaterializedViewRefresher)
+	if !ok {
+		return ErrRefreshNotSupported
+	}
+	if opts == nil || opts.Table == "" {
+		return errors.New("no materialized view provided")
+	}
+
+	err := refresher.RefreshMaterializedView(context.Background(), opts)
+	if err != nil {
+		return fmt.Errorf("refresher.RefreshMaterializedView: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestStructureType_MaterializedView(t *testing.T) {
+	r := require.New(t)
+
+	typ := core.StructureTypeMaterializedView
+	r.Equal("materialized_view", typ.String())
+	r.Equal(typ, core.StructureTypeFromString(typ.String()))
+}
+
+func TestConnection_RefreshMaterializedView(t *testing.T) {
+	r := require.New(t)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(mock.NewRows(0, 1)))
+	r.NoError(err)
+
+	err = connection.RefreshMaterializedView(&core.TableOptions{Table: "daily_totals"})
+	r.ErrorIs(err, core.ErrRefreshNotSupported)
+}
@@ -0,0 +1,511 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrNoMigrations      = errors.New("no migrations found")
+	ErrUnknownMigration  = errors.New("unknown migration")
+	ErrMigrationNotReady = errors.New("migration can't be run")
+)
+
+// MigrationTool is the tool whose layout of migration files and tracking
+// table is used.
+type MigrationTool string
+
+const (
+	// MigrationToolGoose migrations are single files with "-- +goose Up" and
+	// "-- +goose Down" sections, applied versions are in goose_db_version.
+	MigrationToolGoose MigrationTool = "goose"
+	// MigrationToolMigrate (golang-migrate) migrations are pairs of
+	// "<version>_<name>.up.sql" and ".down.sql" files, the current version is
+	// in schema_migrations.
+	MigrationToolMigrate MigrationTool = "golang-migrate"
+	// MigrationToolDbmate migrations are single files with "-- migrate:up" and
+	// "-- migrate:down" sections, applied versions are in schema_migrations.
+	MigrationToolDbmate MigrationTool = "dbmate"
+)
+
+// migrationDirs are directories (relative to the project root) searched for
+// migrations.
+var migrationDirs = []string{"", "migrations", "db/migrations", "db/migrate", "sql/migrations", "database/migrations"}
+
+var (
+	migrationFileRe = regexp.MustCompile(`^(\d+)_(.+?)(\.(up|down))?\.sql$`)
+
+	gooseUpRe             = regexp.MustCompile(`(?im)^--\s*\+goose\s+up\b.*$`)
+	gooseDownRe           = regexp.MustCompile(`(?im)^--\s*\+goose\s+down\b.*$`)
+	gooseNoTransactionRe  = regexp.MustCompile(`(?im)^--\s*\+goose\s+no\s+transaction\b`)
+	gooseStatementBeginRe = regexp.MustCompile(`(?i)^--\s*\+goose\s+statementbegin\b`)
+	gooseStatementEndRe   = regexp.MustCompile(`(?i)^--\s*\+goose\s+statementend\b`)
+	dbmateUpRe            = regexp.MustCompile(`(?m)^--\s*migrate:up\b.*$`)
+	dbmateDownRe          = regexp.MustCompile(`(?m)^--\s*migrate:down\b.*$`)
+	dbmateNoTransactionRe = regexp.MustCompile(`(?m)^--\s*migrate:(up|down)\b.*\btransaction:false\b`)
+)
+
+type (
+	// Migration is a migration file (or a pair of up and down files).
+	Migration struct {
+		Version string
+		Name    string
+		// files of the migration
+		Files []string
+		Up    string
+		Down  string
+		// migrations which can't run in a transaction (e.g. CREATE INDEX
+		// CONCURRENTLY)
+		NoTransaction bool
+		Applied       bool
+	}
+
+	// MigrationStatus lists migrations of a directory with their status on a
+	// connection. Migrations are pending if the tracking table can't be read
+	// (e.g. it doesn't exist yet).
+	MigrationStatus struct {
+		Tool       MigrationTool
+		Dir        string
+		Migrations []*Migration
+		// golang-migrate marks the database dirty if a migration failed
+		Dirty bool
+	}
+)
+
+// DiscoverMigrations finds migrations in the root directory or in one of the
+// common migration directories of a project in it (e.g. "db/migrations").
+func DiscoverMigrations(root string) (MigrationTool, string, []*Migration, error) {
+	for _, dir := range migrationDirs {
+		dir = filepath.Join(root, dir)
+		tool, migrations, err := readMigrations(dir)
+		if err != nil {
+			return "", "", nil, err
+		}
+		if len(migrations) > 0 {
+			return tool, dir, migrations, nil
+		}
+	}
+
+	return "", "", nil, fmt.Errorf("%w in %s", ErrNoMigrations, root)
+}
+
+// readMigrations reads migrations of the directory and detects their tool.
+// Directories without migrations return no error.
+func readMigrations(dir string) (MigrationTool, []*Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) || !isDir(dir) {
+			return "", nil, nil
+		}
+		return "", nil, err
+	}
+
+	byVersion := make(map[string]*Migration)
+	var tool MigrationTool
+	for _, entry := range entries {
+		match := migrationFileRe.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", nil, err
+		}
+		text := string(content)
+
+		version, name, direction := match[1], match[2], match[4]
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		m.Files = append(m.Files, path)
+
+		switch {
+		case direction != "":
+			tool = MigrationToolMigrate
+			if direction == "up" {
+				m.Up = text
+			} else {
+				m.Down = text
+			}
+		case gooseUpRe.MatchString(text):
+			if tool == "" {
+				tool = MigrationToolGoose
+			}
+			m.Up, m.Down = splitMigration(text, gooseUpRe, gooseDownRe)
+			m.NoTransaction = gooseNoTransactionRe.MatchString(text)
+		case dbmateUpRe.MatchString(text):
+			if tool == "" {
+				tool = MigrationToolDbmate
+			}
+			m.Up, m.Down = splitMigration(text, dbmateUpRe, dbmateDownRe)
+			m.NoTransaction = dbmateNoTransactionRe.MatchString(text)
+		default:
+			// not a migration of a known tool
+			delete(byVersion, version)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return compareVersions(migrations[i].Version, migrations[j].Version) < 0
+	})
+
+	return tool, migrations, nil
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// splitMigration returns up and down sections of a migration file.
+func splitMigration(text string, upRe, downRe *regexp.Regexp) (up, down string) {
+	upLoc := upRe.FindStringIndex(text)
+	downLoc := downRe.FindStringIndex(text)
+
+	switch {
+	case downLoc == nil:
+		return strings.TrimSpace(text[upLoc[1]:]), ""
+	case downLoc[0] > upLoc[0]:
+		return strings.TrimSpace(text[upLoc[1]:downLoc[0]]), strings.TrimSpace(text[downLoc[1]:])
+	default:
+		return strings.TrimSpace(text[upLoc[1]:]), strings.TrimSpace(text[downLoc[1]:upLoc[0]])
+	}
+}
+
+// normalizeVersion strips leading zeros, so versions of files and tracking
+// tables can be compared.
+func normalizeVersion(version string) string {
+	version = strings.TrimLeft(strings.TrimSpace(version), "0")
+	if version == "" {
+		return "0"
+	}
+	return version
+}
+
+// compareVersions compares numeric versions of any length.
+func compareVersions(a, b string) int {
+	a, b = normalizeVersion(a), normalizeVersion(b)
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// splitMigrationStatements splits a section of a migration into statements.
+// Statements between goose's StatementBegin and StatementEnd annotations are
+// kept whole.
+func splitMigrationStatements(text string, dialect *StatementDialect) []string {
+	var statements []string
+	var chunk, block strings.Builder
+	inBlock := false
+
+	flush := func() {
+		for _, stmt := range SplitStatements(chunk.String(), dialect) {
+			statements = append(statements, stmt.Text)
+		}
+		chunk.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case gooseStatementBeginRe.MatchString(trimmed):
+			flush()
+			inBlock = true
+		case gooseStatementEndRe.MatchString(trimmed):
+			if stmt := strings.TrimSpace(block.String()); stmt != "" {
+				statements = append(statements, stmt)
+			}
+			block.Reset()
+			inBlock = false
+		case inBlock:
+			block.WriteString(line)
+		default:
+			chunk.WriteString(line)
+		}
+	}
+	flush()
+
+	return statements
+}
+
+// migrationQueries are statements which read and update the tracking table of
+// a tool. They use standard sql.
+type migrationQueries struct {
+	create  string
+	applied string
+	// statements which record the applied (or rolled back) migration, the
+	// previous applied version is "" if there is none
+	apply    func(version string) []string
+	rollback func(version, previous string) []string
+}
+
+func migrationQueriesOf(tool MigrationTool) *migrationQueries {
+	switch tool {
+	case MigrationToolGoose:
+		return &migrationQueries{
+			create:  "CREATE TABLE IF NOT EXISTS goose_db_version (version_id BIGINT NOT NULL, is_applied BOOLEAN NOT NULL, tstamp TIMESTAMP DEFAULT CURRENT_TIMESTAMP)",
+			applied: "SELECT version_id, is_applied FROM goose_db_version",
+			apply: func(version string) []string {
+				return []string{fmt.Sprintf("INSERT INTO goose_db_version (version_id, is_applied) VALUES (%s, TRUE)", version)}
+			},
+			rollback: func(version, _ string) []string {
+				return []string{fmt.Sprintf("DELETE FROM goose_db_version WHERE version_id = %s", version)}
+			},
+		}
+	case MigrationToolMigrate:
+		return &migrationQueries{
+			create:  "CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)",
+			applied: "SELECT version, dirty FROM schema_migrations",
+			apply: func(version string) []string {
+				return []string{
+					"DELETE FROM schema_migrations",
+					fmt.Sprintf("INSERT INTO schema_migrations (version, dirty) VALUES (%s, FALSE)", version),
+				}
+			},
+			rollback: func(_, previous string) []string {
+				statements := []string{"DELETE FROM schema_migrations"}
+				if previous != "" {
+					statements = append(statements, fmt.Sprintf("INSERT INTO schema_migrations (version, dirty) VALUES (%s, FALSE)", previous))
+				}
+				return statements
+			},
+		}
+	default:
+		return &migrationQueries{
+			create:  "CREATE TABLE IF NOT EXISTS schema_migrations (version VARCHAR(128) NOT NULL PRIMARY KEY)",
+			applied: "SELECT version FROM schema_migrations",
+			apply: func(version string) []string {
+				return []string{fmt.Sprintf("INSERT INTO schema_migrations (version) VALUES ('%s')", version)}
+			},
+			rollback: func(version, _ string) []string {
+				return []string{fmt.Sprintf("DELETE FROM schema_migrations WHERE version = '%s'", version)}
+			},
+		}
+	}
+}
+
+// isTrue reports whether a boolean value of a database is true.
+func isTrue(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "t" || v == "1" || strings.EqualFold(v, "true")
+	case []byte:
+		return isTrue(string(v))
+	case nil:
+		return false
+	default:
+		return fmt.Sprint(v) != "0"
+	}
+}
+
+// queryAll runs the query and returns all of its rows.
+func queryAll(ctx context.Context, query func(context.Context, string) (ResultStream, error), stmt string) ([]Row, error) {
+	rows, err := query(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Row
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
+
+// GetMigrations returns migrations with their status on the connection. They
+// are read from the migrations directory of the connection or discovered in
+// the project root.
+func (c *Connection) GetMigrations(root string) (*MigrationStatus, error) {
+	var tool MigrationTool
+	var dir string
+	var migrations []*Migration
+	var err error
+	if c.params.MigrationsDir != "" {
+		dir = c.params.MigrationsDir
+		tool, migrations, err = readMigrations(dir)
+		if err == nil && len(migrations) < 1 {
+			err = fmt.Errorf("%w in %s", ErrNoMigrations, dir)
+		}
+	} else {
+		tool, dir, migrations, err = DiscoverMigrations(root)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	status := &MigrationStatus{Tool: tool, Dir: dir, Migrations: migrations}
+
+	rows, err := queryAll(context.Background(), c.driver.Query, migrationQueriesOf(tool).applied)
+	if err != nil {
+		// the tracking table doesn't exist yet
+		return status, nil
+	}
+
+	applied := make(map[string]bool)
+	switch tool {
+	case MigrationToolMigrate:
+		// the current version and all before it are applied
+		if len(rows) > 0 && len(rows[0]) > 1 {
+			current := fmt.Sprint(rows[0][0])
+			status.Dirty = isTrue(rows[0][1])
+			for _, m := range migrations {
+				applied[normalizeVersion(m.Version)] = compareVersions(m.Version, current) <= 0
+			}
+		}
+	default:
+		for _, row := range rows {
+			if len(row) < 1 || (len(row) > 1 && !isTrue(row[1])) {
+				continue
+			}
+			applied[normalizeVersion(fmt.Sprint(row[0]))] = true
+		}
+	}
+	for _, m := range migrations {
+		m.Applied = applied[normalizeVersion(m.Version)]
+	}
+
+	return status, nil
+}
+
+// ApplyMigration runs the up section of a pending migration and records it
+// in the tracking table, which is created if it doesn't exist. golang-migrate
+// migrations are applied in order, so only the first pending one can be
+// applied.
+func (c *Connection) ApplyMigration(root, version string) error {
+	status, m, err := c.migration(root, version)
+	if err != nil {
+		return err
+	}
+	if m.Applied {
+		return fmt.Errorf("%w: %s is already applied", ErrMigrationNotReady, m.Version)
+	}
+	if status.Dirty {
+		return fmt.Errorf("%w: database is dirty, fix it and the version in schema_migrations first", ErrMigrationNotReady)
+	}
+	if status.Tool == MigrationToolMigrate {
+		for _, other := range status.Migrations {
+			if !other.Applied && other != m {
+				return fmt.Errorf("%w: %s has to be applied first", ErrMigrationNotReady, other.Version)
+			}
+			if other == m {
+				break
+			}
+		}
+	}
+
+	queries := migrationQueriesOf(status.Tool)
+	version = m.Version
+	if status.Tool != MigrationToolDbmate {
+		version = normalizeVersion(version)
+	}
+	return c.runMigration(m.Up, queries.create, queries.apply(version), m.NoTransaction)
+}
+
+// RollbackMigration runs the down section of the latest applied migration
+// and removes it from the tracking table.
+func (c *Connection) RollbackMigration(root, version string) error {
+	status, m, err := c.migration(root, version)
+	if err != nil {
+		return err
+	}
+	if !m.Applied {
+		return fmt.Errorf("%w: %s is not applied", ErrMigrationNotReady, m.Version)
+	}
+
+	var previous string
+	for _, other := range status.Migrations {
+		if other == m {
+			continue
+		}
+		if other.Applied && compareVersions(other.Version, m.Version) > 0 {
+			return fmt.Errorf("%w: %s is applied after it, roll it back first", ErrMigrationNotReady, other.Version)
+		}
+		if other.Applied {
+			previous = normalizeVersion(other.Version)
+		}
+	}
+
+	queries := migrationQueriesOf(status.Tool)
+	version = m.Version
+	if status.Tool != MigrationToolDbmate {
+		version = normalizeVersion(version)
+	}
+	return c.runMigration(m.Down, queries.create, queries.rollback(version, previous), m.NoTransaction)
+}
+
+func (c *Connection) migration(root, version string) (*MigrationStatus, *Migration, error) {
+	status, err := c.GetMigrations(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, m := range status.Migrations {
+		if compareVersions(m.Version, version) == 0 {
+			return status, m, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: %q", ErrUnknownMigration, version)
+}
+
+// runMigration runs statements of a migration followed by statements which
+// update the tracking table. They run in a transaction if the driver
+// supports it, unless the migration can't run in one.
+func (c *Connection) runMigration(text, create string, tracking []string, noTransaction bool) error {
+	if c.InTransaction() {
+		return ErrTransactionInProgress
+	}
+
+	ctx := context.Background()
+	statements := append([]string{create}, splitMigrationStatements(text, c.statementDialect())...)
+	statements = append(statements, tracking...)
+	defer c.structure.invalidate(nil)
+
+	transactor, ok := c.driver.(Transactor)
+	if !ok || noTransaction {
+		for _, stmt := range statements {
+			if _, err := queryAll(ctx, c.driver.Query, stmt); err != nil {
+				return classifyError(c.driver, err, stmt)
+			}
+		}
+		return nil
+	}
+
+	tx, err := transactor.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("transactor.BeginTx: %w", err)
+	}
+	for _, stmt := range statements {
+		if _, err := queryAll(ctx, tx.Query, stmt); err != nil {
+			_ = tx.Rollback()
+			return classifyError(c.driver, err, stmt)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package core_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func writeMigrations(t *testing.T, dir string, files map[string]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	r := require.New(t)
+	r.NoError(os.MkdirAll(filepath.Join(root, dir), 0o755))
+	for name, content := range files {
+		r.NoError(os.WriteFile(filepath.Join(root, dir, name), []byte(content), 0o644))
+	}
+	return root
+}
+
+func TestDiscoverMigrations(t *testing.T) {
+	type testCase struct {
+		name              string
+		dir               string
+		files             map[string]string
+		expectedTool      core.MigrationTool
+		expectedVersions  []string
+		expectedUp        string
+		expectedDown      string
+		expectedNoTxFirst bool
+	}
+
+	testCases := []testCase{
+		{
+			name: "goose",
+			dir:  "migrations",
+			files: map[string]string{
+				"00002_add_email.sql":    "-- +goose Up\nALTER TABLE users ADD email TEXT;\n-- +goose Down\nALTER TABLE users DROP email;\n",
+				"00001_create_users.sql": "-- +goose NO TRANSACTION\n-- +goose Up\nCREATE TABLE users (id INT);\n\n-- +goose Down\nDROP TABLE users;\n",
+				"README.md":              "not a migration",
+			},
+			expectedTool:      core.MigrationToolGoose,
+			expectedVersions:  []string{"00001", "00002"},
+			expectedUp:        "CREATE TABLE users (id INT);",
+			expectedDown:      "DROP TABLE users;",
+			expectedNoTxFirst: true,
+		},
+		{
+			name: "golang-migrate",
+			dir:  "db/migrations",
+			files: map[string]string{
+				"10_create_users.up.sql":   "CREATE TABLE users (id INT);",
+				"10_create_users.down.sql": "DROP TABLE users;",
+				"9_init.up.sql":            "SELECT 1;",
+			},
+			expectedTool:     core.MigrationToolMigrate,
+			expectedVersions: []string{"9", "10"},
+			expectedUp:       "SELECT 1;",
+		},
+		{
+			name: "dbmate",
+			dir:  "db/migrations",
+			files: map[string]string{
+				"20240101120000_create_users.sql": "-- migrate:up transaction:false\nCREATE TABLE users (id INT);\n\n-- migrate:down\nDROP TABLE users;\n",
+			},
+			expectedTool:      core.MigrationToolDbmate,
+			expectedVersions:  []string{"20240101120000"},
+			expectedUp:        "CREATE TABLE users (id INT);",
+			expectedDown:      "DROP TABLE users;",
+			expectedNoTxFirst: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			root := writeMigrations(t, tc.dir, tc.files)
+			tool, dir, migrations, err := core.DiscoverMigrations(root)
+			r.NoError(err)
+			r.Equal(tc.expectedTool, tool)
+			r.Equal(filepath.Join(root, tc.dir), dir)
+
+			var versions []string
+			for _, m := range migrations {
+				versions = append(versions, m.Version)
+			}
+			r.Equal(tc.expectedVersions, versions)
+			r.Equal(tc.expectedUp, migrations[0].Up)
+			r.Equal(tc.expectedDown, migrations[0].Down)
+			r.Equal(tc.expectedNoTxFirst, migrations[0].NoTransaction)
+		})
+	}
+
+	_, _, _, err := core.DiscoverMigrations(t.TempDir())
+	require.ErrorIs(t, err, core.ErrNoMigrations)
+}
+
+func TestConnection_GetMigrations(t *testing.T) {
+	r := require.New(t)
+
+	root := writeMigrations(t, "migrations", map[string]string{
+		"1_a.up.sql": "SELECT 1;",
+		"2_b.up.sql": "SELECT 2;",
+		"3_c.up.sql": "SELECT 3;",
+	})
+
+	// schema_migrations of golang-migrate stores the current version
+	adapter := mock.NewAdapter([]core.Row{{int64(2), true}})
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	status, err := connection.GetMigrations(root)
+	r.NoError(err)
+	r.Equal(core.MigrationToolMigrate, status.Tool)
+	r.True(status.Dirty)
+
+	var applied []bool
+	for _, m := range status.Migrations {
+		applied = append(applied, m.Applied)
+	}
+	r.Equal([]bool{true, true, false}, applied)
+
+	// dirty databases need fixing first
+	r.ErrorIs(connection.ApplyMigration(root, "3"), core.ErrMigrationNotReady)
+}
+
+func TestConnection_ApplyMigration(t *testing.T) {
+	r := require.New(t)
+
+	root := writeMigrations(t, "migrations", map[string]string{
+		"001_create.sql": "-- +goose Up\nCREATE TABLE a (id INT);\n-- +goose StatementBegin\nCREATE FUNCTION f() RETURNS INT AS $$ SELECT 1; $$;\n-- +goose StatementEnd\n-- +goose Down\nDROP TABLE a;\n",
+		"002_insert.sql": "-- +goose Up\nINSERT INTO a VALUES (1);\n-- +goose Down\nDELETE FROM a;\n",
+	})
+
+	// goose_db_version rows of applied migrations
+	adapter := mock.NewAdapter([]core.Row{{int64(1), true}})
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	r.ErrorIs(connection.ApplyMigration(root, "1"), core.ErrMigrationNotReady)
+	r.ErrorIs(connection.ApplyMigration(root, "4"), core.ErrUnknownMigration)
+	r.NoError(connection.ApplyMigration(root, "2"))
+
+	r.Equal([]string{
+		"begin",
+		"query CREATE TABLE IF NOT EXISTS goose_db_version (version_id BIGINT NOT NULL, is_applied BOOLEAN NOT NULL, tstamp TIMESTAMP DEFAULT CURRENT_TIMESTAMP)",
+		"query INSERT INTO a VALUES (1)",
+		"query INSERT INTO goose_db_version (version_id, is_applied) VALUES (2, TRUE)",
+		"commit",
+	}, adapter.TransactionOps())
+}
+
+func TestConnection_RollbackMigration(t *testing.T) {
+	r := require.New(t)
+
+	root := writeMigrations(t, "", map[string]string{
+		"001_create.sql": "-- +goose Up\nCREATE TABLE a (id INT);\n-- +goose Down\n-- +goose StatementBegin\nDROP TABLE a; DROP TABLE b;\n-- +goose StatementEnd\n",
+		"002_insert.sql": "-- +goose Up\nINSERT INTO a VALUES (1);\n-- +goose Down\nDELETE FROM a;\n",
+	})
+
+	adapter := mock.NewAdapter([]core.Row{{int64(1), true}, {int64(2), true}})
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	// only the latest applied migration can be rolled back
+	r.ErrorIs(connection.RollbackMigration(root, "1"), core.ErrMigrationNotReady)
+
+	adapter = mock.NewAdapter([]core.Row{{int64(1), true}, {int64(2), false}})
+	connection, err = core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	r.NoError(connection.RollbackMigration(root, "1"))
+	r.Equal([]string{
+		"begin",
+		"query CREATE TABLE IF NOT EXISTS goose_db_version (version_id BIGINT NOT NULL, is_applied BOOLEAN NOT NULL, tstamp TIMESTAMP DEFAULT CURRENT_TIMESTAMP)",
+		"query DROP TABLE a; DROP TABLE b;",
+		"query DELETE FROM goose_db_version WHERE version_id = 1",
+		"commit",
+	}, adapter.TransactionOps())
+}
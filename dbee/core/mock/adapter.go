@@ -7,7 +7,25 @@ import (
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 )
 
-var _ core.Driver = (*driver)(nil)
+var (
+	_ core.Driver                    = (*driver)(nil)
+	_ core.TransientErrorClassifier  = (*driver)(nil)
+	_ core.ConnectionErrorClassifier = (*driver)(nil)
+	_ core.ErrorClassifier           = (*driver)(nil)
+	_ core.Transactor                = (*driver)(nil)
+	_ core.Importer                  = (*driver)(nil)
+	_ core.RoutineLister             = (*driver)(nil)
+	_ core.DefinitionProvider        = (*driver)(nil)
+	_ core.DatabaseSwitcher          = (*driver)(nil)
+	_ core.SystemObjectClassifier    = (*driver)(nil)
+	_ core.DependencyLister          = (*driver)(nil)
+	_ core.Commenter                 = (*driver)(nil)
+	_ core.TableInspector            = (*driver)(nil)
+	_ core.ScanEstimator             = (*driver)(nil)
+	_ core.PartitionLister           = (*driver)(nil)
+	_ core.PartitionManager          = (*driver)(nil)
+	_ core.ProcedureCaller           = (*driver)(nil)
+)
 
 type driver struct {
 	data   []core.Row
@@ -27,12 +45,15 @@ func (d *driver) Query(ctx context.Context, query string) (core.ResultStream, er
 }
 
 func (d *driver) Structure() ([]*core.Structure, error) {
+	d.config.structureLoads++
+
 	var structure []*core.Structure
 
 	for table := range d.config.tableColumns {
 		structure = append(structure, &core.Structure{
-			Name: table,
-			Type: core.StructureTypeTable,
+			Name:    table,
+			Type:    core.StructureTypeTable,
+			Comment: d.config.comments[table],
 		})
 	}
 
@@ -45,11 +66,200 @@ func (d *driver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
 		return nil, fmt.Errorf("unknown table: %s", opts.Table)
 	}
 
-	return columns, nil
+	out := make([]*core.Column, len(columns))
+	for i, column := range columns {
+		col := *column
+		if comment, ok := d.config.comments[opts.Table+"."+column.Name]; ok {
+			col.Comment = comment
+		}
+		out[i] = &col
+	}
+	return out, nil
+}
+
+func (d *driver) Indexes(_ context.Context, opts *core.TableOptions) ([]*core.Index, error) {
+	return d.config.indexes[opts.Table], nil
+}
+
+func (d *driver) Constraints(_ context.Context, _ *core.TableOptions) ([]*core.Constraint, error) {
+	return nil, nil
+}
+
+// SetComment stores comments of tables and their columns.
+func (d *driver) SetComment(_ context.Context, opts *core.TableOptions, column string, comment string) error {
+	if _, ok := d.config.tableColumns[opts.Table]; !ok {
+		return fmt.Errorf("unknown table: %s", opts.Table)
+	}
+
+	key := opts.Table
+	if column != "" {
+		key += "." + column
+	}
+	d.config.comments[key] = comment
+	return nil
+}
+
+func (d *driver) Routines() ([]*core.Structure, error) {
+	return d.config.routines, nil
+}
+
+func (d *driver) Definition(_ context.Context, opts *core.TableOptions) (string, error) {
+	return d.config.definitions[opts.Schema+"."+opts.Table], nil
+}
+
+func (d *driver) ListDatabases() (string, []string, error) {
+	if d.config.currentDatabase == "" {
+		return "", nil, core.ErrDatabaseSwitchingNotSupported
+	}
+
+	var available []string
+	for _, db := range d.config.databases {
+		if db != d.config.currentDatabase {
+			available = append(available, db)
+		}
+	}
+	return d.config.currentDatabase, available, nil
+}
+
+func (d *driver) SelectDatabase(name string) error {
+	for _, db := range d.config.databases {
+		if db == name {
+			d.config.currentDatabase = name
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown database: %s", name)
+}
+
+func (d *driver) IsSystem(node *core.Structure) bool {
+	for _, schema := range d.config.systemSchemas {
+		if node.Schema == schema {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *driver) DependsOn(_ context.Context, opts *core.TableOptions) ([]*core.DependencyNode, error) {
+	return d.dependencies(opts, 0, 1), nil
+}
+
+func (d *driver) Dependents(_ context.Context, opts *core.TableOptions) ([]*core.DependencyNode, error) {
+	return d.dependencies(opts, 1, 0), nil
+}
+
+// dependencies returns objects on the "to" side of registered dependencies
+// whose "from" side is the object described by opts.
+func (d *driver) dependencies(opts *core.TableOptions, from, to int) []*core.DependencyNode {
+	var nodes []*core.DependencyNode
+	for _, dep := range d.config.dependencies {
+		if dep[from].Schema != opts.Schema || dep[from].Name != opts.Table {
+			continue
+		}
+		nodes = append(nodes, &core.DependencyNode{
+			Schema: dep[to].Schema,
+			Name:   dep[to].Name,
+			Type:   dep[to].Type,
+		})
+	}
+	return nodes
+}
+
+func (d *driver) Partitions(_ context.Context, opts *core.TableOptions) ([]*core.Partition, error) {
+	return d.config.partitions[opts.Table], nil
+}
+
+func (d *driver) DetachPartition(_ context.Context, _ *core.TableOptions, partition *core.Partition) error {
+	d.config.partitionOps = append(d.config.partitionOps, "detach "+partition.Schema+"."+partition.Name)
+	return nil
+}
+
+func (d *driver) DropPartition(_ context.Context, _ *core.TableOptions, partition *core.Partition) error {
+	d.config.partitionOps = append(d.config.partitionOps, "drop "+partition.Schema+"."+partition.Name)
+	return nil
+}
+
+// CallProcedure returns the adapter's data, the same as Query.
+func (d *driver) CallProcedure(_ context.Context, _ string, _ []*core.ProcedureParam) (core.ResultStream, error) {
+	return NewResultStream(d.data, d.config.resultStreamOptions...), nil
+}
+
+func (d *driver) IsTransient(err error) bool {
+	if d.config.isTransient == nil {
+		return false
+	}
+	return d.config.isTransient(err)
+}
+
+func (d *driver) IsConnectionError(err error) bool {
+	if d.config.isConnectionError == nil {
+		return false
+	}
+	return d.config.isConnectionError(err)
+}
+
+func (d *driver) ClassifyError(err error) *core.QueryError {
+	if d.config.classifyError == nil {
+		return nil
+	}
+	return d.config.classifyError(err)
+}
+
+// ImportBatch records imported rows. If any row is rejected by the import
+// validator, none of the rows are recorded.
+func (d *driver) ImportBatch(_ context.Context, _ string, _ []string, rows []core.Row) error {
+	if d.config.importValidator != nil {
+		for _, row := range rows {
+			if err := d.config.importValidator(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	d.config.importedRows = append(d.config.importedRows, rows...)
+	return nil
+}
+
+func (d *driver) EstimateScan(_ context.Context, _ string) (int64, error) {
+	return d.config.scanEstimate, nil
 }
 
 func (d *driver) Close() {}
 
+var _ core.StructureLoader = (*lazyDriver)(nil)
+
+// lazyDriver is a driver which loads the structure incrementally.
+type lazyDriver struct {
+	*driver
+}
+
+func (d *lazyDriver) StructureRoots(_ context.Context) ([]*core.Structure, error) {
+	d.config.structureLoads++
+
+	roots := make([]*core.Structure, len(d.config.lazyStructure))
+	for i, s := range d.config.lazyStructure {
+		roots[i] = &core.Structure{
+			Name:   s.Name,
+			Schema: s.Schema,
+			Type:   s.Type,
+		}
+	}
+
+	return roots, nil
+}
+
+func (d *lazyDriver) StructureChildren(_ context.Context, parent *core.Structure) ([]*core.Structure, error) {
+	d.config.structureLoads++
+
+	for _, s := range d.config.lazyStructure {
+		if s.Name == parent.Name && s.Schema == parent.Schema {
+			return s.Children, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown structure node: %s", parent.Name)
+}
+
 var _ core.Adapter = (*Adapter)(nil)
 
 type Adapter struct {
@@ -62,6 +272,11 @@ func NewAdapter(data []core.Row, opts ...AdapterOption) *Adapter {
 		querySideEffects: make(map[string]func(context.Context) error),
 		tableHelpers:     make(map[string]string),
 		tableColumns:     make(map[string][]*core.Column),
+		definitions:      make(map[string]string),
+		comments:         make(map[string]string),
+		indexes:          make(map[string][]*core.Index),
+		affectedRows:     make(map[string]int64),
+		partitions:       make(map[string][]*core.Partition),
 
 		resultStreamOptions: []ResultStreamOption{},
 	}
@@ -76,12 +291,98 @@ func NewAdapter(data []core.Row, opts ...AdapterOption) *Adapter {
 }
 
 func (a *Adapter) Connect(_ string) (core.Driver, error) {
-	return &driver{
+	d := &driver{
 		data:   a.data,
 		config: a.config,
-	}, nil
+	}
+	if a.config.lazyStructure != nil {
+		return &lazyDriver{driver: d}, nil
+	}
+
+	return d, nil
+}
+
+// TransactionOps returns operations executed in transactions of all drivers
+// created by the adapter (e.g. "begin", "query <query>", "savepoint <name>", "commit").
+func (a *Adapter) TransactionOps() []string {
+	return a.config.transactionOps
+}
+
+// PartitionOps returns detached and dropped partitions of all drivers created
+// by the adapter (e.g. "drop <schema>.<name>").
+func (a *Adapter) PartitionOps() []string {
+	return a.config.partitionOps
+}
+
+// StructureLoads returns the number of times structure (or a part of it) was
+// loaded by all drivers created by the adapter.
+func (a *Adapter) StructureLoads() int {
+	return a.config.structureLoads
+}
+
+// ImportedRows returns rows imported by all drivers created by the adapter.
+func (a *Adapter) ImportedRows() []core.Row {
+	return a.config.importedRows
 }
 
 func (a *Adapter) GetHelpers(opts *core.TableOptions) map[string]string {
 	return a.config.tableHelpers
 }
+
+var (
+	_ core.Transaction       = (*transaction)(nil)
+	_ core.TransactionExecer = (*transaction)(nil)
+)
+
+// transaction is a mocked transaction which records executed operations.
+type transaction struct {
+	driver *driver
+	ops    *[]string
+}
+
+func (d *driver) BeginTx(ctx context.Context) (core.Transaction, error) {
+	d.config.transactionOps = append(d.config.transactionOps, "begin")
+	return &transaction{
+		driver: d,
+		ops:    &d.config.transactionOps,
+	}, nil
+}
+
+func (t *transaction) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	*t.ops = append(*t.ops, "query "+query)
+	return t.driver.Query(ctx, query)
+}
+
+// Exec affects a single row, unless configured otherwise.
+func (t *transaction) Exec(ctx context.Context, query string) (int64, error) {
+	*t.ops = append(*t.ops, "exec "+query)
+	if eff, ok := t.driver.config.querySideEffects[query]; ok {
+		if err := eff(ctx); err != nil {
+			return 0, fmt.Errorf("side effect error: %w", err)
+		}
+	}
+	if affected, ok := t.driver.config.affectedRows[query]; ok {
+		return affected, nil
+	}
+	return 1, nil
+}
+
+func (t *transaction) Savepoint(_ context.Context, name string) error {
+	*t.ops = append(*t.ops, "savepoint "+name)
+	return nil
+}
+
+func (t *transaction) RollbackTo(_ context.Context, name string) error {
+	*t.ops = append(*t.ops, "rollback to "+name)
+	return nil
+}
+
+func (t *transaction) Commit() error {
+	*t.ops = append(*t.ops, "commit")
+	return nil
+}
+
+func (t *transaction) Rollback() error {
+	*t.ops = append(*t.ops, "rollback")
+	return nil
+}
@@ -7,9 +7,29 @@ import (
 )
 
 type adapterConfig struct {
-	querySideEffects map[string]func(context.Context) error
-	tableHelpers     map[string]string
-	tableColumns     map[string][]*core.Column
+	querySideEffects  map[string]func(context.Context) error
+	tableHelpers      map[string]string
+	tableColumns      map[string][]*core.Column
+	isTransient       func(error) bool
+	isConnectionError func(error) bool
+	classifyError     func(error) *core.QueryError
+	transactionOps    []string
+	importValidator   func(core.Row) error
+	importedRows      []core.Row
+	routines          []*core.Structure
+	definitions       map[string]string
+	lazyStructure     []*core.Structure
+	structureLoads    int
+	currentDatabase   string
+	databases         []string
+	systemSchemas     []string
+	dependencies      [][2]*core.Structure
+	comments          map[string]string
+	indexes           map[string][]*core.Index
+	scanEstimate      int64
+	affectedRows      map[string]int64
+	partitions        map[string][]*core.Partition
+	partitionOps      []string
 
 	resultStreamOptions []ResultStreamOption
 }
@@ -49,6 +69,120 @@ func AdapterWithTableDefinition(table string, columns []*core.Column) AdapterOpt
 	}
 }
 
+// AdapterWithTransientErrors sets a function which classifies query errors as transient.
+func AdapterWithTransientErrors(isTransient func(error) bool) AdapterOption {
+	return func(c *adapterConfig) {
+		c.isTransient = isTransient
+	}
+}
+
+// AdapterWithConnectionErrors sets a function which classifies query errors as
+// errors of dropped connections.
+func AdapterWithConnectionErrors(isConnectionError func(error) bool) AdapterOption {
+	return func(c *adapterConfig) {
+		c.isConnectionError = isConnectionError
+	}
+}
+
+// AdapterWithScanEstimate sets the number of bytes the driver estimates
+// every query scans.
+func AdapterWithScanEstimate(bytes int64) AdapterOption {
+	return func(c *adapterConfig) {
+		c.scanEstimate = bytes
+	}
+}
+
+// AdapterWithErrorClassifier sets a function which extracts details of query errors.
+func AdapterWithErrorClassifier(classify func(error) *core.QueryError) AdapterOption {
+	return func(c *adapterConfig) {
+		c.classifyError = classify
+	}
+}
+
+// AdapterWithRoutine registers a function or procedure and its definition.
+func AdapterWithRoutine(routine *core.Structure, definition string) AdapterOption {
+	return func(c *adapterConfig) {
+		key := routine.Schema + "." + routine.Name
+		_, ok := c.definitions[key]
+		if ok {
+			panic("routine already registered: " + key)
+		}
+
+		c.routines = append(c.routines, routine)
+		c.definitions[key] = definition
+	}
+}
+
+// AdapterWithDefinition registers a definition of an object which is not
+// a routine (e.g. a table).
+func AdapterWithDefinition(schema, name, definition string) AdapterOption {
+	return func(c *adapterConfig) {
+		c.definitions[schema+"."+name] = definition
+	}
+}
+
+// AdapterWithLazyStructure makes drivers load the provided structure incrementally
+// (see core.StructureLoader).
+func AdapterWithLazyStructure(structure []*core.Structure) AdapterOption {
+	return func(c *adapterConfig) {
+		c.lazyStructure = structure
+	}
+}
+
+// AdapterWithDatabases makes drivers switch between the provided databases
+// (see core.DatabaseSwitcher).
+func AdapterWithDatabases(current string, available ...string) AdapterOption {
+	return func(c *adapterConfig) {
+		c.currentDatabase = current
+		c.databases = available
+	}
+}
+
+// AdapterWithSystemSchemas makes drivers report the provided schemas as system
+// schemas (see core.SystemObjectClassifier).
+func AdapterWithSystemSchemas(schemas ...string) AdapterOption {
+	return func(c *adapterConfig) {
+		c.systemSchemas = schemas
+	}
+}
+
+// AdapterWithDependency registers that dependent depends on dependency
+// (see core.DependencyLister).
+func AdapterWithDependency(dependent, dependency *core.Structure) AdapterOption {
+	return func(c *adapterConfig) {
+		c.dependencies = append(c.dependencies, [2]*core.Structure{dependent, dependency})
+	}
+}
+
+// AdapterWithIndexes registers indexes of a table (see core.TableInspector).
+func AdapterWithIndexes(table string, indexes ...*core.Index) AdapterOption {
+	return func(c *adapterConfig) {
+		c.indexes[table] = append(c.indexes[table], indexes...)
+	}
+}
+
+// AdapterWithImportValidator sets a function which rejects imported rows.
+func AdapterWithImportValidator(validate func(core.Row) error) AdapterOption {
+	return func(c *adapterConfig) {
+		c.importValidator = validate
+	}
+}
+
+// AdapterWithPartitions sets partitions of the table.
+func AdapterWithPartitions(table string, partitions ...*core.Partition) AdapterOption {
+	return func(c *adapterConfig) {
+		c.partitions[table] = append(c.partitions[table], partitions...)
+	}
+}
+
+// AdapterWithAffectedRows sets the number of rows affected by the query
+// executed in a transaction (1 by default).
+func AdapterWithAffectedRows(query string, affected int64) AdapterOption {
+	return func(c *adapterConfig) {
+		c.affectedRows[query] = affected
+	}
+}
+
 func AdapterWithResultStreamOpts(opts ...ResultStreamOption) AdapterOption {
 	return func(c *adapterConfig) {
 		c.resultStreamOptions = append(c.resultStreamOptions, opts...)
@@ -29,6 +29,11 @@ func newNext(rows []core.Row) (func() (core.Row, error), func() bool) {
 	return next, hasNext
 }
 
+var (
+	_ core.ResultStream      = (*ResultStream)(nil)
+	_ core.MultiResultStream = (*ResultStream)(nil)
+)
+
 type ResultStream struct {
 	next    func() (core.Row, error)
 	hasNext func() bool
@@ -84,6 +89,19 @@ func (rs *ResultStream) HasNext() bool {
 	return rs.hasNext()
 }
 
+func (rs *ResultStream) NextResultSet() bool {
+	if len(rs.config.extraSets) < 1 {
+		return false
+	}
+
+	rows := rs.config.extraSets[0]
+	rs.config.extraSets = rs.config.extraSets[1:]
+
+	rs.next, rs.hasNext = newNext(rows)
+	rs.config.header = makeDefaultHeader(rows)
+	return true
+}
+
 func (rs *ResultStream) Close() {}
 
 // NewRows returns a slice of rows in form of:
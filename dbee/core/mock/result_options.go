@@ -10,6 +10,7 @@ type resultStreamConfig struct {
 	nextSleep time.Duration
 	meta      *core.Meta
 	header    core.Header
+	extraSets [][]core.Row
 }
 
 type ResultStreamOption func(*resultStreamConfig)
@@ -31,3 +32,11 @@ func ResultStreamWithHeader(header core.Header) ResultStreamOption {
 		c.header = header
 	}
 }
+
+// ResultStreamWithExtraSets adds additional result sets to the stream,
+// which are reachable through NextResultSet.
+func ResultStreamWithExtraSets(sets ...[]core.Row) ResultStreamOption {
+	return func(c *resultStreamConfig) {
+		c.extraSets = append(c.extraSets, sets...)
+	}
+}
@@ -0,0 +1,246 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnknownObjectAction = errors.New("unknown object action")
+	ErrActionNotConfirmed  = errors.New("object action not confirmed")
+)
+
+// DefaultSelectLimit is the number of rows returned by the "select" object action
+// if the connection doesn't configure it (see PreviewOptions).
+const DefaultSelectLimit = 500
+
+// ConfirmationLevel says how an object action has to be confirmed before it runs.
+type ConfirmationLevel int
+
+const (
+	// ConfirmationNone runs the action right away.
+	ConfirmationNone ConfirmationLevel = iota
+	// ConfirmationSimple requires a yes/no confirmation.
+	ConfirmationSimple
+	// ConfirmationTyped requires typing the name of the object.
+	ConfirmationTyped
+)
+
+func (l ConfirmationLevel) String() string {
+	switch l {
+	case ConfirmationSimple:
+		return "simple"
+	case ConfirmationTyped:
+		return "typed"
+	default:
+		return "none"
+	}
+}
+
+type (
+	// ObjectAction is an action which can be run on a database object
+	// (e.g. counting the rows of a table).
+	ObjectAction struct {
+		ID           string
+		Title        string
+		Confirmation ConfirmationLevel
+	}
+
+	// ObjectActionResult is the outcome of an object action. Actions which
+	// run a query return the call, actions which produce text (e.g. definitions)
+	// return the text. Other actions return an empty result.
+	ObjectActionResult struct {
+		Call *Call
+		Text string
+	}
+
+	// ObjectActionQuerier is an optional interface for drivers whose queries
+	// for object actions differ from standard sql (e.g. no TRUNCATE statement).
+	// If ok is false, the default query is used.
+	ObjectActionQuerier interface {
+		ObjectActionQuery(action string, opts *TableOptions) (query string, ok bool)
+	}
+)
+
+// IDs of object actions
+const (
+	ObjectActionSelect     = "select"
+	ObjectActionCount      = "count"
+	ObjectActionDefinition = "definition"
+	ObjectActionRefresh    = "refresh"
+	ObjectActionTruncate   = "truncate"
+	ObjectActionDrop       = "drop"
+)
+
+// GetObjectActions returns actions which can be run on the object described
+// by opts. Actions depend on the kind of the object and capabilities of the driver.
+func (c *Connection) GetObjectActions(opts *TableOptions) ([]*ObjectAction, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("opts cannot be nil")
+	}
+
+	_, hasDefinitions := c.driver.(DefinitionProvider)
+	_, canRefresh := c.driver.(MaterializedViewRefresher)
+
+	var actions []*ObjectAction
+	add := func(id, title string, confirmation ConfirmationLevel) {
+		actions = append(actions, &ObjectAction{ID: id, Title: title, Confirmation: confirmation})
+	}
+
+	switch opts.Materialization {
+	case StructureTypeTable, StructureTypeView, StructureTypeMaterializedView:
+		if c.params.Preview.Latest {
+			add(ObjectActionSelect, fmt.Sprintf("Select latest %d", c.previewLimit()), ConfirmationNone)
+		} else {
+			add(ObjectActionSelect, fmt.Sprintf("Select %d", c.previewLimit()), ConfirmationNone)
+		}
+		add(ObjectActionCount, "Count", ConfirmationNone)
+		if hasDefinitions {
+			add(ObjectActionDefinition, "Definition", ConfirmationNone)
+		}
+		if opts.Materialization == StructureTypeMaterializedView && canRefresh {
+			add(ObjectActionRefresh, "Refresh", ConfirmationSimple)
+		}
+		if opts.Materialization == StructureTypeTable {
+			add(ObjectActionTruncate, "Truncate", ConfirmationTyped)
+			add(ObjectActionDrop, "Drop", ConfirmationTyped)
+		} else {
+			add(ObjectActionDrop, "Drop", ConfirmationSimple)
+		}
+	case StructureTypeFunction, StructureTypeProcedure, StructureTypeSequence, StructureTypeIndex, StructureTypeTrigger:
+		if hasDefinitions {
+			add(ObjectActionDefinition, "Definition", ConfirmationNone)
+		}
+	}
+
+	return actions, nil
+}
+
+// RunObjectAction runs the action with the provided id on the object described
+// by opts. Actions which require confirmation run only if confirmation is
+// non-empty (simple) or equals the object name (typed).
+func (c *Connection) RunObjectAction(opts *TableOptions, id string, confirmation string, onEvent func(CallState, *Call)) (*ObjectActionResult, error) {
+	actions, err := c.GetObjectActions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var action *ObjectAction
+	for _, a := range actions {
+		if a.ID == id {
+			action = a
+			break
+		}
+	}
+	if action == nil {
+		return nil, fmt.Errorf("%w: %q for %s", ErrUnknownObjectAction, id, opts.Materialization)
+	}
+
+	switch action.Confirmation {
+	case ConfirmationSimple:
+		if confirmation == "" {
+			return nil, ErrActionNotConfirmed
+		}
+	case ConfirmationTyped:
+		if confirmation != opts.Table {
+			return nil, fmt.Errorf("%w: type %q to confirm", ErrActionNotConfirmed, opts.Table)
+		}
+	}
+
+	switch action.ID {
+	case ObjectActionDefinition:
+		definition, err := c.GetDefinition(opts)
+		if err != nil {
+			return nil, err
+		}
+		return &ObjectActionResult{Text: definition}, nil
+	case ObjectActionRefresh:
+		return &ObjectActionResult{}, c.RefreshMaterializedView(opts)
+	}
+
+	query := c.objectActionQuery(action.ID, opts)
+	if action.Confirmation == ConfirmationNone {
+		return &ObjectActionResult{Call: c.Execute(query, onEvent)}, nil
+	}
+	// the action was already confirmed, so the guard is skipped
+	return &ObjectActionResult{Call: c.ExecuteConfirmed(query, onEvent)}, nil
+}
+
+// objectActionQuery returns the query of an object action.
+func (c *Connection) objectActionQuery(action string, opts *TableOptions) string {
+	if querier, ok := c.driver.(ObjectActionQuerier); ok {
+		if query, ok := querier.ObjectActionQuery(action, opts); ok {
+			return query
+		}
+	}
+
+	dialect := c.Dialect()
+	name := dialect.QualifiedName(opts.Schema, opts.Table)
+
+	switch action {
+	case ObjectActionSelect:
+		return c.previewQuery(name, opts, dialect)
+	case ObjectActionCount:
+		return "SELECT COUNT(*) FROM " + name
+	case ObjectActionTruncate:
+		return "TRUNCATE TABLE " + name
+	case ObjectActionDrop:
+		switch opts.Materialization {
+		case StructureTypeView:
+			return "DROP VIEW " + name
+		case StructureTypeMaterializedView:
+			return "DROP MATERIALIZED VIEW " + name
+		default:
+			return "DROP TABLE " + name
+		}
+	default:
+		return ""
+	}
+}
+
+// previewLimit returns the number of rows returned by the "select" object action.
+func (c *Connection) previewLimit() int {
+	if c.params.Preview.Limit > 0 {
+		return c.params.Preview.Limit
+	}
+	return DefaultSelectLimit
+}
+
+// previewQuery returns the query of the "select" object action, built from
+// preview options of the connection. Columns of the object are retrieved only
+// if the options select a subset of columns or order by the primary key.
+func (c *Connection) previewQuery(name string, opts *TableOptions, dialect *Dialect) string {
+	preview := c.params.Preview
+
+	selected := "*"
+	var order []string
+	if preview.Latest || len(preview.Columns) > 0 {
+		// without columns the query falls back to all rows in any order
+		columns, _ := c.GetColumns(opts)
+
+		var names []string
+		for _, col := range columns {
+			if preview.Latest && col.PrimaryKey {
+				order = append(order, dialect.QuoteIdentifier(col.Name)+" DESC")
+			}
+			for _, wanted := range preview.Columns {
+				if strings.EqualFold(wanted, col.Name) {
+					names = append(names, dialect.QuoteIdentifier(col.Name))
+					break
+				}
+			}
+		}
+		if len(names) > 0 {
+			selected = strings.Join(names, ", ")
+		}
+	}
+
+	query := "SELECT " + selected + " FROM " + name
+	if len(order) > 0 {
+		query += " ORDER BY " + strings.Join(order, ", ")
+	}
+
+	query, _ = dialect.InjectLimit(query, c.previewLimit())
+	return query
+}
@@ -0,0 +1,103 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_ObjectActions(t *testing.T) {
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 1),
+		mock.AdapterWithRoutine(&core.Structure{
+			Name:   "add",
+			Schema: "public",
+			Type:   core.StructureTypeFunction,
+		}, "CREATE FUNCTION add(a int, b int) RETURNS int AS 'select a + b' LANGUAGE sql;"),
+	)
+	connection, err := core.NewConnection(&core.ConnectionParams{Guarded: true}, adapter)
+	r.NoError(err)
+
+	table := &core.TableOptions{Table: "users", Schema: "public", Materialization: core.StructureTypeTable}
+
+	actions, err := connection.GetObjectActions(table)
+	r.NoError(err)
+	ids := make([]string, len(actions))
+	for i, action := range actions {
+		ids[i] = action.ID
+	}
+	r.Equal([]string{"select", "count", "definition", "truncate", "drop"}, ids)
+	r.Equal(core.ConfirmationTyped, actions[4].Confirmation)
+
+	result, err := connection.RunObjectAction(table, "select", "", nil)
+	r.NoError(err)
+	r.Equal("SELECT * FROM \"public\".\"users\"\nLIMIT 500", result.Call.GetQuery())
+	<-result.Call.Done()
+	r.NoError(result.Call.Err())
+
+	// destructive actions require typing the object name, but skip the guard once confirmed
+	_, err = connection.RunObjectAction(table, "drop", "yes", nil)
+	r.ErrorIs(err, core.ErrActionNotConfirmed)
+	result, err = connection.RunObjectAction(table, "drop", "users", nil)
+	r.NoError(err)
+	r.Equal(`DROP TABLE "public"."users"`, result.Call.GetQuery())
+	<-result.Call.Done()
+	r.NoError(result.Call.Err())
+
+	result, err = connection.RunObjectAction(&core.TableOptions{
+		Table:           "add",
+		Schema:          "public",
+		Materialization: core.StructureTypeFunction,
+	}, "definition", "", nil)
+	r.NoError(err)
+	r.Contains(result.Text, "CREATE FUNCTION add")
+
+	_, err = connection.RunObjectAction(table, "refresh", "yes", nil)
+	r.ErrorIs(err, core.ErrUnknownObjectAction)
+}
+
+func TestConnection_ObjectActionPreview(t *testing.T) {
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 1),
+		mock.AdapterWithTableDefinition("events", []*core.Column{
+			{Name: "id", Type: "int", PrimaryKey: true},
+			{Name: "name", Type: "text"},
+			{Name: "payload", Type: "json"},
+		}),
+	)
+	connection, err := core.NewConnection(&core.ConnectionParams{
+		Preview: core.PreviewOptions{
+			Limit:   20,
+			Latest:  true,
+			Columns: []string{"ID", "name", "missing"},
+		},
+	}, adapter)
+	r.NoError(err)
+
+	table := &core.TableOptions{Table: "events", Materialization: core.StructureTypeTable}
+
+	actions, err := connection.GetObjectActions(table)
+	r.NoError(err)
+	r.Equal("Select latest 20", actions[0].Title)
+
+	result, err := connection.RunObjectAction(table, "select", "", nil)
+	r.NoError(err)
+	r.Equal(`SELECT "id", "name" FROM "events" ORDER BY "id" DESC`+"\nLIMIT 20", result.Call.GetQuery())
+	<-result.Call.Done()
+	r.NoError(result.Call.Err())
+
+	// objects without known columns select all rows
+	result, err = connection.RunObjectAction(&core.TableOptions{
+		Table:           "unknown",
+		Materialization: core.StructureTypeView,
+	}, "select", "", nil)
+	r.NoError(err)
+	r.Equal("SELECT * FROM \"unknown\"\nLIMIT 20", result.Call.GetQuery())
+	<-result.Call.Done()
+	r.NoError(result.Call.Err())
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrPartitionsNotSupported      = errors.New("listing partitions not supported")
+	ErrPartitionActionNotSupported = errors.New("partition action not supported")
+	ErrUnknownPartition            = errors.New("not a partition of the table")
+)
+
+type (
+	// Partition is a partition of a partitioned table.
+	Partition struct {
+		Schema string
+		Name   string
+		// partition bound or key value (e.g. "FOR VALUES FROM (1) TO (10)")
+		Expression string
+		// estimated number of rows (-1 if unknown)
+		Rows int64
+		// size in bytes (-1 if unknown)
+		Size int64
+	}
+
+	// PartitionLister is an optional interface for drivers that can list
+	// partitions of a table.
+	PartitionLister interface {
+		Partitions(ctx context.Context, opts *TableOptions) ([]*Partition, error)
+	}
+
+	// PartitionManager is an optional interface for drivers that can detach
+	// and drop partitions of a table. Drivers which support only one of the
+	// actions return ErrPartitionActionNotSupported from the other.
+	PartitionManager interface {
+		DetachPartition(ctx context.Context, opts *TableOptions, partition *Partition) error
+		DropPartition(ctx context.Context, opts *TableOptions, partition *Partition) error
+	}
+)
+
+// GetPartitions returns partitions of the table described by opts.
+func (c *Connection) GetPartitions(opts *TableOptions) ([]*Partition, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("opts cannot be nil")
+	}
+
+	lister, ok := c.driver.(PartitionLister)
+	if !ok {
+		return nil, ErrPartitionsNotSupported
+	}
+
+	partitions, err := lister.Partitions(context.Background(), opts)
+	if err != nil {
+		return nil, fmt.Errorf("lister.Partitions: %w", err)
+	}
+
+	return partitions, nil
+}
+
+// DetachPartition detaches the partition from the table described by opts.
+// Like destructive object actions, it runs only if confirmation equals the
+// name of the partition.
+func (c *Connection) DetachPartition(opts *TableOptions, partition *Partition, confirmation string) error {
+	manager, partition, err := c.partitionManager(opts, partition, confirmation)
+	if err != nil {
+		return err
+	}
+
+	err = manager.DetachPartition(context.Background(), opts, partition)
+	if err != nil {
+		return fmt.Errorf("manager.DetachPartition: %w", err)
+	}
+
+	// detached partitions may become standalone tables
+	c.RefreshStructure(nil)
+	return nil
+}
+
+// DropPartition drops the partition and its data from the table described by opts.
+// It runs only if confirmation equals the name of the partition.
+func (c *Connection) DropPartition(opts *TableOptions, partition *Partition, confirmation string) error {
+	manager, partition, err := c.partitionManager(opts, partition, confirmation)
+	if err != nil {
+		return err
+	}
+
+	err = manager.DropPartition(context.Background(), opts, partition)
+	if err != nil {
+		return fmt.Errorf("manager.DropPartition: %w", err)
+	}
+
+	c.RefreshStructure(nil)
+	return nil
+}
+
+// partitionManager checks the confirmation and returns the manager and the
+// partition as listed by the driver, so actions never run on tables which
+// aren't partitions of the table described by opts.
+func (c *Connection) partitionManager(opts *TableOptions, partition *Partition, confirmation string) (PartitionManager, *Partition, error) {
+	manager, ok := c.driver.(PartitionManager)
+	if !ok {
+		return nil, nil, ErrPartitionActionNotSupported
+	}
+	if opts == nil || opts.Table == "" {
+		return nil, nil, errors.New("no table provided")
+	}
+	if partition == nil || partition.Name == "" {
+		return nil, nil, errors.New("no partition provided")
+	}
+	if confirmation != partition.Name {
+		return nil, nil, fmt.Errorf("%w: type %q to confirm", ErrActionNotConfirmed, partition.Name)
+	}
+
+	partitions, err := c.GetPartitions(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, p := range partitions {
+		if p.Name == partition.Name && (partition.Schema == "" || p.Schema == partition.Schema) {
+			return manager, p, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: %q", ErrUnknownPartition, partition.Name)
+}
@@ -0,0 +1,42 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_PartitionActions(t *testing.T) {
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 1),
+		mock.AdapterWithPartitions("events",
+			&core.Partition{Schema: "public", Name: "events_2024"},
+			&core.Partition{Schema: "public", Name: "events_2025"},
+		),
+	)
+	connection, err := core.NewConnection(&core.ConnectionParams{Guarded: true}, adapter)
+	r.NoError(err)
+
+	opts := &core.TableOptions{Schema: "public", Table: "events"}
+
+	// the name of the partition has to be typed
+	err = connection.DropPartition(opts, &core.Partition{Name: "events_2024"}, "")
+	r.ErrorIs(err, core.ErrActionNotConfirmed)
+	err = connection.DetachPartition(opts, &core.Partition{Name: "events_2024"}, "events")
+	r.ErrorIs(err, core.ErrActionNotConfirmed)
+
+	// only partitions of the table are dropped
+	err = connection.DropPartition(opts, &core.Partition{Name: "users"}, "users")
+	r.ErrorIs(err, core.ErrUnknownPartition)
+	err = connection.DropPartition(opts, &core.Partition{Schema: "other", Name: "events_2024"}, "events_2024")
+	r.ErrorIs(err, core.ErrUnknownPartition)
+	r.Empty(adapter.PartitionOps())
+
+	r.NoError(connection.DetachPartition(opts, &core.Partition{Name: "events_2024"}, "events_2024"))
+	r.NoError(connection.DropPartition(opts, &core.Partition{Schema: "public", Name: "events_2025"}, "events_2025"))
+	r.Equal([]string{"detach public.events_2024", "drop public.events_2025"}, adapter.PartitionOps())
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrExplainNotSupported = errors.New("structured query plans not supported")
+
+// PlanNode is a node of a normalized query plan tree.
+type PlanNode struct {
+	// type of the operation (e.g. "Seq Scan", "Hash Join", "ALL")
+	Type string
+	// relation (table, index, ...) the operation works on, if any
+	Relation string
+	// estimated cost of the operation including its children
+	Cost float64
+	// estimated number of rows
+	Rows float64
+	// actual number of rows and total time in milliseconds;
+	// only available if the plan was analyzed
+	ActualRows float64
+	ActualTime float64
+
+	Children []*PlanNode
+}
+
+// Planner is an optional interface for drivers that can return structured query plans.
+type Planner interface {
+	// Explain returns the plan of query. If analyze is true, the query is executed
+	// and actual row counts and timings are included.
+	Explain(ctx context.Context, query string, analyze bool) (*PlanNode, error)
+}
+
+// Explain returns a structured plan of the query. Analyzed plans execute the
+// query, so they are subject to the guard of the connection.
+func (c *Connection) Explain(query string, analyze bool) (*PlanNode, error) {
+	planner, ok := c.driver.(Planner)
+	if !ok {
+		return nil, ErrExplainNotSupported
+	}
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("empty query")
+	}
+	if analyze {
+		if err := c.CheckGuard(query); err != nil {
+			return nil, err
+		}
+	}
+
+	plan, err := planner.Explain(context.Background(), query, analyze)
+	if err != nil {
+		return nil, fmt.Errorf("planner.Explain: %w", err)
+	}
+
+	return plan, nil
+}
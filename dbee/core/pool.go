@@ -0,0 +1,36 @@
+package core
+
+import "errors"
+
+var ErrPoolStatsNotSupported = errors.New("pool stats not supported")
+
+type (
+	// PoolStats describes the pool of database connections of a connection.
+	PoolStats struct {
+		// maximum number of open connections (0 is unlimited)
+		MaxOpen int
+		Open    int
+		InUse   int
+		Idle    int
+		// total number of times a query waited for a free connection
+		WaitCount int64
+		// total time spent waiting for a free connection in seconds
+		WaitSeconds float64
+	}
+
+	// PoolStatsProvider is an optional interface for drivers which keep a pool
+	// of database connections.
+	PoolStatsProvider interface {
+		PoolStats() *PoolStats
+	}
+)
+
+// GetPoolStats returns utilization of the driver's pool of database connections.
+func (c *Connection) GetPoolStats() (*PoolStats, error) {
+	provider, ok := c.driver.(PoolStatsProvider)
+	if !ok {
+		return nil, ErrPoolStatsNotSupported
+	}
+
+	return provider.PoolStats(), nil
+}
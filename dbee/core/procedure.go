@@ -0,0 +1,78 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrProcedureCallingNotSupported = errors.New("procedure calling not supported")
+
+type ParamMode int
+
+const (
+	ParamModeIn ParamMode = iota
+	ParamModeOut
+	ParamModeInOut
+)
+
+func ParamModeFromString(s string) ParamMode {
+	switch strings.ToLower(s) {
+	case "out":
+		return ParamModeOut
+	case "inout":
+		return ParamModeInOut
+	default:
+		return ParamModeIn
+	}
+}
+
+func (m ParamMode) String() string {
+	switch m {
+	case ParamModeOut:
+		return "out"
+	case ParamModeInOut:
+		return "inout"
+	default:
+		return "in"
+	}
+}
+
+// ProcedureParam is a single parameter of a stored procedure call.
+type ProcedureParam struct {
+	Name string
+	Mode ParamMode
+	// Value is ignored for ParamModeOut
+	Value any
+}
+
+// IsOutput reports whether the parameter returns a value.
+func (p *ProcedureParam) IsOutput() bool {
+	return p.Mode == ParamModeOut || p.Mode == ParamModeInOut
+}
+
+// ProcedureCaller is an optional interface for drivers that can call stored procedures
+// with output parameters. Output values are returned as a single row, with parameter
+// names as header.
+type ProcedureCaller interface {
+	CallProcedure(ctx context.Context, name string, params []*ProcedureParam) (ResultStream, error)
+}
+
+// procedureCallString returns a human readable form of the procedure call,
+// which is used as the query of the call.
+func procedureCallString(name string, params []*ProcedureParam) string {
+	args := make([]string, len(params))
+	for i, p := range params {
+		switch p.Mode {
+		case ParamModeOut:
+			args[i] = fmt.Sprintf("%s => OUT", p.Name)
+		case ParamModeInOut:
+			args[i] = fmt.Sprintf("%s => %v INOUT", p.Name, p.Value)
+		default:
+			args[i] = fmt.Sprintf("%s => %v", p.Name, p.Value)
+		}
+	}
+
+	return fmt.Sprintf("CALL %s(%s)", name, strings.Join(args, ", "))
+}
@@ -0,0 +1,47 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_CallProcedure(t *testing.T) {
+	r := require.New(t)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{
+		Guarded: true,
+		Quota:   core.QueryQuota{MaxQueriesPerMinute: 2},
+	}, mock.NewAdapter(mock.NewRows(0, 3)))
+	r.NoError(err)
+
+	params := []*core.ProcedureParam{{Name: "id", Value: 1}}
+
+	wait := func(call *core.Call) {
+		select {
+		case <-call.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("call did not finish in expected time")
+		}
+	}
+
+	// procedure calls have to be confirmed on guarded connections
+	call := connection.CallProcedure("archive", params, nil)
+	wait(call)
+	r.ErrorIs(call.Err(), core.ErrConfirmationRequired)
+
+	call = connection.CallProcedureConfirmed("archive", params, nil)
+	wait(call)
+	r.NoError(call.Err())
+	r.Equal("CALL archive(id => 1)", call.GetQuery())
+
+	// procedure calls count towards the quota
+	wait(connection.CallProcedureConfirmed("archive", params, nil))
+	call = connection.CallProcedureConfirmed("archive", params, nil)
+	wait(call)
+	r.ErrorIs(call.Err(), core.ErrQuotaExceeded)
+}
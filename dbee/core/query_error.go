@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+)
+
+// ErrorCategory is a dialect independent kind of a query error.
+type ErrorCategory string
+
+const (
+	ErrorCategoryUnknown    ErrorCategory = "unknown"
+	ErrorCategoryAuth       ErrorCategory = "auth"
+	ErrorCategoryNetwork    ErrorCategory = "network"
+	ErrorCategorySyntax     ErrorCategory = "syntax"
+	ErrorCategoryConstraint ErrorCategory = "constraint"
+	ErrorCategoryTimeout    ErrorCategory = "timeout"
+	ErrorCategoryPermission ErrorCategory = "permission"
+	ErrorCategoryNotFound   ErrorCategory = "not_found"
+)
+
+// Kinds of objects of query errors.
+const (
+	ErrorObjectColumn   = "column"
+	ErrorObjectTable    = "table"
+	ErrorObjectFunction = "function"
+	ErrorObjectSchema   = "schema"
+)
+
+type (
+	// QueryError is an error of a query with details reported by the database.
+	QueryError struct {
+		Category ErrorCategory
+		// native error code of the database (e.g. SQLSTATE)
+		Code string
+		// 1-based character offset of the error in the query (0 if unknown)
+		Position int
+		// 1-based line and column of the error in the query (0 if unknown),
+		// calculated from Position if drivers report only the offset
+		Line   int
+		Column int
+		// suggestion how to fix the error (if the database reports one)
+		Hint string
+		// additional details reported by the database
+		Detail string
+		// kind (see ErrorObject*) and name of the object the error is about,
+		// e.g. the undefined column of ErrorCategoryNotFound errors
+		ObjectKind string
+		Object     string
+
+		Err error
+	}
+
+	// ErrorClassifier is an optional interface for drivers which can extract
+	// details from errors of their database. It returns nil for errors it
+	// doesn't recognize. The Err field of the returned error is set by the caller.
+	ErrorClassifier interface {
+		ClassifyError(err error) *QueryError
+	}
+)
+
+func (e *QueryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// classifyError wraps err of the query into a QueryError. Errors which the
+// driver doesn't recognize are classified from standard library errors.
+func classifyError(drv Driver, err error, query string) error {
+	var qErr *QueryError
+	if err == nil || errors.As(err, &qErr) {
+		return err
+	}
+
+	if classifier, ok := drv.(ErrorClassifier); ok {
+		qErr = classifier.ClassifyError(err)
+	}
+	if qErr == nil {
+		qErr = &QueryError{Category: genericErrorCategory(err)}
+	}
+	qErr.Err = err
+
+	if qErr.Position > 0 && qErr.Line == 0 {
+		qErr.Line, qErr.Column = characterPosition(query, qErr.Position)
+	}
+
+	return qErr
+}
+
+func genericErrorCategory(err error) ErrorCategory {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorCategoryTimeout
+	case errors.Is(err, driver.ErrBadConn),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.As(err, &netErr):
+		return ErrorCategoryNetwork
+	}
+	return ErrorCategoryUnknown
+}
+
+// characterPosition returns 1-based line and column of the 1-based character
+// offset in text.
+func characterPosition(text string, position int) (line, column int) {
+	line, column = 1, 1
+	for i, r := range []rune(text) {
+		if i+1 >= position {
+			break
+		}
+		if r == '\n' {
+			line++
+			column = 1
+			continue
+		}
+		column++
+	}
+	return line, column
+}
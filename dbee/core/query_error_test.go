@@ -0,0 +1,63 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_QueryError(t *testing.T) {
+	r := require.New(t)
+
+	syntaxErr := errors.New(`syntax error at or near "form"`)
+	query := "select *\n  form users"
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 1),
+		mock.AdapterWithQuerySideEffect(query, func(context.Context) error {
+			return syntaxErr
+		}),
+		mock.AdapterWithQuerySideEffect("select 1", func(context.Context) error {
+			return context.DeadlineExceeded
+		}),
+		mock.AdapterWithErrorClassifier(func(err error) *core.QueryError {
+			if !errors.Is(err, syntaxErr) {
+				return nil
+			}
+			return &core.QueryError{Category: core.ErrorCategorySyntax, Code: "42601", Position: 12, Hint: "check the spelling"}
+		}),
+	)
+	connection, err := core.NewConnection(&core.ConnectionParams{Type: "mock"}, adapter)
+	r.NoError(err)
+
+	call := connection.Execute(query, nil)
+	<-call.Done()
+
+	var qErr *core.QueryError
+	r.ErrorAs(call.Err(), &qErr)
+	r.ErrorIs(call.Err(), syntaxErr)
+	r.Equal(core.ErrorCategorySyntax, qErr.Category)
+	r.Equal("42601", qErr.Code)
+	r.Equal(2, qErr.Line)
+	r.Equal(3, qErr.Column)
+	r.Equal("check the spelling", qErr.Hint)
+
+	// details survive the call log
+	b, err := call.MarshalJSON()
+	r.NoError(err)
+	restored := new(core.Call)
+	r.NoError(restored.UnmarshalJSON(b))
+	r.ErrorAs(restored.Err(), &qErr)
+	r.Equal(2, qErr.Line)
+
+	// errors unknown to the driver are classified generically
+	call = connection.Execute("select 1", nil)
+	<-call.Done()
+	r.ErrorAs(call.Err(), &qErr)
+	r.Equal(core.ErrorCategoryTimeout, qErr.Category)
+	r.Zero(qErr.Line)
+}
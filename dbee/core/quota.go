@@ -0,0 +1,196 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrQuotaExceeded = errors.New("query quota exceeded")
+
+// ErrorCategoryQuota errors are returned by queries which exceed the query
+// quota of a connection.
+const ErrorCategoryQuota ErrorCategory = "quota"
+
+// QuotaLimit is the kind of a query quota.
+type QuotaLimit string
+
+const (
+	QuotaLimitRate     QuotaLimit = "max_queries_per_minute"
+	QuotaLimitCooldown QuotaLimit = "cooldown"
+)
+
+// QueryQuota limits how many queries run on a connection, so that editors
+// can't overload shared databases. It's enforced on all connections, guarded
+// or not. Zero values disable the limits.
+type QueryQuota struct {
+	// MaxQueriesPerMinute is the maximum number of queries started within
+	// any minute.
+	MaxQueriesPerMinute int
+	// CooldownRows is the number of rows a query may read before the
+	// connection cools down.
+	CooldownRows int64
+	// Cooldown is the number of seconds no queries run after a query which
+	// read more than CooldownRows rows.
+	Cooldown int
+}
+
+func (q *QueryQuota) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		MaxQueriesPerMinute int   `json:"max_queries_per_minute,omitempty"`
+		CooldownRows        int64 `json:"cooldown_rows,omitempty"`
+		Cooldown            int   `json:"cooldown,omitempty"`
+	}{
+		MaxQueriesPerMinute: q.MaxQueriesPerMinute,
+		CooldownRows:        q.CooldownRows,
+		Cooldown:            q.Cooldown,
+	})
+}
+
+// IsEmpty reports whether all limits are disabled.
+func (q *QueryQuota) IsEmpty() bool {
+	return q.MaxQueriesPerMinute <= 0 && (q.CooldownRows <= 0 || q.Cooldown <= 0)
+}
+
+// QuotaError is the error of a call which was refused by the query quota.
+// It's returned as the Err of a QueryError with the ErrorCategoryQuota
+// category and the limit as the code.
+type QuotaError struct {
+	Limit QuotaLimit
+	// RetryAfter is the time until queries run again.
+	RetryAfter time.Duration
+}
+
+func (e *QuotaError) Error() string {
+	var detail string
+	switch e.Limit {
+	case QuotaLimitRate:
+		detail = "too many queries in the last minute"
+	case QuotaLimitCooldown:
+		detail = "the connection cools down after a large query"
+	}
+	return fmt.Sprintf("%s: %s: %s, retry in %s", ErrQuotaExceeded, e.Limit, detail, e.RetryAfter.Round(time.Second))
+}
+
+func (e *QuotaError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+func newQuotaError(limit QuotaLimit, retryAfter time.Duration) error {
+	// always wait at least a second, so the error doesn't say "retry in 0s"
+	if retryAfter < time.Second {
+		retryAfter = time.Second
+	}
+	return &QueryError{
+		Category: ErrorCategoryQuota,
+		Code:     string(limit),
+		Hint:     fmt.Sprintf("wait %s before running another query", retryAfter.Round(time.Second)),
+		Err:      &QuotaError{Limit: limit, RetryAfter: retryAfter},
+	}
+}
+
+// quotaTracker enforces the query quota of a connection. Nil trackers
+// enforce no quota.
+type quotaTracker struct {
+	mu    sync.Mutex
+	quota QueryQuota
+	// start times of queries within the last minute
+	started []time.Time
+	// no queries run until then
+	cooldownUntil time.Time
+}
+
+func newQuotaTracker(quota QueryQuota) *quotaTracker {
+	if quota.IsEmpty() {
+		return nil
+	}
+	return &quotaTracker{quota: quota}
+}
+
+// acquire records the start of a query or fails if the quota doesn't allow it.
+func (qt *quotaTracker) acquire() error {
+	if qt == nil {
+		return nil
+	}
+
+	qt.mu.Lock()
+	defer qt.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(qt.cooldownUntil) {
+		return newQuotaError(QuotaLimitCooldown, qt.cooldownUntil.Sub(now))
+	}
+
+	if qt.quota.MaxQueriesPerMinute > 0 {
+		// forget queries older than a minute
+		window := now.Add(-time.Minute)
+		i := 0
+		for i < len(qt.started) && !qt.started[i].After(window) {
+			i++
+		}
+		qt.started = qt.started[i:]
+
+		if len(qt.started) >= qt.quota.MaxQueriesPerMinute {
+			return newQuotaError(QuotaLimitRate, qt.started[0].Sub(window))
+		}
+		qt.started = append(qt.started, now)
+	}
+
+	return nil
+}
+
+// observe starts the cooldown if the query read too many rows.
+func (qt *quotaTracker) observe(rows int64) {
+	if qt.quota.CooldownRows <= 0 || qt.quota.Cooldown <= 0 || rows <= qt.quota.CooldownRows {
+		return
+	}
+
+	qt.mu.Lock()
+	defer qt.mu.Unlock()
+
+	qt.cooldownUntil = time.Now().Add(time.Duration(qt.quota.Cooldown) * time.Second)
+}
+
+// stream returns a stream which counts rows of iter for the cooldown.
+func (qt *quotaTracker) stream(iter ResultStream) ResultStream {
+	if qt == nil {
+		return iter
+	}
+	qs := &quotaStream{ResultStream: iter, tracker: qt}
+	if multi, ok := iter.(MultiResultStream); ok {
+		return &quotaMultiStream{quotaStream: qs, multi: multi}
+	}
+	return qs
+}
+
+// quotaStream counts rows of all result sets of the underlying stream.
+type quotaStream struct {
+	ResultStream
+	tracker *quotaTracker
+	rows    int64
+	once    sync.Once
+}
+
+func (qs *quotaStream) Next() (Row, error) {
+	row, err := qs.ResultStream.Next()
+	if err == nil {
+		qs.rows++
+	}
+	return row, err
+}
+
+func (qs *quotaStream) Close() {
+	qs.ResultStream.Close()
+	qs.once.Do(func() { qs.tracker.observe(qs.rows) })
+}
+
+type quotaMultiStream struct {
+	*quotaStream
+	multi MultiResultStream
+}
+
+func (qs *quotaMultiStream) NextResultSet() bool {
+	return qs.multi.NextResultSet()
+}
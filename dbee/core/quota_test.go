@@ -0,0 +1,74 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_QueryQuota(t *testing.T) {
+	type testCase struct {
+		name          string
+		quota         core.QueryQuota
+		expectedLimit core.QuotaLimit
+	}
+
+	testCases := []testCase{
+		{
+			name: "no quota",
+		},
+		{
+			name:          "too many queries per minute",
+			quota:         core.QueryQuota{MaxQueriesPerMinute: 2},
+			expectedLimit: core.QuotaLimitRate,
+		},
+		{
+			name:          "cooldown after a large query",
+			quota:         core.QueryQuota{CooldownRows: 5, Cooldown: 60},
+			expectedLimit: core.QuotaLimitCooldown,
+		},
+		{
+			name:  "queries within the cooldown rows",
+			quota: core.QueryQuota{CooldownRows: 10, Cooldown: 60},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			connection, err := core.NewConnection(&core.ConnectionParams{Quota: tc.quota}, mock.NewAdapter(mock.NewRows(0, 10),
+				mock.AdapterWithResultStreamOpts(mock.ResultStreamWithMeta(&core.Meta{})),
+			))
+			r.NoError(err)
+
+			var call *core.Call
+			for i := 0; i < 3; i++ {
+				call = connection.Execute("select", nil)
+				select {
+				case <-call.Done():
+				case <-time.After(5 * time.Second):
+					t.Fatal("call did not finish in expected time")
+				}
+			}
+
+			if tc.expectedLimit == "" {
+				r.NoError(call.Err())
+				return
+			}
+
+			r.ErrorIs(call.Err(), core.ErrQuotaExceeded)
+
+			var qErr *core.QueryError
+			r.True(errors.As(call.Err(), &qErr))
+			r.Equal(core.ErrorCategoryQuota, qErr.Category)
+			r.Equal(string(tc.expectedLimit), qErr.Code)
+			r.NotEmpty(qErr.Hint)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// recoveryGracePeriod is the age of artifacts of unfinished writes after which
+// they are removed. Younger ones could belong to another backend which is
+// still writing them.
+const recoveryGracePeriod = 10 * time.Minute
+
+// RecoveryReport describes artifacts of a previous crash repaired by RecoverStorage.
+type RecoveryReport struct {
+	// IncompleteArchives is the number of removed result sets whose archiving
+	// was interrupted.
+	IncompleteArchives int
+	// TempFiles is the number of removed temporary files of interrupted
+	// atomic writes.
+	TempFiles int
+}
+
+// Empty reports whether nothing was repaired.
+func (r *RecoveryReport) Empty() bool {
+	return r.IncompleteArchives == 0 && r.TempFiles == 0
+}
+
+// RecoverStorage removes artifacts of writes interrupted by a crash of a
+// previous backend: archived results without meta (which is written last) and
+// temporary files of WriteFileAtomic. It's meant to run on startup.
+func RecoverStorage() (*RecoveryReport, error) {
+	report := &RecoveryReport{}
+	cutoff := time.Now().Add(-recoveryGracePeriod)
+
+	calls, err := os.ReadDir(archiveBasePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("os.ReadDir: %w", err)
+	}
+	for _, call := range calls {
+		if !call.IsDir() {
+			continue
+		}
+		n, err := recoverArchive(archiveBasePath, call, cutoff)
+		if err != nil {
+			return report, err
+		}
+		report.IncompleteArchives += n
+	}
+
+	files, err := os.ReadDir(StorageDir())
+	if err != nil && !os.IsNotExist(err) {
+		return report, fmt.Errorf("os.ReadDir: %w", err)
+	}
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasPrefix(name, ".dbee-") || !strings.HasSuffix(name, ".tmp") {
+			continue
+		}
+		if !modifiedBefore(file, cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(StorageDir(), name)); err != nil && !os.IsNotExist(err) {
+			return report, fmt.Errorf("os.Remove: %w", err)
+		}
+		report.TempFiles++
+	}
+
+	return report, nil
+}
+
+// recoverArchive removes incomplete result sets of the call's archive and
+// returns their number. The whole directory is removed if the first set is
+// incomplete.
+func recoverArchive(base string, call os.DirEntry, cutoff time.Time) (int, error) {
+	dir := filepath.Join(base, call.Name())
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("os.ReadDir: %w", err)
+	}
+
+	isComplete := func(dir string) bool {
+		_, err := os.Stat(metaFile(dir))
+		return err == nil
+	}
+	// archives which are still written are modified constantly
+	isStale := func(root os.DirEntry, entries []os.DirEntry) bool {
+		for _, entry := range append(entries, root) {
+			if !modifiedBefore(entry, cutoff) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if !isComplete(dir) {
+		if !isStale(call, entries) {
+			return 0, nil
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return 0, fmt.Errorf("os.RemoveAll: %w", err)
+		}
+		return 1, nil
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), "set_") {
+			continue
+		}
+		setDir := filepath.Join(dir, entry.Name())
+		if isComplete(setDir) {
+			continue
+		}
+		setEntries, err := os.ReadDir(setDir)
+		if err != nil {
+			return removed, fmt.Errorf("os.ReadDir: %w", err)
+		}
+		if !isStale(entry, setEntries) {
+			continue
+		}
+		if err := os.RemoveAll(setDir); err != nil {
+			return removed, fmt.Errorf("os.RemoveAll: %w", err)
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+func modifiedBefore(entry os.DirEntry, cutoff time.Time) bool {
+	info, err := entry.Info()
+	if err != nil {
+		return false
+	}
+	return info.ModTime().Before(cutoff)
+}
@@ -0,0 +1,75 @@
+package core_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestRecoverStorage(t *testing.T) {
+	r := require.New(t)
+
+	base := filepath.Join(core.StorageDir(), "dbee-history")
+	old := time.Now().Add(-time.Hour)
+
+	// mkArchive creates an archive of a call and returns its directory
+	mkArchive := func(id string, files []string, modified time.Time) string {
+		dir := filepath.Join(base, id)
+		for _, f := range files {
+			path := filepath.Join(dir, f)
+			r.NoError(os.MkdirAll(filepath.Dir(path), os.ModePerm))
+			r.NoError(os.WriteFile(path, []byte("_"), 0o644))
+			r.NoError(os.Chtimes(path, modified, modified))
+			r.NoError(os.Chtimes(filepath.Dir(path), modified, modified))
+		}
+		r.NoError(os.Chtimes(dir, modified, modified))
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+		return dir
+	}
+
+	prefix := "recovery-test-" + time.Now().Format("150405.000000") + "-"
+	complete := mkArchive(prefix+"complete", []string{"header.gob", "row_0.gob", "meta.gob"}, old)
+	interrupted := mkArchive(prefix+"interrupted", []string{"header.gob", "row_0.gob"}, old)
+	writing := mkArchive(prefix+"writing", []string{"header.gob"}, time.Now())
+	partialSet := mkArchive(prefix+"partial-set", []string{"header.gob", "meta.gob", "set_1/header.gob"}, old)
+
+	temp := filepath.Join(core.StorageDir(), ".dbee-"+prefix+"calllog.json.1.tmp")
+	r.NoError(os.WriteFile(temp, []byte("{"), 0o644))
+	r.NoError(os.Chtimes(temp, old, old))
+	t.Cleanup(func() { _ = os.Remove(temp) })
+
+	report, err := core.RecoverStorage()
+	r.NoError(err)
+	r.GreaterOrEqual(report.IncompleteArchives, 2)
+	r.GreaterOrEqual(report.TempFiles, 1)
+
+	r.DirExists(complete)
+	r.NoDirExists(interrupted)
+	r.DirExists(writing)
+	r.FileExists(filepath.Join(partialSet, "meta.gob"))
+	r.NoDirExists(filepath.Join(partialSet, "set_1"))
+	r.NoFileExists(temp)
+}
+
+func TestCall_RestoreInterrupted(t *testing.T) {
+	r := require.New(t)
+
+	// the backend was killed while the call was executing
+	b := []byte(`{"id":"recovery-test-executing","query":"select 1","state":"executing","time_taken_us":0,"timestamp_us":0}`)
+
+	call := new(core.Call)
+	r.NoError(json.Unmarshal(b, call))
+	r.Equal(core.CallStateUnknown, call.GetState())
+
+	select {
+	case <-call.Done():
+	default:
+		t.Fatal("restored call is not done")
+	}
+}
@@ -0,0 +1,230 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrResourceLimitExceeded = errors.New("resource limit exceeded")
+
+// ErrorCategoryResourceLimit errors are returned by queries which exceed the
+// resource limits of guarded connections.
+const ErrorCategoryResourceLimit ErrorCategory = "resource_limit"
+
+// ResourceLimit is the kind of a resource limit.
+type ResourceLimit string
+
+const (
+	ResourceLimitRows         ResourceLimit = "max_rows"
+	ResourceLimitScannedBytes ResourceLimit = "max_scanned_bytes"
+	ResourceLimitRuntime      ResourceLimit = "max_runtime"
+)
+
+// ResourceLimits are enforced on every call of a guarded connection.
+// Zero values disable the limits.
+type ResourceLimits struct {
+	// MaxRows is the maximum number of rows of a result set.
+	MaxRows int
+	// TruncateRows stops result sets at MaxRows instead of failing the call.
+	TruncateRows bool
+	// MaxScannedBytes is the maximum number of bytes a query may scan. It's
+	// enforced only if the driver can estimate it before running the query.
+	MaxScannedBytes int64
+	// MaxRuntime is the maximum number of seconds a call may take, including
+	// fetching of rows.
+	MaxRuntime int
+}
+
+func (rl *ResourceLimits) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		MaxRows         int   `json:"max_rows,omitempty"`
+		TruncateRows    bool  `json:"truncate_rows,omitempty"`
+		MaxScannedBytes int64 `json:"max_scanned_bytes,omitempty"`
+		MaxRuntime      int   `json:"max_runtime,omitempty"`
+	}{
+		MaxRows:         rl.MaxRows,
+		TruncateRows:    rl.TruncateRows,
+		MaxScannedBytes: rl.MaxScannedBytes,
+		MaxRuntime:      rl.MaxRuntime,
+	})
+}
+
+// IsEmpty reports whether all limits are disabled.
+func (rl *ResourceLimits) IsEmpty() bool {
+	return rl.MaxRows <= 0 && rl.MaxScannedBytes <= 0 && rl.MaxRuntime <= 0
+}
+
+// ScanEstimator is an optional interface for drivers whose databases report
+// the number of bytes a query scans without running it (e.g. a dry run).
+type ScanEstimator interface {
+	EstimateScan(ctx context.Context, query string) (int64, error)
+}
+
+// ResourceLimitError is the error of a call which exceeded a resource limit.
+// It's returned as the Err of a QueryError with the ErrorCategoryResourceLimit
+// category and the limit as the code.
+type ResourceLimitError struct {
+	Limit ResourceLimit
+	// value of the limit (seconds for the runtime)
+	Max int64
+	// reported value (0 if unknown)
+	Actual int64
+}
+
+func (e *ResourceLimitError) Error() string {
+	var detail string
+	switch e.Limit {
+	case ResourceLimitRows:
+		detail = fmt.Sprintf("result has more than %d rows", e.Max)
+	case ResourceLimitScannedBytes:
+		detail = fmt.Sprintf("query would scan %d bytes, the limit is %d bytes", e.Actual, e.Max)
+	case ResourceLimitRuntime:
+		detail = fmt.Sprintf("call took longer than %s", time.Duration(e.Max)*time.Second)
+	}
+	return fmt.Sprintf("%s: %s: %s", ErrResourceLimitExceeded, e.Limit, detail)
+}
+
+func (e *ResourceLimitError) Is(target error) bool {
+	return target == ErrResourceLimitExceeded
+}
+
+// newResourceLimitError returns the error as a QueryError, so the limit is
+// reported with other details of errors.
+func newResourceLimitError(limit ResourceLimit, max, actual int64) error {
+	hints := map[ResourceLimit]string{
+		ResourceLimitRows:         "add a LIMIT clause or a more selective WHERE clause",
+		ResourceLimitScannedBytes: "filter by partitioned or clustered columns and select fewer columns",
+		ResourceLimitRuntime:      "make the query faster or run it on a connection without limits",
+	}
+	return &QueryError{
+		Category: ErrorCategoryResourceLimit,
+		Code:     string(limit),
+		Hint:     hints[limit],
+		Err:      &ResourceLimitError{Limit: limit, Max: max, Actual: actual},
+	}
+}
+
+// resourceLimits returns limits enforced on calls of the connection, nil if
+// the connection isn't guarded.
+func (c *Connection) resourceLimits() *ResourceLimits {
+	if !c.params.Guarded || c.params.Limits.IsEmpty() {
+		return nil
+	}
+	return &c.params.Limits
+}
+
+// checkScan fails if the driver estimates the query would scan more bytes
+// than allowed.
+func (rl *ResourceLimits) checkScan(ctx context.Context, driver Driver, query string) error {
+	estimator, ok := driver.(ScanEstimator)
+	if rl.MaxScannedBytes <= 0 || !ok {
+		return nil
+	}
+
+	scanned, err := estimator.EstimateScan(ctx, query)
+	if err != nil {
+		return classifyError(driver, err, query)
+	}
+	if scanned > rl.MaxScannedBytes {
+		return newResourceLimitError(ResourceLimitScannedBytes, rl.MaxScannedBytes, scanned)
+	}
+	return nil
+}
+
+// withRuntime returns a context which is canceled after the maximum runtime.
+func (rl *ResourceLimits) withRuntime(ctx context.Context) (context.Context, context.CancelFunc) {
+	if rl.MaxRuntime <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(rl.MaxRuntime)*time.Second)
+}
+
+// queryError converts errors caused by the exceeded runtime.
+func (rl *ResourceLimits) queryError(ctx context.Context, err error) error {
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return newResourceLimitError(ResourceLimitRuntime, int64(rl.MaxRuntime), 0)
+	}
+	return err
+}
+
+// stream returns a stream which enforces the limits on rows of iter. The
+// context is canceled when the stream is closed. Streams with multiple result
+// sets stay multi set streams.
+func (rl *ResourceLimits) stream(ctx context.Context, cancel context.CancelFunc, iter ResultStream) ResultStream {
+	ls := &limitedStream{ResultStream: iter, limits: rl, ctx: ctx, cancel: cancel}
+	if multi, ok := iter.(MultiResultStream); ok {
+		return &limitedMultiStream{limitedStream: ls, multi: multi}
+	}
+	return ls
+}
+
+// limitedStream enforces resource limits on the underlying stream.
+type limitedStream struct {
+	ResultStream
+	limits *ResourceLimits
+	ctx    context.Context
+	cancel context.CancelFunc
+	// rows of the current result set
+	rows      int
+	truncated bool
+}
+
+// full reports whether the current result set reached the maximum rows.
+func (ls *limitedStream) full() bool {
+	return ls.limits.MaxRows > 0 && ls.rows >= ls.limits.MaxRows
+}
+
+func (ls *limitedStream) HasNext() bool {
+	if !ls.ResultStream.HasNext() {
+		return false
+	}
+	if !ls.full() || !ls.limits.TruncateRows {
+		return true
+	}
+
+	if !ls.truncated {
+		ls.truncated = true
+		if meta := ls.Meta(); meta != nil {
+			meta.Notices = append(meta.Notices,
+				fmt.Sprintf("result truncated to %d rows by the %s resource limit", ls.limits.MaxRows, ResourceLimitRows))
+		}
+	}
+	return false
+}
+
+func (ls *limitedStream) Next() (Row, error) {
+	if ls.full() {
+		return nil, newResourceLimitError(ResourceLimitRows, int64(ls.limits.MaxRows), 0)
+	}
+
+	row, err := ls.ResultStream.Next()
+	if err != nil {
+		return nil, ls.limits.queryError(ls.ctx, err)
+	}
+	ls.rows++
+	return row, nil
+}
+
+func (ls *limitedStream) Close() {
+	ls.ResultStream.Close()
+	ls.cancel()
+}
+
+// limitedMultiStream is a limitedStream over a stream with multiple result
+// sets. Rows are limited per result set.
+type limitedMultiStream struct {
+	*limitedStream
+	multi MultiResultStream
+}
+
+func (ls *limitedMultiStream) NextResultSet() bool {
+	if !ls.multi.NextResultSet() {
+		return false
+	}
+	ls.rows = 0
+	ls.truncated = false
+	return true
+}
@@ -0,0 +1,109 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_ResourceLimits(t *testing.T) {
+	type testCase struct {
+		name          string
+		params        *core.ConnectionParams
+		opts          []mock.AdapterOption
+		expectedLimit core.ResourceLimit
+		expectedRows  []core.Row
+	}
+
+	testCases := []testCase{
+		{
+			name:         "within limits",
+			params:       &core.ConnectionParams{Guarded: true, Limits: core.ResourceLimits{MaxRows: 10}},
+			expectedRows: mock.NewRows(0, 10),
+		},
+		{
+			name:         "unguarded connection",
+			params:       &core.ConnectionParams{Limits: core.ResourceLimits{MaxRows: 3}},
+			expectedRows: mock.NewRows(0, 10),
+		},
+		{
+			name:          "too many rows",
+			params:        &core.ConnectionParams{Guarded: true, Limits: core.ResourceLimits{MaxRows: 3}},
+			expectedLimit: core.ResourceLimitRows,
+		},
+		{
+			name:         "truncated rows",
+			params:       &core.ConnectionParams{Guarded: true, Limits: core.ResourceLimits{MaxRows: 3, TruncateRows: true}},
+			expectedRows: mock.NewRows(0, 3),
+		},
+		{
+			name:          "too many scanned bytes",
+			params:        &core.ConnectionParams{Guarded: true, Limits: core.ResourceLimits{MaxScannedBytes: 1000}},
+			opts:          []mock.AdapterOption{mock.AdapterWithScanEstimate(1001)},
+			expectedLimit: core.ResourceLimitScannedBytes,
+		},
+		{
+			name:         "scanned bytes within limit",
+			params:       &core.ConnectionParams{Guarded: true, Limits: core.ResourceLimits{MaxScannedBytes: 1000}},
+			opts:         []mock.AdapterOption{mock.AdapterWithScanEstimate(1000)},
+			expectedRows: mock.NewRows(0, 10),
+		},
+		{
+			name:   "too long runtime",
+			params: &core.ConnectionParams{Guarded: true, Limits: core.ResourceLimits{MaxRuntime: 1}},
+			opts: []mock.AdapterOption{mock.AdapterWithQuerySideEffect("select", func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			})},
+			expectedLimit: core.ResourceLimitRuntime,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			opts := append(tc.opts, mock.AdapterWithResultStreamOpts(mock.ResultStreamWithMeta(&core.Meta{})))
+			connection, err := core.NewConnection(tc.params, mock.NewAdapter(mock.NewRows(0, 10), opts...))
+			r.NoError(err)
+
+			call := connection.Execute("select", nil)
+			select {
+			case <-call.Done():
+			case <-time.After(5 * time.Second):
+				t.Fatal("call did not finish in expected time")
+			}
+
+			if tc.expectedLimit != "" {
+				r.ErrorIs(call.Err(), core.ErrResourceLimitExceeded)
+
+				var qErr *core.QueryError
+				r.True(errors.As(call.Err(), &qErr))
+				r.Equal(core.ErrorCategoryResourceLimit, qErr.Category)
+				r.Equal(string(tc.expectedLimit), qErr.Code)
+
+				var limitErr *core.ResourceLimitError
+				r.True(errors.As(call.Err(), &limitErr))
+				r.Equal(tc.expectedLimit, limitErr.Limit)
+				return
+			}
+
+			r.NoError(call.Err())
+			result, err := call.GetResult()
+			r.NoError(err)
+			rows, err := result.Rows(0, -1)
+			r.NoError(err)
+			r.Equal(tc.expectedRows, rows)
+
+			if tc.params.Limits.TruncateRows {
+				r.Len(result.Meta().Notices, 1)
+			}
+		})
+	}
+}
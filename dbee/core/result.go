@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -19,6 +20,9 @@ type Result struct {
 	isFilled   bool
 	writeMutex sync.Mutex
 	readMutex  sync.RWMutex
+
+	// duration of the last Format call in nanoseconds
+	formatTime atomic.Int64
 }
 
 // SetIter sets the ResultStream iterator to result.
@@ -85,10 +89,12 @@ func (cr *Result) Format(formatter Formatter, from, to int) ([]byte, error) {
 		ChunkStart: fromAdjusted,
 	}
 
+	start := time.Now()
 	f, err := formatter.Format(cr.header, rows, opts)
 	if err != nil {
 		return nil, fmt.Errorf("formatter.Format: %w", err)
 	}
+	cr.formatTime.Store(int64(time.Since(start)))
 
 	return f, nil
 }
@@ -109,6 +115,22 @@ func (cr *Result) Meta() *Meta {
 	return cr.meta
 }
 
+// FormatTime returns the duration of the last Format call.
+func (cr *Result) FormatTime() time.Duration {
+	return time.Duration(cr.formatTime.Load())
+}
+
+// Checksum returns a hash of header and all rows of the result.
+// Two results with the same contents have the same checksum.
+func (cr *Result) Checksum() (string, error) {
+	rows, err := cr.Rows(0, -1)
+	if err != nil {
+		return "", fmt.Errorf("cr.Rows: %w", err)
+	}
+
+	return ResultChecksum(cr.header, rows, false), nil
+}
+
 func (cr *Result) Rows(from, to int) ([]Row, error) {
 	rows, _, _, err := cr.getRows(from, to)
 	return rows, err
@@ -137,7 +159,7 @@ func (cr *Result) getRows(from, to int) (rows []Row, rangeFrom int, rangeTo int,
 
 	// Wait for drain, available index or timeout
 	for {
-		if cr.isDrained || (to >= 0 && to <= len(cr.rows)) {
+		if cr.isDrained || (cr.meta != nil && cr.meta.Live) || (to >= 0 && to <= len(cr.rows)) {
 			break
 		}
 
@@ -0,0 +1,174 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// defaultMaxRowDiffs is the default number of reported differing rows.
+const defaultMaxRowDiffs = 100
+
+type (
+	// ResultCompareOptions configure a comparison of results.
+	ResultCompareOptions struct {
+		// compare rows regardless of their order (e.g. of queries without
+		// ORDER BY)
+		IgnoreOrder bool
+		// maximum number of reported differing rows, 100 if zero and all if
+		// negative
+		MaxDiffs int
+	}
+
+	// ResultComparison is the outcome of comparing a result with a baseline
+	// (e.g. the result of a refactored query with the one of the original).
+	ResultComparison struct {
+		// the result has the same header and rows as the baseline
+		Passed           bool
+		BaselineChecksum string
+		Checksum         string
+		BaselineHeader   Header
+		Header           Header
+		BaselineRows     int
+		Rows             int
+		// differing rows, up to MaxDiffs
+		Diffs []*RowDiff
+		// number of all differing rows
+		DiffCount int
+	}
+
+	// RowDiff is a row which differs between the baseline and the result.
+	// Missing rows exist in the baseline only, extra rows in the result only.
+	// Rows are changed if they differ at the same position (only if the order
+	// is compared).
+	RowDiff struct {
+		Kind DiffKind
+		// index of the row in the result, in the baseline for missing rows
+		Index    int
+		Baseline Row
+		Result   Row
+	}
+)
+
+// rowKey encodes the row for checksums and comparisons. Values of different
+// types (e.g. 1 and "1") have different keys.
+func rowKey(row Row) string {
+	var b strings.Builder
+	for _, val := range row {
+		fmt.Fprintf(&b, "%T:%v,", val, val)
+	}
+	return b.String()
+}
+
+// ResultChecksum returns a hash of the header and rows. Rows are hashed in
+// their order unless ignoreOrder is set, so the checksum is the same for rows
+// returned in any order. The ordered checksum equals Result.Checksum.
+func ResultChecksum(header Header, rows []Row, ignoreOrder bool) string {
+	keys := make([]string, len(rows))
+	for i, row := range rows {
+		keys[i] = rowKey(row)
+	}
+	if ignoreOrder {
+		sort.Strings(keys)
+	}
+
+	h := sha256.New()
+	for _, col := range header {
+		fmt.Fprintf(h, "%q,", col)
+	}
+	fmt.Fprintln(h)
+	for _, key := range keys {
+		fmt.Fprintln(h, key)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// CompareResults compares rows and headers of the result with the baseline.
+func CompareResults(baseline, result *Result, opts *ResultCompareOptions) (*ResultComparison, error) {
+	if baseline == nil || result == nil {
+		return nil, errors.New("baseline and result are required")
+	}
+	if opts == nil {
+		opts = &ResultCompareOptions{}
+	}
+	maxDiffs := opts.MaxDiffs
+	if maxDiffs == 0 {
+		maxDiffs = defaultMaxRowDiffs
+	}
+
+	baselineRows, err := baseline.Rows(0, -1)
+	if err != nil {
+		return nil, fmt.Errorf("baseline.Rows: %w", err)
+	}
+	rows, err := result.Rows(0, -1)
+	if err != nil {
+		return nil, fmt.Errorf("result.Rows: %w", err)
+	}
+
+	cmp := &ResultComparison{
+		BaselineChecksum: ResultChecksum(baseline.Header(), baselineRows, opts.IgnoreOrder),
+		Checksum:         ResultChecksum(result.Header(), rows, opts.IgnoreOrder),
+		BaselineHeader:   baseline.Header(),
+		Header:           result.Header(),
+		BaselineRows:     len(baselineRows),
+		Rows:             len(rows),
+	}
+	cmp.Passed = cmp.BaselineChecksum == cmp.Checksum
+
+	addDiff := func(diff *RowDiff) {
+		cmp.DiffCount++
+		if maxDiffs < 0 || len(cmp.Diffs) < maxDiffs {
+			cmp.Diffs = append(cmp.Diffs, diff)
+		}
+	}
+
+	if opts.IgnoreOrder {
+		// rows of the result consume equal rows of the baseline, duplicates
+		// are counted
+		remaining := make(map[string][]int, len(baselineRows))
+		for i, row := range baselineRows {
+			key := rowKey(row)
+			remaining[key] = append(remaining[key], i)
+		}
+		var extra []int
+		for i, row := range rows {
+			key := rowKey(row)
+			if indexes := remaining[key]; len(indexes) > 0 {
+				remaining[key] = indexes[1:]
+				continue
+			}
+			extra = append(extra, i)
+		}
+
+		var missing []int
+		for _, indexes := range remaining {
+			missing = append(missing, indexes...)
+		}
+		sort.Ints(missing)
+		for _, i := range missing {
+			addDiff(&RowDiff{Kind: DiffKindMissing, Index: i, Baseline: baselineRows[i]})
+		}
+		for _, i := range extra {
+			addDiff(&RowDiff{Kind: DiffKindExtra, Index: i, Result: rows[i]})
+		}
+
+		return cmp, nil
+	}
+
+	for i := 0; i < max(len(baselineRows), len(rows)); i++ {
+		switch {
+		case i >= len(rows):
+			addDiff(&RowDiff{Kind: DiffKindMissing, Index: i, Baseline: baselineRows[i]})
+		case i >= len(baselineRows):
+			addDiff(&RowDiff{Kind: DiffKindExtra, Index: i, Result: rows[i]})
+		case rowKey(baselineRows[i]) != rowKey(rows[i]):
+			addDiff(&RowDiff{Kind: DiffKindChanged, Index: i, Baseline: baselineRows[i], Result: rows[i]})
+		}
+	}
+
+	return cmp, nil
+}
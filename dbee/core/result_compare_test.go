@@ -0,0 +1,121 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func newTestResult(t *testing.T, rows []core.Row) *core.Result {
+	t.Helper()
+
+	result := new(core.Result)
+	require.NoError(t, result.SetIter(mock.NewResultStream(rows), nil))
+	return result
+}
+
+func TestResultChecksum(t *testing.T) {
+	r := require.New(t)
+
+	header := core.Header{"id", "name"}
+	rows := []core.Row{{1, "a"}, {2, "b"}, {3, "c"}}
+	reversed := []core.Row{{3, "c"}, {2, "b"}, {1, "a"}}
+
+	r.Equal(core.ResultChecksum(header, rows, false), core.ResultChecksum(header, rows, false))
+	r.NotEqual(core.ResultChecksum(header, rows, false), core.ResultChecksum(header, reversed, false))
+	r.Equal(core.ResultChecksum(header, rows, true), core.ResultChecksum(header, reversed, true))
+	r.NotEqual(core.ResultChecksum(header, rows, true), core.ResultChecksum(core.Header{"id", "title"}, rows, true))
+	// values of different types differ
+	r.NotEqual(core.ResultChecksum(header, []core.Row{{1, "a"}}, false), core.ResultChecksum(header, []core.Row{{"1", "a"}}, false))
+
+	result := newTestResult(t, mock.NewRows(0, 10))
+	sum, err := result.Checksum()
+	r.NoError(err)
+	r.Equal(core.ResultChecksum(result.Header(), mock.NewRows(0, 10), false), sum)
+}
+
+func TestCompareResults(t *testing.T) {
+	type testCase struct {
+		name           string
+		baseline       []core.Row
+		result         []core.Row
+		opts           *core.ResultCompareOptions
+		expectedPassed bool
+		expectedDiffs  []*core.RowDiff
+		expectedCount  int
+	}
+
+	testCases := []testCase{
+		{
+			name:           "identical",
+			baseline:       mock.NewRows(0, 5),
+			result:         mock.NewRows(0, 5),
+			expectedPassed: true,
+		},
+		{
+			name:     "different order",
+			baseline: []core.Row{{1, "a"}, {2, "b"}},
+			result:   []core.Row{{2, "b"}, {1, "a"}},
+			expectedDiffs: []*core.RowDiff{
+				{Kind: core.DiffKindChanged, Index: 0, Baseline: core.Row{1, "a"}, Result: core.Row{2, "b"}},
+				{Kind: core.DiffKindChanged, Index: 1, Baseline: core.Row{2, "b"}, Result: core.Row{1, "a"}},
+			},
+			expectedCount: 2,
+		},
+		{
+			name:           "different order ignored",
+			baseline:       []core.Row{{1, "a"}, {2, "b"}},
+			result:         []core.Row{{2, "b"}, {1, "a"}},
+			opts:           &core.ResultCompareOptions{IgnoreOrder: true},
+			expectedPassed: true,
+		},
+		{
+			name:     "missing and extra rows",
+			baseline: []core.Row{{1, "a"}, {2, "b"}, {2, "b"}},
+			result:   []core.Row{{2, "b"}, {3, "c"}, {1, "a"}},
+			opts:     &core.ResultCompareOptions{IgnoreOrder: true},
+			expectedDiffs: []*core.RowDiff{
+				{Kind: core.DiffKindMissing, Index: 2, Baseline: core.Row{2, "b"}},
+				{Kind: core.DiffKindExtra, Index: 1, Result: core.Row{3, "c"}},
+			},
+			expectedCount: 2,
+		},
+		{
+			name:     "ordered length difference",
+			baseline: mock.NewRows(0, 2),
+			result:   mock.NewRows(0, 3),
+			expectedDiffs: []*core.RowDiff{
+				{Kind: core.DiffKindExtra, Index: 2, Result: core.Row{2, "row_2"}},
+			},
+			expectedCount: 1,
+		},
+		{
+			name:     "limited diffs",
+			baseline: mock.NewRows(0, 5),
+			result:   mock.NewRows(5, 10),
+			opts:     &core.ResultCompareOptions{IgnoreOrder: true, MaxDiffs: 1},
+			expectedDiffs: []*core.RowDiff{
+				{Kind: core.DiffKindMissing, Index: 0, Baseline: core.Row{0, "row_0"}},
+			},
+			expectedCount: 10,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			cmp, err := core.CompareResults(newTestResult(t, tc.baseline), newTestResult(t, tc.result), tc.opts)
+			r.NoError(err)
+			r.Equal(tc.expectedPassed, cmp.Passed)
+			r.Equal(tc.expectedPassed, cmp.Checksum == cmp.BaselineChecksum)
+			r.Equal(tc.expectedDiffs, cmp.Diffs)
+			r.Equal(tc.expectedCount, cmp.DiffCount)
+			r.Equal(len(tc.baseline), cmp.BaselineRows)
+			r.Equal(len(tc.result), cmp.Rows)
+		})
+	}
+}
@@ -1,12 +1,15 @@
 package core_test
 
 import (
+	"bytes"
+	"context"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/format"
 	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
 )
 
@@ -109,3 +112,37 @@ func TestResult(t *testing.T) {
 		})
 	}
 }
+
+func TestResult_Export(t *testing.T) {
+	r := require.New(t)
+
+	result := new(core.Result)
+	err := result.SetIter(mock.NewResultStream(mock.NewRows(0, 2500)), nil)
+	r.NoError(err)
+
+	for _, formatter := range []core.Formatter{format.NewCSV(), format.NewJSON()} {
+		expected, err := result.Format(formatter, 0, -1)
+		r.NoError(err)
+
+		// chunked output is the same as formatting all rows at once
+		var progress []*core.ExportProgress
+		var b bytes.Buffer
+		err = result.Export(context.Background(), &b, formatter, 0, -1, func(p *core.ExportProgress) {
+			progress = append(progress, p)
+		})
+		r.NoError(err)
+		r.Equal(string(expected), b.String())
+
+		r.Len(progress, 3)
+		r.Equal(1000, progress[0].Rows)
+		r.Equal(&core.ExportProgress{Rows: 2500, RowsTotal: 2500, BytesWritten: int64(len(expected))}, progress[2])
+	}
+
+	// canceled exports stop writing
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var b bytes.Buffer
+	err = result.Export(ctx, &b, format.NewCSV(), 0, -1, nil)
+	r.ErrorIs(err, context.Canceled)
+	r.Zero(b.Len())
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	retryBaseDelay = 200 * time.Millisecond
+	retryMaxDelay  = 5 * time.Second
+)
+
+var retryIntoRe = regexp.MustCompile(`(?i)\binto\b`)
+
+// TransientErrorClassifier is an optional interface for drivers that can recognize
+// transient errors, which guarantee that the statement had no effect (serialization
+// failures, deadlocks, ...). Single statements which fail with a transient error can
+// be retried - earlier statements of a batch might have been executed already.
+type TransientErrorClassifier interface {
+	IsTransient(err error) bool
+}
+
+// ConnectionErrorClassifier is an optional interface for drivers that can recognize
+// errors of dropped connections. Statements which fail with them might have been
+// executed, so only read-only statements are retried.
+type ConnectionErrorClassifier interface {
+	IsConnectionError(err error) bool
+}
+
+// retryDelay returns the backoff delay before the n-th retry (starting with 1).
+func retryDelay(n int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < n; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+	return delay
+}
+
+// queryWithRetry runs the query and retries it up to retries times if it is a single
+// statement which fails with a transient error, or a single read-only statement which
+// fails with a connection error. It returns the number of retries that were performed.
+func queryWithRetry(ctx context.Context, driver Driver, query string, retries int) (ResultStream, int, error) {
+	transient, _ := driver.(TransientErrorClassifier)
+	connection, _ := driver.(ConnectionErrorClassifier)
+	statements := splitStatements(query)
+	single := len(statements) == 1
+	readOnly := single && readOnlyStatement(statements[0])
+
+	retryable := func(err error) bool {
+		if single && transient != nil && transient.IsTransient(err) {
+			return true
+		}
+		return readOnly && connection != nil && connection.IsConnectionError(err)
+	}
+
+	for n := 0; ; n++ {
+		rows, err := driver.Query(ctx, query)
+		if err == nil {
+			return rows, n, nil
+		}
+		if n >= retries || !retryable(err) {
+			return nil, n, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, n, ctx.Err()
+		case <-time.After(retryDelay(n + 1)):
+		}
+	}
+}
+
+// splitStatements returns non-empty normalized statements of the query.
+func splitStatements(query string) []string {
+	var statements []string
+	for _, stmt := range strings.Split(normalizeStatement(query), ";") {
+		if strings.TrimSpace(stmt) != "" {
+			statements = append(statements, stmt)
+		}
+	}
+	return statements
+}
+
+// readOnlyStatement reports whether the normalized statement only reads data.
+// Functions called by queries aren't known, so e.g. "SELECT nextval(...)" is
+// still considered read-only.
+func readOnlyStatement(stmt string) bool {
+	bodies, main := splitCTEs(stmt)
+	for _, body := range bodies {
+		if !readOnlyStatement(body) {
+			return false
+		}
+	}
+
+	match := guardFirstWordRe.FindStringSubmatch(main)
+	if match == nil {
+		return false
+	}
+
+	switch strings.ToLower(match[1]) {
+	case "select":
+		// SELECT ... INTO creates a table (or assigns variables)
+		return !retryIntoRe.MatchString(main)
+	case "show", "describe", "desc", "values", "table":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+var (
+	errTransient  = errors.New("deadlock detected")
+	errConnection = errors.New("connection reset by peer")
+)
+
+func TestConnection_Retry(t *testing.T) {
+	type testCase struct {
+		name            string
+		query           string
+		err             error
+		retries         int
+		failures        int
+		expectedError   bool
+		expectedRetries int
+	}
+
+	testCases := []testCase{
+		{
+			name:            "no failures",
+			retries:         3,
+			failures:        0,
+			expectedRetries: 0,
+		},
+		{
+			name:            "recovers after retries",
+			retries:         3,
+			failures:        2,
+			expectedRetries: 2,
+		},
+		{
+			name:          "exceeds retries",
+			retries:       1,
+			failures:      2,
+			expectedError: true,
+		},
+		{
+			name:          "retrying disabled",
+			retries:       0,
+			failures:      1,
+			expectedError: true,
+		},
+		{
+			name:            "transient error of a write",
+			query:           "UPDATE t SET a = 1",
+			retries:         3,
+			failures:        1,
+			expectedRetries: 1,
+		},
+		{
+			name:          "transient error of a batch",
+			query:         "UPDATE t SET a = 1; UPDATE u SET b = 2 WHERE id = 1",
+			retries:       3,
+			failures:      1,
+			expectedError: true,
+		},
+		{
+			name:            "connection error of a read",
+			query:           "WITH x AS (SELECT 1) SELECT * FROM x",
+			err:             errConnection,
+			retries:         3,
+			failures:        2,
+			expectedRetries: 2,
+		},
+		{
+			name:          "connection error of a write",
+			query:         "UPDATE t SET a = 1",
+			err:           errConnection,
+			retries:       3,
+			failures:      1,
+			expectedError: true,
+		},
+		{
+			name:          "connection error of a data-modifying cte",
+			query:         "WITH d AS (DELETE FROM t RETURNING *) SELECT * FROM d",
+			err:           errConnection,
+			retries:       3,
+			failures:      1,
+			expectedError: true,
+		},
+		{
+			name:          "connection error of select into",
+			query:         "SELECT * INTO t2 FROM t",
+			err:           errConnection,
+			retries:       3,
+			failures:      1,
+			expectedError: true,
+		},
+		{
+			name:          "connection error of multiple statements",
+			query:         "SELECT 1; SELECT 2",
+			err:           errConnection,
+			retries:       3,
+			failures:      1,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			query := tc.query
+			if query == "" {
+				query = "_"
+			}
+			queryErr := tc.err
+			if queryErr == nil {
+				queryErr = errTransient
+			}
+
+			failures := tc.failures
+			adapter := mock.NewAdapter(mock.NewRows(0, 3),
+				mock.AdapterWithQuerySideEffect(query, func(context.Context) error {
+					if failures > 0 {
+						failures--
+						return queryErr
+					}
+					return nil
+				}),
+				mock.AdapterWithTransientErrors(func(err error) bool {
+					return errors.Is(err, errTransient)
+				}),
+				mock.AdapterWithConnectionErrors(func(err error) bool {
+					return errors.Is(err, errConnection)
+				}),
+			)
+
+			connection, err := core.NewConnection(&core.ConnectionParams{Retries: tc.retries}, adapter)
+			r.NoError(err)
+
+			call := connection.Execute(query, nil)
+			select {
+			case <-call.Done():
+			case <-time.After(5 * time.Second):
+				t.Fatal("call did not finish in expected time")
+			}
+
+			if tc.expectedError {
+				r.ErrorIs(call.Err(), queryErr)
+				return
+			}
+			r.NoError(call.Err())
+
+			result, err := call.GetResult()
+			r.NoError(err)
+			r.Equal(tc.expectedRetries, result.Meta().Retries)
+		})
+	}
+}
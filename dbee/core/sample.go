@@ -0,0 +1,178 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrSamplingNotSupported is returned if the query isn't a single SELECT
+// statement, which is the only kind of queries that can be sampled.
+var ErrSamplingNotSupported = errors.New("only single SELECT statements can be sampled")
+
+const (
+	// percentage of rows of a table sampled if none is set
+	defaultSamplePercent = 1
+	// maximum number of sampled rows if none is set
+	defaultSampleRows = 1000
+)
+
+// SampleOptions configure sampled execution of a query.
+type SampleOptions struct {
+	// percentage of rows of the table read by table sampling (defaultSamplePercent if 0)
+	Percent float64
+	// maximum number of returned rows (defaultSampleRows if 0)
+	Rows int
+}
+
+// SampleSyntax is the way a database samples rows.
+type SampleSyntax struct {
+	// clause placed after the table of single table queries, with %s replaced
+	// by the percentage (e.g. "TABLESAMPLE SYSTEM (%s)"). Tables are read
+	// partially, so it's used whenever possible. Empty if tables can't be sampled.
+	TableSample string
+	// the percentage is written as a ratio between 0 and 1
+	Ratio bool
+	// the clause goes between the table and its alias
+	BeforeAlias bool
+	// expression of a random number (e.g. "RANDOM()") used to pick random rows
+	// of other queries, which are read in full
+	Random string
+}
+
+// SampleDialect is an optional interface for drivers which don't sample rows
+// the way of the default sample syntax (random rows ordered by RANDOM()).
+type SampleDialect interface {
+	SampleSyntax() SampleSyntax
+}
+
+var defaultSampleSyntax = SampleSyntax{
+	Random: "RANDOM()",
+}
+
+var (
+	sampleIdentifier = "(?:[\\w$]+|\"[^\"]+\"|`[^`]+`|\\[[^\\]]+\\])"
+	// SELECT ... FROM table [AS alias] [WHERE ...|GROUP BY ...|...]
+	sampleTableRe = regexp.MustCompile(`(?is)^(.*?\bfrom\s+)(` + sampleIdentifier + `(?:\s*\.\s*` + sampleIdentifier + `)*)` +
+		`((?:\s+as)?\s+[\w$]+)?` +
+		`(\s+(?:where|group|order|limit|having|fetch|offset|window|qualify)\b.*)?$`)
+	sampleFromRe     = regexp.MustCompile(`(?i)\bfrom\b`)
+	sampleCompoundRe = regexp.MustCompile(`(?i)\b(union|intersect|except|minus)\b`)
+	sampleKeywordRe  = regexp.MustCompile(`(?i)^(where|group|order|limit|having|fetch|offset|window|qualify|join|inner|left|right|full|cross|natural)$`)
+)
+
+// SampleQuery rewrites the query so that it returns a sample of its rows.
+// Single table queries use table sampling of the syntax, which doesn't scan
+// the whole table, other queries pick random rows of their result. It returns
+// the rewritten query and a description of the sampling method.
+func SampleQuery(query string, opts *SampleOptions, syntax SampleSyntax, limitSyntax LimitSyntax) (string, string, error) {
+	percent, rows := float64(defaultSamplePercent), defaultSampleRows
+	if opts != nil {
+		if opts.Percent != 0 {
+			percent = opts.Percent
+		}
+		if opts.Rows != 0 {
+			rows = opts.Rows
+		}
+	}
+	if percent <= 0 || percent > 100 {
+		return "", "", fmt.Errorf("sample percentage out of range: %g", percent)
+	}
+	if rows < 0 {
+		return "", "", fmt.Errorf("invalid number of sampled rows: %d", rows)
+	}
+
+	normalized := strings.TrimRight(normalizeStatement(query), "; ")
+	first := strings.ToLower(strings.SplitN(normalized, " ", 2)[0])
+	if strings.Contains(normalized, ";") || (first != "select" && first != "with") {
+		return "", "", ErrSamplingNotSupported
+	}
+
+	trimmed := trimStatement(query)
+
+	if sampled, table, ok := sampleTable(trimmed, normalized, percent, syntax); ok {
+		method := fmt.Sprintf("%s of %s", strings.TrimSpace(fmt.Sprintf(syntax.TableSample, samplePercent(percent, syntax.Ratio))), table)
+		if limited, injected := InjectLimit(sampled, rows, limitSyntax); injected {
+			sampled = limited
+			method += fmt.Sprintf(", at most %d rows", rows)
+		}
+		return sampled, method, nil
+	}
+
+	random := syntax.Random
+	if random == "" {
+		random = defaultSampleSyntax.Random
+	}
+	sampled, ok := appendLimit(fmt.Sprintf("SELECT * FROM (\n%s\n) dbee_sample\nORDER BY %s", trimmed, random), rows, limitSyntax)
+	if !ok {
+		return "", "", ErrSamplingNotSupported
+	}
+	return sampled, fmt.Sprintf("%d random rows (ORDER BY %s)", rows, random), nil
+}
+
+// sampleTable adds the table sample clause to single table SELECT statements.
+// It returns the rewritten query and the sampled table.
+func sampleTable(trimmed, normalized string, percent float64, syntax SampleSyntax) (string, string, bool) {
+	if syntax.TableSample == "" {
+		return "", "", false
+	}
+	// "from" in comments or literals would be matched instead of the table
+	if len(sampleFromRe.FindAllStringIndex(normalized, -1)) != 1 || len(sampleFromRe.FindAllStringIndex(trimmed, -1)) != 1 {
+		return "", "", false
+	}
+	if strings.HasPrefix(strings.ToLower(normalized), "with") || sampleCompoundRe.MatchString(normalized) {
+		return "", "", false
+	}
+
+	m := sampleTableRe.FindStringSubmatch(trimmed)
+	if m == nil {
+		return "", "", false
+	}
+	prefix, table, alias, rest := m[1], m[2], m[3], m[4]
+	if fields := strings.Fields(alias); len(fields) > 0 && sampleKeywordRe.MatchString(fields[len(fields)-1]) {
+		return "", "", false
+	}
+
+	clause := " " + fmt.Sprintf(syntax.TableSample, samplePercent(percent, syntax.Ratio))
+	if syntax.BeforeAlias {
+		return prefix + table + clause + alias + rest, table, true
+	}
+	return prefix + table + alias + clause + rest, table, true
+}
+
+func samplePercent(percent float64, ratio bool) string {
+	if ratio {
+		percent /= 100
+	}
+	return strconv.FormatFloat(percent, 'f', -1, 64)
+}
+
+// ExecuteSample executes the query on a sample of rows (see SampleQuery). The
+// sampling method is noted in the meta of the result.
+func (c *Connection) ExecuteSample(query string, opts *SampleOptions, confirmed bool, onEvent func(CallState, *Call)) (*Call, error) {
+	syntax := defaultSampleSyntax
+	if dialect, ok := c.driver.(SampleDialect); ok {
+		syntax = dialect.SampleSyntax()
+	}
+	sampled, method, err := SampleQuery(query, opts, syntax, c.Dialect().LimitSyntax())
+	if err != nil {
+		return nil, err
+	}
+
+	executor := c.executor(sampled, confirmed)
+	exec := func(ctx context.Context) (ResultStream, error) {
+		iter, err := executor(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if meta := iter.Meta(); meta != nil {
+			meta.Sampling = method
+		}
+		return iter, nil
+	}
+
+	return newCallFromExecutor(exec, query, onEvent), nil
+}
@@ -0,0 +1,116 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestSampleQuery(t *testing.T) {
+	postgres := core.SampleSyntax{TableSample: "TABLESAMPLE SYSTEM (%s)", Random: "RANDOM()"}
+	oracle := core.SampleSyntax{TableSample: "SAMPLE (%s)", BeforeAlias: true, Random: "DBMS_RANDOM.VALUE"}
+	ratio := core.SampleSyntax{TableSample: "SAMPLE %s", Ratio: true, Random: "rand()"}
+
+	type testCase struct {
+		name           string
+		query          string
+		opts           *core.SampleOptions
+		syntax         core.SampleSyntax
+		limitSyntax    core.LimitSyntax
+		expected       string
+		expectedMethod string
+	}
+
+	testCases := []testCase{
+		{
+			name:           "table sample",
+			query:          "SELECT * FROM public.orders;",
+			opts:           &core.SampleOptions{Percent: 5, Rows: 100},
+			syntax:         postgres,
+			expected:       "SELECT * FROM public.orders TABLESAMPLE SYSTEM (5)\nLIMIT 100",
+			expectedMethod: "TABLESAMPLE SYSTEM (5) of public.orders, at most 100 rows",
+		},
+		{
+			name:           "alias and where clause",
+			query:          "select o.id from orders as o where o.total > 10",
+			syntax:         postgres,
+			expected:       "select o.id from orders as o TABLESAMPLE SYSTEM (1) where o.total > 10\nLIMIT 1000",
+			expectedMethod: "TABLESAMPLE SYSTEM (1) of orders, at most 1000 rows",
+		},
+		{
+			name:           "clause before alias",
+			query:          "SELECT * FROM orders o WHERE o.total > 10",
+			opts:           &core.SampleOptions{Percent: 0.5, Rows: 10},
+			syntax:         oracle,
+			limitSyntax:    core.LimitSyntaxFetchFirst,
+			expected:       "SELECT * FROM orders SAMPLE (0.5) o WHERE o.total > 10\nFETCH FIRST 10 ROWS ONLY",
+			expectedMethod: "SAMPLE (0.5) of orders, at most 10 rows",
+		},
+		{
+			name:           "ratio",
+			query:          "SELECT * FROM hits",
+			opts:           &core.SampleOptions{Percent: 10, Rows: 10},
+			syntax:         ratio,
+			expected:       "SELECT * FROM hits SAMPLE 0.1\nLIMIT 10",
+			expectedMethod: "SAMPLE 0.1 of hits, at most 10 rows",
+		},
+		{
+			name:           "existing limit is kept",
+			query:          "SELECT * FROM orders LIMIT 5",
+			syntax:         postgres,
+			expected:       "SELECT * FROM orders TABLESAMPLE SYSTEM (1) LIMIT 5",
+			expectedMethod: "TABLESAMPLE SYSTEM (1) of orders",
+		},
+		{
+			name:           "joins pick random rows",
+			query:          "SELECT * FROM a JOIN b ON a.id = b.id",
+			opts:           &core.SampleOptions{Rows: 50},
+			syntax:         postgres,
+			expected:       "SELECT * FROM (\nSELECT * FROM a JOIN b ON a.id = b.id\n) dbee_sample\nORDER BY RANDOM()\nLIMIT 50",
+			expectedMethod: "50 random rows (ORDER BY RANDOM())",
+		},
+		{
+			name:           "from in a literal",
+			query:          "SELECT 'from x' AS a FROM t",
+			opts:           &core.SampleOptions{Rows: 50},
+			syntax:         postgres,
+			expected:       "SELECT * FROM (\nSELECT 'from x' AS a FROM t\n) dbee_sample\nORDER BY RANDOM()\nLIMIT 50",
+			expectedMethod: "50 random rows (ORDER BY RANDOM())",
+		},
+		{
+			name:           "no table sampling",
+			query:          "SELECT * FROM orders",
+			opts:           &core.SampleOptions{Rows: 50},
+			syntax:         core.SampleSyntax{Random: "NEWID()"},
+			limitSyntax:    core.LimitSyntaxTop,
+			expected:       "SELECT TOP 50 * FROM (\nSELECT * FROM orders\n) dbee_sample\nORDER BY NEWID()",
+			expectedMethod: "50 random rows (ORDER BY NEWID())",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			sampled, method, err := core.SampleQuery(tc.query, tc.opts, tc.syntax, tc.limitSyntax)
+			r.NoError(err)
+			r.Equal(tc.expected, sampled)
+			r.Equal(tc.expectedMethod, method)
+		})
+	}
+}
+
+func TestSampleQuery_Errors(t *testing.T) {
+	r := require.New(t)
+
+	_, _, err := core.SampleQuery("DELETE FROM orders", nil, core.SampleSyntax{}, core.LimitSyntaxLimit)
+	r.ErrorIs(err, core.ErrSamplingNotSupported)
+
+	_, _, err = core.SampleQuery("SELECT 1; SELECT 2", nil, core.SampleSyntax{}, core.LimitSyntaxLimit)
+	r.ErrorIs(err, core.ErrSamplingNotSupported)
+
+	_, _, err = core.SampleQuery("SELECT * FROM orders", &core.SampleOptions{Percent: 150}, core.SampleSyntax{}, core.LimitSyntaxLimit)
+	r.Error(err)
+}
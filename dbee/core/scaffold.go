@@ -0,0 +1,77 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Names of query scaffolds
+const (
+	ScaffoldSelect = "select"
+	ScaffoldInsert = "insert"
+	ScaffoldUpdate = "update"
+)
+
+// Scaffold is a ready-to-edit statement for a database object.
+type Scaffold struct {
+	Name  string
+	Query string
+}
+
+// GetScaffolds returns statements built from columns of the object described
+// by opts: a SELECT of all columns for tables and views, an INSERT template
+// for tables and an UPDATE by primary key for tables which have one.
+// Values to fill in are NULL placeholders followed by a comment with the name
+// and the type of the column.
+func (c *Connection) GetScaffolds(opts *TableOptions) ([]*Scaffold, error) {
+	columns, err := c.GetColumns(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	dialect := c.Dialect()
+	quote := dialect.QuoteIdentifier
+	name := dialect.QualifiedName(opts.Schema, opts.Table)
+
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = quote(col.Name)
+	}
+	placeholder := func(col *Column) string {
+		return fmt.Sprintf("NULL /* %s %s */", col.Name, col.Type)
+	}
+
+	query, _ := dialect.InjectLimit("SELECT\n  "+strings.Join(names, ",\n  ")+"\nFROM "+name, c.previewLimit())
+
+	scaffolds := []*Scaffold{{Name: ScaffoldSelect, Query: query}}
+	if opts.Materialization != StructureTypeTable {
+		return scaffolds, nil
+	}
+
+	values := make([]string, len(columns))
+	var sets, conds []string
+	for i, col := range columns {
+		values[i] = placeholder(col)
+		if col.PrimaryKey {
+			conds = append(conds, names[i]+" = "+placeholder(col))
+		} else {
+			sets = append(sets, names[i]+" = "+placeholder(col))
+		}
+	}
+
+	scaffolds = append(scaffolds, &Scaffold{
+		Name: ScaffoldInsert,
+		Query: fmt.Sprintf("INSERT INTO %s (\n  %s\n) VALUES (\n  %s\n)",
+			name, strings.Join(names, ",\n  "), strings.Join(values, ",\n  ")),
+	})
+
+	if len(conds) > 0 && len(sets) > 0 {
+		scaffolds = append(scaffolds, &Scaffold{
+			Name: ScaffoldUpdate,
+			Query: fmt.Sprintf("UPDATE %s SET\n  %s\nWHERE %s",
+				name, strings.Join(sets, ",\n  "), strings.Join(conds, " AND ")),
+		})
+	}
+
+	return scaffolds, nil
+}
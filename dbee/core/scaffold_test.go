@@ -0,0 +1,55 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_GetScaffolds(t *testing.T) {
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 1),
+		mock.AdapterWithTableDefinition("users", []*core.Column{
+			{Name: "id", Type: "int", PrimaryKey: true},
+			{Name: "email", Type: "text"},
+		}),
+	)
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	scaffolds, err := connection.GetScaffolds(&core.TableOptions{
+		Table:           "users",
+		Schema:          "public",
+		Materialization: core.StructureTypeTable,
+	})
+	r.NoError(err)
+	r.Equal([]*core.Scaffold{
+		{
+			Name:  core.ScaffoldSelect,
+			Query: "SELECT\n  \"id\",\n  \"email\"\nFROM \"public\".\"users\"\nLIMIT 500",
+		},
+		{
+			Name:  core.ScaffoldInsert,
+			Query: "INSERT INTO \"public\".\"users\" (\n  \"id\",\n  \"email\"\n) VALUES (\n  NULL /* id int */,\n  NULL /* email text */\n)",
+		},
+		{
+			Name:  core.ScaffoldUpdate,
+			Query: "UPDATE \"public\".\"users\" SET\n  \"email\" = NULL /* email text */\nWHERE \"id\" = NULL /* id int */",
+		},
+	}, scaffolds)
+
+	// views can only be selected
+	scaffolds, err = connection.GetScaffolds(&core.TableOptions{
+		Table:           "users",
+		Materialization: core.StructureTypeView,
+	})
+	r.NoError(err)
+	r.Len(scaffolds, 1)
+
+	_, err = connection.GetScaffolds(&core.TableOptions{Table: "unknown"})
+	r.Error(err)
+}
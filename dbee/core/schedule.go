@@ -0,0 +1,172 @@
+package core
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidScheduleInterval = errors.New("schedule interval must be positive")
+
+type ScheduleID string
+
+// Schedule re-runs a query on a fixed interval. Each run produces a regular call,
+// so every run ends up in call history.
+type Schedule struct {
+	id       ScheduleID
+	connID   ConnectionID
+	query    string
+	interval time.Duration
+
+	execute func() (*Call, error)
+	// called with the number of the run and its call when the call finished
+	onFinished func(int, *Call)
+
+	mu           sync.Mutex
+	runs         int
+	lastCallID   CallID
+	lastChecksum string
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewSchedule creates a new schedule and starts it immediately.
+// execute is called on every tick and should return a call which was started by it.
+// onChange is called whenever the result of a finished run differs from the
+// result of the previous one.
+func NewSchedule(connID ConnectionID, query string, interval time.Duration, execute func() (*Call, error), onChange func(*Schedule, *Call)) (*Schedule, error) {
+	s, err := newSchedule(connID, query, interval, execute)
+	if err != nil {
+		return nil, err
+	}
+	s.onFinished = func(_ int, call *Call) {
+		if s.resultChanged(call) && onChange != nil {
+			onChange(s, call)
+		}
+	}
+
+	go s.loop()
+
+	return s, nil
+}
+
+// newSchedule creates a schedule which isn't started yet. Runs are reported to
+// onFinished, which has to be set before the schedule is started with loop.
+func newSchedule(connID ConnectionID, query string, interval time.Duration, execute func() (*Call, error)) (*Schedule, error) {
+	if interval <= 0 {
+		return nil, ErrInvalidScheduleInterval
+	}
+	if execute == nil {
+		return nil, errors.New("no executor provided")
+	}
+
+	return &Schedule{
+		id:       ScheduleID(uuid.New().String()),
+		connID:   connID,
+		query:    query,
+		interval: interval,
+
+		execute: execute,
+
+		stop: make(chan struct{}),
+	}, nil
+}
+
+func (s *Schedule) loop() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.run()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.run()
+		}
+	}
+}
+
+func (s *Schedule) run() {
+	call, err := s.execute()
+	if err != nil || call == nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.runs++
+	run := s.runs
+	s.lastCallID = call.GetID()
+	s.mu.Unlock()
+
+	// wait for the call to finish, but don't overlap with the next run
+	select {
+	case <-call.Done():
+	case <-s.stop:
+		call.Cancel()
+		return
+	}
+
+	s.onFinished(run, call)
+}
+
+// resultChanged reports whether the result of the finished call differs from
+// the result of the previous successful run.
+func (s *Schedule) resultChanged(call *Call) bool {
+	if call.GetState() != CallStateArchived {
+		return false
+	}
+
+	result, err := call.GetResult()
+	if err != nil {
+		return false
+	}
+	sum, err := result.Checksum()
+	if err != nil {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := s.lastChecksum != "" && s.lastChecksum != sum
+	s.lastChecksum = sum
+	return changed
+}
+
+// Stop stops the schedule. Calls which are still executing are canceled.
+func (s *Schedule) Stop() {
+	s.once.Do(func() { close(s.stop) })
+}
+
+func (s *Schedule) GetID() ScheduleID {
+	return s.id
+}
+
+func (s *Schedule) GetConnectionID() ConnectionID {
+	return s.connID
+}
+
+func (s *Schedule) GetQuery() string {
+	return s.query
+}
+
+func (s *Schedule) GetInterval() time.Duration {
+	return s.interval
+}
+
+// GetRuns returns the number of runs so far.
+func (s *Schedule) GetRuns() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.runs
+}
+
+// GetLastCallID returns the id of the call produced by the latest run.
+func (s *Schedule) GetLastCallID() CallID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastCallID
+}
@@ -0,0 +1,48 @@
+package core_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestSchedule(t *testing.T) {
+	r := require.New(t)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(mock.NewRows(0, 10)))
+	r.NoError(err)
+
+	var changes atomic.Int32
+	schedule, err := core.NewSchedule(connection.GetID(), "_", 100*time.Millisecond,
+		func() (*core.Call, error) {
+			return connection.Execute("_", nil), nil
+		},
+		func(*core.Schedule, *core.Call) {
+			changes.Add(1)
+		})
+	r.NoError(err)
+
+	time.Sleep(550 * time.Millisecond)
+	schedule.Stop()
+
+	runs := schedule.GetRuns()
+	r.GreaterOrEqual(runs, 3)
+	r.NotEmpty(schedule.GetLastCallID())
+
+	// result is always the same
+	r.Equal(int32(0), changes.Load())
+
+	// no runs after stop
+	time.Sleep(300 * time.Millisecond)
+	r.Equal(runs, schedule.GetRuns())
+}
+
+func TestSchedule_InvalidInterval(t *testing.T) {
+	_, err := core.NewSchedule("", "_", 0, func() (*core.Call, error) { return nil, nil }, nil)
+	require.ErrorIs(t, err, core.ErrInvalidScheduleInterval)
+}
@@ -0,0 +1,208 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type (
+	// SchemaExportOptions describe an export of object definitions to files.
+	SchemaExportOptions struct {
+		// file (or directory if Split is set) where definitions are written
+		Path string
+		// write every object to its own file: Path/<schema>/<type>/<name>.sql
+		Split bool
+		// schema of exported objects, all schemas if empty
+		Schema string
+		// objects to export, all objects of the structure if empty
+		Objects []*TableOptions
+		// include system schemas and objects (see StructureOptions)
+		IncludeSystem bool
+	}
+
+	// SchemaExport is the outcome of a schema export.
+	SchemaExport struct {
+		// number of exported objects
+		Exported int
+		// objects whose definitions can't be retrieved (e.g. unsupported kinds)
+		Skipped []string
+		// written files
+		Files []string
+	}
+)
+
+// schemaExportOrder orders objects so that a single file can be run from top
+// to bottom (e.g. sequences are created before tables using them).
+var schemaExportOrder = map[StructureType]int{
+	StructureTypeSequence:         0,
+	StructureTypeType:             1,
+	StructureTypeFunction:         2,
+	StructureTypeProcedure:        3,
+	StructureTypeTable:            4,
+	StructureTypeView:             5,
+	StructureTypeMaterializedView: 6,
+	StructureTypeTrigger:          7,
+}
+
+// ExportSchema writes definitions (CREATE statements) of objects on the
+// connection to a file or to a directory tree, similar to a schema-only dump.
+// Objects whose definitions can't be retrieved are skipped. Indexes are
+// exported as part of table definitions if the driver includes them.
+func (c *Connection) ExportSchema(opts *SchemaExportOptions) (*SchemaExport, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("opts cannot be nil")
+	}
+	if opts.Path == "" {
+		return nil, errors.New("no export path provided")
+	}
+	if _, ok := c.driver.(DefinitionProvider); !ok {
+		return nil, ErrDefinitionNotSupported
+	}
+
+	objects := opts.Objects
+	if len(objects) == 0 {
+		var err error
+		objects, err = c.exportedObjects(opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(objects, func(i, j int) bool {
+		oi, ok := schemaExportOrder[objects[i].Materialization]
+		if !ok {
+			oi = len(schemaExportOrder)
+		}
+		oj, ok := schemaExportOrder[objects[j].Materialization]
+		if !ok {
+			oj = len(schemaExportOrder)
+		}
+		if oi != oj {
+			return oi < oj
+		}
+		if objects[i].Schema != objects[j].Schema {
+			return objects[i].Schema < objects[j].Schema
+		}
+		return objects[i].Table < objects[j].Table
+	})
+
+	export := &SchemaExport{}
+	var single strings.Builder
+	seen := make(map[string]struct{}, len(objects))
+
+	for _, object := range objects {
+		name := object.Table
+		if object.Schema != "" {
+			name = object.Schema + "." + name
+		}
+		// overloaded routines share the definition
+		key := object.Materialization.String() + ":" + name
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		definition, err := c.GetDefinition(object)
+		if errors.Is(err, ErrDefinitionNotSupported) || errors.Is(err, ErrDefinitionNotFound) {
+			export.Skipped = append(export.Skipped, name)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		definition = strings.TrimRight(definition, "\n") + "\n"
+
+		if !opts.Split {
+			if single.Len() > 0 {
+				single.WriteString("\n")
+			}
+			fmt.Fprintf(&single, "-- %s %s\n%s", object.Materialization, name, definition)
+			export.Exported++
+			continue
+		}
+
+		path := filepath.Join(opts.Path, exportFileName(object.Schema), object.Materialization.String(), exportFileName(object.Table)+".sql")
+		if err := writeExportFile(path, definition); err != nil {
+			return nil, err
+		}
+		export.Files = append(export.Files, path)
+		export.Exported++
+	}
+
+	if !opts.Split {
+		if err := writeExportFile(opts.Path, single.String()); err != nil {
+			return nil, err
+		}
+		export.Files = []string{opts.Path}
+	}
+
+	return export, nil
+}
+
+// exportedObjects collects objects of the structure (including children of
+// lazy nodes) which can have definitions.
+func (c *Connection) exportedObjects(opts *SchemaExportOptions) ([]*TableOptions, error) {
+	structure, err := c.GetStructureWithOptions(&StructureOptions{IncludeSystem: opts.IncludeSystem})
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []*TableOptions
+
+	var walk func(nodes []*Structure) error
+	walk = func(nodes []*Structure) error {
+		for _, node := range nodes {
+			if opts.Schema != "" && node.Schema != opts.Schema {
+				continue
+			}
+
+			children := node.Children
+			if node.Lazy {
+				children, err = c.GetStructureChildren(node)
+				if err != nil {
+					return err
+				}
+			}
+			if err := walk(children); err != nil {
+				return err
+			}
+
+			if _, ok := schemaExportOrder[node.Type]; ok {
+				objects = append(objects, &TableOptions{
+					Table:           node.Name,
+					Schema:          node.Schema,
+					Materialization: node.Type,
+				})
+			}
+		}
+		return nil
+	}
+
+	if err := walk(structure); err != nil {
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+// exportFileName makes a file name out of an object name.
+func exportFileName(name string) string {
+	if name == "" {
+		return "_"
+	}
+	return strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(name)
+}
+
+func writeExportFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("os.MkdirAll: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("os.WriteFile: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package core_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_ExportSchema(t *testing.T) {
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 1),
+		mock.AdapterWithTableDefinition("items", []*core.Column{{Name: "id", Type: "int"}}),
+		mock.AdapterWithTableDefinition("logs", []*core.Column{{Name: "msg", Type: "text"}}),
+		mock.AdapterWithDefinition("", "items", "CREATE TABLE items (id int);"),
+		mock.AdapterWithRoutine(&core.Structure{
+			Name:   "add",
+			Schema: "public",
+			Type:   core.StructureTypeFunction,
+		}, "CREATE FUNCTION add(a int, b int) RETURNS int AS 'select a + b' LANGUAGE sql;\n"),
+	)
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	dir := t.TempDir()
+
+	// single file, functions go before tables, tables without definitions are skipped
+	path := filepath.Join(dir, "schema.sql")
+	export, err := connection.ExportSchema(&core.SchemaExportOptions{Path: path})
+	r.NoError(err)
+	r.Equal(&core.SchemaExport{
+		Exported: 2,
+		Skipped:  []string{"logs"},
+		Files:    []string{path},
+	}, export)
+
+	content, err := os.ReadFile(path)
+	r.NoError(err)
+	r.Equal("-- function public.add\n"+
+		"CREATE FUNCTION add(a int, b int) RETURNS int AS 'select a + b' LANGUAGE sql;\n"+
+		"\n"+
+		"-- table items\n"+
+		"CREATE TABLE items (id int);\n", string(content))
+
+	// selected objects, one file per object
+	export, err = connection.ExportSchema(&core.SchemaExportOptions{
+		Path:  dir,
+		Split: true,
+		Objects: []*core.TableOptions{
+			{Table: "add", Schema: "public", Materialization: core.StructureTypeFunction},
+		},
+	})
+	r.NoError(err)
+	r.Equal(1, export.Exported)
+	r.Equal([]string{filepath.Join(dir, "public", "function", "add.sql")}, export.Files)
+
+	_, err = connection.ExportSchema(&core.SchemaExportOptions{})
+	r.Error(err)
+}
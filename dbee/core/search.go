@@ -0,0 +1,90 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DefaultSearchLimit is the number of search matches returned if no limit is provided.
+const DefaultSearchLimit = 200
+
+type (
+	// ObjectMatch is a database object whose name matches a search pattern.
+	ObjectMatch struct {
+		Schema string
+		// table of the column (empty for other objects)
+		Table string
+		Name  string
+		Type  StructureType
+	}
+
+	// ObjectSearcher is an optional interface for drivers that can search the
+	// catalog for tables, views, columns and functions by name. Pattern is
+	// matched case insensitively anywhere in the name and can contain LIKE
+	// wildcards ("%" and "_").
+	ObjectSearcher interface {
+		SearchObjects(ctx context.Context, pattern string, limit int) ([]*ObjectMatch, error)
+	}
+)
+
+// Path returns the names of nodes leading to the object (e.g. schema, table, column).
+func (m *ObjectMatch) Path() []string {
+	var path []string
+	for _, name := range []string{m.Schema, m.Table, m.Name} {
+		if name != "" {
+			path = append(path, name)
+		}
+	}
+	return path
+}
+
+// SearchObjects searches for objects with names matching the pattern. Drivers
+// which can't search the catalog fall back to searching the loaded structure,
+// without columns.
+func (c *Connection) SearchObjects(pattern string, limit int) ([]*ObjectMatch, error) {
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+
+	if searcher, ok := c.driver.(ObjectSearcher); ok {
+		matches, err := searcher.SearchObjects(context.Background(), pattern, limit)
+		if err != nil {
+			return nil, fmt.Errorf("searcher.SearchObjects: %w", err)
+		}
+		return matches, nil
+	}
+
+	structure, err := c.GetStructure()
+	if err != nil {
+		return nil, err
+	}
+
+	return searchStructure(structure, pattern, limit), nil
+}
+
+// searchStructure returns nodes of the structure whose names contain the pattern.
+func searchStructure(structure []*Structure, pattern string, limit int) []*ObjectMatch {
+	pattern = strings.ToLower(pattern)
+
+	var matches []*ObjectMatch
+	var search func(nodes []*Structure)
+	search = func(nodes []*Structure) {
+		for _, node := range nodes {
+			if len(matches) >= limit {
+				return
+			}
+			if node.Type != StructureTypeNone && strings.Contains(strings.ToLower(node.Name), pattern) {
+				matches = append(matches, &ObjectMatch{
+					Schema: node.Schema,
+					Name:   node.Name,
+					Type:   node.Type,
+				})
+			}
+			search(node.Children)
+		}
+	}
+	search(structure)
+
+	return matches
+}
@@ -0,0 +1,35 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_SearchObjects_Fallback(t *testing.T) {
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 1),
+		mock.AdapterWithTableDefinition("users", []*core.Column{{Name: "id", Type: "int"}}),
+		mock.AdapterWithTableDefinition("user_roles", []*core.Column{{Name: "id", Type: "int"}}),
+		mock.AdapterWithTableDefinition("orders", []*core.Column{{Name: "id", Type: "int"}}),
+	)
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	// drivers without a searcher search the structure
+	matches, err := connection.SearchObjects("USER", 0)
+	r.NoError(err)
+	r.Len(matches, 2)
+	for _, m := range matches {
+		r.Contains(m.Name, "user")
+		r.Equal(core.StructureTypeTable, m.Type)
+	}
+
+	matches, err = connection.SearchObjects("", 1)
+	r.NoError(err)
+	r.Len(matches, 1)
+}
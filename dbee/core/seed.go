@@ -0,0 +1,415 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrUnknownSeedGenerator = errors.New("unknown seed generator")
+
+// SeedGenerator generates values of a column.
+type SeedGenerator string
+
+const (
+	// SeedGeneratorSkip leaves the column out, so it gets its default value.
+	SeedGeneratorSkip      SeedGenerator = "skip"
+	SeedGeneratorNull      SeedGenerator = "null"
+	SeedGeneratorSequence  SeedGenerator = "sequence"
+	SeedGeneratorInt       SeedGenerator = "int"
+	SeedGeneratorFloat     SeedGenerator = "float"
+	SeedGeneratorBool      SeedGenerator = "bool"
+	SeedGeneratorTimestamp SeedGenerator = "timestamp"
+	SeedGeneratorDate      SeedGenerator = "date"
+	SeedGeneratorTime      SeedGenerator = "time"
+	SeedGeneratorUUID      SeedGenerator = "uuid"
+	SeedGeneratorJSON      SeedGenerator = "json"
+	SeedGeneratorBytes     SeedGenerator = "bytes"
+	SeedGeneratorWord      SeedGenerator = "word"
+	SeedGeneratorSentence  SeedGenerator = "sentence"
+	SeedGeneratorFirstName SeedGenerator = "first_name"
+	SeedGeneratorLastName  SeedGenerator = "last_name"
+	SeedGeneratorName      SeedGenerator = "name"
+	SeedGeneratorEmail     SeedGenerator = "email"
+	SeedGeneratorPhone     SeedGenerator = "phone"
+	SeedGeneratorCity      SeedGenerator = "city"
+	SeedGeneratorCountry   SeedGenerator = "country"
+	SeedGeneratorCompany   SeedGenerator = "company"
+	SeedGeneratorURL       SeedGenerator = "url"
+	// SeedGeneratorOneOf picks one of the values of the rule.
+	SeedGeneratorOneOf SeedGenerator = "one_of"
+)
+
+const defaultSeedRows = 100
+
+type (
+	// SeedRule configures values generated for a column.
+	SeedRule struct {
+		// Generator of values, inferred from the type and name of the column if empty.
+		Generator SeedGenerator
+		// Values picked by the "one_of" generator.
+		Values []any
+		// Min and Max bound numbers of the "int" and "float" generators and the
+		// start of the "sequence" generator (Min).
+		Min float64
+		Max float64
+		// NullRate is the fraction (0 to 1) of NULL values.
+		NullRate float64
+	}
+
+	// SeedOptions describe seeding of a table with generated rows.
+	SeedOptions struct {
+		Table  string
+		Schema string
+		// Rows is the number of inserted rows (100 if 0).
+		Rows int
+		// Columns are rules of columns by their name. Other columns get
+		// generators inferred from their type and name.
+		Columns map[string]*SeedRule
+		// number of rows sent to the database at once
+		BatchSize int
+		// Seed of the random generator, the same seed generates the same rows
+		// (random if 0).
+		Seed int64
+	}
+)
+
+var (
+	seedFirstNames = []string{
+		"Ada", "Alan", "Barbara", "Dennis", "Edsger", "Frances", "Grace", "Guido", "John", "Ken",
+		"Linus", "Margaret", "Niklaus", "Radia", "Rob", "Sophie", "Tim", "Yukihiro", "Maja", "Luka",
+	}
+	seedLastNames = []string{
+		"Allen", "Hopper", "Kernighan", "Knuth", "Lamport", "Liskov", "Lovelace", "Perlman", "Pike",
+		"Ritchie", "Rossum", "Thompson", "Torvalds", "Turing", "Wilson", "Wirth", "Matsumoto", "Hamilton",
+	}
+	seedCities    = []string{"Berlin", "Lisbon", "Ljubljana", "London", "Oslo", "Paris", "Tokyo", "Toronto", "Vienna", "Zagreb"}
+	seedCountries = []string{"DE", "FR", "GB", "JP", "SI", "US", "BR", "IN", "CA", "AU"}
+	seedCompanies = []string{"Acme", "Globex", "Initech", "Hooli", "Umbrella", "Stark", "Wayne", "Tyrell"}
+	seedWords     = []string{
+		"alpha", "amber", "breeze", "cedar", "delta", "ember", "falcon", "glacier", "harbor", "island",
+		"jasper", "kernel", "lumen", "meadow", "nectar", "orbit", "pebble", "quartz", "river", "summit",
+	}
+)
+
+// seedEpoch is the start of generated timestamps, which span two years.
+var seedEpoch = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var seedLengthRe = regexp.MustCompile(`\(\s*(\d+)`)
+
+// seedColumn generates values of a column.
+type seedColumn struct {
+	name string
+	rule *SeedRule
+	// maximum length of strings (0 if unlimited)
+	length int
+	// next value of the sequence generator
+	next int64
+}
+
+// inferSeedGenerator picks a generator from the type and name of the column.
+// Columns with defaults which generate keys (serial, identity, autoincrement)
+// are skipped.
+func inferSeedGenerator(col *Column) SeedGenerator {
+	typ := strings.ToLower(col.Type)
+	name := strings.ToLower(col.Name)
+	def := strings.ToLower(col.Default)
+
+	if strings.Contains(typ, "serial") || strings.Contains(def, "nextval") ||
+		strings.Contains(def, "identity") || strings.Contains(def, "auto_increment") ||
+		strings.Contains(typ, "identity") || strings.Contains(typ, "auto_increment") {
+		return SeedGeneratorSkip
+	}
+
+	switch {
+	case strings.Contains(typ, "uuid") || strings.Contains(typ, "uniqueidentifier"):
+		return SeedGeneratorUUID
+	case strings.Contains(typ, "bool") || typ == "bit" || typ == "bit(1)" || typ == "tinyint(1)":
+		return SeedGeneratorBool
+	case strings.Contains(typ, "int"):
+		if col.PrimaryKey {
+			return SeedGeneratorSequence
+		}
+		return SeedGeneratorInt
+	case strings.Contains(typ, "numeric") || strings.Contains(typ, "decimal") || strings.Contains(typ, "float") ||
+		strings.Contains(typ, "double") || strings.Contains(typ, "real") || strings.Contains(typ, "money") ||
+		strings.Contains(typ, "number"):
+		return SeedGeneratorFloat
+	case strings.Contains(typ, "timestamp") || strings.Contains(typ, "datetime"):
+		return SeedGeneratorTimestamp
+	case strings.Contains(typ, "date"):
+		return SeedGeneratorDate
+	case strings.HasPrefix(typ, "time"):
+		return SeedGeneratorTime
+	case strings.Contains(typ, "json"):
+		return SeedGeneratorJSON
+	case strings.Contains(typ, "bytea") || strings.Contains(typ, "blob") || strings.Contains(typ, "binary"):
+		return SeedGeneratorBytes
+	}
+
+	// text columns
+	switch {
+	case strings.Contains(name, "email"):
+		return SeedGeneratorEmail
+	case strings.Contains(name, "first"):
+		return SeedGeneratorFirstName
+	case strings.Contains(name, "last") || strings.Contains(name, "surname"):
+		return SeedGeneratorLastName
+	case strings.Contains(name, "company") || strings.Contains(name, "organization"):
+		return SeedGeneratorCompany
+	case strings.Contains(name, "name"):
+		return SeedGeneratorName
+	case strings.Contains(name, "phone"):
+		return SeedGeneratorPhone
+	case strings.Contains(name, "city"):
+		return SeedGeneratorCity
+	case strings.Contains(name, "country"):
+		return SeedGeneratorCountry
+	case strings.Contains(name, "url") || strings.Contains(name, "website"):
+		return SeedGeneratorURL
+	case strings.Contains(name, "description") || strings.Contains(name, "comment") ||
+		strings.Contains(name, "note") || strings.Contains(name, "text") || strings.Contains(name, "body"):
+		return SeedGeneratorSentence
+	}
+	return SeedGeneratorWord
+}
+
+// generate returns the next value of the column.
+func (sc *seedColumn) generate(rnd *rand.Rand) (any, error) {
+	rule := sc.rule
+	if rule.NullRate > 0 && rnd.Float64() < rule.NullRate {
+		return nil, nil
+	}
+
+	pick := func(values []string) string {
+		return values[rnd.Intn(len(values))]
+	}
+	// numbers are between 1 and 1000 if the rule doesn't bound them
+	bounds := func() (float64, float64) {
+		if rule.Min == 0 && rule.Max == 0 {
+			return 1, 1000
+		}
+		return rule.Min, rule.Max
+	}
+
+	var value any
+	switch rule.Generator {
+	case SeedGeneratorNull:
+		return nil, nil
+	case SeedGeneratorSequence:
+		value = sc.next
+		sc.next++
+	case SeedGeneratorInt:
+		min, max := bounds()
+		value = int64(math.Floor(min + rnd.Float64()*(max-min+1)))
+	case SeedGeneratorFloat:
+		min, max := bounds()
+		value = math.Round((min+rnd.Float64()*(max-min))*100) / 100
+	case SeedGeneratorBool:
+		value = rnd.Intn(2) == 1
+	case SeedGeneratorTimestamp:
+		value = seedEpoch.Add(time.Duration(rnd.Int63n(int64(2 * 365 * 24 * time.Hour))))
+	case SeedGeneratorDate:
+		value = seedEpoch.AddDate(0, 0, rnd.Intn(2*365)).Format("2006-01-02")
+	case SeedGeneratorTime:
+		d := time.Duration(rnd.Int63n(int64(24 * time.Hour)))
+		value = fmt.Sprintf("%02d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+	case SeedGeneratorUUID:
+		var b [16]byte
+		_, _ = rnd.Read(b[:])
+		id, err := uuid.FromBytes(b[:])
+		if err != nil {
+			return nil, err
+		}
+		// version 4 layout
+		id[6] = (id[6] & 0x0f) | 0x40
+		id[8] = (id[8] & 0x3f) | 0x80
+		value = id.String()
+	case SeedGeneratorJSON:
+		value = fmt.Sprintf(`{"%s": "%s", "n": %d}`, pick(seedWords), pick(seedWords), rnd.Intn(100))
+	case SeedGeneratorBytes:
+		b := make([]byte, 16)
+		_, _ = rnd.Read(b)
+		value = b
+	case SeedGeneratorWord:
+		value = pick(seedWords)
+	case SeedGeneratorSentence:
+		words := make([]string, 4+rnd.Intn(6))
+		for i := range words {
+			words[i] = pick(seedWords)
+		}
+		value = strings.ToUpper(words[0][:1]) + strings.Join(words, " ")[1:] + "."
+	case SeedGeneratorFirstName:
+		value = pick(seedFirstNames)
+	case SeedGeneratorLastName:
+		value = pick(seedLastNames)
+	case SeedGeneratorName:
+		value = pick(seedFirstNames) + " " + pick(seedLastNames)
+	case SeedGeneratorEmail:
+		value = fmt.Sprintf("%s.%s%d@example.com",
+			strings.ToLower(pick(seedFirstNames)), strings.ToLower(pick(seedLastNames)), rnd.Intn(1000))
+	case SeedGeneratorPhone:
+		value = fmt.Sprintf("+1 555 %03d %04d", rnd.Intn(1000), rnd.Intn(10000))
+	case SeedGeneratorCity:
+		value = pick(seedCities)
+	case SeedGeneratorCountry:
+		value = pick(seedCountries)
+	case SeedGeneratorCompany:
+		value = pick(seedCompanies) + " " + pick([]string{"Inc", "Ltd", "GmbH", "d.o.o."})
+	case SeedGeneratorURL:
+		value = fmt.Sprintf("https://%s.example.com/%s", pick(seedWords), pick(seedWords))
+	case SeedGeneratorOneOf:
+		if len(rule.Values) < 1 {
+			return nil, fmt.Errorf("column %q: no values for the %s generator", sc.name, rule.Generator)
+		}
+		return rule.Values[rnd.Intn(len(rule.Values))], nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownSeedGenerator, rule.Generator)
+	}
+
+	if s, ok := value.(string); ok && sc.length > 0 && len([]rune(s)) > sc.length {
+		value = string([]rune(s)[:sc.length])
+	}
+	return value, nil
+}
+
+// seedColumns returns generators of columns of the table. Columns with the
+// skip generator are left out.
+func (c *Connection) seedColumns(ctx context.Context, opts *SeedOptions, table string) ([]*seedColumn, error) {
+	columns, err := c.GetColumns(&TableOptions{
+		Table:           opts.Table,
+		Schema:          opts.Schema,
+		Materialization: StructureTypeTable,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(columns))
+	var out []*seedColumn
+	for _, col := range columns {
+		known[col.Name] = true
+
+		rule := &SeedRule{}
+		if r, ok := opts.Columns[col.Name]; ok && r != nil {
+			copied := *r
+			rule = &copied
+		}
+		if rule.Generator == "" {
+			rule.Generator = inferSeedGenerator(col)
+		}
+		if rule.Generator == SeedGeneratorSkip {
+			continue
+		}
+
+		sc := &seedColumn{name: col.Name, rule: rule, next: int64(rule.Min)}
+		if match := seedLengthRe.FindStringSubmatch(col.Type); match != nil && !strings.Contains(strings.ToLower(col.Type), "int") {
+			sc.length, _ = strconv.Atoi(match[1])
+		}
+
+		// sequences continue after existing rows
+		if rule.Generator == SeedGeneratorSequence && rule.Min == 0 {
+			sc.next = 1
+			rows, err := queryAll(ctx, c.driver.Query, fmt.Sprintf("SELECT MAX(%s) FROM %s", c.Dialect().QuoteIdentifier(col.Name), table))
+			if err == nil && len(rows) > 0 && len(rows[0]) > 0 && rows[0][0] != nil {
+				if max, err := strconv.ParseInt(fmt.Sprint(rows[0][0]), 10, 64); err == nil {
+					sc.next = max + 1
+				}
+			}
+		}
+
+		out = append(out, sc)
+	}
+
+	for name := range opts.Columns {
+		if !known[name] {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownColumn, name)
+		}
+	}
+	if len(out) < 1 {
+		return nil, errors.New("no columns to seed")
+	}
+
+	return out, nil
+}
+
+// Seed generates rows and inserts them into the table using the driver's bulk
+// import. Values are generated by rules of columns or by generators inferred
+// from types and names of columns.
+func (c *Connection) Seed(opts *SeedOptions, onEvent func(CallState, *Call)) (*Call, error) {
+	importer, ok := c.driver.(Importer)
+	if !ok {
+		return nil, ErrImportNotSupported
+	}
+	if opts.Table == "" {
+		return nil, errors.New("no table provided")
+	}
+
+	rowCount := opts.Rows
+	if rowCount < 1 {
+		rowCount = defaultSeedRows
+	}
+	batchSize := opts.BatchSize
+	if batchSize < 1 {
+		batchSize = defaultImportBatchSize
+	}
+	seed := opts.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	table := opts.Table
+	if opts.Schema != "" {
+		table = opts.Schema + "." + opts.Table
+	}
+
+	exec := func(ctx context.Context) (ResultStream, error) {
+		columns, err := c.seedColumns(ctx, opts, table)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, len(columns))
+		for i, col := range columns {
+			names[i] = col.name
+		}
+
+		rnd := rand.New(rand.NewSource(seed))
+		inserted := 0
+		batch := make([]Row, 0, batchSize)
+		for inserted+len(batch) < rowCount {
+			row := make(Row, len(columns))
+			for i, col := range columns {
+				row[i], err = col.generate(rnd)
+				if err != nil {
+					return nil, err
+				}
+			}
+			batch = append(batch, row)
+
+			if len(batch) >= batchSize || inserted+len(batch) >= rowCount {
+				if err := importer.ImportBatch(ctx, table, names, batch); err != nil {
+					return nil, classifyError(c.driver, err, "")
+				}
+				inserted += len(batch)
+				batch = batch[:0]
+			}
+		}
+
+		return newSliceStream(
+			Header{"Inserted", "Seed"},
+			[]Row{{inserted, seed}},
+			&Meta{},
+		), nil
+	}
+
+	query := fmt.Sprintf("-- seed %s with %d rows", table, rowCount)
+
+	return newCallFromExecutor(exec, query, onEvent), nil
+}
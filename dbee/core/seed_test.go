@@ -0,0 +1,89 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_Seed(t *testing.T) {
+	r := require.New(t)
+
+	columns := []*core.Column{
+		{Name: "id", Type: "INTEGER", PrimaryKey: true},
+		{Name: "serial_no", Type: "bigserial"},
+		{Name: "external_id", Type: "uuid"},
+		{Name: "email", Type: "VARCHAR(12)"},
+		{Name: "created_at", Type: "timestamp with time zone"},
+		{Name: "status", Type: "text"},
+		{Name: "score", Type: "int"},
+		{Name: "note", Type: "text"},
+	}
+	opts := &core.SeedOptions{
+		Table: "users",
+		Rows:  25,
+		Columns: map[string]*core.SeedRule{
+			"status": {Generator: core.SeedGeneratorOneOf, Values: []any{"active", "banned"}},
+			"score":  {Min: 5, Max: 5},
+			"note":   {NullRate: 1},
+		},
+		BatchSize: 10,
+		Seed:      42,
+	}
+
+	seed := func() []core.Row {
+		// the highest existing id is 41
+		adapter := mock.NewAdapter([]core.Row{{int64(41)}}, mock.AdapterWithTableDefinition("users", columns))
+		connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+		r.NoError(err)
+
+		call, err := connection.Seed(opts, nil)
+		r.NoError(err)
+		select {
+		case <-call.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("call did not finish in expected time")
+		}
+		r.NoError(call.Err())
+
+		return adapter.ImportedRows()
+	}
+
+	rows := seed()
+	r.Len(rows, 25)
+	for i, row := range rows {
+		// serial_no is left to its default
+		r.Len(row, 7)
+		r.Equal(int64(42+i), row[0])
+		r.Len(row[1], 36)
+		r.LessOrEqual(len(row[2].(string)), 12)
+		r.IsType(time.Time{}, row[3])
+		r.Contains([]any{"active", "banned"}, row[4])
+		r.Equal(int64(5), row[5])
+		r.Nil(row[6])
+	}
+
+	// the same seed generates the same rows
+	r.Equal(rows, seed())
+
+	// invalid rules
+	adapter := mock.NewAdapter(nil, mock.AdapterWithTableDefinition("users", columns))
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+	for rule, expected := range map[string]error{
+		"missing": core.ErrUnknownColumn,
+		"status":  core.ErrUnknownSeedGenerator,
+	} {
+		call, err := connection.Seed(&core.SeedOptions{
+			Table:   "users",
+			Columns: map[string]*core.SeedRule{rule: {Generator: "lorem"}},
+		}, nil)
+		r.NoError(err)
+		<-call.Done()
+		r.ErrorIs(call.Err(), expected)
+	}
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrServerInfoNotSupported = errors.New("server info not supported")
+
+type (
+	// ServerInfo describes the database server a connection is connected to.
+	ServerInfo struct {
+		Version string
+		// uptime of the server in seconds (-1 if unknown)
+		Uptime int64
+		// installed extensions or plugins
+		Extensions []*Extension
+		// settings of interest (e.g. encoding, time zone)
+		Settings []*Setting
+	}
+
+	// Extension is an extension or plugin installed on the server.
+	Extension struct {
+		Name    string
+		Version string
+	}
+
+	// Setting is a server configuration parameter.
+	Setting struct {
+		Name  string
+		Value string
+	}
+
+	// ServerInfoProvider is an optional interface for drivers that can describe
+	// the server they are connected to.
+	ServerInfoProvider interface {
+		ServerInfo(ctx context.Context) (*ServerInfo, error)
+	}
+)
+
+// GetServerInfo returns version, uptime, extensions and settings of the server.
+func (c *Connection) GetServerInfo() (*ServerInfo, error) {
+	provider, ok := c.driver.(ServerInfoProvider)
+	if !ok {
+		return nil, ErrServerInfoNotSupported
+	}
+
+	info, err := provider.ServerInfo(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("provider.ServerInfo: %w", err)
+	}
+
+	return info, nil
+}
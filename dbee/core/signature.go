@@ -0,0 +1,84 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrSignaturesNotSupported = errors.New("listing function signatures not supported")
+
+type (
+	// Signature is a single overload of a function or a procedure.
+	Signature struct {
+		Schema string
+		Name   string
+		Params []*SignatureParam
+		// return type (empty for procedures)
+		Returns string
+		// comment (description) of the routine stored in the database
+		Comment string
+	}
+
+	// SignatureParam is a parameter of a signature.
+	SignatureParam struct {
+		Name string
+		Type string
+		Mode ParamMode
+	}
+
+	// SignatureProvider is an optional interface for drivers that can list
+	// signatures of functions and procedures. opts.Table is the name of the
+	// routine and opts.Schema is empty if the routine can be in any schema.
+	SignatureProvider interface {
+		Signatures(ctx context.Context, opts *TableOptions) ([]*Signature, error)
+	}
+)
+
+// String returns the signature in "schema.name(param type, ...) -> returns" form.
+func (s *Signature) String() string {
+	name := s.Name
+	if s.Schema != "" {
+		name = s.Schema + "." + name
+	}
+
+	params := ""
+	for i, p := range s.Params {
+		if i > 0 {
+			params += ", "
+		}
+		if p.Mode != ParamModeIn {
+			params += p.Mode.String() + " "
+		}
+		if p.Name != "" {
+			params += p.Name + " "
+		}
+		params += p.Type
+	}
+
+	out := name + "(" + params + ")"
+	if s.Returns != "" {
+		out += " -> " + s.Returns
+	}
+	return out
+}
+
+// GetSignatures returns all overloads of the function or procedure described
+// by opts (e.g. for signature help while writing a call).
+func (c *Connection) GetSignatures(opts *TableOptions) ([]*Signature, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("opts cannot be nil")
+	}
+
+	provider, ok := c.driver.(SignatureProvider)
+	if !ok {
+		return nil, ErrSignaturesNotSupported
+	}
+
+	signatures, err := provider.Signatures(context.Background(), opts)
+	if err != nil {
+		return nil, fmt.Errorf("provider.Signatures: %w", err)
+	}
+
+	return signatures, nil
+}
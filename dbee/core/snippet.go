@@ -0,0 +1,221 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+var (
+	ErrSnippetNotFound     = errors.New("snippet not found")
+	ErrInvalidSnippetName  = errors.New("snippet name must not be empty")
+	ErrInvalidSnippetQuery = errors.New("snippet query must not be empty")
+)
+
+// Snippet is a named, parameterized query. Query is a go-template which is
+// rendered with the arguments provided at invocation, for example:
+//
+//	select * from pg_stat_activity where datname = {{ quote .db }}
+//
+// Snippets without a connection ID are global and available on all connections.
+type Snippet struct {
+	Name         string       `json:"name"`
+	ConnectionID ConnectionID `json:"connection_id,omitempty"`
+	Description  string       `json:"description,omitempty"`
+	Query        string       `json:"query"`
+}
+
+var snippetFuncs = template.FuncMap{
+	// quote quotes the value as an sql string literal
+	"quote": func(val any) string {
+		return "'" + strings.ReplaceAll(fmt.Sprint(val), "'", "''") + "'"
+	},
+}
+
+func (s *Snippet) template() (*template.Template, error) {
+	return template.New(s.Name).
+		Option("missingkey=error").
+		Funcs(snippetFuncs).
+		Parse(s.Query)
+}
+
+// Render renders the snippet's query with provided arguments.
+// Referencing an argument that is not provided is an error.
+func (s *Snippet) Render(args map[string]any) (string, error) {
+	tmpl, err := s.template()
+	if err != nil {
+		return "", fmt.Errorf("s.template: %w", err)
+	}
+
+	if args == nil {
+		args = make(map[string]any)
+	}
+
+	var sb strings.Builder
+	err = tmpl.Execute(&sb, args)
+	if err != nil {
+		return "", fmt.Errorf("tmpl.Execute: %w", err)
+	}
+
+	return sb.String(), nil
+}
+
+// SnippetStore holds snippets and optionally persists them to a json file.
+type SnippetStore struct {
+	mu       sync.Mutex
+	path     string
+	snippets []*Snippet
+}
+
+// NewSnippetStore creates a new store. If path is not empty, snippets are loaded
+// from that file (if it exists) and every change is written back to it.
+func NewSnippetStore(path string) (*SnippetStore, error) {
+	s := &SnippetStore{
+		path: path,
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	err = json.Unmarshal(b, &s.snippets)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	return s, nil
+}
+
+func (s *SnippetStore) find(connID ConnectionID, name string) int {
+	for i, sn := range s.snippets {
+		if sn.ConnectionID == connID && sn.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Add adds a snippet to the store or replaces an existing snippet with
+// the same name and connection ID.
+func (s *SnippetStore) Add(snippet *Snippet) error {
+	if snippet.Name == "" {
+		return ErrInvalidSnippetName
+	}
+	if snippet.Query == "" {
+		return ErrInvalidSnippetQuery
+	}
+	if _, err := snippet.template(); err != nil {
+		return fmt.Errorf("invalid snippet template: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sn := *snippet
+	if i := s.find(sn.ConnectionID, sn.Name); i >= 0 {
+		s.snippets[i] = &sn
+	} else {
+		s.snippets = append(s.snippets, &sn)
+	}
+
+	return s.persist()
+}
+
+// Remove removes a snippet from the store.
+func (s *SnippetStore) Remove(connID ConnectionID, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.find(connID, name)
+	if i < 0 {
+		return fmt.Errorf("%w: %q", ErrSnippetNotFound, name)
+	}
+	s.snippets = append(s.snippets[:i], s.snippets[i+1:]...)
+
+	return s.persist()
+}
+
+// Get returns the snippet that is available on connection by name.
+// Snippets of the connection take precedence over global ones.
+func (s *SnippetStore) Get(connID ConnectionID, name string) (*Snippet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.find(connID, name)
+	if i < 0 && connID != "" {
+		i = s.find("", name)
+	}
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %q", ErrSnippetNotFound, name)
+	}
+
+	sn := *s.snippets[i]
+	return &sn, nil
+}
+
+// List returns snippets available on connection, sorted by name.
+// If connection ID is empty, only global snippets are returned.
+func (s *SnippetStore) List(connID ConnectionID) []*Snippet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byName := make(map[string]*Snippet)
+	for _, sn := range s.snippets {
+		if sn.ConnectionID != "" && sn.ConnectionID != connID {
+			continue
+		}
+		// connection's snippets shadow global ones
+		if existing, ok := byName[sn.Name]; ok && existing.ConnectionID != "" {
+			continue
+		}
+		sn := *sn
+		byName[sn.Name] = &sn
+	}
+
+	snippets := make([]*Snippet, 0, len(byName))
+	for _, sn := range byName {
+		snippets = append(snippets, sn)
+	}
+	sort.Slice(snippets, func(i, j int) bool {
+		return snippets[i].Name < snippets[j].Name
+	})
+
+	return snippets
+}
+
+// persist writes the snippets to the store's file. Caller must hold the lock.
+func (s *SnippetStore) persist() error {
+	if s.path == "" {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(s.snippets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.path), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("os.MkdirAll: %w", err)
+	}
+
+	err = os.WriteFile(s.path, b, 0o600)
+	if err != nil {
+		return fmt.Errorf("os.WriteFile: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package core_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestSnippet_Render(t *testing.T) {
+	r := require.New(t)
+
+	snippet := &core.Snippet{
+		Name:  "by_name",
+		Query: "select * from {{ .table }} where name = {{ quote .name }}",
+	}
+
+	query, err := snippet.Render(map[string]any{"table": "users", "name": "o'brien"})
+	r.NoError(err)
+	r.Equal("select * from users where name = 'o''brien'", query)
+
+	// missing argument
+	_, err = snippet.Render(map[string]any{"table": "users"})
+	r.Error(err)
+}
+
+func TestSnippetStore(t *testing.T) {
+	r := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "snippets.json")
+
+	store, err := core.NewSnippetStore(path)
+	r.NoError(err)
+
+	r.NoError(store.Add(&core.Snippet{Name: "locks", Query: "select 'global'"}))
+	r.NoError(store.Add(&core.Snippet{Name: "locks", ConnectionID: "pg", Query: "select 'pg'"}))
+	r.NoError(store.Add(&core.Snippet{Name: "size", Query: "select 1"}))
+	r.ErrorIs(store.Add(&core.Snippet{Name: ""}), core.ErrInvalidSnippetName)
+	r.Error(store.Add(&core.Snippet{Name: "broken", Query: "{{ .x "}))
+
+	// connection snippet shadows the global one
+	snippet, err := store.Get("pg", "locks")
+	r.NoError(err)
+	r.Equal("select 'pg'", snippet.Query)
+
+	snippet, err = store.Get("other", "locks")
+	r.NoError(err)
+	r.Equal("select 'global'", snippet.Query)
+
+	r.Len(store.List("pg"), 2)
+	r.Len(store.List(""), 2)
+
+	// reload from disk
+	store, err = core.NewSnippetStore(path)
+	r.NoError(err)
+
+	r.NoError(store.Remove("pg", "locks"))
+	snippet, err = store.Get("pg", "locks")
+	r.NoError(err)
+	r.Equal("select 'global'", snippet.Query)
+
+	r.ErrorIs(store.Remove("pg", "locks"), core.ErrSnippetNotFound)
+}
@@ -0,0 +1,331 @@
+package core
+
+import "strings"
+
+// FormatOptions control how statements are formatted.
+type FormatOptions struct {
+	// indentation of clause bodies (two spaces if empty)
+	Indent string
+	// case of keywords: "upper" (default), "lower" or "preserve"
+	KeywordCase string
+}
+
+// formatKeywords are words whose case is changed by the formatter.
+var formatKeywords = func() map[string]bool {
+	keywords := make(map[string]bool)
+	for _, k := range sqlKeywords {
+		keywords[k] = true
+	}
+	for _, k := range []string{
+		"ASC", "CONFLICT", "CONSTRAINT", "DEFAULT", "DESC", "FETCH", "FILTER", "FOREIGN", "ILIKE",
+		"INTERVAL", "KEY", "LATERAL", "LIMIT", "MATERIALIZED", "NATURAL", "NULLS", "OFFSET",
+		"RECURSIVE", "REFERENCES", "RETURNING", "UNIQUE", "WINDOW",
+	} {
+		keywords[k] = true
+	}
+	return keywords
+}()
+
+// formatFrame is a query or a subquery being formatted.
+type formatFrame struct {
+	// indentation level of clauses
+	level int
+	// indentation level of the closing parenthesis of a subquery
+	closeLevel int
+	// number of open parentheses which aren't subqueries
+	inline int
+	// current clause (e.g. "SELECT" or "WHERE")
+	clause string
+	// waiting for BY of GROUP BY or ORDER BY
+	awaitBy bool
+	// an AND which belongs to BETWEEN is expected
+	between bool
+	// body of the clause starts after the next word (e.g. SELECT DISTINCT)
+	pendingBody bool
+}
+
+type sqlFormatter struct {
+	text    string
+	indent  string
+	kwCase  string
+	out     strings.Builder
+	frames  []*formatFrame
+	prev    *sqlToken
+	first   string
+	pending int
+	// indentation level of the current line
+	lineLevel int
+}
+
+// FormatStatements formats every statement in text: clauses start on new
+// lines, their bodies are indented and keywords are upper cased. Text between
+// statements (comments, delimiters and batch separators) is kept as it is and
+// procedural blocks are not formatted.
+func FormatStatements(text string, dialect *StatementDialect, opts *FormatOptions) string {
+	if dialect == nil {
+		dialect = DefaultStatementDialect
+	}
+	if opts == nil {
+		opts = &FormatOptions{}
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, stmt := range SplitStatements(text, dialect) {
+		b.WriteString(text[last:stmt.Start])
+		if dialect.BlockStatement != nil && dialect.BlockStatement.MatchString(stmt.Text) {
+			b.WriteString(stmt.Text)
+		} else {
+			b.WriteString(formatStatement(stmt.Text, dialect, opts))
+		}
+		last = stmt.Start + len(stmt.Text)
+	}
+	b.WriteString(text[last:])
+
+	return b.String()
+}
+
+// FormatStatements formats statements in text using the dialect of the connection.
+func (c *Connection) FormatStatements(text string, opts *FormatOptions) string {
+	return FormatStatements(text, c.statementDialect(), opts)
+}
+
+func formatStatement(stmt string, dialect *StatementDialect, opts *FormatOptions) string {
+	f := &sqlFormatter{
+		text:    stmt,
+		indent:  opts.Indent,
+		kwCase:  strings.ToLower(opts.KeywordCase),
+		frames:  []*formatFrame{{}},
+		pending: -1,
+	}
+	if f.indent == "" {
+		f.indent = "  "
+	}
+
+	tokens := lexSQL(stmt, dialect)
+	for _, tok := range tokens {
+		if tok.kind == sqlTokenWord {
+			f.first = tok.upper()
+			break
+		}
+	}
+
+	for i, tok := range tokens {
+		var next *sqlToken
+		if i+1 < len(tokens) {
+			next = tokens[i+1]
+		}
+		f.format(tok, next)
+	}
+
+	return f.out.String()
+}
+
+func (f *sqlFormatter) format(tok, next *sqlToken) {
+	top := f.frames[len(f.frames)-1]
+	atQuery := top.inline == 0
+	text := f.keywordCase(tok, next)
+
+	switch {
+	case tok.kind == sqlTokenComment:
+		f.comment(tok)
+
+	case atQuery && tok.kind == sqlTokenWord && f.isClause(tok, next):
+		word := tok.upper()
+		f.breakLine(top.level)
+		f.write(tok, text)
+		top.clause = word
+		top.between = false
+		switch word {
+		case "GROUP", "ORDER":
+			top.awaitBy = true
+		case "SELECT", "WITH":
+			if next != nil && next.is("DISTINCT", "ALL", "RECURSIVE") {
+				top.pendingBody = true
+			} else {
+				f.breakLine(top.level + 1)
+			}
+		case "FROM", "WHERE", "HAVING", "VALUES", "SET", "RETURNING", "WINDOW":
+			f.breakLine(top.level + 1)
+		}
+
+	case atQuery && top.awaitBy && tok.is("BY"):
+		f.write(tok, text)
+		top.awaitBy = false
+		f.breakLine(top.level + 1)
+
+	case atQuery && tok.kind == sqlTokenWord && f.isJoin(top, tok, next):
+		f.breakLine(top.level + 1)
+		f.write(tok, text)
+		top.clause = "JOIN"
+
+	case atQuery && tok.is("ON") && top.clause == "JOIN":
+		f.write(tok, text)
+		top.clause = "ON"
+
+	case atQuery && tok.is("BETWEEN"):
+		f.write(tok, text)
+		top.between = true
+
+	case atQuery && tok.is("AND", "OR") && (top.clause == "WHERE" || top.clause == "HAVING" || top.clause == "ON"):
+		if tok.is("AND") && top.between {
+			top.between = false
+			f.write(tok, text)
+			break
+		}
+		level := top.level + 1
+		if top.clause == "ON" {
+			level++
+		}
+		f.breakLine(level)
+		f.write(tok, text)
+
+	case tok.is("("):
+		f.write(tok, text)
+		if next != nil && next.is("SELECT", "WITH") {
+			f.frames = append(f.frames, &formatFrame{level: f.lineLevel + 1, closeLevel: f.lineLevel})
+		} else {
+			top.inline++
+		}
+
+	case tok.is(")"):
+		if top.inline == 0 && len(f.frames) > 1 {
+			f.frames = f.frames[:len(f.frames)-1]
+			f.breakLine(top.closeLevel)
+		} else if top.inline > 0 {
+			top.inline--
+		}
+		f.write(tok, text)
+
+	case tok.is(","):
+		f.write(tok, text)
+		switch top.clause {
+		case "SELECT", "FROM", "GROUP", "ORDER", "VALUES", "SET", "RETURNING", "WITH", "WINDOW":
+			if atQuery {
+				f.breakLine(top.level + 1)
+			}
+		}
+
+	default:
+		f.write(tok, text)
+		if top.pendingBody {
+			top.pendingBody = false
+			f.breakLine(top.level + 1)
+		}
+	}
+}
+
+// isClause reports whether the word starts a clause of a query.
+func (f *sqlFormatter) isClause(tok, next *sqlToken) bool {
+	prev := f.prev
+	switch tok.upper() {
+	case "SELECT", "WHERE", "HAVING", "LIMIT", "OFFSET", "RETURNING", "WINDOW", "UNION", "INTERSECT", "EXCEPT":
+		return true
+	case "VALUES":
+		return prev == nil || !prev.is("DEFAULT")
+	case "FROM":
+		return prev == nil || !prev.is("DELETE", "DISTINCT")
+	case "GROUP", "ORDER":
+		return next != nil && next.is("BY")
+	case "FETCH":
+		return next != nil && next.is("FIRST", "NEXT")
+	case "WITH":
+		return prev == nil || prev.is("(")
+	case "SET":
+		return f.first == "UPDATE"
+	case "INSERT", "UPDATE", "DELETE", "MERGE":
+		return prev == nil || prev.is("(", ")")
+	}
+	return false
+}
+
+// isJoin reports whether the word starts a join in the FROM clause.
+func (f *sqlFormatter) isJoin(top *formatFrame, tok, next *sqlToken) bool {
+	if top.clause != "FROM" && top.clause != "JOIN" && top.clause != "ON" {
+		return false
+	}
+	prev := f.prev
+	switch tok.upper() {
+	case "JOIN":
+		return prev == nil || !prev.is("LEFT", "RIGHT", "FULL", "INNER", "OUTER", "CROSS", "NATURAL")
+	case "LEFT", "RIGHT", "FULL", "INNER", "CROSS":
+		return next != nil && !next.is("(") && (prev == nil || !prev.is("NATURAL"))
+	case "NATURAL":
+		return true
+	}
+	return false
+}
+
+// comment keeps trailing comments on the line of the preceding token and
+// comments on their own lines on separate lines.
+func (f *sqlFormatter) comment(tok *sqlToken) {
+	ownLine := f.prev != nil && strings.Contains(f.text[f.prev.end:tok.start], "\n")
+	lineComment := !strings.HasPrefix(tok.text, "/*")
+
+	if !ownLine && f.pending >= 0 && f.out.Len() > 0 {
+		// the line break stays after the comment
+		f.out.WriteString(" " + tok.text)
+		f.prev = tok
+		return
+	}
+
+	if ownLine {
+		f.breakLine(f.lineLevel)
+	}
+	f.write(tok, tok.text)
+	if lineComment {
+		f.breakLine(f.lineLevel)
+	}
+}
+
+// breakLine starts the next token on a new line with the indentation level.
+func (f *sqlFormatter) breakLine(level int) {
+	f.pending = level
+}
+
+func (f *sqlFormatter) write(tok *sqlToken, text string) {
+	switch {
+	case f.pending >= 0:
+		if f.out.Len() > 0 {
+			f.out.WriteString("\n" + strings.Repeat(f.indent, f.pending))
+			f.lineLevel = f.pending
+		}
+		f.pending = -1
+	case f.needsSpace(tok):
+		f.out.WriteString(" ")
+	}
+
+	f.out.WriteString(text)
+	f.prev = tok
+}
+
+// needsSpace reports whether tok is separated from the previous token.
+// Whitespace is collapsed to a single space, tokens which weren't separated
+// (e.g. "a.b" or "-1") stay together.
+func (f *sqlFormatter) needsSpace(tok *sqlToken) bool {
+	prev := f.prev
+	switch {
+	case prev == nil:
+		return false
+	case prev.is(","):
+		return true
+	case tok.is(",", ")", ";") || prev.is("("):
+		return false
+	}
+	return prev.end != tok.start
+}
+
+// keywordCase returns the text of the token with the configured keyword
+// case. Parts of qualified names are never changed.
+func (f *sqlFormatter) keywordCase(tok, next *sqlToken) string {
+	if tok.kind != sqlTokenWord || f.kwCase == "preserve" || !formatKeywords[tok.upper()] {
+		return tok.text
+	}
+	if (f.prev != nil && f.prev.is(".")) || (next != nil && next.is(".")) {
+		return tok.text
+	}
+	if f.kwCase == "lower" {
+		return strings.ToLower(tok.text)
+	}
+	return tok.upper()
+}
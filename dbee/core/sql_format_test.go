@@ -0,0 +1,55 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestFormatStatements(t *testing.T) {
+	type testCase struct {
+		name     string
+		opts     *core.FormatOptions
+		text     string
+		expected string
+	}
+
+	testCases := []testCase{
+		{
+			name: "select",
+			text: "select a, b.c as d, count(*) from t join b on t.id = b.id and b.x > 1 where a = 1 and (b.c = 2 or b.c between 3 and 4) group by a order by 1 desc limit 10",
+			expected: "SELECT\n  a,\n  b.c AS d,\n  count(*)\nFROM\n  t\n  JOIN b ON t.id = b.id\n    AND b.x > 1\nWHERE\n  a = 1\n  AND (b.c = 2 OR b.c BETWEEN 3 AND 4)\n" +
+				"GROUP BY\n  a\nORDER BY\n  1 DESC\nLIMIT 10",
+		},
+		{
+			name:     "subquery",
+			text:     "select * from (select id from users where active) u where u.id in (1, 2)",
+			expected: "SELECT\n  *\nFROM\n  (\n    SELECT\n      id\n    FROM\n      users\n    WHERE\n      active\n  ) u\nWHERE\n  u.id IN (1, 2)",
+		},
+		{
+			name:     "statements and comments",
+			text:     "-- first\nupdate t set a = 1, b = 'x' where id = 2; delete from t",
+			expected: "-- first\nUPDATE t\nSET\n  a = 1,\n  b = 'x'\nWHERE\n  id = 2; DELETE FROM t",
+		},
+		{
+			name:     "options",
+			opts:     &core.FormatOptions{Indent: "\t", KeywordCase: "lower"},
+			text:     "SELECT Id FROM Users",
+			expected: "select\n\tId\nfrom\n\tUsers",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			formatted := core.FormatStatements(tc.text, nil, tc.opts)
+			r.Equal(tc.expected, formatted)
+
+			// formatting is idempotent
+			r.Equal(formatted, core.FormatStatements(formatted, nil, tc.opts))
+		})
+	}
+}
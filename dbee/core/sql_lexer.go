@@ -0,0 +1,125 @@
+package core
+
+import "strings"
+
+type sqlTokenKind int
+
+const (
+	// keywords and unquoted identifiers
+	sqlTokenWord sqlTokenKind = iota
+	sqlTokenQuotedIdent
+	sqlTokenString
+	sqlTokenNumber
+	sqlTokenComment
+	// operators, parentheses, commas, dots and delimiters
+	sqlTokenPunct
+)
+
+// sqlToken is a lexical token of a statement.
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+	// byte offsets in the lexed text (end is exclusive)
+	start int
+	end   int
+}
+
+// operators of more than one character
+var sqlOperators = []string{"->>", "<=", ">=", "<>", "!=", "::", "||", "->", ":="}
+
+// upper returns the text of a word in upper case.
+func (t *sqlToken) upper() string {
+	if t.kind != sqlTokenWord {
+		return t.text
+	}
+	return strings.ToUpper(t.text)
+}
+
+// is reports whether the token is one of the words (case insensitive) or
+// punctuation.
+func (t *sqlToken) is(values ...string) bool {
+	for _, v := range values {
+		if (t.kind == sqlTokenWord && strings.EqualFold(t.text, v)) || (t.kind == sqlTokenPunct && t.text == v) {
+			return true
+		}
+	}
+	return false
+}
+
+// lexSQL splits text into tokens according to the lexical rules of dialect.
+// Whitespace is dropped, comments are kept.
+func lexSQL(text string, dialect *StatementDialect) []*sqlToken {
+	if dialect == nil {
+		dialect = DefaultStatementDialect
+	}
+	s := &statementSplitter{text: text, dialect: dialect}
+
+	var tokens []*sqlToken
+	add := func(kind sqlTokenKind, start, end int) {
+		tokens = append(tokens, &sqlToken{kind: kind, text: text[start:end], start: start, end: end})
+	}
+
+	i := 0
+	for i < len(text) {
+		c := text[i]
+		start := i
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(text[i:], "--"), c == '#' && dialect.HashComments:
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				i = len(text)
+			} else {
+				i += end
+			}
+			add(sqlTokenComment, start, i)
+		case strings.HasPrefix(text[i:], "/*"):
+			i = s.skipBlockComment(i)
+			add(sqlTokenComment, start, i)
+		case c == '\'':
+			escapes := dialect.BackslashEscapes || (dialect.EscapeStrings && isEStringPrefix(text, i))
+			i = s.skipQuoted(i, '\'', escapes)
+			add(sqlTokenString, start, i)
+		case (c == 'e' || c == 'E') && dialect.EscapeStrings && i+1 < len(text) && text[i+1] == '\'':
+			i = s.skipQuoted(i+1, '\'', true)
+			add(sqlTokenString, start, i)
+		case c == '"':
+			i = s.skipQuoted(i, '"', false)
+			add(sqlTokenQuotedIdent, start, i)
+		case c == '`' && dialect.BacktickIdentifiers:
+			i = s.skipQuoted(i, '`', false)
+			add(sqlTokenQuotedIdent, start, i)
+		case c == '[' && dialect.BracketIdentifiers:
+			i = s.skipQuoted(i, ']', false)
+			add(sqlTokenQuotedIdent, start, i)
+		case c == '$' && dialect.DollarQuotes && s.skipDollarQuoted(i) > i+1:
+			i = s.skipDollarQuoted(i)
+			add(sqlTokenString, start, i)
+		case c >= '0' && c <= '9':
+			for i < len(text) && (isIdentChar(text[i]) || text[i] == '.') {
+				i++
+			}
+			add(sqlTokenNumber, start, i)
+		case isIdentChar(c) || c >= 0x80 || c == '@' || c == '$' || c == ':' && i+1 < len(text) && isIdentChar(text[i+1]):
+			// variables and parameters (@name, $1, :name) are words as well
+			i++
+			for i < len(text) && (isIdentChar(text[i]) || text[i] >= 0x80 || text[i] == '$' || text[i] == '@' || text[i] == '#') {
+				i++
+			}
+			add(sqlTokenWord, start, i)
+		default:
+			i++
+			for _, op := range sqlOperators {
+				if strings.HasPrefix(text[start:], op) {
+					i = start + len(op)
+					break
+				}
+			}
+			add(sqlTokenPunct, start, i)
+		}
+	}
+
+	return tokens
+}
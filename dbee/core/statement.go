@@ -0,0 +1,347 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+)
+
+type (
+	// StatementDialect describes the lexical rules of a dialect which are needed
+	// to find where statements begin and end.
+	StatementDialect struct {
+		// strings can contain backslash escapes (e.g. 'it\'s')
+		BackslashEscapes bool
+		// strings prefixed with E can contain backslash escapes (e.g. E'it\'s')
+		EscapeStrings bool
+		// $tag$ ... $tag$ quoted strings
+		DollarQuotes bool
+		// block comments can be nested
+		NestedComments bool
+		// # starts a line comment
+		HashComments bool
+		// `identifier`
+		BacktickIdentifiers bool
+		// [identifier]
+		BracketIdentifiers bool
+		// "DELIMITER <delimiter>" lines change the statement delimiter
+		DelimiterCommand bool
+		// a line containing only the separator (case-insensitive) ends the
+		// current statement (e.g. "GO" or "/")
+		BatchSeparator string
+		// statements matching this pattern are not ended by the delimiter,
+		// only by the batch separator (e.g. procedural blocks)
+		BlockStatement *regexp.Regexp
+	}
+
+	// StatementDialectProvider is an optional interface for drivers whose dialect
+	// differs from standard sql.
+	StatementDialectProvider interface {
+		StatementDialect() *StatementDialect
+	}
+
+	// StatementRange is a single statement in a text.
+	StatementRange struct {
+		// byte offsets of the statement (end is exclusive and includes the delimiter)
+		Start int
+		End   int
+		// 0-based positions of start and end
+		StartLine int
+		StartCol  int
+		EndLine   int
+		EndCol    int
+		// statement without the delimiter and surrounding whitespace
+		Text string
+	}
+)
+
+// DefaultStatementDialect follows standard sql.
+var DefaultStatementDialect = &StatementDialect{}
+
+// SplitStatements splits the text into statements. Delimiters inside strings,
+// quoted identifiers, comments and procedural blocks are ignored.
+// Parts of text which contain only whitespace and comments are skipped.
+func SplitStatements(text string, dialect *StatementDialect) []*StatementRange {
+	if dialect == nil {
+		dialect = DefaultStatementDialect
+	}
+
+	s := &statementSplitter{
+		text:      text,
+		dialect:   dialect,
+		delimiter: ";",
+		start:     -1,
+	}
+	s.split()
+
+	return s.statements
+}
+
+// StatementAt returns the statement at byte offset of text. If offset is
+// between statements, the preceding statement is returned (or the following one
+// if there is none). It returns nil if the text contains no statements.
+func StatementAt(text string, offset int, dialect *StatementDialect) *StatementRange {
+	statements := SplitStatements(text, dialect)
+	if len(statements) < 1 {
+		return nil
+	}
+
+	var found *StatementRange
+	for _, stmt := range statements {
+		if stmt.Start > offset {
+			break
+		}
+		found = stmt
+	}
+	if found == nil {
+		return statements[0]
+	}
+	return found
+}
+
+// StatementAt returns the statement at byte offset of text using the dialect
+// of the connection.
+func (c *Connection) StatementAt(text string, offset int) *StatementRange {
+	return StatementAt(text, offset, c.statementDialect())
+}
+
+// SplitStatements splits the text into statements using the dialect of the connection.
+func (c *Connection) SplitStatements(text string) []*StatementRange {
+	return SplitStatements(text, c.statementDialect())
+}
+
+func (c *Connection) statementDialect() *StatementDialect {
+	if provider, ok := c.driver.(StatementDialectProvider); ok {
+		return provider.StatementDialect()
+	}
+	return DefaultStatementDialect
+}
+
+type statementSplitter struct {
+	text      string
+	dialect   *StatementDialect
+	delimiter string
+
+	statements []*StatementRange
+
+	// start of the current statement (-1 if the statement has no content yet)
+	start int
+	// whether the current statement was already checked to be a block
+	blockChecked bool
+	isBlock      bool
+}
+
+func (s *statementSplitter) split() {
+	text := s.text
+	d := s.dialect
+
+	i := 0
+	for i < len(text) {
+		if i == 0 || text[i-1] == '\n' {
+			lineEnd := strings.IndexByte(text[i:], '\n')
+			if lineEnd < 0 {
+				lineEnd = len(text)
+			} else {
+				lineEnd += i
+			}
+			line := strings.TrimSpace(text[i:lineEnd])
+
+			if d.BatchSeparator != "" && strings.EqualFold(line, d.BatchSeparator) {
+				if s.start >= 0 {
+					s.finish(i, i)
+				}
+				i = lineEnd
+				continue
+			}
+			if d.DelimiterCommand && s.start < 0 && len(line) > 10 && strings.EqualFold(line[:10], "DELIMITER ") {
+				s.delimiter = strings.TrimSpace(line[10:])
+				i = lineEnd
+				continue
+			}
+		}
+
+		c := text[i]
+
+		// whitespace and comments
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case strings.HasPrefix(text[i:], "--"), c == '#' && d.HashComments:
+			i = s.skipUntil(i, "\n")
+			continue
+		case strings.HasPrefix(text[i:], "/*"):
+			i = s.skipBlockComment(i)
+			continue
+		}
+
+		if s.start < 0 {
+			s.start = i
+		}
+
+		if strings.HasPrefix(text[i:], s.delimiter) && !s.inBlock(i) {
+			s.finish(i, i+len(s.delimiter))
+			i += len(s.delimiter)
+			continue
+		}
+
+		switch {
+		case c == '\'':
+			escapes := d.BackslashEscapes || (d.EscapeStrings && isEStringPrefix(text, i))
+			i = s.skipQuoted(i, '\'', escapes)
+		case c == '"':
+			i = s.skipQuoted(i, '"', false)
+		case c == '`' && d.BacktickIdentifiers:
+			i = s.skipQuoted(i, '`', false)
+		case c == '[' && d.BracketIdentifiers:
+			i = s.skipQuoted(i, ']', false)
+		case c == '$' && d.DollarQuotes:
+			i = s.skipDollarQuoted(i)
+		default:
+			i++
+		}
+	}
+
+	if s.start >= 0 {
+		s.finish(len(text), len(text))
+	}
+}
+
+// inBlock reports whether the current statement is a procedural block,
+// which isn't ended by the delimiter.
+func (s *statementSplitter) inBlock(i int) bool {
+	if s.dialect.BlockStatement == nil {
+		return false
+	}
+	if !s.blockChecked {
+		s.isBlock = s.dialect.BlockStatement.MatchString(s.text[s.start:i])
+		s.blockChecked = true
+	}
+	return s.isBlock
+}
+
+// finish ends the current statement. end is the end of statement's text and
+// next is the end of the statement including the delimiter.
+func (s *statementSplitter) finish(end, next int) {
+	start := s.start
+	s.start = -1
+	s.blockChecked = false
+	s.isBlock = false
+
+	stmt := strings.TrimSpace(s.text[start:end])
+	if stmt == "" {
+		return
+	}
+
+	startLine, startCol := textPosition(s.text, start)
+	endLine, endCol := textPosition(s.text, next)
+
+	s.statements = append(s.statements, &StatementRange{
+		Start:     start,
+		End:       next,
+		StartLine: startLine,
+		StartCol:  startCol,
+		EndLine:   endLine,
+		EndCol:    endCol,
+		Text:      stmt,
+	})
+}
+
+// skipUntil returns the index after the first occurrence of end after i.
+func (s *statementSplitter) skipUntil(i int, end string) int {
+	idx := strings.Index(s.text[i:], end)
+	if idx < 0 {
+		return len(s.text)
+	}
+	return i + idx + len(end)
+}
+
+func (s *statementSplitter) skipBlockComment(i int) int {
+	if !s.dialect.NestedComments {
+		return s.skipUntil(i+2, "*/")
+	}
+
+	depth := 0
+	for i < len(s.text) {
+		switch {
+		case strings.HasPrefix(s.text[i:], "/*"):
+			depth++
+			i += 2
+		case strings.HasPrefix(s.text[i:], "*/"):
+			depth--
+			i += 2
+			if depth == 0 {
+				return i
+			}
+		default:
+			i++
+		}
+	}
+	return i
+}
+
+// skipQuoted skips a quoted string or identifier starting at i. Doubled
+// closing characters are treated as escaped.
+func (s *statementSplitter) skipQuoted(i int, closing byte, backslashEscapes bool) int {
+	i++
+	for i < len(s.text) {
+		c := s.text[i]
+		switch {
+		case backslashEscapes && c == '\\':
+			i += 2
+		case c == closing:
+			if i+1 < len(s.text) && s.text[i+1] == closing {
+				i += 2
+				continue
+			}
+			return i + 1
+		default:
+			i++
+		}
+	}
+	return len(s.text)
+}
+
+// skipDollarQuoted skips a $tag$ quoted string starting at i. If there is no
+// valid tag at i (e.g. a positional parameter $1), only the $ is skipped.
+func (s *statementSplitter) skipDollarQuoted(i int) int {
+	if i > 0 && isIdentChar(s.text[i-1]) {
+		return i + 1
+	}
+
+	end := strings.IndexByte(s.text[i+1:], '$')
+	if end < 0 {
+		return i + 1
+	}
+	tag := s.text[i+1 : i+1+end]
+	for j := 0; j < len(tag); j++ {
+		if !isIdentChar(tag[j]) || (j == 0 && tag[j] >= '0' && tag[j] <= '9') {
+			return i + 1
+		}
+	}
+
+	delimiter := "$" + tag + "$"
+	return s.skipUntil(i+len(delimiter), delimiter)
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' ||
+		(c >= 'a' && c <= 'z') ||
+		(c >= 'A' && c <= 'Z') ||
+		(c >= '0' && c <= '9')
+}
+
+// isEStringPrefix reports whether the quote at i starts an E” string.
+func isEStringPrefix(text string, i int) bool {
+	if i < 1 || (text[i-1] != 'e' && text[i-1] != 'E') {
+		return false
+	}
+	return i < 2 || !isIdentChar(text[i-2])
+}
+
+// textPosition converts byte offset to a 0-based line and column.
+func textPosition(text string, offset int) (line int, col int) {
+	before := text[:offset]
+	line = strings.Count(before, "\n")
+	col = offset - (strings.LastIndexByte(before, '\n') + 1)
+	return line, col
+}
@@ -0,0 +1,114 @@
+package core_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestSplitStatements(t *testing.T) {
+	type testCase struct {
+		name     string
+		dialect  *core.StatementDialect
+		text     string
+		expected []string
+	}
+
+	testCases := []testCase{
+		{
+			name:     "simple",
+			text:     "select 1;\nselect 2;  select 3",
+			expected: []string{"select 1", "select 2", "select 3"},
+		},
+		{
+			name:     "delimiters in strings, identifiers and comments",
+			text:     "select ';', \"a;b\" -- c;d\nfrom t; /* x; y */ select 'it''s;'",
+			expected: []string{"select ';', \"a;b\" -- c;d\nfrom t", "select 'it''s;'"},
+		},
+		{
+			name:     "comments only",
+			text:     "-- nothing;\n/* here */\n;;",
+			expected: nil,
+		},
+		{
+			name:    "dollar quoted body",
+			dialect: &core.StatementDialect{DollarQuotes: true, EscapeStrings: true, NestedComments: true},
+			text: "create function f() returns int as $body$ begin select 1; return 1; end; $body$ language plpgsql;\n" +
+				"select $1, E'\\';', 'a' /* /* ; */ ; */ ;",
+			expected: []string{
+				"create function f() returns int as $body$ begin select 1; return 1; end; $body$ language plpgsql",
+				"select $1, E'\\';', 'a' /* /* ; */ ; */",
+			},
+		},
+		{
+			name: "mysql",
+			dialect: &core.StatementDialect{
+				BackslashEscapes:    true,
+				HashComments:        true,
+				BacktickIdentifiers: true,
+				DelimiterCommand:    true,
+			},
+			text: "select 'a\\';', `x;y` # z;\nfrom t;\n" +
+				"DELIMITER $$\ncreate procedure p() begin select 1; end$$\nDELIMITER ;\nselect 2;",
+			expected: []string{
+				"select 'a\\';', `x;y` # z;\nfrom t",
+				"create procedure p() begin select 1; end",
+				"select 2",
+			},
+		},
+		{
+			name: "batch separator and blocks",
+			dialect: &core.StatementDialect{
+				BatchSeparator: "GO",
+				BlockStatement: regexp.MustCompile(`(?is)^(create|alter)\s+procedure\b`),
+			},
+			text: "create procedure p as begin select 1; select 2; end\ngo\nselect 3; select 4\nGO\n",
+			expected: []string{
+				"create procedure p as begin select 1; select 2; end",
+				"select 3",
+				"select 4",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			var texts []string
+			for _, stmt := range core.SplitStatements(tc.text, tc.dialect) {
+				texts = append(texts, stmt.Text)
+				// ranges point to the statement in text
+				r.True(strings.HasPrefix(tc.text[stmt.Start:stmt.End], stmt.Text))
+			}
+			r.Equal(tc.expected, texts)
+		})
+	}
+}
+
+func TestStatementAt(t *testing.T) {
+	r := require.New(t)
+
+	text := "select 1;\n\nselect 'a;b'\nfrom t;"
+
+	stmt := core.StatementAt(text, 3, nil)
+	r.Equal("select 1", stmt.Text)
+	r.Equal(0, stmt.StartLine)
+
+	// between statements -> previous statement
+	stmt = core.StatementAt(text, 10, nil)
+	r.Equal("select 1", stmt.Text)
+
+	stmt = core.StatementAt(text, strings.Index(text, "from"), nil)
+	r.Equal("select 'a;b'\nfrom t", stmt.Text)
+	r.Equal(2, stmt.StartLine)
+	r.Equal(0, stmt.StartCol)
+	r.Equal(3, stmt.EndLine)
+	r.Equal(7, stmt.EndCol)
+
+	r.Nil(core.StatementAt("-- nothing", 0, nil))
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// StorageDir returns the directory where the backend keeps files between runs
+// (archived results and the call log): /tmp on unix-like systems and
+// %LocalAppData%\dbee on windows.
+func StorageDir() string {
+	return storageDir()
+}
+
+// LockFile takes an exclusive lock of a lock file next to path (path + ".lock"),
+// blocking until it's acquired. Locks synchronize processes which share files
+// (e.g. the backend of the editor and the command line), not goroutines.
+func LockFile(path string) (unlock func(), err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("os.MkdirAll: %w", err)
+	}
+
+	file, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("os.OpenFile: %w", err)
+	}
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("lockFile: %w", err)
+	}
+
+	return func() {
+		_ = unlockFile(file)
+		file.Close()
+	}, nil
+}
+
+// WriteFileAtomic replaces the file at path with data, so readers never see a
+// partially written file. Data is written to a temporary file in the same
+// directory first, which is renamed to path (renames replace existing files
+// on windows as well).
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("os.CreateTemp: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(data)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("file.Write: %w", err)
+	}
+	if err := os.Chmod(file.Name(), perm); err != nil {
+		return fmt.Errorf("os.Chmod: %w", err)
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("os.Rename: %w", err)
+	}
+	return nil
+}
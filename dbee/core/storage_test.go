@@ -0,0 +1,62 @@
+package core_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	r := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "file.json")
+
+	r.NoError(core.WriteFileAtomic(path, []byte("first"), 0o644))
+	r.NoError(core.WriteFileAtomic(path, []byte("second"), 0o644))
+
+	b, err := os.ReadFile(path)
+	r.NoError(err)
+	r.Equal("second", string(b))
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	r.NoError(err)
+	r.Len(entries, 1)
+}
+
+func TestLockFile(t *testing.T) {
+	r := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "nested", "file.json")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			unlock, err := core.LockFile(path)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer unlock()
+
+			// read-modify-write which loses updates without the lock
+			b, _ := os.ReadFile(path)
+			if err := core.WriteFileAtomic(path, append(b, 'x'), 0o644); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	b, err := os.ReadFile(path)
+	r.NoError(err)
+	r.Equal("xxxxxxxxxx", string(b))
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package core
+
+// storageDir is /tmp, so archives are shared by all backends of the user and
+// survive restarts of the editor (but not reboots on most systems).
+func storageDir() string {
+	return "/tmp"
+}
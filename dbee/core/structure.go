@@ -0,0 +1,145 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrLazyStructureNotSupported = errors.New("loading structure incrementally not supported")
+
+// StructureLoader is an optional interface for drivers which can load the
+// structure incrementally, which keeps databases with large catalogs fast to
+// open. StructureRoots returns top-level nodes (e.g. schemas) without their
+// children, StructureChildren returns children of one of the top-level nodes.
+// Drivers which implement it are never asked for the whole Structure.
+type StructureLoader interface {
+	StructureRoots(ctx context.Context) ([]*Structure, error)
+	StructureChildren(ctx context.Context, parent *Structure) ([]*Structure, error)
+}
+
+type (
+	// StructureOptions are options of a structure request.
+	StructureOptions struct {
+		// IncludeSystem includes system schemas and objects (e.g. pg_catalog)
+		IncludeSystem bool
+	}
+
+	// SystemObjectClassifier is an optional interface for drivers which list
+	// system schemas or objects (e.g. information_schema) in the structure.
+	// Top-level nodes reported as system are hidden unless requested.
+	SystemObjectClassifier interface {
+		IsSystem(node *Structure) bool
+	}
+)
+
+// GetStructureWithOptions returns the structure the same way as GetStructure,
+// but hides system schemas and objects unless opts.IncludeSystem is set.
+// Nil opts are the same as empty opts.
+func (c *Connection) GetStructureWithOptions(opts *StructureOptions) ([]*Structure, error) {
+	structure, err := c.GetStructure()
+	if err != nil {
+		return nil, err
+	}
+
+	classifier, ok := c.driver.(SystemObjectClassifier)
+	if !ok || (opts != nil && opts.IncludeSystem) {
+		return structure, nil
+	}
+
+	filtered := make([]*Structure, 0, len(structure))
+	for _, node := range structure {
+		if !classifier.IsSystem(node) {
+			filtered = append(filtered, node)
+		}
+	}
+
+	return filtered, nil
+}
+
+// GetStructureChildren returns children of a lazy structure node (see Structure.Lazy).
+// Functions, procedures and types of the schema are included. Children are cached
+// the same way as the structure (see GetStructure).
+func (c *Connection) GetStructureChildren(parent *Structure) ([]*Structure, error) {
+	if parent == nil {
+		return nil, fmt.Errorf("parent cannot be nil")
+	}
+
+	loader, ok := c.driver.(StructureLoader)
+	if !ok {
+		return nil, ErrLazyStructureNotSupported
+	}
+
+	if children, ok := c.structure.get(parent); ok {
+		return children, nil
+	}
+
+	children, err := c.loadStructureChildren(loader, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	c.structure.set(parent, children)
+	return children, nil
+}
+
+func (c *Connection) loadStructureChildren(loader StructureLoader, parent *Structure) ([]*Structure, error) {
+	children, err := loader.StructureChildren(context.Background(), parent)
+	if err != nil {
+		return nil, fmt.Errorf("loader.StructureChildren: %w", err)
+	}
+
+	if parent.Type == StructureTypeNone {
+		objects, err := c.schemaObjects()
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range objects {
+			if object.Schema == parent.Schema {
+				children = append(children, object)
+			}
+		}
+	}
+
+	return children, nil
+}
+
+// schemaObjects returns objects which drivers list apart from the structure
+// (functions, procedures and user-defined types).
+func (c *Connection) schemaObjects() ([]*Structure, error) {
+	var objects []*Structure
+
+	if lister, ok := c.driver.(RoutineLister); ok {
+		routines, err := lister.Routines()
+		if err != nil {
+			return nil, fmt.Errorf("lister.Routines: %w", err)
+		}
+		objects = append(objects, routines...)
+	}
+
+	if lister, ok := c.driver.(TypeLister); ok {
+		types, err := lister.Types()
+		if err != nil {
+			return nil, fmt.Errorf("lister.Types: %w", err)
+		}
+		objects = append(objects, types...)
+	}
+
+	return objects, nil
+}
+
+// getStructureRoots returns top-level nodes of a lazily loaded structure.
+func (c *Connection) getStructureRoots(loader StructureLoader) ([]*Structure, error) {
+	roots, err := loader.StructureRoots(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("loader.StructureRoots: %w", err)
+	}
+
+	for _, root := range roots {
+		if len(root.Children) < 1 {
+			root.Lazy = true
+		}
+	}
+
+	return roots, nil
+}
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// structureCache holds the structure of a connection, so that it isn't
+// loaded from the database every time the layout is displayed.
+type structureCache struct {
+	mu sync.Mutex
+	// cached entries expire after ttl (0 means they never expire)
+	ttl time.Duration
+	// top-level structure (key "")
+	entries map[string]*structureCacheEntry
+	// columns of tables and views which were requested for completion
+	columns map[string]*columnCacheEntry
+}
+
+type structureCacheEntry struct {
+	structure []*Structure
+	loaded    time.Time
+}
+
+type columnCacheEntry struct {
+	schema  string
+	columns []*Column
+	loaded  time.Time
+}
+
+func newStructureCache(ttl time.Duration) *structureCache {
+	return &structureCache{
+		ttl:     ttl,
+		entries: make(map[string]*structureCacheEntry),
+		columns: make(map[string]*columnCacheEntry),
+	}
+}
+
+// structureCacheKey returns the cache key of children of the node.
+// Nil node is the root of the structure.
+func structureCacheKey(node *Structure) string {
+	if node == nil {
+		return ""
+	}
+	return node.Type.String() + "\x00" + node.Schema + "\x00" + node.Name
+}
+
+func (sc *structureCache) get(node *Structure) ([]*Structure, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	key := structureCacheKey(node)
+	entry, ok := sc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if sc.ttl > 0 && time.Since(entry.loaded) > sc.ttl {
+		delete(sc.entries, key)
+		return nil, false
+	}
+
+	return entry.structure, true
+}
+
+func (sc *structureCache) set(node *Structure, structure []*Structure) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	sc.entries[structureCacheKey(node)] = &structureCacheEntry{
+		structure: structure,
+		loaded:    time.Now(),
+	}
+}
+
+func columnCacheKey(schema, table string) string {
+	return schema + "\x00" + table
+}
+
+func (sc *structureCache) getColumns(schema, table string) ([]*Column, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	key := columnCacheKey(schema, table)
+	entry, ok := sc.columns[key]
+	if !ok {
+		return nil, false
+	}
+	if sc.ttl > 0 && time.Since(entry.loaded) > sc.ttl {
+		delete(sc.columns, key)
+		return nil, false
+	}
+
+	return entry.columns, true
+}
+
+func (sc *structureCache) setColumns(schema, table string, columns []*Column) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	sc.columns[columnCacheKey(schema, table)] = &columnCacheEntry{
+		schema:  schema,
+		columns: columns,
+		loaded:  time.Now(),
+	}
+}
+
+// invalidate removes cached children of the node and cached columns of
+// objects in the node. Nil node clears the whole cache.
+func (sc *structureCache) invalidate(node *Structure) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if node == nil {
+		sc.entries = make(map[string]*structureCacheEntry)
+		sc.columns = make(map[string]*columnCacheEntry)
+		return
+	}
+	delete(sc.entries, structureCacheKey(node))
+	for key, entry := range sc.columns {
+		if entry.schema == node.Schema {
+			delete(sc.columns, key)
+		}
+	}
+}
+
+// RefreshStructure drops the cached structure, so that it is loaded from
+// the database the next time it's requested. If node is provided and the
+// driver loads the structure incrementally (see StructureLoader), only
+// children of the node are dropped, otherwise the whole structure is.
+func (c *Connection) RefreshStructure(node *Structure) {
+	if _, ok := c.driver.(StructureLoader); !ok {
+		node = nil
+	}
+	c.structure.invalidate(node)
+}
+
+// changesStructure reports whether any of the statements in query is a DDL
+// statement which could change the structure.
+func changesStructure(query string) bool {
+	for _, stmt := range strings.Split(normalizeStatement(query), ";") {
+		match := guardFirstWordRe.FindStringSubmatch(stmt)
+		if match == nil {
+			continue
+		}
+
+		switch strings.ToLower(match[1]) {
+		case "create", "alter", "drop", "rename":
+			return true
+		}
+	}
+
+	return false
+}
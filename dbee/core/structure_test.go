@@ -0,0 +1,175 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_LazyStructure(t *testing.T) {
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 1),
+		mock.AdapterWithLazyStructure([]*core.Structure{
+			{
+				Name:   "public",
+				Schema: "public",
+				Type:   core.StructureTypeNone,
+				Children: []*core.Structure{
+					{Name: "users", Schema: "public", Type: core.StructureTypeTable},
+				},
+			},
+			{Name: "audit", Schema: "audit", Type: core.StructureTypeNone},
+		}),
+		mock.AdapterWithRoutine(&core.Structure{
+			Name:   "add",
+			Schema: "public",
+			Type:   core.StructureTypeFunction,
+		}, "CREATE FUNCTION add() ..."),
+	)
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	// only top-level nodes are loaded
+	structure, err := connection.GetStructure()
+	r.NoError(err)
+	r.Len(structure, 2)
+	r.True(structure[0].Lazy)
+	r.Empty(structure[0].Children)
+
+	children, err := connection.GetStructureChildren(structure[0])
+	r.NoError(err)
+	r.Len(children, 2)
+	r.Equal("users", children[0].Name)
+	r.Equal(core.StructureTypeFunction, children[1].Type)
+
+	children, err = connection.GetStructureChildren(structure[1])
+	r.NoError(err)
+	r.Empty(children)
+}
+
+func TestConnection_GetStructureChildren_NotSupported(t *testing.T) {
+	r := require.New(t)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(mock.NewRows(0, 1)))
+	r.NoError(err)
+
+	_, err = connection.GetStructureChildren(&core.Structure{Name: "public"})
+	r.ErrorIs(err, core.ErrLazyStructureNotSupported)
+}
+
+func TestConnection_StructureCache(t *testing.T) {
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 1),
+		mock.AdapterWithTableDefinition("users", []*core.Column{{Name: "id", Type: "int"}}),
+	)
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	_, err = connection.GetStructure()
+	r.NoError(err)
+	_, err = connection.GetStructure()
+	r.NoError(err)
+	r.Equal(1, adapter.StructureLoads())
+
+	// explicit refresh
+	connection.RefreshStructure(nil)
+	_, err = connection.GetStructure()
+	r.NoError(err)
+	r.Equal(2, adapter.StructureLoads())
+
+	// DDL statements invalidate the cache
+	call := connection.Execute("SELECT 1", nil)
+	<-call.Done()
+	r.NoError(call.Err())
+	_, err = connection.GetStructure()
+	r.NoError(err)
+	r.Equal(2, adapter.StructureLoads())
+
+	call = connection.Execute("CREATE TABLE logs (msg text)", nil)
+	<-call.Done()
+	r.NoError(call.Err())
+	_, err = connection.GetStructure()
+	r.NoError(err)
+	r.Equal(3, adapter.StructureLoads())
+}
+
+func TestConnection_StructureCache_Lazy(t *testing.T) {
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 1),
+		mock.AdapterWithLazyStructure([]*core.Structure{
+			{Name: "public", Schema: "public", Type: core.StructureTypeNone},
+			{Name: "audit", Schema: "audit", Type: core.StructureTypeNone},
+		}),
+	)
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	structure, err := connection.GetStructure()
+	r.NoError(err)
+	for i := 0; i < 2; i++ {
+		for _, s := range structure {
+			_, err = connection.GetStructureChildren(s)
+			r.NoError(err)
+		}
+	}
+	r.Equal(3, adapter.StructureLoads())
+
+	// refreshing a node reloads only its children
+	connection.RefreshStructure(structure[0])
+	_, err = connection.GetStructure()
+	r.NoError(err)
+	_, err = connection.GetStructureChildren(structure[0])
+	r.NoError(err)
+	_, err = connection.GetStructureChildren(structure[1])
+	r.NoError(err)
+	r.Equal(4, adapter.StructureLoads())
+}
+
+func TestConnection_StructureCache_TTL(t *testing.T) {
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 1))
+	connection, err := core.NewConnection(&core.ConnectionParams{StructureTTL: 1}, adapter)
+	r.NoError(err)
+
+	_, err = connection.GetStructure()
+	r.NoError(err)
+	time.Sleep(1100 * time.Millisecond)
+	_, err = connection.GetStructure()
+	r.NoError(err)
+	r.Equal(2, adapter.StructureLoads())
+}
+
+func TestConnection_GetStructureWithOptions(t *testing.T) {
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 1),
+		mock.AdapterWithLazyStructure([]*core.Structure{
+			{Name: "public", Schema: "public", Type: core.StructureTypeNone},
+			{Name: "pg_catalog", Schema: "pg_catalog", Type: core.StructureTypeNone},
+		}),
+		mock.AdapterWithSystemSchemas("pg_catalog"),
+	)
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	// system schemas are hidden by default
+	structure, err := connection.GetStructureWithOptions(nil)
+	r.NoError(err)
+	r.Len(structure, 1)
+	r.Equal("public", structure[0].Name)
+
+	structure, err = connection.GetStructureWithOptions(&core.StructureOptions{IncludeSystem: true})
+	r.NoError(err)
+	r.Len(structure, 2)
+
+	// both requests are served from the same cache
+	r.Equal(1, adapter.StructureLoads())
+}
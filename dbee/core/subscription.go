@@ -0,0 +1,41 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+var ErrSubscriptionsNotSupported = errors.New("subscriptions not supported")
+
+// Subscriber is an optional interface for drivers which can subscribe to
+// events of the database: notification channels (e.g. LISTEN of postgres),
+// change streams (e.g. of mongo collections) or pub/sub channels (e.g. of
+// redis).
+type Subscriber interface {
+	// Subscribe returns a stream with a row for every event of the channel.
+	// The stream doesn't end on its own, only when ctx is canceled.
+	Subscribe(ctx context.Context, channel string) (ResultStream, error)
+}
+
+// Subscribe subscribes to the channel of the database (see [Subscriber]).
+// Events are appended to the result of the call as they arrive, so it keeps
+// retrieving rows until it's canceled, which closes the subscription.
+func (c *Connection) Subscribe(channel string, onEvent func(CallState, *Call)) *Call {
+	exec := func(ctx context.Context) (ResultStream, error) {
+		subscriber, ok := c.driver.(Subscriber)
+		if !ok {
+			return nil, ErrSubscriptionsNotSupported
+		}
+		rows, err := subscriber.Subscribe(ctx, strings.TrimSpace(channel))
+		if err != nil {
+			return nil, err
+		}
+		if meta := rows.Meta(); meta != nil {
+			meta.Live = true
+		}
+		return c.masker.stream(rows), nil
+	}
+
+	return newCallFromExecutor(exec, "-- subscription: "+channel, onEvent)
+}
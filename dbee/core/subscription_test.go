@@ -0,0 +1,23 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_Subscribe_NotSupported(t *testing.T) {
+	r := require.New(t)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(mock.NewRows(0, 1)))
+	r.NoError(err)
+
+	call := connection.Subscribe("jobs", nil)
+	<-call.Done()
+
+	r.ErrorIs(call.Err(), core.ErrSubscriptionsNotSupported)
+	r.Equal("-- subscription: jobs", call.GetQuery())
+}
@@ -0,0 +1,423 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// TableCompareOptions configure a comparison of rows of a table on two
+	// connections.
+	TableCompareOptions struct {
+		// compared table of the source connection
+		Schema string
+		Table  string
+		// table of the target connection, defaults to Schema and Table
+		TargetSchema string
+		TargetTable  string
+		// columns identifying rows (e.g. the primary key)
+		KeyColumns []string
+		// maximum number of reported differing rows, 100 if zero and all if
+		// negative
+		MaxDiffs int
+	}
+
+	// TableDataDiff is the difference between rows of a table on the source
+	// and the target connection (e.g. a primary and its replica). It describes
+	// the target relative to the source.
+	TableDataDiff struct {
+		KeyColumns []string
+		// columns of both tables, which are compared
+		Columns    []string
+		SourceRows int
+		TargetRows int
+		// number of rows of the target only
+		Inserted int
+		// number of rows of the source only
+		Deleted int
+		// number of rows whose compared columns differ
+		Changed int
+		// differing rows, up to MaxDiffs
+		Diffs []*TableRowDiff
+	}
+
+	// TableRowDiff is a row which differs between the source and the target.
+	// Missing rows were deleted from the target, extra rows were inserted.
+	TableRowDiff struct {
+		Kind DiffKind
+		// values of key columns
+		Key Row
+		// values of compared columns, nil for extra or missing rows
+		Source Row
+		Target Row
+		// names of columns which differ (changed rows only)
+		Changed []string
+	}
+)
+
+// IsEqual reports whether the tables have the same rows.
+func (d *TableDataDiff) IsEqual() bool {
+	return d.Inserted == 0 && d.Deleted == 0 && d.Changed == 0
+}
+
+// CompareTableData streams rows of the table on the source and the target
+// connection ordered by key columns and reports rows which were inserted,
+// deleted or changed in the target. Only columns of both tables are compared,
+// values of masked columns are compared masked. Both databases have to order
+// keys the same way (e.g. collations of text keys), otherwise the comparison
+// fails.
+func CompareTableData(ctx context.Context, source, target *Connection, opts *TableCompareOptions) (*TableDataDiff, error) {
+	if source == nil || target == nil {
+		return nil, errors.New("source and target connections are required")
+	}
+	if opts == nil || opts.Table == "" {
+		return nil, errors.New("table is required")
+	}
+	if len(opts.KeyColumns) == 0 {
+		return nil, errors.New("key columns are required")
+	}
+	maxDiffs := opts.MaxDiffs
+	if maxDiffs == 0 {
+		maxDiffs = defaultMaxRowDiffs
+	}
+	targetSchema, targetTable := opts.TargetSchema, opts.TargetTable
+	if targetTable == "" {
+		targetSchema, targetTable = opts.Schema, opts.Table
+	}
+
+	src, err := source.readTableOrdered(ctx, opts.Schema, opts.Table, opts.KeyColumns)
+	if err != nil {
+		return nil, fmt.Errorf("source %s: %w", source.GetName(), err)
+	}
+	defer src.stream.Close()
+	dst, err := target.readTableOrdered(ctx, targetSchema, targetTable, opts.KeyColumns)
+	if err != nil {
+		return nil, fmt.Errorf("target %s: %w", target.GetName(), err)
+	}
+	defer dst.stream.Close()
+
+	diff := &TableDataDiff{
+		KeyColumns: opts.KeyColumns,
+	}
+	// positions of compared columns in both tables
+	var srcColumns, dstColumns []int
+	for i, col := range src.header {
+		if j := columnIndex(dst.header, col); j >= 0 {
+			diff.Columns = append(diff.Columns, col)
+			srcColumns = append(srcColumns, i)
+			dstColumns = append(dstColumns, j)
+		}
+	}
+
+	addDiff := func(d *TableRowDiff) {
+		switch d.Kind {
+		case DiffKindMissing:
+			diff.Deleted++
+		case DiffKindExtra:
+			diff.Inserted++
+		default:
+			diff.Changed++
+		}
+		if maxDiffs < 0 || len(diff.Diffs) < maxDiffs {
+			diff.Diffs = append(diff.Diffs, d)
+		}
+	}
+
+	for _, r := range []*orderedTableReader{src, dst} {
+		if err := r.next(); err != nil {
+			return nil, err
+		}
+	}
+	for !src.done || !dst.done {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		cmp := 0
+		switch {
+		case src.done:
+			cmp = 1
+		case dst.done:
+			cmp = -1
+		default:
+			cmp = compareRows(src.key(), dst.key())
+		}
+
+		switch {
+		case cmp < 0:
+			addDiff(&TableRowDiff{Kind: DiffKindMissing, Key: src.key(), Source: pickValues(src.row, srcColumns)})
+			err = src.next()
+		case cmp > 0:
+			addDiff(&TableRowDiff{Kind: DiffKindExtra, Key: dst.key(), Target: pickValues(dst.row, dstColumns)})
+			err = dst.next()
+		default:
+			var changed []string
+			for i, col := range diff.Columns {
+				if compareValues(src.row[srcColumns[i]], dst.row[dstColumns[i]]) != 0 {
+					changed = append(changed, col)
+				}
+			}
+			if len(changed) > 0 {
+				addDiff(&TableRowDiff{
+					Kind:    DiffKindChanged,
+					Key:     src.key(),
+					Source:  pickValues(src.row, srcColumns),
+					Target:  pickValues(dst.row, dstColumns),
+					Changed: changed,
+				})
+			}
+			if err = src.next(); err == nil {
+				err = dst.next()
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	diff.SourceRows = src.rows
+	diff.TargetRows = dst.rows
+	return diff, nil
+}
+
+// orderedTableReader reads rows of a table ordered by key columns.
+type orderedTableReader struct {
+	name   string
+	stream ResultStream
+	header Header
+	// positions of key columns in the header
+	keys []int
+	row  Row
+	done bool
+	rows int
+}
+
+// readTableOrdered starts reading all rows of the table ordered by the key
+// columns. Rows are masked, but the connection's limits don't apply.
+func (c *Connection) readTableOrdered(ctx context.Context, schema, table string, keyColumns []string) (*orderedTableReader, error) {
+	dialect := c.Dialect()
+	name := dialect.QualifiedName(schema, table)
+	order := make([]string, len(keyColumns))
+	for i, col := range keyColumns {
+		order[i] = dialect.QuoteIdentifier(col)
+	}
+
+	query := "SELECT * FROM " + name + " ORDER BY " + strings.Join(order, ", ")
+	rows, err := c.driver.Query(ctx, query)
+	if err != nil {
+		return nil, classifyError(c.driver, err, query)
+	}
+	rows = c.masker.stream(rows)
+
+	r := &orderedTableReader{
+		name:   name,
+		stream: rows,
+		header: rows.Header(),
+	}
+	for _, col := range keyColumns {
+		i := columnIndex(r.header, col)
+		if i < 0 {
+			rows.Close()
+			return nil, fmt.Errorf("key column %q not found in %s", col, name)
+		}
+		r.keys = append(r.keys, i)
+	}
+	return r, nil
+}
+
+// next advances to the next row. It fails if the row's key comes before the
+// key of the previous row, because differences can't be found in rows which
+// aren't ordered.
+func (r *orderedTableReader) next() error {
+	if !r.stream.HasNext() {
+		r.done = true
+		r.row = nil
+		return nil
+	}
+	row, err := r.stream.Next()
+	if err != nil {
+		return fmt.Errorf("%s: %w", r.name, err)
+	}
+	if row == nil {
+		r.done = true
+		r.row = nil
+		return nil
+	}
+
+	prev := r.row
+	r.row = row
+	r.rows++
+	if prev != nil && compareRows(pickValues(prev, r.keys), r.key()) > 0 {
+		return fmt.Errorf("rows of %s aren't ordered by the key the way they are compared (e.g. collation of text keys)", r.name)
+	}
+	return nil
+}
+
+// key returns values of key columns of the current row.
+func (r *orderedTableReader) key() Row {
+	return pickValues(r.row, r.keys)
+}
+
+// columnIndex returns the position of the column in the header, compared
+// case insensitively (-1 if not found).
+func columnIndex(header Header, column string) int {
+	for i, col := range header {
+		if strings.EqualFold(col, column) {
+			return i
+		}
+	}
+	return -1
+}
+
+func pickValues(row Row, indexes []int) Row {
+	values := make(Row, len(indexes))
+	for i, idx := range indexes {
+		if idx < len(row) {
+			values[i] = row[idx]
+		}
+	}
+	return values
+}
+
+// compareRows compares rows value by value.
+func compareRows(a, b Row) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if cmp := compareValues(a[i], b[i]); cmp != 0 {
+			return cmp
+		}
+	}
+	return len(a) - len(b)
+}
+
+// compareValues orders values of different databases, which may return the
+// same value as different types (e.g. int32 and int64, or decimals as text).
+// NULLs come first.
+func compareValues(a, b any) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+
+	if ai, ok := integerValue(a); ok {
+		if bi, ok := integerValue(b); ok {
+			return compareOrdered(ai, bi)
+		}
+	}
+	// text is compared as a number only with a number, numeric text keys
+	// are ordered as text
+	if isNumber(a) || isNumber(b) {
+		af, aok := numericValue(a)
+		bf, bok := numericValue(b)
+		if aok && bok {
+			return compareOrdered(af, bf)
+		}
+	}
+
+	switch av := a.(type) {
+	case time.Time:
+		if bv, ok := b.(time.Time); ok {
+			return av.Compare(bv)
+		}
+	case bool:
+		if bv, ok := b.(bool); ok && av != bv {
+			if av {
+				return 1
+			}
+			return -1
+		}
+	case []byte:
+		if bv, ok := b.([]byte); ok {
+			return bytes.Compare(av, bv)
+		}
+	}
+
+	return strings.Compare(textValue(a), textValue(b))
+}
+
+func compareOrdered[T int64 | float64 | string](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func integerValue(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint8:
+		return int64(n), true
+	case uint16:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	}
+	return 0, false
+}
+
+func isNumber(v any) bool {
+	switch v.(type) {
+	case string, []byte:
+		return false
+	}
+	_, ok := numericValue(v)
+	return ok
+}
+
+// numericValue converts numbers and numeric text (e.g. decimals) to float64.
+func numericValue(v any) (float64, bool) {
+	if n, ok := integerValue(v); ok {
+		return float64(n), true
+	}
+	switch n := v.(type) {
+	case uint:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	case []byte:
+		f, err := strconv.ParseFloat(string(n), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func textValue(v any) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case []byte:
+		return string(s)
+	default:
+		return fmt.Sprint(v)
+	}
+}
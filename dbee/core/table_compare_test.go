@@ -0,0 +1,92 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestCompareTableData(t *testing.T) {
+	r := require.New(t)
+
+	primary, err := core.NewConnection(&core.ConnectionParams{Name: "primary"}, mock.NewAdapter(mock.NewRows(0, 6)))
+	r.NoError(err)
+
+	// row 1 is deleted, row 3 changed and row 7 inserted, keys are returned
+	// as different integer types
+	replica, err := core.NewConnection(&core.ConnectionParams{Name: "replica"}, mock.NewAdapter([]core.Row{
+		{int64(0), "row_0"},
+		{int64(2), "row_2"},
+		{int64(3), "changed"},
+		{int64(4), "row_4"},
+		{int64(5), "row_5"},
+		{int64(7), "row_7"},
+	}))
+	r.NoError(err)
+
+	diff, err := core.CompareTableData(context.Background(), primary, replica, &core.TableCompareOptions{
+		Table:      "t",
+		KeyColumns: []string{"header_0"},
+	})
+	r.NoError(err)
+
+	r.False(diff.IsEqual())
+	r.Equal([]string{"header_0", "header_1"}, diff.Columns)
+	r.Equal(6, diff.SourceRows)
+	r.Equal(6, diff.TargetRows)
+	r.Equal(1, diff.Inserted)
+	r.Equal(1, diff.Deleted)
+	r.Equal(1, diff.Changed)
+	r.Equal([]*core.TableRowDiff{
+		{Kind: core.DiffKindMissing, Key: core.Row{1}, Source: core.Row{1, "row_1"}},
+		{Kind: core.DiffKindChanged, Key: core.Row{3}, Source: core.Row{3, "row_3"}, Target: core.Row{int64(3), "changed"}, Changed: []string{"header_1"}},
+		{Kind: core.DiffKindExtra, Key: core.Row{int64(7)}, Target: core.Row{int64(7), "row_7"}},
+	}, diff.Diffs)
+
+	// reported rows are limited, all of them are counted
+	diff, err = core.CompareTableData(context.Background(), primary, replica, &core.TableCompareOptions{
+		Table:      "t",
+		KeyColumns: []string{"header_0"},
+		MaxDiffs:   1,
+	})
+	r.NoError(err)
+	r.Len(diff.Diffs, 1)
+	r.Equal(3, diff.Inserted+diff.Deleted+diff.Changed)
+
+	// equal tables
+	diff, err = core.CompareTableData(context.Background(), primary, primary, &core.TableCompareOptions{
+		Table:      "t",
+		KeyColumns: []string{"header_0"},
+	})
+	r.NoError(err)
+	r.True(diff.IsEqual())
+	r.Empty(diff.Diffs)
+}
+
+func TestCompareTableData_Errors(t *testing.T) {
+	r := require.New(t)
+
+	ordered, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(mock.NewRows(0, 3)))
+	r.NoError(err)
+	unordered, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter([]core.Row{{2, "b"}, {1, "a"}}))
+	r.NoError(err)
+
+	_, err = core.CompareTableData(context.Background(), ordered, ordered, &core.TableCompareOptions{Table: "t"})
+	r.Error(err)
+
+	_, err = core.CompareTableData(context.Background(), ordered, ordered, &core.TableCompareOptions{
+		Table:      "t",
+		KeyColumns: []string{"missing"},
+	})
+	r.ErrorContains(err, "key column")
+
+	_, err = core.CompareTableData(context.Background(), ordered, unordered, &core.TableCompareOptions{
+		Table:      "t",
+		KeyColumns: []string{"header_0"},
+	})
+	r.ErrorContains(err, "aren't ordered")
+}
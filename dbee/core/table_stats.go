@@ -0,0 +1,43 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrTableStatsNotSupported = errors.New("table statistics not supported")
+
+type (
+	// TableStats holds estimated row count and size of a table.
+	TableStats struct {
+		Schema string
+		Table  string
+		// estimated number of rows (-1 if unknown)
+		Rows int64
+		// size in bytes (-1 if unknown)
+		Size int64
+	}
+
+	// TableStatsProvider is an optional interface for drivers that can cheaply
+	// estimate row counts and sizes of all tables, usually from catalog
+	// statistics instead of scanning the tables.
+	TableStatsProvider interface {
+		TableStats(ctx context.Context) ([]*TableStats, error)
+	}
+)
+
+// GetTableStats returns estimated row counts and sizes of tables.
+func (c *Connection) GetTableStats() ([]*TableStats, error) {
+	provider, ok := c.driver.(TableStatsProvider)
+	if !ok {
+		return nil, ErrTableStatsNotSupported
+	}
+
+	stats, err := provider.TableStats(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("provider.TableStats: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,226 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+var (
+	ErrTransactionsNotSupported = errors.New("transactions not supported")
+	ErrNoTransaction            = errors.New("no transaction in progress")
+	ErrTransactionInProgress    = errors.New("transaction already in progress")
+	ErrInvalidSavepointName     = errors.New("invalid savepoint name")
+	ErrUnknownSavepoint         = errors.New("unknown savepoint")
+)
+
+var savepointNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+type (
+	// Transactor is an optional interface for drivers that support explicit transactions.
+	Transactor interface {
+		BeginTx(ctx context.Context) (Transaction, error)
+	}
+
+	// Transaction is a transaction in progress. All statements executed while
+	// the transaction is open run on the same database session.
+	Transaction interface {
+		Query(ctx context.Context, query string) (ResultStream, error)
+		// Savepoint creates a savepoint with the given name.
+		Savepoint(ctx context.Context, name string) error
+		// RollbackTo undoes all statements executed after the savepoint was created.
+		RollbackTo(ctx context.Context, name string) error
+		Commit() error
+		Rollback() error
+	}
+
+	// TransactionExecer is an optional interface for transactions that report
+	// the number of rows affected by a statement.
+	TransactionExecer interface {
+		Exec(ctx context.Context, query string) (affected int64, err error)
+	}
+)
+
+// BeginTransaction starts a transaction on the connection. All subsequent
+// statements are executed in the transaction until it's committed or rolled back.
+func (c *Connection) BeginTransaction() error {
+	transactor, ok := c.driver.(Transactor)
+	if !ok {
+		return ErrTransactionsNotSupported
+	}
+
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+
+	if c.tx != nil {
+		return ErrTransactionInProgress
+	}
+
+	tx, err := transactor.BeginTx(context.Background())
+	if err != nil {
+		return fmt.Errorf("transactor.BeginTx: %w", err)
+	}
+
+	c.tx = tx
+	c.savepoints = nil
+	return nil
+}
+
+func (c *Connection) CommitTransaction() error {
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+
+	if c.tx == nil {
+		return ErrNoTransaction
+	}
+
+	// the transaction is done even if commit fails
+	tx := c.tx
+	c.tx = nil
+	c.savepoints = nil
+
+	err := tx.Commit()
+	if err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+	return nil
+}
+
+func (c *Connection) RollbackTransaction() error {
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+
+	if c.tx == nil {
+		return ErrNoTransaction
+	}
+
+	tx := c.tx
+	c.tx = nil
+	c.savepoints = nil
+
+	err := tx.Rollback()
+	if err != nil {
+		return fmt.Errorf("tx.Rollback: %w", err)
+	}
+	return nil
+}
+
+// Savepoint creates a named savepoint in the current transaction.
+func (c *Connection) Savepoint(name string) error {
+	if !savepointNameRe.MatchString(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidSavepointName, name)
+	}
+
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+
+	if c.tx == nil {
+		return ErrNoTransaction
+	}
+
+	err := c.tx.Savepoint(context.Background(), name)
+	if err != nil {
+		return fmt.Errorf("tx.Savepoint: %w", err)
+	}
+
+	c.savepoints = append(c.savepoints, name)
+	return nil
+}
+
+// RollbackToSavepoint undoes all statements executed after the named savepoint
+// was created, without ending the transaction. Savepoints created after it are released.
+func (c *Connection) RollbackToSavepoint(name string) error {
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+
+	if c.tx == nil {
+		return ErrNoTransaction
+	}
+
+	// use the latest savepoint with this name
+	index := -1
+	for i := len(c.savepoints) - 1; i >= 0; i-- {
+		if c.savepoints[i] == name {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("%w: %q", ErrUnknownSavepoint, name)
+	}
+
+	err := c.tx.RollbackTo(context.Background(), name)
+	if err != nil {
+		return fmt.Errorf("tx.RollbackTo: %w", err)
+	}
+
+	c.savepoints = c.savepoints[:index+1]
+	return nil
+}
+
+// InTransaction reports whether the connection has a transaction in progress.
+func (c *Connection) InTransaction() bool {
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+	return c.tx != nil
+}
+
+// GetSavepoints returns names of savepoints in the current transaction.
+func (c *Connection) GetSavepoints() []string {
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+	return append([]string(nil), c.savepoints...)
+}
+
+// SetAutoCommit turns autocommit on or off for the connection's session. With autocommit
+// off, every statement joins an implicit transaction until it's explicitly committed
+// or rolled back. Turning autocommit back on commits the pending transaction.
+func (c *Connection) SetAutoCommit(enabled bool) error {
+	if !enabled {
+		if _, ok := c.driver.(Transactor); !ok {
+			return ErrTransactionsNotSupported
+		}
+	}
+
+	c.txMu.Lock()
+	c.manualCommit = !enabled
+	pending := c.tx != nil
+	c.txMu.Unlock()
+
+	if enabled && pending {
+		return c.CommitTransaction()
+	}
+	return nil
+}
+
+func (c *Connection) IsAutoCommit() bool {
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+	return !c.manualCommit
+}
+
+// statementTx returns the transaction the next statement should run in or nil
+// if it should run on its own. With autocommit off, a transaction is started
+// if none is in progress.
+func (c *Connection) statementTx() (Transaction, error) {
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+
+	if c.tx != nil || !c.manualCommit {
+		return c.tx, nil
+	}
+
+	transactor, ok := c.driver.(Transactor)
+	if !ok {
+		return nil, ErrTransactionsNotSupported
+	}
+	tx, err := transactor.BeginTx(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("transactor.BeginTx: %w", err)
+	}
+
+	c.tx = tx
+	c.savepoints = nil
+	return tx, nil
+}
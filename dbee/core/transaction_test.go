@@ -0,0 +1,107 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_Savepoints(t *testing.T) {
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 3))
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	// no transaction yet
+	r.ErrorIs(connection.Savepoint("a"), core.ErrNoTransaction)
+	r.ErrorIs(connection.CommitTransaction(), core.ErrNoTransaction)
+
+	r.NoError(connection.BeginTransaction())
+	r.ErrorIs(connection.BeginTransaction(), core.ErrTransactionInProgress)
+	r.True(connection.InTransaction())
+
+	r.NoError(connection.Savepoint("a"))
+	r.NoError(connection.Savepoint("b"))
+	r.NoError(connection.Savepoint("c"))
+	r.ErrorIs(connection.Savepoint("bad name; drop table x"), core.ErrInvalidSavepointName)
+	r.Equal([]string{"a", "b", "c"}, connection.GetSavepoints())
+
+	// statements are executed in the transaction
+	call := connection.Execute("select 1", nil)
+	select {
+	case <-call.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not finish in expected time")
+	}
+	r.NoError(call.Err())
+
+	// savepoints after the one rolled back to are released
+	r.NoError(connection.RollbackToSavepoint("b"))
+	r.Equal([]string{"a", "b"}, connection.GetSavepoints())
+	r.ErrorIs(connection.RollbackToSavepoint("c"), core.ErrUnknownSavepoint)
+
+	r.NoError(connection.CommitTransaction())
+	r.False(connection.InTransaction())
+	r.Empty(connection.GetSavepoints())
+
+	r.Equal([]string{
+		"begin",
+		"savepoint a",
+		"savepoint b",
+		"savepoint c",
+		"query select 1",
+		"rollback to b",
+		"commit",
+	}, adapter.TransactionOps())
+}
+
+func TestConnection_AutoCommit(t *testing.T) {
+	r := require.New(t)
+
+	adapter := mock.NewAdapter(mock.NewRows(0, 3))
+	connection, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	execute := func(query string) {
+		call := connection.Execute(query, nil)
+		select {
+		case <-call.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("call did not finish in expected time")
+		}
+		r.NoError(call.Err())
+	}
+
+	r.True(connection.IsAutoCommit())
+	execute("outside")
+	r.False(connection.InTransaction())
+
+	// statements join an implicit transaction
+	r.NoError(connection.SetAutoCommit(false))
+	execute("first")
+	r.True(connection.InTransaction())
+	execute("second")
+
+	// a new transaction starts after commit
+	r.NoError(connection.CommitTransaction())
+	execute("third")
+
+	// turning autocommit on commits the pending transaction
+	r.NoError(connection.SetAutoCommit(true))
+	r.False(connection.InTransaction())
+
+	r.Equal([]string{
+		"begin",
+		"query first",
+		"query second",
+		"commit",
+		"begin",
+		"query third",
+		"commit",
+	}, adapter.TransactionOps())
+}
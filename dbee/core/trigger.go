@@ -0,0 +1,49 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrTriggersNotSupported = errors.New("listing triggers not supported")
+
+type (
+	// Trigger is a trigger defined on a table.
+	Trigger struct {
+		Name string
+		// events which fire the trigger (e.g. "INSERT OR UPDATE")
+		Event string
+		// BEFORE, AFTER or INSTEAD OF
+		Timing string
+		// function executed by the trigger, if the database uses one
+		Function string
+	}
+
+	// TriggerLister is an optional interface for drivers that can list
+	// triggers of a table. Drivers which implement it should also return
+	// definitions of triggers (StructureTypeTrigger) from DefinitionProvider,
+	// where opts.Table is the name of the trigger.
+	TriggerLister interface {
+		Triggers(ctx context.Context, opts *TableOptions) ([]*Trigger, error)
+	}
+)
+
+// GetTriggers returns triggers of the table described by opts.
+func (c *Connection) GetTriggers(opts *TableOptions) ([]*Trigger, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("opts cannot be nil")
+	}
+
+	lister, ok := c.driver.(TriggerLister)
+	if !ok {
+		return nil, ErrTriggersNotSupported
+	}
+
+	triggers, err := lister.Triggers(context.Background(), opts)
+	if err != nil {
+		return nil, fmt.Errorf("lister.Triggers: %w", err)
+	}
+
+	return triggers, nil
+}
@@ -1,6 +1,9 @@
 package core
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 type SchemaType int
 
@@ -20,6 +23,13 @@ type (
 	Formatter interface {
 		Format(header Header, rows []Row, opts *FormatterOptions) ([]byte, error)
 	}
+
+	// StreamFormatter is an optional interface of formatters which can convert
+	// rows in chunks. Formatted chunks are concatenated, first and last tell
+	// whether the chunk starts or ends the output.
+	StreamFormatter interface {
+		FormatChunk(header Header, rows []Row, opts *FormatterOptions, first, last bool) ([]byte, error)
+	}
 )
 
 type (
@@ -31,6 +41,38 @@ type (
 	Meta struct {
 		// type of schema (schemaful or schemaless)
 		SchemaType SchemaType
+		// limit that was automatically added to the query (0 if none)
+		InjectedLimit int
+		// number of times the query was retried because of transient errors
+		Retries int
+		// method used to sample rows of the query (empty if it wasn't sampled)
+		Sampling string
+		// notices, warnings and messages the server sent during the query
+		Notices []string
+		// id of the query on the server (e.g. to look it up in the query
+		// history of a warehouse), empty if the driver doesn't report it
+		QueryID string
+		// number of bytes the query processed (for databases billing
+		// scanned bytes, 0 if the driver doesn't report it)
+		BytesProcessed int64
+		// rows keep arriving until the stream is closed (e.g. events of a
+		// subscription), so reading the result doesn't wait for missing rows
+		Live bool
+		// durations of execution phases
+		Metrics Metrics
+	}
+
+	// Metrics break down the time a call took, to tell a slow query apart
+	// from slow fetching or rendering.
+	Metrics struct {
+		// time until the server returned the first result
+		Execution time.Duration
+		// time spent fetching rows from the server
+		Fetch time.Duration
+		// time spent serializing the result to the archive
+		Archive time.Duration
+		// time spent formatting the last displayed page of the result
+		Format time.Duration
 	}
 
 	// ResultStream is a result from executed query and has a form of an iterator
@@ -41,6 +83,13 @@ type (
 		HasNext() bool
 		Close()
 	}
+
+	// MultiResultStream is an optional interface for result streams that can hold
+	// more than one result set. When the current set is drained, NextResultSet
+	// advances the stream to the next one, after which Header describes the new set.
+	MultiResultStream interface {
+		NextResultSet() bool
+	}
 )
 
 type StructureType int
@@ -49,6 +98,15 @@ const (
 	StructureTypeNone StructureType = iota
 	StructureTypeTable
 	StructureTypeView
+	StructureTypeMaterializedView
+	StructureTypeFunction
+	StructureTypeProcedure
+	StructureTypeTrigger
+	StructureTypeIndex
+	StructureTypeSequence
+	StructureTypeColumn
+	StructureTypeType
+	StructureTypeEnumValue
 )
 
 func (s StructureType) String() string {
@@ -59,6 +117,24 @@ func (s StructureType) String() string {
 		return "table"
 	case StructureTypeView:
 		return "view"
+	case StructureTypeMaterializedView:
+		return "materialized_view"
+	case StructureTypeFunction:
+		return "function"
+	case StructureTypeProcedure:
+		return "procedure"
+	case StructureTypeTrigger:
+		return "trigger"
+	case StructureTypeIndex:
+		return "index"
+	case StructureTypeSequence:
+		return "sequence"
+	case StructureTypeColumn:
+		return "column"
+	case StructureTypeType:
+		return "type"
+	case StructureTypeEnumValue:
+		return "enum_value"
 	default:
 		return ""
 	}
@@ -70,6 +146,24 @@ func StructureTypeFromString(s string) StructureType {
 		return StructureTypeTable
 	case "view":
 		return StructureTypeView
+	case "materialized_view":
+		return StructureTypeMaterializedView
+	case "function":
+		return StructureTypeFunction
+	case "procedure":
+		return StructureTypeProcedure
+	case "trigger":
+		return StructureTypeTrigger
+	case "index":
+		return StructureTypeIndex
+	case "sequence":
+		return StructureTypeSequence
+	case "column":
+		return StructureTypeColumn
+	case "type":
+		return StructureTypeType
+	case "enum_value":
+		return StructureTypeEnumValue
 	default:
 		return StructureTypeNone
 	}
@@ -82,8 +176,16 @@ type Structure struct {
 	Schema string
 	// Type of layout
 	Type StructureType
+	// Status of the node if the database reports one
+	// (e.g. whether a materialized view is populated or stale)
+	Status string
 	// Children layout nodes
 	Children []*Structure
+	// Lazy is set if children of the node are not loaded yet
+	// (see Connection.GetStructureChildren)
+	Lazy bool
+	// Comment (description) of the object stored in the database
+	Comment string
 }
 
 type Column struct {
@@ -91,4 +193,12 @@ type Column struct {
 	Name string
 	// Database data type
 	Type string
+	// NotNull is set if the column is known not to accept NULL
+	NotNull bool
+	// Default value expression (empty if none or unknown)
+	Default string
+	// PrimaryKey is set if the column is a part of table's primary key
+	PrimaryKey bool
+	// Comment (description) of the column stored in the database
+	Comment string
 }
@@ -0,0 +1,10 @@
+package core
+
+// TypeLister is an optional interface for drivers that can list user-defined
+// types, such as enums and domains. Types are returned as a flat list of
+// StructureTypeType nodes, which are placed under the schema nodes of the
+// structure. Status of a type node describes its kind (e.g. "enum") and
+// values of enums are its children (StructureTypeEnumValue).
+type TypeLister interface {
+	Types() ([]*Structure, error)
+}
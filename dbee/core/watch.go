@@ -0,0 +1,341 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidWatchInterval = errors.New("watch interval must be positive")
+
+// default number of rows of a watched result which are compared
+const defaultWatchMaxRows = 1000
+
+type WatchID string
+
+type (
+	// WatchOptions configure a watched query.
+	WatchOptions struct {
+		Interval time.Duration
+		// columns identifying rows (e.g. the primary key), which makes
+		// changed rows distinguishable from deleted and inserted ones. Rows are
+		// compared as a whole if empty.
+		KeyColumns []string
+		// every update carries all rows of the refreshed result instead of
+		// the changes only
+		FullRefresh bool
+		// maximum number of compared rows of a result, 1000 if zero
+		MaxRows int
+	}
+
+	// WatchUpdate is a refreshed result of a watched query which differs from
+	// the previous one. The first update, updates whose header changed and
+	// full refreshes carry all rows, others carry only differing rows.
+	WatchUpdate struct {
+		// number of the run which produced the update
+		Run  int
+		Call *Call
+		// error of the run (the previous result is kept for comparison)
+		Err    error
+		Header Header
+		// all rows of the result, nil if the update carries only changes
+		Rows []Row
+		// rows which differ from the previous result: extra rows were
+		// inserted, missing ones deleted and changed ones have the same key
+		// (Source is the previous row and Target the refreshed one)
+		Diffs    []*TableRowDiff
+		Inserted int
+		Deleted  int
+		Changed  int
+		// the result has more than MaxRows rows, the rest wasn't compared
+		Truncated bool
+	}
+)
+
+// Watch re-runs a query on a fixed interval and reports how its result
+// changed since the previous run (e.g. to monitor job queues or status
+// tables). Runs are made by a schedule, so every run is a regular call.
+type Watch struct {
+	id       WatchID
+	opts     WatchOptions
+	schedule *Schedule
+
+	onUpdate func(*Watch, *WatchUpdate)
+
+	mu sync.Mutex
+	// header and rows of the previous successful run
+	header Header
+	rows   []Row
+}
+
+// NewWatch creates a new watch and starts it immediately.
+// execute is called on every tick and should return a call which was started by it.
+// onUpdate is called with the first result and whenever a refreshed result
+// differs from the previous one or a run fails.
+func NewWatch(connID ConnectionID, query string, opts *WatchOptions, execute func() (*Call, error), onUpdate func(*Watch, *WatchUpdate)) (*Watch, error) {
+	if opts == nil || opts.Interval <= 0 {
+		return nil, ErrInvalidWatchInterval
+	}
+	if execute == nil {
+		return nil, errors.New("no executor provided")
+	}
+
+	schedule, err := newSchedule(connID, query, opts.Interval, execute)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watch{
+		id:       WatchID(uuid.New().String()),
+		opts:     *opts,
+		schedule: schedule,
+
+		onUpdate: onUpdate,
+	}
+	if w.opts.MaxRows <= 0 {
+		w.opts.MaxRows = defaultWatchMaxRows
+	}
+
+	schedule.onFinished = w.update
+	go schedule.loop()
+
+	return w, nil
+}
+
+// update compares the result of the finished run with the previous one.
+func (w *Watch) update(run int, call *Call) {
+	update := &WatchUpdate{
+		Run:  run,
+		Call: call,
+	}
+	// the state of the call is updated asynchronously, its error isn't
+	if err := call.Err(); err != nil {
+		update.Err = err
+		w.notify(update)
+		return
+	}
+
+	header, rows, truncated, err := w.readResult(call)
+	if err != nil {
+		update.Err = err
+		w.notify(update)
+		return
+	}
+	update.Header = header
+	update.Truncated = truncated
+
+	w.mu.Lock()
+	prevHeader, prevRows := w.header, w.rows
+	w.header, w.rows = header, rows
+	w.mu.Unlock()
+
+	if prevHeader == nil || !sameHeader(prevHeader, header) {
+		update.Rows = rows
+		w.notify(update)
+		return
+	}
+
+	diffs, err := DiffRows(header, prevRows, rows, w.opts.KeyColumns)
+	if err != nil {
+		update.Err = err
+		w.notify(update)
+		return
+	}
+	if len(diffs) == 0 {
+		return
+	}
+
+	update.Diffs = diffs
+	for _, diff := range diffs {
+		switch diff.Kind {
+		case DiffKindExtra:
+			update.Inserted++
+		case DiffKindMissing:
+			update.Deleted++
+		default:
+			update.Changed++
+		}
+	}
+	if w.opts.FullRefresh {
+		update.Rows = rows
+	}
+	w.notify(update)
+}
+
+// readResult returns the header and up to MaxRows rows of the call.
+func (w *Watch) readResult(call *Call) (Header, []Row, bool, error) {
+	result, err := call.GetResult()
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("call.GetResult: %w", err)
+	}
+	length := result.Len()
+	rows, err := result.Rows(0, min(length, w.opts.MaxRows))
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("result.Rows: %w", err)
+	}
+	return result.Header(), rows, length > w.opts.MaxRows, nil
+}
+
+func (w *Watch) notify(update *WatchUpdate) {
+	if w.onUpdate != nil {
+		w.onUpdate(w, update)
+	}
+}
+
+// Stop stops the watch. Calls which are still executing are canceled.
+func (w *Watch) Stop() {
+	w.schedule.Stop()
+}
+
+func (w *Watch) GetID() WatchID {
+	return w.id
+}
+
+func (w *Watch) GetConnectionID() ConnectionID {
+	return w.schedule.GetConnectionID()
+}
+
+func (w *Watch) GetQuery() string {
+	return w.schedule.GetQuery()
+}
+
+func (w *Watch) GetOptions() WatchOptions {
+	return w.opts
+}
+
+// GetRuns returns the number of runs so far.
+func (w *Watch) GetRuns() int {
+	return w.schedule.GetRuns()
+}
+
+// GetLastCallID returns the id of the call produced by the latest run.
+func (w *Watch) GetLastCallID() CallID {
+	return w.schedule.GetLastCallID()
+}
+
+// TableWatch returns the query and options which watch rows of the table.
+// Rows are identified by key columns of the options or by the primary key of
+// the table (if the driver can look it up), and no more than MaxRows rows are
+// read by a run.
+func (c *Connection) TableWatch(schema, table string, opts *WatchOptions) (string, *WatchOptions) {
+	watchOpts := &WatchOptions{}
+	if opts != nil {
+		*watchOpts = *opts
+	}
+	if watchOpts.MaxRows <= 0 {
+		watchOpts.MaxRows = defaultWatchMaxRows
+	}
+
+	if len(watchOpts.KeyColumns) == 0 {
+		if lister, ok := c.driver.(KeyLister); ok {
+			keys, err := lister.PrimaryKey(&TableOptions{Schema: schema, Table: table})
+			if err == nil {
+				watchOpts.KeyColumns = keys
+			}
+		}
+	}
+
+	dialect := c.Dialect()
+	query := "SELECT * FROM " + dialect.QualifiedName(schema, table)
+	if len(watchOpts.KeyColumns) > 0 {
+		// truncated results keep the same rows
+		keys := make([]string, len(watchOpts.KeyColumns))
+		for i, key := range watchOpts.KeyColumns {
+			keys[i] = dialect.QuoteIdentifier(key)
+		}
+		query += " ORDER BY " + strings.Join(keys, ", ")
+	}
+	// one more row tells that the result is truncated
+	if limited, ok := dialect.InjectLimit(query, watchOpts.MaxRows+1); ok {
+		query = limited
+	}
+
+	return query, watchOpts
+}
+
+func sameHeader(a, b Header) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// DiffRows reports rows which were inserted (extra), deleted (missing) or
+// changed in the current rows relative to the previous ones. Rows with the
+// same values of key columns are the same row, which changed if any other
+// value differs. Without key columns, rows are compared as a whole regardless
+// of their order, so changes are reported as deleted and inserted rows.
+func DiffRows(header Header, previous, current []Row, keyColumns []string) ([]*TableRowDiff, error) {
+	keys := make([]int, len(keyColumns))
+	for i, col := range keyColumns {
+		keys[i] = columnIndex(header, col)
+		if keys[i] < 0 {
+			return nil, fmt.Errorf("%w: key column %q is not in the result", ErrUnknownColumn, col)
+		}
+	}
+
+	// previous rows which aren't matched by the current ones yet, duplicates
+	// are counted
+	remaining := make(map[string][]int, len(previous))
+	rowIdentity := func(row Row) string {
+		if len(keys) == 0 {
+			return rowKey(row)
+		}
+		return rowKey(pickValues(row, keys))
+	}
+	for i, row := range previous {
+		id := rowIdentity(row)
+		remaining[id] = append(remaining[id], i)
+	}
+
+	var diffs []*TableRowDiff
+	matched := make([]bool, len(previous))
+	for _, row := range current {
+		id := rowIdentity(row)
+		indexes := remaining[id]
+		if len(indexes) == 0 {
+			diff := &TableRowDiff{Kind: DiffKindExtra, Target: row}
+			if len(keys) > 0 {
+				diff.Key = pickValues(row, keys)
+			}
+			diffs = append(diffs, diff)
+			continue
+		}
+		remaining[id] = indexes[1:]
+		matched[indexes[0]] = true
+
+		prev := previous[indexes[0]]
+		if len(keys) == 0 || rowKey(prev) == rowKey(row) {
+			continue
+		}
+		diff := &TableRowDiff{Kind: DiffKindChanged, Key: pickValues(row, keys), Source: prev, Target: row}
+		for i, col := range header {
+			if i < len(prev) && i < len(row) && rowKey(Row{prev[i]}) != rowKey(Row{row[i]}) {
+				diff.Changed = append(diff.Changed, col)
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+
+	for i, row := range previous {
+		if matched[i] {
+			continue
+		}
+		diff := &TableRowDiff{Kind: DiffKindMissing, Source: row}
+		if len(keys) > 0 {
+			diff.Key = pickValues(row, keys)
+		}
+		diffs = append(diffs, diff)
+	}
+
+	return diffs, nil
+}
@@ -0,0 +1,121 @@
+package core_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestWatch(t *testing.T) {
+	r := require.New(t)
+
+	before, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(mock.NewRows(0, 3)))
+	r.NoError(err)
+	after, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(mock.NewRows(1, 4)))
+	r.NoError(err)
+
+	// the result changes after the second run
+	var (
+		mu      sync.Mutex
+		runs    int
+		updates []*core.WatchUpdate
+	)
+	watch, err := core.NewWatch(before.GetID(), "_", &core.WatchOptions{Interval: 100 * time.Millisecond},
+		func() (*core.Call, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			runs++
+			if runs < 3 {
+				return before.Execute("_", nil), nil
+			}
+			return after.Execute("_", nil), nil
+		},
+		func(_ *core.Watch, update *core.WatchUpdate) {
+			mu.Lock()
+			defer mu.Unlock()
+			updates = append(updates, update)
+		})
+	r.NoError(err)
+
+	time.Sleep(550 * time.Millisecond)
+	watch.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	r.GreaterOrEqual(watch.GetRuns(), 4)
+	// the first result and the single change
+	r.Len(updates, 2)
+
+	r.Equal(1, updates[0].Run)
+	r.Equal(core.Header{"header_0", "header_1"}, updates[0].Header)
+	r.Equal(mock.NewRows(0, 3), updates[0].Rows)
+	r.Empty(updates[0].Diffs)
+
+	r.Equal(3, updates[1].Run)
+	r.Nil(updates[1].Rows)
+	r.Equal(1, updates[1].Inserted)
+	r.Equal(1, updates[1].Deleted)
+	r.Equal(core.Row{3, "row_3"}, updates[1].Diffs[0].Target)
+	r.Equal(core.Row{0, "row_0"}, updates[1].Diffs[1].Source)
+}
+
+func TestWatch_InvalidInterval(t *testing.T) {
+	_, err := core.NewWatch("", "_", &core.WatchOptions{}, func() (*core.Call, error) { return nil, nil }, nil)
+	require.ErrorIs(t, err, core.ErrInvalidWatchInterval)
+}
+
+func TestDiffRows(t *testing.T) {
+	r := require.New(t)
+
+	header := core.Header{"id", "status"}
+	previous := []core.Row{{1, "queued"}, {2, "running"}, {3, "queued"}}
+	current := []core.Row{{2, "done"}, {3, "queued"}, {4, "queued"}}
+
+	diffs, err := core.DiffRows(header, previous, current, []string{"id"})
+	r.NoError(err)
+	r.Equal([]*core.TableRowDiff{
+		{Kind: core.DiffKindChanged, Key: core.Row{2}, Source: core.Row{2, "running"}, Target: core.Row{2, "done"}, Changed: []string{"status"}},
+		{Kind: core.DiffKindExtra, Key: core.Row{4}, Target: core.Row{4, "queued"}},
+		{Kind: core.DiffKindMissing, Key: core.Row{1}, Source: core.Row{1, "queued"}},
+	}, diffs)
+
+	// whole rows, regardless of their order
+	diffs, err = core.DiffRows(header, previous, current, nil)
+	r.NoError(err)
+	r.Equal([]*core.TableRowDiff{
+		{Kind: core.DiffKindExtra, Target: core.Row{2, "done"}},
+		{Kind: core.DiffKindExtra, Target: core.Row{4, "queued"}},
+		{Kind: core.DiffKindMissing, Source: core.Row{1, "queued"}},
+		{Kind: core.DiffKindMissing, Source: core.Row{2, "running"}},
+	}, diffs)
+
+	diffs, err = core.DiffRows(header, previous, previous, []string{"id"})
+	r.NoError(err)
+	r.Empty(diffs)
+
+	_, err = core.DiffRows(header, previous, current, []string{"missing"})
+	r.ErrorIs(err, core.ErrUnknownColumn)
+}
+
+func TestConnection_TableWatch(t *testing.T) {
+	r := require.New(t)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(nil))
+	r.NoError(err)
+
+	query, opts := connection.TableWatch("public", "jobs", &core.WatchOptions{Interval: time.Second, KeyColumns: []string{"id"}, MaxRows: 50})
+	r.Equal("SELECT * FROM \"public\".\"jobs\" ORDER BY \"id\"\nLIMIT 51", query)
+	r.Equal(time.Second, opts.Interval)
+	r.Equal([]string{"id"}, opts.KeyColumns)
+
+	// no key columns and the default number of rows
+	query, opts = connection.TableWatch("", "jobs", nil)
+	r.Equal("SELECT * FROM \"jobs\"\nLIMIT 1001", query)
+	r.Empty(opts.KeyColumns)
+}
@@ -1,8 +1,12 @@
 package main
 
 import (
+	"log/slog"
+	"time"
+
 	"github.com/neovim/go-client/nvim"
 
+	"github.com/kndndrj/nvim-dbee/dbee/adapters"
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/handler"
 	"github.com/kndndrj/nvim-dbee/dbee/plugin"
@@ -13,18 +17,120 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 		"DbeeCreateConnection",
 		func(args *struct {
 			Opts *struct {
-				ID   string `msgpack:"id"`
-				URL  string `msgpack:"url"`
-				Type string `msgpack:"type"`
-				Name string `msgpack:"name"`
+				ID           string `msgpack:"id"`
+				URL          string `msgpack:"url"`
+				Type         string `msgpack:"type"`
+				Name         string `msgpack:"name"`
+				Guarded      bool   `msgpack:"guarded"`
+				AutoLimit    int    `msgpack:"auto_limit"`
+				Retries      int    `msgpack:"retries"`
+				StructureTTL int    `msgpack:"structure_ttl"`
+
+				HiddenDatabases []string `msgpack:"hidden_databases"`
+				Preview         *struct {
+					Limit   int      `msgpack:"limit"`
+					Latest  bool     `msgpack:"latest"`
+					Columns []string `msgpack:"columns"`
+				} `msgpack:"preview"`
+				Masking []struct {
+					Column  string `msgpack:"column"`
+					Pattern string `msgpack:"pattern"`
+					Method  string `msgpack:"method"`
+					Keep    int    `msgpack:"keep"`
+				} `msgpack:"masking"`
+				Display *struct {
+					Timezone           string `msgpack:"timezone"`
+					TimeFormat         string `msgpack:"time_format"`
+					ThousandsSeparator string `msgpack:"thousands_separator"`
+					FloatPrecision     *int   `msgpack:"float_precision"`
+				} `msgpack:"display"`
+				Limits *struct {
+					MaxRows         int   `msgpack:"max_rows"`
+					TruncateRows    bool  `msgpack:"truncate_rows"`
+					MaxScannedBytes int64 `msgpack:"max_scanned_bytes"`
+					MaxRuntime      int   `msgpack:"max_runtime"`
+				} `msgpack:"limits"`
+				Quota *struct {
+					MaxQueriesPerMinute int   `msgpack:"max_queries_per_minute"`
+					CooldownRows        int64 `msgpack:"cooldown_rows"`
+					Cooldown            int   `msgpack:"cooldown"`
+				} `msgpack:"quota"`
+				Cost *struct {
+					PricePerTiB float64 `msgpack:"price_per_tib"`
+					MaxCost     float64 `msgpack:"max_cost"`
+				} `msgpack:"cost"`
+				MigrationsDir string `msgpack:"migrations_dir"`
 			} `msgpack:",array"`
 		},
 		) (core.ConnectionID, error) {
+			var preview core.PreviewOptions
+			if args.Opts.Preview != nil {
+				preview = core.PreviewOptions{
+					Limit:   args.Opts.Preview.Limit,
+					Latest:  args.Opts.Preview.Latest,
+					Columns: args.Opts.Preview.Columns,
+				}
+			}
+			var display core.DisplayOptions
+			if args.Opts.Display != nil {
+				display = core.DisplayOptions{
+					Timezone:           args.Opts.Display.Timezone,
+					TimeFormat:         args.Opts.Display.TimeFormat,
+					ThousandsSeparator: args.Opts.Display.ThousandsSeparator,
+					FloatPrecision:     args.Opts.Display.FloatPrecision,
+				}
+			}
+			var limits core.ResourceLimits
+			if args.Opts.Limits != nil {
+				limits = core.ResourceLimits{
+					MaxRows:         args.Opts.Limits.MaxRows,
+					TruncateRows:    args.Opts.Limits.TruncateRows,
+					MaxScannedBytes: args.Opts.Limits.MaxScannedBytes,
+					MaxRuntime:      args.Opts.Limits.MaxRuntime,
+				}
+			}
+			var quota core.QueryQuota
+			if args.Opts.Quota != nil {
+				quota = core.QueryQuota{
+					MaxQueriesPerMinute: args.Opts.Quota.MaxQueriesPerMinute,
+					CooldownRows:        args.Opts.Quota.CooldownRows,
+					Cooldown:            args.Opts.Quota.Cooldown,
+				}
+			}
+			var cost core.CostOptions
+			if args.Opts.Cost != nil {
+				cost = core.CostOptions{
+					PricePerTiB: args.Opts.Cost.PricePerTiB,
+					MaxCost:     args.Opts.Cost.MaxCost,
+				}
+			}
+			var masking []core.MaskRule
+			for _, rule := range args.Opts.Masking {
+				masking = append(masking, core.MaskRule{
+					Column:  rule.Column,
+					Pattern: rule.Pattern,
+					Method:  rule.Method,
+					Keep:    rule.Keep,
+				})
+			}
 			return h.CreateConnection(&core.ConnectionParams{
-				ID:   core.ConnectionID(args.Opts.ID),
-				Name: args.Opts.Name,
-				Type: args.Opts.Type,
-				URL:  args.Opts.URL,
+				ID:           core.ConnectionID(args.Opts.ID),
+				Name:         args.Opts.Name,
+				Type:         args.Opts.Type,
+				URL:          args.Opts.URL,
+				Guarded:      args.Opts.Guarded,
+				AutoLimit:    args.Opts.AutoLimit,
+				Retries:      args.Opts.Retries,
+				StructureTTL: args.Opts.StructureTTL,
+
+				HiddenDatabases: args.Opts.HiddenDatabases,
+				Preview:         preview,
+				Masking:         masking,
+				Display:         display,
+				Limits:          limits,
+				Quota:           quota,
+				Cost:            cost,
+				MigrationsDir:   args.Opts.MigrationsDir,
 			})
 		})
 
@@ -46,6 +152,28 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 			return handler.WrapConnections(h.GetConnections(args.IDs)), nil
 		})
 
+	p.RegisterEndpoint(
+		"DbeeRegisterAdapter",
+		func(args *struct {
+			Type string `msgpack:",array"`
+			Opts *struct {
+				Command []string `msgpack:"command"`
+				Plugin  string   `msgpack:"plugin"`
+			}
+		},
+		) error {
+			return h.RegisterAdapter(args.Type, &adapters.AdapterOptions{
+				Command: args.Opts.Command,
+				Plugin:  args.Opts.Plugin,
+			})
+		})
+
+	p.RegisterEndpoint(
+		"DbeeGetAdapters",
+		func() (any, error) {
+			return handler.WrapAdapterInfos(h.GetAdapters()), nil
+		})
+
 	p.RegisterEndpoint(
 		"DbeeAddHelpers",
 		func(args *struct {
@@ -83,6 +211,12 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 			return h.SetCurrentConnection(args.ID)
 		})
 
+	p.RegisterEndpoint(
+		"DbeeGetQueryStatus",
+		func() (any, error) {
+			return handler.WrapQueryStatus(h.GetQueryStatus()), nil
+		})
+
 	p.RegisterEndpoint(
 		"DbeeGetCurrentConnection",
 		func() (any, error) {
@@ -95,118 +229,1736 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 		func(args *struct {
 			ID    core.ConnectionID `msgpack:",array"`
 			Query string
+			Opts  *struct {
+				Confirmed bool `msgpack:"confirmed"`
+				Sample    *struct {
+					Percent float64 `msgpack:"percent"`
+					Rows    int     `msgpack:"rows"`
+				} `msgpack:"sample"`
+			}
 		},
 		) (any, error) {
+			if args.Opts != nil && args.Opts.Sample != nil {
+				call, err := h.ConnectionExecuteSample(args.ID, args.Query, &core.SampleOptions{
+					Percent: args.Opts.Sample.Percent,
+					Rows:    args.Opts.Sample.Rows,
+				}, args.Opts.Confirmed)
+				return handler.WrapCall(call), err
+			}
+			if args.Opts != nil && args.Opts.Confirmed {
+				call, err := h.ConnectionExecuteConfirmed(args.ID, args.Query)
+				return handler.WrapCall(call), err
+			}
 			call, err := h.ConnectionExecute(args.ID, args.Query)
 			return handler.WrapCall(call), err
 		})
 
 	p.RegisterEndpoint(
-		"DbeeConnectionGetCalls",
+		"DbeeConnectionEstimateCost",
 		func(args *struct {
-			ID core.ConnectionID `msgpack:",array"`
+			ID    core.ConnectionID `msgpack:",array"`
+			Query string
 		},
 		) (any, error) {
-			calls, err := h.ConnectionGetCalls(args.ID)
-			return handler.WrapCalls(calls), err
+			estimate, err := h.ConnectionEstimateCost(args.ID, args.Query)
+			return handler.WrapCostEstimate(estimate), err
 		})
 
 	p.RegisterEndpoint(
-		"DbeeConnectionGetParams",
+		"DbeeConnectionsExecute",
 		func(args *struct {
-			ID core.ConnectionID `msgpack:",array"`
+			IDs   []core.ConnectionID `msgpack:",array"`
+			Query string
+			Opts  *struct {
+				Confirmed bool `msgpack:"confirmed"`
+			}
 		},
 		) (any, error) {
-			params, err := h.ConnectionGetParams(args.ID)
-			return handler.WrapConnectionParams(params), err
+			confirmed := args.Opts != nil && args.Opts.Confirmed
+			call, err := h.ConnectionsExecute(args.IDs, args.Query, confirmed)
+			return handler.WrapCall(call), err
 		})
 
 	p.RegisterEndpoint(
-		"DbeeConnectionGetStructure",
+		"DbeeConnectionsFederate",
 		func(args *struct {
-			ID core.ConnectionID `msgpack:",array"`
+			Tables []*struct {
+				Name      string            `msgpack:"name"`
+				ConnID    core.ConnectionID `msgpack:"conn_id"`
+				Query     string            `msgpack:"query"`
+				CallID    core.CallID       `msgpack:"call_id"`
+				ResultSet int               `msgpack:"result_set"`
+			} `msgpack:",array"`
+			Query string
+			Opts  *struct {
+				Engine core.ConnectionID `msgpack:"engine"`
+			}
 		},
 		) (any, error) {
-			str, err := h.ConnectionGetStructure(args.ID)
-			return handler.WrapStructures(str), err
+			sources := make([]*handler.FederatedTableSource, len(args.Tables))
+			for i, table := range args.Tables {
+				sources[i] = &handler.FederatedTableSource{
+					Name:         table.Name,
+					ConnectionID: table.ConnID,
+					Query:        table.Query,
+					CallID:       table.CallID,
+					ResultSet:    table.ResultSet,
+				}
+			}
+			var engineID core.ConnectionID
+			if args.Opts != nil {
+				engineID = args.Opts.Engine
+			}
+			call, err := h.ConnectionsFederate(engineID, sources, args.Query)
+			return handler.WrapCall(call), err
 		})
 
-	p.RegisterEndpoint("DbeeConnectionGetColumns", func(args *struct {
-		ID   core.ConnectionID `msgpack:",array"`
-		Opts *struct {
-			Table           string `msgpack:"table"`
-			Schema          string `msgpack:"schema"`
-			Materialization string `msgpack:"materialization"`
-		}
-	},
-	) (any, error) {
-		cols, err := h.ConnectionGetColumns(args.ID, &core.TableOptions{
-			Table:           args.Opts.Table,
-			Schema:          args.Opts.Schema,
-			Materialization: core.StructureTypeFromString(args.Opts.Materialization),
+	p.RegisterEndpoint(
+		"DbeeConnectionsExport",
+		func(args *struct {
+			IDs  []core.ConnectionID `msgpack:",array"`
+			Path string
+			Opts *struct {
+				Secrets string `msgpack:"secrets"`
+			}
+		},
+		) (any, error) {
+			var mode core.SecretMode
+			if args.Opts != nil {
+				mode = core.SecretMode(args.Opts.Secrets)
+			}
+			bundle, err := h.ConnectionsExport(args.IDs, args.Path, mode)
+			if err != nil {
+				return nil, err
+			}
+			return handler.WrapConnectionBundle(bundle, nil), nil
 		})
-		return handler.WrapColumns(cols), err
-	})
 
 	p.RegisterEndpoint(
-		"DbeeConnectionListDatabases",
+		"DbeeReadConnectionBundle",
 		func(args *struct {
-			ID core.ConnectionID `msgpack:",array"`
+			Path string `msgpack:",array"`
 		},
 		) (any, error) {
-			current, available, err := h.ConnectionListDatabases(args.ID)
+			bundle, missing, err := h.ReadConnectionBundle(args.Path)
 			if err != nil {
 				return nil, err
 			}
-			return []any{current, available}, nil
+			return handler.WrapConnectionBundle(bundle, missing), nil
 		})
 
 	p.RegisterEndpoint(
-		"DbeeConnectionSelectDatabase",
+		"DbeeImportConnections",
 		func(args *struct {
-			ID       core.ConnectionID `msgpack:",array"`
-			Database string
+			Path    string `msgpack:",array"`
+			Secrets map[string]string
 		},
 		) (any, error) {
-			return nil, h.ConnectionSelectDatabase(args.ID, args.Database)
+			params, err := h.ImportConnections(args.Path, args.Secrets)
+			return handler.WrapConnectionParamsList(params), err
 		})
 
 	p.RegisterEndpoint(
-		"DbeeCallCancel",
+		"DbeeConnectionsCompareSchemas",
 		func(args *struct {
-			ID core.CallID `msgpack:",array"`
+			SourceID core.ConnectionID `msgpack:",array"`
+			TargetID core.ConnectionID
+			Opts     *struct {
+				SourceSchema string `msgpack:"source_schema"`
+				TargetSchema string `msgpack:"target_schema"`
+				Migration    bool   `msgpack:"migration"`
+			}
 		},
 		) (any, error) {
-			return nil, h.CallCancel(args.ID)
+			opts := &core.CompareOptions{}
+			if args.Opts != nil {
+				opts.SourceSchema = args.Opts.SourceSchema
+				opts.TargetSchema = args.Opts.TargetSchema
+				opts.Migration = args.Opts.Migration
+			}
+			diff, err := h.ConnectionsCompareSchemas(args.SourceID, args.TargetID, opts)
+			return handler.WrapSchemaDiff(diff), err
 		})
 
 	p.RegisterEndpoint(
-		"DbeeCallDisplayResult",
+		"DbeeConnectionsCompareTableData",
 		func(args *struct {
-			ID   core.CallID `msgpack:",array"`
+			SourceID core.ConnectionID `msgpack:",array"`
+			TargetID core.ConnectionID
+			Opts     *struct {
+				Schema       string   `msgpack:"schema"`
+				Table        string   `msgpack:"table"`
+				TargetSchema string   `msgpack:"target_schema"`
+				TargetTable  string   `msgpack:"target_table"`
+				KeyColumns   []string `msgpack:"key_columns"`
+				MaxDiffs     int      `msgpack:"max_diffs"`
+			}
+		},
+		) (any, error) {
+			opts := &core.TableCompareOptions{}
+			if args.Opts != nil {
+				opts.Schema = args.Opts.Schema
+				opts.Table = args.Opts.Table
+				opts.TargetSchema = args.Opts.TargetSchema
+				opts.TargetTable = args.Opts.TargetTable
+				opts.KeyColumns = args.Opts.KeyColumns
+				opts.MaxDiffs = args.Opts.MaxDiffs
+			}
+			diff, err := h.ConnectionsCompareTableData(args.SourceID, args.TargetID, opts)
+			return handler.WrapTableDataDiff(diff), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionCallProcedure",
+		func(args *struct {
+			ID     core.ConnectionID `msgpack:",array"`
+			Name   string
+			Params []*struct {
+				Name  string `msgpack:"name"`
+				Mode  string `msgpack:"mode"`
+				Value any    `msgpack:"value"`
+			}
 			Opts *struct {
-				Buffer int `msgpack:"buffer"`
-				From   int `msgpack:"from"`
-				To     int `msgpack:"to"`
+				Confirmed bool `msgpack:"confirmed"`
 			}
 		},
 		) (any, error) {
-			return h.CallDisplayResult(args.ID, nvim.Buffer(args.Opts.Buffer), args.Opts.From, args.Opts.To)
+			params := make([]*core.ProcedureParam, len(args.Params))
+			for i, p := range args.Params {
+				params[i] = &core.ProcedureParam{
+					Name:  p.Name,
+					Mode:  core.ParamModeFromString(p.Mode),
+					Value: p.Value,
+				}
+			}
+			confirmed := args.Opts != nil && args.Opts.Confirmed
+			call, err := h.ConnectionCallProcedure(args.ID, args.Name, params, confirmed)
+			return handler.WrapCall(call), err
 		})
 
 	p.RegisterEndpoint(
-		"DbeeCallStoreResult",
+		"DbeeConnectionExplain",
 		func(args *struct {
-			ID     core.CallID `msgpack:",array"`
-			Format string
-			Output string
-			Opts   *struct {
-				From     int `msgpack:"from"`
-				To       int `msgpack:"to"`
-				ExtraArg any `msgpack:"extra_arg"`
+			ID    core.ConnectionID `msgpack:",array"`
+			Query string
+			Opts  *struct {
+				Analyze bool `msgpack:"analyze"`
+			}
+		},
+		) (any, error) {
+			analyze := args.Opts != nil && args.Opts.Analyze
+			plan, err := h.ConnectionExplain(args.ID, args.Query, analyze)
+			return handler.WrapPlanNode(plan), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetActivity",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			call, err := h.ConnectionGetActivity(args.ID)
+			return handler.WrapCall(call), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetJSONPath",
+		func(args *struct {
+			ID     core.ConnectionID `msgpack:",array"`
+			Column string
+			Path   string
+		},
+		) (any, error) {
+			return h.ConnectionGetJSONPath(args.ID, args.Column, args.Path)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionSubscribe",
+		func(args *struct {
+			ID      core.ConnectionID `msgpack:",array"`
+			Channel string
+		},
+		) (any, error) {
+			call, err := h.ConnectionSubscribe(args.ID, args.Channel)
+			return handler.WrapCall(call), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionKillSession",
+		func(args *struct {
+			ID        core.ConnectionID `msgpack:",array"`
+			SessionID string
+		},
+		) (any, error) {
+			return nil, h.ConnectionKillSession(args.ID, args.SessionID)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionRefreshMaterializedView",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Table  string `msgpack:"table"`
+				Schema string `msgpack:"schema"`
+			}
+		},
+		) (any, error) {
+			return nil, h.ConnectionRefreshMaterializedView(args.ID, &core.TableOptions{
+				Table:           args.Opts.Table,
+				Schema:          args.Opts.Schema,
+				Materialization: core.StructureTypeMaterializedView,
+			})
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetPartitions",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Table  string `msgpack:"table"`
+				Schema string `msgpack:"schema"`
+			}
+		},
+		) (any, error) {
+			partitions, err := h.ConnectionGetPartitions(args.ID, &core.TableOptions{
+				Table:           args.Opts.Table,
+				Schema:          args.Opts.Schema,
+				Materialization: core.StructureTypeTable,
+			})
+			return handler.WrapPartitions(partitions), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionDetachPartition",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Table  string `msgpack:"table"`
+				Schema string `msgpack:"schema"`
+			}
+			Partition *struct {
+				Name   string `msgpack:"name"`
+				Schema string `msgpack:"schema"`
+			}
+			Confirmation string
+		},
+		) (any, error) {
+			return nil, h.ConnectionDetachPartition(args.ID, &core.TableOptions{
+				Table:           args.Opts.Table,
+				Schema:          args.Opts.Schema,
+				Materialization: core.StructureTypeTable,
+			}, &core.Partition{
+				Name:   args.Partition.Name,
+				Schema: args.Partition.Schema,
+			}, args.Confirmation)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionDropPartition",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Table  string `msgpack:"table"`
+				Schema string `msgpack:"schema"`
+			}
+			Partition *struct {
+				Name   string `msgpack:"name"`
+				Schema string `msgpack:"schema"`
+			}
+			Confirmation string
+		},
+		) (any, error) {
+			return nil, h.ConnectionDropPartition(args.ID, &core.TableOptions{
+				Table:           args.Opts.Table,
+				Schema:          args.Opts.Schema,
+				Materialization: core.StructureTypeTable,
+			}, &core.Partition{
+				Name:   args.Partition.Name,
+				Schema: args.Partition.Schema,
+			}, args.Confirmation)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetObjectActions",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Table           string `msgpack:"table"`
+				Schema          string `msgpack:"schema"`
+				Materialization string `msgpack:"materialization"`
+			}
+		},
+		) (any, error) {
+			actions, err := h.ConnectionGetObjectActions(args.ID, &core.TableOptions{
+				Table:           args.Opts.Table,
+				Schema:          args.Opts.Schema,
+				Materialization: core.StructureTypeFromString(args.Opts.Materialization),
+			})
+			return handler.WrapObjectActions(actions), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionRunObjectAction",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Table           string `msgpack:"table"`
+				Schema          string `msgpack:"schema"`
+				Materialization string `msgpack:"materialization"`
+			}
+			Action       string
+			Confirmation string
+		},
+		) (any, error) {
+			result, err := h.ConnectionRunObjectAction(args.ID, &core.TableOptions{
+				Table:           args.Opts.Table,
+				Schema:          args.Opts.Schema,
+				Materialization: core.StructureTypeFromString(args.Opts.Materialization),
+			}, args.Action, args.Confirmation)
+			return handler.WrapObjectActionResult(result), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetDependencies",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Table           string `msgpack:"table"`
+				Schema          string `msgpack:"schema"`
+				Materialization string `msgpack:"materialization"`
+			}
+		},
+		) (any, error) {
+			deps, err := h.ConnectionGetDependencies(args.ID, &core.TableOptions{
+				Table:           args.Opts.Table,
+				Schema:          args.Opts.Schema,
+				Materialization: core.StructureTypeFromString(args.Opts.Materialization),
+			})
+			return handler.WrapDependencies(deps), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionSetComment",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Table           string `msgpack:"table"`
+				Schema          string `msgpack:"schema"`
+				Materialization string `msgpack:"materialization"`
+			}
+			Column  string
+			Comment string
+		},
+		) (any, error) {
+			return nil, h.ConnectionSetComment(args.ID, &core.TableOptions{
+				Table:           args.Opts.Table,
+				Schema:          args.Opts.Schema,
+				Materialization: core.StructureTypeFromString(args.Opts.Materialization),
+			}, args.Column, args.Comment)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetScaffolds",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Table           string `msgpack:"table"`
+				Schema          string `msgpack:"schema"`
+				Materialization string `msgpack:"materialization"`
 			}
 		},
 		) (any, error) {
-			return nil, h.CallStoreResult(args.ID, args.Format, args.Output, args.Opts.From, args.Opts.To, args.Opts.ExtraArg)
+			scaffolds, err := h.ConnectionGetScaffolds(args.ID, &core.TableOptions{
+				Table:           args.Opts.Table,
+				Schema:          args.Opts.Schema,
+				Materialization: core.StructureTypeFromString(args.Opts.Materialization),
+			})
+			return handler.WrapScaffolds(scaffolds), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionBeginTransaction",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			return nil, h.ConnectionBeginTransaction(args.ID)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionCommitTransaction",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			return nil, h.ConnectionCommitTransaction(args.ID)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionRollbackTransaction",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			return nil, h.ConnectionRollbackTransaction(args.ID)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCancelAll",
+		func(args *struct {
+			Opts *struct {
+				TimeoutMs int64 `msgpack:"timeout_ms"`
+			} `msgpack:",array"`
+		},
+		) (int, error) {
+			timeout := 5 * time.Second
+			if args.Opts != nil && args.Opts.TimeoutMs > 0 {
+				timeout = time.Duration(args.Opts.TimeoutMs) * time.Millisecond
+			}
+			return h.CancelAll(timeout)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeSetAuditLog",
+		func(args *struct {
+			Path string `msgpack:",array"`
+		},
+		) (any, error) {
+			h.SetAuditLog(args.Path)
+			return nil, nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeSetLogOptions",
+		func(args *struct {
+			Opts *struct {
+				Level      string            `msgpack:"level"`
+				Levels     map[string]string `msgpack:"levels"`
+				Format     string            `msgpack:"format"`
+				Path       string            `msgpack:"path"`
+				MaxSizeMB  int               `msgpack:"max_size_mb"`
+				MaxBackups int               `msgpack:"max_backups"`
+				RingSize   int               `msgpack:"ring_size"`
+			} `msgpack:",array"`
+		},
+		) (any, error) {
+			if args.Opts == nil {
+				return nil, h.SetLogOptions(nil)
+			}
+			opts := &plugin.LogOptions{
+				Levels:     make(map[string]slog.Level),
+				Format:     args.Opts.Format,
+				Path:       args.Opts.Path,
+				MaxSize:    int64(args.Opts.MaxSizeMB) << 20,
+				MaxBackups: args.Opts.MaxBackups,
+				RingSize:   args.Opts.RingSize,
+			}
+			if args.Opts.Level != "" {
+				level, err := plugin.ParseLogLevel(args.Opts.Level)
+				if err != nil {
+					return nil, err
+				}
+				opts.Level = level
+			}
+			for subsystem, name := range args.Opts.Levels {
+				level, err := plugin.ParseLogLevel(name)
+				if err != nil {
+					return nil, err
+				}
+				opts.Levels[subsystem] = level
+			}
+			return nil, h.SetLogOptions(opts)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeGetLogs",
+		func(args *struct {
+			Opts *struct {
+				Level     string `msgpack:"level"`
+				Subsystem string `msgpack:"subsystem"`
+				Limit     int    `msgpack:"limit"`
+			} `msgpack:",array"`
+		},
+		) (any, error) {
+			filter := &plugin.LogFilter{Level: slog.LevelDebug}
+			if args.Opts != nil {
+				if args.Opts.Level != "" {
+					level, err := plugin.ParseLogLevel(args.Opts.Level)
+					if err != nil {
+						return nil, err
+					}
+					filter.Level = level
+				}
+				filter.Subsystem = args.Opts.Subsystem
+				filter.Limit = args.Opts.Limit
+			}
+			return handler.WrapLogEntries(h.GetLogs(filter)), nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeSetSlowQueryLog",
+		func(args *struct {
+			Opts *struct {
+				ThresholdMs int    `msgpack:"threshold_ms"`
+				Explain     bool   `msgpack:"explain"`
+				Path        string `msgpack:"path"`
+			} `msgpack:",array"`
+		},
+		) (any, error) {
+			opts := &handler.SlowQueryLogOptions{}
+			if args.Opts != nil {
+				opts.Threshold = time.Duration(args.Opts.ThresholdMs) * time.Millisecond
+				opts.Explain = args.Opts.Explain
+				opts.Path = args.Opts.Path
+			}
+			h.SetSlowQueryLog(opts)
+			return nil, nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeGetSlowQueries",
+		func(args *struct {
+			Opts *struct {
+				ConnID core.ConnectionID `msgpack:"conn_id"`
+				Limit  int               `msgpack:"limit"`
+			} `msgpack:",array"`
+		},
+		) (any, error) {
+			if args.Opts == nil {
+				return handler.WrapSlowQueries(h.GetSlowQueries("", 0)), nil
+			}
+			return handler.WrapSlowQueries(h.GetSlowQueries(args.Opts.ConnID, args.Opts.Limit)), nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeClearSlowQueries",
+		func() (any, error) {
+			h.ClearSlowQueries()
+			return nil, nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeSetNotifier",
+		func(args *struct {
+			Opts *struct {
+				Command []string `msgpack:"command"`
+				Webhook string   `msgpack:"webhook"`
+				Event   bool     `msgpack:"event"`
+			} `msgpack:",array"`
+		},
+		) (any, error) {
+			opts := &handler.NotifierOptions{}
+			if args.Opts != nil {
+				opts.Command = args.Opts.Command
+				opts.Webhook = args.Opts.Webhook
+				opts.Event = args.Opts.Event
+			}
+			h.SetNotifier(opts)
+			return nil, nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallNotify",
+		func(args *struct {
+			ID      core.CallID `msgpack:",array"`
+			Enabled bool
+		},
+		) (any, error) {
+			return nil, h.CallNotify(args.ID, args.Enabled)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeSetErrorHistory",
+		func(args *struct {
+			Opts *struct {
+				Enabled    bool `msgpack:"enabled"`
+				MaxEntries int  `msgpack:"max_entries"`
+				MaxAgeDays int  `msgpack:"max_age_days"`
+			} `msgpack:",array"`
+		},
+		) (any, error) {
+			opts := &handler.ErrorHistoryOptions{}
+			if args.Opts != nil {
+				opts.Enabled = args.Opts.Enabled
+				opts.MaxEntries = args.Opts.MaxEntries
+				opts.MaxAge = time.Duration(args.Opts.MaxAgeDays) * 24 * time.Hour
+			}
+			return nil, h.SetErrorHistory(opts)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeGetFailedQueries",
+		func(args *struct {
+			Opts *struct {
+				ConnID core.ConnectionID `msgpack:"conn_id"`
+				Limit  int               `msgpack:"limit"`
+			} `msgpack:",array"`
+		},
+		) (any, error) {
+			var connID core.ConnectionID
+			var limit int
+			if args.Opts != nil {
+				connID = args.Opts.ConnID
+				limit = args.Opts.Limit
+			}
+			queries, err := h.GetFailedQueries(connID, limit)
+			if err != nil {
+				return nil, err
+			}
+			return handler.WrapFailedQueries(queries), nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeClearFailedQueries",
+		func(args *struct {
+			ConnID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			return nil, h.ClearFailedQueries(args.ConnID)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionSetAutoCommit",
+		func(args *struct {
+			ID      core.ConnectionID `msgpack:",array"`
+			Enabled bool
+		},
+		) (any, error) {
+			return nil, h.ConnectionSetAutoCommit(args.ID, args.Enabled)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionSavepoint",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Name string
+		},
+		) (any, error) {
+			return nil, h.ConnectionSavepoint(args.ID, args.Name)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionRollbackToSavepoint",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Name string
+		},
+		) (any, error) {
+			return nil, h.ConnectionRollbackToSavepoint(args.ID, args.Name)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetSavepoints",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			return h.ConnectionGetSavepoints(args.ID)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetCalls",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			calls, err := h.ConnectionGetCalls(args.ID)
+			return handler.WrapCalls(calls), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetQueryStats",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Order   string `msgpack:"order"`
+				Pattern string `msgpack:"pattern"`
+				Limit   int    `msgpack:"limit"`
+			}
+		},
+		) (any, error) {
+			opts := &core.QueryStatsOptions{}
+			if args.Opts != nil {
+				opts.ByFrequency = args.Opts.Order == "frequency"
+				opts.Pattern = args.Opts.Pattern
+				opts.Limit = args.Opts.Limit
+			}
+			stats, err := h.ConnectionGetQueryStats(args.ID, opts)
+			if err != nil {
+				return nil, err
+			}
+			return handler.WrapQueryStats(stats), nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetParams",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			params, err := h.ConnectionGetParams(args.ID)
+			return handler.WrapConnectionParams(params), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetStructure",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				IncludeSystem bool `msgpack:"include_system"`
+			}
+		},
+		) (any, error) {
+			var opts *core.StructureOptions
+			if args.Opts != nil {
+				opts = &core.StructureOptions{IncludeSystem: args.Opts.IncludeSystem}
+			}
+			str, err := h.ConnectionGetStructure(args.ID, opts)
+			return handler.WrapStructures(str), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionSearchObjects",
+		func(args *struct {
+			ID      core.ConnectionID `msgpack:",array"`
+			Pattern string
+			Limit   int
+		},
+		) (any, error) {
+			matches, err := h.ConnectionSearchObjects(args.ID, args.Pattern, args.Limit)
+			return handler.WrapObjectMatches(matches), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionRefreshStructure",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Node *struct {
+				Name   string `msgpack:"name"`
+				Schema string `msgpack:"schema"`
+				Type   string `msgpack:"type"`
+			}
+		},
+		) error {
+			// empty node refreshes the whole structure
+			var node *core.Structure
+			if args.Node != nil && args.Node.Name != "" {
+				node = &core.Structure{
+					Name:   args.Node.Name,
+					Schema: args.Node.Schema,
+					Type:   core.StructureTypeFromString(args.Node.Type),
+				}
+			}
+			return h.ConnectionRefreshStructure(args.ID, node)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetStructureChildren",
+		func(args *struct {
+			ID     core.ConnectionID `msgpack:",array"`
+			Parent *struct {
+				Name   string `msgpack:"name"`
+				Schema string `msgpack:"schema"`
+				Type   string `msgpack:"type"`
+			}
+		},
+		) (any, error) {
+			str, err := h.ConnectionGetStructureChildren(args.ID, &core.Structure{
+				Name:   args.Parent.Name,
+				Schema: args.Parent.Schema,
+				Type:   core.StructureTypeFromString(args.Parent.Type),
+			})
+			return handler.WrapStructures(str), err
+		})
+
+	p.RegisterEndpoint("DbeeConnectionGetColumns", func(args *struct {
+		ID   core.ConnectionID `msgpack:",array"`
+		Opts *struct {
+			Table           string `msgpack:"table"`
+			Schema          string `msgpack:"schema"`
+			Materialization string `msgpack:"materialization"`
+		}
+	},
+	) (any, error) {
+		cols, err := h.ConnectionGetColumns(args.ID, &core.TableOptions{
+			Table:           args.Opts.Table,
+			Schema:          args.Opts.Schema,
+			Materialization: core.StructureTypeFromString(args.Opts.Materialization),
+		})
+		return handler.WrapColumns(cols), err
+	})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetIndexes",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Table           string `msgpack:"table"`
+				Schema          string `msgpack:"schema"`
+				Materialization string `msgpack:"materialization"`
+			}
+		},
+		) (any, error) {
+			indexes, err := h.ConnectionGetIndexes(args.ID, &core.TableOptions{
+				Table:           args.Opts.Table,
+				Schema:          args.Opts.Schema,
+				Materialization: core.StructureTypeFromString(args.Opts.Materialization),
+			})
+			return handler.WrapIndexes(indexes), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetConstraints",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Table           string `msgpack:"table"`
+				Schema          string `msgpack:"schema"`
+				Materialization string `msgpack:"materialization"`
+			}
+		},
+		) (any, error) {
+			constraints, err := h.ConnectionGetConstraints(args.ID, &core.TableOptions{
+				Table:           args.Opts.Table,
+				Schema:          args.Opts.Schema,
+				Materialization: core.StructureTypeFromString(args.Opts.Materialization),
+			})
+			return handler.WrapConstraints(constraints), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetTriggers",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Table           string `msgpack:"table"`
+				Schema          string `msgpack:"schema"`
+				Materialization string `msgpack:"materialization"`
+			}
+		},
+		) (any, error) {
+			triggers, err := h.ConnectionGetTriggers(args.ID, &core.TableOptions{
+				Table:           args.Opts.Table,
+				Schema:          args.Opts.Schema,
+				Materialization: core.StructureTypeFromString(args.Opts.Materialization),
+			})
+			return handler.WrapTriggers(triggers), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetTableStats",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			stats, err := h.ConnectionGetTableStats(args.ID)
+			return handler.WrapTableStats(stats), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetServerInfo",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			info, err := h.ConnectionGetServerInfo(args.ID)
+			return handler.WrapServerInfo(info), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetRoles",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			roles, err := h.ConnectionGetRoles(args.ID)
+			return handler.WrapRoles(roles), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetForeignServers",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			servers, err := h.ConnectionGetForeignServers(args.ID)
+			return handler.WrapForeignServers(servers), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetForeignObjects",
+		func(args *struct {
+			ID     core.ConnectionID `msgpack:",array"`
+			Server string
+		},
+		) (any, error) {
+			objects, err := h.ConnectionGetForeignObjects(args.ID, args.Server)
+			return handler.WrapStructures(objects), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetGrants",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Table           string `msgpack:"table"`
+				Schema          string `msgpack:"schema"`
+				Materialization string `msgpack:"materialization"`
+			}
+		},
+		) (any, error) {
+			grants, err := h.ConnectionGetGrants(args.ID, &core.TableOptions{
+				Table:           args.Opts.Table,
+				Schema:          args.Opts.Schema,
+				Materialization: core.StructureTypeFromString(args.Opts.Materialization),
+			})
+			return handler.WrapGrants(grants), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetDefinition",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Table           string `msgpack:"table"`
+				Schema          string `msgpack:"schema"`
+				Materialization string `msgpack:"materialization"`
+			}
+		},
+		) (any, error) {
+			return h.ConnectionGetDefinition(args.ID, &core.TableOptions{
+				Table:           args.Opts.Table,
+				Schema:          args.Opts.Schema,
+				Materialization: core.StructureTypeFromString(args.Opts.Materialization),
+			})
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionListDatabases",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			current, available, err := h.ConnectionListDatabases(args.ID)
+			if err != nil {
+				return nil, err
+			}
+			return []any{current, available}, nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionSelectDatabase",
+		func(args *struct {
+			ID       core.ConnectionID `msgpack:",array"`
+			Database string
+		},
+		) (any, error) {
+			return nil, h.ConnectionSelectDatabase(args.ID, args.Database)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetMigrations",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Root string
+		},
+		) (any, error) {
+			status, err := h.ConnectionGetMigrations(args.ID, args.Root)
+			return handler.WrapMigrationStatus(status), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionApplyMigration",
+		func(args *struct {
+			ID      core.ConnectionID `msgpack:",array"`
+			Root    string
+			Version string
+		},
+		) (any, error) {
+			return nil, h.ConnectionApplyMigration(args.ID, args.Root, args.Version)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionRollbackMigration",
+		func(args *struct {
+			ID      core.ConnectionID `msgpack:",array"`
+			Root    string
+			Version string
+		},
+		) (any, error) {
+			return nil, h.ConnectionRollbackMigration(args.ID, args.Root, args.Version)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallCancel",
+		func(args *struct {
+			ID core.CallID `msgpack:",array"`
+		},
+		) (any, error) {
+			return nil, h.CallCancel(args.ID)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallExplainError",
+		func(args *struct {
+			ID core.CallID `msgpack:",array"`
+		},
+		) (any, error) {
+			hints, err := h.CallExplainError(args.ID)
+			return handler.WrapErrorHints(hints), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallGetMeta",
+		func(args *struct {
+			ID   core.CallID `msgpack:",array"`
+			Opts *struct {
+				Set int `msgpack:"set"`
+			}
+		},
+		) (any, error) {
+			set := 0
+			if args.Opts != nil {
+				set = args.Opts.Set
+			}
+			meta, err := h.CallGetMeta(args.ID, set)
+			return handler.WrapMeta(meta), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallDisplayResult",
+		func(args *struct {
+			ID   core.CallID `msgpack:",array"`
+			Opts *struct {
+				Buffer int `msgpack:"buffer"`
+				From   int `msgpack:"from"`
+				To     int `msgpack:"to"`
+				Set    int `msgpack:"set"`
+			}
+		},
+		) (any, error) {
+			return h.CallDisplayResult(args.ID, args.Opts.Set, nvim.Buffer(args.Opts.Buffer), args.Opts.From, args.Opts.To)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallGetTableLayout",
+		func(args *struct {
+			ID   core.CallID `msgpack:",array"`
+			Opts *struct {
+				From int `msgpack:"from"`
+				To   int `msgpack:"to"`
+				Set  int `msgpack:"set"`
+			}
+		},
+		) (any, error) {
+			layout, err := h.CallGetTableLayout(args.ID, args.Opts.Set, args.Opts.From, args.Opts.To)
+			if err != nil {
+				return nil, err
+			}
+			return handler.WrapTableLayout(layout), nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeSetAmbiguousWidth",
+		func(args *struct {
+			Double bool `msgpack:",array"`
+		},
+		) (any, error) {
+			h.SetAmbiguousWidth(args.Double)
+			return nil, nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallGetChecksum",
+		func(args *struct {
+			ID   core.CallID `msgpack:",array"`
+			Opts *struct {
+				Set         int  `msgpack:"set"`
+				IgnoreOrder bool `msgpack:"ignore_order"`
+			}
+		},
+		) (any, error) {
+			return h.CallGetChecksum(args.ID, args.Opts.Set, args.Opts.IgnoreOrder)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallCompareResult",
+		func(args *struct {
+			ID         core.CallID `msgpack:",array"`
+			BaselineID core.CallID
+			Opts       *struct {
+				Set         int  `msgpack:"set"`
+				IgnoreOrder bool `msgpack:"ignore_order"`
+				MaxDiffs    int  `msgpack:"max_diffs"`
+			}
+		},
+		) (any, error) {
+			cmp, err := h.CallCompareResult(args.ID, args.BaselineID, args.Opts.Set, &core.ResultCompareOptions{
+				IgnoreOrder: args.Opts.IgnoreOrder,
+				MaxDiffs:    args.Opts.MaxDiffs,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return handler.WrapResultComparison(cmp), nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallGetJSONColumns",
+		func(args *struct {
+			ID   core.CallID `msgpack:",array"`
+			Opts *struct {
+				Set int `msgpack:"set"`
+			}
+		},
+		) (any, error) {
+			return h.CallGetJSONColumns(args.ID, args.Opts.Set)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallFormatJSONCell",
+		func(args *struct {
+			ID   core.CallID `msgpack:",array"`
+			Opts *struct {
+				Set    int `msgpack:"set"`
+				Row    int `msgpack:"row"`
+				Column int `msgpack:"column"`
+			}
+		},
+		) (any, error) {
+			return h.CallFormatJSONCell(args.ID, args.Opts.Set, args.Opts.Row, args.Opts.Column)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallFlattenJSON",
+		func(args *struct {
+			ID   core.CallID `msgpack:",array"`
+			Opts *struct {
+				Set    int      `msgpack:"set"`
+				Column string   `msgpack:"column"`
+				Paths  []string `msgpack:"paths"`
+			}
+		},
+		) (any, error) {
+			call, err := h.CallFlattenJSON(args.ID, args.Opts.Set, &core.JSONFlattenOptions{
+				Column: args.Opts.Column,
+				Paths:  args.Opts.Paths,
+			})
+			return handler.WrapCall(call), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallStoreResult",
+		func(args *struct {
+			ID     core.CallID `msgpack:",array"`
+			Format string
+			Output string
+			Opts   *struct {
+				From     int `msgpack:"from"`
+				To       int `msgpack:"to"`
+				ExtraArg any `msgpack:"extra_arg"`
+				Set      int `msgpack:"set"`
+			}
+		},
+		) (any, error) {
+			return nil, h.CallStoreResult(args.ID, args.Opts.Set, args.Format, args.Output, args.Opts.From, args.Opts.To, args.Opts.ExtraArg)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallExport",
+		func(args *struct {
+			ID   core.CallID `msgpack:",array"`
+			Opts *struct {
+				Format string `msgpack:"format"`
+				Path   string `msgpack:"path"`
+				From   int    `msgpack:"from"`
+				To     int    `msgpack:"to"`
+				Set    int    `msgpack:"set"`
+			}
+		},
+		) (any, error) {
+			if args.Opts == nil {
+				return h.CallExport(args.ID, 0, "", "", 0, -1)
+			}
+			return h.CallExport(args.ID, args.Opts.Set, args.Opts.Format, args.Opts.Path, args.Opts.From, args.Opts.To)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeExportCancel",
+		func(args *struct {
+			ID int `msgpack:",array"`
+		},
+		) (any, error) {
+			return nil, h.ExportCancel(args.ID)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetStatementAt",
+		func(args *struct {
+			ID     core.ConnectionID `msgpack:",array"`
+			Text   string
+			Offset int
+		},
+		) (any, error) {
+			stmt, err := h.ConnectionGetStatementAt(args.ID, args.Text, args.Offset)
+			return handler.WrapStatementRange(stmt), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionSplitStatements",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Text string
+		},
+		) (any, error) {
+			stmts, err := h.ConnectionSplitStatements(args.ID, args.Text)
+			return handler.WrapStatementRanges(stmts), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionFormat",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Text string
+			Opts *struct {
+				Indent      string `msgpack:"indent"`
+				KeywordCase string `msgpack:"keyword_case"`
+			}
+		},
+		) (any, error) {
+			opts := &core.FormatOptions{}
+			if args.Opts != nil {
+				opts.Indent = args.Opts.Indent
+				opts.KeywordCase = args.Opts.KeywordCase
+			}
+			return h.ConnectionFormat(args.ID, args.Text, opts)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionLint",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Text string
+		},
+		) (any, error) {
+			diagnostics, err := h.ConnectionLint(args.ID, args.Text)
+			return handler.WrapLintDiagnostics(diagnostics), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGenerateEdits",
+		func(args *struct {
+			ID     core.ConnectionID `msgpack:",array"`
+			CallID core.CallID
+			Opts   *struct {
+				Set        int      `msgpack:"set"`
+				Table      string   `msgpack:"table"`
+				Schema     string   `msgpack:"schema"`
+				KeyColumns []string `msgpack:"key_columns"`
+				Updates    []struct {
+					Row    int    `msgpack:"row"`
+					Column string `msgpack:"column"`
+					Value  any    `msgpack:"value"`
+				} `msgpack:"updates"`
+				Inserts   []map[string]any `msgpack:"inserts"`
+				Deletes   []int            `msgpack:"deletes"`
+				Execute   bool             `msgpack:"execute"`
+				Confirmed bool             `msgpack:"confirmed"`
+			}
+		},
+		) ([]string, error) {
+			if args.Opts == nil {
+				return nil, core.ErrNothingToChange
+			}
+			edits := &core.ResultEdits{
+				Table:      args.Opts.Table,
+				Schema:     args.Opts.Schema,
+				KeyColumns: args.Opts.KeyColumns,
+				Inserts:    args.Opts.Inserts,
+				Deletes:    args.Opts.Deletes,
+			}
+			for _, u := range args.Opts.Updates {
+				edits.Updates = append(edits.Updates, &core.CellEdit{
+					Row:    u.Row,
+					Column: u.Column,
+					Value:  u.Value,
+				})
+			}
+			return h.ConnectionGenerateEdits(args.ID, args.CallID, args.Opts.Set, edits, args.Opts.Execute, args.Opts.Confirmed)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionUploadBlob",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Table     string         `msgpack:"table"`
+				Schema    string         `msgpack:"schema"`
+				Column    string         `msgpack:"column"`
+				Path      string         `msgpack:"path"`
+				Key       map[string]any `msgpack:"key"`
+				Values    map[string]any `msgpack:"values"`
+				ChunkSize int            `msgpack:"chunk_size"`
+			}
+		},
+		) (int64, error) {
+			opts := &core.BlobUploadOptions{}
+			if args.Opts != nil {
+				opts = &core.BlobUploadOptions{
+					Table:     args.Opts.Table,
+					Schema:    args.Opts.Schema,
+					Column:    args.Opts.Column,
+					Path:      args.Opts.Path,
+					Key:       args.Opts.Key,
+					Values:    args.Opts.Values,
+					ChunkSize: args.Opts.ChunkSize,
+				}
+			}
+			return h.ConnectionUploadBlob(args.ID, opts)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionImport",
+		func(args *struct {
+			ID    core.ConnectionID `msgpack:",array"`
+			Path  string
+			Table string
+			Opts  *struct {
+				Format    string            `msgpack:"format"`
+				Columns   map[string]string `msgpack:"columns"`
+				BatchSize int               `msgpack:"batch_size"`
+				ErrorPath string            `msgpack:"error_path"`
+			}
+		},
+		) (any, error) {
+			opts := &core.ImportOptions{
+				Path:  args.Path,
+				Table: args.Table,
+			}
+			if args.Opts != nil {
+				opts.Format = core.ImportFormat(args.Opts.Format)
+				opts.Columns = args.Opts.Columns
+				opts.BatchSize = args.Opts.BatchSize
+				opts.ErrorPath = args.Opts.ErrorPath
+			}
+			call, err := h.ConnectionImport(args.ID, opts)
+			return handler.WrapCall(call), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionSeed",
+		func(args *struct {
+			ID    core.ConnectionID `msgpack:",array"`
+			Table string
+			Opts  *struct {
+				Schema  string `msgpack:"schema"`
+				Rows    int    `msgpack:"rows"`
+				Columns map[string]*struct {
+					Generator string  `msgpack:"generator"`
+					Values    []any   `msgpack:"values"`
+					Min       float64 `msgpack:"min"`
+					Max       float64 `msgpack:"max"`
+					NullRate  float64 `msgpack:"null_rate"`
+				} `msgpack:"columns"`
+				BatchSize int   `msgpack:"batch_size"`
+				Seed      int64 `msgpack:"seed"`
+			}
+		},
+		) (any, error) {
+			opts := &core.SeedOptions{
+				Table: args.Table,
+			}
+			if args.Opts != nil {
+				opts.Schema = args.Opts.Schema
+				opts.Rows = args.Opts.Rows
+				opts.BatchSize = args.Opts.BatchSize
+				opts.Seed = args.Opts.Seed
+				opts.Columns = make(map[string]*core.SeedRule, len(args.Opts.Columns))
+				for name, rule := range args.Opts.Columns {
+					if rule == nil {
+						continue
+					}
+					opts.Columns[name] = &core.SeedRule{
+						Generator: core.SeedGenerator(rule.Generator),
+						Values:    rule.Values,
+						Min:       rule.Min,
+						Max:       rule.Max,
+						NullRate:  rule.NullRate,
+					}
+				}
+			}
+			call, err := h.ConnectionSeed(args.ID, opts)
+			return handler.WrapCall(call), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionExportSchema",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Path string
+			Opts *struct {
+				Split         bool   `msgpack:"split"`
+				Schema        string `msgpack:"schema"`
+				IncludeSystem bool   `msgpack:"include_system"`
+				Objects       []*struct {
+					Table           string `msgpack:"table"`
+					Schema          string `msgpack:"schema"`
+					Materialization string `msgpack:"materialization"`
+				} `msgpack:"objects"`
+			}
+		},
+		) (any, error) {
+			opts := &core.SchemaExportOptions{
+				Path: args.Path,
+			}
+			if args.Opts != nil {
+				opts.Split = args.Opts.Split
+				opts.Schema = args.Opts.Schema
+				opts.IncludeSystem = args.Opts.IncludeSystem
+				for _, object := range args.Opts.Objects {
+					opts.Objects = append(opts.Objects, &core.TableOptions{
+						Table:           object.Table,
+						Schema:          object.Schema,
+						Materialization: core.StructureTypeFromString(object.Materialization),
+					})
+				}
+			}
+			export, err := h.ConnectionExportSchema(args.ID, opts)
+			return handler.WrapSchemaExport(export), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionDumpLayout",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			return h.ConnectionDumpLayout(args.ID)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetCompletion",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Prefix string `msgpack:"prefix"`
+				Schema string `msgpack:"schema"`
+				Table  string `msgpack:"table"`
+			}
+		},
+		) (any, error) {
+			opts := &core.CompletionOptions{}
+			if args.Opts != nil {
+				opts.Prefix = args.Opts.Prefix
+				opts.Schema = args.Opts.Schema
+				opts.Table = args.Opts.Table
+			}
+			items, err := h.ConnectionGetCompletion(args.ID, opts)
+			return handler.WrapCompletionItems(items), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetSignatures",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Table  string `msgpack:"table"`
+				Schema string `msgpack:"schema"`
+			}
+		},
+		) (any, error) {
+			signatures, err := h.ConnectionGetSignatures(args.ID, &core.TableOptions{
+				Table:  args.Opts.Table,
+				Schema: args.Opts.Schema,
+			})
+			return handler.WrapSignatures(signatures), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetCapabilities",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			return h.ConnectionGetCapabilities(args.ID)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionScheduleQuery",
+		func(args *struct {
+			ID    core.ConnectionID `msgpack:",array"`
+			Query string
+			Opts  *struct {
+				IntervalMs int64 `msgpack:"interval_ms"`
+			}
+		},
+		) (any, error) {
+			schedule, err := h.ConnectionScheduleQuery(args.ID, args.Query, time.Duration(args.Opts.IntervalMs)*time.Millisecond)
+			return handler.WrapSchedule(schedule), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeScheduleCancel",
+		func(args *struct {
+			ID core.ScheduleID `msgpack:",array"`
+		},
+		) (any, error) {
+			return nil, h.ScheduleCancel(args.ID)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeGetSchedules",
+		func() (any, error) {
+			return handler.WrapSchedules(h.GetSchedules()), nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionWatchQuery",
+		func(args *struct {
+			ID    core.ConnectionID `msgpack:",array"`
+			Query string
+			Opts  *struct {
+				IntervalMs  int64    `msgpack:"interval_ms"`
+				KeyColumns  []string `msgpack:"key_columns"`
+				FullRefresh bool     `msgpack:"full_refresh"`
+				MaxRows     int      `msgpack:"max_rows"`
+			}
+		},
+		) (any, error) {
+			opts := &core.WatchOptions{}
+			if args.Opts != nil {
+				opts.Interval = time.Duration(args.Opts.IntervalMs) * time.Millisecond
+				opts.KeyColumns = args.Opts.KeyColumns
+				opts.FullRefresh = args.Opts.FullRefresh
+				opts.MaxRows = args.Opts.MaxRows
+			}
+			watch, err := h.ConnectionWatchQuery(args.ID, args.Query, opts)
+			return handler.WrapWatch(watch), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionWatchTable",
+		func(args *struct {
+			ID     core.ConnectionID `msgpack:",array"`
+			Schema string
+			Table  string
+			Opts   *struct {
+				IntervalMs  int64    `msgpack:"interval_ms"`
+				KeyColumns  []string `msgpack:"key_columns"`
+				FullRefresh bool     `msgpack:"full_refresh"`
+				MaxRows     int      `msgpack:"max_rows"`
+			}
+		},
+		) (any, error) {
+			opts := &core.WatchOptions{}
+			if args.Opts != nil {
+				opts.Interval = time.Duration(args.Opts.IntervalMs) * time.Millisecond
+				opts.KeyColumns = args.Opts.KeyColumns
+				opts.FullRefresh = args.Opts.FullRefresh
+				opts.MaxRows = args.Opts.MaxRows
+			}
+			watch, err := h.ConnectionWatchTable(args.ID, args.Schema, args.Table, opts)
+			return handler.WrapWatch(watch), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeWatchCancel",
+		func(args *struct {
+			ID core.WatchID `msgpack:",array"`
+		},
+		) (any, error) {
+			return nil, h.WatchCancel(args.ID)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeGetWatches",
+		func() (any, error) {
+			return handler.WrapWatches(h.GetWatches()), nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeSetSnippetsFile",
+		func(args *struct {
+			Path string `msgpack:",array"`
+		},
+		) (any, error) {
+			return nil, h.SetSnippetsFile(args.Path)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeAddSnippet",
+		func(args *struct {
+			Opts *struct {
+				Name         string `msgpack:"name"`
+				ConnectionID string `msgpack:"connection_id"`
+				Description  string `msgpack:"description"`
+				Query        string `msgpack:"query"`
+			} `msgpack:",array"`
+		},
+		) (any, error) {
+			return nil, h.AddSnippet(&core.Snippet{
+				Name:         args.Opts.Name,
+				ConnectionID: core.ConnectionID(args.Opts.ConnectionID),
+				Description:  args.Opts.Description,
+				Query:        args.Opts.Query,
+			})
+		})
+
+	p.RegisterEndpoint(
+		"DbeeRemoveSnippet",
+		func(args *struct {
+			Name string `msgpack:",array"`
+			Opts *struct {
+				ConnectionID string `msgpack:"connection_id"`
+			}
+		},
+		) (any, error) {
+			var connID core.ConnectionID
+			if args.Opts != nil {
+				connID = core.ConnectionID(args.Opts.ConnectionID)
+			}
+			return nil, h.RemoveSnippet(connID, args.Name)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeGetSnippets",
+		func(args *struct {
+			Opts *struct {
+				ConnectionID string `msgpack:"connection_id"`
+			} `msgpack:",array"`
+		},
+		) (any, error) {
+			var connID core.ConnectionID
+			if args.Opts != nil {
+				connID = core.ConnectionID(args.Opts.ConnectionID)
+			}
+			return handler.WrapSnippets(h.GetSnippets(connID)), nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionRenderSnippet",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Name string
+			Args map[string]any
+		},
+		) (string, error) {
+			return h.ConnectionRenderSnippet(args.ID, args.Name, args.Args)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionExecuteSnippet",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Name string
+			Args map[string]any
+			Opts *struct {
+				Confirmed bool `msgpack:"confirmed"`
+			}
+		},
+		) (any, error) {
+			confirmed := args.Opts != nil && args.Opts.Confirmed
+			call, err := h.ConnectionExecuteSnippet(args.ID, args.Name, args.Args, confirmed)
+			return handler.WrapCall(call), err
 		})
 }
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// auditRecord is a single line of the audit log
+type auditRecord struct {
+	Timestamp    time.Time `json:"timestamp"`
+	ConnectionID string    `json:"connection_id"`
+	Connection   string    `json:"connection"`
+	User         string    `json:"user"`
+	Statement    string    `json:"statement"`
+	DurationMs   float64   `json:"duration_ms"`
+	Rows         int       `json:"rows"`
+	Status       string    `json:"status"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// auditLog is an append-only log of executed statements in JSONL format.
+// It is kept separately from call history, which can be wiped.
+type auditLog struct {
+	mu   sync.Mutex
+	path string
+	user string
+	// the end of the log was checked for a line cut off by a crash
+	checked bool
+}
+
+func newAuditLog(path string) *auditLog {
+	username := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		username = u.Username
+	}
+
+	return &auditLog{
+		path: path,
+		user: username,
+	}
+}
+
+func (a *auditLog) write(rec *auditRecord) error {
+	rec.User = a.user
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	file, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("os.OpenFile: %w", err)
+	}
+	defer file.Close()
+
+	line := append(b, '\n')
+	if !a.checked {
+		// start on a new line if the last write was interrupted
+		if cut, err := endsWithPartialLine(file); err == nil && cut {
+			line = append([]byte{'\n'}, line...)
+		}
+		a.checked = true
+	}
+
+	_, err = file.Write(line)
+	if err != nil {
+		return fmt.Errorf("file.Write: %w", err)
+	}
+
+	// records of statements must survive a crash of the machine as well
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("file.Sync: %w", err)
+	}
+
+	return nil
+}
+
+// endsWithPartialLine reports whether the last line of the file is missing
+// its newline.
+func endsWithPartialLine(file *os.File) (bool, error) {
+	info, err := file.Stat()
+	if err != nil || info.Size() == 0 {
+		return false, err
+	}
+
+	last := make([]byte, 1)
+	if _, err := file.ReadAt(last, info.Size()-1); err != nil {
+		return false, err
+	}
+	return last[0] != '\n', nil
+}
+
+// SetAuditLog sets the path of the audit log. Empty path disables auditing.
+func (h *Handler) SetAuditLog(path string) {
+	if path == "" {
+		h.audit = nil
+		return
+	}
+	h.audit = newAuditLog(path)
+}
+
+// auditCall writes a finished call to the audit log (if enabled).
+func (h *Handler) auditCall(call *core.Call, connections []*core.Connection) {
+	audit := h.audit
+	if audit == nil {
+		return
+	}
+
+	ids := make([]string, len(connections))
+	names := make([]string, len(connections))
+	for i, c := range connections {
+		ids[i] = string(c.GetID())
+		names[i] = c.GetName()
+	}
+
+	rec := &auditRecord{
+		Timestamp:    call.GetTimestamp(),
+		ConnectionID: strings.Join(ids, ","),
+		Connection:   strings.Join(names, ","),
+		Statement:    call.GetQuery(),
+		DurationMs:   durationMs(call.GetTimeTaken()),
+		Status:       call.GetState().String(),
+	}
+	if err := call.Err(); err != nil {
+		rec.Error = err.Error()
+	}
+	if call.GetState() == core.CallStateArchived {
+		for i := 0; i < call.ResultSetCount(); i++ {
+			if res, err := call.GetResultSet(i); err == nil {
+				rec.Rows += res.Len()
+			}
+		}
+	}
+
+	if err := audit.write(rec); err != nil {
+		h.log.Errorf("audit.write: %s", err)
+	}
+}
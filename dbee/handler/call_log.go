@@ -8,15 +8,30 @@ import (
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 )
 
+// storeCallLog stores the call log. The command line appends to the stored call
+// log as well, so it's merged with calls of the handler by their IDs.
 func (h *Handler) storeCallLog() error {
-	store := make(map[core.ConnectionID][]*core.Call)
+	unlock, err := core.LockFile(callLogFileName)
+	if err != nil {
+		return fmt.Errorf("core.LockFile: %w", err)
+	}
+	defer unlock()
+
+	store, err := readCallLog()
+	if err != nil {
+		return err
+	}
 
 	for connID := range h.lookupConnection {
 		calls, err := h.ConnectionGetCalls(connID)
 		if err != nil || len(calls) < 1 {
 			continue
 		}
-		store[connID] = calls
+		merged, err := mergeCalls(store[connID], calls)
+		if err != nil {
+			return err
+		}
+		store[connID] = merged
 	}
 
 	b, err := json.MarshalIndent(store, "", "  ")
@@ -24,21 +39,79 @@ func (h *Handler) storeCallLog() error {
 		return fmt.Errorf("json.MarshalIndent: %w", err)
 	}
 
-	file, err := os.Create(callLogFileName)
-	if err != nil {
-		return fmt.Errorf("os.Create: %s", err)
+	if err := core.WriteFileAtomic(callLogFileName, b, 0o644); err != nil {
+		return fmt.Errorf("core.WriteFileAtomic: %w", err)
 	}
-	defer file.Close()
 
-	_, err = file.Write(b)
-	if err != nil {
-		return fmt.Errorf("file.Write: %w", err)
+	return nil
+}
+
+// mergeCalls merges calls into the stored calls of a connection. Stored calls
+// are replaced with their current state and the other calls are appended.
+func mergeCalls(stored []json.RawMessage, calls []*core.Call) ([]json.RawMessage, error) {
+	current := make(map[core.CallID]json.RawMessage, len(calls))
+	for _, call := range calls {
+		b, err := json.Marshal(call)
+		if err != nil {
+			return nil, fmt.Errorf("json.Marshal: %w", err)
+		}
+		current[call.GetID()] = b
 	}
 
-	return nil
+	merged := make([]json.RawMessage, 0, len(stored)+len(calls))
+	seen := make(map[core.CallID]bool, len(stored)+len(calls))
+	add := func(id core.CallID, b json.RawMessage) {
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		if c, ok := current[id]; ok {
+			b = c
+		}
+		merged = append(merged, b)
+	}
+
+	for _, raw := range stored {
+		var c struct {
+			ID core.CallID `json:"id"`
+		}
+		if err := json.Unmarshal(raw, &c); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal: %w", err)
+		}
+		add(c.ID, raw)
+	}
+	for _, call := range calls {
+		add(call.GetID(), nil)
+	}
+
+	return merged, nil
+}
+
+// readCallLog reads the stored call log. Calls are kept as they are stored.
+// The call log file should be locked by the caller.
+func readCallLog() (map[core.ConnectionID][]json.RawMessage, error) {
+	store := make(map[core.ConnectionID][]json.RawMessage)
+
+	b, err := os.ReadFile(callLogFileName)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &store); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal: %w", err)
+		}
+	}
+
+	return store, nil
 }
 
 func (h *Handler) restoreCallLog() error {
+	unlock, err := core.LockFile(callLogFileName)
+	if err != nil {
+		return fmt.Errorf("core.LockFile: %w", err)
+	}
+	defer unlock()
+
 	file, err := os.Open(callLogFileName)
 	if err != nil {
 		return fmt.Errorf("os.Open: %w", err)
@@ -51,20 +124,73 @@ func (h *Handler) restoreCallLog() error {
 
 	err = decoder.Decode(&store)
 	if err != nil {
-		return fmt.Errorf("decoder.Decode: %w", err)
+		// keep the damaged log aside, so it doesn't break appending to the log
+		file.Close()
+		if rerr := os.Rename(callLogFileName, callLogFileName+".corrupt"); rerr != nil {
+			return fmt.Errorf("decoder.Decode: %w (os.Rename: %s)", err, rerr)
+		}
+		return fmt.Errorf("decoder.Decode: %w (moved to %s)", err, callLogFileName+".corrupt")
 	}
 
 	for connID, calls := range store {
-		callIDs := make([]core.CallID, len(calls))
-
-		// fill call lookup
-		for i, c := range calls {
+		callIDs := make([]core.CallID, 0, len(calls))
+
+		// fill call lookup, skipping calls which were journaled twice or
+		// already made by this backend
+		for _, c := range calls {
+			if _, ok := h.lookupCall[c.GetID()]; ok {
+				continue
+			}
 			h.lookupCall[c.GetID()] = c
-			callIDs[i] = c.GetID()
+			callIDs = append(callIDs, c.GetID())
 		}
 
 		// add to conn-call lookup
 		h.lookupConnectionCall[connID] = append(h.lookupConnectionCall[connID], callIDs...)
+		h.events.CallLogChanged(connID)
+	}
+
+	return nil
+}
+
+// journalCall appends a finished call to the stored call log, so that it isn't
+// lost if the backend is killed before the whole call log is stored.
+func (h *Handler) journalCall(connID core.ConnectionID, call *core.Call) {
+	if connID == "" {
+		return
+	}
+	if err := AppendCallLog(connID, call); err != nil {
+		h.log.Errorf("AppendCallLog: %s", err)
+	}
+}
+
+// AppendCallLog adds a call to the stored call log, so that calls made outside
+// of the editor (e.g. from the command line) show up in the call log.
+func AppendCallLog(connID core.ConnectionID, call *core.Call) error {
+	unlock, err := core.LockFile(callLogFileName)
+	if err != nil {
+		return fmt.Errorf("core.LockFile: %w", err)
+	}
+	defer unlock()
+
+	store, err := readCallLog()
+	if err != nil {
+		return err
+	}
+
+	c, err := json.Marshal(call)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	store[connID] = append(store[connID], c)
+
+	b, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent: %w", err)
+	}
+
+	if err := core.WriteFileAtomic(callLogFileName, b, 0o644); err != nil {
+		return fmt.Errorf("core.WriteFileAtomic: %w", err)
 	}
 
 	return nil
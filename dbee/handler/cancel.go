@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+var (
+	ErrCancelTimeout = errors.New("some work did not stop in time")
+	ErrUnknownExport = errors.New("unknown or finished export")
+)
+
+// exportTracker keeps track of in-progress exports, so they can be canceled.
+type exportTracker struct {
+	mu      sync.Mutex
+	next    int
+	cancels map[int]context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// start registers a new export. done has to be called when the export finishes.
+func (et *exportTracker) start() (id int, ctx context.Context, done func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	et.mu.Lock()
+	defer et.mu.Unlock()
+
+	if et.cancels == nil {
+		et.cancels = make(map[int]context.CancelFunc)
+	}
+	id = et.next
+	et.next++
+	et.cancels[id] = cancel
+	et.wg.Add(1)
+
+	return id, ctx, func() {
+		et.mu.Lock()
+		delete(et.cancels, id)
+		et.mu.Unlock()
+		cancel()
+		et.wg.Done()
+	}
+}
+
+// cancel cancels the export with id.
+func (et *exportTracker) cancel(id int) error {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+
+	cancel, ok := et.cancels[id]
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrUnknownExport, id)
+	}
+	cancel()
+	return nil
+}
+
+// cancelAll cancels all in-progress exports and returns their number.
+func (et *exportTracker) cancelAll() int {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+
+	for _, cancel := range et.cancels {
+		cancel()
+	}
+	return len(et.cancels)
+}
+
+// ctxWriter stops writing when the context is canceled.
+type ctxWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (cw *ctxWriter) Write(p []byte) (int, error) {
+	if err := cw.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cw.w.Write(p)
+}
+
+// CancelAll cancels every running call (including the ones writing results to
+// history) and every in-progress export. It waits for them to stop for at most
+// timeout and returns the number of canceled operations.
+func (h *Handler) CancelAll(timeout time.Duration) (int, error) {
+	var pending []*core.Call
+	for _, call := range h.lookupCall {
+		select {
+		case <-call.Done():
+			continue
+		default:
+		}
+		call.Cancel()
+		pending = append(pending, call)
+	}
+
+	canceled := len(pending) + h.exports.cancelAll()
+
+	deadline := time.After(timeout)
+	for _, call := range pending {
+		select {
+		case <-call.Done():
+		case <-deadline:
+			return canceled, fmt.Errorf("call %q: %w", call.GetID(), ErrCancelTimeout)
+		}
+	}
+
+	exportsDone := make(chan struct{})
+	go func() {
+		h.exports.wg.Wait()
+		close(exportsDone)
+	}()
+	select {
+	case <-exportsDone:
+	case <-deadline:
+		return canceled, fmt.Errorf("exports: %w", ErrCancelTimeout)
+	}
+
+	return canceled, nil
+}
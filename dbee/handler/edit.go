@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// ConnectionGenerateEdits generates statements which apply edits of the call's
+// n-th result set to a table. If execute is set, the statements are also executed
+// in a transaction. Generated statements are returned in both cases.
+func (h *Handler) ConnectionGenerateEdits(connID core.ConnectionID, callID core.CallID, set int, edits *core.ResultEdits, execute, confirmed bool) ([]string, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return nil, fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	res, err := call.GetResultSet(set)
+	if err != nil {
+		return nil, fmt.Errorf("call.GetResultSet: %w", err)
+	}
+
+	statements, err := c.GenerateEdits(res, edits)
+	if err != nil {
+		return nil, fmt.Errorf("c.GenerateEdits: %w", err)
+	}
+
+	if !execute {
+		return statements, nil
+	}
+
+	if !confirmed {
+		for _, stmt := range statements {
+			if err := c.CheckGuard(stmt); err != nil {
+				return statements, err
+			}
+		}
+	}
+
+	err = c.ApplyEdits(statements)
+	if err != nil {
+		return statements, fmt.Errorf("c.ApplyEdits: %w", err)
+	}
+
+	return statements, nil
+}
+
+// ConnectionUploadBlob writes a file into a binary column and returns the
+// number of written bytes.
+func (h *Handler) ConnectionUploadBlob(connID core.ConnectionID, opts *core.BlobUploadOptions) (int64, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return 0, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	written, err := c.UploadBlob(opts)
+	if err != nil {
+		return 0, fmt.Errorf("c.UploadBlob: %w", err)
+	}
+
+	return written, nil
+}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+var errorHistoryFileName = filepath.Join(core.StorageDir(), "dbee-errors.json")
+
+// default number of failed queries kept in the error history
+const defaultErrorHistorySize = 500
+
+// FailedQuery is a record of the error history: a call which failed, along
+// with the connection it was executed on.
+type FailedQuery struct {
+	ConnectionID core.ConnectionID `json:"connection_id"`
+	Connection   string            `json:"connection"`
+	// call with the statement, error, duration and timestamp
+	Call *core.Call `json:"call"`
+}
+
+// ErrorHistoryOptions configure the error history. Its retention is separate
+// from the call log, so failed attempts are kept after the call log is wiped.
+type ErrorHistoryOptions struct {
+	// record failed calls
+	Enabled bool
+	// maximum number of kept records (defaultErrorHistorySize if 0)
+	MaxEntries int
+	// records older than this are removed, 0 keeps them regardless of age
+	MaxAge time.Duration
+}
+
+// errorHistory keeps failed calls in a file shared by all backends.
+type errorHistory struct {
+	mu   sync.Mutex
+	opts ErrorHistoryOptions
+	path string
+}
+
+func newErrorHistory() *errorHistory {
+	return &errorHistory{
+		path: errorHistoryFileName,
+	}
+}
+
+func (eh *errorHistory) options() ErrorHistoryOptions {
+	eh.mu.Lock()
+	defer eh.mu.Unlock()
+
+	return eh.opts
+}
+
+// update reads the records, applies fn and the retention and writes them
+// back if they changed. The file is locked, because it's shared by all
+// backends.
+func (eh *errorHistory) update(fn func([]*FailedQuery) []*FailedQuery) ([]*FailedQuery, error) {
+	opts := eh.options()
+
+	unlock, err := core.LockFile(eh.path)
+	if err != nil {
+		return nil, fmt.Errorf("core.LockFile: %w", err)
+	}
+	defer unlock()
+
+	var entries []*FailedQuery
+	b, err := os.ReadFile(eh.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &entries); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal: %w", err)
+		}
+	}
+
+	count := len(entries)
+	var last *FailedQuery
+	if count > 0 {
+		last = entries[count-1]
+	}
+
+	updated := fn(entries)
+	// retention of disabled history isn't known, records are kept as they are
+	if opts.Enabled {
+		updated = pruneFailedQueries(updated, opts, time.Now())
+	}
+	// records are only added to the end or removed
+	if len(updated) == count && (count == 0 || updated[count-1] == last) {
+		return updated, nil
+	}
+
+	b, err = json.MarshalIndent(updated, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("json.MarshalIndent: %w", err)
+	}
+	if err := core.WriteFileAtomic(eh.path, b, 0o600); err != nil {
+		return nil, fmt.Errorf("core.WriteFileAtomic: %w", err)
+	}
+
+	return updated, nil
+}
+
+// pruneFailedQueries removes records which are too old and the oldest ones
+// over the maximum number of records.
+func pruneFailedQueries(entries []*FailedQuery, opts ErrorHistoryOptions, now time.Time) []*FailedQuery {
+	maxEntries := opts.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultErrorHistorySize
+	}
+
+	kept := make([]*FailedQuery, 0, len(entries))
+	for _, entry := range entries {
+		if entry == nil || entry.Call == nil {
+			continue
+		}
+		if opts.MaxAge > 0 && now.Sub(entry.Call.GetTimestamp()) > opts.MaxAge {
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	if len(kept) > maxEntries {
+		kept = kept[len(kept)-maxEntries:]
+	}
+
+	return kept
+}
+
+// SetErrorHistory configures the error history. Records of disabled history
+// are kept until they are cleared.
+func (h *Handler) SetErrorHistory(opts *ErrorHistoryOptions) error {
+	h.errorHistory.mu.Lock()
+	if opts == nil {
+		opts = &ErrorHistoryOptions{}
+	}
+	h.errorHistory.opts = *opts
+	h.errorHistory.mu.Unlock()
+
+	if !opts.Enabled {
+		return nil
+	}
+
+	// apply the retention to records of previous runs
+	_, err := h.errorHistory.update(func(entries []*FailedQuery) []*FailedQuery { return entries })
+	return err
+}
+
+// GetFailedQueries returns records of the error history from the newest one.
+// Records of all connections are returned if connID is empty, limit 0 returns
+// all of them.
+func (h *Handler) GetFailedQueries(connID core.ConnectionID, limit int) ([]*FailedQuery, error) {
+	entries, err := h.errorHistory.update(func(entries []*FailedQuery) []*FailedQuery { return entries })
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*FailedQuery
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if connID != "" && entry.ConnectionID != connID {
+			continue
+		}
+		out = append(out, entry)
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+
+	return out, nil
+}
+
+// ClearFailedQueries removes records of the connection from the error
+// history, or all records if connID is empty.
+func (h *Handler) ClearFailedQueries(connID core.ConnectionID) error {
+	_, err := h.errorHistory.update(func(entries []*FailedQuery) []*FailedQuery {
+		if connID == "" {
+			return nil
+		}
+		kept := entries[:0]
+		for _, entry := range entries {
+			if entry.ConnectionID != connID {
+				kept = append(kept, entry)
+			}
+		}
+		return kept
+	})
+	return err
+}
+
+// recordFailedQuery adds a failed call to the error history. Canceled calls
+// aren't failed attempts, so they aren't recorded.
+func (h *Handler) recordFailedQuery(call *core.Call, state core.CallState, connections []*core.Connection) {
+	if !h.errorHistory.options().Enabled || call.Err() == nil {
+		return
+	}
+	if state != core.CallStateExecutingFailed && state != core.CallStateRetrievingFailed {
+		return
+	}
+
+	entry := &FailedQuery{
+		Call: call,
+	}
+	if len(connections) == 1 {
+		entry.ConnectionID = connections[0].GetID()
+		entry.Connection = connections[0].GetName()
+	}
+
+	_, err := h.errorHistory.update(func(entries []*FailedQuery) []*FailedQuery {
+		return append(entries, entry)
+	})
+	if err != nil {
+		h.log.Errorf("errorHistory.update: %s", err)
+	}
+}
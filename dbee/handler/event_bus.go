@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/neovim/go-client/nvim"
@@ -9,22 +11,62 @@ import (
 	"github.com/kndndrj/nvim-dbee/dbee/plugin"
 )
 
+// States of connections sent with connection_state_changed
+const (
+	connectionStateConnected = "connected"
+	connectionStateRemoved   = "removed"
+)
+
 type eventBus struct {
 	vim *nvim.Nvim
 	log *plugin.Logger
 }
 
 func (eb *eventBus) callLua(event string, data string) {
+	// the owner of a shared backend has no editor
+	if eb.vim == nil {
+		return
+	}
+
 	err := eb.vim.ExecLua(fmt.Sprintf(`require("dbee.handler.__events").trigger(%q, %s)`, event, data), nil)
 	if err != nil {
 		eb.log.Infof("eb.vim.ExecLua: %s", err)
 	}
 }
 
+// callLuaWith triggers the event with data encoded as an argument of the lua
+// call, for data which can't be written as a literal (e.g. rows of results).
+func (eb *eventBus) callLuaWith(event string, data any) {
+	if eb.vim == nil {
+		return
+	}
+
+	err := eb.vim.ExecLua(fmt.Sprintf(`require("dbee.handler.__events").trigger(%q, ...)`, event), nil, data)
+	if err != nil {
+		eb.log.Infof("eb.vim.ExecLua: %s", err)
+	}
+}
+
 func (eb *eventBus) CallStateChanged(call *core.Call) {
 	errMsg := "nil"
+	errInfo := "nil"
 	if err := call.Err(); err != nil {
 		errMsg = fmt.Sprintf("[[%s]]", err.Error())
+
+		var qErr *core.QueryError
+		if errors.As(err, &qErr) {
+			errInfo = fmt.Sprintf(`{
+				category = %q,
+				code = %q,
+				position = %d,
+				line = %d,
+				column = %d,
+				hint = %q,
+				detail = %q,
+				object_kind = %q,
+				object = %q,
+			}`, qErr.Category, qErr.Code, qErr.Position, qErr.Line, qErr.Column, qErr.Hint, qErr.Detail, qErr.ObjectKind, qErr.Object)
+		}
 	}
 
 	data := fmt.Sprintf(`{
@@ -35,13 +77,15 @@ func (eb *eventBus) CallStateChanged(call *core.Call) {
 			time_taken_us = %d,
 			timestamp_us = %d,
 			error = %s,
+			error_info = %s,
 		},
 	}`, call.GetID(),
 		call.GetQuery(),
 		call.GetState().String(),
 		call.GetTimeTaken().Microseconds(),
 		call.GetTimestamp().UnixMicro(),
-		errMsg)
+		errMsg,
+		errInfo)
 
 	eb.callLua("call_state_changed", data)
 }
@@ -64,3 +108,155 @@ func (eb *eventBus) DatabaseSelected(id core.ConnectionID, dbname string) {
 
 	eb.callLua("database_selected", data)
 }
+
+// ScheduleResultChanged is called when a scheduled query returns a different result
+// than in the previous run.
+func (eb *eventBus) ScheduleResultChanged(schedule *core.Schedule, call *core.Call) {
+	data := fmt.Sprintf(`{
+		schedule_id = %q,
+		conn_id = %q,
+		call_id = %q,
+	}`, schedule.GetID(), schedule.GetConnectionID(), call.GetID())
+
+	eb.callLua("schedule_result_changed", data)
+}
+
+// WatchUpdated is called with the first result of a watched query and
+// whenever its result changes or a run fails.
+func (eb *eventBus) WatchUpdated(watch *core.Watch, update *core.WatchUpdate) {
+	eb.callLuaWith("watch_updated", WrapWatchUpdate(watch, update))
+}
+
+// ImportProgress is called after every batch of a bulk import.
+func (eb *eventBus) ImportProgress(connID core.ConnectionID, progress *core.ImportProgress) {
+	data := fmt.Sprintf(`{
+		conn_id = %q,
+		call_id = %q,
+		imported = %d,
+		failed = %d,
+		bytes_read = %d,
+		bytes_total = %d,
+	}`, connID, progress.CallID, progress.Imported, progress.Failed, progress.BytesRead, progress.BytesTotal)
+
+	eb.callLua("import_progress", data)
+}
+
+// ExportProgress is called while a result is exported to a file.
+func (eb *eventBus) ExportProgress(id int, callID core.CallID, progress *core.ExportProgress) {
+	data := fmt.Sprintf(`{
+		export_id = %d,
+		call_id = %q,
+		rows = %d,
+		rows_total = %d,
+		bytes_written = %d,
+	}`, id, callID, progress.Rows, progress.RowsTotal, progress.BytesWritten)
+
+	eb.callLua("export_progress", data)
+}
+
+// ExportFinished is called when an export is done, failed or was canceled.
+func (eb *eventBus) ExportFinished(id int, callID core.CallID, path string, err error) {
+	errMsg := "nil"
+	if err != nil {
+		errMsg = fmt.Sprintf("[[%s]]", err.Error())
+	}
+
+	data := fmt.Sprintf(`{
+		export_id = %d,
+		call_id = %q,
+		path = %q,
+		canceled = %t,
+		error = %s,
+	}`, id, callID, path, errors.Is(err, context.Canceled), errMsg)
+
+	eb.callLua("export_finished", data)
+}
+
+// QueryStarted is called when a call starts executing. Connection id is empty
+// for calls on multiple connections.
+func (eb *eventBus) QueryStarted(connID core.ConnectionID, call *core.Call, status QueryStatus) {
+	data := fmt.Sprintf(`{
+		conn_id = %q,
+		call_id = %q,
+		query = %q,
+		active_calls = %d,
+	}`, connID, call.GetID(), call.GetQuery(), status.ActiveCalls)
+
+	eb.callLua("query_started", data)
+}
+
+// QueryFinished is called when a call is done, failed or was canceled.
+func (eb *eventBus) QueryFinished(connID core.ConnectionID, call *core.Call, state core.CallState, status QueryStatus) {
+	errMsg := "nil"
+	if status.LastError != "" {
+		errMsg = fmt.Sprintf("[[%s]]", status.LastError)
+	}
+
+	data := fmt.Sprintf(`{
+		conn_id = %q,
+		call_id = %q,
+		state = %q,
+		active_calls = %d,
+		duration_us = %d,
+		error = %s,
+	}`, connID, call.GetID(), state.String(), status.ActiveCalls, status.LastDuration.Microseconds(), errMsg)
+
+	eb.callLua("query_finished", data)
+}
+
+// CallNotification is called when a call marked for notification finishes.
+func (eb *eventBus) CallNotification(n *CallNotification) {
+	errMsg := "nil"
+	if n.Error != "" {
+		errMsg = fmt.Sprintf("[[%s]]", n.Error)
+	}
+
+	data := fmt.Sprintf(`{
+		call_id = %q,
+		conn_id = %q,
+		connection = %q,
+		state = %q,
+		duration_ms = %f,
+		rows = %d,
+		error = %s,
+		message = %q,
+	}`, n.CallID, n.ConnectionID, n.Connection, n.State, n.DurationMs, n.Rows, errMsg, n.Message)
+
+	eb.callLua("call_notification", data)
+}
+
+// ConnectionStateChanged is called when a connection is created or removed.
+func (eb *eventBus) ConnectionStateChanged(id core.ConnectionID, state string) {
+	data := fmt.Sprintf(`{
+		conn_id = %q,
+		state = %q,
+	}`, id, state)
+
+	eb.callLua("connection_state_changed", data)
+}
+
+// CallLogChanged is called when calls are added to the call log of a connection.
+func (eb *eventBus) CallLogChanged(id core.ConnectionID) {
+	data := fmt.Sprintf(`{
+		conn_id = %q,
+	}`, id)
+
+	eb.callLua("call_log_changed", data)
+}
+
+// StructureRefreshed is called when the structure of a connection is loaded
+// again after a refresh. Schema is empty if the whole structure was refreshed.
+func (eb *eventBus) StructureRefreshed(id core.ConnectionID, schema string, err error) {
+	errMsg := "nil"
+	if err != nil {
+		errMsg = fmt.Sprintf("[[%s]]", err.Error())
+	}
+
+	data := fmt.Sprintf(`{
+		conn_id = %q,
+		schema = %q,
+		error = %s,
+	}`, id, schema, errMsg)
+
+	eb.callLua("structure_refreshed", data)
+}
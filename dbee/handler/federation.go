@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/kndndrj/nvim-dbee/dbee/adapters"
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// federationEngineParams are parameters of the default engine of federated
+// queries: an in-memory sqlite database shared by all calls.
+var federationEngineParams = &core.ConnectionParams{
+	ID:   "__federation",
+	Name: "federation",
+	Type: "sqlite",
+	URL:  "file:dbee_federation?mode=memory&cache=shared",
+}
+
+// FederatedTableSource is the source of a table of a federated query: a query
+// on a connection or a result set of a call.
+type FederatedTableSource struct {
+	// name of the table in the federated query
+	Name         string
+	ConnectionID core.ConnectionID
+	Query        string
+	CallID       core.CallID
+	ResultSet    int
+}
+
+// ConnectionsFederate loads the tables from their connections and calls into
+// the engine and runs the query on it (see core.Connection.Federate). The
+// default in-memory engine is used if engineID is empty. The call is stored
+// under the engine, or under the connection of the first table with the
+// default engine.
+func (h *Handler) ConnectionsFederate(engineID core.ConnectionID, sources []*FederatedTableSource, query string) (*core.Call, error) {
+	if len(sources) < 1 {
+		return nil, errors.New("no tables provided")
+	}
+
+	var ownerID core.ConnectionID
+	tables := make([]*core.FederatedTable, len(sources))
+	for i, source := range sources {
+		table := &core.FederatedTable{
+			Name:      source.Name,
+			Query:     source.Query,
+			ResultSet: source.ResultSet,
+		}
+
+		connID := source.ConnectionID
+		if source.CallID != "" {
+			call, ok := h.lookupCall[source.CallID]
+			if !ok {
+				return nil, fmt.Errorf("unknown call with id: %q", source.CallID)
+			}
+			table.Call = call
+			connID = h.callConnectionID(source.CallID)
+		} else {
+			c, ok := h.lookupConnection[connID]
+			if !ok {
+				return nil, fmt.Errorf("unknown connection with id: %q", connID)
+			}
+			table.Connection = c
+		}
+		if _, ok := h.lookupConnection[connID]; ok && ownerID == "" {
+			ownerID = connID
+		}
+
+		tables[i] = table
+	}
+
+	engine, err := h.federationEngine(engineID)
+	if err != nil {
+		return nil, err
+	}
+	if engineID != "" {
+		ownerID = engineID
+	}
+	owner, ok := h.lookupConnection[ownerID]
+	if !ok {
+		return nil, errors.New("no open connection to store the call under")
+	}
+
+	call, err := engine.Federate(tables, query, h.callStateHandler(owner))
+	if err != nil {
+		return nil, fmt.Errorf("engine.Federate: %w", err)
+	}
+
+	h.addCall(ownerID, call)
+
+	return call, nil
+}
+
+// federationEngine returns the connection with id or the default engine,
+// which is created on first use.
+func (h *Handler) federationEngine(id core.ConnectionID) (*core.Connection, error) {
+	if id != "" {
+		c, ok := h.lookupConnection[id]
+		if !ok {
+			return nil, fmt.Errorf("unknown connection with id: %q", id)
+		}
+		return c, nil
+	}
+
+	if h.defaultFederationEngine == nil {
+		c, err := adapters.NewConnection(federationEngineParams)
+		if err != nil {
+			return nil, fmt.Errorf("adapters.NewConnection: %w", err)
+		}
+		h.defaultFederationEngine = c
+	}
+	return h.defaultFederationEngine, nil
+}
+
+// callConnectionID returns the id of the connection the call is stored under.
+func (h *Handler) callConnectionID(callID core.CallID) core.ConnectionID {
+	for connID, callIDs := range h.lookupConnectionCall {
+		if slices.Contains(callIDs, callID) {
+			return connID
+		}
+	}
+	return ""
+}
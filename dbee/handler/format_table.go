@@ -1,47 +1,264 @@
 package handler
 
 import (
-	"github.com/jedib0t/go-pretty/v6/table"
-	"github.com/jedib0t/go-pretty/v6/text"
+	"reflect"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 )
 
 var _ core.Formatter = (*Table)(nil)
 
-type Table struct{}
+const (
+	tableColumnSeparator = "│"
+	tableHeaderSeparator = "─"
+	tableCrossSeparator  = "┼"
+)
+
+// Table renders results as aligned tables. Cells are aligned by their display
+// widths in the editor, so tables with wide (e.g. CJK or emoji) and zero width
+// (e.g. combining) characters stay aligned.
+type Table struct {
+	width   *runewidth.Condition
+	display *core.ValueDisplay
+}
+
+// newTable returns a table formatter. If ambiguousWide is set, characters of
+// ambiguous width are two cells wide (as with 'ambiwidth' set to "double").
+// Values are converted to text with display (raw values if it's nil).
+func newTable(ambiguousWide bool, display *core.ValueDisplay) *Table {
+	width := runewidth.NewCondition()
+	width.EastAsianWidth = ambiguousWide
+	width.StrictEmojiNeutral = true
+
+	return &Table{width: width, display: display}
+}
 
-func newTable() *Table {
-	return &Table{}
+// NewTableFormatter returns the formatter of results displayed in the editor.
+func NewTableFormatter() core.Formatter {
+	return newTable(false, nil)
+}
+
+// TableCell is a rendered cell of a table.
+type TableCell struct {
+	// text of the cell, cells with line breaks span multiple lines
+	Lines []string
+	// display width of the widest line
+	Width int
+	// byte offset of the cell's text in the first line of its row
+	Col int
+}
+
+// TableLayout is a rendered table with display widths and positions of its
+// cells. The first column holds row numbers.
+type TableLayout struct {
+	Header []*TableCell
+	Rows   [][]*TableCell
+	// display widths of columns
+	Widths []int
+	// numeric columns are aligned to the right
+	RightAligned []bool
+	// line of the first row is 2 (after the header and the separator), rows
+	// with multi line cells take more lines
+	RowLines []int
+	// index of the first row
+	ChunkStart int
+	// rendered lines of the table
+	Lines []string
 }
 
 func (tf *Table) Format(header core.Header, rows []core.Row, opts *core.FormatterOptions) ([]byte, error) {
-	tableHeaders := []any{""}
-	for _, k := range header {
-		tableHeaders = append(tableHeaders, k)
+	return []byte(strings.Join(tf.Layout(header, rows, opts).Lines, "\n")), nil
+}
+
+// Layout renders the table and returns its layout.
+func (tf *Table) Layout(header core.Header, rows []core.Row, opts *core.FormatterOptions) *TableLayout {
+	layout := &TableLayout{
+		Widths:       make([]int, len(header)+1),
+		RightAligned: make([]bool, len(header)+1),
+		ChunkStart:   opts.ChunkStart,
 	}
-	index := opts.ChunkStart
 
-	var tableRows []table.Row
+	// columns are numeric if all of their values are numbers
+	for i := range layout.RightAligned {
+		layout.RightAligned[i] = true
+	}
 	for _, row := range rows {
-		indexedRow := append([]any{index + 1}, row...)
-		tableRows = append(tableRows, table.Row(indexedRow))
-		index += 1
-	}
-
-	t := table.NewWriter()
-	t.AppendHeader(table.Row(tableHeaders))
-	t.AppendRows(tableRows)
-	t.AppendSeparator()
-	t.SetStyle(table.StyleLight)
-	t.Style().Format = table.FormatOptions{
-		Footer: text.FormatDefault,
-		Header: text.FormatDefault,
-		Row:    text.FormatDefault,
-	}
-	t.Style().Options.DrawBorder = false
-	t.SuppressTrailingSpaces()
-	render := t.Render()
-
-	return []byte(render), nil
+		for i := range header {
+			if i >= len(row) || !isNumber(row[i]) {
+				layout.RightAligned[i+1] = false
+			}
+		}
+	}
+
+	layout.Header = tf.cells(layout, append(core.Row{""}, toRow(header)...))
+	for i, row := range rows {
+		values := core.Row{opts.ChunkStart + i + 1}
+		for j := range header {
+			if j < len(row) {
+				values = append(values, row[j])
+			} else {
+				values = append(values, "")
+			}
+		}
+		layout.Rows = append(layout.Rows, tf.cells(layout, values))
+	}
+
+	layout.Lines = append(layout.Lines, tf.renderRow(layout, layout.Header)...)
+	separators := make([]string, len(layout.Widths))
+	for i, width := range layout.Widths {
+		separators[i] = strings.Repeat(tableHeaderSeparator, width+2)
+	}
+	layout.Lines = append(layout.Lines, strings.Join(separators, tableCrossSeparator))
+	for _, row := range layout.Rows {
+		layout.RowLines = append(layout.RowLines, len(layout.Lines))
+		layout.Lines = append(layout.Lines, tf.renderRow(layout, row)...)
+	}
+
+	return layout
+}
+
+// cells converts values to cells and updates widths of columns.
+func (tf *Table) cells(layout *TableLayout, values core.Row) []*TableCell {
+	cells := make([]*TableCell, len(layout.Widths))
+	for i := range cells {
+		var value any = ""
+		if i < len(values) {
+			value = values[i]
+		}
+
+		cell := &TableCell{Lines: cellLines(tf.display.String(value))}
+		for _, line := range cell.Lines {
+			cell.Width = max(cell.Width, tf.displayWidth(line))
+		}
+		layout.Widths[i] = max(layout.Widths[i], cell.Width)
+		cells[i] = cell
+	}
+	return cells
+}
+
+// renderRow renders lines of a row and sets offsets of its cells.
+func (tf *Table) renderRow(layout *TableLayout, cells []*TableCell) []string {
+	height := 1
+	for _, cell := range cells {
+		height = max(height, len(cell.Lines))
+	}
+
+	lines := make([]string, height)
+	for l := range lines {
+		var b strings.Builder
+		for i, cell := range cells {
+			if i > 0 {
+				b.WriteString(tableColumnSeparator)
+			}
+			b.WriteString(" ")
+
+			text := ""
+			if l < len(cell.Lines) {
+				text = cell.Lines[l]
+			}
+			padding := strings.Repeat(" ", layout.Widths[i]-tf.displayWidth(text))
+			if layout.RightAligned[i] {
+				b.WriteString(padding)
+			}
+			if l == 0 {
+				cell.Col = b.Len()
+			}
+			b.WriteString(text)
+			if !layout.RightAligned[i] {
+				b.WriteString(padding)
+			}
+
+			b.WriteString(" ")
+		}
+		lines[l] = strings.TrimRight(b.String(), " ")
+	}
+
+	return lines
+}
+
+// displayWidth returns the number of cells the text takes in the editor.
+// Grapheme clusters (e.g. emoji sequences, flags or letters with combining
+// marks) are measured as a whole.
+func (tf *Table) displayWidth(text string) int {
+	width := 0
+	graphemes := uniseg.NewGraphemes(text)
+	for graphemes.Next() {
+		width += tf.graphemeWidth(graphemes.Runes())
+	}
+	return width
+}
+
+func (tf *Table) graphemeWidth(runes []rune) int {
+	first := runes[0]
+	switch {
+	case first < 0x20 || first == 0x7f:
+		// displayed as ^X
+		return 2
+	case first >= 0x80 && first <= 0x9f:
+		// displayed as <xx>
+		return 4
+	case len(runes) > 1 && first >= 0x1f1e6 && first <= 0x1f1ff:
+		// pair of regional indicators is a flag
+		return 2
+	}
+
+	for _, r := range runes[1:] {
+		// emoji presentation selector
+		if r == 0xfe0f {
+			return 2
+		}
+	}
+	// the first character which isn't zero width determines the width
+	for _, r := range runes {
+		if w := tf.width.RuneWidth(r); w > 0 {
+			return w
+		}
+	}
+	return 0
+}
+
+// cellLines splits text of a cell to lines.
+func cellLines(text string) []string {
+	text = strings.ReplaceAll(text, "\t", "    ")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	return strings.Split(text, "\n")
+}
+
+func isNumber(value any) bool {
+	if value == nil {
+		return false
+	}
+
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func toRow(header core.Header) core.Row {
+	row := make(core.Row, len(header))
+	for i, h := range header {
+		row[i] = h
+	}
+	return row
+}
+
+// tableLayoutRecorder formats results as tables and keeps the last layout.
+type tableLayoutRecorder struct {
+	table  *Table
+	layout *TableLayout
+}
+
+func (tr *tableLayoutRecorder) Format(header core.Header, rows []core.Row, opts *core.FormatterOptions) ([]byte, error) {
+	tr.layout = tr.table.Layout(header, rows, opts)
+	return nil, nil
 }
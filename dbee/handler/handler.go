@@ -1,12 +1,18 @@
 package handler
 
 import (
+	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"reflect"
 	"slices"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/neovim/go-client/nvim"
@@ -17,7 +23,13 @@ import (
 	"github.com/kndndrj/nvim-dbee/dbee/plugin"
 )
 
-const callLogFileName = "/tmp/dbee-calllog.json"
+var callLogFileName = filepath.Join(core.StorageDir(), "dbee-calllog.json")
+
+// closeTimeout is how long in-flight work has to stop when the handler is closed.
+const closeTimeout = 10 * time.Second
+
+// exportProgressInterval is the minimum interval between export_progress events.
+const exportProgressInterval = 200 * time.Millisecond
 
 type Handler struct {
 	vim    *nvim.Nvim
@@ -27,27 +39,76 @@ type Handler struct {
 	lookupConnection     map[core.ConnectionID]*core.Connection
 	lookupCall           map[core.CallID]*core.Call
 	lookupConnectionCall map[core.ConnectionID][]core.CallID
+	lookupSchedule       map[core.ScheduleID]*core.Schedule
+	lookupWatch          map[core.WatchID]*core.Watch
+
+	// lock held by endpoints while they run, which is taken by runs of
+	// schedules and watches before they touch the lookups
+	lock sync.Locker
 
 	currentConnectionID core.ConnectionID
+
+	// audit log of executed statements (nil if disabled)
+	audit *auditLog
+	// writes of finished calls to the audit log and call log
+	finishing sync.WaitGroup
+	// named query snippets
+	snippets *core.SnippetStore
+	// in-progress exports of results
+	exports exportTracker
+	// counters of finished queries
+	metrics *metrics
+	// running queries and the last finished one
+	queries *queryTracker
+	// calls which took longer than a threshold
+	slowLog *slowQueryLog
+	// failed calls kept for later fixing
+	errorHistory *errorHistory
+	// calls marked for notification when they finish
+	notifier *notifier
+	// characters of ambiguous width are two cells wide in the editor
+	ambiguousWide bool
+	// engine of federated queries without an explicit one (nil until used)
+	defaultFederationEngine *core.Connection
 }
 
 func New(vim *nvim.Nvim, logger *plugin.Logger) *Handler {
 	h := &Handler{
 		vim: vim,
-		log: logger,
+		log: logger.With("handler"),
 		events: &eventBus{
 			vim: vim,
-			log: logger,
+			log: logger.With("events"),
 		},
 
 		lookupConnection:     make(map[core.ConnectionID]*core.Connection),
 		lookupCall:           make(map[core.CallID]*core.Call),
 		lookupConnectionCall: make(map[core.ConnectionID][]core.CallID),
+		lookupSchedule:       make(map[core.ScheduleID]*core.Schedule),
+		lookupWatch:          make(map[core.WatchID]*core.Watch),
+
+		lock: &sync.Mutex{},
+
+		metrics:      newMetrics(),
+		queries:      newQueryTracker(),
+		slowLog:      newSlowQueryLog(),
+		errorHistory: newErrorHistory(),
+		notifier:     newNotifier(),
 	}
 
-	// restore the call log concurrently
+	// in-memory until a file is set
+	h.snippets, _ = core.NewSnippetStore("")
+
+	// repair leftovers of a crash and restore the call log concurrently
 	go func() {
-		err := h.restoreCallLog()
+		report, err := core.RecoverStorage()
+		if err != nil {
+			h.log.Errorf("core.RecoverStorage: %s", err)
+		} else if !report.Empty() {
+			h.log.Infof("removed %d incomplete archives and %d temporary files of a previous run", report.IncompleteArchives, report.TempFiles)
+		}
+
+		err = h.restoreCallLog()
 		if err != nil {
 			h.log.Infof("h.restoreCallLog: %s", err)
 		}
@@ -56,17 +117,45 @@ func New(vim *nvim.Nvim, logger *plugin.Logger) *Handler {
 	return h
 }
 
+// SetLock replaces the lock which endpoints of the handler hold while they
+// run (see plugin.Plugin.SetLock).
+func (h *Handler) SetLock(lock sync.Locker) {
+	h.lock = lock
+}
+
 func (h *Handler) Close() {
-	// wait for unfinished calls
-	for _, c := range h.lookupCall {
-		select {
-		case <-c.Done():
-		case <-time.After(10 * time.Second):
-		}
+	// endpoints and runs of schedules and watches don't touch lookups while closing
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	// stop schedules and watches so they don't spawn new calls
+	for _, s := range h.lookupSchedule {
+		s.Stop()
+	}
+	for _, w := range h.lookupWatch {
+		w.Stop()
+	}
+
+	// stop unfinished calls and exports
+	_, err := h.CancelAll(closeTimeout)
+	if err != nil {
+		h.log.Infof("h.CancelAll: %s", err)
+	}
+
+	// flush writes of the canceled calls
+	flushed := make(chan struct{})
+	go func() {
+		h.finishing.Wait()
+		close(flushed)
+	}()
+	select {
+	case <-flushed:
+	case <-time.After(closeTimeout):
+		h.log.Infof("writes of finished calls did not complete in %s", closeTimeout)
 	}
 
 	// store call log
-	err := h.storeCallLog()
+	err = h.storeCallLog()
 	if err != nil {
 		h.log.Infof("h.storeCallLog: %s", err)
 	}
@@ -75,9 +164,18 @@ func (h *Handler) Close() {
 	for _, c := range h.lookupConnection {
 		c.Close()
 	}
+	if h.defaultFederationEngine != nil {
+		h.defaultFederationEngine.Close()
+	}
 }
 
 func (h *Handler) CreateConnection(params *core.ConnectionParams) (core.ConnectionID, error) {
+	// editors sharing the backend create the same connections
+	if existing, ok := h.lookupConnection[params.ID]; ok && reflect.DeepEqual(existing.GetParams(), params) {
+		_ = h.SetCurrentConnection(existing.GetID())
+		return existing.GetID(), nil
+	}
+
 	c, err := adapters.NewConnection(params)
 	if err != nil {
 		return "", fmt.Errorf("adapters.NewConnection: %w", err)
@@ -90,6 +188,7 @@ func (h *Handler) CreateConnection(params *core.ConnectionParams) (core.Connecti
 	}
 
 	h.lookupConnection[c.GetID()] = c
+	h.events.ConnectionStateChanged(c.GetID(), connectionStateConnected)
 	_ = h.SetCurrentConnection(c.GetID())
 
 	return c.GetID(), nil
@@ -102,6 +201,21 @@ func (h *Handler) DeleteConnection(id core.ConnectionID) error {
 	}
 	c.Close()
 	delete(h.lookupConnection, id)
+	h.events.ConnectionStateChanged(id, connectionStateRemoved)
+
+	for sID, s := range h.lookupSchedule {
+		if s.GetConnectionID() == id {
+			s.Stop()
+			delete(h.lookupSchedule, sID)
+		}
+	}
+	for wID, w := range h.lookupWatch {
+		if w.GetConnectionID() == id {
+			w.Stop()
+			delete(h.lookupWatch, wID)
+		}
+	}
+
 	return nil
 }
 
@@ -118,6 +232,25 @@ func (h *Handler) GetConnections(ids []core.ConnectionID) []*core.Connection {
 	return conns
 }
 
+// RegisterAdapter registers an adapter from an external binary or a go plugin
+// for connections of the provided type.
+func (h *Handler) RegisterAdapter(typ string, opts *adapters.AdapterOptions) error {
+	return new(adapters.Mux).LoadAdapter(typ, opts)
+}
+
+func (h *Handler) GetAdapters() []*adapters.AdapterInfo {
+	return new(adapters.Mux).GetAdapters()
+}
+
+func (h *Handler) ConnectionGetCapabilities(connID core.ConnectionID) ([]string, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	return c.GetCapabilities(), nil
+}
+
 func (h *Handler) AddHelpers(typ string, helpers map[string]string) error {
 	return new(adapters.Mux).AddHelpers(typ, helpers)
 }
@@ -156,197 +289,1461 @@ func (h *Handler) SetCurrentConnection(connID core.ConnectionID) error {
 	return nil
 }
 
+// ConnectionExecute executes the query on connection. If the connection is guarded
+// and the query is destructive, core.ErrConfirmationRequired is returned and
+// the query needs to be re-sent with ConnectionExecuteConfirmed.
 func (h *Handler) ConnectionExecute(connID core.ConnectionID, query string) (*core.Call, error) {
+	return h.connectionExecute(connID, query, false)
+}
+
+// ConnectionExecuteConfirmed executes the query without checking the connection guard.
+func (h *Handler) ConnectionExecuteConfirmed(connID core.ConnectionID, query string) (*core.Call, error) {
+	return h.connectionExecute(connID, query, true)
+}
+
+func (h *Handler) connectionExecute(connID core.ConnectionID, query string, confirmed bool) (*core.Call, error) {
 	c, ok := h.lookupConnection[connID]
 	if !ok {
 		return nil, fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	call := c.Execute(query, func(state core.CallState, c *core.Call) {
-		if err := c.Err(); err != nil {
-			h.log.Errorf("cl.Err: %s", err)
+	var call *core.Call
+	if confirmed {
+		call = c.ExecuteConfirmed(query, h.callStateHandler(c))
+	} else {
+		if err := c.CheckGuard(query); err != nil {
+			return nil, err
 		}
+		call = c.Execute(query, h.callStateHandler(c))
+	}
 
-		h.events.CallStateChanged(c)
-	})
+	h.addCall(connID, call)
 
-	id := call.GetID()
+	return call, nil
+}
 
-	// add to lookup
-	h.lookupCall[id] = call
-	h.lookupConnectionCall[connID] = append(h.lookupConnectionCall[connID], id)
+// ConnectionExecuteSample executes the query on a sample of rows, for quick
+// previews of huge tables (see core.Connection.ExecuteSample).
+func (h *Handler) ConnectionExecuteSample(connID core.ConnectionID, query string, opts *core.SampleOptions, confirmed bool) (*core.Call, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
 
-	// update current call and conn
-	_ = h.SetCurrentConnection(connID)
+	if !confirmed {
+		if err := c.CheckGuard(query); err != nil {
+			return nil, err
+		}
+	}
+	call, err := c.ExecuteSample(query, opts, confirmed, h.callStateHandler(c))
+	if err != nil {
+		return nil, fmt.Errorf("c.ExecuteSample: %w", err)
+	}
+
+	h.addCall(connID, call)
 
 	return call, nil
 }
 
-func (h *Handler) ConnectionGetCalls(connID core.ConnectionID) ([]*core.Call, error) {
-	_, ok := h.lookupConnection[connID]
+// ConnectionEstimateCost estimates the bytes the query would scan and their
+// price, without running it (see core.Connection.EstimateCost).
+func (h *Handler) ConnectionEstimateCost(connID core.ConnectionID, query string) (*core.CostEstimate, error) {
+	c, ok := h.lookupConnection[connID]
 	if !ok {
 		return nil, fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	var calls []*core.Call
-	callIDs, ok := h.lookupConnectionCall[connID]
+	estimate, err := c.EstimateCost(query)
+	if err != nil {
+		return nil, fmt.Errorf("c.EstimateCost: %w", err)
+	}
+
+	return estimate, nil
+}
+
+// ConnectionImport imports a local CSV or NDJSON file into a table on connection.
+// Progress is reported with "import_progress" events.
+func (h *Handler) ConnectionImport(connID core.ConnectionID, opts *core.ImportOptions) (*core.Call, error) {
+	c, ok := h.lookupConnection[connID]
 	if !ok {
-		return calls, nil
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
 	}
-	for _, cID := range callIDs {
-		c, ok := h.lookupCall[cID]
-		if !ok {
-			continue
-		}
-		calls = append(calls, c)
+
+	call, err := c.Import(opts, h.callStateHandler(c), func(p *core.ImportProgress) {
+		h.events.ImportProgress(connID, p)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return calls, nil
+	h.addCall(connID, call)
+
+	return call, nil
 }
 
-func (h *Handler) ConnectionGetParams(connID core.ConnectionID) (*core.ConnectionParams, error) {
+// ConnectionSeed inserts generated rows into a table on connection.
+func (h *Handler) ConnectionSeed(connID core.ConnectionID, opts *core.SeedOptions) (*core.Call, error) {
 	c, ok := h.lookupConnection[connID]
 	if !ok {
 		return nil, fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	return c.GetParams(), nil
+	call, err := c.Seed(opts, h.callStateHandler(c))
+	if err != nil {
+		return nil, err
+	}
+
+	h.addCall(connID, call)
+
+	return call, nil
 }
 
-func (h *Handler) ConnectionGetStructure(connID core.ConnectionID) ([]*core.Structure, error) {
+// ConnectionExportSchema writes definitions of objects on connection to files.
+func (h *Handler) ConnectionExportSchema(connID core.ConnectionID, opts *core.SchemaExportOptions) (*core.SchemaExport, error) {
 	c, ok := h.lookupConnection[connID]
 	if !ok {
 		return nil, fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	layout, err := c.GetStructure()
+	export, err := c.ExportSchema(opts)
 	if err != nil {
-		return nil, fmt.Errorf("c.GetStructure: %w", err)
+		return nil, fmt.Errorf("c.ExportSchema: %w", err)
 	}
 
-	return layout, nil
+	return export, nil
 }
 
-func (h *Handler) ConnectionGetColumns(connID core.ConnectionID, opts *core.TableOptions) ([]*core.Column, error) {
+// ConnectionDumpLayout returns the whole structure of connection as a JSON document.
+func (h *Handler) ConnectionDumpLayout(connID core.ConnectionID) (string, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return "", fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	dump, err := c.DumpLayout()
+	if err != nil {
+		return "", fmt.Errorf("c.DumpLayout: %w", err)
+	}
+
+	return string(dump), nil
+}
+
+// ConnectionGetCompletion returns names for completion of queries on connection.
+func (h *Handler) ConnectionGetCompletion(connID core.ConnectionID, opts *core.CompletionOptions) ([]*core.CompletionItem, error) {
 	c, ok := h.lookupConnection[connID]
 	if !ok {
 		return nil, fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	columns, err := c.GetColumns(opts)
+	items, err := c.GetCompletion(opts)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("c.GetCompletion: %w", err)
 	}
 
-	return columns, nil
+	return items, nil
 }
 
-func (h *Handler) ConnectionListDatabases(connID core.ConnectionID) (current string, available []string, err error) {
+// ConnectionGetSignatures returns overloads of a function or a procedure on connection.
+func (h *Handler) ConnectionGetSignatures(connID core.ConnectionID, opts *core.TableOptions) ([]*core.Signature, error) {
 	c, ok := h.lookupConnection[connID]
 	if !ok {
-		return "", nil, fmt.Errorf("unknown connection with id: %q", connID)
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	currentDB, availableDBs, err := c.ListDatabases()
+	signatures, err := c.GetSignatures(opts)
 	if err != nil {
-		if errors.Is(err, core.ErrDatabaseSwitchingNotSupported) {
-			return "", []string{}, nil
+		return nil, fmt.Errorf("c.GetSignatures: %w", err)
+	}
+
+	return signatures, nil
+}
+
+// ConnectionsExecute executes the same query on multiple connections concurrently
+// and aggregates the results into a single call. The call is stored under the first
+// connection.
+func (h *Handler) ConnectionsExecute(connIDs []core.ConnectionID, query string, confirmed bool) (*core.Call, error) {
+	if len(connIDs) < 1 {
+		return nil, errors.New("no connections provided")
+	}
+
+	connections := make([]*core.Connection, len(connIDs))
+	for i, id := range connIDs {
+		c, ok := h.lookupConnection[id]
+		if !ok {
+			return nil, fmt.Errorf("unknown connection with id: %q", id)
 		}
-		return "", nil, fmt.Errorf("c.ListDatabases: %w", err)
+		if !confirmed {
+			if err := c.CheckGuard(query); err != nil {
+				return nil, fmt.Errorf("%s: %w", c.GetName(), err)
+			}
+		}
+		connections[i] = c
 	}
 
-	return currentDB, availableDBs, nil
+	call := core.ExecuteOnMany(connections, query, confirmed, h.callStateHandler(connections...))
+
+	h.addCall(connIDs[0], call)
+
+	return call, nil
 }
 
-func (h *Handler) ConnectionSelectDatabase(connID core.ConnectionID, database string) error {
+// ConnectionsCompareSchemas compares tables of the source connection with the
+// ones of the target connection.
+func (h *Handler) ConnectionsCompareSchemas(sourceID, targetID core.ConnectionID, opts *core.CompareOptions) (*core.SchemaDiff, error) {
+	source, ok := h.lookupConnection[sourceID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", sourceID)
+	}
+	target, ok := h.lookupConnection[targetID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", targetID)
+	}
+
+	diff, err := core.CompareSchemas(source, target, opts)
+	if err != nil {
+		return nil, fmt.Errorf("core.CompareSchemas: %w", err)
+	}
+
+	return diff, nil
+}
+
+// ConnectionsCompareTableData compares rows of a table on the source
+// connection with the ones on the target connection (see core.CompareTableData).
+func (h *Handler) ConnectionsCompareTableData(sourceID, targetID core.ConnectionID, opts *core.TableCompareOptions) (*core.TableDataDiff, error) {
+	source, ok := h.lookupConnection[sourceID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", sourceID)
+	}
+	target, ok := h.lookupConnection[targetID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", targetID)
+	}
+
+	diff, err := core.CompareTableData(context.Background(), source, target, opts)
+	if err != nil {
+		return nil, fmt.Errorf("core.CompareTableData: %w", err)
+	}
+
+	return diff, nil
+}
+
+// ConnectionsExport writes connections to a file which can be shared, with
+// credentials replaced by secret references (see core.ExportConnections).
+func (h *Handler) ConnectionsExport(connIDs []core.ConnectionID, path string, mode core.SecretMode) (*core.ConnectionBundle, error) {
+	var params []*core.ConnectionParams
+	for _, id := range connIDs {
+		c, ok := h.lookupConnection[id]
+		if !ok {
+			return nil, fmt.Errorf("unknown connection with id: %q", id)
+		}
+		params = append(params, c.GetParams())
+	}
+
+	bundle, err := core.ExportConnections(params, mode)
+	if err != nil {
+		return nil, fmt.Errorf("core.ExportConnections: %w", err)
+	}
+	if err := bundle.Write(path); err != nil {
+		return nil, fmt.Errorf("bundle.Write: %w", err)
+	}
+
+	return bundle, nil
+}
+
+// ReadConnectionBundle reads exported connections and returns the secrets
+// which have to be provided to import them.
+func (h *Handler) ReadConnectionBundle(path string) (*core.ConnectionBundle, []*core.SecretRef, error) {
+	bundle, err := core.ReadConnectionBundle(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("core.ReadConnectionBundle: %w", err)
+	}
+
+	return bundle, bundle.MissingSecrets(), nil
+}
+
+// ImportConnections returns exported connections with secrets filled in by
+// name. The connections are added to a source by the caller.
+func (h *Handler) ImportConnections(path string, secrets map[string]string) ([]*core.ConnectionParams, error) {
+	bundle, err := core.ReadConnectionBundle(path)
+	if err != nil {
+		return nil, fmt.Errorf("core.ReadConnectionBundle: %w", err)
+	}
+
+	return bundle.FillSecrets(secrets), nil
+}
+
+// ConnectionCallProcedure calls a stored procedure on connection. Unless confirmed,
+// calls on guarded connections fail with core.ErrConfirmationRequired.
+func (h *Handler) ConnectionCallProcedure(connID core.ConnectionID, name string, params []*core.ProcedureParam, confirmed bool) (*core.Call, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	var call *core.Call
+	if confirmed {
+		call = c.CallProcedureConfirmed(name, params, h.callStateHandler(c))
+	} else {
+		call = c.CallProcedure(name, params, h.callStateHandler(c))
+	}
+
+	h.addCall(connID, call)
+
+	return call, nil
+}
+
+func (h *Handler) ConnectionBeginTransaction(connID core.ConnectionID) error {
 	c, ok := h.lookupConnection[connID]
 	if !ok {
 		return fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	err := c.SelectDatabase(database)
+	err := c.BeginTransaction()
 	if err != nil {
-		return fmt.Errorf("c.SelectDatabase: %w", err)
+		return fmt.Errorf("c.BeginTransaction: %w", err)
 	}
-	h.events.DatabaseSelected(connID, database)
 
 	return nil
 }
 
-func (h *Handler) CallCancel(callID core.CallID) error {
-	call, ok := h.lookupCall[callID]
+func (h *Handler) ConnectionCommitTransaction(connID core.ConnectionID) error {
+	c, ok := h.lookupConnection[connID]
 	if !ok {
-		return fmt.Errorf("unknown call with id: %q", callID)
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	err := c.CommitTransaction()
+	if err != nil {
+		return fmt.Errorf("c.CommitTransaction: %w", err)
 	}
 
-	call.Cancel()
 	return nil
 }
 
-func (h *Handler) CallDisplayResult(callID core.CallID, buffer nvim.Buffer, from, to int) (int, error) {
-	call, ok := h.lookupCall[callID]
+func (h *Handler) ConnectionRollbackTransaction(connID core.ConnectionID) error {
+	c, ok := h.lookupConnection[connID]
 	if !ok {
-		return 0, fmt.Errorf("unknown call with id: %q", callID)
+		return fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	res, err := call.GetResult()
+	err := c.RollbackTransaction()
 	if err != nil {
-		return 0, fmt.Errorf("call.GetResult: %w", err)
+		return fmt.Errorf("c.RollbackTransaction: %w", err)
 	}
 
-	text, err := res.Format(newTable(), from, to)
-	if err != nil {
-		return 0, fmt.Errorf("res.Format: %w", err)
+	return nil
+}
+
+// ConnectionSetAutoCommit turns autocommit on or off for the connection.
+func (h *Handler) ConnectionSetAutoCommit(connID core.ConnectionID, enabled bool) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	_, err = newBuffer(h.vim, buffer).Write(text)
+	err := c.SetAutoCommit(enabled)
 	if err != nil {
-		return 0, fmt.Errorf("buffer.Write: %w", err)
+		return fmt.Errorf("c.SetAutoCommit: %w", err)
 	}
 
-	return res.Len(), nil
+	return nil
 }
 
-func (h *Handler) CallStoreResult(callID core.CallID, fmat, out string, from, to int, arg ...any) error {
-	stat, ok := h.lookupCall[callID]
+func (h *Handler) ConnectionSavepoint(connID core.ConnectionID, name string) error {
+	c, ok := h.lookupConnection[connID]
 	if !ok {
-		return fmt.Errorf("unknown call with id: %q", callID)
+		return fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	var formatter core.Formatter
-	switch fmat {
-	case "json":
-		formatter = format.NewJSON()
-	case "csv":
-		formatter = format.NewCSV()
-	case "table":
-		formatter = newTable()
-	default:
-		return fmt.Errorf("store output: %q is not supported", fmat)
+	err := c.Savepoint(name)
+	if err != nil {
+		return fmt.Errorf("c.Savepoint: %w", err)
 	}
 
-	writer, cleanup, err := h.getStoreWriter(out, arg...)
-	if err != nil {
-		return err
+	return nil
+}
+
+func (h *Handler) ConnectionRollbackToSavepoint(connID core.ConnectionID, name string) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
 	}
-	defer cleanup()
 
-	res, err := stat.GetResult()
+	err := c.RollbackToSavepoint(name)
 	if err != nil {
-		return fmt.Errorf("stat.GetResult: %w", err)
+		return fmt.Errorf("c.RollbackToSavepoint: %w", err)
 	}
 
-	text, err := res.Format(formatter, from, to)
-	if err != nil {
-		return fmt.Errorf("res.Format: %w", err)
+	return nil
+}
+
+func (h *Handler) ConnectionGetSavepoints(connID core.ConnectionID) ([]string, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	return c.GetSavepoints(), nil
+}
+
+// ConnectionExplain returns a structured plan of the query.
+func (h *Handler) ConnectionExplain(connID core.ConnectionID, query string, analyze bool) (*core.PlanNode, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	_, err = writer.Write(text)
+	plan, err := c.Explain(query, analyze)
 	if err != nil {
-		return fmt.Errorf("buffer.Write: %w", err)
+		return nil, fmt.Errorf("c.Explain: %w", err)
 	}
 
-	return nil
+	return plan, nil
+}
+
+// ConnectionGetStatementAt returns the statement at byte offset of text,
+// using the connection's dialect to find statement boundaries.
+func (h *Handler) ConnectionGetStatementAt(connID core.ConnectionID, text string, offset int) (*core.StatementRange, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	return c.StatementAt(text, offset), nil
+}
+
+// ConnectionFormat formats statements in text using the connection's dialect.
+func (h *Handler) ConnectionFormat(connID core.ConnectionID, text string, opts *core.FormatOptions) (string, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return "", fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	return c.FormatStatements(text, opts), nil
+}
+
+// ConnectionLint returns problems of statements in text.
+func (h *Handler) ConnectionLint(connID core.ConnectionID, text string) ([]*core.LintDiagnostic, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	return c.Lint(text), nil
+}
+
+// ConnectionSplitStatements splits the text into statements using the connection's dialect.
+func (h *Handler) ConnectionSplitStatements(connID core.ConnectionID, text string) ([]*core.StatementRange, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	return c.SplitStatements(text), nil
+}
+
+// ConnectionGetActivity lists active sessions on the server as a new call.
+func (h *Handler) ConnectionGetActivity(connID core.ConnectionID) (*core.Call, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	call := c.GetActivity(h.callStateHandler(c))
+
+	h.addCall(connID, call)
+
+	return call, nil
+}
+
+// ConnectionSubscribe subscribes to the channel of the connection as a new
+// call, which appends a row for every event until it's canceled.
+func (h *Handler) ConnectionSubscribe(connID core.ConnectionID, channel string) (*core.Call, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	call := c.Subscribe(channel, h.callStateHandler(c))
+
+	h.addCall(connID, call)
+
+	return call, nil
+}
+
+func (h *Handler) ConnectionKillSession(connID core.ConnectionID, sessionID string) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	err := c.KillSession(sessionID)
+	if err != nil {
+		return fmt.Errorf("c.KillSession: %w", err)
+	}
+
+	return nil
+}
+
+func (h *Handler) ConnectionRefreshMaterializedView(connID core.ConnectionID, opts *core.TableOptions) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	err := c.RefreshMaterializedView(opts)
+	if err != nil {
+		return fmt.Errorf("c.RefreshMaterializedView: %w", err)
+	}
+
+	return nil
+}
+
+func (h *Handler) ConnectionGetPartitions(connID core.ConnectionID, opts *core.TableOptions) ([]*core.Partition, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	partitions, err := c.GetPartitions(opts)
+	if err != nil {
+		return nil, fmt.Errorf("c.GetPartitions: %w", err)
+	}
+
+	return partitions, nil
+}
+
+func (h *Handler) ConnectionDetachPartition(connID core.ConnectionID, opts *core.TableOptions, partition *core.Partition, confirmation string) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	err := c.DetachPartition(opts, partition, confirmation)
+	if err != nil {
+		return fmt.Errorf("c.DetachPartition: %w", err)
+	}
+
+	return nil
+}
+
+func (h *Handler) ConnectionDropPartition(connID core.ConnectionID, opts *core.TableOptions, partition *core.Partition, confirmation string) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	err := c.DropPartition(opts, partition, confirmation)
+	if err != nil {
+		return fmt.Errorf("c.DropPartition: %w", err)
+	}
+
+	return nil
+}
+
+func (h *Handler) ConnectionGetObjectActions(connID core.ConnectionID, opts *core.TableOptions) ([]*core.ObjectAction, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	actions, err := c.GetObjectActions(opts)
+	if err != nil {
+		return nil, fmt.Errorf("c.GetObjectActions: %w", err)
+	}
+
+	return actions, nil
+}
+
+// ConnectionRunObjectAction runs the object action on connection. Calls of
+// actions which run a query are tracked like regular calls.
+func (h *Handler) ConnectionRunObjectAction(connID core.ConnectionID, opts *core.TableOptions, actionID, confirmation string) (*core.ObjectActionResult, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	result, err := c.RunObjectAction(opts, actionID, confirmation, h.callStateHandler(c))
+	if err != nil {
+		return nil, fmt.Errorf("c.RunObjectAction: %w", err)
+	}
+	if result.Call != nil {
+		h.addCall(connID, result.Call)
+	}
+
+	return result, nil
+}
+
+func (h *Handler) ConnectionGetDependencies(connID core.ConnectionID, opts *core.TableOptions) (*core.Dependencies, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	deps, err := c.GetDependencies(opts)
+	if err != nil {
+		return nil, fmt.Errorf("c.GetDependencies: %w", err)
+	}
+
+	return deps, nil
+}
+
+func (h *Handler) ConnectionSetComment(connID core.ConnectionID, opts *core.TableOptions, column string, comment string) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	err := c.SetComment(opts, column, comment)
+	if err != nil {
+		return fmt.Errorf("c.SetComment: %w", err)
+	}
+
+	return nil
+}
+
+func (h *Handler) ConnectionGetScaffolds(connID core.ConnectionID, opts *core.TableOptions) ([]*core.Scaffold, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	scaffolds, err := c.GetScaffolds(opts)
+	if err != nil {
+		return nil, fmt.Errorf("c.GetScaffolds: %w", err)
+	}
+
+	return scaffolds, nil
+}
+
+// SetLogOptions configures the log of the backend (levels, format and rotation).
+func (h *Handler) SetLogOptions(opts *plugin.LogOptions) error {
+	return h.log.SetOptions(opts)
+}
+
+// GetLogs returns recent entries of the log of the backend.
+func (h *Handler) GetLogs(filter *plugin.LogFilter) []*plugin.LogEntry {
+	return h.log.Entries(filter)
+}
+
+// callStateHandler returns a handler for state changes of calls executed on connections.
+func (h *Handler) callStateHandler(connections ...*core.Connection) func(core.CallState, *core.Call) {
+	return func(state core.CallState, c *core.Call) {
+		var connID core.ConnectionID
+		if len(connections) == 1 {
+			connID = connections[0].GetID()
+		}
+
+		if err := c.Err(); err != nil {
+			h.log.Log(slog.LevelError, "call failed", "call_id", c.GetID(), "connection", connID, "state", state.String(), "error", err.Error())
+		} else {
+			h.log.Log(slog.LevelDebug, "call state changed", "call_id", c.GetID(), "connection", connID, "state", state.String())
+		}
+
+		h.events.CallStateChanged(c)
+
+		switch state {
+		case core.CallStateExecuting:
+			if status, ok := h.queries.start(c); ok {
+				h.events.QueryStarted(connID, c, status)
+			}
+		case core.CallStateArchived,
+			core.CallStateArchiveFailed,
+			core.CallStateExecutingFailed,
+			core.CallStateRetrievingFailed,
+			core.CallStateCanceled:
+			h.finishing.Add(1)
+			defer h.finishing.Done()
+
+			h.auditCall(c, connections)
+			h.journalCall(connID, c)
+			h.metrics.observe(c, state, connections)
+			h.recordSlowQuery(c, connections)
+			h.recordFailedQuery(c, state, connections)
+			h.notifyCall(c, connections)
+			h.events.QueryFinished(connID, c, state, h.queries.finish(c))
+		}
+	}
+}
+
+// addCall adds call to lookups and makes its connection the current one.
+func (h *Handler) addCall(connID core.ConnectionID, call *core.Call) {
+	id := call.GetID()
+
+	// add to lookup
+	h.lookupCall[id] = call
+	h.lookupConnectionCall[connID] = append(h.lookupConnectionCall[connID], id)
+	h.events.CallLogChanged(connID)
+
+	// update current call and conn
+	_ = h.SetCurrentConnection(connID)
+}
+
+// callValueDisplay returns the display of values of the call's connection.
+// Values of calls whose connection was removed are displayed as they are.
+func (h *Handler) callValueDisplay(callID core.CallID) *core.ValueDisplay {
+	for connID, callIDs := range h.lookupConnectionCall {
+		if !slices.Contains(callIDs, callID) {
+			continue
+		}
+		if conn, ok := h.lookupConnection[connID]; ok {
+			return conn.GetValueDisplay()
+		}
+	}
+	return nil
+}
+
+func (h *Handler) ConnectionGetCalls(connID core.ConnectionID) ([]*core.Call, error) {
+	_, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	var calls []*core.Call
+	callIDs, ok := h.lookupConnectionCall[connID]
+	if !ok {
+		return calls, nil
+	}
+	for _, cID := range callIDs {
+		c, ok := h.lookupCall[cID]
+		if !ok {
+			continue
+		}
+		calls = append(calls, c)
+	}
+
+	return calls, nil
+}
+
+// ConnectionGetQueryStats returns the call log of the connection grouped by
+// query fingerprints, so repeated calls of a query with different values are
+// a single entry.
+func (h *Handler) ConnectionGetQueryStats(connID core.ConnectionID, opts *core.QueryStatsOptions) ([]*core.QueryStats, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	calls, err := h.ConnectionGetCalls(connID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.GetQueryStats(calls, opts), nil
+}
+
+func (h *Handler) ConnectionGetParams(connID core.ConnectionID) (*core.ConnectionParams, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	return c.GetParams(), nil
+}
+
+func (h *Handler) ConnectionGetStructure(connID core.ConnectionID, opts *core.StructureOptions) ([]*core.Structure, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	layout, err := c.GetStructureWithOptions(opts)
+	if err != nil {
+		return nil, fmt.Errorf("c.GetStructureWithOptions: %w", err)
+	}
+
+	return layout, nil
+}
+
+func (h *Handler) ConnectionRefreshStructure(connID core.ConnectionID, node *core.Structure) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	c.RefreshStructure(node)
+
+	// load the structure again in the background and announce when it's ready
+	go func() {
+		var err error
+		schema := ""
+		if node != nil {
+			schema = node.Schema
+			_, err = c.GetStructureChildren(node)
+		}
+		// the whole structure is refreshed if nodes can't be loaded lazily
+		if node == nil || errors.Is(err, core.ErrLazyStructureNotSupported) {
+			schema = ""
+			_, err = c.GetStructureWithOptions(nil)
+		}
+		h.events.StructureRefreshed(connID, schema, err)
+	}()
+
+	return nil
+}
+
+func (h *Handler) ConnectionGetStructureChildren(connID core.ConnectionID, parent *core.Structure) ([]*core.Structure, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	children, err := c.GetStructureChildren(parent)
+	if err != nil {
+		return nil, fmt.Errorf("c.GetStructureChildren: %w", err)
+	}
+
+	return children, nil
+}
+
+func (h *Handler) ConnectionSearchObjects(connID core.ConnectionID, pattern string, limit int) ([]*core.ObjectMatch, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	matches, err := c.SearchObjects(pattern, limit)
+	if err != nil {
+		return nil, fmt.Errorf("c.SearchObjects: %w", err)
+	}
+
+	return matches, nil
+}
+
+func (h *Handler) ConnectionGetColumns(connID core.ConnectionID, opts *core.TableOptions) ([]*core.Column, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	columns, err := c.GetColumns(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return columns, nil
+}
+
+func (h *Handler) ConnectionGetIndexes(connID core.ConnectionID, opts *core.TableOptions) ([]*core.Index, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	indexes, err := c.GetIndexes(opts)
+	if err != nil {
+		return nil, fmt.Errorf("c.GetIndexes: %w", err)
+	}
+
+	return indexes, nil
+}
+
+func (h *Handler) ConnectionGetConstraints(connID core.ConnectionID, opts *core.TableOptions) ([]*core.Constraint, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	constraints, err := c.GetConstraints(opts)
+	if err != nil {
+		return nil, fmt.Errorf("c.GetConstraints: %w", err)
+	}
+
+	return constraints, nil
+}
+
+func (h *Handler) ConnectionGetTriggers(connID core.ConnectionID, opts *core.TableOptions) ([]*core.Trigger, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	triggers, err := c.GetTriggers(opts)
+	if err != nil {
+		return nil, fmt.Errorf("c.GetTriggers: %w", err)
+	}
+
+	return triggers, nil
+}
+
+func (h *Handler) ConnectionGetTableStats(connID core.ConnectionID) ([]*core.TableStats, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	stats, err := c.GetTableStats()
+	if err != nil {
+		return nil, fmt.Errorf("c.GetTableStats: %w", err)
+	}
+
+	return stats, nil
+}
+
+func (h *Handler) ConnectionGetServerInfo(connID core.ConnectionID) (*core.ServerInfo, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	info, err := c.GetServerInfo()
+	if err != nil {
+		return nil, fmt.Errorf("c.GetServerInfo: %w", err)
+	}
+
+	return info, nil
+}
+
+func (h *Handler) ConnectionGetRoles(connID core.ConnectionID) ([]*core.Role, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	roles, err := c.GetRoles()
+	if err != nil {
+		return nil, fmt.Errorf("c.GetRoles: %w", err)
+	}
+
+	return roles, nil
+}
+
+func (h *Handler) ConnectionGetForeignServers(connID core.ConnectionID) ([]*core.ForeignServer, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	servers, err := c.GetForeignServers()
+	if err != nil {
+		return nil, fmt.Errorf("c.GetForeignServers: %w", err)
+	}
+
+	return servers, nil
+}
+
+func (h *Handler) ConnectionGetForeignObjects(connID core.ConnectionID, server string) ([]*core.Structure, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	objects, err := c.GetForeignObjects(server)
+	if err != nil {
+		return nil, fmt.Errorf("c.GetForeignObjects: %w", err)
+	}
+
+	return objects, nil
+}
+
+func (h *Handler) ConnectionGetGrants(connID core.ConnectionID, opts *core.TableOptions) ([]*core.Grant, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	grants, err := c.GetGrants(opts)
+	if err != nil {
+		return nil, fmt.Errorf("c.GetGrants: %w", err)
+	}
+
+	return grants, nil
+}
+
+func (h *Handler) ConnectionGetDefinition(connID core.ConnectionID, opts *core.TableOptions) (string, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return "", fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	definition, err := c.GetDefinition(opts)
+	if err != nil {
+		return "", fmt.Errorf("c.GetDefinition: %w", err)
+	}
+
+	return definition, nil
+}
+
+func (h *Handler) ConnectionListDatabases(connID core.ConnectionID) (current string, available []string, err error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	currentDB, availableDBs, err := c.ListDatabases()
+	if err != nil {
+		if errors.Is(err, core.ErrDatabaseSwitchingNotSupported) {
+			return "", []string{}, nil
+		}
+		return "", nil, fmt.Errorf("c.ListDatabases: %w", err)
+	}
+
+	return currentDB, availableDBs, nil
+}
+
+func (h *Handler) ConnectionSelectDatabase(connID core.ConnectionID, database string) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	err := c.SelectDatabase(database)
+	if err != nil {
+		return fmt.Errorf("c.SelectDatabase: %w", err)
+	}
+	h.events.DatabaseSelected(connID, database)
+
+	return nil
+}
+
+// ConnectionGetMigrations returns migrations of the connection's migrations
+// directory, or of the project root if it has none, with their status.
+func (h *Handler) ConnectionGetMigrations(connID core.ConnectionID, root string) (*core.MigrationStatus, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	status, err := c.GetMigrations(root)
+	if err != nil {
+		return nil, fmt.Errorf("c.GetMigrations: %w", err)
+	}
+
+	return status, nil
+}
+
+// ConnectionApplyMigration applies the migration and refreshes the structure
+// of the connection.
+func (h *Handler) ConnectionApplyMigration(connID core.ConnectionID, root, version string) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	err := c.ApplyMigration(root, version)
+	if err != nil {
+		return fmt.Errorf("c.ApplyMigration: %w", err)
+	}
+
+	return h.ConnectionRefreshStructure(connID, nil)
+}
+
+// ConnectionRollbackMigration rolls the migration back and refreshes the
+// structure of the connection.
+func (h *Handler) ConnectionRollbackMigration(connID core.ConnectionID, root, version string) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	err := c.RollbackMigration(root, version)
+	if err != nil {
+		return fmt.Errorf("c.RollbackMigration: %w", err)
+	}
+
+	return h.ConnectionRefreshStructure(connID, nil)
+}
+
+// GetCall returns the call with the provided id.
+func (h *Handler) GetCall(callID core.CallID) (*core.Call, error) {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return nil, fmt.Errorf("unknown call with id: %q", callID)
+	}
+	return call, nil
+}
+
+func (h *Handler) CallCancel(callID core.CallID) error {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	call.Cancel()
+	return nil
+}
+
+// CallGetMeta returns the metadata of the call's n-th result set.
+func (h *Handler) CallGetMeta(callID core.CallID, set int) (*core.Meta, error) {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return nil, fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	res, err := call.GetResultSet(set)
+	if err != nil {
+		return nil, fmt.Errorf("call.GetResultSet: %w", err)
+	}
+
+	meta := res.Meta()
+	if meta == nil {
+		return nil, nil
+	}
+
+	// formatting happens after the result is archived, so it's not in the stored meta
+	withFormat := *meta
+	withFormat.Metrics.Format = res.FormatTime()
+
+	return &withFormat, nil
+}
+
+func (h *Handler) CallDisplayResult(callID core.CallID, set int, buffer nvim.Buffer, from, to int) (int, error) {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return 0, fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	res, err := call.GetResultSet(set)
+	if err != nil {
+		return 0, fmt.Errorf("call.GetResultSet: %w", err)
+	}
+
+	text, err := res.Format(newTable(h.ambiguousWide, h.callValueDisplay(callID)), from, to)
+	if err != nil {
+		return 0, fmt.Errorf("res.Format: %w", err)
+	}
+
+	_, err = newBuffer(h.vim, buffer).Write(text)
+	if err != nil {
+		return 0, fmt.Errorf("buffer.Write: %w", err)
+	}
+
+	return res.Len(), nil
+}
+
+// CallGetTableLayout returns the layout of the table which CallDisplayResult
+// renders for the same range of rows.
+func (h *Handler) CallGetTableLayout(callID core.CallID, set int, from, to int) (*TableLayout, error) {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return nil, fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	res, err := call.GetResultSet(set)
+	if err != nil {
+		return nil, fmt.Errorf("call.GetResultSet: %w", err)
+	}
+
+	recorder := &tableLayoutRecorder{table: newTable(h.ambiguousWide, h.callValueDisplay(callID))}
+	_, err = res.Format(recorder, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("res.Format: %w", err)
+	}
+
+	return recorder.layout, nil
+}
+
+// CallGetChecksum returns the checksum of the call's n-th result set.
+func (h *Handler) CallGetChecksum(callID core.CallID, set int, ignoreOrder bool) (string, error) {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return "", fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	res, err := call.GetResultSet(set)
+	if err != nil {
+		return "", fmt.Errorf("call.GetResultSet: %w", err)
+	}
+
+	rows, err := res.Rows(0, -1)
+	if err != nil {
+		return "", fmt.Errorf("res.Rows: %w", err)
+	}
+
+	return core.ResultChecksum(res.Header(), rows, ignoreOrder), nil
+}
+
+// CallCompareResult compares the call's n-th result set with the one of the
+// baseline call (e.g. a call of the original query from history).
+func (h *Handler) CallCompareResult(callID, baselineID core.CallID, set int, opts *core.ResultCompareOptions) (*core.ResultComparison, error) {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return nil, fmt.Errorf("unknown call with id: %q", callID)
+	}
+	baselineCall, ok := h.lookupCall[baselineID]
+	if !ok {
+		return nil, fmt.Errorf("unknown call with id: %q", baselineID)
+	}
+
+	res, err := call.GetResultSet(set)
+	if err != nil {
+		return nil, fmt.Errorf("call.GetResultSet: %w", err)
+	}
+	baseline, err := baselineCall.GetResultSet(set)
+	if err != nil {
+		return nil, fmt.Errorf("baselineCall.GetResultSet: %w", err)
+	}
+
+	cmp, err := core.CompareResults(baseline, res, opts)
+	if err != nil {
+		return nil, fmt.Errorf("core.CompareResults: %w", err)
+	}
+
+	return cmp, nil
+}
+
+// CallGetJSONColumns returns names of columns of the result which hold json
+// documents (see core.JSONColumns).
+func (h *Handler) CallGetJSONColumns(callID core.CallID, set int) ([]string, error) {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return nil, fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	res, err := call.GetResultSet(set)
+	if err != nil {
+		return nil, fmt.Errorf("call.GetResultSet: %w", err)
+	}
+
+	columns, err := core.JSONColumns(res)
+	if err != nil {
+		return nil, fmt.Errorf("core.JSONColumns: %w", err)
+	}
+
+	return columns, nil
+}
+
+// CallFormatJSONCell pretty prints the json document in the cell of the result.
+func (h *Handler) CallFormatJSONCell(callID core.CallID, set, row, column int) (string, error) {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return "", fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	res, err := call.GetResultSet(set)
+	if err != nil {
+		return "", fmt.Errorf("call.GetResultSet: %w", err)
+	}
+	rows, err := res.Rows(row, row+1)
+	if err != nil {
+		return "", fmt.Errorf("res.Rows: %w", err)
+	}
+	if len(rows) < 1 || column < 0 || column >= len(rows[0]) {
+		return "", fmt.Errorf("no cell at row %d, column %d", row, column)
+	}
+
+	formatted, err := core.FormatJSONValue(rows[0][column])
+	if err != nil {
+		return "", fmt.Errorf("core.FormatJSONValue: %w", err)
+	}
+
+	return formatted, nil
+}
+
+// CallFlattenJSON creates a call of the connection of the source call whose
+// result has values at paths of a json column as additional columns (see
+// core.FlattenJSON).
+func (h *Handler) CallFlattenJSON(callID core.CallID, set int, opts *core.JSONFlattenOptions) (*core.Call, error) {
+	source, ok := h.lookupCall[callID]
+	if !ok {
+		return nil, fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	var conn *core.Connection
+	for connID, callIDs := range h.lookupConnectionCall {
+		if slices.Contains(callIDs, callID) {
+			conn = h.lookupConnection[connID]
+			break
+		}
+	}
+	if conn == nil {
+		return nil, fmt.Errorf("connection of call %q doesn't exist anymore", callID)
+	}
+
+	call, err := core.FlattenJSON(source, set, opts, h.callStateHandler(conn))
+	if err != nil {
+		return nil, fmt.Errorf("core.FlattenJSON: %w", err)
+	}
+
+	h.addCall(conn.GetID(), call)
+
+	return call, nil
+}
+
+// ConnectionGetJSONPath returns the expression which extracts the value at the
+// path of the json column in the dialect of the connection.
+func (h *Handler) ConnectionGetJSONPath(connID core.ConnectionID, column, path string) (string, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return "", fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	expr, err := c.JSONPathExpression(column, path)
+	if err != nil {
+		return "", fmt.Errorf("c.JSONPathExpression: %w", err)
+	}
+
+	return expr, nil
+}
+
+// CallExplainError returns hints how to fix the error of a failed call (see
+// core.Connection.ExplainError).
+func (h *Handler) CallExplainError(callID core.CallID) ([]*core.ErrorHint, error) {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return nil, fmt.Errorf("unknown call with id: %q", callID)
+	}
+	if call.Err() == nil {
+		return nil, fmt.Errorf("call did not fail: %q", callID)
+	}
+
+	for connID, callIDs := range h.lookupConnectionCall {
+		if !slices.Contains(callIDs, callID) {
+			continue
+		}
+		if conn, ok := h.lookupConnection[connID]; ok {
+			return conn.ExplainError(call.Err(), call.GetQuery()), nil
+		}
+	}
+
+	return nil, fmt.Errorf("connection of call %q is closed", callID)
+}
+
+// SetAmbiguousWidth sets whether characters of ambiguous width (e.g. some
+// greek and cyrillic letters in east asian fonts) are two cells wide in
+// result tables, as with 'ambiwidth' set to "double".
+func (h *Handler) SetAmbiguousWidth(double bool) {
+	h.ambiguousWide = double
+}
+
+func (h *Handler) CallStoreResult(callID core.CallID, set int, fmat, out string, from, to int, arg ...any) error {
+	stat, ok := h.lookupCall[callID]
+	if !ok {
+		return fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	_, ctx, done := h.exports.start()
+	defer done()
+
+	formatter, err := h.storeFormatter(stat, fmat)
+	if err != nil {
+		return err
+	}
+
+	writer, cleanup, err := h.getStoreWriter(out, arg...)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	res, err := stat.GetResultSet(set)
+	if err != nil {
+		return fmt.Errorf("stat.GetResultSet: %w", err)
+	}
+
+	text, err := res.Format(formatter, from, to)
+	if err != nil {
+		return fmt.Errorf("res.Format: %w", err)
+	}
+
+	_, err = (&ctxWriter{ctx: ctx, w: writer}).Write(text)
+	if err != nil {
+		return fmt.Errorf("buffer.Write: %w", err)
+	}
+
+	return nil
+}
+
+// CallExport starts writing a result set of the call to a file in the
+// background and returns the id of the export. Progress is reported with
+// "export_progress" events and the end of the export (including its error)
+// with an "export_finished" event. The file is replaced only if the export
+// succeeds.
+func (h *Handler) CallExport(callID core.CallID, set int, fmat, path string, from, to int) (int, error) {
+	stat, ok := h.lookupCall[callID]
+	if !ok {
+		return 0, fmt.Errorf("unknown call with id: %q", callID)
+	}
+	if path == "" {
+		return 0, errors.New("no output path provided")
+	}
+
+	formatter, err := h.storeFormatter(stat, fmat)
+	if err != nil {
+		return 0, err
+	}
+
+	id, ctx, done := h.exports.start()
+	go func() {
+		defer done()
+
+		var last time.Time
+		err := exportFile(ctx, stat, set, formatter, path, from, to, func(p *core.ExportProgress) {
+			if time.Since(last) < exportProgressInterval && p.Rows < p.RowsTotal {
+				return
+			}
+			last = time.Now()
+			h.events.ExportProgress(id, callID, p)
+		})
+		h.events.ExportFinished(id, callID, path, err)
+	}()
+
+	return id, nil
+}
+
+// ExportCancel cancels an export started with CallExport.
+func (h *Handler) ExportCancel(id int) error {
+	return h.exports.cancel(id)
+}
+
+// exportFile writes the result set to a temporary file next to path, which
+// replaces path when the export is done.
+func exportFile(ctx context.Context, call *core.Call, set int, formatter core.Formatter, path string, from, to int, progress func(*core.ExportProgress)) error {
+	res, err := call.GetResultSet(set)
+	if err != nil {
+		return fmt.Errorf("call.GetResultSet: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.part")
+	if err != nil {
+		return fmt.Errorf("os.CreateTemp: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	w := bufio.NewWriter(file)
+	err = res.Export(ctx, w, formatter, from, to, progress)
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("os.Rename: %w", err)
+	}
+	return nil
+}
+
+// storeFormatter returns the formatter of a store or export format of the
+// call's results. INSERT statements are written in the dialect of the call's
+// connection into the table the call's query selects from ("result" if the
+// query doesn't select from a single table).
+func (h *Handler) storeFormatter(call *core.Call, fmat string) (core.Formatter, error) {
+	switch fmat {
+	case "insert":
+		dialect := core.DefaultDialect
+		if conn := h.callConnection(call.GetID()); conn != nil {
+			dialect = conn.Dialect()
+		}
+		table, ok := dialect.QueryTable(call.GetQuery())
+		if !ok {
+			table = dialect.QuoteIdentifier("result")
+		}
+		return format.NewInsert(dialect, table), nil
+	case "json":
+		return format.NewJSON(), nil
+	case "csv":
+		return format.NewCSV(), nil
+	case "geojson":
+		return format.NewGeoJSON(), nil
+	case "table":
+		return newTable(false, nil), nil
+	}
+	return nil, fmt.Errorf("store output: %q is not supported", fmat)
 }
 
 func (h *Handler) getStoreWriter(output string, arg ...any) (writer io.Writer, cleanup func(), err error) {
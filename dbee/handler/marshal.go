@@ -1,9 +1,16 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/neovim/go-client/msgpack"
 
+	"github.com/kndndrj/nvim-dbee/dbee/adapters"
 	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/plugin"
 )
 
 // callWrap is a wrapper around core.Call with msgpack marshaling capabilities
@@ -35,17 +42,25 @@ func (cw *callWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
 	}
 
 	errMsg := ""
+	var errInfo *queryErrorWrap
 	if err := cw.call.Err(); err != nil {
 		errMsg = err.Error()
+
+		var qErr *core.QueryError
+		if errors.As(err, &qErr) {
+			errInfo = &queryErrorWrap{qErr}
+		}
 	}
 
 	return enc.Encode(&struct {
-		ID        string `msgpack:"id"`
-		Query     string `msgpack:"query"`
-		State     string `msgpack:"state"`
-		TimeTaken int64  `msgpack:"time_taken_us"`
-		Timestamp int64  `msgpack:"timestamp_us"`
-		Error     string `msgpack:"error,omitempty"`
+		ID        string          `msgpack:"id"`
+		Query     string          `msgpack:"query"`
+		State     string          `msgpack:"state"`
+		TimeTaken int64           `msgpack:"time_taken_us"`
+		Timestamp int64           `msgpack:"timestamp_us"`
+		Error     string          `msgpack:"error,omitempty"`
+		ErrorInfo *queryErrorWrap `msgpack:"error_info,omitempty"`
+		Sets      int             `msgpack:"result_sets"`
 	}{
 		ID:        string(cw.call.GetID()),
 		Query:     cw.call.GetQuery(),
@@ -53,6 +68,72 @@ func (cw *callWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
 		TimeTaken: cw.call.GetTimeTaken().Microseconds(),
 		Timestamp: cw.call.GetTimestamp().UnixMicro(),
 		Error:     errMsg,
+		ErrorInfo: errInfo,
+		Sets:      cw.call.ResultSetCount(),
+	})
+}
+
+// queryErrorWrap is a wrapper around core.QueryError with msgpack marshaling capabilities
+type queryErrorWrap struct {
+	err *core.QueryError
+}
+
+func (qw *queryErrorWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if qw.err == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Category string `msgpack:"category"`
+		Code     string `msgpack:"code"`
+		Position int    `msgpack:"position"`
+		Line     int    `msgpack:"line"`
+		Column   int    `msgpack:"column"`
+		Hint     string `msgpack:"hint"`
+
+		Detail     string `msgpack:"detail"`
+		ObjectKind string `msgpack:"object_kind"`
+		Object     string `msgpack:"object"`
+	}{
+		Category: string(qw.err.Category),
+		Code:     qw.err.Code,
+		Position: qw.err.Position,
+		Line:     qw.err.Line,
+		Column:   qw.err.Column,
+		Hint:     qw.err.Hint,
+
+		Detail:     qw.err.Detail,
+		ObjectKind: qw.err.ObjectKind,
+		Object:     qw.err.Object,
+	})
+}
+
+// errorHintWrap is a wrapper around core.ErrorHint with msgpack marshaling capabilities
+type errorHintWrap struct {
+	hint *core.ErrorHint
+}
+
+func WrapErrorHints(hints []*core.ErrorHint) []*errorHintWrap {
+	wraps := make([]*errorHintWrap, len(hints))
+	for i := range hints {
+		wraps[i] = &errorHintWrap{hint: hints[i]}
+	}
+	return wraps
+}
+
+func (hw *errorHintWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if hw.hint == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Kind       string   `msgpack:"kind"`
+		Message    string   `msgpack:"message"`
+		Candidates []string `msgpack:"candidates"`
+		Query      string   `msgpack:"query"`
+	}{
+		Kind:       string(hw.hint.Kind),
+		Message:    hw.hint.Message,
+		Candidates: hw.hint.Candidates,
+		Query:      hw.hint.Query,
 	})
 }
 
@@ -84,15 +165,176 @@ func (cw *connectionWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
 		return enc.Encode(nil)
 	}
 	return enc.Encode(&struct {
-		ID   string `msgpack:"id"`
-		Name string `msgpack:"name"`
-		Type string `msgpack:"type"`
-		URL  string `msgpack:"url"`
+		ID           string `msgpack:"id"`
+		Name         string `msgpack:"name"`
+		Type         string `msgpack:"type"`
+		URL          string `msgpack:"url"`
+		Guarded      bool   `msgpack:"guarded"`
+		AutoLimit    int    `msgpack:"auto_limit"`
+		Retries      int    `msgpack:"retries"`
+		StructureTTL int    `msgpack:"structure_ttl"`
+
+		HiddenDatabases []string        `msgpack:"hidden_databases"`
+		Preview         *previewWrap    `msgpack:"preview"`
+		Masking         []*maskRuleWrap `msgpack:"masking"`
+		Display         *displayWrap    `msgpack:"display"`
+		Limits          *limitsWrap     `msgpack:"limits"`
+		Quota           *quotaWrap      `msgpack:"quota"`
+		Cost            *costWrap       `msgpack:"cost"`
+		MigrationsDir   string          `msgpack:"migrations_dir"`
+
+		AutoCommit    bool `msgpack:"autocommit"`
+		InTransaction bool `msgpack:"in_transaction"`
+	}{
+		ID:           string(cw.connection.GetID()),
+		Name:         cw.connection.GetName(),
+		Type:         cw.connection.GetType(),
+		URL:          cw.connection.GetURL(),
+		Guarded:      cw.connection.IsGuarded(),
+		AutoLimit:    cw.connection.GetParams().AutoLimit,
+		Retries:      cw.connection.GetParams().Retries,
+		StructureTTL: cw.connection.GetParams().StructureTTL,
+
+		HiddenDatabases: cw.connection.GetParams().HiddenDatabases,
+		Preview:         &previewWrap{preview: &cw.connection.GetParams().Preview},
+		Masking:         wrapMaskRules(cw.connection.GetParams().Masking),
+		Display:         &displayWrap{display: &cw.connection.GetParams().Display},
+		Limits:          &limitsWrap{limits: &cw.connection.GetParams().Limits},
+		Quota:           &quotaWrap{quota: &cw.connection.GetParams().Quota},
+		Cost:            &costWrap{cost: &cw.connection.GetParams().Cost},
+		MigrationsDir:   cw.connection.GetParams().MigrationsDir,
+
+		AutoCommit:    cw.connection.IsAutoCommit(),
+		InTransaction: cw.connection.InTransaction(),
+	})
+}
+
+// previewWrap is a wrapper around core.PreviewOptions with msgpack marshaling capabilities
+type previewWrap struct {
+	preview *core.PreviewOptions
+}
+
+func (pw *previewWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if pw.preview == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Limit   int      `msgpack:"limit"`
+		Latest  bool     `msgpack:"latest"`
+		Columns []string `msgpack:"columns"`
+	}{
+		Limit:   pw.preview.Limit,
+		Latest:  pw.preview.Latest,
+		Columns: pw.preview.Columns,
+	})
+}
+
+// displayWrap is a wrapper around core.DisplayOptions with msgpack marshaling capabilities
+type displayWrap struct {
+	display *core.DisplayOptions
+}
+
+func (dw *displayWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if dw.display == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Timezone           string `msgpack:"timezone"`
+		TimeFormat         string `msgpack:"time_format"`
+		ThousandsSeparator string `msgpack:"thousands_separator"`
+		FloatPrecision     *int   `msgpack:"float_precision"`
+	}{
+		Timezone:           dw.display.Timezone,
+		TimeFormat:         dw.display.TimeFormat,
+		ThousandsSeparator: dw.display.ThousandsSeparator,
+		FloatPrecision:     dw.display.FloatPrecision,
+	})
+}
+
+// limitsWrap is a wrapper around core.ResourceLimits with msgpack marshaling capabilities
+type limitsWrap struct {
+	limits *core.ResourceLimits
+}
+
+func (lw *limitsWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if lw.limits == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		MaxRows         int   `msgpack:"max_rows"`
+		TruncateRows    bool  `msgpack:"truncate_rows"`
+		MaxScannedBytes int64 `msgpack:"max_scanned_bytes"`
+		MaxRuntime      int   `msgpack:"max_runtime"`
+	}{
+		MaxRows:         lw.limits.MaxRows,
+		TruncateRows:    lw.limits.TruncateRows,
+		MaxScannedBytes: lw.limits.MaxScannedBytes,
+		MaxRuntime:      lw.limits.MaxRuntime,
+	})
+}
+
+// quotaWrap is a wrapper around core.QueryQuota with msgpack marshaling capabilities
+type quotaWrap struct {
+	quota *core.QueryQuota
+}
+
+func (qw *quotaWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if qw.quota == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		MaxQueriesPerMinute int   `msgpack:"max_queries_per_minute"`
+		CooldownRows        int64 `msgpack:"cooldown_rows"`
+		Cooldown            int   `msgpack:"cooldown"`
+	}{
+		MaxQueriesPerMinute: qw.quota.MaxQueriesPerMinute,
+		CooldownRows:        qw.quota.CooldownRows,
+		Cooldown:            qw.quota.Cooldown,
+	})
+}
+
+// costWrap is a wrapper around core.CostOptions with msgpack marshaling capabilities
+type costWrap struct {
+	cost *core.CostOptions
+}
+
+func (cw *costWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if cw.cost == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		PricePerTiB float64 `msgpack:"price_per_tib"`
+		MaxCost     float64 `msgpack:"max_cost"`
+	}{
+		PricePerTiB: cw.cost.PricePerTiB,
+		MaxCost:     cw.cost.MaxCost,
+	})
+}
+
+// maskRuleWrap is a wrapper around core.MaskRule with msgpack marshaling capabilities
+type maskRuleWrap struct {
+	rule *core.MaskRule
+}
+
+func wrapMaskRules(rules []core.MaskRule) []*maskRuleWrap {
+	wraps := make([]*maskRuleWrap, len(rules))
+	for i := range rules {
+		wraps[i] = &maskRuleWrap{rule: &rules[i]}
+	}
+	return wraps
+}
+
+func (mw *maskRuleWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	return enc.Encode(&struct {
+		Column  string `msgpack:"column"`
+		Pattern string `msgpack:"pattern"`
+		Method  string `msgpack:"method"`
+		Keep    int    `msgpack:"keep"`
 	}{
-		ID:   string(cw.connection.GetID()),
-		Name: cw.connection.GetName(),
-		Type: cw.connection.GetType(),
-		URL:  cw.connection.GetURL(),
+		Column:  mw.rule.Column,
+		Pattern: mw.rule.Pattern,
+		Method:  mw.rule.Method,
+		Keep:    mw.rule.Keep,
 	})
 }
 
@@ -107,20 +349,54 @@ func WrapConnectionParams(params *core.ConnectionParams) *connectionParamsWrap {
 	}
 }
 
+func WrapConnectionParamsList(params []*core.ConnectionParams) []*connectionParamsWrap {
+	wraps := make([]*connectionParamsWrap, len(params))
+	for i, p := range params {
+		wraps[i] = WrapConnectionParams(p)
+	}
+	return wraps
+}
+
 func (cw *connectionParamsWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
 	if cw.params == nil {
 		return enc.Encode(nil)
 	}
 	return enc.Encode(&struct {
-		ID   string `msgpack:"id"`
-		Name string `msgpack:"name"`
-		Type string `msgpack:"type"`
-		URL  string `msgpack:"url"`
+		ID           string `msgpack:"id"`
+		Name         string `msgpack:"name"`
+		Type         string `msgpack:"type"`
+		URL          string `msgpack:"url"`
+		Guarded      bool   `msgpack:"guarded"`
+		AutoLimit    int    `msgpack:"auto_limit"`
+		Retries      int    `msgpack:"retries"`
+		StructureTTL int    `msgpack:"structure_ttl"`
+
+		HiddenDatabases []string        `msgpack:"hidden_databases"`
+		Preview         *previewWrap    `msgpack:"preview"`
+		Masking         []*maskRuleWrap `msgpack:"masking"`
+		Display         *displayWrap    `msgpack:"display"`
+		Limits          *limitsWrap     `msgpack:"limits"`
+		Quota           *quotaWrap      `msgpack:"quota"`
+		Cost            *costWrap       `msgpack:"cost"`
+		MigrationsDir   string          `msgpack:"migrations_dir"`
 	}{
-		ID:   string(cw.params.ID),
-		Name: cw.params.Name,
-		Type: cw.params.Type,
-		URL:  cw.params.URL,
+		ID:           string(cw.params.ID),
+		Name:         cw.params.Name,
+		Type:         cw.params.Type,
+		URL:          cw.params.URL,
+		Guarded:      cw.params.Guarded,
+		AutoLimit:    cw.params.AutoLimit,
+		Retries:      cw.params.Retries,
+		StructureTTL: cw.params.StructureTTL,
+
+		HiddenDatabases: cw.params.HiddenDatabases,
+		Preview:         &previewWrap{preview: &cw.params.Preview},
+		Masking:         wrapMaskRules(cw.params.Masking),
+		Display:         &displayWrap{display: &cw.params.Display},
+		Limits:          &limitsWrap{limits: &cw.params.Limits},
+		Quota:           &quotaWrap{quota: &cw.params.Quota},
+		Cost:            &costWrap{cost: &cw.params.Cost},
+		MigrationsDir:   cw.params.MigrationsDir,
 	})
 }
 
@@ -155,12 +431,18 @@ func (cw *structureWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
 		Name     string           `msgpack:"name"`
 		Schema   string           `msgpack:"schema"`
 		Type     string           `msgpack:"type"`
+		Status   string           `msgpack:"status"`
 		Children []*structureWrap `msgpack:"children"`
+		Lazy     bool             `msgpack:"lazy"`
+		Comment  string           `msgpack:"comment"`
 	}{
 		Name:     cw.structure.Name,
 		Schema:   cw.structure.Schema,
 		Type:     cw.structure.Type.String(),
+		Status:   cw.structure.Status,
 		Children: WrapStructures(cw.structure.Children),
+		Lazy:     cw.structure.Lazy,
+		Comment:  cw.structure.Comment,
 	})
 }
 
@@ -192,10 +474,1660 @@ func (cw *columnWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
 		return enc.Encode(nil)
 	}
 	return enc.Encode(&struct {
-		Name string `msgpack:"name"`
-		Type string `msgpack:"type"`
+		Name       string `msgpack:"name"`
+		Type       string `msgpack:"type"`
+		NotNull    bool   `msgpack:"not_null"`
+		Default    string `msgpack:"default"`
+		PrimaryKey bool   `msgpack:"primary_key"`
+		Comment    string `msgpack:"comment"`
+	}{
+		Name:       cw.column.Name,
+		Type:       cw.column.Type,
+		NotNull:    cw.column.NotNull,
+		Default:    cw.column.Default,
+		PrimaryKey: cw.column.PrimaryKey,
+		Comment:    cw.column.Comment,
+	})
+}
+
+// indexWrap is a wrapper around core.Index with msgpack marshaling capabilities
+type indexWrap struct {
+	index *core.Index
+}
+
+func WrapIndexes(indexes []*core.Index) []*indexWrap {
+	wraps := make([]*indexWrap, len(indexes))
+
+	for i := range indexes {
+		wraps[i] = &indexWrap{
+			index: indexes[i],
+		}
+	}
+
+	return wraps
+}
+
+func (iw *indexWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if iw.index == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Name    string   `msgpack:"name"`
+		Columns []string `msgpack:"columns"`
+		Unique  bool     `msgpack:"unique"`
+		Primary bool     `msgpack:"primary"`
+	}{
+		Name:    iw.index.Name,
+		Columns: iw.index.Columns,
+		Unique:  iw.index.Unique,
+		Primary: iw.index.Primary,
+	})
+}
+
+// constraintWrap is a wrapper around core.Constraint with msgpack marshaling capabilities
+type constraintWrap struct {
+	constraint *core.Constraint
+}
+
+func WrapConstraints(constraints []*core.Constraint) []*constraintWrap {
+	wraps := make([]*constraintWrap, len(constraints))
+
+	for i := range constraints {
+		wraps[i] = &constraintWrap{
+			constraint: constraints[i],
+		}
+	}
+
+	return wraps
+}
+
+func (cw *constraintWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if cw.constraint == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Name              string   `msgpack:"name"`
+		Type              string   `msgpack:"type"`
+		Columns           []string `msgpack:"columns"`
+		Definition        string   `msgpack:"definition"`
+		ReferencedSchema  string   `msgpack:"referenced_schema"`
+		ReferencedTable   string   `msgpack:"referenced_table"`
+		ReferencedColumns []string `msgpack:"referenced_columns"`
+	}{
+		Name:              cw.constraint.Name,
+		Type:              cw.constraint.Type.String(),
+		Columns:           cw.constraint.Columns,
+		Definition:        cw.constraint.Definition,
+		ReferencedSchema:  cw.constraint.ReferencedSchema,
+		ReferencedTable:   cw.constraint.ReferencedTable,
+		ReferencedColumns: cw.constraint.ReferencedColumns,
+	})
+}
+
+// triggerWrap is a wrapper around core.Trigger with msgpack marshaling capabilities
+type triggerWrap struct {
+	trigger *core.Trigger
+}
+
+func WrapTriggers(triggers []*core.Trigger) []*triggerWrap {
+	wraps := make([]*triggerWrap, len(triggers))
+
+	for i := range triggers {
+		wraps[i] = &triggerWrap{
+			trigger: triggers[i],
+		}
+	}
+
+	return wraps
+}
+
+func (tw *triggerWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if tw.trigger == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Name     string `msgpack:"name"`
+		Event    string `msgpack:"event"`
+		Timing   string `msgpack:"timing"`
+		Function string `msgpack:"function"`
+	}{
+		Name:     tw.trigger.Name,
+		Event:    tw.trigger.Event,
+		Timing:   tw.trigger.Timing,
+		Function: tw.trigger.Function,
+	})
+}
+
+// tableStatsWrap is a wrapper around core.TableStats with msgpack marshaling capabilities
+type tableStatsWrap struct {
+	stats *core.TableStats
+}
+
+func WrapTableStats(stats []*core.TableStats) []*tableStatsWrap {
+	wraps := make([]*tableStatsWrap, len(stats))
+
+	for i := range stats {
+		wraps[i] = &tableStatsWrap{
+			stats: stats[i],
+		}
+	}
+
+	return wraps
+}
+
+func (sw *tableStatsWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if sw.stats == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Schema string `msgpack:"schema"`
+		Table  string `msgpack:"table"`
+		Rows   int64  `msgpack:"rows"`
+		Size   int64  `msgpack:"size"`
+	}{
+		Schema: sw.stats.Schema,
+		Table:  sw.stats.Table,
+		Rows:   sw.stats.Rows,
+		Size:   sw.stats.Size,
+	})
+}
+
+// serverInfoWrap is a wrapper around core.ServerInfo with msgpack marshaling capabilities
+type serverInfoWrap struct {
+	info *core.ServerInfo
+}
+
+func WrapServerInfo(info *core.ServerInfo) *serverInfoWrap {
+	return &serverInfoWrap{
+		info: info,
+	}
+}
+
+func (sw *serverInfoWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if sw.info == nil {
+		return enc.Encode(nil)
+	}
+
+	type extension struct {
+		Name    string `msgpack:"name"`
+		Version string `msgpack:"version"`
+	}
+	type setting struct {
+		Name  string `msgpack:"name"`
+		Value string `msgpack:"value"`
+	}
+
+	extensions := make([]extension, len(sw.info.Extensions))
+	for i, e := range sw.info.Extensions {
+		extensions[i] = extension{Name: e.Name, Version: e.Version}
+	}
+	settings := make([]setting, len(sw.info.Settings))
+	for i, s := range sw.info.Settings {
+		settings[i] = setting{Name: s.Name, Value: s.Value}
+	}
+
+	return enc.Encode(&struct {
+		Version    string      `msgpack:"version"`
+		Uptime     int64       `msgpack:"uptime"`
+		Extensions []extension `msgpack:"extensions"`
+		Settings   []setting   `msgpack:"settings"`
+	}{
+		Version:    sw.info.Version,
+		Uptime:     sw.info.Uptime,
+		Extensions: extensions,
+		Settings:   settings,
+	})
+}
+
+// partitionWrap is a wrapper around core.Partition with msgpack marshaling capabilities
+type partitionWrap struct {
+	partition *core.Partition
+}
+
+func WrapPartitions(partitions []*core.Partition) []*partitionWrap {
+	wraps := make([]*partitionWrap, len(partitions))
+
+	for i := range partitions {
+		wraps[i] = &partitionWrap{
+			partition: partitions[i],
+		}
+	}
+
+	return wraps
+}
+
+func (pw *partitionWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if pw.partition == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Schema     string `msgpack:"schema"`
+		Name       string `msgpack:"name"`
+		Expression string `msgpack:"expression"`
+		Rows       int64  `msgpack:"rows"`
+		Size       int64  `msgpack:"size"`
+	}{
+		Schema:     pw.partition.Schema,
+		Name:       pw.partition.Name,
+		Expression: pw.partition.Expression,
+		Rows:       pw.partition.Rows,
+		Size:       pw.partition.Size,
+	})
+}
+
+// objectActionWrap is a wrapper around core.ObjectAction with msgpack marshaling capabilities
+type objectActionWrap struct {
+	action *core.ObjectAction
+}
+
+func WrapObjectActions(actions []*core.ObjectAction) []*objectActionWrap {
+	wraps := make([]*objectActionWrap, len(actions))
+
+	for i := range actions {
+		wraps[i] = &objectActionWrap{
+			action: actions[i],
+		}
+	}
+
+	return wraps
+}
+
+func (aw *objectActionWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if aw.action == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		ID           string `msgpack:"id"`
+		Title        string `msgpack:"title"`
+		Confirmation string `msgpack:"confirmation"`
+	}{
+		ID:           aw.action.ID,
+		Title:        aw.action.Title,
+		Confirmation: aw.action.Confirmation.String(),
+	})
+}
+
+// objectActionResultWrap is a wrapper around core.ObjectActionResult with msgpack marshaling capabilities
+type objectActionResultWrap struct {
+	result *core.ObjectActionResult
+}
+
+func WrapObjectActionResult(result *core.ObjectActionResult) *objectActionResultWrap {
+	return &objectActionResultWrap{
+		result: result,
+	}
+}
+
+func (rw *objectActionResultWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if rw.result == nil {
+		return enc.Encode(nil)
+	}
+
+	var call any
+	if rw.result.Call != nil {
+		call = WrapCall(rw.result.Call)
+	}
+
+	return enc.Encode(&struct {
+		Call any    `msgpack:"call"`
+		Text string `msgpack:"text"`
+	}{
+		Call: call,
+		Text: rw.result.Text,
+	})
+}
+
+// objectMatchWrap is a wrapper around core.ObjectMatch with msgpack marshaling capabilities
+type objectMatchWrap struct {
+	match *core.ObjectMatch
+}
+
+func WrapObjectMatches(matches []*core.ObjectMatch) []*objectMatchWrap {
+	wraps := make([]*objectMatchWrap, len(matches))
+
+	for i := range matches {
+		wraps[i] = &objectMatchWrap{
+			match: matches[i],
+		}
+	}
+
+	return wraps
+}
+
+func (mw *objectMatchWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if mw.match == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Schema string   `msgpack:"schema"`
+		Table  string   `msgpack:"table"`
+		Name   string   `msgpack:"name"`
+		Type   string   `msgpack:"type"`
+		Path   []string `msgpack:"path"`
 	}{
-		Name: cw.column.Name,
-		Type: cw.column.Type,
+		Schema: mw.match.Schema,
+		Table:  mw.match.Table,
+		Name:   mw.match.Name,
+		Type:   mw.match.Type.String(),
+		Path:   mw.match.Path(),
+	})
+}
+
+// roleWrap is a wrapper around core.Role with msgpack marshaling capabilities
+type roleWrap struct {
+	role *core.Role
+}
+
+func WrapRoles(roles []*core.Role) []*roleWrap {
+	wraps := make([]*roleWrap, len(roles))
+
+	for i := range roles {
+		wraps[i] = &roleWrap{
+			role: roles[i],
+		}
+	}
+
+	return wraps
+}
+
+func (rw *roleWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if rw.role == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Name      string   `msgpack:"name"`
+		Superuser bool     `msgpack:"superuser"`
+		CanLogin  bool     `msgpack:"can_login"`
+		MemberOf  []string `msgpack:"member_of"`
+	}{
+		Name:      rw.role.Name,
+		Superuser: rw.role.Superuser,
+		CanLogin:  rw.role.CanLogin,
+		MemberOf:  rw.role.MemberOf,
+	})
+}
+
+// grantWrap is a wrapper around core.Grant with msgpack marshaling capabilities
+type grantWrap struct {
+	grant *core.Grant
+}
+
+func WrapGrants(grants []*core.Grant) []*grantWrap {
+	wraps := make([]*grantWrap, len(grants))
+
+	for i := range grants {
+		wraps[i] = &grantWrap{
+			grant: grants[i],
+		}
+	}
+
+	return wraps
+}
+
+func (gw *grantWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if gw.grant == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Grantee   string `msgpack:"grantee"`
+		Privilege string `msgpack:"privilege"`
+		Grantable bool   `msgpack:"grantable"`
+		Grantor   string `msgpack:"grantor"`
+	}{
+		Grantee:   gw.grant.Grantee,
+		Privilege: gw.grant.Privilege,
+		Grantable: gw.grant.Grantable,
+		Grantor:   gw.grant.Grantor,
+	})
+}
+
+// snippetWrap is a wrapper around core.Snippet with msgpack marshaling capabilities
+type snippetWrap struct {
+	snippet *core.Snippet
+}
+
+func WrapSnippets(snippets []*core.Snippet) []*snippetWrap {
+	wraps := make([]*snippetWrap, len(snippets))
+
+	for i := range snippets {
+		wraps[i] = &snippetWrap{
+			snippet: snippets[i],
+		}
+	}
+
+	return wraps
+}
+
+func (sw *snippetWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if sw.snippet == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Name         string `msgpack:"name"`
+		ConnectionID string `msgpack:"connection_id"`
+		Description  string `msgpack:"description"`
+		Query        string `msgpack:"query"`
+	}{
+		Name:         sw.snippet.Name,
+		ConnectionID: string(sw.snippet.ConnectionID),
+		Description:  sw.snippet.Description,
+		Query:        sw.snippet.Query,
+	})
+}
+
+// scheduleWrap is a wrapper around core.Schedule with msgpack marshaling capabilities
+type scheduleWrap struct {
+	schedule *core.Schedule
+}
+
+func WrapSchedule(schedule *core.Schedule) *scheduleWrap {
+	return &scheduleWrap{
+		schedule: schedule,
+	}
+}
+
+func WrapSchedules(schedules []*core.Schedule) []*scheduleWrap {
+	wraps := make([]*scheduleWrap, len(schedules))
+
+	for i := range schedules {
+		wraps[i] = &scheduleWrap{
+			schedule: schedules[i],
+		}
+	}
+
+	return wraps
+}
+
+func (sw *scheduleWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if sw.schedule == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		ID         string `msgpack:"id"`
+		ConnID     string `msgpack:"conn_id"`
+		Query      string `msgpack:"query"`
+		IntervalMs int64  `msgpack:"interval_ms"`
+		Runs       int    `msgpack:"runs"`
+		LastCallID string `msgpack:"last_call_id"`
+	}{
+		ID:         string(sw.schedule.GetID()),
+		ConnID:     string(sw.schedule.GetConnectionID()),
+		Query:      sw.schedule.GetQuery(),
+		IntervalMs: sw.schedule.GetInterval().Milliseconds(),
+		Runs:       sw.schedule.GetRuns(),
+		LastCallID: string(sw.schedule.GetLastCallID()),
+	})
+}
+
+// metaWrap is a wrapper around core.Meta with msgpack marshaling capabilities
+type metaWrap struct {
+	meta *core.Meta
+}
+
+func WrapMeta(meta *core.Meta) *metaWrap {
+	return &metaWrap{
+		meta: meta,
+	}
+}
+
+// metricsMsg is the msgpack form of core.Metrics
+type metricsMsg struct {
+	Execution float64 `msgpack:"execution_ms"`
+	Fetch     float64 `msgpack:"fetch_ms"`
+	Archive   float64 `msgpack:"archive_ms"`
+	Format    float64 `msgpack:"format_ms"`
+}
+
+// durationMs converts duration to fractional milliseconds.
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func (mw *metaWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if mw.meta == nil {
+		return enc.Encode(nil)
+	}
+
+	schemaType := "schemaful"
+	if mw.meta.SchemaType == core.SchemaLess {
+		schemaType = "schemaless"
+	}
+
+	return enc.Encode(&struct {
+		SchemaType     string      `msgpack:"schema_type"`
+		InjectedLimit  int         `msgpack:"injected_limit"`
+		Retries        int         `msgpack:"retries"`
+		Sampling       string      `msgpack:"sampling"`
+		Notices        []string    `msgpack:"notices"`
+		QueryID        string      `msgpack:"query_id"`
+		BytesProcessed int64       `msgpack:"bytes_processed"`
+		Metrics        *metricsMsg `msgpack:"metrics"`
+	}{
+		SchemaType:     schemaType,
+		InjectedLimit:  mw.meta.InjectedLimit,
+		Retries:        mw.meta.Retries,
+		Sampling:       mw.meta.Sampling,
+		Notices:        mw.meta.Notices,
+		QueryID:        mw.meta.QueryID,
+		BytesProcessed: mw.meta.BytesProcessed,
+		Metrics: &metricsMsg{
+			Execution: durationMs(mw.meta.Metrics.Execution),
+			Fetch:     durationMs(mw.meta.Metrics.Fetch),
+			Archive:   durationMs(mw.meta.Metrics.Archive),
+			Format:    durationMs(mw.meta.Metrics.Format),
+		},
+	})
+}
+
+// planNodeWrap is a wrapper around core.PlanNode with msgpack marshaling capabilities
+type planNodeWrap struct {
+	node *core.PlanNode
+}
+
+func WrapPlanNode(node *core.PlanNode) *planNodeWrap {
+	return &planNodeWrap{
+		node: node,
+	}
+}
+
+func (pw *planNodeWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if pw.node == nil {
+		return enc.Encode(nil)
+	}
+
+	children := make([]*planNodeWrap, len(pw.node.Children))
+	for i, child := range pw.node.Children {
+		children[i] = WrapPlanNode(child)
+	}
+
+	return enc.Encode(&struct {
+		Type       string          `msgpack:"type"`
+		Relation   string          `msgpack:"relation"`
+		Cost       float64         `msgpack:"cost"`
+		Rows       float64         `msgpack:"rows"`
+		ActualRows float64         `msgpack:"actual_rows"`
+		ActualTime float64         `msgpack:"actual_time_ms"`
+		Children   []*planNodeWrap `msgpack:"children"`
+	}{
+		Type:       pw.node.Type,
+		Relation:   pw.node.Relation,
+		Cost:       pw.node.Cost,
+		Rows:       pw.node.Rows,
+		ActualRows: pw.node.ActualRows,
+		ActualTime: pw.node.ActualTime,
+		Children:   children,
+	})
+}
+
+// statementRangeWrap is a wrapper around core.StatementRange with msgpack marshaling capabilities
+type statementRangeWrap struct {
+	stmt *core.StatementRange
+}
+
+func WrapStatementRange(stmt *core.StatementRange) *statementRangeWrap {
+	return &statementRangeWrap{
+		stmt: stmt,
+	}
+}
+
+func WrapStatementRanges(stmts []*core.StatementRange) []*statementRangeWrap {
+	wraps := make([]*statementRangeWrap, len(stmts))
+
+	for i := range stmts {
+		wraps[i] = &statementRangeWrap{
+			stmt: stmts[i],
+		}
+	}
+
+	return wraps
+}
+
+func (sw *statementRangeWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if sw.stmt == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Start     int    `msgpack:"start"`
+		End       int    `msgpack:"end"`
+		StartLine int    `msgpack:"start_line"`
+		StartCol  int    `msgpack:"start_col"`
+		EndLine   int    `msgpack:"end_line"`
+		EndCol    int    `msgpack:"end_col"`
+		Text      string `msgpack:"text"`
+	}{
+		Start:     sw.stmt.Start,
+		End:       sw.stmt.End,
+		StartLine: sw.stmt.StartLine,
+		StartCol:  sw.stmt.StartCol,
+		EndLine:   sw.stmt.EndLine,
+		EndCol:    sw.stmt.EndCol,
+		Text:      sw.stmt.Text,
+	})
+}
+
+// lintDiagnosticWrap is a wrapper around core.LintDiagnostic with msgpack marshaling capabilities
+type lintDiagnosticWrap struct {
+	diagnostic *core.LintDiagnostic
+}
+
+func WrapLintDiagnostics(diagnostics []*core.LintDiagnostic) []*lintDiagnosticWrap {
+	wraps := make([]*lintDiagnosticWrap, len(diagnostics))
+
+	for i := range diagnostics {
+		wraps[i] = &lintDiagnosticWrap{
+			diagnostic: diagnostics[i],
+		}
+	}
+
+	return wraps
+}
+
+func (lw *lintDiagnosticWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if lw.diagnostic == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Line     int    `msgpack:"line"`
+		Col      int    `msgpack:"col"`
+		EndLine  int    `msgpack:"end_line"`
+		EndCol   int    `msgpack:"end_col"`
+		Severity string `msgpack:"severity"`
+		Rule     string `msgpack:"rule"`
+		Message  string `msgpack:"message"`
+	}{
+		Line:     lw.diagnostic.Line,
+		Col:      lw.diagnostic.Col,
+		EndLine:  lw.diagnostic.EndLine,
+		EndCol:   lw.diagnostic.EndCol,
+		Severity: string(lw.diagnostic.Severity),
+		Rule:     lw.diagnostic.Rule,
+		Message:  lw.diagnostic.Message,
+	})
+}
+
+// dependenciesWrap is a wrapper around core.Dependencies with msgpack marshaling capabilities
+type dependenciesWrap struct {
+	deps *core.Dependencies
+}
+
+func WrapDependencies(deps *core.Dependencies) *dependenciesWrap {
+	return &dependenciesWrap{
+		deps: deps,
+	}
+}
+
+func (dw *dependenciesWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if dw.deps == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		DependsOn  []*dependencyNodeWrap `msgpack:"depends_on"`
+		Dependents []*dependencyNodeWrap `msgpack:"dependents"`
+	}{
+		DependsOn:  wrapDependencyNodes(dw.deps.DependsOn),
+		Dependents: wrapDependencyNodes(dw.deps.Dependents),
+	})
+}
+
+// dependencyNodeWrap is a wrapper around core.DependencyNode with msgpack marshaling capabilities
+type dependencyNodeWrap struct {
+	node *core.DependencyNode
+}
+
+func wrapDependencyNodes(nodes []*core.DependencyNode) []*dependencyNodeWrap {
+	wraps := make([]*dependencyNodeWrap, len(nodes))
+
+	for i := range nodes {
+		wraps[i] = &dependencyNodeWrap{
+			node: nodes[i],
+		}
+	}
+
+	return wraps
+}
+
+func (nw *dependencyNodeWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if nw.node == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Schema   string                `msgpack:"schema"`
+		Name     string                `msgpack:"name"`
+		Type     string                `msgpack:"type"`
+		Cycle    bool                  `msgpack:"cycle"`
+		Children []*dependencyNodeWrap `msgpack:"children"`
+	}{
+		Schema:   nw.node.Schema,
+		Name:     nw.node.Name,
+		Type:     nw.node.Type.String(),
+		Cycle:    nw.node.Cycle,
+		Children: wrapDependencyNodes(nw.node.Children),
+	})
+}
+
+// schemaDiffWrap is a wrapper around core.SchemaDiff with msgpack marshaling capabilities
+type schemaDiffWrap struct {
+	diff *core.SchemaDiff
+}
+
+func WrapSchemaDiff(diff *core.SchemaDiff) *schemaDiffWrap {
+	return &schemaDiffWrap{
+		diff: diff,
+	}
+}
+
+func (dw *schemaDiffWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if dw.diff == nil {
+		return enc.Encode(nil)
+	}
+
+	type columnDiff struct {
+		Name   string      `msgpack:"name"`
+		Kind   string      `msgpack:"kind"`
+		Source *columnWrap `msgpack:"source"`
+		Target *columnWrap `msgpack:"target"`
+	}
+	type indexDiff struct {
+		Name   string     `msgpack:"name"`
+		Kind   string     `msgpack:"kind"`
+		Source *indexWrap `msgpack:"source"`
+		Target *indexWrap `msgpack:"target"`
+	}
+	type tableDiff struct {
+		Schema  string       `msgpack:"schema"`
+		Name    string       `msgpack:"name"`
+		Kind    string       `msgpack:"kind"`
+		Columns []columnDiff `msgpack:"columns"`
+		Indexes []indexDiff  `msgpack:"indexes"`
+	}
+
+	tables := make([]tableDiff, len(dw.diff.Tables))
+	for i, t := range dw.diff.Tables {
+		columns := make([]columnDiff, len(t.Columns))
+		for j, c := range t.Columns {
+			columns[j] = columnDiff{
+				Name:   c.Name,
+				Kind:   c.Kind.String(),
+				Source: WrapColumn(c.Source),
+				Target: WrapColumn(c.Target),
+			}
+		}
+		indexes := make([]indexDiff, len(t.Indexes))
+		for j, idx := range t.Indexes {
+			indexes[j] = indexDiff{
+				Name:   idx.Name,
+				Kind:   idx.Kind.String(),
+				Source: &indexWrap{index: idx.Source},
+				Target: &indexWrap{index: idx.Target},
+			}
+		}
+		tables[i] = tableDiff{
+			Schema:  t.Schema,
+			Name:    t.Name,
+			Kind:    t.Kind.String(),
+			Columns: columns,
+			Indexes: indexes,
+		}
+	}
+
+	migration := dw.diff.Migration
+	if migration == nil {
+		migration = []string{}
+	}
+
+	return enc.Encode(&struct {
+		Tables    []tableDiff `msgpack:"tables"`
+		Migration []string    `msgpack:"migration"`
+	}{
+		Tables:    tables,
+		Migration: migration,
+	})
+}
+
+// scaffoldWrap is a wrapper around core.Scaffold with msgpack marshaling capabilities
+type scaffoldWrap struct {
+	scaffold *core.Scaffold
+}
+
+func WrapScaffolds(scaffolds []*core.Scaffold) []*scaffoldWrap {
+	wraps := make([]*scaffoldWrap, len(scaffolds))
+
+	for i := range scaffolds {
+		wraps[i] = &scaffoldWrap{
+			scaffold: scaffolds[i],
+		}
+	}
+
+	return wraps
+}
+
+func (sw *scaffoldWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if sw.scaffold == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Name  string `msgpack:"name"`
+		Query string `msgpack:"query"`
+	}{
+		Name:  sw.scaffold.Name,
+		Query: sw.scaffold.Query,
+	})
+}
+
+// foreignServerWrap is a wrapper around core.ForeignServer with msgpack marshaling capabilities
+type foreignServerWrap struct {
+	server *core.ForeignServer
+}
+
+func WrapForeignServers(servers []*core.ForeignServer) []*foreignServerWrap {
+	wraps := make([]*foreignServerWrap, len(servers))
+
+	for i := range servers {
+		wraps[i] = &foreignServerWrap{
+			server: servers[i],
+		}
+	}
+
+	return wraps
+}
+
+func (fw *foreignServerWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if fw.server == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Name    string `msgpack:"name"`
+		Wrapper string `msgpack:"wrapper"`
+		Options string `msgpack:"options"`
+	}{
+		Name:    fw.server.Name,
+		Wrapper: fw.server.Wrapper,
+		Options: fw.server.Options,
+	})
+}
+
+// schemaExportWrap is a wrapper around core.SchemaExport with msgpack marshaling capabilities
+type schemaExportWrap struct {
+	export *core.SchemaExport
+}
+
+func WrapSchemaExport(export *core.SchemaExport) *schemaExportWrap {
+	return &schemaExportWrap{
+		export: export,
+	}
+}
+
+func (ew *schemaExportWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if ew.export == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Exported int      `msgpack:"exported"`
+		Skipped  []string `msgpack:"skipped"`
+		Files    []string `msgpack:"files"`
+	}{
+		Exported: ew.export.Exported,
+		Skipped:  ew.export.Skipped,
+		Files:    ew.export.Files,
+	})
+}
+
+// connectionBundleWrap is a wrapper around core.ConnectionBundle with msgpack marshaling capabilities
+type connectionBundleWrap struct {
+	bundle  *core.ConnectionBundle
+	missing []*core.SecretRef
+}
+
+// WrapConnectionBundle wraps the bundle and the secrets which are missing to import it.
+func WrapConnectionBundle(bundle *core.ConnectionBundle, missing []*core.SecretRef) *connectionBundleWrap {
+	return &connectionBundleWrap{
+		bundle:  bundle,
+		missing: missing,
+	}
+}
+
+func (bw *connectionBundleWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if bw.bundle == nil {
+		return enc.Encode(nil)
+	}
+
+	return enc.Encode(&struct {
+		Connections []*connectionParamsWrap `msgpack:"connections"`
+		Secrets     []*secretRefWrap        `msgpack:"secrets"`
+		Missing     []*secretRefWrap        `msgpack:"missing"`
+	}{
+		Connections: WrapConnectionParamsList(bw.bundle.Connections),
+		Secrets:     wrapSecretRefs(bw.bundle.Secrets),
+		Missing:     wrapSecretRefs(bw.missing),
+	})
+}
+
+// secretRefWrap is a wrapper around core.SecretRef with msgpack marshaling capabilities
+type secretRefWrap struct {
+	secret *core.SecretRef
+}
+
+func wrapSecretRefs(secrets []*core.SecretRef) []*secretRefWrap {
+	wraps := make([]*secretRefWrap, len(secrets))
+	for i, s := range secrets {
+		wraps[i] = &secretRefWrap{secret: s}
+	}
+	return wraps
+}
+
+func (sw *secretRefWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	return enc.Encode(&struct {
+		Name         string `msgpack:"name"`
+		ConnectionID string `msgpack:"connection_id"`
+		Reference    string `msgpack:"reference"`
+		Env          string `msgpack:"env"`
+		Encoding     string `msgpack:"encoding"`
+	}{
+		Name:         sw.secret.Name,
+		ConnectionID: string(sw.secret.ConnectionID),
+		Reference:    sw.secret.Reference,
+		Env:          sw.secret.Env,
+		Encoding:     string(sw.secret.Encoding),
+	})
+}
+
+// completionItemWrap is a wrapper around core.CompletionItem with msgpack marshaling capabilities
+type completionItemWrap struct {
+	item *core.CompletionItem
+}
+
+func WrapCompletionItems(items []*core.CompletionItem) []*completionItemWrap {
+	wraps := make([]*completionItemWrap, len(items))
+
+	for i := range items {
+		wraps[i] = &completionItemWrap{
+			item: items[i],
+		}
+	}
+
+	return wraps
+}
+
+func (cw *completionItemWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if cw.item == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Name   string `msgpack:"name"`
+		Kind   string `msgpack:"kind"`
+		Schema string `msgpack:"schema"`
+		Detail string `msgpack:"detail"`
+	}{
+		Name:   cw.item.Name,
+		Kind:   cw.item.Kind,
+		Schema: cw.item.Schema,
+		Detail: cw.item.Detail,
+	})
+}
+
+// signatureWrap is a wrapper around core.Signature with msgpack marshaling capabilities
+type signatureWrap struct {
+	signature *core.Signature
+}
+
+func WrapSignatures(signatures []*core.Signature) []*signatureWrap {
+	wraps := make([]*signatureWrap, len(signatures))
+
+	for i := range signatures {
+		wraps[i] = &signatureWrap{
+			signature: signatures[i],
+		}
+	}
+
+	return wraps
+}
+
+func (sw *signatureWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if sw.signature == nil {
+		return enc.Encode(nil)
+	}
+
+	type param struct {
+		Name string `msgpack:"name"`
+		Type string `msgpack:"type"`
+		Mode string `msgpack:"mode"`
+	}
+	params := make([]param, len(sw.signature.Params))
+	for i, p := range sw.signature.Params {
+		params[i] = param{
+			Name: p.Name,
+			Type: p.Type,
+			Mode: p.Mode.String(),
+		}
+	}
+
+	return enc.Encode(&struct {
+		Schema  string  `msgpack:"schema"`
+		Name    string  `msgpack:"name"`
+		Label   string  `msgpack:"label"`
+		Params  []param `msgpack:"params"`
+		Returns string  `msgpack:"returns"`
+		Comment string  `msgpack:"comment"`
+	}{
+		Schema:  sw.signature.Schema,
+		Name:    sw.signature.Name,
+		Label:   sw.signature.String(),
+		Params:  params,
+		Returns: sw.signature.Returns,
+		Comment: sw.signature.Comment,
+	})
+}
+
+// adapterInfoWrap is a wrapper around adapters.AdapterInfo with msgpack marshaling capabilities
+type adapterInfoWrap struct {
+	info *adapters.AdapterInfo
+}
+
+func WrapAdapterInfos(infos []*adapters.AdapterInfo) []*adapterInfoWrap {
+	wraps := make([]*adapterInfoWrap, len(infos))
+
+	for i := range infos {
+		wraps[i] = &adapterInfoWrap{
+			info: infos[i],
+		}
+	}
+
+	return wraps
+}
+
+func (aw *adapterInfoWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if aw.info == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Type string `msgpack:"type"`
+		Kind string `msgpack:"kind"`
+	}{
+		Type: aw.info.Type,
+		Kind: aw.info.Kind,
+	})
+}
+
+// queryStatusWrap is a wrapper around QueryStatus with msgpack marshaling capabilities
+type queryStatusWrap struct {
+	status QueryStatus
+}
+
+func WrapQueryStatus(status QueryStatus) *queryStatusWrap {
+	return &queryStatusWrap{
+		status: status,
+	}
+}
+
+func (qw *queryStatusWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	return enc.Encode(&struct {
+		ActiveCalls    int    `msgpack:"active_calls"`
+		LastDurationUs int64  `msgpack:"last_duration_us"`
+		LastError      string `msgpack:"last_error"`
+	}{
+		ActiveCalls:    qw.status.ActiveCalls,
+		LastDurationUs: qw.status.LastDuration.Microseconds(),
+		LastError:      qw.status.LastError,
+	})
+}
+
+// logEntryWrap is a wrapper around plugin.LogEntry with msgpack marshaling capabilities
+type logEntryWrap struct {
+	entry *plugin.LogEntry
+}
+
+func WrapLogEntries(entries []*plugin.LogEntry) []*logEntryWrap {
+	wraps := make([]*logEntryWrap, len(entries))
+
+	for i := range entries {
+		wraps[i] = &logEntryWrap{
+			entry: entries[i],
+		}
+	}
+
+	return wraps
+}
+
+func (lw *logEntryWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if lw.entry == nil {
+		return enc.Encode(nil)
+	}
+
+	attrs := make(map[string]string, len(lw.entry.Attrs))
+	for key, value := range lw.entry.Attrs {
+		attrs[key] = fmt.Sprint(value)
+	}
+
+	return enc.Encode(&struct {
+		TimestampUs int64             `msgpack:"timestamp_us"`
+		Level       string            `msgpack:"level"`
+		Subsystem   string            `msgpack:"subsystem"`
+		Message     string            `msgpack:"message"`
+		Attrs       map[string]string `msgpack:"attrs"`
+	}{
+		TimestampUs: lw.entry.Time.UnixMicro(),
+		Level:       strings.ToLower(lw.entry.Level.String()),
+		Subsystem:   lw.entry.Subsystem,
+		Message:     lw.entry.Message,
+		Attrs:       attrs,
+	})
+}
+
+// slowQueryWrap is a wrapper around SlowQuery with msgpack marshaling capabilities
+type slowQueryWrap struct {
+	query *SlowQuery
+}
+
+func WrapSlowQueries(queries []*SlowQuery) []*slowQueryWrap {
+	wraps := make([]*slowQueryWrap, len(queries))
+
+	for i := range queries {
+		wraps[i] = &slowQueryWrap{
+			query: queries[i],
+		}
+	}
+
+	return wraps
+}
+
+func (sw *slowQueryWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if sw.query == nil {
+		return enc.Encode(nil)
+	}
+
+	return enc.Encode(&struct {
+		CallID      string        `msgpack:"call_id"`
+		ConnID      string        `msgpack:"conn_id"`
+		Connection  string        `msgpack:"connection"`
+		Query       string        `msgpack:"query"`
+		TimestampUs int64         `msgpack:"timestamp_us"`
+		DurationMs  float64       `msgpack:"duration_ms"`
+		State       string        `msgpack:"state"`
+		Error       string        `msgpack:"error"`
+		Plan        *planNodeWrap `msgpack:"plan"`
+		PlanError   string        `msgpack:"plan_error"`
+	}{
+		CallID:      string(sw.query.CallID),
+		ConnID:      string(sw.query.ConnectionID),
+		Connection:  sw.query.Connection,
+		Query:       sw.query.Query,
+		TimestampUs: sw.query.Timestamp.UnixMicro(),
+		DurationMs:  sw.query.DurationMs,
+		State:       sw.query.State,
+		Error:       sw.query.Error,
+		Plan:        WrapPlanNode(sw.query.Plan),
+		PlanError:   sw.query.PlanError,
+	})
+}
+
+// failedQueryWrap is a wrapper around FailedQuery with msgpack marshaling capabilities
+type failedQueryWrap struct {
+	query *FailedQuery
+}
+
+func WrapFailedQueries(queries []*FailedQuery) []*failedQueryWrap {
+	wraps := make([]*failedQueryWrap, len(queries))
+
+	for i := range queries {
+		wraps[i] = &failedQueryWrap{
+			query: queries[i],
+		}
+	}
+
+	return wraps
+}
+
+func (fw *failedQueryWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if fw.query == nil {
+		return enc.Encode(nil)
+	}
+
+	return enc.Encode(&struct {
+		ConnID     string    `msgpack:"conn_id"`
+		Connection string    `msgpack:"connection"`
+		Call       *callWrap `msgpack:"call"`
+	}{
+		ConnID:     string(fw.query.ConnectionID),
+		Connection: fw.query.Connection,
+		Call:       WrapCall(fw.query.Call),
+	})
+}
+
+// tableLayoutWrap is a wrapper around TableLayout with msgpack marshaling capabilities
+type tableLayoutWrap struct {
+	layout *TableLayout
+}
+
+func WrapTableLayout(layout *TableLayout) *tableLayoutWrap {
+	return &tableLayoutWrap{
+		layout: layout,
+	}
+}
+
+type tableCellWrap struct {
+	Text  string `msgpack:"text"`
+	Width int    `msgpack:"width"`
+	Col   int    `msgpack:"col"`
+}
+
+func wrapTableCells(cells []*TableCell) []*tableCellWrap {
+	wraps := make([]*tableCellWrap, len(cells))
+	for i, cell := range cells {
+		wraps[i] = &tableCellWrap{
+			Text:  strings.Join(cell.Lines, "\n"),
+			Width: cell.Width,
+			Col:   cell.Col,
+		}
+	}
+	return wraps
+}
+
+func (tw *tableLayoutWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if tw.layout == nil {
+		return enc.Encode(nil)
+	}
+
+	type rowWrap struct {
+		Index int              `msgpack:"index"`
+		Line  int              `msgpack:"line"`
+		Cells []*tableCellWrap `msgpack:"cells"`
+	}
+	rows := make([]*rowWrap, len(tw.layout.Rows))
+	for i, cells := range tw.layout.Rows {
+		rows[i] = &rowWrap{
+			Index: tw.layout.ChunkStart + i,
+			Line:  tw.layout.RowLines[i],
+			Cells: wrapTableCells(cells),
+		}
+	}
+
+	return enc.Encode(&struct {
+		Header       []*tableCellWrap `msgpack:"header"`
+		Rows         []*rowWrap       `msgpack:"rows"`
+		Widths       []int            `msgpack:"widths"`
+		RightAligned []bool           `msgpack:"right_aligned"`
+	}{
+		Header:       wrapTableCells(tw.layout.Header),
+		Rows:         rows,
+		Widths:       tw.layout.Widths,
+		RightAligned: tw.layout.RightAligned,
+	})
+}
+
+// tableDataDiffWrap is a wrapper around core.TableDataDiff with msgpack marshaling capabilities
+type tableDataDiffWrap struct {
+	diff *core.TableDataDiff
+}
+
+func WrapTableDataDiff(diff *core.TableDataDiff) *tableDataDiffWrap {
+	return &tableDataDiffWrap{
+		diff: diff,
+	}
+}
+
+func (dw *tableDataDiffWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if dw.diff == nil {
+		return enc.Encode(nil)
+	}
+
+	type rowDiff struct {
+		Kind    string   `msgpack:"kind"`
+		Key     []any    `msgpack:"key"`
+		Source  []any    `msgpack:"source"`
+		Target  []any    `msgpack:"target"`
+		Changed []string `msgpack:"changed"`
+	}
+	diffs := make([]rowDiff, len(dw.diff.Diffs))
+	for i, d := range dw.diff.Diffs {
+		diffs[i] = rowDiff{
+			Kind:    d.Kind.String(),
+			Key:     wrapRowValues(d.Key),
+			Source:  wrapRowValues(d.Source),
+			Target:  wrapRowValues(d.Target),
+			Changed: d.Changed,
+		}
+	}
+
+	return enc.Encode(&struct {
+		Equal      bool      `msgpack:"equal"`
+		KeyColumns []string  `msgpack:"key_columns"`
+		Columns    []string  `msgpack:"columns"`
+		SourceRows int       `msgpack:"source_rows"`
+		TargetRows int       `msgpack:"target_rows"`
+		Inserted   int       `msgpack:"inserted"`
+		Deleted    int       `msgpack:"deleted"`
+		Changed    int       `msgpack:"changed"`
+		Diffs      []rowDiff `msgpack:"diffs"`
+	}{
+		Equal:      dw.diff.IsEqual(),
+		KeyColumns: dw.diff.KeyColumns,
+		Columns:    dw.diff.Columns,
+		SourceRows: dw.diff.SourceRows,
+		TargetRows: dw.diff.TargetRows,
+		Inserted:   dw.diff.Inserted,
+		Deleted:    dw.diff.Deleted,
+		Changed:    dw.diff.Changed,
+		Diffs:      diffs,
+	})
+}
+
+// resultComparisonWrap is a wrapper around core.ResultComparison with msgpack marshaling capabilities
+type resultComparisonWrap struct {
+	cmp *core.ResultComparison
+}
+
+func WrapResultComparison(cmp *core.ResultComparison) *resultComparisonWrap {
+	return &resultComparisonWrap{
+		cmp: cmp,
+	}
+}
+
+// wrapRowValues converts values of the row to strings, nil values are kept.
+func wrapRowValues(row core.Row) []any {
+	if row == nil {
+		return nil
+	}
+	values := make([]any, len(row))
+	for i, val := range row {
+		if val != nil {
+			values[i] = fmt.Sprint(val)
+		}
+	}
+	return values
+}
+
+func (cw *resultComparisonWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if cw.cmp == nil {
+		return enc.Encode(nil)
+	}
+
+	type rowDiff struct {
+		Kind     string `msgpack:"kind"`
+		Index    int    `msgpack:"index"`
+		Baseline []any  `msgpack:"baseline"`
+		Result   []any  `msgpack:"result"`
+	}
+	diffs := make([]rowDiff, len(cw.cmp.Diffs))
+	for i, d := range cw.cmp.Diffs {
+		diffs[i] = rowDiff{
+			Kind:     d.Kind.String(),
+			Index:    d.Index,
+			Baseline: wrapRowValues(d.Baseline),
+			Result:   wrapRowValues(d.Result),
+		}
+	}
+
+	return enc.Encode(&struct {
+		Passed           bool      `msgpack:"passed"`
+		BaselineChecksum string    `msgpack:"baseline_checksum"`
+		Checksum         string    `msgpack:"checksum"`
+		BaselineHeader   []string  `msgpack:"baseline_header"`
+		Header           []string  `msgpack:"header"`
+		BaselineRows     int       `msgpack:"baseline_rows"`
+		Rows             int       `msgpack:"rows"`
+		Diffs            []rowDiff `msgpack:"diffs"`
+		DiffCount        int       `msgpack:"diff_count"`
+	}{
+		Passed:           cw.cmp.Passed,
+		BaselineChecksum: cw.cmp.BaselineChecksum,
+		Checksum:         cw.cmp.Checksum,
+		BaselineHeader:   cw.cmp.BaselineHeader,
+		Header:           cw.cmp.Header,
+		BaselineRows:     cw.cmp.BaselineRows,
+		Rows:             cw.cmp.Rows,
+		Diffs:            diffs,
+		DiffCount:        cw.cmp.DiffCount,
+	})
+}
+
+// migrationStatusWrap is a wrapper around core.MigrationStatus with msgpack marshaling capabilities
+type migrationStatusWrap struct {
+	status *core.MigrationStatus
+}
+
+func WrapMigrationStatus(status *core.MigrationStatus) *migrationStatusWrap {
+	return &migrationStatusWrap{
+		status: status,
+	}
+}
+
+func (mw *migrationStatusWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if mw.status == nil {
+		return enc.Encode(nil)
+	}
+
+	type migration struct {
+		Version       string   `msgpack:"version"`
+		Name          string   `msgpack:"name"`
+		Files         []string `msgpack:"files"`
+		NoTransaction bool     `msgpack:"no_transaction"`
+		Applied       bool     `msgpack:"applied"`
+	}
+	migrations := make([]migration, len(mw.status.Migrations))
+	for i, m := range mw.status.Migrations {
+		migrations[i] = migration{
+			Version:       m.Version,
+			Name:          m.Name,
+			Files:         m.Files,
+			NoTransaction: m.NoTransaction,
+			Applied:       m.Applied,
+		}
+	}
+
+	return enc.Encode(&struct {
+		Tool       string      `msgpack:"tool"`
+		Dir        string      `msgpack:"dir"`
+		Migrations []migration `msgpack:"migrations"`
+		Dirty      bool        `msgpack:"dirty"`
+	}{
+		Tool:       string(mw.status.Tool),
+		Dir:        mw.status.Dir,
+		Migrations: migrations,
+		Dirty:      mw.status.Dirty,
+	})
+}
+
+// costEstimateWrap is a wrapper around core.CostEstimate with msgpack marshaling capabilities
+type costEstimateWrap struct {
+	estimate *core.CostEstimate
+}
+
+func WrapCostEstimate(estimate *core.CostEstimate) *costEstimateWrap {
+	return &costEstimateWrap{
+		estimate: estimate,
+	}
+}
+
+func (cw *costEstimateWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if cw.estimate == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		ScannedBytes int64   `msgpack:"scanned_bytes"`
+		PricePerTiB  float64 `msgpack:"price_per_tib"`
+		Cost         float64 `msgpack:"cost"`
+		MaxCost      float64 `msgpack:"max_cost"`
+		Exceeds      bool    `msgpack:"exceeds"`
+	}{
+		ScannedBytes: cw.estimate.ScannedBytes,
+		PricePerTiB:  cw.estimate.PricePerTiB,
+		Cost:         cw.estimate.Cost,
+		MaxCost:      cw.estimate.MaxCost,
+		Exceeds:      cw.estimate.Exceeds(),
+	})
+}
+
+// queryStatsWrap is a wrapper around core.QueryStats with msgpack marshaling capabilities
+type queryStatsWrap struct {
+	stats *core.QueryStats
+}
+
+func WrapQueryStats(stats []*core.QueryStats) []*queryStatsWrap {
+	wraps := make([]*queryStatsWrap, len(stats))
+
+	for i := range stats {
+		wraps[i] = &queryStatsWrap{
+			stats: stats[i],
+		}
+	}
+
+	return wraps
+}
+
+func (qw *queryStatsWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if qw.stats == nil {
+		return enc.Encode(nil)
+	}
+
+	return enc.Encode(&struct {
+		Fingerprint string    `msgpack:"fingerprint"`
+		LastCall    *callWrap `msgpack:"last_call"`
+		Count       int       `msgpack:"count"`
+		Failed      int       `msgpack:"failed"`
+		TotalTime   int64     `msgpack:"total_time_us"`
+		AverageTime int64     `msgpack:"average_time_us"`
+	}{
+		Fingerprint: qw.stats.Fingerprint,
+		LastCall:    WrapCall(qw.stats.LastCall),
+		Count:       qw.stats.Count,
+		Failed:      qw.stats.Failed,
+		TotalTime:   qw.stats.TotalTime.Microseconds(),
+		AverageTime: qw.stats.AverageTime().Microseconds(),
+	})
+}
+
+// watchWrap is a wrapper around core.Watch with msgpack marshaling capabilities
+type watchWrap struct {
+	watch *core.Watch
+}
+
+func WrapWatch(watch *core.Watch) *watchWrap {
+	return &watchWrap{
+		watch: watch,
+	}
+}
+
+func WrapWatches(watches []*core.Watch) []*watchWrap {
+	wraps := make([]*watchWrap, len(watches))
+
+	for i := range watches {
+		wraps[i] = &watchWrap{
+			watch: watches[i],
+		}
+	}
+
+	return wraps
+}
+
+func (ww *watchWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if ww.watch == nil {
+		return enc.Encode(nil)
+	}
+	opts := ww.watch.GetOptions()
+	return enc.Encode(&struct {
+		ID          string   `msgpack:"id"`
+		ConnID      string   `msgpack:"conn_id"`
+		Query       string   `msgpack:"query"`
+		IntervalMs  int64    `msgpack:"interval_ms"`
+		KeyColumns  []string `msgpack:"key_columns"`
+		FullRefresh bool     `msgpack:"full_refresh"`
+		MaxRows     int      `msgpack:"max_rows"`
+		Runs        int      `msgpack:"runs"`
+		LastCallID  string   `msgpack:"last_call_id"`
+	}{
+		ID:          string(ww.watch.GetID()),
+		ConnID:      string(ww.watch.GetConnectionID()),
+		Query:       ww.watch.GetQuery(),
+		IntervalMs:  opts.Interval.Milliseconds(),
+		KeyColumns:  opts.KeyColumns,
+		FullRefresh: opts.FullRefresh,
+		MaxRows:     opts.MaxRows,
+		Runs:        ww.watch.GetRuns(),
+		LastCallID:  string(ww.watch.GetLastCallID()),
+	})
+}
+
+// watchUpdateWrap is a wrapper around core.WatchUpdate with msgpack marshaling capabilities
+type watchUpdateWrap struct {
+	watch  *core.Watch
+	update *core.WatchUpdate
+}
+
+func WrapWatchUpdate(watch *core.Watch, update *core.WatchUpdate) *watchUpdateWrap {
+	return &watchUpdateWrap{
+		watch:  watch,
+		update: update,
+	}
+}
+
+func (uw *watchUpdateWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if uw.watch == nil || uw.update == nil {
+		return enc.Encode(nil)
+	}
+
+	type rowDiff struct {
+		Kind    string   `msgpack:"kind"`
+		Key     []any    `msgpack:"key"`
+		Old     []any    `msgpack:"old"`
+		New     []any    `msgpack:"new"`
+		Changed []string `msgpack:"changed"`
+	}
+	diffs := make([]rowDiff, len(uw.update.Diffs))
+	for i, d := range uw.update.Diffs {
+		kind := "changed"
+		switch d.Kind {
+		case core.DiffKindExtra:
+			kind = "inserted"
+		case core.DiffKindMissing:
+			kind = "deleted"
+		}
+		diffs[i] = rowDiff{
+			Kind:    kind,
+			Key:     wrapRowValues(d.Key),
+			Old:     wrapRowValues(d.Source),
+			New:     wrapRowValues(d.Target),
+			Changed: d.Changed,
+		}
+	}
+
+	var rows [][]any
+	if uw.update.Rows != nil {
+		rows = make([][]any, len(uw.update.Rows))
+		for i, row := range uw.update.Rows {
+			rows[i] = wrapRowValues(row)
+		}
+	}
+
+	errMsg := ""
+	if uw.update.Err != nil {
+		errMsg = uw.update.Err.Error()
+	}
+
+	return enc.Encode(&struct {
+		WatchID   string    `msgpack:"watch_id"`
+		ConnID    string    `msgpack:"conn_id"`
+		CallID    string    `msgpack:"call_id"`
+		Run       int       `msgpack:"run"`
+		Error     string    `msgpack:"error,omitempty"`
+		Header    []string  `msgpack:"header"`
+		Rows      [][]any   `msgpack:"rows,omitempty"`
+		Diffs     []rowDiff `msgpack:"diffs"`
+		Inserted  int       `msgpack:"inserted"`
+		Deleted   int       `msgpack:"deleted"`
+		Changed   int       `msgpack:"changed"`
+		Truncated bool      `msgpack:"truncated"`
+	}{
+		WatchID:   string(uw.watch.GetID()),
+		ConnID:    string(uw.watch.GetConnectionID()),
+		CallID:    string(uw.update.Call.GetID()),
+		Run:       uw.update.Run,
+		Error:     errMsg,
+		Header:    uw.update.Header,
+		Rows:      rows,
+		Diffs:     diffs,
+		Inserted:  uw.update.Inserted,
+		Deleted:   uw.update.Deleted,
+		Changed:   uw.update.Changed,
+		Truncated: uw.update.Truncated,
 	})
 }
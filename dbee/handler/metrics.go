@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// upper bounds of query duration buckets in seconds
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300}
+
+// queryKey identifies a series of finished queries
+type queryKey struct {
+	typ   string
+	state string
+}
+
+// durationHistogram counts query durations in durationBuckets.
+type durationHistogram struct {
+	buckets []int64
+	sum     float64
+	count   int64
+}
+
+// metrics counts finished queries of all sessions of the backend.
+type metrics struct {
+	mu        sync.Mutex
+	queries   map[queryKey]int64
+	durations map[string]*durationHistogram
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		queries:   make(map[queryKey]int64),
+		durations: make(map[string]*durationHistogram),
+	}
+}
+
+// observe records a finished call on connections.
+func (m *metrics) observe(call *core.Call, state core.CallState, connections []*core.Connection) {
+	typ := "multiple"
+	if len(connections) == 1 {
+		typ = connections[0].GetType()
+	}
+	seconds := call.GetTimeTaken().Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.queries[queryKey{typ: typ, state: state.String()}]++
+
+	hist, ok := m.durations[typ]
+	if !ok {
+		hist = &durationHistogram{buckets: make([]int64, len(durationBuckets))}
+		m.durations[typ] = hist
+	}
+	for i, bound := range durationBuckets {
+		if seconds <= bound {
+			hist.buckets[i]++
+		}
+	}
+	hist.sum += seconds
+	hist.count++
+}
+
+// WriteMetrics writes metrics of the backend in prometheus text format:
+// finished queries and their durations, calls and rows held in memory,
+// utilization of connection pools and disk usage of call history.
+func (h *Handler) WriteMetrics(w io.Writer) error {
+	var b strings.Builder
+
+	h.metrics.mu.Lock()
+	writeMetricHeader(&b, "dbee_queries_total", "counter", "Number of finished queries by connection type and final state.")
+	keys := make([]queryKey, 0, len(h.metrics.queries))
+	for key := range h.metrics.queries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].typ != keys[j].typ {
+			return keys[i].typ < keys[j].typ
+		}
+		return keys[i].state < keys[j].state
+	})
+	for _, key := range keys {
+		fmt.Fprintf(&b, "dbee_queries_total{type=%s,state=%s} %d\n", quoteLabel(key.typ), quoteLabel(key.state), h.metrics.queries[key])
+	}
+
+	writeMetricHeader(&b, "dbee_query_duration_seconds", "histogram", "Duration of finished queries by connection type.")
+	types := make([]string, 0, len(h.metrics.durations))
+	for typ := range h.metrics.durations {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	for _, typ := range types {
+		hist := h.metrics.durations[typ]
+		for i, bound := range durationBuckets {
+			fmt.Fprintf(&b, "dbee_query_duration_seconds_bucket{type=%s,le=\"%g\"} %d\n", quoteLabel(typ), bound, hist.buckets[i])
+		}
+		fmt.Fprintf(&b, "dbee_query_duration_seconds_bucket{type=%s,le=\"+Inf\"} %d\n", quoteLabel(typ), hist.count)
+		fmt.Fprintf(&b, "dbee_query_duration_seconds_sum{type=%s} %g\n", quoteLabel(typ), hist.sum)
+		fmt.Fprintf(&b, "dbee_query_duration_seconds_count{type=%s} %d\n", quoteLabel(typ), hist.count)
+	}
+	h.metrics.mu.Unlock()
+
+	writeMetricHeader(&b, "dbee_connections", "gauge", "Number of open connections.")
+	fmt.Fprintf(&b, "dbee_connections %d\n", len(h.lookupConnection))
+
+	rows := 0
+	for _, call := range h.lookupCall {
+		rows += call.CachedRows()
+	}
+	writeMetricHeader(&b, "dbee_calls", "gauge", "Number of calls in the call log.")
+	fmt.Fprintf(&b, "dbee_calls %d\n", len(h.lookupCall))
+	writeMetricHeader(&b, "dbee_cached_rows", "gauge", "Number of result rows held in memory.")
+	fmt.Fprintf(&b, "dbee_cached_rows %d\n", rows)
+
+	conns := h.GetConnections(nil)
+	sort.Slice(conns, func(i, j int) bool { return conns[i].GetID() < conns[j].GetID() })
+	pools := make(map[core.ConnectionID]*core.PoolStats)
+	for _, c := range conns {
+		if stats, err := c.GetPoolStats(); err == nil {
+			pools[c.GetID()] = stats
+		}
+	}
+	writePoolMetric := func(name, typ, help string, value func(*core.PoolStats) string) {
+		writeMetricHeader(&b, name, typ, help)
+		for _, c := range conns {
+			if stats, ok := pools[c.GetID()]; ok {
+				fmt.Fprintf(&b, "%s{connection=%s} %s\n", name, quoteLabel(string(c.GetID())), value(stats))
+			}
+		}
+	}
+	writePoolMetric("dbee_pool_max_open_connections", "gauge", "Maximum number of open database connections (0 is unlimited).",
+		func(s *core.PoolStats) string { return fmt.Sprint(s.MaxOpen) })
+	writePoolMetric("dbee_pool_in_use_connections", "gauge", "Number of database connections in use.",
+		func(s *core.PoolStats) string { return fmt.Sprint(s.InUse) })
+	writePoolMetric("dbee_pool_idle_connections", "gauge", "Number of idle database connections.",
+		func(s *core.PoolStats) string { return fmt.Sprint(s.Idle) })
+	writePoolMetric("dbee_pool_waits_total", "counter", "Number of times a query waited for a free database connection.",
+		func(s *core.PoolStats) string { return fmt.Sprint(s.WaitCount) })
+	writePoolMetric("dbee_pool_wait_seconds_total", "counter", "Time spent waiting for a free database connection.",
+		func(s *core.PoolStats) string { return fmt.Sprintf("%g", s.WaitSeconds) })
+
+	archive, err := core.HistoryDiskUsage()
+	if err != nil {
+		h.log.Errorf("core.HistoryDiskUsage: %s", err)
+	}
+	var callLog int64
+	if info, err := os.Stat(callLogFileName); err == nil {
+		callLog = info.Size()
+	}
+	writeMetricHeader(&b, "dbee_history_disk_bytes", "gauge", "Disk usage of call history.")
+	fmt.Fprintf(&b, "dbee_history_disk_bytes{kind=\"archive\"} %d\n", archive)
+	fmt.Fprintf(&b, "dbee_history_disk_bytes{kind=\"call_log\"} %d\n", callLog)
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func writeMetricHeader(b *strings.Builder, name, typ, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// quoteLabel quotes a label value with escapes of the prometheus text format.
+func quoteLabel(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// notifyTimeout is how long a notification command or webhook can take.
+const notifyTimeout = 30 * time.Second
+
+// CallNotification is sent when a call marked with CallNotify finishes.
+type CallNotification struct {
+	CallID       core.CallID       `json:"call_id"`
+	ConnectionID core.ConnectionID `json:"connection_id"`
+	Connection   string            `json:"connection"`
+	Query        string            `json:"query"`
+	State        string            `json:"state"`
+	DurationMs   float64           `json:"duration_ms"`
+	// number of returned rows of all result sets
+	Rows  int    `json:"rows"`
+	Error string `json:"error,omitempty"`
+	// short summary, e.g. "query on prod finished in 20m3s (120 rows)"
+	Message string `json:"message"`
+}
+
+// NotifierOptions configure where notifications of finished calls are sent.
+// Any combination of notifiers can be used.
+type NotifierOptions struct {
+	// command which is run with the message as its last argument (e.g.
+	// ["notify-send", "dbee"]), details are in DBEE_* environment variables
+	Command []string
+	// URL to which the notification is POSTed as JSON
+	Webhook string
+	// send "call_notification" events to the editor
+	Event bool
+}
+
+// notifier keeps calls which are marked for notification.
+type notifier struct {
+	mu     sync.Mutex
+	opts   NotifierOptions
+	marked map[core.CallID]struct{}
+	client *http.Client
+}
+
+func newNotifier() *notifier {
+	return &notifier{
+		opts:   NotifierOptions{Event: true},
+		marked: make(map[core.CallID]struct{}),
+		client: &http.Client{Timeout: notifyTimeout},
+	}
+}
+
+// isFinished reports whether the call won't change its state anymore.
+func isFinished(state core.CallState) bool {
+	switch state {
+	case core.CallStateArchived,
+		core.CallStateArchiveFailed,
+		core.CallStateExecutingFailed,
+		core.CallStateRetrievingFailed,
+		core.CallStateCanceled:
+		return true
+	}
+	return false
+}
+
+// SetNotifier configures notifiers of finished calls.
+func (h *Handler) SetNotifier(opts *NotifierOptions) {
+	h.notifier.mu.Lock()
+	defer h.notifier.mu.Unlock()
+
+	if opts == nil {
+		opts = &NotifierOptions{}
+	}
+	h.notifier.opts = *opts
+}
+
+// CallNotify marks the call, so that notifiers are triggered once it
+// completes or fails. The notification is sent right away if the call is
+// already finished. Disabling removes the mark.
+func (h *Handler) CallNotify(callID core.CallID, enabled bool) error {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	h.notifier.mu.Lock()
+	if !enabled {
+		delete(h.notifier.marked, callID)
+		h.notifier.mu.Unlock()
+		return nil
+	}
+	// the state is set before the state handler takes the mark
+	if !isFinished(call.GetState()) {
+		h.notifier.marked[callID] = struct{}{}
+		h.notifier.mu.Unlock()
+		return nil
+	}
+	h.notifier.mu.Unlock()
+
+	h.sendNotification(call, h.callConnection(callID))
+	return nil
+}
+
+// notifyCall triggers notifiers if the finished call was marked.
+func (h *Handler) notifyCall(call *core.Call, connections []*core.Connection) {
+	h.notifier.mu.Lock()
+	_, ok := h.notifier.marked[call.GetID()]
+	delete(h.notifier.marked, call.GetID())
+	h.notifier.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	var conn *core.Connection
+	if len(connections) == 1 {
+		conn = connections[0]
+	}
+	h.sendNotification(call, conn)
+}
+
+// callConnection returns the connection the call is stored under (nil if the
+// call isn't stored under an open connection).
+func (h *Handler) callConnection(callID core.CallID) *core.Connection {
+	return h.lookupConnection[h.callConnectionID(callID)]
+}
+
+// sendNotification sends the notification of the call to all configured
+// notifiers. Commands and webhooks run in the background.
+func (h *Handler) sendNotification(call *core.Call, conn *core.Connection) {
+	n := &CallNotification{
+		CallID:     call.GetID(),
+		Query:      call.GetQuery(),
+		State:      call.GetState().String(),
+		DurationMs: durationMs(call.GetTimeTaken()),
+		Rows:       call.CachedRows(),
+	}
+	name := "multiple connections"
+	if conn != nil {
+		n.ConnectionID = conn.GetID()
+		n.Connection = conn.GetName()
+		name = conn.GetName()
+	}
+
+	took := call.GetTimeTaken().Round(time.Millisecond)
+	switch err := call.Err(); {
+	case call.GetState() == core.CallStateCanceled:
+		n.Message = fmt.Sprintf("query on %s canceled after %s", name, took)
+	case err != nil:
+		n.Error = err.Error()
+		n.Message = fmt.Sprintf("query on %s failed after %s: %s", name, took, n.Error)
+	default:
+		n.Message = fmt.Sprintf("query on %s finished in %s (%d rows)", name, took, n.Rows)
+	}
+
+	h.notifier.mu.Lock()
+	opts := h.notifier.opts
+	h.notifier.mu.Unlock()
+
+	if opts.Event {
+		h.events.CallNotification(n)
+	}
+	if len(opts.Command) > 0 {
+		go func() {
+			if err := runNotifyCommand(opts.Command, n); err != nil {
+				h.log.Errorf("runNotifyCommand: %s", err)
+			}
+		}()
+	}
+	if opts.Webhook != "" {
+		go func() {
+			if err := h.notifier.postWebhook(opts.Webhook, n); err != nil {
+				h.log.Errorf("postWebhook: %s", err)
+			}
+		}()
+	}
+}
+
+// runNotifyCommand runs the command with the message as its last argument.
+func runNotifyCommand(command []string, n *CallNotification) error {
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	args := append(append([]string{}, command[1:]...), n.Message)
+	cmd := exec.CommandContext(ctx, command[0], args...)
+	cmd.Env = append(os.Environ(),
+		"DBEE_CALL_ID="+string(n.CallID),
+		"DBEE_CONNECTION_ID="+string(n.ConnectionID),
+		"DBEE_CONNECTION="+n.Connection,
+		"DBEE_STATE="+n.State,
+		"DBEE_DURATION_MS="+strconv.FormatFloat(n.DurationMs, 'f', 0, 64),
+		"DBEE_ROWS="+strconv.Itoa(n.Rows),
+		"DBEE_ERROR="+n.Error,
+	)
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %w: %s", command[0], err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// postWebhook posts the notification as JSON to the URL.
+func (nt *notifier) postWebhook(url string, n *CallNotification) error {
+	b, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	resp, err := nt.client.Post(url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("client.Post: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// QueryStatus describes running queries and the last finished one. It's sent
+// with query_started and query_finished events (e.g. for statuslines).
+type QueryStatus struct {
+	// number of queries running in the backend
+	ActiveCalls int
+	// duration and error of the last finished query
+	LastDuration time.Duration
+	LastError    string
+}
+
+// queryTracker keeps track of running calls of all sessions of the backend.
+type queryTracker struct {
+	mu     sync.Mutex
+	active map[core.CallID]struct{}
+	status QueryStatus
+}
+
+func newQueryTracker() *queryTracker {
+	return &queryTracker{
+		active: make(map[core.CallID]struct{}),
+	}
+}
+
+// start marks the call as running. It returns false if it was already running.
+func (qt *queryTracker) start(call *core.Call) (QueryStatus, bool) {
+	qt.mu.Lock()
+	defer qt.mu.Unlock()
+
+	if _, ok := qt.active[call.GetID()]; ok {
+		return qt.status, false
+	}
+	qt.active[call.GetID()] = struct{}{}
+	qt.status.ActiveCalls = len(qt.active)
+
+	return qt.status, true
+}
+
+// finish records the finished call.
+func (qt *queryTracker) finish(call *core.Call) QueryStatus {
+	qt.mu.Lock()
+	defer qt.mu.Unlock()
+
+	delete(qt.active, call.GetID())
+	qt.status.ActiveCalls = len(qt.active)
+	qt.status.LastDuration = call.GetTimeTaken()
+	qt.status.LastError = ""
+	if err := call.Err(); err != nil {
+		qt.status.LastError = err.Error()
+	}
+
+	return qt.status
+}
+
+// get returns the current status.
+func (qt *queryTracker) get() QueryStatus {
+	qt.mu.Lock()
+	defer qt.mu.Unlock()
+
+	return qt.status
+}
+
+// GetQueryStatus returns the number of running queries and the duration and
+// error of the last finished one.
+func (h *Handler) GetQueryStatus() QueryStatus {
+	return h.queries.get()
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// ConnectionScheduleQuery registers a query which is executed on the connection
+// every interval. Each run is stored in call history like any other call.
+func (h *Handler) ConnectionScheduleQuery(connID core.ConnectionID, query string, interval time.Duration) (*core.Schedule, error) {
+	if _, ok := h.lookupConnection[connID]; !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	// runs start outside of endpoints, so they take the lock of endpoints
+	execute := func() (*core.Call, error) {
+		h.lock.Lock()
+		defer h.lock.Unlock()
+		return h.ConnectionExecute(connID, query)
+	}
+
+	schedule, err := core.NewSchedule(connID, query, interval, execute, func(s *core.Schedule, c *core.Call) {
+		h.events.ScheduleResultChanged(s, c)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("core.NewSchedule: %w", err)
+	}
+
+	h.lookupSchedule[schedule.GetID()] = schedule
+
+	return schedule, nil
+}
+
+func (h *Handler) ScheduleCancel(id core.ScheduleID) error {
+	schedule, ok := h.lookupSchedule[id]
+	if !ok {
+		return fmt.Errorf("unknown schedule with id: %q", id)
+	}
+
+	schedule.Stop()
+	delete(h.lookupSchedule, id)
+
+	return nil
+}
+
+func (h *Handler) GetSchedules() []*core.Schedule {
+	schedules := make([]*core.Schedule, 0, len(h.lookupSchedule))
+	for _, s := range h.lookupSchedule {
+		schedules = append(schedules, s)
+	}
+
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].GetID() < schedules[j].GetID()
+	})
+
+	return schedules
+}
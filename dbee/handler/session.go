@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/kndndrj/nvim-dbee/dbee/plugin"
+)
+
+// NewSession returns a handler for another editor attached to the same
+// backend (see "dbee serve"). Connections, calls, schedules and snippets are
+// shared with the owner, while events and buffers belong to the new editor.
+// Sessions don't need to be closed, the owner closes shared resources.
+func NewSession(vim *nvim.Nvim, logger *plugin.Logger, owner *Handler) *Handler {
+	return &Handler{
+		vim: vim,
+		log: logger.With("handler"),
+		events: &eventBus{
+			vim: vim,
+			log: logger.With("events"),
+		},
+
+		lookupConnection:     owner.lookupConnection,
+		lookupCall:           owner.lookupCall,
+		lookupConnectionCall: owner.lookupConnectionCall,
+		lookupSchedule:       owner.lookupSchedule,
+		lookupWatch:          owner.lookupWatch,
+
+		lock: owner.lock,
+
+		currentConnectionID: owner.currentConnectionID,
+
+		audit:        owner.audit,
+		snippets:     owner.snippets,
+		metrics:      owner.metrics,
+		queries:      owner.queries,
+		slowLog:      owner.slowLog,
+		errorHistory: owner.errorHistory,
+		notifier:     owner.notifier,
+	}
+}
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// maximum number of slow queries kept in memory
+const slowQueryLogSize = 500
+
+// SlowQuery is a call which took longer than the threshold of the slow query log.
+type SlowQuery struct {
+	CallID       core.CallID       `json:"call_id"`
+	ConnectionID core.ConnectionID `json:"connection_id"`
+	Connection   string            `json:"connection"`
+	Query        string            `json:"query"`
+	Timestamp    time.Time         `json:"timestamp"`
+	DurationMs   float64           `json:"duration_ms"`
+	State        string            `json:"state"`
+	Error        string            `json:"error,omitempty"`
+	// plan of the query (nil if it's not supported or can't be obtained)
+	Plan      *core.PlanNode `json:"plan,omitempty"`
+	PlanError string         `json:"plan_error,omitempty"`
+}
+
+// SlowQueryLogOptions configure the slow query log.
+type SlowQueryLogOptions struct {
+	// calls which take at least this long are recorded, 0 disables the log
+	Threshold time.Duration
+	// get plans of slow queries (EXPLAIN without executing the query)
+	Explain bool
+	// file to which slow queries are appended as JSON lines (optional)
+	Path string
+}
+
+// slowQueryLog keeps slow queries of all sessions of the backend.
+type slowQueryLog struct {
+	mu      sync.Mutex
+	opts    SlowQueryLogOptions
+	entries []*SlowQuery
+}
+
+func newSlowQueryLog() *slowQueryLog {
+	return &slowQueryLog{}
+}
+
+func (sl *slowQueryLog) options() SlowQueryLogOptions {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	return sl.opts
+}
+
+func (sl *slowQueryLog) add(entry *SlowQuery) error {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	sl.entries = append(sl.entries, entry)
+	if len(sl.entries) > slowQueryLogSize {
+		sl.entries = sl.entries[len(sl.entries)-slowQueryLogSize:]
+	}
+
+	if sl.opts.Path == "" {
+		return nil
+	}
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	file, err := os.OpenFile(sl.opts.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("os.OpenFile: %w", err)
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(b, '\n'))
+	if err != nil {
+		return fmt.Errorf("file.Write: %w", err)
+	}
+
+	return nil
+}
+
+// SetSlowQueryLog configures the slow query log.
+func (h *Handler) SetSlowQueryLog(opts *SlowQueryLogOptions) {
+	h.slowLog.mu.Lock()
+	defer h.slowLog.mu.Unlock()
+
+	if opts == nil {
+		opts = &SlowQueryLogOptions{}
+	}
+	h.slowLog.opts = *opts
+}
+
+// GetSlowQueries returns slow queries from the newest one. Queries of all
+// connections are returned if connID is empty, limit 0 returns all of them.
+func (h *Handler) GetSlowQueries(connID core.ConnectionID, limit int) []*SlowQuery {
+	h.slowLog.mu.Lock()
+	defer h.slowLog.mu.Unlock()
+
+	var out []*SlowQuery
+	for i := len(h.slowLog.entries) - 1; i >= 0; i-- {
+		entry := h.slowLog.entries[i]
+		if connID != "" && entry.ConnectionID != connID {
+			continue
+		}
+		out = append(out, entry)
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+
+	return out
+}
+
+// ClearSlowQueries removes slow queries kept in memory.
+func (h *Handler) ClearSlowQueries() {
+	h.slowLog.mu.Lock()
+	defer h.slowLog.mu.Unlock()
+
+	h.slowLog.entries = nil
+}
+
+// recordSlowQuery adds a finished call to the slow query log if it took longer
+// than the threshold. Plans are only requested for single statements on a
+// single connection and are obtained in the background.
+func (h *Handler) recordSlowQuery(call *core.Call, connections []*core.Connection) {
+	opts := h.slowLog.options()
+	if opts.Threshold <= 0 || call.GetTimeTaken() < opts.Threshold {
+		return
+	}
+
+	entry := &SlowQuery{
+		CallID:     call.GetID(),
+		Query:      call.GetQuery(),
+		Timestamp:  call.GetTimestamp(),
+		DurationMs: durationMs(call.GetTimeTaken()),
+		State:      call.GetState().String(),
+	}
+	if err := call.Err(); err != nil {
+		entry.Error = err.Error()
+	}
+
+	var conn *core.Connection
+	if len(connections) == 1 {
+		conn = connections[0]
+		entry.ConnectionID = conn.GetID()
+		entry.Connection = conn.GetName()
+	}
+
+	if !opts.Explain || conn == nil || len(conn.SplitStatements(entry.Query)) != 1 {
+		if err := h.slowLog.add(entry); err != nil {
+			h.log.Errorf("slowLog.add: %s", err)
+		}
+		return
+	}
+
+	go func() {
+		plan, err := conn.Explain(entry.Query, false)
+		if err != nil && !errors.Is(err, core.ErrExplainNotSupported) {
+			entry.PlanError = err.Error()
+		}
+		entry.Plan = plan
+		if err := h.slowLog.add(entry); err != nil {
+			h.log.Errorf("slowLog.add: %s", err)
+		}
+	}()
+}
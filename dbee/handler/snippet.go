@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// SetSnippetsFile replaces the snippet store with one persisted to path.
+// Empty path keeps snippets in memory only.
+func (h *Handler) SetSnippetsFile(path string) error {
+	store, err := core.NewSnippetStore(path)
+	if err != nil {
+		return fmt.Errorf("core.NewSnippetStore: %w", err)
+	}
+
+	h.snippets = store
+	return nil
+}
+
+func (h *Handler) AddSnippet(snippet *core.Snippet) error {
+	return h.snippets.Add(snippet)
+}
+
+func (h *Handler) RemoveSnippet(connID core.ConnectionID, name string) error {
+	return h.snippets.Remove(connID, name)
+}
+
+// GetSnippets returns snippets available on connection (including global ones).
+// If connID is empty, only global snippets are returned.
+func (h *Handler) GetSnippets(connID core.ConnectionID) []*core.Snippet {
+	return h.snippets.List(connID)
+}
+
+// ConnectionRenderSnippet renders the snippet available on connection with args.
+func (h *Handler) ConnectionRenderSnippet(connID core.ConnectionID, name string, args map[string]any) (string, error) {
+	if _, ok := h.lookupConnection[connID]; !ok {
+		return "", fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	snippet, err := h.snippets.Get(connID, name)
+	if err != nil {
+		return "", err
+	}
+
+	return snippet.Render(args)
+}
+
+// ConnectionExecuteSnippet renders the snippet and executes the resulting query
+// on connection.
+func (h *Handler) ConnectionExecuteSnippet(connID core.ConnectionID, name string, args map[string]any, confirmed bool) (*core.Call, error) {
+	query, err := h.ConnectionRenderSnippet(connID, name, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.connectionExecute(connID, query, confirmed)
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// ConnectionWatchQuery registers a query which is executed on the connection
+// every interval. Its first result and every change of the result are pushed
+// with the "watch_updated" event. Each run is stored in call history like any
+// other call.
+func (h *Handler) ConnectionWatchQuery(connID core.ConnectionID, query string, opts *core.WatchOptions) (*core.Watch, error) {
+	if _, ok := h.lookupConnection[connID]; !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	// runs start outside of endpoints, so they take the lock of endpoints
+	execute := func() (*core.Call, error) {
+		h.lock.Lock()
+		defer h.lock.Unlock()
+		return h.ConnectionExecute(connID, query)
+	}
+
+	watch, err := core.NewWatch(connID, query, opts, execute, func(w *core.Watch, update *core.WatchUpdate) {
+		h.events.WatchUpdated(w, update)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("core.NewWatch: %w", err)
+	}
+
+	h.lookupWatch[watch.GetID()] = watch
+
+	return watch, nil
+}
+
+// ConnectionWatchTable watches rows of the table (see ConnectionWatchQuery).
+// Rows are identified by the primary key of the table, unless key columns
+// are provided.
+func (h *Handler) ConnectionWatchTable(connID core.ConnectionID, schema, table string, opts *core.WatchOptions) (*core.Watch, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	query, watchOpts := c.TableWatch(schema, table, opts)
+	return h.ConnectionWatchQuery(connID, query, watchOpts)
+}
+
+func (h *Handler) WatchCancel(id core.WatchID) error {
+	watch, ok := h.lookupWatch[id]
+	if !ok {
+		return fmt.Errorf("unknown watch with id: %q", id)
+	}
+
+	watch.Stop()
+	delete(h.lookupWatch, id)
+
+	return nil
+}
+
+func (h *Handler) GetWatches() []*core.Watch {
+	watches := make([]*core.Watch, 0, len(h.lookupWatch))
+	for _, w := range h.lookupWatch {
+		watches = append(watches, w)
+	}
+
+	sort.Slice(watches, func(i, j int) bool {
+		return watches[i].GetID() < watches[j].GetID()
+	})
+
+	return watches
+}
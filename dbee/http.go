@@ -0,0 +1,334 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/handler"
+)
+
+// httpAPI exposes connections and calls of the shared backend as a JSON API
+// for clients other than the editor (e.g. notebooks or a browser UI):
+//
+//	GET  /connections
+//	POST /connections/{id}/query      {"query": "...", "confirmed": false}
+//	GET  /calls/{id}
+//	POST /calls/{id}/cancel
+//	GET  /calls/{id}/results?set=0&from=0&to=100&format=json
+//	GET  /calls/{id}/export?set=0&format=csv
+//	GET  /metrics
+type httpAPI struct {
+	h *handler.Handler
+	// the same lock as the one used by endpoints of editors
+	mu sync.Locker
+	// required bearer token (see newHTTPToken)
+	token string
+}
+
+// httpConnection describes a connection without its url, which can contain
+// credentials.
+type httpConnection struct {
+	ID            core.ConnectionID `json:"id"`
+	Name          string            `json:"name"`
+	Type          string            `json:"type"`
+	Guarded       bool              `json:"guarded"`
+	InTransaction bool              `json:"in_transaction"`
+}
+
+// checkLoopback returns an error if address is not on the loopback interface.
+// The API runs queries with credentials of the user, so it's never exposed to
+// the network.
+func checkLoopback(address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("net.SplitHostPort: %w", err)
+	}
+	if !isLoopbackHost(host) {
+		return fmt.Errorf("address %q is not a loopback address", address)
+	}
+	return nil
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// newHTTPToken returns a random token for servers started without one. The
+// API is never served without a token, so web pages the user visits can't
+// reach it.
+func newHTTPToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("rand.Read: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (a *httpAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// names which resolve to loopback addresses are rejected as well, to
+	// prevent dns rebinding
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if !isLoopbackHost(strings.Trim(host, "[]")) {
+		writeHTTPError(w, http.StatusForbidden, fmt.Errorf("host %q is not a loopback address", r.Host))
+		return
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if a.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+		writeHTTPError(w, http.StatusUnauthorized, errors.New("invalid or missing token"))
+		return
+	}
+
+	// forms can't send json, so cross-site requests are always preflighted
+	if r.Method == http.MethodPost {
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/json" {
+			writeHTTPError(w, http.StatusUnsupportedMediaType, errors.New("content type must be application/json"))
+			return
+		}
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "metrics" && r.Method == http.MethodGet:
+		a.writeMetrics(w)
+	case len(parts) == 1 && parts[0] == "connections" && r.Method == http.MethodGet:
+		a.listConnections(w)
+	case len(parts) == 3 && parts[0] == "connections" && parts[2] == "query" && r.Method == http.MethodPost:
+		a.runQuery(w, r, core.ConnectionID(parts[1]))
+	case len(parts) == 2 && parts[0] == "calls" && r.Method == http.MethodGet:
+		a.getCall(w, core.CallID(parts[1]))
+	case len(parts) == 3 && parts[0] == "calls" && parts[2] == "cancel" && r.Method == http.MethodPost:
+		a.cancelCall(w, core.CallID(parts[1]))
+	case len(parts) == 3 && parts[0] == "calls" && parts[2] == "results" && r.Method == http.MethodGet:
+		a.writeResult(w, r, core.CallID(parts[1]), false)
+	case len(parts) == 3 && parts[0] == "calls" && parts[2] == "export" && r.Method == http.MethodGet:
+		a.writeResult(w, r, core.CallID(parts[1]), true)
+	default:
+		writeHTTPError(w, http.StatusNotFound, fmt.Errorf("no route for %s %s", r.Method, r.URL.Path))
+	}
+}
+
+func (a *httpAPI) listConnections(w http.ResponseWriter) {
+	a.mu.Lock()
+	conns := a.h.GetConnections(nil)
+	a.mu.Unlock()
+
+	out := make([]*httpConnection, 0, len(conns))
+	for _, c := range conns {
+		out = append(out, &httpConnection{
+			ID:            c.GetID(),
+			Name:          c.GetName(),
+			Type:          c.GetType(),
+			Guarded:       c.IsGuarded(),
+			InTransaction: c.InTransaction(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+
+	writeHTTPJSON(w, http.StatusOK, out)
+}
+
+func (a *httpAPI) runQuery(w http.ResponseWriter, r *http.Request, connID core.ConnectionID) {
+	var body struct {
+		Query     string `json:"query"`
+		Confirmed bool   `json:"confirmed"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeHTTPError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+	if body.Query == "" {
+		writeHTTPError(w, http.StatusBadRequest, errors.New("no query provided"))
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.h.GetConnections([]core.ConnectionID{connID})) == 0 {
+		writeHTTPError(w, http.StatusNotFound, fmt.Errorf("unknown connection with id: %q", connID))
+		return
+	}
+
+	var call *core.Call
+	var err error
+	if body.Confirmed {
+		call, err = a.h.ConnectionExecuteConfirmed(connID, body.Query)
+	} else {
+		call, err = a.h.ConnectionExecute(connID, body.Query)
+	}
+	if err != nil {
+		writeHTTPError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	writeHTTPJSON(w, http.StatusAccepted, call)
+}
+
+func (a *httpAPI) getCall(w http.ResponseWriter, callID core.CallID) {
+	call, ok := a.lookupCall(w, callID)
+	if !ok {
+		return
+	}
+
+	writeHTTPJSON(w, http.StatusOK, call)
+}
+
+func (a *httpAPI) cancelCall(w http.ResponseWriter, callID core.CallID) {
+	a.mu.Lock()
+	err := a.h.CallCancel(callID)
+	a.mu.Unlock()
+	if err != nil {
+		writeHTTPError(w, http.StatusNotFound, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeResult writes a page of a result set of the call. Exports contain the
+// whole result set and are sent as attachments.
+func (a *httpAPI) writeResult(w http.ResponseWriter, r *http.Request, callID core.CallID, export bool) {
+	call, ok := a.lookupCall(w, callID)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	set, err := intParam(query.Get("set"), 0)
+	if err != nil {
+		writeHTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+	from, to := 0, -1
+	if !export {
+		if from, err = intParam(query.Get("from"), 0); err != nil {
+			writeHTTPError(w, http.StatusBadRequest, err)
+			return
+		}
+		if to, err = intParam(query.Get("to"), -1); err != nil {
+			writeHTTPError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	formatName := query.Get("format")
+	if formatName == "" {
+		formatName = "json"
+		if export {
+			formatName = "csv"
+		}
+	}
+	formatter, err := newFormatter(formatName)
+	if err != nil {
+		writeHTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// results are read without the lock, because they block until the rows
+	// are retrieved
+	res, err := call.GetResultSet(set)
+	if err != nil {
+		writeHTTPError(w, http.StatusConflict, fmt.Errorf("call.GetResultSet: %w", err))
+		return
+	}
+	out, err := res.Format(formatter, from, to)
+	if err != nil {
+		writeHTTPError(w, http.StatusBadRequest, fmt.Errorf("res.Format: %w", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType(formatName))
+	w.Header().Set("X-Dbee-Total-Rows", strconv.Itoa(res.Len()))
+	if export {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s-%d.%s", callID, set, extension(formatName))))
+	}
+	_, _ = w.Write(out)
+}
+
+func (a *httpAPI) writeMetrics(w http.ResponseWriter) {
+	var b bytes.Buffer
+	a.mu.Lock()
+	err := a.h.WriteMetrics(&b)
+	a.mu.Unlock()
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(b.Bytes())
+}
+
+// lookupCall returns the call or writes an error response if it doesn't exist.
+func (a *httpAPI) lookupCall(w http.ResponseWriter, callID core.CallID) (*core.Call, bool) {
+	a.mu.Lock()
+	call, err := a.h.GetCall(callID)
+	a.mu.Unlock()
+	if err != nil {
+		writeHTTPError(w, http.StatusNotFound, err)
+		return nil, false
+	}
+	return call, true
+}
+
+func intParam(value string, def int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number: %q", value)
+	}
+	return n, nil
+}
+
+func contentType(formatName string) string {
+	switch formatName {
+	case "json":
+		return "application/json"
+	case "csv":
+		return "text/csv; charset=utf-8"
+	case "geojson":
+		return "application/geo+json"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+func extension(formatName string) string {
+	if formatName == "table" {
+		return "txt"
+	}
+	return formatName
+}
+
+func writeHTTPJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeHTTPError(w http.ResponseWriter, status int, err error) {
+	writeHTTPJSON(w, status, map[string]string{"error": err.Error()})
+}
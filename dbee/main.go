@@ -5,34 +5,46 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"runtime/debug"
+	"sync"
+	"syscall"
 
 	"github.com/neovim/go-client/nvim"
 
+	"github.com/kndndrj/nvim-dbee/dbee/adapters"
 	"github.com/kndndrj/nvim-dbee/dbee/handler"
 	"github.com/kndndrj/nvim-dbee/dbee/plugin"
 )
 
 func main() {
+	// headless subcommands
+	if len(os.Args) > 1 && (os.Args[1] == "query" || os.Args[1] == "serve") {
+		var err error
+		if os.Args[1] == "query" {
+			err = runQuery(os.Args[2:], os.Stdout)
+		} else {
+			err = runServer(os.Args[2:])
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	generateManifest := flag.String("manifest", "", "Generate manifest to file (filename of manifest).")
 	getVersion := flag.Bool("version", false, "Get version and exit.")
 	flag.Parse()
 
 	// get version info
 	if *getVersion {
-		info, ok := debug.ReadBuildInfo()
-		if !ok {
-			fmt.Println("unknown")
+		revision := buildRevision()
+		fmt.Println(revision)
+		if revision == "unknown" {
 			os.Exit(1)
 		}
-		for _, inf := range info.Settings {
-			if inf.Key == "vcs.revision" {
-				fmt.Println(inf.Value)
-				return
-			}
-		}
-		fmt.Println("unknown")
-		os.Exit(1)
+		return
 	}
 
 	stdout := os.Stdout
@@ -47,12 +59,19 @@ func main() {
 	logger := plugin.NewLogger(v)
 
 	p := plugin.New(v, logger)
+	// endpoints and asynchronous requests modify the same handler
+	var mu sync.Mutex
+	p.SetLock(&mu)
 
 	h := handler.New(v, logger)
+	h.SetLock(&mu)
 	defer h.Close()
 
 	// configure "endpoints" from handler
 	mountEndpoints(p, h)
+	p.SetBackendInfo(backendInfo(h, false))
+	// negotiation and asynchronous requests for all endpoints
+	p.RegisterProtocol()
 
 	// generate manifest
 	if *generateManifest != "" {
@@ -64,8 +83,56 @@ func main() {
 		return
 	}
 
-	// start server
+	// stop serving when the editor terminates the backend, so that the
+	// deferred close of the handler runs
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		<-signals
+		v.Close()
+	}()
+
+	// start server (errors aren't fatal, so the handler is still closed)
 	if err := v.Serve(); err != nil {
-		log.Fatal(err)
+		log.Print(err)
+	}
+}
+
+// buildRevision returns the vcs revision the binary was built from.
+func buildRevision() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	for _, inf := range info.Settings {
+		if inf.Key == "vcs.revision" {
+			return inf.Value
+		}
+	}
+	return "unknown"
+}
+
+// backendInfo describes the build of the backend for negotiation with the
+// frontend. Shared backends are the ones started with "dbee serve".
+func backendInfo(h *handler.Handler, shared bool) *plugin.BackendInfo {
+	var builtin []string
+	for _, info := range h.GetAdapters() {
+		if info.Kind == adapters.AdapterKindBuiltin {
+			builtin = append(builtin, info.Type)
+		}
+	}
+
+	features := []string{"external_adapters"}
+	if adapters.PluginsSupported {
+		features = append(features, "go_plugins")
+	}
+	if shared {
+		features = append(features, "shared")
+	}
+
+	return &plugin.BackendInfo{
+		Version:  buildRevision(),
+		Adapters: builtin,
+		Features: features,
 	}
 }
@@ -1,68 +1,405 @@
 package plugin
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/neovim/go-client/nvim"
 )
 
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+var ErrUnknownLogFormat = errors.New("unknown log format")
+
+// LogOptions configure the logger. Zero values are replaced with defaults.
+type LogOptions struct {
+	// minimum level of entries
+	Level slog.Level
+	// levels of subsystems (e.g. "handler" or "rpc") which override Level
+	Levels map[string]slog.Level
+	// LogFormatText or LogFormatJSON
+	Format string
+	// log file (dbee/dbee.log in the cache directory of nvim if empty)
+	Path string
+	// size of the log file in bytes after which it is rotated
+	MaxSize int64
+	// number of rotated files which are kept
+	MaxBackups int
+	// number of recent entries kept in memory
+	RingSize int
+}
+
+// LogEntry is a log entry kept in memory.
+type LogEntry struct {
+	Time      time.Time
+	Level     slog.Level
+	Subsystem string
+	Message   string
+	// structured attributes of the entry
+	Attrs map[string]any
+}
+
+// LogFilter selects entries returned by Logger.Entries.
+type LogFilter struct {
+	// minimum level of entries
+	Level slog.Level
+	// entries of a single subsystem, all subsystems if empty
+	Subsystem string
+	// maximum number of the most recent entries, all of them if 0
+	Limit int
+}
+
+// ParseLogLevel parses a level name ("debug", "info", "warn" or "error").
+func ParseLogLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("level.UnmarshalText: %w", err)
+	}
+	return level, nil
+}
+
+// Logger writes structured entries to a rotated log file and keeps the most
+// recent ones in memory, so they can be inspected from the editor. Loggers of
+// subsystems (see With) share the file and the memory.
 type Logger struct {
+	core      *logCore
+	subsystem string
+}
+
+// logCore is shared by loggers of all subsystems.
+type logCore struct {
+	mu           sync.Mutex
 	vim          *nvim.Nvim
-	logger       *log.Logger
-	file         *os.File
+	opts         LogOptions
+	handler      slog.Handler
+	file         *rotatingFile
 	triedFileSet bool
+	// ring buffer of recent entries
+	entries []*LogEntry
+	next    int
+}
+
+func defaultLogOptions() LogOptions {
+	return LogOptions{
+		Level:      slog.LevelInfo,
+		Format:     LogFormatText,
+		MaxSize:    10 << 20,
+		MaxBackups: 3,
+		RingSize:   1000,
+	}
 }
 
 func NewLogger(vim *nvim.Nvim) *Logger {
-	return &Logger{
-		vim:          vim,
-		logger:       log.New(os.Stdout, "", log.Ldate|log.Ltime),
-		triedFileSet: false,
+	c := &logCore{
+		vim:  vim,
+		opts: defaultLogOptions(),
 	}
+	c.handler = c.newHandler(os.Stderr)
+
+	return &Logger{core: c}
 }
 
-func (l *Logger) setupFile() error {
-	var fileName string
-	err := l.vim.Call("stdpath", &fileName, "cache")
+// With returns a logger of a subsystem, which is added to every entry.
+func (l *Logger) With(subsystem string) *Logger {
+	return &Logger{core: l.core, subsystem: subsystem}
+}
+
+// SetOptions changes the configuration of the logger. The log file is
+// reopened if its path changes.
+func (l *Logger) SetOptions(opts *LogOptions) error {
+	o := defaultLogOptions()
+	if opts != nil {
+		o.Level = opts.Level
+		o.Levels = opts.Levels
+		o.Path = opts.Path
+		if opts.Format != "" {
+			o.Format = opts.Format
+		}
+		if opts.MaxSize > 0 {
+			o.MaxSize = opts.MaxSize
+		}
+		if opts.MaxBackups > 0 {
+			o.MaxBackups = opts.MaxBackups
+		}
+		if opts.RingSize > 0 {
+			o.RingSize = opts.RingSize
+		}
+	}
+	if o.Format != LogFormatText && o.Format != LogFormatJSON {
+		return fmt.Errorf("%w: %q", ErrUnknownLogFormat, o.Format)
+	}
+
+	c := l.core
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if o.Path != c.opts.Path && c.file != nil {
+		c.file.Close()
+		c.file = nil
+		c.triedFileSet = false
+	}
+	if o.RingSize != c.opts.RingSize {
+		c.entries = c.sortedEntries()
+		if len(c.entries) > o.RingSize {
+			c.entries = c.entries[len(c.entries)-o.RingSize:]
+		}
+		c.next = len(c.entries) % o.RingSize
+	}
+	c.opts = o
+
+	var w io.Writer = os.Stderr
+	if c.file != nil {
+		c.file.maxSize = o.MaxSize
+		c.file.maxBackups = o.MaxBackups
+		w = c.file
+	}
+	c.handler = c.newHandler(w)
+
+	return nil
+}
+
+// Entries returns entries kept in memory from the oldest to the newest.
+func (l *Logger) Entries(filter *LogFilter) []*LogEntry {
+	if filter == nil {
+		filter = &LogFilter{Level: slog.LevelDebug}
+	}
+
+	c := l.core
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var entries []*LogEntry
+	for _, e := range c.sortedEntries() {
+		if e.Level < filter.Level || (filter.Subsystem != "" && e.Subsystem != filter.Subsystem) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[len(entries)-filter.Limit:]
+	}
+
+	return entries
+}
+
+func (l *Logger) Close() {
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+
+	if l.core.file != nil {
+		l.core.file.Close()
+		l.core.file = nil
+	}
+}
+
+// Log writes an entry with structured attributes given as key-value pairs
+// (e.g. "call_id", id).
+func (l *Logger) Log(level slog.Level, message string, args ...any) {
+	l.core.log(l.subsystem, level, message, args...)
+}
+
+func (l *Logger) Debugf(format string, args ...any) {
+	l.core.log(l.subsystem, slog.LevelDebug, fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Infof(format string, args ...any) {
+	l.core.log(l.subsystem, slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Warnf(format string, args ...any) {
+	l.core.log(l.subsystem, slog.LevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Errorf(format string, args ...any) {
+	l.core.log(l.subsystem, slog.LevelError, fmt.Sprintf(format, args...))
+}
+
+func (c *logCore) newHandler(w io.Writer) slog.Handler {
+	// levels are checked before entries reach the handler
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug}
+	if c.opts.Format == LogFormatJSON {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+func (c *logCore) enabled(subsystem string, level slog.Level) bool {
+	threshold, ok := c.opts.Levels[subsystem]
+	if !ok {
+		threshold = c.opts.Level
+	}
+	return level >= threshold
+}
+
+func (c *logCore) log(subsystem string, level slog.Level, message string, args ...any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.enabled(subsystem, level) {
+		return
+	}
+
+	if c.file == nil && !c.triedFileSet {
+		c.triedFileSet = true
+		if err := c.setupFile(); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "dbee: opening log file: %s\n", err)
+		}
+	}
+
+	record := slog.NewRecord(time.Now(), level, message, 0)
+	if subsystem != "" {
+		record.AddAttrs(slog.String("subsystem", subsystem))
+	}
+	record.Add(args...)
+	_ = c.handler.Handle(context.Background(), record)
+
+	entry := &LogEntry{
+		Time:      record.Time,
+		Level:     level,
+		Subsystem: subsystem,
+		Message:   message,
+		Attrs:     make(map[string]any),
+	}
+	record.Attrs(func(a slog.Attr) bool {
+		if a.Key != "subsystem" {
+			entry.Attrs[a.Key] = a.Value.Resolve().Any()
+		}
+		return true
+	})
+	c.push(entry)
+}
+
+// push adds the entry to the ring buffer.
+func (c *logCore) push(entry *LogEntry) {
+	if len(c.entries) < c.opts.RingSize {
+		c.entries = append(c.entries, entry)
+		c.next = len(c.entries) % c.opts.RingSize
+		return
+	}
+	c.entries[c.next] = entry
+	c.next = (c.next + 1) % c.opts.RingSize
+}
+
+// sortedEntries returns entries of the ring buffer from the oldest one.
+func (c *logCore) sortedEntries() []*LogEntry {
+	if len(c.entries) < c.opts.RingSize {
+		return append([]*LogEntry(nil), c.entries...)
+	}
+	return append(append([]*LogEntry(nil), c.entries[c.next:]...), c.entries[:c.next]...)
+}
+
+func (c *logCore) setupFile() error {
+	fileName := c.opts.Path
+	if fileName == "" {
+		var dir string
+		if c.vim != nil {
+			err := c.vim.Call("stdpath", &dir, "cache")
+			if err != nil {
+				return err
+			}
+		} else {
+			// without an editor (server mode), use the same directory as nvim
+			cache, err := os.UserCacheDir()
+			if err != nil {
+				return err
+			}
+			dir = filepath.Join(cache, "nvim")
+		}
+		fileName = filepath.Join(dir, "dbee", "dbee.log")
+	}
+
+	file, err := openRotatingFile(fileName, c.opts.MaxSize, c.opts.MaxBackups)
 	if err != nil {
 		return err
 	}
-	fileName = filepath.Join(fileName, "dbee", "dbee.log")
 
-	file, err := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o666)
+	c.file = file
+	c.handler = c.newHandler(file)
+	return nil
+}
+
+// rotatingFile is a log file which is renamed to path.1 (and older backups
+// to path.2, ...) when it grows over maxSize.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func openRotatingFile(path string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+	rf := &rotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *rotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(rf.path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(rf.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o666)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
 	if err != nil {
+		file.Close()
 		return err
 	}
 
-	l.logger.SetOutput(file)
+	rf.file = file
+	rf.size = info.Size()
 	return nil
 }
 
-func (l *Logger) Close() {
-	if l.file != nil {
-		l.file.Close()
+func (rf *rotatingFile) Write(p []byte) (int, error) {
+	if rf.size > 0 && rf.size+int64(len(p)) > rf.maxSize {
+		if err := rf.rotate(); err != nil {
+			return 0, fmt.Errorf("rf.rotate: %w", err)
+		}
 	}
+
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
 }
 
-func (l *Logger) log(level, message string) {
-	if l.file == nil && !l.triedFileSet {
-		err := l.setupFile()
-		if err != nil {
-			l.logger.Print(err)
+func (rf *rotatingFile) rotate() error {
+	if err := rf.file.Close(); err != nil {
+		return err
+	}
+
+	for i := rf.maxBackups - 1; i >= 1; i-- {
+		_ = os.Rename(rf.backup(i), rf.backup(i+1))
+	}
+	if rf.maxBackups > 0 {
+		if err := os.Rename(rf.path, rf.backup(1)); err != nil {
+			return err
 		}
-		l.triedFileSet = true
+	} else if err := os.Remove(rf.path); err != nil {
+		return err
 	}
 
-	l.logger.Printf("[%s]: %s", level, message)
+	return rf.open()
 }
 
-func (l *Logger) Infof(format string, args ...any) {
-	l.log("info", fmt.Sprintf(format, args...))
+func (rf *rotatingFile) backup(i int) string {
+	return fmt.Sprintf("%s.%d", rf.path, i)
 }
 
-func (l *Logger) Errorf(format string, args ...any) {
-	l.log("error", fmt.Sprintf(format, args...))
+func (rf *rotatingFile) Close() {
+	rf.file.Close()
 }
@@ -0,0 +1,97 @@
+package plugin
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// discardLogger returns a logger which only keeps entries in memory.
+func discardLogger(opts ...*LogOptions) *Logger {
+	l := NewLogger(nil)
+	for _, o := range opts {
+		_ = l.SetOptions(o)
+	}
+	l.core.triedFileSet = true
+	l.core.handler = slog.NewTextHandler(io.Discard, nil)
+	return l
+}
+
+func TestLogger_Levels(t *testing.T) {
+	r := require.New(t)
+
+	l := discardLogger(&LogOptions{
+		Level:  slog.LevelWarn,
+		Levels: map[string]slog.Level{"handler": slog.LevelDebug},
+	})
+
+	l.With("rpc").Infof("skipped")
+	l.With("rpc").Errorf("rpc failed: %d", 1)
+	l.With("handler").Log(slog.LevelDebug, "call started", "call_id", "abc")
+
+	entries := l.Entries(nil)
+	r.Len(entries, 2)
+	r.Equal("rpc failed: 1", entries[0].Message)
+	r.Equal("rpc", entries[0].Subsystem)
+	r.Equal(map[string]any{"call_id": "abc"}, entries[1].Attrs)
+
+	entries = l.Entries(&LogFilter{Level: slog.LevelError})
+	r.Len(entries, 1)
+	entries = l.Entries(&LogFilter{Subsystem: "handler", Level: slog.LevelDebug})
+	r.Len(entries, 1)
+
+	r.ErrorIs(l.SetOptions(&LogOptions{Format: "xml"}), ErrUnknownLogFormat)
+}
+
+func TestLogger_RingBuffer(t *testing.T) {
+	r := require.New(t)
+
+	l := discardLogger(&LogOptions{RingSize: 3})
+
+	for i := 0; i < 5; i++ {
+		l.Infof("entry %d", i)
+	}
+
+	var messages []string
+	for _, e := range l.Entries(nil) {
+		messages = append(messages, e.Message)
+	}
+	r.Equal([]string{"entry 2", "entry 3", "entry 4"}, messages)
+
+	r.Len(l.Entries(&LogFilter{Limit: 1}), 1)
+	r.Equal("entry 4", l.Entries(&LogFilter{Limit: 1})[0].Message)
+}
+
+func TestLogger_Rotation(t *testing.T) {
+	r := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "dbee.log")
+	l := NewLogger(nil)
+	defer l.Close()
+	r.NoError(l.SetOptions(&LogOptions{Path: path, Format: LogFormatJSON, MaxSize: 200, MaxBackups: 2}))
+
+	for i := 0; i < 10; i++ {
+		l.Infof("a fairly long message number %d", i)
+	}
+
+	current, err := os.ReadFile(path)
+	r.NoError(err)
+	r.Contains(string(current), `"msg":"a fairly long message number 9"`)
+	r.LessOrEqual(len(current), 200)
+
+	_, err = os.Stat(path + ".1")
+	r.NoError(err)
+	_, err = os.Stat(path + ".2")
+	r.NoError(err)
+	_, err = os.Stat(path + ".3")
+	r.True(os.IsNotExist(err))
+
+	backup, err := os.ReadFile(path + ".1")
+	r.NoError(err)
+	r.True(strings.HasPrefix(string(backup), "{"))
+}
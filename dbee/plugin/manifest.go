@@ -3,9 +3,15 @@ package plugin
 const manifestLuaFile = `-- This file is automatically generated using "dbee -manifest <file>"
 -- DO NOT EDIT!
 
-return function()
+---@param opts? { server?: string } address of a running "dbee serve" to connect to
+return function(opts)
+  opts = opts or {}
+
   -- Register host
   vim.fn["remote#host#Register"]("{{ .Host }}", "x", function()
+    if opts.server then
+      return vim.fn.sockconnect(opts.server:find("/") and "pipe" or "tcp", opts.server, { rpc = true })
+    end
     return vim.fn.jobstart({ "{{ .Executable }}" }, {
       rpc = true,
       detach = true,
@@ -2,10 +2,13 @@ package plugin
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"reflect"
 	"sort"
+	"sync"
 	"text/template"
+	"time"
 
 	"github.com/neovim/go-client/nvim"
 )
@@ -14,17 +17,32 @@ import (
 type Plugin struct {
 	vim         *nvim.Nvim
 	pluginSpecs []*pluginSpec
-	log         *Logger
+	// endpoints by name, used by Dispatch
+	endpoints map[string]reflect.Value
+	log       *Logger
+	// lock is held while endpoints run, so endpoints called asynchronously
+	// (see RegisterProtocol) don't run at the same time as other ones
+	lock sync.Locker
+	// build info reported in negotiation
+	backend *BackendInfo
 }
 
 // New returns an intialized plugin.
 func New(v *nvim.Nvim, l *Logger) *Plugin {
 	return &Plugin{
-		vim: v,
-		log: l,
+		vim:       v,
+		endpoints: make(map[string]reflect.Value),
+		log:       l.With("rpc"),
+		lock:      &sync.Mutex{},
 	}
 }
 
+// SetLock replaces the lock which is held while endpoints run. Plugins of
+// editors which share a handler use the same lock.
+func (p *Plugin) SetLock(lock sync.Locker) {
+	p.lock = lock
+}
+
 type pluginSpec struct {
 	sm   string
 	Type string            `msgpack:"type"`
@@ -50,18 +68,18 @@ func (p *Plugin) handle(fn interface{}, spec *pluginSpec) {
 	}
 }
 
-func (p *Plugin) logReturn(method string, values []reflect.Value) {
+func (p *Plugin) logReturn(method string, took time.Duration, values []reflect.Value) {
 	// check for return errors
 	for _, val := range values {
 		v := val.Interface()
 
 		if v, ok := v.(error); ok && v != nil {
-			p.log.Infof("method %q failed with error: %s", method, v)
+			p.log.Log(slog.LevelWarn, "method failed", "method", method, "duration", took, "error", v.Error())
 			return
 		}
 	}
 
-	p.log.Infof("method %q returned successfully", method)
+	p.log.Log(slog.LevelDebug, "method returned", "method", method, "duration", took)
 }
 
 // RegisterEndpoint registers fn as a handler for a vim function. The function
@@ -69,6 +87,7 @@ func (p *Plugin) logReturn(method string, values []reflect.Value) {
 //
 //	func([v *nvim.Nvim,] args {arrayType}) ({resultType}, error)
 //	func([v *nvim.Nvim,] args {arrayType}) error
+//	func([v *nvim.Nvim,] args {arrayType})
 //
 // where {arrayType} is a type that can be unmarshaled from a MessagePack
 // array and {resultType} is the type of function result.
@@ -76,11 +95,15 @@ func (p *Plugin) RegisterEndpoint(name string, fn any) {
 	v := reflect.ValueOf(fn)
 
 	newFn := reflect.MakeFunc(v.Type(), func(args []reflect.Value) (results []reflect.Value) {
-		p.log.Infof("calling method %q", name)
+		p.log.Log(slog.LevelDebug, "calling method", "method", name)
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		start := time.Now()
 		ret := v.Call(args)
-		p.logReturn(name, ret)
+		p.logReturn(name, time.Since(start), ret)
 		return ret
 	})
+	p.endpoints[name] = newFn
 
 	p.handle(newFn.Interface(), &pluginSpec{
 		sm:   `0:function:` + name,
@@ -0,0 +1,141 @@
+package plugin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/neovim/go-client/msgpack"
+)
+
+const (
+	// ProtocolVersion is the version of the protocol between the lua frontend
+	// and the backend. It is increased on incompatible changes of endpoints
+	// (e.g. removed endpoints or changed arguments), new endpoints are
+	// announced as capabilities instead.
+	ProtocolVersion = 1
+	// MinProtocolVersion is the oldest frontend protocol version which the
+	// backend still serves.
+	MinProtocolVersion = 1
+)
+
+var ErrUnknownMethod = errors.New("unknown method")
+
+// Protocol describes the backend side of the protocol.
+type Protocol struct {
+	Version    int `msgpack:"version"`
+	MinVersion int `msgpack:"min_version"`
+	// Compatible is false if the frontend is older than MinVersion
+	Compatible bool `msgpack:"compatible"`
+	// names of endpoints the backend serves
+	Capabilities []string `msgpack:"capabilities"`
+	// build of the backend, nil if not set
+	Backend *BackendInfo `msgpack:"backend"`
+}
+
+// BackendInfo describes the build of the backend, so the frontend can hide
+// actions which the backend can't perform.
+type BackendInfo struct {
+	// vcs revision of the binary ("unknown" if not known)
+	Version string `msgpack:"version"`
+	// types of adapters compiled into the binary
+	Adapters []string `msgpack:"adapters"`
+	// optional features of the binary (e.g. "go_plugins")
+	Features []string `msgpack:"features"`
+}
+
+// SetBackendInfo sets the build info reported in negotiation.
+func (p *Plugin) SetBackendInfo(info *BackendInfo) {
+	p.backend = info
+}
+
+// Negotiate returns the protocol of the backend for a frontend which speaks
+// the provided version.
+func (p *Plugin) Negotiate(version int) *Protocol {
+	capabilities := make([]string, 0, len(p.endpoints))
+	for name := range p.endpoints {
+		capabilities = append(capabilities, name)
+	}
+	sort.Strings(capabilities)
+
+	return &Protocol{
+		Version:      ProtocolVersion,
+		MinVersion:   MinProtocolVersion,
+		Compatible:   version >= MinProtocolVersion,
+		Capabilities: capabilities,
+		Backend:      p.backend,
+	}
+}
+
+// Dispatch calls the endpoint registered under method. Args are the same as
+// arguments of the vim function of the endpoint.
+func (p *Plugin) Dispatch(method string, args []any) (any, error) {
+	fn, ok := p.endpoints[method]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownMethod, method)
+	}
+
+	// arguments are decoded the same way as arguments of vim functions
+	var in []reflect.Value
+	if fn.Type().NumIn() > 0 {
+		var buf bytes.Buffer
+		if err := msgpack.NewEncoder(&buf).Encode(args); err != nil {
+			return nil, fmt.Errorf("msgpack.Encode: %w", err)
+		}
+		arg := reflect.New(fn.Type().In(0).Elem())
+		if err := msgpack.NewDecoder(&buf).Decode(arg.Interface()); err != nil {
+			return nil, fmt.Errorf("msgpack.Decode: %w", err)
+		}
+		in = append(in, arg)
+	}
+
+	var result any
+	var err error
+	for _, out := range fn.Call(in) {
+		if e, ok := out.Interface().(error); ok {
+			err = e
+		} else if out.Type() != reflect.TypeOf((*error)(nil)).Elem() {
+			result = out.Interface()
+		}
+	}
+
+	return result, err
+}
+
+// RegisterProtocol registers endpoints for protocol negotiation and
+// asynchronous requests. Responses to asynchronous requests are sent to
+// "dbee.handler.__rpc" with the id of the request.
+func (p *Plugin) RegisterProtocol() {
+	p.RegisterEndpoint(
+		"DbeeNegotiate",
+		func(args *struct {
+			Version int `msgpack:",array"`
+		},
+		) (any, error) {
+			return p.Negotiate(args.Version), nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeRequest",
+		func(args *struct {
+			ID     int64 `msgpack:",array"`
+			Method string
+			Args   []any
+		},
+		) {
+			go func() {
+				result, err := p.Dispatch(args.Method, args.Args)
+				errMsg := ""
+				if err != nil {
+					errMsg = err.Error()
+				}
+
+				err = p.vim.ExecLua(`require("dbee.handler.__rpc").respond(...)`, nil, args.ID, result, errMsg)
+				if err != nil {
+					p.log.Errorf("p.vim.ExecLua: %s", err)
+				}
+			}()
+		})
+}
@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlugin_Dispatch(t *testing.T) {
+	r := require.New(t)
+
+	p := New(nil, discardLogger())
+	p.RegisterEndpoint(
+		"Add",
+		func(args *struct {
+			A    int `msgpack:",array"`
+			Opts *struct {
+				B int `msgpack:"b"`
+			}
+		},
+		) (any, error) {
+			return args.A + args.Opts.B, nil
+		})
+	p.RegisterEndpoint(
+		"Fail",
+		func(args *struct {
+			Name string `msgpack:",array"`
+		},
+		) error {
+			return errors.New("failed: " + args.Name)
+		})
+	p.RegisterEndpoint(
+		"Ping",
+		func() (any, error) {
+			return "pong", nil
+		})
+
+	result, err := p.Dispatch("Add", []any{1, map[string]any{"b": 2}})
+	r.NoError(err)
+	r.Equal(3, result)
+
+	result, err = p.Dispatch("Fail", []any{"x"})
+	r.EqualError(err, "failed: x")
+	r.Nil(result)
+
+	result, err = p.Dispatch("Ping", nil)
+	r.NoError(err)
+	r.Equal("pong", result)
+
+	_, err = p.Dispatch("Missing", nil)
+	r.ErrorIs(err, ErrUnknownMethod)
+
+	protocol := p.Negotiate(ProtocolVersion)
+	r.True(protocol.Compatible)
+	r.Equal([]string{"Add", "Fail", "Ping"}, protocol.Capabilities)
+	r.False(p.Negotiate(MinProtocolVersion - 1).Compatible)
+	r.Nil(protocol.Backend)
+
+	info := &BackendInfo{Version: "abc", Adapters: []string{"postgres"}}
+	p.SetBackendInfo(info)
+	r.Equal(info, p.Negotiate(ProtocolVersion).Backend)
+}
+
+func TestPlugin_DispatchLocked(t *testing.T) {
+	r := require.New(t)
+
+	// endpoints called asynchronously never run at the same time
+	p := New(nil, discardLogger())
+	var running, overlaps atomic.Int32
+	p.RegisterEndpoint(
+		"Work",
+		func() (any, error) {
+			if running.Add(1) > 1 {
+				overlaps.Add(1)
+			}
+			defer running.Add(-1)
+			for i := 0; i < 1000; i++ {
+				_ = running.Load()
+			}
+			return nil, nil
+		})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := p.Dispatch("Work", nil)
+			r.NoError(err)
+		}()
+	}
+	wg.Wait()
+
+	r.Zero(overlaps.Load())
+}
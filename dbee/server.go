@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/kndndrj/nvim-dbee/dbee/handler"
+	"github.com/kndndrj/nvim-dbee/dbee/plugin"
+)
+
+// interval between writes of the metrics file
+const metricsInterval = 15 * time.Second
+
+// runServer starts a backend which is shared by multiple editors:
+//
+//	dbee serve --listen /tmp/dbee.sock
+//
+// Editors connect to it with the "server" config option. Connections, calls
+// and the call log are shared, so a query started in one editor can be
+// inspected in another. The rpc isn't authenticated, so editors connect through
+// a unix socket which only the current user can access.
+//
+// With --http, the backend is also served as a JSON API on a loopback
+// address (see httpAPI). Metrics are served on /metrics of the API or written
+// to --metrics-file.
+func runServer(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	listenFlag := fs.String("listen", "", "Path of a unix socket to listen on for editors.")
+	httpFlag := fs.String("http", "", "Loopback address of the HTTP API (e.g. 127.0.0.1:7655).")
+	tokenFlag := fs.String("http-token", os.Getenv("DBEE_HTTP_TOKEN"), "Bearer token required by the HTTP API (default $DBEE_HTTP_TOKEN, generated if empty).")
+	metricsFileFlag := fs.String("metrics-file", "", "File to which metrics in prometheus text format are written every 15 seconds.")
+	connectionsFlag := fs.String("connections", "", "File with connections to open on start (same format as the plugin's file source).")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *listenFlag == "" && *httpFlag == "" {
+		return errors.New("--listen or --http is required")
+	}
+	// the rpc isn't authenticated and exposes urls of connections, so it's only
+	// served on unix sockets, where access is limited by file permissions
+	if *listenFlag != "" && !isUnixSocket(*listenFlag) {
+		return fmt.Errorf("--listen %q is not the path of a unix socket", *listenFlag)
+	}
+	if *httpFlag != "" {
+		if err := checkLoopback(*httpFlag); err != nil {
+			return err
+		}
+	}
+
+	logger := plugin.NewLogger(nil)
+	defer logger.Close()
+	serverLog := logger.With("server")
+
+	// endpoints of all editors and the http api modify the same handler
+	var mu sync.Mutex
+
+	owner := handler.New(nil, logger)
+	owner.SetLock(&mu)
+	defer owner.Close()
+
+	if *connectionsFlag != "" {
+		connections, err := readConnections(*connectionsFlag)
+		if err != nil {
+			return err
+		}
+		for _, params := range connections {
+			if _, err := owner.CreateConnection(params); err != nil {
+				serverLog.Errorf("could not open connection %q: %s", params.Name, err)
+			}
+		}
+	}
+
+	if *metricsFileFlag != "" {
+		go func() {
+			for range time.Tick(metricsInterval) {
+				if err := writeMetricsFile(*metricsFileFlag, owner, &mu); err != nil {
+					serverLog.Errorf("writeMetricsFile: %s", err)
+				}
+			}
+		}()
+	}
+
+	var httpServer *http.Server
+	if *httpFlag != "" {
+		token := *tokenFlag
+		if token == "" {
+			var err error
+			token, err = newHTTPToken()
+			if err != nil {
+				return err
+			}
+			log.Printf("http token: %s", token)
+		}
+
+		httpListener, err := net.Listen("tcp", *httpFlag)
+		if err != nil {
+			return fmt.Errorf("net.Listen: %w", err)
+		}
+		httpServer = &http.Server{
+			Handler:           &httpAPI{h: owner, mu: &mu, token: token},
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			if err := httpServer.Serve(httpListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serverLog.Errorf("httpServer.Serve: %s", err)
+			}
+		}()
+		log.Printf("serving http on %s", httpListener.Addr())
+	}
+
+	var listener net.Listener
+	if *listenFlag != "" {
+		var err error
+		listener, err = listenPrivateSocket(*listenFlag)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(*listenFlag)
+		log.Printf("listening on %s", *listenFlag)
+	}
+
+	// stop serving on interrupt, so the owner gets closed
+	stopped := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		if httpServer != nil {
+			httpServer.Close()
+		}
+		if listener != nil {
+			listener.Close()
+		}
+		close(stopped)
+	}()
+
+	if listener == nil {
+		<-stopped
+		return nil
+	}
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return fmt.Errorf("listener.Accept: %w", err)
+		}
+
+		go serveEditor(conn, owner, logger, &mu)
+	}
+}
+
+// isUnixSocket reports whether the listen address is a path of a unix socket.
+func isUnixSocket(address string) bool {
+	return strings.ContainsAny(address, `/\`)
+}
+
+// listenPrivateSocket listens on a unix socket at path which only the current
+// user can connect to. The socket is created in a private directory and moved
+// to path once its permissions are restricted, so nobody can connect before.
+// The socket is left at path when the listener is closed.
+func listenPrivateSocket(path string) (net.Listener, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".dbee-")
+	if err != nil {
+		return nil, fmt.Errorf("os.MkdirTemp: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "dbee.sock")
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: tmp, Net: "unix"})
+	if err != nil {
+		return nil, fmt.Errorf("net.ListenUnix: %w", err)
+	}
+	listener.SetUnlinkOnClose(false)
+
+	if err := os.Chmod(tmp, 0o600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("os.Chmod: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("os.Rename: %w", err)
+	}
+
+	return listener, nil
+}
+
+// removeStaleSocket removes the socket of a previous server at path. Files
+// other than sockets are left alone.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("os.Lstat: %w", err)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%q exists and is not a socket", path)
+	}
+	return os.Remove(path)
+}
+
+// writeMetricsFile replaces the file at path with current metrics of the
+// backend, so collectors never read a partially written file.
+func writeMetricsFile(path string, owner *handler.Handler, mu sync.Locker) error {
+	var b bytes.Buffer
+	mu.Lock()
+	err := owner.WriteMetrics(&b)
+	mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("owner.WriteMetrics: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("os.WriteFile: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// serveEditor serves endpoints to an editor connected to the shared backend.
+func serveEditor(conn net.Conn, owner *handler.Handler, logger *plugin.Logger, mu *sync.Mutex) {
+	defer conn.Close()
+
+	v, err := nvim.New(conn, conn, conn, log.Printf)
+	if err != nil {
+		logger.With("server").Errorf("nvim.New: %s", err)
+		return
+	}
+
+	p := plugin.New(v, logger)
+	p.SetLock(mu)
+
+	session := handler.NewSession(v, logger, owner)
+	mountEndpoints(p, session)
+	p.SetBackendInfo(backendInfo(session, true))
+	p.RegisterProtocol()
+
+	if err := v.Serve(); err != nil {
+		logger.With("server").Infof("v.Serve: %s", err)
+	}
+}
@@ -1,6 +1,7 @@
 local install = require("dbee.install")
 local api = require("dbee.api")
 local config = require("dbee.config")
+local utils = require("dbee.utils")
 
 ---@toc dbee.ref.contents
 
@@ -76,9 +77,41 @@ function dbee.execute(query)
   dbee.open()
 end
 
+---Subscribe to a channel of the current connection (see
+---api.core.connection_subscribe) and show events in result UI as they arrive.
+---The subscription is closed by canceling the call (e.g. with <C-c> in result UI).
+---@param channel string
+function dbee.subscribe(channel)
+  local conn = api.core.get_current_connection()
+  if not conn then
+    error("no connection currently selected")
+  end
+
+  local call = api.core.connection_subscribe(conn.id, channel)
+  api.ui.result_set_call(call)
+
+  -- result UI is drawn once when rows start arriving, refresh it with new events
+  local timer = vim.loop.new_timer()
+  timer:start(
+    1000,
+    1000,
+    vim.schedule_wrap(function()
+      local current = api.ui.result_get_call()
+      if not current or current.id ~= call.id or current.state ~= "retrieving" then
+        timer:stop()
+        timer:close()
+        return
+      end
+      pcall(api.ui.result_page_current)
+    end)
+  )
+
+  dbee.open()
+end
+
 ---Store currently displayed result.
 ---Convenience wrapper around some api functions.
----@param format string format of the output -> "csv"|"json"|"table"
+---@param format string format of the output -> "csv"|"json"|"geojson"|"insert"|"table"
 ---@param output string where to pipe the results -> "file"|"yank"|"buffer"
 ---@param opts { from: integer, to: integer, extra_arg: any }
 function dbee.store(format, output, opts)
@@ -90,6 +123,202 @@ function dbee.store(format, output, opts)
   api.core.call_store_result(call.id, format, output, opts)
 end
 
+---Export currently displayed result to a file in the background.
+---Progress is shown in the command line and a message is logged when the
+---export is done.
+---Convenience wrapper around some api functions.
+---@param format string format of the output -> "csv"|"json"|"geojson"|"insert"|"table"
+---@param path string
+---@param opts? { from: integer, to: integer }
+---@return integer export_id can be passed to api.core.export_cancel()
+function dbee.export(format, path, opts)
+  opts = opts or {}
+  local call = api.ui.result_get_call()
+  if not call then
+    error("no current call to export")
+  end
+
+  path = vim.fn.fnamemodify(vim.fn.expand(path), ":p")
+  return api.core.call_export(call.id, { format = format, path = path, from = opts.from, to = opts.to }, {
+    on_progress = function(data)
+      vim.api.nvim_echo({ { string.format("dbee: exported %d/%d rows", data.rows, data.rows_total) } }, false, {})
+    end,
+    on_finish = function(data)
+      if data.canceled then
+        utils.log("info", "export canceled: " .. data.path, "export")
+      elseif data.error then
+        utils.log("error", "export failed: " .. data.error, "export")
+      else
+        utils.log("info", "exported to " .. data.path, "export")
+      end
+    end,
+  })
+end
+
+---Get notified when the currently displayed call completes or fails (see the
+---notifier config option), e.g. to switch buffers while a long query runs.
+function dbee.notify()
+  local call = api.ui.result_get_call()
+  if not call then
+    error("no current call to notify about")
+  end
+
+  api.core.call_notify(call.id)
+end
+
+---Show recent entries of the log of the backend in a new split.
+---@param level? string minimum level ("debug", "info", "warn" or "error")
+function dbee.logs(level)
+  local lines = {}
+  for _, entry in ipairs(api.core.get_logs({ level = level })) do
+    local attrs = {}
+    for key, value in pairs(entry.attrs or {}) do
+      table.insert(attrs, key .. "=" .. value)
+    end
+    table.sort(attrs)
+    local line = string.format(
+      "%s %-5s %-8s %s",
+      os.date("%H:%M:%S", math.floor(entry.timestamp_us / 1000000)),
+      entry.level:upper(),
+      entry.subsystem,
+      entry.message
+    )
+    if #attrs > 0 then
+      line = line .. " " .. table.concat(attrs, " ")
+    end
+    for _, l in ipairs(vim.split(line, "\n", { plain = true })) do
+      table.insert(lines, l)
+    end
+  end
+
+  vim.cmd("new")
+  local bufnr = vim.api.nvim_get_current_buf()
+  vim.api.nvim_buf_set_name(bufnr, "dbee-logs-" .. bufnr)
+  vim.api.nvim_buf_set_lines(bufnr, 0, -1, false, lines)
+  vim.bo[bufnr].buftype = "nofile"
+  vim.bo[bufnr].bufhidden = "wipe"
+  vim.bo[bufnr].modifiable = false
+  vim.api.nvim_win_set_cursor(0, { math.max(#lines, 1), 0 })
+end
+
+---Show calls recorded in the slow query log in a new split, from the newest one.
+---@param conn_id? connection_id show slow queries of a single connection
+function dbee.slow_queries(conn_id)
+  local lines = {}
+  ---@param node PlanNode
+  ---@param depth integer
+  local function add_plan(node, depth)
+    local line = string.rep("  ", depth + 2) .. "-> " .. node.type
+    if node.relation ~= "" then
+      line = line .. " on " .. node.relation
+    end
+    table.insert(lines, string.format("%s (cost=%.2f rows=%d)", line, node.cost, node.rows))
+    for _, child in ipairs(node.children or {}) do
+      add_plan(child, depth + 1)
+    end
+  end
+
+  for _, entry in ipairs(api.core.get_slow_queries({ conn_id = conn_id })) do
+    table.insert(
+      lines,
+      string.format(
+        "%s %10.1fms %-10s %s",
+        os.date("%Y-%m-%d %H:%M:%S", math.floor(entry.timestamp_us / 1000000)),
+        entry.duration_ms,
+        entry.state,
+        entry.connection
+      )
+    )
+    for _, l in ipairs(vim.split(entry.query, "\n", { plain = true })) do
+      table.insert(lines, "  " .. l)
+    end
+    if entry.error ~= "" then
+      table.insert(lines, "  error: " .. entry.error)
+    end
+    if entry.plan then
+      add_plan(entry.plan, 0)
+    elseif entry.plan_error ~= "" then
+      table.insert(lines, "  plan error: " .. entry.plan_error)
+    end
+    table.insert(lines, "")
+  end
+
+  vim.cmd("new")
+  local bufnr = vim.api.nvim_get_current_buf()
+  vim.api.nvim_buf_set_name(bufnr, "dbee-slow-queries-" .. bufnr)
+  vim.api.nvim_buf_set_lines(bufnr, 0, -1, false, lines)
+  vim.bo[bufnr].buftype = "nofile"
+  vim.bo[bufnr].bufhidden = "wipe"
+  vim.bo[bufnr].modifiable = false
+end
+
+---Show failed calls recorded in the error history in a new split, from the
+---newest one.
+---@param conn_id? connection_id show failed calls of a single connection
+function dbee.failed_queries(conn_id)
+  local lines = {}
+  for _, entry in ipairs(api.core.get_failed_queries({ conn_id = conn_id })) do
+    local call = entry.call
+    table.insert(
+      lines,
+      string.format(
+        "%s %10.1fms %-18s %s",
+        os.date("%Y-%m-%d %H:%M:%S", math.floor(call.timestamp_us / 1000000)),
+        call.time_taken_us / 1000,
+        call.state,
+        entry.connection
+      )
+    )
+    for _, l in ipairs(vim.split(call.query, "\n", { plain = true })) do
+      table.insert(lines, "  " .. l)
+    end
+    for _, l in ipairs(vim.split(call.error or "", "\n", { plain = true, trimempty = true })) do
+      table.insert(lines, "  error: " .. l)
+    end
+    if call.error_info and call.error_info.hint ~= "" then
+      table.insert(lines, "  hint: " .. call.error_info.hint)
+    end
+    table.insert(lines, "")
+  end
+
+  vim.cmd("new")
+  local bufnr = vim.api.nvim_get_current_buf()
+  vim.api.nvim_buf_set_name(bufnr, "dbee-failed-queries-" .. bufnr)
+  vim.api.nvim_buf_set_lines(bufnr, 0, -1, false, lines)
+  vim.bo[bufnr].buftype = "nofile"
+  vim.bo[bufnr].bufhidden = "wipe"
+  vim.bo[bufnr].modifiable = false
+end
+
+---Show the most frequent queries of the current connection in a new split.
+---Calls of a query which differ only in values are counted together.
+---@param limit? integer number of queries (20 by default)
+function dbee.top_queries(limit)
+  local conn = api.core.get_current_connection()
+  if not conn then
+    error("no connection currently selected")
+  end
+
+  local lines = {}
+  local stats = api.core.connection_get_query_stats(conn.id, { order = "frequency", limit = tonumber(limit) or 20 })
+  for _, s in ipairs(stats) do
+    table.insert(
+      lines,
+      string.format("%6d calls %10.1fms avg %4d failed", s.count, s.average_time_us / 1000, s.failed)
+    )
+    table.insert(lines, "  " .. s.fingerprint)
+    table.insert(lines, "")
+  end
+
+  vim.cmd("new")
+  local bufnr = vim.api.nvim_get_current_buf()
+  vim.api.nvim_buf_set_name(bufnr, "dbee-top-queries-" .. bufnr)
+  vim.api.nvim_buf_set_lines(bufnr, 0, -1, false, lines)
+  vim.bo[bufnr].buftype = "nofile"
+  vim.bo[bufnr].bufhidden = "wipe"
+  vim.bo[bufnr].modifiable = false
+end
+
 ---Supported install commands.
 ---@alias install_command
 ---| '"wget"'
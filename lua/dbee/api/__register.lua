@@ -1,9 +1,15 @@
 -- This file is automatically generated using "dbee -manifest <file>"
 -- DO NOT EDIT!
 
-return function()
+---@param opts? { server?: string } address of a running "dbee serve" to connect to
+return function(opts)
+  opts = opts or {}
+
   -- Register host
   vim.fn["remote#host#Register"]("nvim_dbee", "x", function()
+    if opts.server then
+      return vim.fn.sockconnect(opts.server:find("/") and "pipe" or "tcp", opts.server, { rpc = true })
+    end
     return vim.fn.jobstart({ "dbee" }, {
       rpc = true,
       detach = true,
@@ -18,21 +24,122 @@ return function()
   -- Manifest
   vim.fn["remote#host#RegisterPlugin"]("nvim_dbee", "0", {
     { type = "function", name = "DbeeAddHelpers", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeAddSnippet", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeCallCancel", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeCallCompareResult", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeCallDisplayResult", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeCallExplainError", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeCallExport", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeCallFlattenJSON", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeCallFormatJSONCell", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeCallGetChecksum", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeCallGetJSONColumns", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeCallGetMeta", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeCallGetTableLayout", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeCallNotify", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeCallStoreResult", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeCancelAll", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeClearFailedQueries", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeClearSlowQueries", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionApplyMigration", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionBeginTransaction", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionCallProcedure", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionCommitTransaction", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionDetachPartition", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionDropPartition", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionDumpLayout", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionEstimateCost", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeConnectionExecute", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionExecuteSnippet", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionExplain", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionExportSchema", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionFormat", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGenerateEdits", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetActivity", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeConnectionGetCalls", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetCapabilities", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeConnectionGetColumns", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetCompletion", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetConstraints", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetDefinition", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetDependencies", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetForeignObjects", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetForeignServers", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetGrants", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeConnectionGetHelpers", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetIndexes", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetJSONPath", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetMigrations", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetObjectActions", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeConnectionGetParams", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetPartitions", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetQueryStats", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetRoles", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetSavepoints", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetScaffolds", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetServerInfo", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetSignatures", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetStatementAt", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeConnectionGetStructure", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetStructureChildren", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetTableStats", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetTriggers", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionImport", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionKillSession", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionLint", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeConnectionListDatabases", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionRefreshMaterializedView", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionRefreshStructure", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionRenderSnippet", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionRollbackMigration", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionRollbackToSavepoint", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionRollbackTransaction", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionRunObjectAction", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionSavepoint", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionScheduleQuery", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionSearchObjects", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionSeed", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeConnectionSelectDatabase", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionSetAutoCommit", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionSetComment", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionSplitStatements", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionSubscribe", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionUploadBlob", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionWatchQuery", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionWatchTable", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionsCompareSchemas", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionsCompareTableData", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionsExecute", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionsExport", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionsFederate", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeCreateConnection", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeDeleteConnection", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeExportCancel", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeGetAdapters", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeGetConnections", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeGetCurrentConnection", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeGetFailedQueries", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeGetLogs", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeGetQueryStatus", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeGetSchedules", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeGetSlowQueries", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeGetSnippets", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeGetWatches", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeImportConnections", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeNegotiate", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeReadConnectionBundle", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeRegisterAdapter", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeRemoveSnippet", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeRequest", sync = false, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeScheduleCancel", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeSetAmbiguousWidth", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeSetAuditLog", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeSetCurrentConnection", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeSetErrorHistory", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeSetLogOptions", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeSetNotifier", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeSetSlowQueryLog", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeSetSnippetsFile", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeWatchCancel", sync = true, opts = vim.empty_dict() },
   })
 end
@@ -25,6 +25,80 @@ function core.register_event_listener(event, listener)
   state.handler():register_event_listener(event, listener)
 end
 
+---Get calls which took longer than the threshold of the slow query log
+---(see the slow_query_log config option), from the newest one.
+---@param opts? { conn_id: connection_id, limit: integer } all connections and all queries by default
+---@return SlowQuery[]
+function core.get_slow_queries(opts)
+  return state.handler():get_slow_queries(opts)
+end
+
+---Remove all entries of the slow query log.
+function core.clear_slow_queries()
+  state.handler():clear_slow_queries()
+end
+
+---Get failed calls recorded in the error history (see the error_history
+---config option), from the newest one.
+---@param opts? { conn_id: connection_id, limit: integer } all connections and all records by default
+---@return FailedQuery[]
+function core.get_failed_queries(opts)
+  return state.handler():get_failed_queries(opts)
+end
+
+---Remove records of the connection from the error history (all records if
+---conn_id is nil).
+---@param conn_id? connection_id
+function core.clear_failed_queries(conn_id)
+  state.handler():clear_failed_queries(conn_id)
+end
+
+---Get recent entries of the log of the backend, from the oldest one.
+---@param opts? { level: string, subsystem: string, limit: integer } minimum level (default "debug"), a single subsystem (e.g. "rpc", "handler") and the number of the most recent entries
+---@return LogEntry[]
+function core.get_logs(opts)
+  return state.handler():get_logs(opts)
+end
+
+---Get the number of running queries and the duration and error of the last
+---finished one (e.g. for statuslines). The status is cached and updated by
+---"query_started" and "query_finished" events, which are also fired as
+---"DbeeQueryStarted" and "DbeeQueryFinished" User autocommands.
+---@return QueryStatus
+function core.get_query_status()
+  return state.handler():get_query_status()
+end
+
+---Get the protocol negotiated with the backend (nil if the backend is too old to negotiate).
+---@return Protocol|nil
+function core.get_protocol()
+  return state.handler():get_protocol()
+end
+
+---Get the build of the backend: version, compiled-in adapters and features
+---(nil if the backend doesn't report it).
+---@return BackendInfo|nil
+function core.get_backend_info()
+  return state.handler():get_backend_info()
+end
+
+---Returns true if the backend serves the endpoint (e.g. "DbeeConnectionGetColumns").
+---@param name string
+---@return boolean
+function core.has_capability(name)
+  return state.handler():has_capability(name)
+end
+
+---Call a backend endpoint without blocking the editor. The callback receives
+---the result or an error message.
+---@param method string name of the endpoint
+---@param args any[] arguments of the endpoint
+---@param cb rpc_callback
+---@return integer request_id
+function core.request(method, args, cb)
+  return state.handler():request(method, args, cb)
+end
+
 ---Add new source and load connections from it.
 ---@param source Source
 function core.add_source(source)
@@ -116,16 +190,45 @@ end
 ---Execute a query on a connection.
 ---@param id connection_id
 ---@param query string
+---@param opts? { confirmed: boolean, sample: SampleOpts } set sample for a quick preview of a sample of rows
 ---@return CallDetails
-function core.connection_execute(id, query)
-  return state.handler():connection_execute(id, query)
+function core.connection_execute(id, query, opts)
+  return state.handler():connection_execute(id, query, opts)
+end
+
+---Estimate the cost of a query without running it (databases billing scanned
+---bytes, e.g. BigQuery).
+---@param id connection_id
+---@param query string
+---@return CostEstimate
+function core.connection_estimate_cost(id, query)
+  return state.handler():connection_estimate_cost(id, query)
 end
 
 ---Get database structure of a connection.
 ---@param id connection_id
+---@param opts? StructureOpts
+---@return DBStructure[]
+function core.connection_get_structure(id, opts)
+  return state.handler():connection_get_structure(id, opts)
+end
+
+---Get children of a lazy structure node (nodes with the "lazy" field set).
+---@param id connection_id
+---@param parent DBStructure
 ---@return DBStructure[]
-function core.connection_get_structure(id)
-  return state.handler():connection_get_structure(id)
+function core.connection_get_structure_children(id, parent)
+  return state.handler():connection_get_structure_children(id, parent)
+end
+
+---Search the database for tables, views, columns and functions by name.
+---Useful for feeding fuzzy pickers with objects of large databases.
+---@param id connection_id
+---@param pattern string matched case insensitively anywhere in the name (LIKE wildcards are allowed)
+---@param limit? integer maximum number of matches (default 200)
+---@return ObjectMatch[]
+function core.connection_search_objects(id, pattern, limit)
+  return state.handler():connection_search_objects(id, pattern, limit)
 end
 
 ---Get columns of a table
@@ -136,6 +239,178 @@ function core.connection_get_columns(id, opts)
   return state.handler():connection_get_columns(id, opts)
 end
 
+---Compare tables, columns and indexes of two connections (e.g. staging and
+---production). The diff describes the target relative to the source.
+---@param source_id connection_id
+---@param target_id connection_id
+---@param opts? CompareOpts
+---@return SchemaDiff
+function core.connections_compare_schemas(source_id, target_id, opts)
+  return state.handler():connections_compare_schemas(source_id, target_id, opts)
+end
+
+---Compare rows of a table on two connections (e.g. a primary and its replica,
+---or a database before and after a migration). The diff describes the target
+---relative to the source.
+---@param source_id connection_id
+---@param target_id connection_id
+---@param opts TableCompareOpts
+---@return TableDataDiff
+function core.connections_compare_table_data(source_id, target_id, opts)
+  return state.handler():connections_compare_table_data(source_id, target_id, opts)
+end
+
+---Run a federated query, which joins data of different connections (e.g.
+---postgres and mysql, or a history record and a live table). Every table is
+---filled with rows of a query on a connection or of a result set of a call
+---and copied into an embedded engine, where the query runs. The engine is an
+---in-memory sqlite database by default, opts.engine can be a sqlite, scratch
+---or demo connection instead. Tables are loaded again on every call.
+---@param tables FederatedTable[]
+---@param query string query in the sqlite dialect
+---@param opts? { engine: connection_id }
+---@return CallDetails
+---@usage lua [[
+---core.connections_federate({
+---  { name = "users", conn_id = "pg", query = "SELECT id, email FROM users" },
+---  { name = "orders", call_id = "<id of a call of the history>" },
+---}, "SELECT u.email, COUNT(*) FROM users u JOIN orders o ON o.user_id = u.id GROUP BY 1")
+---@usage ]]
+function core.connections_federate(tables, query, opts)
+  return state.handler():connections_federate(tables, query, opts)
+end
+
+---Export connections to a file which can be shared with a team. Credentials
+---in URLs are replaced by {{ secret "name" }} placeholders (opts.secrets =
+---"strip", the default) or by {{ env "DBEE_NAME" }} references (opts.secrets
+---= "env"). Credentials which already are templates are kept.
+---@param ids connection_id[]
+---@param path string
+---@param opts? { secrets: "strip"|"env" }
+---@return ConnectionBundle
+function core.connections_export(ids, path, opts)
+  return state.handler():connections_export(ids, path, opts)
+end
+
+---Import connections exported by connections_export into a source (which
+---must support adding connections). Secrets which aren't set in opts.secrets
+---(by name) or in the environment are prompted for. A prompted value can be a
+---template itself (e.g. {{ exec "pass show db/prod" }}), empty values keep
+---the placeholder.
+---@param path string
+---@param source_id source_id
+---@param opts? { secrets: table<string, string> }
+---@return connection_id[]
+function core.import_connections(path, source_id, opts)
+  opts = opts or {}
+  local secrets = vim.deepcopy(opts.secrets or {})
+
+  local bundle = state.handler():read_connection_bundle(path)
+  for _, secret in ipairs(bundle.missing or {}) do
+    if secrets[secret.name] == nil then
+      secrets[secret.name] = vim.fn.inputsecret(string.format("Secret %q of %s: ", secret.name, secret.connection_id))
+    end
+  end
+
+  return state.handler():import_connections(path, source_id, secrets)
+end
+
+---Export definitions of objects of a connection (similar to a schema-only dump).
+---Objects whose definitions can't be retrieved are listed in the skipped field.
+---@param id connection_id
+---@param path string file (or directory with opts.split)
+---@param opts? SchemaExportOpts
+---@return SchemaExport
+function core.connection_export_schema(id, path, opts)
+  return state.handler():connection_export_schema(id, path, opts)
+end
+
+---Dump the whole structure of a connection (ids, types, columns, comments, ...)
+---as a JSON document for external tools or test fixtures. The document has
+---a "version" field which changes only on incompatible changes.
+---@param id connection_id
+---@return string json
+function core.connection_dump_layout(id)
+  return state.handler():connection_dump_layout(id)
+end
+
+---Get schemas, tables, views, routines, columns and keywords for completion
+---of queries (e.g. in a nvim-cmp source). Loaded objects are cached, so only
+---parts of the structure which weren't requested before are loaded.
+---@param id connection_id
+---@param opts? CompletionOpts
+---@return CompletionItem[]
+function core.connection_get_completion(id, opts)
+  return state.handler():connection_get_completion(id, opts)
+end
+
+---Get overloads of a function or a procedure with their parameters, return
+---types and comments (e.g. for signature help while writing a call).
+---@param id connection_id
+---@param opts { table: string, schema?: string } table is the name of the function, all schemas if schema is nil
+---@return Signature[]
+function core.connection_get_signatures(id, opts)
+  return state.handler():connection_get_signatures(id, opts)
+end
+
+---List adapters which can be used as connection types: builtin ones and
+---the ones registered with the "adapters" config option.
+---@return AdapterInfo[]
+function core.get_adapters()
+  return state.handler():get_adapters()
+end
+
+---Get features supported by the driver of the connection
+---(e.g. "transactions", "cancel" or "definitions").
+---@param id connection_id
+---@return string[] capabilities
+function core.connection_get_capabilities(id)
+  return state.handler():connection_get_capabilities(id)
+end
+
+---Returns true if the driver of the connection supports the capability (see
+---|core.connection_get_capabilities|). Results are cached per connection.
+---@param id connection_id
+---@param capability string
+---@return boolean
+function core.connection_supports(id, capability)
+  return state.handler():connection_supports(id, capability)
+end
+
+---Export a result set of the call to a file without blocking the editor.
+---Callbacks receive data of "export_progress" and "export_finished" events.
+---@param id call_id
+---@param opts { format: store_format, path: string, from: integer, to: integer, set: integer } from, to and set default to the whole first result set
+---@param callbacks? export_callbacks
+---@return integer export_id
+function core.call_export(id, opts, callbacks)
+  return state.handler():call_export(id, opts, callbacks)
+end
+
+---Cancel an export started with |core.call_export|.
+---@param export_id integer
+function core.export_cancel(export_id)
+  state.handler():export_cancel(export_id)
+end
+
+---Format statements in text using the dialect of the connection.
+---@param id connection_id
+---@param text string
+---@param opts? { indent: string, keyword_case: "upper"|"lower"|"preserve" } indent defaults to two spaces, keyword_case to "upper"
+---@return string formatted
+function core.connection_format(id, text, opts)
+  return state.handler():connection_format(id, text, opts)
+end
+
+---Lint statements in text: DELETE and UPDATE without WHERE, tables which
+---don't exist in the cached structure and ambiguous columns of joined tables.
+---@param id connection_id
+---@param text string
+---@return LintDiagnostic[]
+function core.connection_lint(id, text)
+  return state.handler():connection_lint(id, text)
+end
+
 ---Get parameters that define the connection.
 ---@param id connection_id
 ---@return ConnectionParams|nil
@@ -162,6 +437,51 @@ function core.connection_select_database(id, database)
   state.handler():connection_select_database(id, database)
 end
 
+---Insert generated rows into a table (e.g. to populate an empty development
+---database). Returns a call which finishes when the rows are inserted.
+---@param id connection_id
+---@param table string
+---@param opts? SeedOpts
+---@return CallDetails
+function core.connection_seed(id, table, opts)
+  return state.handler():connection_seed(id, table, opts)
+end
+
+---Write a local file into a binary column (e.g. an image or a document).
+---Updates the row identified by opts.key or inserts a new one with opts.values.
+---@param id connection_id
+---@param opts BlobUploadOpts
+---@return integer written bytes
+function core.connection_upload_blob(id, opts)
+  return state.handler():connection_upload_blob(id, opts)
+end
+
+---Get migrations (goose, golang-migrate or dbmate) with their status on a
+---connection. They are read from the migrations_dir of the connection or
+---discovered in root (defaults to the working directory).
+---@param id connection_id
+---@param root? string
+---@return MigrationStatus
+function core.connection_get_migrations(id, root)
+  return state.handler():connection_get_migrations(id, root)
+end
+
+---Apply a pending migration. golang-migrate migrations are applied in order.
+---@param id connection_id
+---@param version string
+---@param root? string
+function core.connection_apply_migration(id, version, root)
+  state.handler():connection_apply_migration(id, version, root)
+end
+
+---Roll back the latest applied migration.
+---@param id connection_id
+---@param version string
+---@param root? string
+function core.connection_rollback_migration(id, version, root)
+  state.handler():connection_rollback_migration(id, version, root)
+end
+
 ---Get a list of past calls of a connection.
 ---@param id connection_id
 ---@return CallDetails[]
@@ -169,6 +489,65 @@ function core.connection_get_calls(id)
   return state.handler():connection_get_calls(id)
 end
 
+---Get the call log of a connection grouped by query fingerprints: queries
+---with literals replaced by "?" (e.g. "SELECT * FROM users WHERE id = ?"), so
+---repeated calls of a query with different values are a single entry with the
+---number of calls. The top 20 queries are returned with
+---{ order = "frequency", limit = 20 }.
+---@param id connection_id
+---@param opts? { order: "recent"|"frequency", pattern: string, limit: integer } newest entries first and all of them by default, pattern is matched fuzzily against fingerprints
+---@return QueryStats[]
+function core.connection_get_query_stats(id, opts)
+  return state.handler():connection_get_query_stats(id, opts)
+end
+
+---Watch a query: it's executed every interval and its result is compared with
+---the previous one. The first result and every change are pushed with the
+---"watch_updated" event (e.g. to monitor job queues or status tables), runs
+---which return the same result are silent. Each run is a regular call.
+---@param id connection_id
+---@param query string
+---@param opts watch_options
+---@return WatchDetails
+function core.connection_watch_query(id, query, opts)
+  return state.handler():connection_watch_query(id, query, opts)
+end
+
+---Watch rows of a table (see connection_watch_query). Rows are identified by
+---the primary key of the table, unless key_columns are provided.
+---@param id connection_id
+---@param schema string
+---@param table string
+---@param opts watch_options
+---@return WatchDetails
+function core.connection_watch_table(id, schema, table, opts)
+  return state.handler():connection_watch_table(id, schema, table, opts)
+end
+
+---Stop a watched query or table.
+---@param id watch_id
+function core.watch_cancel(id)
+  state.handler():watch_cancel(id)
+end
+
+---Get all active watches.
+---@return WatchDetails[]
+function core.get_watches()
+  return state.handler():get_watches()
+end
+
+---Subscribe to a channel of the database: LISTEN channels of postgres,
+---change streams of a mongo collection (or of the current database if the
+---channel is empty) and pub/sub channels of redis (glob patterns are allowed).
+---Multiple channels are separated by spaces. Every event is appended as a row
+---to the call's result until the call is canceled with call_cancel.
+---@param id connection_id
+---@param channel string
+---@return CallDetails
+function core.connection_subscribe(id, channel)
+  return state.handler():connection_subscribe(id, channel)
+end
+
 ---Cancel call execution.
 ---If call is finished, nothing happens.
 ---@param id call_id
@@ -176,21 +555,120 @@ function core.call_cancel(id)
   state.handler():call_cancel(id)
 end
 
+---Get notified when the call completes or fails (right away if it's already
+---finished), e.g. to switch buffers while a long query runs. Notifiers are
+---set with the notifier config option.
+---@param id call_id
+---@param enabled? boolean false removes the mark (default true)
+function core.call_notify(id, enabled)
+  state.handler():call_notify(id, enabled)
+end
+
+---Cancel all in-flight work: running calls, result exports and history writes.
+---Useful when the backend seems stuck.
+---@param timeout_ms? integer how long to wait for the work to stop (default 5000)
+---@return integer number of canceled operations
+function core.cancel_all(timeout_ms)
+  return state.handler():cancel_all(timeout_ms)
+end
+
+---Explain the error of a failed call: hints of the database, names of
+---existing objects similar to a missing one (from the cached structure) and
+---queries of other sessions involved in a deadlock (if they are visible).
+---@param id call_id id of the call
+---@return ErrorHint[]
+function core.call_explain_error(id)
+  return state.handler():call_explain_error(id)
+end
+
 ---Display the result of a call formatted as a table in a buffer.
 ---@param id call_id id of the call
 ---@param bufnr integer
 ---@param from integer
 ---@param to integer
+---@param set? integer index of the result set (defaults to 0)
 ---@return integer total number of rows
-function core.call_display_result(id, bufnr, from, to)
-  return state.handler():call_display_result(id, bufnr, from, to)
+function core.call_display_result(id, bufnr, from, to, set)
+  return state.handler():call_display_result(id, bufnr, from, to, set)
+end
+
+---Get the layout of the table which call_display_result displays for the same
+---rows: display widths of columns and positions of cells in the buffer.
+---@param id call_id id of the call
+---@param from integer
+---@param to integer
+---@param set? integer index of the result set (defaults to 0)
+---@return TableLayout
+function core.call_get_table_layout(id, from, to, set)
+  return state.handler():call_get_table_layout(id, from, to, set)
+end
+
+---Get a checksum of the result of a call. Results with the same header and
+---rows have the same checksum.
+---@param id call_id
+---@param opts? { set: integer, ignore_order: boolean } set defaults to 0, with ignore_order the order of rows doesn't matter
+---@return string checksum
+function core.call_get_checksum(id, opts)
+  return state.handler():call_get_checksum(id, opts)
+end
+
+---Compare the result of a call with the result of a baseline call (e.g. a call
+---of the original query from history), to verify that a refactored query
+---returns the same data.
+---@param id call_id
+---@param baseline_id call_id
+---@param opts? ResultCompareOpts
+---@return ResultComparison
+function core.call_compare_result(id, baseline_id, opts)
+  return state.handler():call_compare_result(id, baseline_id, opts)
+end
+
+---Get names of columns of a call's result which hold json documents (objects
+---or arrays), detected from values of the first rows.
+---@param id call_id
+---@param set? integer index of the result set (defaults to 0)
+---@return string[] columns
+function core.call_get_json_columns(id, set)
+  return state.handler():call_get_json_columns(id, set)
+end
+
+---Pretty print the json document in a cell of a call's result.
+---@param id call_id
+---@param row integer index of the row
+---@param column integer index of the column
+---@param set? integer index of the result set (defaults to 0)
+---@return string json
+function core.call_format_json_cell(id, row, column, set)
+  return state.handler():call_format_json_cell(id, row, column, set)
+end
+
+---Flatten values at paths of a json column into columns. The flattened result
+---is a new call of the same connection, with a column named "<column>.<path>"
+---for each path (NULL where the path doesn't exist).
+---@param id call_id
+---@param column string name of the json column
+---@param paths string[] dot separated keys with array indexes in brackets (e.g. "address.city" or "tags[0]")
+---@param set? integer index of the result set (defaults to 0)
+---@return CallDetails
+function core.call_flatten_json(id, column, paths, set)
+  return state.handler():call_flatten_json(id, column, paths, set)
+end
+
+---Get the expression which extracts the value at a path of a json column in the
+---dialect of the connection (e.g. `"data" -> 'address' ->> 'city'` on postgres).
+---@param id connection_id
+---@param column string name of the json column
+---@param path string path of the value (e.g. "address.city" or "tags[0]")
+---@return string expression
+function core.connection_get_json_path(id, column, path)
+  return state.handler():connection_get_json_path(id, column, path)
 end
 
 ---Store the result of a call.
 ---@param id call_id
----@param format string format of the output -> "csv"|"json"|"table"
+---@param format string format of the output -> "csv"|"json"|"geojson"|"insert"|"table"
 ---@param output string where to pipe the results -> "file"|"yank"|"buffer"
----@param opts { from: integer, to: integer, extra_arg: any }
+---@param opts { from: integer, to: integer, extra_arg: any, set: integer }
 function core.call_store_result(id, format, output, opts)
   state.handler():call_store_result(id, format, output, opts)
 end
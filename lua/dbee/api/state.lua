@@ -5,6 +5,7 @@ local ResultUI = require("dbee.ui.result")
 local CallLogUI = require("dbee.ui.call_log")
 local Handler = require("dbee.handler")
 local install = require("dbee.install")
+local utils = require("dbee.utils")
 local register = require("dbee.api.__register")
 
 -- public and private module objects
@@ -29,14 +30,38 @@ local function setup_handler()
     error("setup() has not been called yet")
   end
 
-  -- register remote plugin
-  register()
+  -- register remote plugin (connect to a shared backend if configured)
+  register({ server = m.config.server })
 
   -- add install binary to path
   vim.env.PATH = install.dir() .. ":" .. vim.env.PATH
 
-  m.handler = Handler:new(m.config.sources)
+  m.handler = Handler:new(m.config.sources, m.config.adapters)
   m.handler:add_helpers(m.config.extra_helpers)
+  local ok, err = pcall(m.handler.set_log_options, m.handler, m.config.log)
+  if not ok then
+    utils.log("warn", "invalid log options: " .. tostring(err), "core")
+  end
+  m.handler:set_slow_query_log(m.config.slow_query_log)
+  m.handler:set_notifier(m.config.notifier)
+  local ok_history, err_history = pcall(m.handler.set_error_history, m.handler, m.config.error_history)
+  if not ok_history then
+    utils.log("warn", "error history is not available: " .. tostring(err_history), "core")
+  end
+  -- result tables are aligned by display widths of cells in the editor
+  m.handler:set_ambiguous_width(vim.o.ambiwidth == "double")
+  vim.api.nvim_create_autocmd("OptionSet", {
+    pattern = "ambiwidth",
+    callback = function()
+      m.handler:set_ambiguous_width(vim.o.ambiwidth == "double")
+    end,
+  })
+  if m.config.audit_log then
+    m.handler:set_audit_log(m.config.audit_log)
+  end
+  if m.config.snippets_file then
+    pcall(m.handler.set_snippets_file, m.handler, m.config.snippets_file)
+  end
 
   -- activate default connection if present
   if m.config.default_connection then
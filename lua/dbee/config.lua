@@ -7,6 +7,14 @@ local config = {}
 ---@field default_connection? string
 ---@field sources? Source[] list of connection sources
 ---@field extra_helpers? table<string, table<string, string>>
+---@field audit_log? string path of the audit log of executed statements
+---@field log? log_config log of the backend
+---@field slow_query_log? slow_query_log_config log of calls which took long
+---@field error_history? error_history_config history of failed calls
+---@field notifier? notifier_config notifications of finished calls
+---@field snippets_file? string path of the file where query snippets are stored
+---@field server? string address of a shared backend started with "dbee serve"
+---@field adapters? table<string, external_adapter> external adapters per connection type
 ---@field float_options? table<string, any>
 ---@field drawer? drawer_config
 ---@field editor? editor_config
@@ -19,6 +27,29 @@ local config = {}
 ---@field icon_highlight string
 ---@field text_highlight string
 
+---Log of the backend. Levels are "debug", "info", "warn" or "error", levels of
+---subsystems ("rpc", "handler", "events", "server") override the global level.
+---@alias log_config { level: string, levels: table<string, string>, format: "text"|"json", path?: string, max_size_mb: integer, max_backups: integer, ring_size: integer }
+
+---Log of slow calls. Calls which take at least threshold_ms are recorded
+---(0 disables the log), with plans of single statements if explain is set.
+---@alias slow_query_log_config { threshold_ms: integer, explain: boolean, path?: string }
+
+---History of failed calls, kept separately from the call log. The newest
+---max_entries records (500 if 0) not older than max_age_days (any age if 0)
+---are kept.
+---@alias error_history_config { enabled: boolean, max_entries: integer, max_age_days: integer }
+
+---Notifiers of calls marked with require("dbee").notify(). The command is run
+---with the message as its last argument (details are in DBEE_* environment
+---variables), the webhook receives the notification as JSON and event
+---shows the message in the editor.
+---@alias notifier_config { event: boolean, command?: string[], webhook?: string }
+
+---External adapter: either a subprocess which speaks the adapter protocol over
+---stdio (command) or a go plugin (requires a backend built with "-tags goplugin").
+---@alias external_adapter { command?: string[], plugin?: string }
+
 ---Keymap options.
 ---@alias key_mapping { key: string, mode: string, opts: table, action: string|fun() }
 
@@ -31,10 +62,10 @@ local config = {}
 ---@alias editor_config { directory: string, mappings: key_mapping[], window_options: table<string, any>, buffer_options: table<string, any> }
 
 ---Configuration for call log UI tile.
----@alias call_log_config { mappings: key_mapping[], disable_candies: boolean, candies: table<string, Candy>, window_options: table<string, any>, buffer_options: table<string, any> }
+---@alias call_log_config { mappings: key_mapping[], deduplicate: boolean, disable_candies: boolean, candies: table<string, Candy>, window_options: table<string, any>, buffer_options: table<string, any> }
 
 ---Configuration for drawer UI tile.
----@alias drawer_config { disable_candies: boolean, candies: table<string, Candy>, mappings: key_mapping[], disable_help: boolean, window_options: table<string, any>, buffer_options: table<string, any> }
+---@alias drawer_config { disable_candies: boolean, candies: table<string, Candy>, mappings: key_mapping[], disable_help: boolean, include_system: boolean, window_options: table<string, any>, buffer_options: table<string, any> }
 
 ---@divider -
 
@@ -50,6 +81,8 @@ config.default = {
   sources = {
     require("dbee.sources").EnvSource:new("DBEE_CONNECTIONS"),
     require("dbee.sources").FileSource:new(vim.fn.stdpath("state") .. "/dbee/persistence.json"),
+    -- sqlite scratch database of the current project
+    require("dbee.sources").ScratchSource:new(vim.fn.stdpath("state") .. "/dbee/scratch"),
   },
   -- extra table helpers per connection type
   -- every helper value is a go-template with values set for
@@ -60,6 +93,67 @@ config.default = {
     --   ["List All"] = "select * from {{ .Table }}",
     -- },
   },
+  -- path of an append-only log (JSON lines) of every executed statement,
+  -- kept separately from call history. Leave empty to disable.
+  -- example: vim.fn.stdpath("state") .. "/dbee/audit.jsonl"
+  audit_log = nil,
+  -- log of the backend (see :Dbee logs). The file is rotated when it grows
+  -- over max_size_mb and the last ring_size entries are kept in memory.
+  log = {
+    level = "info",
+    -- per subsystem levels, example: { handler = "debug" }
+    levels = {},
+    -- "text" or "json"
+    format = "text",
+    -- defaults to dbee/dbee.log in the cache directory
+    path = nil,
+    max_size_mb = 10,
+    max_backups = 3,
+    ring_size = 1000,
+  },
+  -- calls which take at least threshold_ms are recorded in the slow query log
+  -- (see :Dbee slow), along with their plans if explain is set (plans are
+  -- requested with EXPLAIN, which doesn't execute the query).
+  -- Entries are also appended to path (JSON lines) if it's set.
+  slow_query_log = {
+    threshold_ms = 1000,
+    explain = true,
+    path = nil,
+  },
+  -- failed calls (statement, error, duration and connection) are kept in the
+  -- error history (see :Dbee errors), so failed attempts can be revisited
+  -- and fixed later. Its retention is separate from the call log.
+  error_history = {
+    enabled = true,
+    max_entries = 500,
+    max_age_days = 30,
+  },
+  -- notifiers of calls marked with require("dbee").notify() (see :Dbee notify),
+  -- triggered when the call completes or fails.
+  notifier = {
+    -- show the message in the editor (also fired as DbeeCallNotification User autocommand)
+    event = true,
+    -- desktop notification, called with the message as the last argument
+    -- example: { "notify-send", "dbee" }
+    command = nil,
+    -- URL to which the notification is POSTed as JSON
+    webhook = nil,
+  },
+  -- file where named query snippets are persisted (see handler:add_snippet()).
+  -- Snippets are kept in memory only if this is empty.
+  snippets_file = vim.fn.stdpath("state") .. "/dbee/snippets.json",
+  -- address of a backend started with "dbee serve --listen <address>",
+  -- shared by multiple editors (path of a unix socket, e.g. "/tmp/dbee.sock").
+  -- Every editor starts its own backend if this is empty.
+  server = nil,
+  -- external adapters per connection type. Adapters are programs in any
+  -- language which speak a JSON protocol over stdio (see dbee/adapters/external.go)
+  -- or go plugins.
+  adapters = {
+    -- example:
+    -- csv = { command = { "python3", "/path/to/nvim-dbee/dbee/adapters/external/csv_adapter.py" } },
+    -- mydb = { plugin = "/path/to/mydb.so" },
+  },
   -- options passed to floating windows - :h nvim_open_win()
   float_options = {},
 
@@ -75,14 +169,18 @@ config.default = {
 
     -- show help or not
     disable_help = false,
+    -- show system schemas and objects (e.g. pg_catalog) in the structure
+    include_system = false,
     -- mappings for the buffer
     mappings = {
       -- manually refresh drawer
       { key = "r", mode = "n", action = "refresh" },
+      -- show or hide system schemas and objects
+      { key = "S", mode = "n", action = "toggle_system" },
       -- actions perform different stuff depending on the node:
       -- action_1 opens a note or executes a helper
       { key = "<CR>", mode = "n", action = "action_1" },
-      -- action_2 renames a note or sets the connection as active manually
+      -- action_2 renames a note, sets the connection as active manually or edits a comment
       { key = "cw", mode = "n", action = "action_2" },
       -- action_3 deletes a note or connection (removes connection from the file if you configured it like so)
       { key = "dd", mode = "n", action = "action_3" },
@@ -119,6 +217,10 @@ config.default = {
         icon = "",
         icon_highlight = "Character",
       },
+      database = {
+        icon = "",
+        icon_highlight = "Comment",
+      },
       table = {
         icon = "",
         icon_highlight = "Conditional",
@@ -129,11 +231,91 @@ config.default = {
         icon_highlight = "Debug",
         text_highlight = "",
       },
+      materialized_view = {
+        icon = "",
+        icon_highlight = "Debug",
+        text_highlight = "",
+      },
+      ["function"] = {
+        icon = "󰊕",
+        icon_highlight = "Function",
+        text_highlight = "",
+      },
+      procedure = {
+        icon = "󰅩",
+        icon_highlight = "Function",
+        text_highlight = "",
+      },
+      sequence = {
+        icon = "󰲹",
+        icon_highlight = "Number",
+        text_highlight = "",
+      },
+      type = {
+        icon = "",
+        icon_highlight = "Type",
+        text_highlight = "",
+      },
+      enum_value = {
+        icon = "",
+        icon_highlight = "Constant",
+        text_highlight = "",
+      },
       column = {
         icon = "󰠵",
         icon_highlight = "WarningMsg",
         text_highlight = "",
       },
+      index = {
+        icon = "",
+        icon_highlight = "Identifier",
+        text_highlight = "",
+      },
+      constraint = {
+        icon = "󰌆",
+        icon_highlight = "Type",
+        text_highlight = "",
+      },
+      foreign_key = {
+        icon = "󰌷",
+        icon_highlight = "Type",
+        text_highlight = "",
+      },
+      trigger = {
+        icon = "󰸗",
+        icon_highlight = "Special",
+        text_highlight = "",
+      },
+      partition = {
+        icon = "",
+        icon_highlight = "Type",
+        text_highlight = "",
+      },
+      grant = {
+        icon = "",
+        icon_highlight = "Special",
+        text_highlight = "",
+      },
+      foreign_server = {
+        icon = "󰒍",
+        icon_highlight = "Structure",
+        text_highlight = "",
+      },
+      role = {
+        icon = "",
+        icon_highlight = "Identifier",
+        text_highlight = "",
+      },
+      migration = {
+        icon = "",
+        icon_highlight = "Constant",
+        text_highlight = "",
+      },
+      info = {
+        icon = "",
+        icon_highlight = "Comment",
+        text_highlight = "",
+      },
       add = {
         icon = "",
         icon_highlight = "String",
@@ -207,13 +389,19 @@ config.default = {
       { key = "H", mode = "", action = "page_prev" },
       { key = "E", mode = "", action = "page_last" },
       { key = "F", mode = "", action = "page_first" },
-      -- yank rows as csv/json
+      -- next/previous result set
+      { key = "]s", mode = "", action = "set_next" },
+      { key = "[s", mode = "", action = "set_prev" },
+      -- yank rows as csv/json/geojson
       { key = "yaj", mode = "n", action = "yank_current_json" },
       { key = "yaj", mode = "v", action = "yank_selection_json" },
       { key = "yaJ", mode = "", action = "yank_all_json" },
       { key = "yac", mode = "n", action = "yank_current_csv" },
       { key = "yac", mode = "v", action = "yank_selection_csv" },
       { key = "yaC", mode = "", action = "yank_all_csv" },
+      { key = "yag", mode = "n", action = "yank_current_geojson" },
+      { key = "yag", mode = "v", action = "yank_selection_geojson" },
+      { key = "yaG", mode = "", action = "yank_all_geojson" },
 
       -- cancel current call execution
       { key = "<C-c>", mode = "", action = "cancel_call" },
@@ -235,6 +423,16 @@ config.default = {
       { key = "BB", mode = "v", action = "run_selection" },
       -- run the whole file on the active connection
       { key = "BB", mode = "n", action = "run_file" },
+      -- run the statement under the cursor on the active connection
+      { key = "BS", mode = "n", action = "run_statement" },
+      -- format the statement under the cursor
+      { key = "BF", mode = "n", action = "format_statement" },
+      -- show lint diagnostics of the whole file
+      { key = "BL", mode = "n", action = "lint" },
+      -- preview a sample of rows of the statement under the cursor
+      { key = "BP", mode = "n", action = "sample_statement" },
+      -- estimate the cost of the statement under the cursor without running it
+      { key = "BC", mode = "n", action = "estimate_cost" },
     },
   },
 
@@ -250,8 +448,18 @@ config.default = {
       { key = "<CR>", mode = "", action = "show_result" },
       -- cancel the currently selected call (if its still executing)
       { key = "<C-c>", mode = "", action = "cancel_call" },
+      -- get notified when the currently selected call finishes
+      { key = "<C-n>", mode = "", action = "notify_call" },
+      -- show repeated calls of a query which differ only in values as a single record
+      { key = "D", mode = "", action = "toggle_deduplicate" },
+      -- fuzzy search past queries (an empty pattern shows all calls again)
+      { key = "s", mode = "", action = "search" },
     },
 
+    -- start with repeated calls of a query shown as a single record, with the number of calls
+    -- (queries are compared with literals replaced by "?", e.g. "SELECT * FROM t WHERE id = ?")
+    deduplicate = false,
+
     -- candies (icons and highlights)
     disable_candies = false,
     candies = {
@@ -311,6 +519,14 @@ function config.validate(cfg)
   vim.validate {
     sources = { cfg.sources, "table" },
     extra_helpers = { cfg.extra_helpers, "table" },
+    audit_log = { cfg.audit_log, "string", true },
+    log = { cfg.log, "table" },
+    slow_query_log = { cfg.slow_query_log, "table" },
+    error_history = { cfg.error_history, "table" },
+    notifier = { cfg.notifier, "table" },
+    snippets_file = { cfg.snippets_file, "string", true },
+    server = { cfg.server, "string", true },
+    adapters = { cfg.adapters, "table", true },
     float_options = { cfg.float_options, "table" },
 
     drawer_disable_candies = { cfg.drawer.disable_candies, "boolean" },
@@ -322,6 +538,7 @@ function config.validate(cfg)
     result_mappings = { cfg.result.mappings, "table" },
     editor_mappings = { cfg.editor.mappings, "table" },
     call_log_mappings = { cfg.call_log.mappings, "table" },
+    call_log_deduplicate = { cfg.call_log.deduplicate, "boolean" },
 
     window_layout = { cfg.window_layout, "table" },
     window_layout_open = { cfg.window_layout.open, "function" },
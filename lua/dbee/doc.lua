@@ -13,11 +13,25 @@
 ---@class Column
 ---@field name string name of the column
 ---@field type string database type of the column
+---@field not_null boolean column doesn't accept NULL
+---@field default string default value expression of the column
+---@field primary_key boolean column is a part of the primary key
+---@field comment string comment (description) of the column stored in the database
 
 ---Table Materialization.
 ---@alias materialization
 ---| '"table"'
 ---| '"view"'
+---| '"materialized_view"'
+---| '"function"'
+---| '"procedure"'
+---| '"trigger"'
+---| '"sequence"'
+---| '"function"'
+---| '"procedure"'
+---| '"trigger"'
+---| '"index"'
+---| '"sequence"'
 
 ---Options for gathering table specific info.
 ---@class TableOpts
@@ -28,6 +42,250 @@
 ---Table helpers queries by name.
 ---@alias table_helpers table<string, string>
 
+---Table index
+---@class TableIndex
+---@field name string
+---@field columns string[] indexed columns or expressions
+---@field unique boolean
+---@field primary boolean
+
+---Options of a schema comparison
+---@class CompareOpts
+---@field source_schema? string schema compared on the source connection (all schemas if empty)
+---@field target_schema? string schema compared on the target connection (defaults to source_schema)
+---@field migration? boolean generate statements which make the target match the source
+
+---How an object differs in the target: "missing" (source only), "extra" (target only) or "changed"
+---@alias diff_kind "missing"|"extra"|"changed"
+
+---@class ColumnDiff
+---@field name string
+---@field kind diff_kind
+---@field source? Column
+---@field target? Column
+
+---@class IndexDiff
+---@field name string
+---@field kind diff_kind
+---@field source? TableIndex
+---@field target? TableIndex
+
+---Table which differs between the source and the target, columns and indexes are set for changed tables
+---@class TableDiff
+---@field schema string
+---@field name string
+---@field kind diff_kind
+---@field columns ColumnDiff[]
+---@field indexes IndexDiff[]
+
+---Difference between tables of two connections
+---@class SchemaDiff
+---@field tables TableDiff[]
+---@field migration string[] statements which make the target match the source (review before running)
+
+---Options of a comparison of rows of a table on two connections
+---@class TableCompareOpts
+---@field table string compared table of the source connection
+---@field schema? string schema of the table
+---@field target_table? string table of the target connection (defaults to table and schema)
+---@field target_schema? string schema of the target table
+---@field key_columns string[] columns identifying rows, ordered the same way by both databases
+---@field max_diffs? integer maximum number of reported rows (100 by default, all if negative)
+
+---Row which differs between the source and the target: "missing" rows were deleted from the
+---target, "extra" rows were inserted and "changed" rows differ in some columns.
+---@class TableRowDiff
+---@field kind diff_kind
+---@field key any[] values of key columns
+---@field source? any[] values of compared columns in the source
+---@field target? any[] values of compared columns in the target
+---@field changed? string[] columns which differ (changed rows only)
+
+---Difference between rows of a table on two connections
+---@class TableDataDiff
+---@field equal boolean both tables have the same rows
+---@field key_columns string[]
+---@field columns string[] columns of both tables, which are compared
+---@field source_rows integer number of rows of the source table
+---@field target_rows integer number of rows of the target table
+---@field inserted integer number of rows of the target only
+---@field deleted integer number of rows of the source only
+---@field changed integer number of rows which differ in compared columns
+---@field diffs TableRowDiff[] differing rows (up to max_diffs)
+
+---@class SchemaExportOpts
+---@field split? boolean write every object to its own file: <path>/<schema>/<type>/<name>.sql
+---@field schema? string schema of exported objects (all schemas if empty)
+---@field objects? { table: string, schema: string, materialization: string }[] objects to export (all if empty)
+---@field include_system? boolean include system schemas and objects
+
+---@class SchemaExport
+---@field exported integer number of exported objects
+---@field skipped string[] objects whose definitions can't be retrieved
+---@field files string[] written files
+
+---Table of a federated query, filled by a query on a connection or by a result set of a call.
+---@class FederatedTable
+---@field name string name of the table in the federated query
+---@field conn_id? connection_id connection of the query
+---@field query? string query whose rows fill the table
+---@field call_id? call_id finished call whose rows fill the table (used instead of conn_id and query)
+---@field result_set? integer result set of the call (0 by default)
+
+---Credential of an exported connection replaced by a reference.
+---@class SecretRef
+---@field name string unique name of the secret in the bundle (e.g. "prod_password")
+---@field connection_id connection_id id of the connection in the bundle
+---@field reference string text replacing the secret in the URL
+---@field env string environment variable of "env" references
+---@field encoding "userinfo"|"query"|"raw" how the value is written to the URL
+
+---Shareable set of connections without credentials.
+---@class ConnectionBundle
+---@field connections ConnectionParams[]
+---@field secrets SecretRef[]
+---@field missing SecretRef[] secrets which have to be provided on import
+
+---@class CompletionOpts
+---@field prefix? string only names starting with prefix (case insensitive)
+---@field schema? string only objects of the schema (all schemas if empty)
+---@field table? string include columns of the table or view
+
+---@class CompletionItem
+---@field name string
+---@field kind "schema"|"table"|"view"|"materialized_view"|"function"|"procedure"|"column"|"keyword"
+---@field schema string
+---@field detail string type of columns
+
+---@class SignatureParam
+---@field name string empty for unnamed parameters
+---@field type string
+---@field mode "in"|"out"|"inout"
+
+---Overload of a function or a procedure.
+---@class Signature
+---@field schema string
+---@field name string
+---@field label string e.g. "public.add(a integer, b integer) -> integer"
+---@field params SignatureParam[]
+---@field returns string empty for procedures
+---@field comment string
+
+---Adapter registered in the backend.
+---@class AdapterInfo
+---@field type string connection type
+---@field kind "builtin"|"external"|"plugin"
+
+---Problem found by linting a statement (positions are 0-based, end is exclusive).
+---@class LintDiagnostic
+---@field line integer
+---@field col integer
+---@field end_line integer
+---@field end_col integer
+---@field severity "error"|"warning"|"info"
+---@field rule "missing_where"|"unknown_table"|"ambiguous_column"
+---@field message string
+
+---Table constraint
+---@class TableConstraint
+---@field name string
+---@field type "primary_key"|"unique"|"check"|"foreign_key"
+---@field columns string[]
+---@field definition string definition of check constraints
+---@field referenced_schema string schema of the table referenced by a foreign key
+---@field referenced_table string table referenced by a foreign key
+---@field referenced_columns string[] columns referenced by a foreign key
+
+---Table trigger
+---@class TableTrigger
+---@field name string
+---@field event string events which fire the trigger (e.g. "INSERT OR UPDATE")
+---@field timing string BEFORE, AFTER or INSTEAD OF
+---@field function string function executed by the trigger (empty if the database doesn't use one)
+
+---Database user or role
+---@class Role
+---@field name string
+---@field superuser boolean
+---@field can_login boolean
+---@field member_of string[] roles this role is a member of
+
+---Remote server the database can query (foreign server or linked server)
+---@class ForeignServer
+---@field name string
+---@field wrapper string foreign data wrapper or provider
+---@field options string connection options (e.g. host and port)
+
+---Privilege on a table granted to a role
+---@class TableGrant
+---@field grantee string
+---@field privilege string (e.g. "SELECT")
+---@field grantable boolean grantee can grant the privilege to others
+---@field grantor string
+
+---Action which can be run on a database object (e.g. "count" or "drop")
+---@class ObjectAction
+---@field id string
+---@field title string
+---@field confirmation "none"|"simple"|"typed" how the action has to be confirmed (typed requires typing the object name)
+
+---Ready-to-edit statement for a database object built from its columns
+---@class Scaffold
+---@field name "select"|"insert"|"update"
+---@field query string
+
+---Object in a dependency tree
+---@class DependencyNode
+---@field schema string
+---@field name string
+---@field type string type of the object (e.g. "view")
+---@field cycle boolean object was already visited higher up in the tree, its children are omitted
+---@field children DependencyNode[]
+
+---Objects an object depends on and objects which depend on it
+---@class Dependencies
+---@field depends_on DependencyNode[]
+---@field dependents DependencyNode[]
+
+---Partition of a partitioned table
+---@class Partition
+---@field schema string
+---@field name string
+---@field expression string partition bound or key value
+---@field rows integer estimated number of rows (-1 if unknown)
+---@field size integer size in bytes (-1 if unknown)
+
+---Database server a connection is connected to
+---@class ServerInfo
+---@field version string
+---@field uptime integer uptime in seconds (-1 if unknown)
+---@field extensions { name: string, version: string }[] installed extensions or plugins
+---@field settings { name: string, value: string }[] settings of interest
+
+---Migration file (or a pair of up and down files).
+---@class Migration
+---@field version string
+---@field name string
+---@field files string[]
+---@field no_transaction boolean migration doesn't run in a transaction
+---@field applied boolean
+
+---Migrations of a directory with their status on a connection.
+---@alias migration_tool "goose"|"golang-migrate"|"dbmate"
+
+---@class MigrationStatus
+---@field tool migration_tool
+---@field dir string
+---@field migrations Migration[] ordered by version
+---@field dirty boolean golang-migrate marked the database dirty after a failed migration
+
+---Estimated table size
+---@class TableStats
+---@field schema string
+---@field table string
+---@field rows integer estimated number of rows (-1 if unknown)
+---@field size integer size in bytes (-1 if unknown)
+
 ---@divider -
 ---@tag dbee.ref.types.call
 ---@brief [[
@@ -56,6 +314,260 @@
 ---@field state call_state
 ---@field timestamp_us integer time in microseconds
 ---@field error? string error message in case of error
+---@field error_info? QueryErrorInfo details of the error reported by the database
+---@field result_sets integer number of result sets the call produced
+
+---Calls of queries with the same fingerprint.
+---@class QueryStats
+---@field fingerprint string query with literals replaced by "?", without comments and with normalized whitespace
+---@field last_call CallDetails newest call with the fingerprint
+---@field count integer number of calls
+---@field failed integer number of failed calls
+---@field total_time_us integer total duration of the calls in microseconds
+---@field average_time_us integer average duration of the calls in microseconds
+
+---Details of a failed query.
+---@class QueryErrorInfo
+---@field category "unknown"|"auth"|"network"|"syntax"|"constraint"|"timeout"|"permission"|"not_found"|"resource_limit"|"quota"|"cost"
+---@field code string native error code of the database (e.g. SQLSTATE), the exceeded limit for "resource_limit", "quota" and "cost" (e.g. "max_rows")
+---@field position integer 1-based character offset in the query (0 if unknown)
+---@field line integer 1-based line in the query (0 if unknown)
+---@field column integer 1-based column in the query (0 if unknown)
+---@field hint string suggestion how to fix the error
+---@field detail string additional details reported by the database
+---@field object_kind ""|"column"|"table"|"function"|"schema" kind of the object the error is about
+---@field object string name of the object the error is about (e.g. the undefined column)
+
+---Explanation of a failed query (see call_explain_error).
+---@class ErrorHint
+---@field kind "database"|"similar_names"|"conflicting_query"
+---@field message string
+---@field candidates? string[] names similar to the missing object ("similar_names")
+---@field query string query of the other session ("conflicting_query")
+
+---ID of a schedule.
+---@alias schedule_id string
+
+---Details of a query which is repeatedly executed on a connection.
+---@class ScheduleDetails
+---@field id schedule_id
+---@field conn_id connection_id
+---@field query string
+---@field interval_ms integer interval between runs in milliseconds
+---@field runs integer number of runs so far
+---@field last_call_id call_id id of the call from the latest run
+
+---ID of a watch.
+---@alias watch_id string
+
+---Options of a watched query or table.
+---@alias watch_options { interval_ms: integer, key_columns?: string[], full_refresh?: boolean, max_rows?: integer } key_columns identify rows (changed rows are reported as deleted and inserted without them), full_refresh sends all rows with every update and max_rows limits compared rows (1000 by default)
+
+---Details of a query or table which is watched for changes.
+---@class WatchDetails
+---@field id watch_id
+---@field conn_id connection_id
+---@field query string
+---@field interval_ms integer interval between runs in milliseconds
+---@field key_columns string[]
+---@field full_refresh boolean
+---@field max_rows integer
+---@field runs integer number of runs so far
+---@field last_call_id call_id id of the call from the latest run
+
+---Row of a watched result which changed since the previous run. Values are
+---strings (nil for NULL).
+---@class WatchRowDiff
+---@field kind "inserted"|"deleted"|"changed"
+---@field key any[] values of key columns
+---@field old? any[] previous row (deleted and changed rows)
+---@field new? any[] refreshed row (inserted and changed rows)
+---@field changed? string[] names of changed columns
+
+---Data of the "watch_updated" event. The first update, updates whose header
+---changed and updates of full_refresh watches carry all rows.
+---@class WatchUpdate
+---@field watch_id watch_id
+---@field conn_id connection_id
+---@field call_id call_id call of the run
+---@field run integer number of the run
+---@field error? string error of a failed run
+---@field header string[]
+---@field rows? any[][] all rows of the result
+---@field diffs WatchRowDiff[]
+---@field inserted integer
+---@field deleted integer
+---@field changed integer
+---@field truncated boolean the result has more than max_rows rows, the rest wasn't compared
+
+---Named, parameterized query. The query is a go-template rendered with the
+---arguments provided at invocation (e.g. "select * from {{ .table }}").
+---Template function "quote" quotes a value as an sql string literal.
+---@class Snippet
+---@field name string
+---@field connection_id? connection_id empty for global snippets
+---@field description? string
+---@field query string
+
+---Statement in a text. Lines and columns are 0-based and columns are in bytes.
+---@class StatementRange
+---@field start integer byte offset of the statement's start
+---@field end integer byte offset after the statement's delimiter
+---@field start_line integer
+---@field start_col integer
+---@field end_line integer
+---@field end_col integer
+---@field text string statement without the delimiter
+
+---Edits of a result from an editable grid.
+---@class ResultEdits
+---@field table string target table
+---@field schema? string
+---@field set? integer index of the result set (0 by default)
+---@field key_columns? string[] columns identifying a row (primary key by default)
+---@field updates? { row: integer, column: string, value: any }[] changed cells (rows are 0-based)
+---@field inserts? table<string, any>[] new rows as column-value pairs
+---@field deletes? integer[] indexes of deleted rows (0-based)
+---@field execute? boolean execute the generated statements in a transaction
+---@field confirmed? boolean skip the guard of a guarded connection
+
+---Upload of a local file into a binary column.
+---@class BlobUploadOpts
+---@field table string
+---@field schema? string
+---@field column string binary column (e.g. BYTEA or BLOB)
+---@field path string local file
+---@field key? table<string, any> values of columns identifying the updated row, a new row is inserted if nil
+---@field values? table<string, any> other columns of the inserted row (they identify it while the file is appended)
+---@field chunk_size? integer bytes written per statement (1 MiB by default)
+
+---Generator of values of a seeded column.
+---@alias seed_generator
+---| '"skip"' leave the column out (default value)
+---| '"null"'
+---| '"sequence"' increasing integers after the highest existing value (or from min)
+---| '"int"'
+---| '"float"'
+---| '"bool"'
+---| '"timestamp"'
+---| '"date"'
+---| '"time"'
+---| '"uuid"'
+---| '"json"'
+---| '"bytes"'
+---| '"word"'
+---| '"sentence"'
+---| '"first_name"'
+---| '"last_name"'
+---| '"name"'
+---| '"email"'
+---| '"phone"'
+---| '"city"'
+---| '"country"'
+---| '"company"'
+---| '"url"'
+---| '"one_of"' one of values
+
+---Values generated for a column.
+---@class SeedRule
+---@field generator? seed_generator inferred from the type and name of the column if nil
+---@field values? any[] values of the "one_of" generator
+---@field min? number lower bound of "int" and "float" (1 by default), start of "sequence"
+---@field max? number upper bound of "int" and "float" (1000 by default)
+---@field null_rate? number fraction (0 to 1) of NULL values
+
+---Seeding of a table with generated rows.
+---@class SeedOpts
+---@field schema? string
+---@field rows? integer number of inserted rows (100 by default)
+---@field columns? table<string, SeedRule> rules by column name
+---@field batch_size? integer rows sent to the database at once
+---@field seed? integer the same seed generates the same rows (random by default)
+
+---Parameter of a stored procedure call.
+---@class ProcedureParam
+---@field name string
+---@field mode "in"|"out"|"inout"
+---@field value? any ignored for "out" parameters
+
+---Node of a normalized query plan.
+---@class PlanNode
+---@field type string type of the operation (e.g. "Seq Scan")
+---@field relation string relation the operation works on (can be empty)
+---@field cost number estimated cost including children
+---@field rows number estimated number of rows
+---@field actual_rows number actual number of rows (analyzed plans only)
+---@field actual_time_ms number actual total time in milliseconds (analyzed plans only)
+---@field children PlanNode[]
+
+---Durations of a call's execution phases in milliseconds.
+---@class ResultMetrics
+---@field execution_ms number time until the server returned the first result
+---@field fetch_ms number time spent fetching rows
+---@field archive_ms number time spent serializing the result to the archive
+---@field format_ms number time spent formatting the last displayed page
+
+---Options of sampled execution. Single table queries are sampled by the
+---database (e.g. TABLESAMPLE), other queries return random rows of their result.
+---@class SampleOpts
+---@field percent? number percentage of rows of the table read by table sampling (1 by default)
+---@field rows? integer maximum number of returned rows (1000 by default)
+
+---Metadata of a call's result.
+---@class ResultMeta
+---@field schema_type "schemaful"|"schemaless"
+---@field injected_limit integer limit that was automatically added to the query (0 if none)
+---@field retries integer number of times the query was retried because of transient errors
+---@field sampling string method used to sample rows of the query (empty if it wasn't sampled)
+---@field metrics ResultMetrics
+---@field notices string[] notices, warnings and messages the server sent during the query
+---@field query_id string id of the query on the server (empty if the database doesn't report it)
+---@field bytes_processed integer number of bytes the query processed (databases billing scanned bytes, 0 otherwise)
+
+---Cell of a result table.
+---@class TableCell
+---@field text string value of the cell as displayed (can contain line breaks)
+---@field width integer display width of the widest line of the text
+---@field col integer byte offset of the text in the first line of its row
+
+---Row of a result table.
+---@class TableRow
+---@field index integer zero based index of the row in the result
+---@field line integer zero based line of the row in the buffer (multi line cells take more lines)
+---@field cells TableCell[] the first cell holds the row number
+
+---Layout of a displayed page of a result.
+---@class TableLayout
+---@field header TableCell[]
+---@field rows TableRow[]
+---@field widths integer[] display widths of columns (the first one holds row numbers)
+---@field right_aligned boolean[] true for numeric columns
+
+---Options of a result comparison
+---@class ResultCompareOpts
+---@field set? integer index of the compared result sets (defaults to 0)
+---@field ignore_order? boolean compare rows regardless of their order
+---@field max_diffs? integer maximum number of reported rows (100 by default, all if negative)
+
+---Row which differs between the baseline and the result. Missing rows exist in the baseline only,
+---extra rows in the result only and changed rows differ at the same position.
+---@class RowDiff
+---@field kind diff_kind
+---@field index integer index of the row in the result (in the baseline for missing rows)
+---@field baseline? any[] values of the row in the baseline
+---@field result? any[] values of the row in the result
+
+---Outcome of comparing a result with a baseline
+---@class ResultComparison
+---@field passed boolean the result has the same header and rows as the baseline
+---@field baseline_checksum string
+---@field checksum string
+---@field baseline_header string[]
+---@field header string[]
+---@field baseline_rows integer number of rows of the baseline
+---@field rows integer number of rows of the result
+---@field diffs RowDiff[] differing rows (up to max_diffs)
+---@field diff_count integer number of all differing rows
 
 ---@divider -
 ---@tag dbee.ref.types.connection
@@ -72,6 +584,84 @@
 ---@field name string
 ---@field type string
 ---@field url string
+---@field guarded? boolean require confirmation for destructive statements
+---@field auto_limit? integer limit appended to unbounded SELECT statements
+---@field retries? integer number of retries for statements failing with transient errors (deadlocks, serialization failures, ...) and for single read-only statements failing with dropped connections
+---@field structure_ttl? integer seconds after which the cached structure is reloaded (0 or nil caches it until refreshed)
+---@field hidden_databases? string[] shell patterns (e.g. "test_*") of databases hidden from the database switch
+---@field preview? PreviewOpts rows returned by the "select" action of tables and views
+---@field masking? MaskRule[] rules masking values of results before they are displayed, archived or exported
+---@field display? DisplayOpts how timestamps and numbers of results are displayed (exports keep raw values)
+---@field limits? ResourceLimits limits enforced on calls of guarded connections
+---@field quota? QueryQuota limits how many queries run on the connection (guarded or not)
+---@field cost? CostOpts cost estimates and the maximum cost of queries (guarded or not)
+---@field migrations_dir? string directory of migration files (discovered in the working directory if nil)
+---@field autocommit? boolean (read only) false if statements join an implicit transaction
+---@field in_transaction? boolean (read only) true if a transaction is pending on the connection
+
+---Options of the "select" action of tables and views.
+---@class PreviewOpts
+---@field limit? integer number of returned rows (500 if nil or 0)
+---@field latest? boolean order by the primary key in descending order (latest rows first)
+---@field columns? string[] selected columns (all if empty), unknown columns are ignored
+
+---Display of values in result tables.
+---@class DisplayOpts
+---@field timezone? string timezone of timestamps (e.g. "UTC", "Local" or "Europe/Ljubljana"), their own zone if nil
+---@field time_format? string layout of timestamps in the form of Go's reference time (e.g. "2006-01-02 15:04:05")
+---@field thousands_separator? string separator of groups of digits of numbers (e.g. ",")
+---@field float_precision? integer number of decimals of floating point numbers
+
+---Resource limits of calls on guarded connections (nil or 0 disables a limit).
+---Calls exceeding them fail with the "resource_limit" error category.
+---@class ResourceLimits
+---@field max_rows? integer maximum number of rows of a result set
+---@field truncate_rows? boolean stop result sets at max_rows instead of failing the call
+---@field max_scanned_bytes? integer maximum bytes a query may scan (only databases which estimate it, e.g. BigQuery)
+---@field max_runtime? integer maximum number of seconds a call may take, including fetching of rows
+
+---Query quota of a connection on a shared database (nil or 0 disables a limit).
+---Refused calls fail with the "quota" error category.
+---@class QueryQuota
+---@field max_queries_per_minute? integer maximum number of queries started within any minute
+---@field cooldown_rows? integer number of rows a query may read before the connection cools down
+---@field cooldown? integer number of seconds no queries run after a query read more than cooldown_rows rows
+
+---Cost of queries on databases billing scanned bytes (e.g. BigQuery).
+---Refused calls fail with the "cost" error category, confirmed calls aren't refused.
+---@class CostOpts
+---@field price_per_tib? number price in USD of a scanned TiB (the database's on-demand price if nil or 0)
+---@field max_cost? number estimated cost in USD above which queries are refused (nil or 0 disables it)
+
+---Estimated cost of a query, obtained without running it.
+---@class CostEstimate
+---@field scanned_bytes integer number of bytes the query would scan
+---@field price_per_tib number price in USD of a scanned TiB (0 if unknown)
+---@field cost number estimated cost in USD (0 if the price is unknown)
+---@field max_cost number maximum cost of the connection (0 if queries aren't refused)
+---@field exceeds boolean true if the query would be refused
+
+---Rule masking values of matching columns in results of a connection.
+---NULLs stay NULLs, the first matching rule of a column is used.
+---@class MaskRule
+---@field column? string name of masked columns (case insensitive)
+---@field pattern? string regular expression matched against column names (used instead of column)
+---@field method? "redact"|"hash"|"partial" replace the value, replace it with a short hash or keep its last characters ("redact" if nil)
+---@field keep? integer number of trailing characters kept by "partial" (4 if nil or 0)
+
+---Protocol between the plugin and its backend.
+---@class Protocol
+---@field version integer protocol version of the backend
+---@field min_version integer oldest plugin protocol version the backend serves
+---@field compatible boolean false if the plugin is older than min_version
+---@field capabilities string[] endpoints the backend serves
+---@field backend? BackendInfo build of the backend (nil for older backends)
+
+---Build of the backend.
+---@class BackendInfo
+---@field version string vcs revision of the binary ("unknown" if not known)
+---@field adapters string[] types of adapters compiled into the binary
+---@field features string[] optional features ("external_adapters", "go_plugins", "shared")
 
 ---@divider -
 ---@tag dbee.ref.types.structure
@@ -86,13 +676,35 @@
 ---| '"history"'
 ---| '"database_switch"'
 ---| '"view"'
+---| '"materialized_view"'
+---| '"function"'
+---| '"procedure"'
+---| '"sequence"'
+---| '"column"'
+---| '"type"'
+---| '"enum_value"'
+
+---Options of structure requests.
+---@class StructureOpts
+---@field include_system? boolean include system schemas and objects (e.g. pg_catalog)
 
 ---Structure of database.
 ---@class DBStructure
 ---@field name string display name
 ---@field type structure_type type of node in structure
 ---@field schema string? parent schema
+---@field status string? status reported by the database (e.g. whether a materialized view is stale)
 ---@field children DBStructure[]? child layout nodes
+---@field lazy boolean? children are not loaded yet (see |Handler:connection_get_structure_children|)
+---@field comment string? comment (description) of the object stored in the database
+
+---Database object matching a search pattern.
+---@class ObjectMatch
+---@field schema string
+---@field table string table of the column (empty for other objects)
+---@field name string
+---@field type structure_type
+---@field path string[] names of nodes leading to the object (e.g. { schema, table, column })
 
 ---@divider -
 ---@tag dbee.ref.types.events
@@ -105,6 +717,17 @@
 ---| '"call_state_changed"' {call}
 ---| '"current_connection_changed"' {conn_id}
 ---| '"database_selected"' {conn_id, database_name}
+---| '"schedule_result_changed"' {schedule_id, conn_id, call_id}
+---| '"watch_updated"' WatchUpdate the first result of a watched query and its changes
+---| '"import_progress"' {conn_id, call_id, imported, failed, bytes_read, bytes_total}
+---| '"connection_state_changed"' {conn_id, state: "connected"|"removed"}
+---| '"call_log_changed"' {conn_id}
+---| '"structure_refreshed"' {conn_id, schema, error} schema is empty if the whole structure was refreshed
+---| '"export_progress"' {export_id, call_id, rows, rows_total, bytes_written}
+---| '"export_finished"' {export_id, call_id, path, canceled, error}
+---| '"query_started"' {conn_id, call_id, query, active_calls} conn_id is empty for queries on multiple connections
+---| '"query_finished"' {conn_id, call_id, state, active_calls, duration_us, error}
+---| '"call_notification"' {call_id, conn_id, connection, state, duration_ms, rows, error, message} a call marked with call_notify finished
 
 ---Available editor events.
 ---@alias editor_event_name
@@ -116,5 +739,43 @@
 ---Event handler function.
 ---@alias event_listener fun(data: any)
 
+---Call recorded in the slow query log.
+---@class SlowQuery
+---@field call_id call_id
+---@field conn_id connection_id empty for calls on multiple connections
+---@field connection string name of the connection
+---@field query string
+---@field timestamp_us integer
+---@field duration_ms number
+---@field state call_state
+---@field error string
+---@field plan? PlanNode plan of the query (nil if it's not available)
+---@field plan_error string
+
+---Record of the error history.
+---@class FailedQuery
+---@field conn_id connection_id empty for calls on multiple connections
+---@field connection string name of the connection
+---@field call CallDetails failed call with the statement, error and duration
+
+---Entry of the log of the backend.
+---@class LogEntry
+---@field timestamp_us integer
+---@field level "debug"|"info"|"warn"|"error"
+---@field subsystem string
+---@field message string
+---@field attrs table<string, string> structured attributes (e.g. call_id)
+
+---Running queries and the last finished one.
+---@class QueryStatus
+---@field active_calls integer number of running queries
+---@field last_duration_us integer duration of the last finished query
+---@field last_error? string error of the last finished query
+
+---Callbacks of an export.
+---@class export_callbacks
+---@field on_progress? fun(data: { export_id: integer, call_id: call_id, rows: integer, rows_total: integer, bytes_written: integer })
+---@field on_finish? fun(data: { export_id: integer, call_id: call_id, path: string, canceled: boolean, error?: string })
+
 local M = {}
 return M
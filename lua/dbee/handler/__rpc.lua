@@ -0,0 +1,49 @@
+-- This package implements asynchronous requests to go endpoints.
+-- Requests get unique ids and go responds to them by calling "respond".
+local M = {}
+
+-- Version of the protocol spoken by the frontend (see plugin.ProtocolVersion in go).
+M.VERSION = 1
+
+---@alias rpc_callback fun(result: any, err?: string)
+
+local last_id = 0
+
+---@type table<integer, rpc_callback>
+local pending = {}
+
+---Calls the endpoint named method with args without blocking.
+---@param method string name of the endpoint (e.g. "DbeeConnectionGetColumns")
+---@param args any[] arguments of the endpoint
+---@param cb rpc_callback called with the result or the error message
+---@return integer id of the request
+function M.request(method, args, cb)
+  last_id = last_id + 1
+  pending[last_id] = cb
+  vim.fn.DbeeRequest(last_id, method, args)
+  return last_id
+end
+
+---@param id integer
+---@param result any
+---@param err string empty on success
+function M.respond(id, result, err)
+  local cb = pending[id]
+  pending[id] = nil
+  if not cb then
+    return
+  end
+
+  if result == vim.NIL then
+    result = nil
+  end
+  if err == "" or err == vim.NIL then
+    err = nil
+  end
+
+  vim.schedule(function()
+    cb(result, err)
+  end)
+end
+
+return M
@@ -1,23 +1,89 @@
 local event_bus = require("dbee.handler.__events")
+local rpc = require("dbee.handler.__rpc")
 local utils = require("dbee.utils")
 
 -- Handler is an aggregator of connections
 ---@class Handler
 ---@field private sources table<source_id, Source>
 ---@field private source_conn_lookup table<source_id, connection_id[]>
+---@field private protocol? Protocol
+---@field private capabilities table<connection_id, table<string, boolean>> cached capabilities of connections
+---@field private exports table<integer, export_callbacks> callbacks of in-progress exports
+---@field private query_status QueryStatus status of queries updated by query events
 local Handler = {}
 
 ---@param sources? Source[]
+---@param adapters? table<string, external_adapter> external adapters per connection type
 ---@return Handler
-function Handler:new(sources)
+function Handler:new(sources, adapters)
   -- class object
   local o = {
     sources = {},
     source_conn_lookup = {},
+    capabilities = {},
+    exports = {},
+    query_status = { active_calls = 0, last_duration_us = 0 },
   }
   setmetatable(o, self)
   self.__index = self
 
+  o:negotiate()
+
+  -- capabilities are loaded again for recreated connections
+  event_bus.register("connection_state_changed", function(data)
+    o.capabilities[data.conn_id] = nil
+  end)
+
+  -- status of queries is kept up to date, so statuslines can read it cheaply
+  local ok, status = pcall(vim.fn.DbeeGetQueryStatus)
+  if ok and type(status) == "table" then
+    o.query_status = status
+  end
+  event_bus.register("query_started", function(data)
+    o.query_status.active_calls = data.active_calls
+    vim.api.nvim_exec_autocmds("User", { pattern = "DbeeQueryStarted", modeline = false, data = data })
+  end)
+  event_bus.register("query_finished", function(data)
+    o.query_status = {
+      active_calls = data.active_calls,
+      last_duration_us = data.duration_us,
+      last_error = data.error,
+    }
+    vim.api.nvim_exec_autocmds("User", { pattern = "DbeeQueryFinished", modeline = false, data = data })
+  end)
+
+  -- calls marked with call_notify finished
+  event_bus.register("call_notification", function(data)
+    utils.log(data.error and "error" or "info", data.message, "notify")
+    vim.api.nvim_exec_autocmds("User", { pattern = "DbeeCallNotification", modeline = false, data = data })
+  end)
+
+  -- callbacks of exports started with call_export
+  event_bus.register("export_progress", function(data)
+    local cbs = o.exports[data.export_id]
+    if cbs and cbs.on_progress then
+      cbs.on_progress(data)
+    end
+  end)
+  event_bus.register("export_finished", function(data)
+    local cbs = o.exports[data.export_id]
+    o.exports[data.export_id] = nil
+    if cbs and cbs.on_finish then
+      cbs.on_finish(data)
+    end
+  end)
+
+  -- external adapters are registered before connections of sources are created
+  for type, adapter in pairs(adapters or {}) do
+    local ok, mes = pcall(vim.fn.DbeeRegisterAdapter, type, {
+      command = adapter.command,
+      plugin = adapter.plugin,
+    })
+    if not ok then
+      utils.log("error", "failed registering adapter: " .. type .. " " .. mes, "core")
+    end
+  end
+
   -- initialize the sources
   sources = sources or {}
   for _, source in ipairs(sources) do
@@ -30,12 +96,95 @@ function Handler:new(sources)
   return o
 end
 
+-- agree on the protocol version with the backend and warn if it's outdated
+function Handler:negotiate()
+  local ok, protocol = pcall(vim.fn.DbeeNegotiate, rpc.VERSION)
+  if not ok or type(protocol) ~= "table" then
+    utils.log("warn", 'backend is outdated, run require("dbee").install()', "core")
+    return
+  end
+  self.protocol = protocol
+
+  if not protocol.compatible then
+    utils.log("warn", "backend is newer than the plugin, update the plugin", "core")
+  elseif protocol.version < rpc.VERSION then
+    utils.log("warn", 'backend is outdated, run require("dbee").install()', "core")
+  end
+end
+
+---@return Protocol|nil _ nil if the backend doesn't negotiate protocols
+function Handler:get_protocol()
+  return self.protocol
+end
+
+---Whether the backend serves the endpoint (e.g. "DbeeConnectionGetColumns").
+---@param name string
+---@return boolean
+function Handler:has_capability(name)
+  if not self.protocol then
+    return false
+  end
+  return vim.tbl_contains(self.protocol.capabilities or {}, name)
+end
+
+---Build of the backend: version, compiled-in adapters and features.
+---@return BackendInfo|nil _ nil if the backend doesn't report it
+function Handler:get_backend_info()
+  if not self.protocol or self.protocol.backend == vim.NIL then
+    return nil
+  end
+  return self.protocol.backend
+end
+
+---Whether the driver of the connection supports the capability (e.g. "transactions").
+---Capabilities are cached per connection. Everything is assumed to be supported
+---if the backend can't report capabilities.
+---@param id connection_id
+---@param capability string
+---@return boolean
+function Handler:connection_supports(id, capability)
+  if not self:has_capability("DbeeConnectionGetCapabilities") then
+    return true
+  end
+
+  local cached = self.capabilities[id]
+  if not cached then
+    local ok, list = pcall(self.connection_get_capabilities, self, id)
+    if not ok then
+      return true
+    end
+    cached = {}
+    for _, name in ipairs(list) do
+      cached[name] = true
+    end
+    self.capabilities[id] = cached
+  end
+
+  return cached[capability] == true
+end
+
+---Calls a backend endpoint without blocking the editor.
+---@param method string name of the endpoint
+---@param args any[] arguments of the endpoint
+---@param cb rpc_callback
+---@return integer request_id
+function Handler:request(method, args, cb)
+  return rpc.request(method, args, cb)
+end
+
 ---@param event core_event_name
 ---@param listener event_listener
 function Handler:register_event_listener(event, listener)
   event_bus.register(event, listener)
 end
 
+---Returns the number of running queries and the duration and error of the
+---last finished one. The status is updated by query events, so it's cheap to call.
+---@return QueryStatus
+function Handler:get_query_status()
+  return vim.deepcopy(self.query_status)
+end
+
 -- add new source and load connections from it
 ---@param source Source
 function Handler:add_source(source)
@@ -212,26 +361,330 @@ end
 
 ---@param id connection_id
 ---@param query string
+---@param opts? { confirmed: boolean, sample: SampleOpts } set confirmed to execute destructive queries on guarded connections, set sample to return a sample of rows of a single SELECT statement
 ---@return CallDetails
-function Handler:connection_execute(id, query)
-  return vim.fn.DbeeConnectionExecute(id, query)
+function Handler:connection_execute(id, query, opts)
+  opts = opts or {}
+  local sample = nil
+  if opts.sample then
+    sample = { percent = opts.sample.percent or 0, rows = opts.sample.rows or 0 }
+  end
+  return vim.fn.DbeeConnectionExecute(id, query, { confirmed = opts.confirmed or false, sample = sample })
 end
 
+---Estimates the bytes the query would scan and their price, without running
+---it. Errors if the database can't estimate scanned bytes (only BigQuery does).
 ---@param id connection_id
----@return DBStructure[]
-function Handler:connection_get_structure(id)
-  local ret = vim.fn.DbeeConnectionGetStructure(id)
+---@param query string
+---@return CostEstimate
+function Handler:connection_estimate_cost(id, query)
+  return vim.fn.DbeeConnectionEstimateCost(id, query)
+end
+
+---Executes the same query on multiple connections and aggregates the results
+---into a single call with a "connection" column prepended.
+---@param ids connection_id[]
+---@param query string
+---@param opts? { confirmed: boolean }
+---@return CallDetails
+function Handler:connections_execute(ids, query, opts)
+  opts = opts or {}
+  return vim.fn.DbeeConnectionsExecute(ids, query, { confirmed = opts.confirmed or false })
+end
+
+---Compares tables, columns and indexes of the source connection with the ones
+---of the target connection.
+---@param source_id connection_id
+---@param target_id connection_id
+---@param opts? CompareOpts
+---@return SchemaDiff
+function Handler:connections_compare_schemas(source_id, target_id, opts)
+  opts = opts or {}
+  return vim.fn.DbeeConnectionsCompareSchemas(source_id, target_id, {
+    source_schema = opts.source_schema or "",
+    target_schema = opts.target_schema or "",
+    migration = opts.migration or false,
+  })
+end
+
+---Compares rows of a table on the source connection with the ones on the
+---target connection, reading both tables ordered by key columns.
+---@param source_id connection_id
+---@param target_id connection_id
+---@param opts TableCompareOpts
+---@return TableDataDiff
+function Handler:connections_compare_table_data(source_id, target_id, opts)
+  return vim.fn.DbeeConnectionsCompareTableData(source_id, target_id, {
+    schema = opts.schema or "",
+    table = opts.table,
+    target_schema = opts.target_schema or "",
+    target_table = opts.target_table or "",
+    key_columns = opts.key_columns,
+    max_diffs = opts.max_diffs or 0,
+  })
+end
+
+---Loads tables from connections and calls into an embedded engine and runs the query on it.
+---@param tables FederatedTable[]
+---@param query string
+---@param opts? { engine: connection_id }
+---@return CallDetails
+function Handler:connections_federate(tables, query, opts)
+  opts = opts or {}
+  return vim.fn.DbeeConnectionsFederate(tables, query, {
+    engine = opts.engine or "",
+  })
+end
+
+---Writes connections to a shareable file with credentials replaced by secret references.
+---@param ids connection_id[]
+---@param path string
+---@param opts? { secrets: "strip"|"env" }
+---@return ConnectionBundle
+function Handler:connections_export(ids, path, opts)
+  opts = opts or {}
+  return vim.fn.DbeeConnectionsExport(ids, path, {
+    secrets = opts.secrets or "",
+  })
+end
+
+---Reads exported connections and the secrets missing to import them.
+---@param path string
+---@return ConnectionBundle
+function Handler:read_connection_bundle(path)
+  return vim.fn.DbeeReadConnectionBundle(path)
+end
+
+---Adds exported connections to the source with secrets filled in by name.
+---@param path string
+---@param source_id source_id
+---@param secrets table<string, string>
+---@return connection_id[]
+function Handler:import_connections(path, source_id, secrets)
+  -- empty tables are encoded as arrays
+  if vim.tbl_isempty(secrets) then
+    secrets = vim.empty_dict()
+  end
+
+  local ids = {}
+  for _, conn in ipairs(vim.fn.DbeeImportConnections(path, secrets)) do
+    conn.id = nil
+    table.insert(ids, self:source_add_connection(source_id, conn))
+  end
+  return ids
+end
+
+---@param id connection_id
+---@param name string name of the stored procedure
+---@param params ProcedureParam[]
+---@param opts? { confirmed: boolean } set confirmed to call procedures on guarded connections
+---@return CallDetails
+function Handler:connection_call_procedure(id, name, params, opts)
+  opts = opts or {}
+  return vim.fn.DbeeConnectionCallProcedure(id, name, params or {}, { confirmed = opts.confirmed or false })
+end
+
+---Starts a transaction on the connection. All statements are executed in
+---the transaction until it's committed or rolled back.
+---@param id connection_id
+function Handler:connection_begin_transaction(id)
+  vim.fn.DbeeConnectionBeginTransaction(id)
+end
+
+---@param id connection_id
+function Handler:connection_commit_transaction(id)
+  vim.fn.DbeeConnectionCommitTransaction(id)
+end
+
+---@param id connection_id
+function Handler:connection_rollback_transaction(id)
+  vim.fn.DbeeConnectionRollbackTransaction(id)
+end
+
+---Turns autocommit on or off. With autocommit off, statements join an implicit
+---transaction until it's committed or rolled back. Turning it back on commits
+---the pending transaction.
+---@param id connection_id
+---@param enabled boolean
+function Handler:connection_set_autocommit(id, enabled)
+  vim.fn.DbeeConnectionSetAutoCommit(id, enabled)
+end
+
+---Configures the log of the backend.
+---@param opts log_config
+function Handler:set_log_options(opts)
+  opts = opts or {}
+  vim.fn.DbeeSetLogOptions({
+    level = opts.level or "info",
+    levels = opts.levels or vim.empty_dict(),
+    format = opts.format or "text",
+    path = opts.path or "",
+    max_size_mb = opts.max_size_mb or 0,
+    max_backups = opts.max_backups or 0,
+    ring_size = opts.ring_size or 0,
+  })
+end
+
+---Returns recent entries of the log of the backend, from the oldest one.
+---@param opts? { level: string, subsystem: string, limit: integer }
+---@return LogEntry[]
+function Handler:get_logs(opts)
+  opts = opts or {}
+  local ret = vim.fn.DbeeGetLogs({
+    level = opts.level or "debug",
+    subsystem = opts.subsystem or "",
+    limit = opts.limit or 0,
+  })
+  if not ret or ret == vim.NIL then
+    return {}
+  end
+  return ret
+end
+
+---Configures the slow query log.
+---@param opts slow_query_log_config
+function Handler:set_slow_query_log(opts)
+  opts = opts or {}
+  vim.fn.DbeeSetSlowQueryLog({
+    threshold_ms = opts.threshold_ms or 0,
+    explain = opts.explain or false,
+    path = opts.path or "",
+  })
+end
+
+---Returns calls recorded in the slow query log, from the newest one.
+---@param opts? { conn_id: connection_id, limit: integer } all connections and all queries by default
+---@return SlowQuery[]
+function Handler:get_slow_queries(opts)
+  opts = opts or {}
+  local ret = vim.fn.DbeeGetSlowQueries({
+    conn_id = opts.conn_id or "",
+    limit = opts.limit or 0,
+  })
+  if not ret or ret == vim.NIL then
+    return {}
+  end
+  return ret
+end
+
+---Removes all entries of the slow query log (the file is kept).
+function Handler:clear_slow_queries()
+  vim.fn.DbeeClearSlowQueries()
+end
+
+---Configures the error history.
+---@param opts error_history_config
+function Handler:set_error_history(opts)
+  opts = opts or {}
+  vim.fn.DbeeSetErrorHistory({
+    enabled = opts.enabled or false,
+    max_entries = opts.max_entries or 0,
+    max_age_days = opts.max_age_days or 0,
+  })
+end
+
+---Returns failed calls recorded in the error history, from the newest one.
+---@param opts? { conn_id: connection_id, limit: integer } all connections and all records by default
+---@return FailedQuery[]
+function Handler:get_failed_queries(opts)
+  opts = opts or {}
+  local ret = vim.fn.DbeeGetFailedQueries({
+    conn_id = opts.conn_id or "",
+    limit = opts.limit or 0,
+  })
   if not ret or ret == vim.NIL then
     return {}
   end
   return ret
 end
 
+---Removes records of the connection from the error history (all records if
+---conn_id is nil).
+---@param conn_id? connection_id
+function Handler:clear_failed_queries(conn_id)
+  vim.fn.DbeeClearFailedQueries(conn_id or "")
+end
+
+---Sets the path of the append-only audit log, to which every executed
+---statement is written as a JSON line. Empty path disables the audit log.
+---@param path string
+function Handler:set_audit_log(path)
+  vim.fn.DbeeSetAuditLog(path or "")
+end
+
+---Creates a savepoint in the current transaction.
 ---@param id connection_id
----@param opts { table: string, schema: string, materialization: string }
----@return Column[]
-function Handler:connection_get_columns(id, opts)
-  local out = vim.fn.DbeeConnectionGetColumns(id, opts)
+---@param name string
+function Handler:connection_savepoint(id, name)
+  vim.fn.DbeeConnectionSavepoint(id, name)
+end
+
+---Undoes statements executed after the savepoint, without ending the transaction.
+---@param id connection_id
+---@param name string
+function Handler:connection_rollback_to_savepoint(id, name)
+  vim.fn.DbeeConnectionRollbackToSavepoint(id, name)
+end
+
+---@param id connection_id
+---@return string[] # savepoints in the current transaction
+function Handler:connection_get_savepoints(id)
+  local ret = vim.fn.DbeeConnectionGetSavepoints(id)
+  if not ret or ret == vim.NIL then
+    return {}
+  end
+  return ret
+end
+
+---Returns a structured plan of the query for visualization.
+---@param id connection_id
+---@param query string
+---@param opts? { analyze: boolean } analyze executes the query and includes actual rows and timings
+---@return PlanNode
+function Handler:connection_explain(id, query, opts)
+  opts = opts or {}
+  return vim.fn.DbeeConnectionExplain(id, query, { analyze = opts.analyze or false })
+end
+
+---Lists active sessions on the server. The list is available as the call's result.
+---@param id connection_id
+---@return CallDetails
+function Handler:connection_get_activity(id)
+  return vim.fn.DbeeConnectionGetActivity(id)
+end
+
+---Subscribes to a channel of the database. Every event is appended as a row
+---to the call's result until the call is canceled.
+---@param id connection_id
+---@param channel string
+---@return CallDetails
+function Handler:connection_subscribe(id, channel)
+  return vim.fn.DbeeConnectionSubscribe(id, channel)
+end
+
+---@param id connection_id
+---@param session_id string|integer id of the session (first column of the activity result)
+function Handler:connection_kill_session(id, session_id)
+  vim.fn.DbeeConnectionKillSession(id, tostring(session_id))
+end
+
+---@param id connection_id
+---@param opts { table: string, schema: string }
+function Handler:connection_refresh_materialized_view(id, opts)
+  vim.fn.DbeeConnectionRefreshMaterializedView(id, {
+    table = opts.table,
+    schema = opts.schema,
+  })
+end
+
+---@param id connection_id
+---@param opts { table: string, schema: string }
+---@return Partition[]
+function Handler:connection_get_partitions(id, opts)
+  local out = vim.fn.DbeeConnectionGetPartitions(id, {
+    table = opts.table,
+    schema = opts.schema,
+  })
   if not out or out == vim.NIL then
     return {}
   end
@@ -240,70 +693,527 @@ function Handler:connection_get_columns(id, opts)
 end
 
 ---@param id connection_id
----@return ConnectionParams?
-function Handler:connection_get_params(id)
-  local ret = vim.fn.DbeeConnectionGetParams(id)
+---@param opts { table: string, schema: string } partitioned table
+---@param partition { name: string, schema: string }
+---@param confirmation string name of the partition
+function Handler:connection_detach_partition(id, opts, partition, confirmation)
+  vim.fn.DbeeConnectionDetachPartition(
+    id,
+    { table = opts.table, schema = opts.schema },
+    { name = partition.name, schema = partition.schema },
+    confirmation
+  )
+end
+
+---@param id connection_id
+---@param opts { table: string, schema: string } partitioned table
+---@param partition { name: string, schema: string }
+---@param confirmation string name of the partition
+function Handler:connection_drop_partition(id, opts, partition, confirmation)
+  vim.fn.DbeeConnectionDropPartition(
+    id,
+    { table = opts.table, schema = opts.schema },
+    { name = partition.name, schema = partition.schema },
+    confirmation
+  )
+end
+
+---@param id connection_id
+---@param opts? StructureOpts
+---@return DBStructure[]
+function Handler:connection_get_structure(id, opts)
+  opts = opts or {}
+  local ret = vim.fn.DbeeConnectionGetStructure(id, { include_system = opts.include_system or false })
   if not ret or ret == vim.NIL then
-    return
+    return {}
   end
   return ret
 end
 
+-- Searches names of tables, views, columns and functions. Pattern is matched
+-- case insensitively anywhere in the name and can contain LIKE wildcards.
 ---@param id connection_id
----@return string current_db
----@return string[] available_dbs
-function Handler:connection_list_databases(id)
-  local ret = vim.fn.DbeeConnectionListDatabases(id)
+---@param pattern string
+---@param limit? integer maximum number of matches (default 200)
+---@return ObjectMatch[]
+function Handler:connection_search_objects(id, pattern, limit)
+  local ret = vim.fn.DbeeConnectionSearchObjects(id, pattern, limit or 0)
   if not ret or ret == vim.NIL then
-    return "", {}
+    return {}
   end
-
-  return unpack(ret)
+  return ret
 end
 
+-- Drops the cached structure of the connection. If node is provided, only
+-- children of a lazy node are dropped.
 ---@param id connection_id
----@param database string
-function Handler:connection_select_database(id, database)
-  vim.fn.DbeeConnectionSelectDatabase(id, database)
+---@param node? DBStructure
+function Handler:connection_refresh_structure(id, node)
+  node = node or {}
+  vim.fn.DbeeConnectionRefreshStructure(id, {
+    name = node.name or "",
+    schema = node.schema or "",
+    type = node.type or "",
+  })
 end
 
+-- Loads children of a lazy structure node.
 ---@param id connection_id
----@return CallDetails[]
-function Handler:connection_get_calls(id)
-  local ret = vim.fn.DbeeConnectionGetCalls(id)
+---@param parent DBStructure
+---@return DBStructure[]
+function Handler:connection_get_structure_children(id, parent)
+  local ret = vim.fn.DbeeConnectionGetStructureChildren(id, {
+    name = parent.name,
+    schema = parent.schema,
+    type = parent.type,
+  })
   if not ret or ret == vim.NIL then
     return {}
   end
   return ret
 end
 
----@param id call_id
-function Handler:call_cancel(id)
-  vim.fn.DbeeCallCancel(id)
+---@param id connection_id
+---@param opts { table: string, schema: string, materialization: string }
+---@return Column[]
+function Handler:connection_get_columns(id, opts)
+  local out = vim.fn.DbeeConnectionGetColumns(id, opts)
+  if not out or out == vim.NIL then
+    return {}
+  end
+
+  return out
 end
 
----@param id call_id
----@param bufnr integer
----@param from integer
----@param to integer
----@return integer # total number of rows
-function Handler:call_display_result(id, bufnr, from, to)
-  local length = vim.fn.DbeeCallDisplayResult(id, { buffer = bufnr, from = from, to = to })
-  if not length or length == vim.NIL then
-    return 0
+---@param id connection_id
+---@param opts TableOpts
+---@return TableIndex[]
+function Handler:connection_get_indexes(id, opts)
+  local out = vim.fn.DbeeConnectionGetIndexes(id, {
+    table = opts.table,
+    schema = opts.schema,
+    materialization = opts.materialization,
+  })
+  if not out or out == vim.NIL then
+    return {}
   end
-  return length
+
+  return out
 end
 
----@alias store_format "csv"|"json"|"table"
----@alias store_output "file"|"yank"|"buffer"
+---@param id connection_id
+---@param opts TableOpts
+---@return TableConstraint[]
+function Handler:connection_get_constraints(id, opts)
+  local out = vim.fn.DbeeConnectionGetConstraints(id, {
+    table = opts.table,
+    schema = opts.schema,
+    materialization = opts.materialization,
+  })
+  if not out or out == vim.NIL then
+    return {}
+  end
 
----@param id call_id
----@param format store_format format of the output
----@param output store_output where to pipe the results
----@param opts { from: integer, to: integer, extra_arg: any }
-function Handler:call_store_result(id, format, output, opts)
-  opts = opts or {}
+  return out
+end
+
+---@param id connection_id
+---@param opts TableOpts
+---@return TableTrigger[]
+function Handler:connection_get_triggers(id, opts)
+  local out = vim.fn.DbeeConnectionGetTriggers(id, {
+    table = opts.table,
+    schema = opts.schema,
+    materialization = opts.materialization,
+  })
+  if not out or out == vim.NIL then
+    return {}
+  end
+
+  return out
+end
+
+---@param id connection_id
+---@return Role[]
+function Handler:connection_get_roles(id)
+  local out = vim.fn.DbeeConnectionGetRoles(id)
+  if not out or out == vim.NIL then
+    return {}
+  end
+
+---@param id connection_id
+---@return ForeignServer[]
+function Handler:connection_get_foreign_servers(id)
+  local out = vim.fn.DbeeConnectionGetForeignServers(id)
+  if not out or out == vim.NIL then
+    return {}
+  end
+
+  return out
+end
+
+---@param id connection_id
+---@param server string name of the foreign server
+---@return DBStructure[]
+function Handler:connection_get_foreign_objects(id, server)
+  local out = vim.fn.DbeeConnectionGetForeignObjects(id, server)
+  if not out or out == vim.NIL then
+    return {}
+  end
+
+  return out
+end
+
+  return out
+end
+
+---@param id connection_id
+---@param opts TableOpts
+---@return TableGrant[]
+function Handler:connection_get_grants(id, opts)
+  local out = vim.fn.DbeeConnectionGetGrants(id, {
+    table = opts.table,
+    schema = opts.schema,
+    materialization = opts.materialization,
+  })
+  if not out or out == vim.NIL then
+    return {}
+  end
+
+  return out
+end
+
+---@param id connection_id
+---@return ServerInfo?
+function Handler:connection_get_server_info(id)
+  local out = vim.fn.DbeeConnectionGetServerInfo(id)
+  if not out or out == vim.NIL then
+    return nil
+  end
+
+  return out
+end
+
+---@param id connection_id
+---@return TableStats[]
+function Handler:connection_get_table_stats(id)
+  local out = vim.fn.DbeeConnectionGetTableStats(id)
+  if not out or out == vim.NIL then
+    return {}
+  end
+
+  return out
+end
+
+---@param id connection_id
+---@param opts TableOpts
+---@return ObjectAction[]
+function Handler:connection_get_object_actions(id, opts)
+  local out = vim.fn.DbeeConnectionGetObjectActions(id, {
+    table = opts.table,
+    schema = opts.schema,
+    materialization = opts.materialization,
+  })
+  if not out or out == vim.NIL then
+    return {}
+  end
+
+  return out
+end
+
+---@param id connection_id
+---@param opts TableOpts
+---@param action string id of the action
+---@param confirmation? string "yes" for simple and object name for typed confirmations
+---@return { call?: CallDetails, text: string }
+function Handler:connection_run_object_action(id, opts, action, confirmation)
+  return vim.fn.DbeeConnectionRunObjectAction(id, {
+    table = opts.table,
+    schema = opts.schema,
+    materialization = opts.materialization,
+  }, action, confirmation or "")
+end
+
+---@param id connection_id
+---@param opts TableOpts
+---@param column string column to comment, empty to comment the object itself
+---@param comment string empty comment removes the existing one
+function Handler:connection_set_comment(id, opts, column, comment)
+  vim.fn.DbeeConnectionSetComment(id, {
+    table = opts.table,
+    schema = opts.schema,
+    materialization = opts.materialization,
+  }, column, comment)
+end
+
+---@param id connection_id
+---@param opts TableOpts
+---@return Scaffold[]
+function Handler:connection_get_scaffolds(id, opts)
+  local out = vim.fn.DbeeConnectionGetScaffolds(id, {
+    table = opts.table,
+    schema = opts.schema,
+    materialization = opts.materialization,
+  })
+  if not out or out == vim.NIL then
+    return {}
+  end
+
+  return out
+end
+
+---@param id connection_id
+---@param opts TableOpts
+---@return Dependencies
+function Handler:connection_get_dependencies(id, opts)
+  local out = vim.fn.DbeeConnectionGetDependencies(id, {
+    table = opts.table,
+    schema = opts.schema,
+    materialization = opts.materialization,
+  })
+  if not out or out == vim.NIL then
+    return { depends_on = {}, dependents = {} }
+  end
+
+  return out
+end
+
+---@param id connection_id
+---@param opts TableOpts
+---@return string definition source of the object
+function Handler:connection_get_definition(id, opts)
+  return vim.fn.DbeeConnectionGetDefinition(id, {
+    table = opts.table,
+    schema = opts.schema,
+    materialization = opts.materialization,
+  })
+end
+
+---@param id connection_id
+---@return ConnectionParams?
+function Handler:connection_get_params(id)
+  local ret = vim.fn.DbeeConnectionGetParams(id)
+  if not ret or ret == vim.NIL then
+    return
+  end
+  return ret
+end
+
+---@param id connection_id
+---@return string current_db
+---@return string[] available_dbs
+function Handler:connection_list_databases(id)
+  local ret = vim.fn.DbeeConnectionListDatabases(id)
+  if not ret or ret == vim.NIL then
+    return "", {}
+  end
+
+  return unpack(ret)
+end
+
+---@param id connection_id
+---@param database string
+function Handler:connection_select_database(id, database)
+  vim.fn.DbeeConnectionSelectDatabase(id, database)
+end
+
+-- Migrations are read from the migrations_dir of the connection or discovered
+-- in root (the working directory if nil).
+---@param id connection_id
+---@param root? string
+---@return MigrationStatus
+function Handler:connection_get_migrations(id, root)
+  return vim.fn.DbeeConnectionGetMigrations(id, root or vim.fn.getcwd())
+end
+
+---@param id connection_id
+---@param version string
+---@param root? string
+function Handler:connection_apply_migration(id, version, root)
+  vim.fn.DbeeConnectionApplyMigration(id, root or vim.fn.getcwd(), version)
+end
+
+---@param id connection_id
+---@param version string
+---@param root? string
+function Handler:connection_rollback_migration(id, version, root)
+  vim.fn.DbeeConnectionRollbackMigration(id, root or vim.fn.getcwd(), version)
+end
+
+---@param id connection_id
+---@return CallDetails[]
+function Handler:connection_get_calls(id)
+  local ret = vim.fn.DbeeConnectionGetCalls(id)
+  if not ret or ret == vim.NIL then
+    return {}
+  end
+  return ret
+end
+
+---Returns the call log grouped by query fingerprints (queries with literals
+---replaced by "?"), so repeated calls of a query with different values are a
+---single entry.
+---@param id connection_id
+---@param opts? { order: "recent"|"frequency", pattern: string, limit: integer } newest entries first and all of them by default, pattern is matched fuzzily against fingerprints
+---@return QueryStats[]
+function Handler:connection_get_query_stats(id, opts)
+  opts = opts or {}
+  local ret = vim.fn.DbeeConnectionGetQueryStats(id, {
+    order = opts.order or "recent",
+    pattern = opts.pattern or "",
+    limit = opts.limit or 0,
+  })
+  if not ret or ret == vim.NIL then
+    return {}
+  end
+  return ret
+end
+
+---@param id call_id
+function Handler:call_cancel(id)
+  vim.fn.DbeeCallCancel(id)
+end
+
+---Marks the call, so that notifiers are triggered once it completes or fails
+---(right away if it's already finished).
+---@param id call_id
+---@param enabled? boolean false removes the mark (default true)
+function Handler:call_notify(id, enabled)
+  vim.fn.DbeeCallNotify(id, enabled ~= false)
+end
+
+---Configures notifiers of calls marked with call_notify.
+---@param opts notifier_config
+function Handler:set_notifier(opts)
+  opts = opts or {}
+  vim.fn.DbeeSetNotifier({
+    command = opts.command or {},
+    webhook = opts.webhook or "",
+    event = opts.event or false,
+  })
+end
+
+---Cancels every running call, result export and history write.
+---@param timeout_ms? integer how long to wait for the work to stop (default 5000)
+---@return integer number of canceled operations
+function Handler:cancel_all(timeout_ms)
+  return vim.fn.DbeeCancelAll({ timeout_ms = timeout_ms or 0 })
+end
+
+---@param id call_id
+---@return ErrorHint[]
+function Handler:call_explain_error(id)
+  local ret = vim.fn.DbeeCallExplainError(id)
+  if not ret or ret == vim.NIL then
+    return {}
+  end
+  return ret
+end
+
+---@param id call_id
+---@param set? integer index of the result set (defaults to 0)
+---@return ResultMeta?
+function Handler:call_get_meta(id, set)
+  local ret = vim.fn.DbeeCallGetMeta(id, { set = set or 0 })
+  if not ret or ret == vim.NIL then
+    return
+  end
+  return ret
+end
+
+---@param id call_id
+---@param bufnr integer
+---@param from integer
+---@param to integer
+---@param set? integer index of the result set (defaults to 0)
+---@return integer # total number of rows
+function Handler:call_display_result(id, bufnr, from, to, set)
+  local length = vim.fn.DbeeCallDisplayResult(id, { buffer = bufnr, from = from, to = to, set = set or 0 })
+  if not length or length == vim.NIL then
+    return 0
+  end
+  return length
+end
+
+---@param id call_id
+---@param from integer
+---@param to integer
+---@param set? integer index of the result set (defaults to 0)
+---@return TableLayout
+function Handler:call_get_table_layout(id, from, to, set)
+  return vim.fn.DbeeCallGetTableLayout(id, { from = from, to = to, set = set or 0 })
+end
+
+---@param id call_id
+---@param opts? { set: integer, ignore_order: boolean }
+---@return string checksum
+function Handler:call_get_checksum(id, opts)
+  opts = opts or {}
+  return vim.fn.DbeeCallGetChecksum(id, { set = opts.set or 0, ignore_order = opts.ignore_order or false })
+end
+
+---Compares the result of a call with the result of the baseline call.
+---@param id call_id
+---@param baseline_id call_id
+---@param opts? ResultCompareOpts
+---@return ResultComparison
+function Handler:call_compare_result(id, baseline_id, opts)
+  opts = opts or {}
+  return vim.fn.DbeeCallCompareResult(id, baseline_id, {
+    set = opts.set or 0,
+    ignore_order = opts.ignore_order or false,
+    max_diffs = opts.max_diffs or 0,
+  })
+end
+
+---@param id call_id
+---@param set? integer index of the result set (defaults to 0)
+---@return string[] columns names of columns holding json documents
+function Handler:call_get_json_columns(id, set)
+  return vim.fn.DbeeCallGetJSONColumns(id, { set = set or 0 })
+end
+
+---@param id call_id
+---@param row integer index of the row
+---@param column integer index of the column
+---@param set? integer index of the result set (defaults to 0)
+---@return string json pretty printed document
+function Handler:call_format_json_cell(id, row, column, set)
+  return vim.fn.DbeeCallFormatJSONCell(id, { set = set or 0, row = row, column = column })
+end
+
+---@param id call_id
+---@param column string name of the json column
+---@param paths string[] paths of values in the column (e.g. "address.city" or "tags[0]")
+---@param set? integer index of the result set (defaults to 0)
+---@return CallDetails
+function Handler:call_flatten_json(id, column, paths, set)
+  return vim.fn.DbeeCallFlattenJSON(id, { set = set or 0, column = column, paths = paths })
+end
+
+---@param id connection_id
+---@param column string name of the json column
+---@param path string path of the value (e.g. "address.city" or "tags[0]")
+---@return string expression
+function Handler:connection_get_json_path(id, column, path)
+  return vim.fn.DbeeConnectionGetJSONPath(id, column, path)
+end
+
+---Sets whether characters of ambiguous width are two cells wide in result tables.
+---@param double boolean true if 'ambiwidth' is "double"
+function Handler:set_ambiguous_width(double)
+  vim.fn.DbeeSetAmbiguousWidth(double)
+end
+
+---@alias store_format "csv"|"json"|"geojson"|"insert"|"table"
+---@alias store_output "file"|"yank"|"buffer"
+
+---@param id call_id
+---@param format store_format format of the output
+---@param output store_output where to pipe the results
+---@param opts { from: integer, to: integer, extra_arg: any, set: integer }
+function Handler:call_store_result(id, format, output, opts)
+  opts = opts or {}
 
   local from = opts.from or 0
   local to = opts.to or -1
@@ -312,7 +1222,339 @@ function Handler:call_store_result(id, format, output, opts)
     from = from,
     to = to,
     extra_arg = opts.extra_arg,
+    set = opts.set or 0,
+  })
+end
+
+---Returns the statement at byte offset of text. Statement boundaries are detected
+---with the connection's dialect, so delimiters in strings, comments and
+---procedural bodies are ignored.
+---@param id connection_id
+---@param text string
+---@param offset integer 0-based byte offset
+---@return StatementRange?
+function Handler:connection_get_statement_at(id, text, offset)
+  local ret = vim.fn.DbeeConnectionGetStatementAt(id, text, offset)
+  if not ret or ret == vim.NIL then
+    return nil
+  end
+  return ret
+end
+
+---Writes a result set of the call to a file in the background and returns the
+---id of the export. Progress is reported with "export_progress" events and the
+---end of the export with an "export_finished" event, which are passed to the
+---callbacks as well. The file is replaced only if the export succeeds.
+---@param id call_id
+---@param opts { format: store_format, path: string, from: integer, to: integer, set: integer }
+---@param callbacks? export_callbacks
+---@return integer export_id
+function Handler:call_export(id, opts, callbacks)
+  local export_id = vim.fn.DbeeCallExport(id, {
+    format = opts.format,
+    path = opts.path,
+    from = opts.from or 0,
+    to = opts.to or -1,
+    set = opts.set or 0,
+  })
+  self.exports[export_id] = callbacks or {}
+  return export_id
+end
+
+---Cancels an in-progress export. The partial file is removed.
+---@param export_id integer
+function Handler:export_cancel(export_id)
+  vim.fn.DbeeExportCancel(export_id)
+end
+
+---Formats statements in text using the connection's dialect: clauses start
+---on new lines and keywords are upper cased.
+---@param id connection_id
+---@param text string
+---@param opts? { indent: string, keyword_case: "upper"|"lower"|"preserve" }
+---@return string formatted
+function Handler:connection_format(id, text, opts)
+  return vim.fn.DbeeConnectionFormat(id, text, opts or {})
+end
+
+---Checks statements in text for DELETE and UPDATE without WHERE, tables which
+---don't exist in the cached structure and ambiguous columns.
+---@param id connection_id
+---@param text string
+---@return LintDiagnostic[]
+function Handler:connection_lint(id, text)
+  local ret = vim.fn.DbeeConnectionLint(id, text)
+  if not ret or ret == vim.NIL then
+    return {}
+  end
+  return ret
+end
+
+---Splits text into statements using the connection's dialect.
+---@param id connection_id
+---@param text string
+---@return StatementRange[]
+function Handler:connection_split_statements(id, text)
+  local ret = vim.fn.DbeeConnectionSplitStatements(id, text)
+  if not ret or ret == vim.NIL then
+    return {}
+  end
+  return ret
+end
+
+---Generates UPDATE, INSERT and DELETE statements which apply edits of a result
+---to a table. Rows are 0-based indexes in the result and are identified in the
+---table by key columns (primary key is looked up if key_columns are not provided).
+---If opts.execute is set, statements are executed in a transaction as well.
+---@param id connection_id
+---@param call_id call_id
+---@param opts ResultEdits
+---@return string[] statements
+function Handler:connection_generate_edits(id, call_id, opts)
+  local ret = vim.fn.DbeeConnectionGenerateEdits(id, call_id, {
+    set = opts.set or 0,
+    table = opts.table,
+    schema = opts.schema or "",
+    key_columns = opts.key_columns or {},
+    updates = opts.updates or {},
+    inserts = opts.inserts or {},
+    deletes = opts.deletes or {},
+    execute = opts.execute or false,
+    confirmed = opts.confirmed or false,
   })
+  if not ret or ret == vim.NIL then
+    return {}
+  end
+  return ret
+end
+
+---Writes a local file into a binary column of a row. The file is streamed in
+---chunks bound as parameters. Returns the number of written bytes.
+---@param id connection_id
+---@param opts BlobUploadOpts
+---@return integer written
+function Handler:connection_upload_blob(id, opts)
+  return vim.fn.DbeeConnectionUploadBlob(id, {
+    table = opts.table,
+    schema = opts.schema or "",
+    column = opts.column,
+    path = vim.fn.expand(opts.path),
+    key = opts.key or vim.empty_dict(),
+    values = opts.values or vim.empty_dict(),
+    chunk_size = opts.chunk_size or 0,
+  })
+end
+
+---Imports a local CSV or NDJSON file into a table. Returns a call which finishes
+---when the import is done. Progress is reported with "import_progress" events and
+---rejected rows are written to the error file (path .. ".errors" by default).
+---@param id connection_id
+---@param path string
+---@param table string
+---@param opts? { format: "csv"|"ndjson", columns: table<string, string>, batch_size: integer, error_path: string }
+---@return CallDetails
+function Handler:connection_import(id, path, table, opts)
+  opts = opts or {}
+  return vim.fn.DbeeConnectionImport(id, path, table, {
+    format = opts.format or "",
+    columns = opts.columns or vim.empty_dict(),
+    batch_size = opts.batch_size or 0,
+    error_path = opts.error_path or "",
+  })
+end
+
+---Inserts generated rows into a table. Values are generated by rules of columns
+---or by generators inferred from types and names of columns. Returns a call which
+---finishes when the rows are inserted.
+---@param id connection_id
+---@param table string
+---@param opts? SeedOpts
+---@return CallDetails
+function Handler:connection_seed(id, table, opts)
+  opts = opts or {}
+  return vim.fn.DbeeConnectionSeed(id, table, {
+    schema = opts.schema or "",
+    rows = opts.rows or 0,
+    columns = opts.columns or vim.empty_dict(),
+    batch_size = opts.batch_size or 0,
+    seed = opts.seed or 0,
+  })
+end
+
+---Writes definitions (CREATE statements) of objects to a file or, with opts.split,
+---to a directory with a file per object.
+---@param id connection_id
+---@param path string
+---@param opts? SchemaExportOpts
+---@return SchemaExport
+function Handler:connection_export_schema(id, path, opts)
+  opts = opts or {}
+  return vim.fn.DbeeConnectionExportSchema(id, path, {
+    split = opts.split or false,
+    schema = opts.schema or "",
+    include_system = opts.include_system or false,
+    objects = opts.objects or {},
+  })
+end
+
+---Returns the whole structure of the connection (including columns) as a
+---versioned JSON document.
+---@param id connection_id
+---@return string
+function Handler:connection_dump_layout(id)
+  return vim.fn.DbeeConnectionDumpLayout(id)
+end
+
+---@param id connection_id
+---@param opts? CompletionOpts
+---@return CompletionItem[]
+function Handler:connection_get_completion(id, opts)
+  opts = opts or {}
+  local ret = vim.fn.DbeeConnectionGetCompletion(id, {
+    prefix = opts.prefix or "",
+    schema = opts.schema or "",
+    table = opts.table or "",
+  })
+  if not ret or ret == vim.NIL then
+    return {}
+  end
+  return ret
+end
+
+---@param id connection_id
+---@param opts { table: string, schema?: string } table is the name of the function
+---@return Signature[]
+function Handler:connection_get_signatures(id, opts)
+  local ret = vim.fn.DbeeConnectionGetSignatures(id, {
+    table = opts.table,
+    schema = opts.schema or "",
+  })
+  if not ret or ret == vim.NIL then
+    return {}
+  end
+  return ret
+end
+
+---@return AdapterInfo[]
+function Handler:get_adapters()
+  local ret = vim.fn.DbeeGetAdapters()
+  if not ret or ret == vim.NIL then
+    return {}
+  end
+  return ret
+end
+
+---@param id connection_id
+---@return string[] capabilities
+function Handler:connection_get_capabilities(id)
+  local ret = vim.fn.DbeeConnectionGetCapabilities(id)
+  if not ret or ret == vim.NIL then
+    return {}
+  end
+  return ret
+end
+
+---@param id connection_id
+---@param query string
+---@param interval_ms integer interval between runs in milliseconds
+---@return ScheduleDetails
+function Handler:connection_schedule_query(id, query, interval_ms)
+  return vim.fn.DbeeConnectionScheduleQuery(id, query, { interval_ms = interval_ms })
+end
+
+---@param id schedule_id
+function Handler:schedule_cancel(id)
+  vim.fn.DbeeScheduleCancel(id)
+end
+
+---@return ScheduleDetails[]
+function Handler:get_schedules()
+  local ret = vim.fn.DbeeGetSchedules()
+  if not ret or ret == vim.NIL then
+    return {}
+  end
+  return ret
+end
+
+---@param id connection_id
+---@param query string
+---@param opts watch_options
+---@return WatchDetails
+function Handler:connection_watch_query(id, query, opts)
+  return vim.fn.DbeeConnectionWatchQuery(id, query, opts)
+end
+
+---@param id connection_id
+---@param schema string
+---@param table string
+---@param opts watch_options
+---@return WatchDetails
+function Handler:connection_watch_table(id, schema, table, opts)
+  return vim.fn.DbeeConnectionWatchTable(id, schema, table, opts)
+end
+
+---@param id watch_id
+function Handler:watch_cancel(id)
+  vim.fn.DbeeWatchCancel(id)
+end
+
+---@return WatchDetails[]
+function Handler:get_watches()
+  local ret = vim.fn.DbeeGetWatches()
+  if not ret or ret == vim.NIL then
+    return {}
+  end
+  return ret
+end
+
+---Sets the file where snippets are persisted and loads snippets from it.
+---@param path string
+function Handler:set_snippets_file(path)
+  vim.fn.DbeeSetSnippetsFile(path or "")
+end
+
+---Adds a snippet or replaces an existing one with the same name and connection.
+---Snippets without connection_id are global.
+---@param snippet Snippet
+function Handler:add_snippet(snippet)
+  vim.fn.DbeeAddSnippet(snippet)
+end
+
+---@param name string
+---@param conn_id? connection_id remove snippet of connection instead of the global one
+function Handler:remove_snippet(name, conn_id)
+  vim.fn.DbeeRemoveSnippet(name, { connection_id = conn_id or "" })
+end
+
+---Lists snippets available on the connection (including global ones).
+---@param conn_id? connection_id only global snippets are listed if nil
+---@return Snippet[]
+function Handler:get_snippets(conn_id)
+  local ret = vim.fn.DbeeGetSnippets({ connection_id = conn_id or "" })
+  if not ret or ret == vim.NIL then
+    return {}
+  end
+  return ret
+end
+
+---Renders the snippet's query with arguments.
+---@param id connection_id
+---@param name string
+---@param args? table<string, any>
+---@return string query
+function Handler:connection_render_snippet(id, name, args)
+  return vim.fn.DbeeConnectionRenderSnippet(id, name, args or vim.empty_dict())
+end
+
+---Renders the snippet's query with arguments and executes it on the connection.
+---@param id connection_id
+---@param name string
+---@param args? table<string, any>
+---@param opts? { confirmed: boolean }
+---@return CallDetails
+function Handler:connection_execute_snippet(id, name, args, opts)
+  opts = opts or {}
+  return vim.fn.DbeeConnectionExecuteSnippet(id, name, args or vim.empty_dict(), { confirmed = opts.confirmed or false })
 end
 
 return Handler
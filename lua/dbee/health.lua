@@ -1,4 +1,5 @@
 local install = require("dbee.install")
+local state = require("dbee.api.state")
 
 local M = {}
 
@@ -36,8 +37,24 @@ local function get_manifest_hash()
   return install.version()
 end
 
+-- Reports adapters and features of the running backend.
+local function check_backend()
+  if not state.is_core_loaded() then
+    return
+  end
+
+  local backend = state.handler():get_backend_info()
+  if not backend then
+    vim.health.warn("Backend doesn't report its build, it might be outdated.")
+    return
+  end
+  vim.health.info("Adapters: " .. table.concat(backend.adapters or {}, ", "))
+  vim.health.info("Features: " .. table.concat(backend.features or {}, ", "))
+end
+
 function M.check()
   vim.health.start("DBee report")
+  check_backend()
 
   if vim.fn.executable(install.bin()) ~= 1 then
     vim.health.error("Binary not executable: " .. install.bin() .. ".")
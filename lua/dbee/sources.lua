@@ -293,4 +293,52 @@ function sources.MemorySource:load()
   return self.conns
 end
 
+---@divider -
+
+---Built-In Scratch Source.
+---Provides a "scratch" connection of the current project (working directory):
+---a sqlite database created and kept by the backend, for staging results,
+---importing files and prototyping without a database server.
+---@class ScratchSource: Source
+---@field private dir string directory of scratch databases
+sources.ScratchSource = {}
+
+---@param dir string directory of scratch databases
+---@return Source
+function sources.ScratchSource:new(dir)
+  if not dir or dir == "" then
+    error("no scratch directory provided")
+  end
+  local o = {
+    dir = dir,
+  }
+  setmetatable(o, self)
+  self.__index = self
+  return o
+end
+
+---@package
+---@return string
+function sources.ScratchSource:name()
+  return "scratch"
+end
+
+---@package
+---@return ConnectionParams[]
+function sources.ScratchSource:load()
+  local project = vim.fn.getcwd()
+  -- the directory is a query string value
+  local dir = self.dir:gsub("[%%&+#]", function(c)
+    return string.format("%%%02X", c:byte())
+  end)
+  return {
+    {
+      id = "scratch_source_" .. project,
+      name = "Scratchpad (" .. vim.fn.fnamemodify(project, ":t") .. ")",
+      type = "scratch",
+      url = project .. "?dir=" .. dir,
+    },
+  }
+end
+
 return sources
@@ -15,6 +15,8 @@ local common = require("dbee.ui.common")
 ---@field private hover_close? fun() function that closes the hover window
 ---@field private window_options table<string, any> a table of window options.
 ---@field private buffer_options table<string, any> a table of buffer options.
+---@field private deduplicate boolean show repeated calls of a query as a single record
+---@field private pattern string fuzzy pattern of searched queries
 local CallLogUI = {}
 
 ---@param handler Handler
@@ -43,6 +45,8 @@ function CallLogUI:new(handler, result, opts)
     candies = candies,
     hover_close = function() end,
     current_connection_id = (handler:get_current_connection() or {}).id,
+    deduplicate = opts.deduplicate or false,
+    pattern = "",
     window_options = vim.tbl_extend("force", {
       wrap = false,
       winfixheight = true,
@@ -77,6 +81,10 @@ function CallLogUI:new(handler, result, opts)
     ---@diagnostic disable-next-line
     o:on_current_connection_changed(data)
   end)
+  handler:register_event_listener("call_log_changed", function(data)
+    ---@diagnostic disable-next-line
+    o:on_call_log_changed(data)
+  end)
 
   return o
 end
@@ -88,6 +96,16 @@ function CallLogUI:on_call_state_changed(_)
   self:refresh()
 end
 
+-- event listener for calls added to the call log (e.g. restored from the file)
+---@private
+---@param data { conn_id: connection_id }
+function CallLogUI:on_call_log_changed(data)
+  if data.conn_id ~= self.current_connection_id then
+    return
+  end
+  self:refresh()
+end
+
 -- event listener for current connection change
 ---@private
 ---@param data { conn_id: connection_id }
@@ -159,6 +177,9 @@ function CallLogUI:create_tree(bufnr)
       line:append(make_length(state_preview, 3), candy.icon_highlight)
       line:append(" ┃ ", "NonText")
       line:append(make_length(string.gsub(call.query, "\n", " "), 40), candy.text_highlight)
+      if node.count and node.count > 1 then
+        line:append(" (" .. node.count .. "×)", "NonText")
+      end
 
       return line
     end,
@@ -202,6 +223,32 @@ function CallLogUI:get_actions()
 
       self.handler:call_cancel(call.id)
     end,
+    notify_call = function()
+      local node = self.tree:get_node()
+      if not node then
+        return
+      end
+      local call = node.call
+      if not call then
+        return
+      end
+
+      self.handler:call_notify(call.id)
+      utils.log("info", "you will be notified when the call finishes", "call_log")
+    end,
+    toggle_deduplicate = function()
+      self.deduplicate = not self.deduplicate
+      self:refresh()
+    end,
+    search = function()
+      common.float_prompt({ { name = "pattern", default = self.pattern } }, {
+        title = "Search Call Log",
+        callback = function(res)
+          self.pattern = vim.trim(res.pattern or "")
+          self:refresh()
+        end,
+      })
+    end,
   }
 end
 
@@ -219,24 +266,38 @@ function CallLogUI:refresh()
   if not self.current_connection_id then
     return
   end
-  local calls = self.handler:connection_get_calls(self.current_connection_id)
+
+  local nodes = {}
+  if self.deduplicate or self.pattern ~= "" then
+    -- records of calls grouped by their fingerprints (queries without literals)
+    local stats = self.handler:connection_get_query_stats(self.current_connection_id, { pattern = self.pattern })
+    for _, s in ipairs(stats) do
+      table.insert(
+        nodes,
+        NuiTree.Node { id = tostring(math.random()), call = s.last_call, count = s.count, fingerprint = s.fingerprint }
+      )
+    end
+  else
+    local calls = self.handler:connection_get_calls(self.current_connection_id)
+    table.sort(calls, function(k1, k2)
+      return k1.timestamp_us > k2.timestamp_us
+    end)
+    for _, c in ipairs(calls) do
+      table.insert(nodes, NuiTree.Node { id = tostring(math.random()), call = c })
+    end
+  end
 
   -- dummy node if no calls
-  if vim.tbl_isempty(calls) then
-    self.tree:set_nodes { NuiTree.Node { id = tostring(math.random()), text = "Call log will be displayed here!" } }
+  if vim.tbl_isempty(nodes) then
+    local text = "Call log will be displayed here!"
+    if self.pattern ~= "" then
+      text = "No queries match: " .. self.pattern
+    end
+    self.tree:set_nodes { NuiTree.Node { id = tostring(math.random()), text = text } }
     self.tree:render()
     return
   end
 
-  table.sort(calls, function(k1, k2)
-    return k1.timestamp_us > k2.timestamp_us
-  end)
-
-  local nodes = {}
-  for _, c in ipairs(calls) do
-    table.insert(nodes, NuiTree.Node { id = tostring(math.random()), call = c })
-  end
-
   self.tree:set_nodes(nodes)
   self.tree:render()
 end
@@ -270,6 +331,10 @@ function CallLogUI:configure_preview(bufnr)
       if call.error and call.error ~= "" then
         table.insert(call_summary, string.format("error:                %s", string.gsub(call.error, "\n", " ")))
       end
+      if node.fingerprint then
+        table.insert(call_summary, string.format("fingerprint:          %s", node.fingerprint))
+        table.insert(call_summary, string.format("calls:                %d", node.count))
+      end
 
       self.hover_close = common.float_hover(self.winid, call_summary)
     end,
@@ -4,20 +4,70 @@ local NuiTree = require("nui.tree")
 
 local M = {}
 
+-- First line of a comment, shown next to names of nodes.
+---@param comment? string
+---@return string
+local function comment_suffix(comment)
+  if not comment or comment == vim.NIL or comment == "" then
+    return ""
+  end
+  return "   -- " .. vim.split(comment, "\n", { plain = true })[1]
+end
+
+-- Action which edits the comment of an object or its column.
+---@param handler Handler
+---@param conn_id connection_id
+---@param opts TableOpts
+---@param column string empty for the object itself
+---@param current? string current comment
+---@return drawer_node_action
+local function comment_action(handler, conn_id, opts, column, current)
+  return function(cb, _, input)
+    local name = opts.table
+    if column ~= "" then
+      name = name .. "." .. column
+    end
+    if current == vim.NIL then
+      current = nil
+    end
+    input {
+      title = "Comment of " .. name,
+      default = current or "",
+      on_confirm = function(value)
+        handler:connection_set_comment(conn_id, opts, column, value)
+        cb()
+      end,
+    }
+  end
+end
+
 ---@param parent_id string
 ---@param columns Column[]
+---@param on_comment? fun(column: Column): drawer_node_action action which edits the comment of a column
 ---@return DrawerUINode[]
-local function column_nodes(parent_id, columns)
+local function column_nodes(parent_id, columns, on_comment)
   ---@type DrawerUINode[]
   local nodes = {}
 
   for _, column in ipairs(columns) do
+    local details = { column.type }
+    if column.primary_key then
+      table.insert(details, "pk")
+    end
+    if column.not_null then
+      table.insert(details, "not null")
+    end
+    if column.default and column.default ~= "" then
+      table.insert(details, "default " .. column.default)
+    end
+
     table.insert(
       nodes,
       NuiTree.Node {
         id = parent_id .. column.type .. column.name,
-        name = column.name .. "   [" .. column.type .. "]",
+        name = column.name .. "   [" .. table.concat(details, ", ") .. "]" .. comment_suffix(column.comment),
         type = "column",
+        action_2 = on_comment and on_comment(column),
       }
     )
   end
@@ -25,11 +75,615 @@ local function column_nodes(parent_id, columns)
   return nodes
 end
 
+-- Action which opens the source of a database object in a buffer.
+-- Shows the definition of an object in a floating editor.
+---@param definition string
+---@param opts TableOpts
+local function show_definition(definition, opts)
+  local file = vim.fn.tempname() .. ".sql"
+  vim.fn.writefile(vim.split(definition, "\n"), file)
+  local title = opts.table
+  if opts.schema and opts.schema ~= "" then
+    title = opts.schema .. "." .. title
+  end
+  common.float_editor(file, { title = title })
+end
+
+---@param handler Handler
+---@param conn_id connection_id
+---@param opts TableOpts
+---@return drawer_node_action
+local function definition_action(handler, conn_id, opts)
+  return function(cb)
+    show_definition(handler:connection_get_definition(conn_id, opts), opts)
+    cb()
+  end
+end
+
+-- Runs an object action advertised by the backend after confirming it
+-- according to its confirmation level.
+---@param handler Handler
+---@param conn_id connection_id
+---@param result ResultUI
+---@param opts TableOpts
+---@param action ObjectAction
+---@param cb fun()
+---@param select menu_select
+---@param input menu_input
+local function run_object_action(handler, conn_id, result, opts, action, cb, select, input)
+  local function run(confirmation)
+    local out = handler:connection_run_object_action(conn_id, opts, action.id, confirmation)
+    if out.call and out.call ~= vim.NIL then
+      result:set_call(out.call)
+    elseif out.text ~= "" then
+      show_definition(out.text, opts)
+    end
+    cb()
+  end
+
+  -- warn about objects which would break when dropping
+  local warning = ""
+  if action.id == "drop" then
+    local ok, deps = pcall(handler.connection_get_dependencies, handler, conn_id, opts)
+    if ok and #deps.dependents > 0 then
+      warning = " (" .. #deps.dependents .. " dependent objects)"
+    end
+  end
+
+  if action.confirmation == "simple" then
+    select {
+      title = action.title .. " " .. opts.table .. warning .. "?",
+      items = { "Yes", "No" },
+      on_confirm = function(selection)
+        if selection == "Yes" then
+          run("yes")
+        end
+      end,
+    }
+  elseif action.confirmation == "typed" then
+    input {
+      title = "Type " .. opts.table .. " to confirm: " .. action.title .. warning,
+      on_confirm = function(value)
+        if value == opts.table then
+          run(value)
+        end
+      end,
+    }
+  else
+    run("")
+  end
+end
+
+---@param list any
+---@return string
+local function join(list)
+  if not list or list == vim.NIL then
+    return ""
+  end
+  return table.concat(list, ", ")
+end
+
+-- Nodes with indexes, constraints, foreign keys, triggers and grants of a table.
+-- Nothing is returned if the database doesn't support listing them.
+---@param handler Handler
+---@param conn_id connection_id
+---@param parent_id string
+---@param opts TableOpts
+---@return DrawerUINode[]
+local function table_detail_nodes(handler, conn_id, parent_id, opts)
+  ---@type DrawerUINode[]
+  local nodes = {}
+
+  local ok, indexes = false, {}
+  if handler:connection_supports(conn_id, "indexes") then
+    ok, indexes = pcall(handler.connection_get_indexes, handler, conn_id, opts)
+  end
+  if ok and #indexes > 0 then
+    local children = {}
+    for _, index in ipairs(indexes) do
+      local name = index.name .. "   (" .. join(index.columns) .. ")"
+      if index.primary then
+        name = name .. " [primary]"
+      elseif index.unique then
+        name = name .. " [unique]"
+      end
+      local node = NuiTree.Node {
+        id = parent_id .. "__index_" .. index.name,
+        name = name,
+        type = "index",
+      } --[[@as DrawerUINode]]
+      node.action_1 = definition_action(handler, conn_id, {
+        table = index.name,
+        schema = opts.schema,
+        materialization = "index",
+      })
+      table.insert(children, node)
+    end
+    table.insert(nodes, NuiTree.Node({ id = parent_id .. "__indexes__", name = "indexes", type = "" }, children))
+  end
+
+  local constraints = {}
+  if handler:connection_supports(conn_id, "indexes") then
+    ok, constraints = pcall(handler.connection_get_constraints, handler, conn_id, opts)
+  end
+  if ok and #constraints > 0 then
+    local keys, foreign = {}, {}
+    for _, con in ipairs(constraints) do
+      local id = parent_id .. "__constraint_" .. con.name
+      if con.type == "foreign_key" then
+        local target = con.referenced_table
+        if con.referenced_schema and con.referenced_schema ~= "" then
+          target = con.referenced_schema .. "." .. target
+        end
+        local name = con.name .. "   (" .. join(con.columns) .. ") -> " .. target .. " (" .. join(con.referenced_columns) .. ")"
+        table.insert(foreign, NuiTree.Node { id = id, name = name, type = "foreign_key" })
+      else
+        local detail = join(con.columns)
+        if con.definition and con.definition ~= "" then
+          detail = con.definition
+        end
+        local name = con.name .. "   [" .. con.type:gsub("_", " ") .. "] " .. detail
+        table.insert(keys, NuiTree.Node { id = id, name = name, type = "constraint" })
+      end
+    end
+
+    if #keys > 0 then
+      table.insert(nodes, NuiTree.Node({ id = parent_id .. "__constraints__", name = "constraints", type = "" }, keys))
+    end
+    if #foreign > 0 then
+      table.insert(nodes, NuiTree.Node({ id = parent_id .. "__foreign_keys__", name = "foreign keys", type = "" }, foreign))
+    end
+  end
+
+  local triggers = {}
+  ok = false
+  if handler:connection_supports(conn_id, "triggers") then
+    ok, triggers = pcall(handler.connection_get_triggers, handler, conn_id, opts)
+  end
+  if ok and #triggers > 0 then
+    local children = {}
+    for _, trigger in ipairs(triggers) do
+      local name = trigger.name .. "   [" .. trigger.timing .. " " .. trigger.event .. "]"
+      if trigger["function"] and trigger["function"] ~= "" then
+        name = name .. " -> " .. trigger["function"]
+      end
+      local node = NuiTree.Node {
+        id = parent_id .. "__trigger_" .. trigger.name,
+        name = name,
+        type = "trigger",
+      } --[[@as DrawerUINode]]
+      node.action_1 = definition_action(handler, conn_id, {
+        table = trigger.name,
+        schema = opts.schema,
+        materialization = "trigger",
+      })
+      table.insert(children, node)
+    end
+    table.insert(nodes, NuiTree.Node({ id = parent_id .. "__triggers__", name = "triggers", type = "" }, children))
+  end
+
+  local grants = {}
+  ok = false
+  if handler:connection_supports(conn_id, "grants") then
+    ok, grants = pcall(handler.connection_get_grants, handler, conn_id, opts)
+  end
+  if ok and #grants > 0 then
+    -- privileges grouped by grantee
+    local privileges, grantees = {}, {}
+    for _, grant in ipairs(grants) do
+      if not privileges[grant.grantee] then
+        privileges[grant.grantee] = {}
+        table.insert(grantees, grant.grantee)
+      end
+      local privilege = grant.privilege
+      if grant.grantable then
+        privilege = privilege .. " (grantable)"
+      end
+      table.insert(privileges[grant.grantee], privilege)
+    end
+
+    local children = {}
+    for _, grantee in ipairs(grantees) do
+      table.insert(
+        children,
+        NuiTree.Node {
+          id = parent_id .. "__grant_" .. grantee,
+          name = grantee .. "   [" .. join(privileges[grantee]) .. "]",
+          type = "grant",
+        }
+      )
+    end
+    table.insert(nodes, NuiTree.Node({ id = parent_id .. "__grants__", name = "grants", type = "" }, children))
+  end
+
+  return nodes
+end
+
+-- Node with users and roles of the database.
+-- Nothing is returned if the database doesn't support listing them.
+---@param handler Handler
+---@param conn_id connection_id
+---@return DrawerUINode[]
+local function role_nodes(handler, conn_id)
+  local ok, roles = pcall(handler.connection_get_roles, handler, conn_id)
+  if not ok or #roles < 1 then
+    return {}
+  end
+
+  local children = {}
+  for _, role in ipairs(roles) do
+    local details = {}
+    if role.superuser then
+      table.insert(details, "superuser")
+    end
+    if role.can_login then
+      table.insert(details, "login")
+    end
+    if role.member_of and role.member_of ~= vim.NIL and #role.member_of > 0 then
+      table.insert(details, "member of " .. join(role.member_of))
+    end
+
+    local name = role.name
+    if #details > 0 then
+      name = name .. "   [" .. table.concat(details, ", ") .. "]"
+    end
+    table.insert(children, NuiTree.Node { id = conn_id .. "__role_" .. role.name, name = name, type = "role" })
+  end
+
+  return { NuiTree.Node({ id = conn_id .. "__roles__", name = "roles", type = "" }, children) }
+end
+
+-- Node with foreign (linked) servers of the database. Objects of a server
+-- are listed on expansion, servers which are not accessible have no children.
+---@param handler Handler
+---@param conn_id connection_id
+---@return DrawerUINode[]
+local function foreign_server_nodes(handler, conn_id)
+  local ok, servers = pcall(handler.connection_get_foreign_servers, handler, conn_id)
+  if not ok or #servers < 1 then
+    return {}
+  end
+
+  local children = {}
+  for _, server in ipairs(servers) do
+    local details = { server.wrapper }
+    if server.options ~= "" then
+      table.insert(details, server.options)
+    end
+
+    local server_id = conn_id .. "__foreign_server_" .. server.name
+    local node = NuiTree.Node {
+      id = server_id,
+      name = server.name .. "   [" .. table.concat(details, ", ") .. "]",
+      type = "foreign_server",
+    } --[[@as DrawerUINode]]
+    node.lazy_children = function()
+      local ok_objects, objects = pcall(handler.connection_get_foreign_objects, handler, conn_id, server.name)
+      if not ok_objects then
+        return {}
+      end
+
+      local nodes = {}
+      for _, object in ipairs(objects) do
+        local name = object.name
+        if object.schema ~= "" then
+          name = object.schema .. "." .. name
+        end
+        table.insert(nodes, NuiTree.Node { id = server_id .. "__" .. name, name = name, type = object.type })
+      end
+      return nodes
+    end
+    table.insert(children, node)
+  end
+
+  return { NuiTree.Node({ id = conn_id .. "__foreign_servers__", name = "foreign servers", type = "" }, children) }
+end
+
+-- Node with migrations of the project and their status on the connection.
+-- Nothing is returned if there are no migrations.
+---@param handler Handler
+---@param conn_id connection_id
+---@return DrawerUINode[]
+local function migration_nodes(handler, conn_id)
+  local ok, status = pcall(handler.connection_get_migrations, handler, conn_id)
+  if not ok or not status or status == vim.NIL then
+    return {}
+  end
+
+  local id = conn_id .. "__migrations__"
+  local pending = 0
+  local children = {}
+  for _, migration in ipairs(status.migrations) do
+    if not migration.applied then
+      pending = pending + 1
+    end
+
+    local state = migration.applied and "applied" or "pending"
+    local action = migration.applied and "Roll back" or "Apply"
+    table.insert(
+      children,
+      NuiTree.Node {
+        id = id .. migration.version,
+        name = migration.version .. "_" .. migration.name .. "   [" .. state .. "]",
+        type = "migration",
+        action_1 = function(cb, select)
+          local items = { action }
+          local files = {}
+          for _, file in ipairs(migration.files) do
+            local item = "Open " .. vim.fn.fnamemodify(file, ":t")
+            files[item] = file
+            table.insert(items, item)
+          end
+          select {
+            title = "Migration " .. migration.version,
+            items = items,
+            on_confirm = function(selection)
+              if files[selection] then
+                common.float_editor(files[selection], { title = vim.fn.fnamemodify(files[selection], ":t") })
+                return
+              elseif selection ~= action then
+                return
+              end
+              select {
+                title = action .. " " .. migration.version .. "_" .. migration.name .. "?",
+                items = { "Yes", "No" },
+                on_confirm = function(confirmation)
+                  if confirmation ~= "Yes" then
+                    return
+                  end
+                  if migration.applied then
+                    handler:connection_rollback_migration(conn_id, migration.version)
+                  else
+                    handler:connection_apply_migration(conn_id, migration.version)
+                  end
+                  cb()
+                end,
+              }
+            end,
+          }
+        end,
+      }
+    )
+  end
+
+  local details = { status.tool, pending .. " pending" }
+  if status.dirty then
+    table.insert(details, "dirty")
+  end
+  return {
+    NuiTree.Node({ id = id, name = "migrations   [" .. table.concat(details, ", ") .. "]", type = "" }, children),
+  }
+end
+
+-- Formats a duration in seconds (e.g. 3d 4h 12m).
+---@param seconds integer
+---@return string
+local function format_uptime(seconds)
+  local days = math.floor(seconds / 86400)
+  local hours = math.floor(seconds % 86400 / 3600)
+  local minutes = math.floor(seconds % 3600 / 60)
+  if days > 0 then
+    return string.format("%dd %dh %dm", days, hours, minutes)
+  elseif hours > 0 then
+    return string.format("%dh %dm", hours, minutes)
+  end
+  return string.format("%dm", minutes)
+end
+
+-- Node with version, uptime, extensions and settings of the server.
+-- Nothing is returned if the database doesn't support describing the server.
+---@param handler Handler
+---@param conn_id connection_id
+---@return DrawerUINode[]
+local function server_info_nodes(handler, conn_id)
+  if not handler:connection_supports(conn_id, "server_info") then
+    return {}
+  end
+  local ok, info = pcall(handler.connection_get_server_info, handler, conn_id)
+  if not ok or not info then
+    return {}
+  end
+
+  local id = conn_id .. "__server_info__"
+  local children = {}
+  if info.version ~= "" then
+    -- some servers report a multiline version
+    local version = vim.trim(vim.split(info.version, "\n", { trimempty = true })[1] or "")
+    table.insert(children, NuiTree.Node { id = id .. "version", name = "version: " .. version, type = "info" })
+  end
+  if info.uptime >= 0 then
+    table.insert(
+      children,
+      NuiTree.Node { id = id .. "uptime", name = "uptime: " .. format_uptime(info.uptime), type = "info" }
+    )
+  end
+
+  if info.extensions and info.extensions ~= vim.NIL and #info.extensions > 0 then
+    local extensions = {}
+    for _, ext in ipairs(info.extensions) do
+      local name = ext.name
+      if ext.version ~= "" then
+        name = name .. "   [" .. ext.version .. "]"
+      end
+      table.insert(extensions, NuiTree.Node { id = id .. "extension_" .. ext.name, name = name, type = "info" })
+    end
+    table.insert(children, NuiTree.Node({ id = id .. "extensions", name = "extensions", type = "" }, extensions))
+  end
+
+  if info.settings and info.settings ~= vim.NIL and #info.settings > 0 then
+    local settings = {}
+    for _, setting in ipairs(info.settings) do
+      local name = setting.name .. " = " .. setting.value
+      table.insert(settings, NuiTree.Node { id = id .. "setting_" .. setting.name, name = name, type = "info" })
+    end
+    table.insert(children, NuiTree.Node({ id = id .. "settings", name = "settings", type = "" }, settings))
+  end
+
+  return { NuiTree.Node({ id = id, name = "server info", type = "" }, children) }
+end
+
+-- Formats a number with a metric suffix (e.g. 1.2k).
+---@param n integer
+---@param units string[]
+---@param base integer
+---@return string
+local function humanize(n, units, base)
+  local unit = 1
+  while n >= base and unit < #units do
+    n = n / base
+    unit = unit + 1
+  end
+  if unit == 1 then
+    return string.format("%d%s", n, units[unit])
+  end
+  return string.format("%.1f%s", n, units[unit])
+end
+
+-- Formats estimated row count and size of a table.
+---@param stats? { rows: integer, size: integer }
+---@return string[]
+local function table_stats_details(stats)
+  if not stats then
+    return {}
+  end
+
+  local details = {}
+  if stats.rows and stats.rows >= 0 then
+    table.insert(details, "~" .. humanize(stats.rows, { "", "k", "M", "B" }, 1000) .. " rows")
+  end
+  if stats.size and stats.size >= 0 then
+    table.insert(details, humanize(stats.size, { " B", " KiB", " MiB", " GiB", " TiB" }, 1024))
+  end
+  return details
+end
+
+-- Node with partitions of a partitioned table. Partitions can be detached
+-- (action_1) and dropped (action_3) after typing their name.
+---@param handler Handler
+---@param conn_id connection_id
+---@param parent_id string
+---@param opts TableOpts
+---@return DrawerUINode[]
+local function partition_nodes(handler, conn_id, parent_id, opts)
+  if not handler:connection_supports(conn_id, "partitions") then
+    return {}
+  end
+  local ok, partitions = pcall(handler.connection_get_partitions, handler, conn_id, opts)
+  if not ok or #partitions < 1 then
+    return {}
+  end
+
+  ---@param title string
+  ---@param fn fun(handler: Handler, id: connection_id, opts: TableOpts, partition: Partition, confirmation: string)
+  ---@param partition Partition
+  ---@return drawer_node_action
+  local function confirmed(title, fn, partition)
+    return function(cb, _, input)
+      input {
+        title = "Type " .. partition.name .. " to confirm: " .. title,
+        on_confirm = function(value)
+          if value == partition.name then
+            fn(handler, conn_id, opts, partition, value)
+          end
+          cb()
+        end,
+      }
+    end
+  end
+
+  local children = {}
+  for _, partition in ipairs(partitions) do
+    local details = table_stats_details(partition)
+    if partition.expression ~= "" then
+      table.insert(details, 1, partition.expression)
+    end
+    local name = partition.name
+    if #details > 0 then
+      name = name .. "   [" .. table.concat(details, ", ") .. "]"
+    end
+
+    table.insert(
+      children,
+      NuiTree.Node {
+        id = parent_id .. "__partition_" .. partition.schema .. "." .. partition.name,
+        name = name,
+        type = "partition",
+        action_1 = confirmed("Detach Partition", handler.connection_detach_partition, partition),
+        action_3 = confirmed("Drop Partition", handler.connection_drop_partition, partition),
+      }
+    )
+  end
+
+  return { NuiTree.Node({ id = parent_id .. "__partitions__", name = "partitions", type = "" }, children) }
+end
+
+-- Trees of objects the object depends on and objects which depend on it.
+-- Nothing is returned if the database doesn't support listing dependencies.
+---@param handler Handler
+---@param conn_id connection_id
+---@param parent_id string
+---@param opts TableOpts
+---@return DrawerUINode[]
+local function dependency_nodes(handler, conn_id, parent_id, opts)
+  if not handler:connection_supports(conn_id, "dependencies") then
+    return {}
+  end
+  local ok, deps = pcall(handler.connection_get_dependencies, handler, conn_id, opts)
+  if not ok or (#deps.depends_on < 1 and #deps.dependents < 1) then
+    return {}
+  end
+
+  ---@param list DependencyNode[]
+  ---@param id string
+  ---@return DrawerUINode[]
+  local function to_nodes(list, id)
+    local nodes = {}
+    for _, dep in ipairs(list or {}) do
+      local node_id = id .. "__dependency_" .. dep.schema .. "." .. dep.name .. dep.type
+      local name = dep.name
+      if dep.schema ~= "" and dep.schema ~= opts.schema then
+        name = dep.schema .. "." .. name
+      end
+      if dep.cycle then
+        name = name .. "   [cycle]"
+      end
+      table.insert(nodes, NuiTree.Node({ id = node_id, name = name, type = dep.type }, to_nodes(dep.children, node_id)))
+    end
+    return nodes
+  end
+
+  local id = parent_id .. "__dependencies__"
+  local children = {}
+  if #deps.depends_on > 0 then
+    local group_id = id .. "depends_on"
+    local group = NuiTree.Node({ id = group_id, name = "depends on", type = "" }, to_nodes(deps.depends_on, group_id))
+    table.insert(children, group)
+  end
+  if #deps.dependents > 0 then
+    local group_id = id .. "dependents"
+    local group = NuiTree.Node({ id = group_id, name = "dependents", type = "" }, to_nodes(deps.dependents, group_id))
+    table.insert(children, group)
+  end
+
+  return { NuiTree.Node({ id = id, name = "dependencies", type = "" }, children) }
+end
+
 ---@param handler Handler
 ---@param conn ConnectionParams
 ---@param result ResultUI
+---@param opts? StructureOpts
 ---@return DrawerUINode[]
-local function connection_nodes(handler, conn, result)
+local function connection_nodes(handler, conn, result, opts)
+  -- estimated table sizes are optional, they are refreshed with the structure
+  ---@type table<string, TableStats>
+  local table_stats = {}
+  local ok, stats = pcall(handler.connection_get_table_stats, handler, conn.id)
+  if ok then
+    for _, st in ipairs(stats) do
+      table_stats[st.schema .. "." .. st.table] = st
+    end
+  end
+
   ---@param structs DBStructure[]
   ---@param parent_id string
   ---@return DrawerUINode[]
@@ -47,41 +701,121 @@ local function connection_nodes(handler, conn, result)
 
     for _, struct in ipairs(structs) do
       local node_id = (parent_id or "") .. "__connection_" .. struct.name .. struct.schema .. struct.type .. "__"
+      local name = struct.name
+      local details = table_stats_details(table_stats[struct.schema .. "." .. struct.name])
+      if struct.status and struct.status ~= vim.NIL and struct.status ~= "" then
+        table.insert(details, 1, struct.status)
+      end
+      if #details > 0 then
+        name = name .. "   [" .. table.concat(details, ", ") .. "]"
+      end
+      name = name .. comment_suffix(struct.comment)
+
       local node = NuiTree.Node({
         id = node_id,
-        name = struct.name,
+        name = name,
         schema = struct.schema,
         type = struct.type,
       }, to_tree_nodes(struct.children, node_id)) --[[@as DrawerUINode]]
 
-      if struct.type == "table" or struct.type == "view" then
+      -- children of large catalogs are loaded on expansion
+      if struct.lazy then
+        node.lazy_children = function()
+          return to_tree_nodes(handler:connection_get_structure_children(conn.id, struct), node_id)
+        end
+        node.refresh = function()
+          handler:connection_refresh_structure(conn.id, struct)
+        end
+      end
+
+      if struct.type == "table" or struct.type == "view" or struct.type == "materialized_view" then
         local table_opts = { table = struct.name, schema = struct.schema, materialization = struct.type }
 
-        -- table helpers
-        node.action_1 = function(cb, select)
+        node.action_2 = comment_action(handler, conn.id, table_opts, "", struct.comment)
+
+        -- object actions advertised by the backend, followed by table helpers
+        node.action_1 = function(cb, select, input)
+          local ok, object_actions = pcall(handler.connection_get_object_actions, handler, conn.id, table_opts)
+          if not ok then
+            object_actions = {}
+          end
+          ---@type table<string, ObjectAction>
+          local actions = {}
+          local items = {}
+          for _, action in ipairs(object_actions) do
+            actions[action.title] = action
+            table.insert(items, action.title)
+          end
+
+          -- statements to edit, opened in a floating editor
+          ---@type table<string, string>
+          local scaffolds = {}
+          local ok_scaffolds, scaffold_list = pcall(handler.connection_get_scaffolds, handler, conn.id, table_opts)
+          if ok_scaffolds then
+            for _, scaffold in ipairs(scaffold_list) do
+              local title = "New " .. scaffold.name:upper()
+              scaffolds[title] = scaffold.query
+              table.insert(items, title)
+            end
+          end
+
           local helpers = handler:connection_get_helpers(conn.id, table_opts)
-          local items = vim.tbl_keys(helpers)
-          table.sort(items)
+          local helper_items = vim.tbl_keys(helpers)
+          table.sort(helper_items)
+          vim.list_extend(items, helper_items)
 
           select {
-            title = "Select a Query",
+            title = "Select an Action",
             items = items,
             on_confirm = function(selection)
+              local action = actions[selection]
+              if action then
+                run_object_action(handler, conn.id, result, table_opts, action, cb, select, input)
+                return
+              end
+              if scaffolds[selection] then
+                show_definition(scaffolds[selection], table_opts)
+                cb()
+                return
+              end
               local call = handler:connection_execute(conn.id, helpers[selection])
               result:set_call(call)
               cb()
             end,
             on_yank = function(selection)
-              vim.fn.setreg(vim.v.register, helpers[selection])
+              local action = actions[selection]
+              if action and action.id == "definition" then
+                vim.fn.setreg(vim.v.register, handler:connection_get_definition(conn.id, table_opts))
+                return
+              end
+              local query = scaffolds[selection] or helpers[selection]
+              if query then
+                vim.fn.setreg(vim.v.register, query)
+              end
             end,
           }
         end
 
         node.lazy_children = function()
-          return column_nodes(node_id, handler:connection_get_columns(conn.id, table_opts))
+          local columns = handler:connection_get_columns(conn.id, table_opts)
+          local children = column_nodes(node_id, columns, function(column)
+            return comment_action(handler, conn.id, table_opts, column.name, column.comment)
+          end)
+          if struct.type == "table" then
+            vim.list_extend(children, table_detail_nodes(handler, conn.id, node_id, table_opts))
+            vim.list_extend(children, partition_nodes(handler, conn.id, node_id, table_opts))
+          end
+          vim.list_extend(children, dependency_nodes(handler, conn.id, node_id, table_opts))
+          return children
         end
       end
 
+      if struct.type == "function" or struct.type == "procedure" or struct.type == "sequence" then
+        local routine_opts = { table = struct.name, schema = struct.schema, materialization = struct.type }
+
+        node.action_1 = definition_action(handler, conn.id, routine_opts)
+      end
+
       table.insert(nodes, node)
     end
 
@@ -89,13 +823,30 @@ local function connection_nodes(handler, conn, result)
   end
 
   -- recursively parse structure to drawer nodes
-  local nodes = to_tree_nodes(handler:connection_get_structure(conn.id), conn.id)
+  local nodes = to_tree_nodes(handler:connection_get_structure(conn.id, opts), conn.id)
+
+  -- users and roles
+  vim.list_extend(nodes, role_nodes(handler, conn.id))
+
+  -- foreign data wrappers and linked servers
+  vim.list_extend(nodes, foreign_server_nodes(handler, conn.id))
+
+  -- version, extensions and settings of the server
+  vim.list_extend(nodes, server_info_nodes(handler, conn.id))
+
+  -- migrations of the project
+  vim.list_extend(nodes, migration_nodes(handler, conn.id))
 
   -- database switching
-  local current_db, available_dbs = handler:connection_list_databases(conn.id)
+  -- only the selected database is introspected, others are listed on expansion
+  local current_db, available_dbs = "", {}
+  if handler:connection_supports(conn.id, "database_switching") then
+    current_db, available_dbs = handler:connection_list_databases(conn.id)
+  end
   if current_db ~= "" and #available_dbs > 0 then
+    local switch_id = conn.id .. "_database_switch__"
     local ly = NuiTree.Node {
-      id = conn.id .. "_database_switch__",
+      id = switch_id,
       name = current_db,
       type = "database_switch",
       action_1 = function(cb, select)
@@ -108,6 +859,24 @@ local function connection_nodes(handler, conn, result)
           end,
         }
       end,
+      lazy_children = function()
+        local children = {}
+        for _, db in ipairs(available_dbs) do
+          table.insert(
+            children,
+            NuiTree.Node {
+              id = switch_id .. db,
+              name = db,
+              type = "database",
+              action_1 = function(cb)
+                handler:connection_select_database(conn.id, db)
+                cb()
+              end,
+            }
+          )
+        end
+        return children
+      end,
     } --[[@as DrawerUINode]]
     table.insert(nodes, 1, ly)
   end
@@ -117,8 +886,9 @@ end
 
 ---@param handler Handler
 ---@param result ResultUI
+---@param opts? StructureOpts
 ---@return DrawerUINode[]
-local function handler_real_nodes(handler, result)
+local function handler_real_nodes(handler, result, opts)
   ---@type DrawerUINode[]
   local nodes = {}
 
@@ -243,7 +1013,11 @@ local function handler_real_nodes(handler, result)
         -- remove connection
         action_3 = delete_action,
         lazy_children = function()
-          return connection_nodes(handler, conn, result)
+          return connection_nodes(handler, conn, result, opts)
+        end,
+        -- drop the cached structure
+        refresh = function()
+          handler:connection_refresh_structure(conn.id)
         end,
       } --[[@as DrawerUINode]]
 
@@ -296,13 +1070,14 @@ end
 
 ---@param handler Handler
 ---@param result ResultUI
+---@param opts? StructureOpts options of structure requests
 ---@return DrawerUINode[]
-function M.handler_nodes(handler, result)
+function M.handler_nodes(handler, result, opts)
   -- in case there are no sources defined, return helper nodes
   if #handler:get_sources() < 1 then
     return handler_help_nodes()
   end
-  return handler_real_nodes(handler, result)
+  return handler_real_nodes(handler, result, opts)
 end
 
 -- whitespace between nodes
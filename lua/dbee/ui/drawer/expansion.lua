@@ -4,28 +4,27 @@ local M = {}
 ---@param tree NuiTree tree to apply the expansion map to
 ---@param expansion table<string, boolean> expansion map ( id:is_expanded mapping )
 function M.set(tree, expansion)
-  -- first pass: load lazy_loaded children
-  for id, t in pairs(expansion) do
-    if t then
-      local node = tree:get_node(id) --[[@as DrawerUINode]]
-      if node then
-        -- if function for getting layout exist, call it
-        if type(node.lazy_children) == "function" then
-          tree:set_nodes(node.lazy_children(), node.id)
-        end
-      end
+  -- walk the tree top-down, so that lazy loaded children of expanded nodes
+  -- exist before their own expansion is restored
+  local function process(node)
+    if not expansion[node:get_id()] then
+      return
     end
-  end
 
-  -- second pass: expand nodes
-  for id, t in pairs(expansion) do
-    if t then
-      local node = tree:get_node(id) --[[@as DrawerUINode]]
-      if node then
-        node:expand()
-      end
+    -- if function for getting layout exist, call it
+    if type(node.lazy_children) == "function" then
+      tree:set_nodes(node.lazy_children(), node:get_id())
+    end
+    node:expand()
+
+    for _, n in ipairs(tree:get_nodes(node:get_id())) do
+      process(n)
     end
   end
+
+  for _, node in ipairs(tree:get_nodes()) do
+    process(node)
+  end
 end
 
 -- gets an expansion config to restore the expansion on new nodes
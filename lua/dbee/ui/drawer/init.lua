@@ -4,6 +4,7 @@ local common = require("dbee.ui.common")
 local menu = require("dbee.ui.drawer.menu")
 local convert = require("dbee.ui.drawer.convert")
 local expansion = require("dbee.ui.drawer.expansion")
+local utils = require("dbee.utils")
 
 -- action function of drawer nodes
 ---@alias drawer_node_action fun(cb: fun(), select: menu_select, input: menu_input)
@@ -12,11 +13,12 @@ local expansion = require("dbee.ui.drawer.expansion")
 ---@class DrawerUINode: NuiTree.Node
 ---@field id string unique identifier
 ---@field name string display name
----@field type ""|"table"|"view"|"column"|"history"|"note"|"connection"|"database_switch"|"add"|"edit"|"remove"|"help"|"source"|"separator" type of node
+---@field type ""|"table"|"view"|"materialized_view"|"function"|"procedure"|"sequence"|"type"|"enum_value"|"column"|"index"|"constraint"|"foreign_key"|"trigger"|"partition"|"grant"|"role"|"foreign_server"|"migration"|"info"|"history"|"note"|"connection"|"database_switch"|"database"|"add"|"edit"|"remove"|"help"|"source"|"separator" type of node
 ---@field action_1? drawer_node_action primary action if function takes a second selection parameter, pick_items get picked before the call
 ---@field action_2? drawer_node_action secondary action if function takes a second selection parameter, pick_items get picked before the call
 ---@field action_3? drawer_node_action tertiary action if function takes a second selection parameter, pick_items get picked before the call
 ---@field lazy_children? fun():DrawerUINode[] lazy loaded child nodes
+---@field refresh? fun() drops cached data of the node and its children (called by the "refresh" action)
 
 ---@class DrawerUI
 ---@field private tree NuiTree
@@ -26,6 +28,7 @@ local expansion = require("dbee.ui.drawer.expansion")
 ---@field private mappings key_mapping[]
 ---@field private candies table<string, Candy> map of eye-candy stuff (icons, highlight)
 ---@field private disable_help boolean show help or not
+---@field private include_system boolean show system schemas and objects
 ---@field private winid? integer
 ---@field private bufnr integer
 ---@field private current_conn_id? connection_id current active connection
@@ -68,6 +71,7 @@ function DrawerUI:new(handler, editor, result, opts)
     mappings = opts.mappings or {},
     candies = candies,
     disable_help = opts.disable_help or false,
+    include_system = opts.include_system or false,
     current_conn_id = current_conn.id,
     current_note_id = current_note.id,
     window_options = vim.tbl_extend("force", {
@@ -105,6 +109,14 @@ function DrawerUI:new(handler, editor, result, opts)
     o:on_current_note_changed(data)
   end)
 
+  handler:register_event_listener("connection_state_changed", function(data)
+    o:on_connection_state_changed(data)
+  end)
+
+  handler:register_event_listener("structure_refreshed", function(data)
+    o:on_structure_refreshed(data)
+  end)
+
   return o
 end
 
@@ -119,6 +131,23 @@ function DrawerUI:on_current_connection_changed(data)
   self:refresh()
 end
 
+-- event listener for added and removed connections
+---@private
+---@param _ { conn_id: connection_id, state: string }
+function DrawerUI:on_connection_state_changed(_)
+  self:refresh()
+end
+
+-- event listener for structure loaded in the background after a refresh
+---@private
+---@param data { conn_id: connection_id, schema: string, error?: string }
+function DrawerUI:on_structure_refreshed(data)
+  if data.error then
+    utils.log("warn", "refreshing structure failed: " .. data.error, "drawer")
+  end
+  self:refresh()
+end
+
 -- event listener for current note change
 ---@private
 ---@param data { note_id: note_id }
@@ -245,6 +274,21 @@ function DrawerUI:get_actions()
 
   return {
     refresh = function()
+      -- drop cached data of the closest node under cursor which holds any
+      local node = self.tree:get_node() --[[@as DrawerUINode]]
+      while node do
+        if type(node.refresh) == "function" then
+          node.refresh()
+          break
+        end
+        local parent_id = node:get_parent_id()
+        node = parent_id and self.tree:get_node(parent_id) --[[@as DrawerUINode]]
+      end
+
+      self:refresh()
+    end,
+    toggle_system = function()
+      self.include_system = not self.include_system
       self:refresh()
     end,
     action_1 = function()
@@ -318,7 +362,7 @@ function DrawerUI:refresh()
     table.insert(nodes, ly)
   end
   table.insert(nodes, convert.separator_node())
-  for _, ly in ipairs(convert.handler_nodes(self.handler, self.result)) do
+  for _, ly in ipairs(convert.handler_nodes(self.handler, self.result, { include_system = self.include_system })) do
     table.insert(nodes, ly)
   end
 
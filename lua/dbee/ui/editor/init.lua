@@ -7,6 +7,29 @@ local welcome = require("dbee.ui.editor.welcome")
 ---@alias note_id string
 ---@alias note_details { id: note_id, name: string, file: string, bufnr: integer? }
 
+-- Where the query of a call starts in a note (0-based).
+---@alias query_origin { bufnr: integer, row: integer, col: integer }
+
+-- namespace of diagnostics of failed queries
+local diagnostics_ns = vim.api.nvim_create_namespace("dbee_query_errors")
+local lint_ns = vim.api.nvim_create_namespace("dbee_lint")
+
+-- Formats a number of bytes with binary units (e.g. "1.5 GiB").
+---@param n integer
+---@return string
+local function format_bytes(n)
+  local units = { "B", "KiB", "MiB", "GiB", "TiB", "PiB" }
+  local i = 1
+  while n >= 1024 and i < #units do
+    n = n / 1024
+    i = i + 1
+  end
+  if i == 1 then
+    return string.format("%d %s", n, units[i])
+  end
+  return string.format("%.1f %s", n, units[i])
+end
+
 ---@class EditorUI
 ---@field private handler Handler
 ---@field private result ResultUI
@@ -18,6 +41,7 @@ local welcome = require("dbee.ui.editor.welcome")
 ---@field private event_callbacks table<editor_event_name, event_listener[]> callbacks for events
 ---@field private window_options table<string, any> a table of window options.
 ---@field private buffer_options table<string, any> a table of buffer options for all notes.
+---@field private query_origins table<call_id, query_origin> origins of running calls
 local EditorUI = {}
 
 ---@param handler Handler
@@ -41,6 +65,7 @@ function EditorUI:new(handler, result, opts)
     result = result,
     notes = {},
     event_callbacks = {},
+    query_origins = {},
     directory = opts.directory or vim.fn.stdpath("state") .. "/dbee/notes",
     mappings = opts.mappings,
     window_options = vim.tbl_extend("force", {}, opts.window_options or {}),
@@ -52,6 +77,10 @@ function EditorUI:new(handler, result, opts)
   setmetatable(o, self)
   self.__index = self
 
+  handler:register_event_listener("call_state_changed", function(data)
+    o:on_call_state_changed(data)
+  end)
+
   -- search for existing notes
   o:search_existing_namespaces()
 
@@ -141,8 +170,182 @@ function EditorUI:get_actions()
         return
       end
       local call = self.handler:connection_execute(conn.id, query)
+      self:track_call(call, { bufnr = bufnr, row = 0, col = 0 })
+      self.result:set_call(call)
+    end,
+    run_statement = function()
+      if not self.winid or not vim.api.nvim_win_is_valid(self.winid) then
+        return
+      end
+      local conn = self.handler:get_current_connection()
+      if not conn then
+        return
+      end
+
+      local bufnr = vim.api.nvim_win_get_buf(self.winid)
+      local lines = vim.api.nvim_buf_get_lines(bufnr, 0, -1, false)
+      local text = table.concat(lines, "\n")
+
+      -- byte offset of the cursor
+      local cursor = vim.api.nvim_win_get_cursor(self.winid)
+      local offset = cursor[2]
+      for i = 1, cursor[1] - 1 do
+        offset = offset + #lines[i] + 1
+      end
+
+      local stmt = self.handler:connection_get_statement_at(conn.id, text, offset)
+      if not stmt then
+        return
+      end
+      local call = self.handler:connection_execute(conn.id, stmt.text)
+      self:track_call(call, { bufnr = bufnr, row = stmt.start_line, col = stmt.start_col })
+      self.result:set_call(call)
+    end,
+    sample_statement = function()
+      if not self.winid or not vim.api.nvim_win_is_valid(self.winid) then
+        return
+      end
+      local conn = self.handler:get_current_connection()
+      if not conn then
+        return
+      end
+
+      local bufnr = vim.api.nvim_win_get_buf(self.winid)
+      local lines = vim.api.nvim_buf_get_lines(bufnr, 0, -1, false)
+      local text = table.concat(lines, "\n")
+
+      local cursor = vim.api.nvim_win_get_cursor(self.winid)
+      local offset = cursor[2]
+      for i = 1, cursor[1] - 1 do
+        offset = offset + #lines[i] + 1
+      end
+
+      local stmt = self.handler:connection_get_statement_at(conn.id, text, offset)
+      if not stmt then
+        return
+      end
+      local ok, call = pcall(self.handler.connection_execute, self.handler, conn.id, stmt.text, { sample = {} })
+      if not ok then
+        utils.log("warn", tostring(call), "editor")
+        return
+      end
+      self:track_call(call, { bufnr = bufnr, row = stmt.start_line, col = stmt.start_col })
       self.result:set_call(call)
     end,
+    estimate_cost = function()
+      if not self.winid or not vim.api.nvim_win_is_valid(self.winid) then
+        return
+      end
+      local conn = self.handler:get_current_connection()
+      if not conn then
+        return
+      end
+
+      local bufnr = vim.api.nvim_win_get_buf(self.winid)
+      local lines = vim.api.nvim_buf_get_lines(bufnr, 0, -1, false)
+      local text = table.concat(lines, "\n")
+
+      local cursor = vim.api.nvim_win_get_cursor(self.winid)
+      local offset = cursor[2]
+      for i = 1, cursor[1] - 1 do
+        offset = offset + #lines[i] + 1
+      end
+
+      local stmt = self.handler:connection_get_statement_at(conn.id, text, offset)
+      if not stmt then
+        return
+      end
+      local ok, estimate = pcall(self.handler.connection_estimate_cost, self.handler, conn.id, stmt.text)
+      if not ok then
+        utils.log("warn", tostring(estimate), "editor")
+        return
+      end
+
+      local msg = "query would scan " .. format_bytes(estimate.scanned_bytes)
+      if estimate.price_per_tib > 0 then
+        msg = msg .. string.format(", estimated cost $%.2f ($%.2f per TiB)", estimate.cost, estimate.price_per_tib)
+      end
+      if estimate.exceeds then
+        utils.log("warn", msg .. string.format(", above the limit of $%.2f", estimate.max_cost), "editor")
+        return
+      end
+      utils.log("info", msg, "editor")
+    end,
+    format_statement = function()
+      if not self.winid or not vim.api.nvim_win_is_valid(self.winid) then
+        return
+      end
+      local conn = self.handler:get_current_connection()
+      if not conn then
+        return
+      end
+
+      local bufnr = vim.api.nvim_win_get_buf(self.winid)
+      local lines = vim.api.nvim_buf_get_lines(bufnr, 0, -1, false)
+      local text = table.concat(lines, "\n")
+
+      local cursor = vim.api.nvim_win_get_cursor(self.winid)
+      local offset = cursor[2]
+      for i = 1, cursor[1] - 1 do
+        offset = offset + #lines[i] + 1
+      end
+
+      local stmt = self.handler:connection_get_statement_at(conn.id, text, offset)
+      if not stmt then
+        return
+      end
+      local formatted = self.handler:connection_format(conn.id, stmt.text)
+      if formatted == stmt.text then
+        return
+      end
+
+      -- the range includes the delimiter and whitespace around the statement,
+      -- which are kept
+      local range = text:sub(stmt.start + 1, stmt["end"])
+      local s = range:find(stmt.text, 1, true)
+      if not s then
+        return
+      end
+      local replacement = range:sub(1, s - 1) .. formatted .. range:sub(s + #stmt.text)
+      vim.api.nvim_buf_set_text(
+        bufnr,
+        stmt.start_line,
+        stmt.start_col,
+        stmt.end_line,
+        stmt.end_col,
+        vim.split(replacement, "\n", { plain = true })
+      )
+    end,
+    lint = function()
+      if not self.winid or not vim.api.nvim_win_is_valid(self.winid) then
+        return
+      end
+      local conn = self.handler:get_current_connection()
+      if not conn then
+        return
+      end
+
+      local bufnr = vim.api.nvim_win_get_buf(self.winid)
+      local text = table.concat(vim.api.nvim_buf_get_lines(bufnr, 0, -1, false), "\n")
+
+      local diagnostics = {}
+      for _, d in ipairs(self.handler:connection_lint(conn.id, text)) do
+        table.insert(diagnostics, {
+          lnum = d.line,
+          col = d.col,
+          end_lnum = d.end_line,
+          end_col = d.end_col,
+          severity = vim.diagnostic.severity[d.severity:upper()],
+          message = d.message,
+          code = d.rule,
+          source = "dbee",
+        })
+      end
+      vim.diagnostic.set(lint_ns, bufnr, diagnostics)
+      if #diagnostics == 0 then
+        utils.log("info", "no problems found", "editor")
+      end
+    end,
     run_selection = function()
       local srow, scol, erow, ecol = utils.visual_selection()
 
@@ -154,11 +357,66 @@ function EditorUI:get_actions()
         return
       end
       local call = self.handler:connection_execute(conn.id, query)
+      self:track_call(call, { bufnr = vim.api.nvim_get_current_buf(), row = srow, col = scol })
       self.result:set_call(call)
     end,
   }
 end
 
+-- Remembers where the query of the call is in the note, so errors can be
+-- shown as diagnostics on the offending line.
+---@private
+---@param call CallDetails
+---@param origin query_origin
+function EditorUI:track_call(call, origin)
+  vim.diagnostic.reset(diagnostics_ns, origin.bufnr)
+  self.query_origins[call.id] = origin
+end
+
+-- event listener for state changes of calls
+---@private
+---@param data { call: CallDetails }
+function EditorUI:on_call_state_changed(data)
+  local call = data.call
+  local origin = self.query_origins[call.id]
+  if not origin then
+    return
+  end
+
+  if call.state == "executing" or call.state == "retrieving" or call.state == "unknown" then
+    return
+  end
+  self.query_origins[call.id] = nil
+
+  local info = call.error_info
+  if not info or info == vim.NIL or info.line < 1 or not vim.api.nvim_buf_is_valid(origin.bufnr) then
+    return
+  end
+
+  -- columns of the first line are relative to the start of the query
+  local lnum = math.min(origin.row + info.line - 1, vim.api.nvim_buf_line_count(origin.bufnr) - 1)
+  local col = math.max(info.column - 1, 0)
+  if info.line == 1 then
+    col = col + origin.col
+  end
+
+  local message = call.error or ""
+  if info.hint ~= "" then
+    message = message .. "\nhint: " .. info.hint
+  end
+
+  vim.diagnostic.set(diagnostics_ns, origin.bufnr, {
+    {
+      lnum = lnum,
+      col = col,
+      severity = vim.diagnostic.severity.ERROR,
+      message = message,
+      code = info.code,
+      source = "dbee",
+    },
+  })
+end
+
 ---Triggers an in-built action.
 ---@param action string
 function EditorUI:do_action(action)
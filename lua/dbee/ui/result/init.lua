@@ -12,6 +12,7 @@ local common = require("dbee.ui.common")
 ---@field private mappings key_mapping[]
 ---@field private page_index integer index of the current page
 ---@field private page_ammount integer number of pages in the current result set
+---@field private set_index integer index of the displayed result set
 ---@field private stop_progress fun() function that stops progress display
 ---@field private progress_opts progress_config
 ---@field private window_options table<string, any> a table of window options.
@@ -34,6 +35,7 @@ function ResultUI:new(handler, opts)
     page_size = opts.page_size or 100,
     page_index = 0,
     page_ammount = 0,
+    set_index = 0,
     mappings = opts.mappings or {},
     stop_progress = function() end,
     progress_opts = opts.progress or {},
@@ -153,6 +155,26 @@ function ResultUI:display_status()
     table.insert(lines, "    " .. string.gsub(self.current_call.error, "\n", " "))
   end
 
+  local info = self.current_call.error_info
+  if info and info ~= vim.NIL then
+    local details = info.category
+    if info.code ~= "" then
+      details = string.format("%s (%s)", details, info.code)
+    end
+    if info.line > 0 then
+      details = string.format("%s at line %d", details, info.line)
+      if info.column > 0 then
+        details = string.format("%s, column %d", details, info.column)
+      end
+    end
+    table.insert(lines, "Category:")
+    table.insert(lines, "    " .. details)
+    if info.hint ~= "" then
+      table.insert(lines, "Hint:")
+      table.insert(lines, "    " .. string.gsub(info.hint, "\n", " "))
+    end
+  end
+
   vim.api.nvim_buf_set_option(self.bufnr, "modifiable", true)
   vim.api.nvim_buf_set_lines(self.bufnr, 0, -1, false, lines)
 
@@ -187,7 +209,7 @@ function ResultUI:display_result(page)
   local to = self.page_size * (page + 1)
 
   -- call go function
-  local length = self.handler:call_display_result(self.current_call.id, self.bufnr, from, to)
+  local length = self.handler:call_display_result(self.current_call.id, self.bufnr, from, to, self.set_index)
 
   -- adjust page ammount
   self.page_ammount = math.floor(length / self.page_size)
@@ -198,12 +220,34 @@ function ResultUI:display_result(page)
   -- convert from microseconds to seconds
   local seconds = self.current_call.time_taken_us / 1000000
 
+  -- extra info about the result
+  local ok, meta = pcall(self.handler.call_get_meta, self.handler, self.current_call.id, self.set_index)
+  if not ok or not meta then
+    meta = {}
+  end
+  local info = ""
+  if (self.current_call.result_sets or 0) > 1 then
+    info = info .. string.format(" [set %d/%d]", self.set_index + 1, self.current_call.result_sets)
+  end
+  if (meta.injected_limit or 0) > 0 then
+    info = info .. string.format(" [limited to %d]", meta.injected_limit)
+  end
+  if (meta.sampling or "") ~= "" then
+    info = info .. string.format(" [sample: %s]", meta.sampling)
+  end
+  if (meta.retries or 0) > 0 then
+    info = info .. string.format(" [%d retries]", meta.retries)
+  end
+  if #(meta.notices or {}) > 0 then
+    info = info .. string.format(" [%d notices]", #meta.notices)
+  end
+
   -- set winbar status
   if self:has_window() then
     vim.api.nvim_win_set_option(
       self.winid,
       "winbar",
-      string.format("%d/%d (%d)%%=Took %.3fs", page + 1, self.page_ammount + 1, length, seconds)
+      string.format("%d/%d (%d)%s%%=Took %.3fs", page + 1, self.page_ammount + 1, length, info, seconds)
     )
 
     -- set focus if window exists
@@ -232,6 +276,12 @@ function ResultUI:get_actions()
     page_first = function()
       self:page_first()
     end,
+    set_next = function()
+      self:set_next()
+    end,
+    set_prev = function()
+      self:set_prev()
+    end,
 
     -- yank functions
     yank_current_json = function()
@@ -252,12 +302,72 @@ function ResultUI:get_actions()
     yank_all_csv = function()
       self:store_all_wrapper("csv", vim.v.register)
     end,
+    yank_current_geojson = function()
+      self:store_current_wrapper("geojson", vim.v.register)
+    end,
+    yank_selection_geojson = function()
+      self:store_selection_wrapper("geojson", vim.v.register)
+    end,
+    yank_all_geojson = function()
+      self:store_all_wrapper("geojson", vim.v.register)
+    end,
 
     cancel_call = function()
       if self.current_call then
         self.handler:call_cancel(self.current_call.id)
       end
     end,
+
+    show_notices = function()
+      if not self.current_call then
+        return
+      end
+      local meta = self.handler:call_get_meta(self.current_call.id, self.set_index) or {}
+      if #(meta.notices or {}) < 1 then
+        utils.log("info", "no notices", "result")
+        return
+      end
+      utils.log("info", table.concat(meta.notices, "\n"), "result")
+    end,
+
+    explain_error = function()
+      if not self.current_call or (self.current_call.error or "") == "" then
+        utils.log("info", "call did not fail", "result")
+        return
+      end
+      local hints = self.handler:call_explain_error(self.current_call.id)
+      if #hints < 1 then
+        utils.log("info", "no hints for the error", "result")
+        return
+      end
+      local lines = {}
+      for _, hint in ipairs(hints) do
+        table.insert(lines, hint.message)
+        if hint.query ~= "" then
+          table.insert(lines, "    " .. string.gsub(hint.query, "\n", " "))
+        end
+      end
+      utils.log("info", table.concat(lines, "\n"), "result")
+    end,
+
+    show_metrics = function()
+      if not self.current_call then
+        return
+      end
+      local meta = self.handler:call_get_meta(self.current_call.id, self.set_index) or {}
+      local m = meta.metrics or {}
+      utils.log(
+        "info",
+        string.format(
+          "execution: %.3fms, fetch: %.3fms, archive: %.3fms, format: %.3fms",
+          m.execution_ms or 0,
+          m.fetch_ms or 0,
+          m.archive_ms or 0,
+          m.format_ms or 0
+        ),
+        "result"
+      )
+    end,
   }
 end
 
@@ -276,6 +386,7 @@ end
 function ResultUI:set_call(call)
   self.page_index = 0
   self.page_ammount = 0
+  self.set_index = 0
   self.current_call = call
 
   self.stop_progress()
@@ -307,6 +418,24 @@ function ResultUI:page_first()
   self.page_index = self:display_result(0)
 end
 
+-- switches to the next result set of the current call
+function ResultUI:set_next()
+  if not self.current_call or self.set_index + 1 >= (self.current_call.result_sets or 1) then
+    return
+  end
+  self.set_index = self.set_index + 1
+  self.page_index = self:display_result(0)
+end
+
+-- switches to the previous result set of the current call
+function ResultUI:set_prev()
+  if not self.current_call or self.set_index < 1 then
+    return
+  end
+  self.set_index = self.set_index - 1
+  self.page_index = self:display_result(0)
+end
+
 -- wrapper for storing the current row
 ---@private
 ---@param format string
@@ -329,7 +458,7 @@ function ResultUI:store_current_wrapper(format, register)
     self.current_call.id,
     format,
     "yank",
-    { from = index, to = index + 1, extra_arg = register }
+    { from = index, to = index + 1, extra_arg = register, set = self.set_index }
   )
 end
 
@@ -353,7 +482,7 @@ function ResultUI:store_selection_wrapper(format, register)
     self.current_call.id,
     format,
     "yank",
-    { from = sindex, to = eindex, extra_arg = register }
+    { from = sindex, to = eindex, extra_arg = register, set = self.set_index }
   )
 end
 
@@ -365,7 +494,7 @@ function ResultUI:store_all_wrapper(format, register)
   if not self.current_call then
     error("no call set to result")
   end
-  self.handler:call_store_result(self.current_call.id, format, "yank", { extra_arg = register })
+  self.handler:call_store_result(self.current_call.id, format, "yank", { extra_arg = register, set = self.set_index })
 end
 
 ---@private
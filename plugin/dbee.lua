@@ -12,6 +12,9 @@ local commands = {
   execute = function(args)
     require("dbee").execute(table.concat(args, " "))
   end,
+  subscribe = function(args)
+    require("dbee").subscribe(table.concat(args, " "))
+  end,
   store = function(args)
     -- args are "format", "output" and "extra_arg"
     if #args < 3 then
@@ -20,6 +23,29 @@ local commands = {
 
     require("dbee").store(args[1], args[2], { extra_arg = args[3] })
   end,
+  logs = function(args)
+    require("dbee").logs(args[1])
+  end,
+  slow = function(args)
+    require("dbee").slow_queries(args[1])
+  end,
+  notify = function()
+    require("dbee").notify()
+  end,
+  errors = function(args)
+    require("dbee").failed_queries(args[1])
+  end,
+  top = function(args)
+    require("dbee").top_queries(args[1])
+  end,
+  export = function(args)
+    -- args are "format" and "path"
+    if #args < 2 then
+      error("not enough arguments, got " .. #args .. " want 2")
+    end
+
+    require("dbee").export(args[1], args[2])
+  end,
 }
 
 ---@param args string args in form of Dbee arg1 arg2 ...
@@ -72,6 +98,20 @@ end, {
       return vim.tbl_keys(commands)
     end
 
+    if line[1] == "logs" then
+      if #line == 1 then
+        return { "debug", "info", "warn", "error" }
+      end
+      return
+    end
+
+    if line[1] == "export" then
+      if #line == 1 then
+        return { "csv", "json", "geojson", "insert", "table" }
+      end
+      return
+    end
+
     if line[1] ~= "store" then
       return {}
     end
@@ -79,7 +119,7 @@ end, {
     local nargs = #line
     if nargs == 1 then
       -- format
-      return { "csv", "json", "table" }
+      return { "csv", "json", "geojson", "insert", "table" }
     elseif nargs == 2 then
       -- output
       return { "file", "yank", "buffer" }